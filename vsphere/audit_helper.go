@@ -0,0 +1,213 @@
+package vsphere
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/vic/pkg/vsphere/tags"
+)
+
+// formatAuditModeBlockedError is the error returned for a SOAP call that was
+// blocked by audit mode.
+const formatAuditModeBlockedError = `audit mode is enabled, and the following vSphere API call was blocked:
+
+Method: %s
+Object: %s
+
+%s
+
+Disable audit_mode in the provider configuration to make changes.`
+
+// formatAuditModeBlockedRESTError is the error returned for a REST call that
+// was blocked by audit mode.
+const formatAuditModeBlockedRESTError = `audit mode is enabled, and the following vSphere REST API call was blocked:
+
+%s %s
+
+%s
+
+Disable audit_mode in the provider configuration to make changes.`
+
+// auditAllowedMethods is the list of SOAP methods that are let through in
+// audit mode. These methods only read data, or manage session-scoped objects
+// such as views and property filters. Any method that is not on this list is
+// blocked.
+var auditAllowedMethods = map[string]bool{
+	"RetrieveServiceContent":            true,
+	"Login":                             true,
+	"Logout":                            true,
+	"SessionIsActive":                   true,
+	"CurrentTime":                       true,
+	"RetrieveProperties":                true,
+	"RetrievePropertiesEx":              true,
+	"ContinueRetrievePropertiesEx":      true,
+	"CancelRetrievePropertiesEx":        true,
+	"CreateFilter":                      true,
+	"DestroyPropertyFilter":             true,
+	"WaitForUpdates":                    true,
+	"WaitForUpdatesEx":                  true,
+	"CheckForUpdates":                   true,
+	"CreateContainerView":               true,
+	"CreateListView":                    true,
+	"CreatePropertyCollector":           true,
+	"DestroyPropertyCollector":          true,
+	"DestroyView":                       true,
+	"FindByUuid":                        true,
+	"FindAllByUuid":                     true,
+	"FindByInventoryPath":               true,
+	"FindChild":                         true,
+	"FindExtension":                     true,
+	"QueryConfigOption":                 true,
+	"QueryConfigOptionDescriptor":       true,
+	"QueryConfigOptionEx":               true,
+	"QueryConfigTarget":                 true,
+	"QueryTargetCapabilities":           true,
+	"QueryAvailableDisksForVmfs":        true,
+	"QueryVmfsDatastoreCreateOptions":   true,
+	"ListVStorageObject":                true,
+	"RetrieveVStorageObject":            true,
+	"PlaceVm":                           true,
+	"RecommendDatastores":               true,
+	"FetchDVPorts":                      true,
+	"QueryDvsByUuid":                    true,
+	"QueryDvsCheckCompatibility":        true,
+	"DVSManagerLookupDvPortGroup":       true,
+	"SearchDatastore_Task":              true,
+	"QueryOptions":                      true,
+	"QueryLockdownExceptions":           true,
+	"QueryConfiguredModuleOptionString": true,
+	"RetrieveEntityPermissions":         true,
+	"HasPrivilegeOnEntity":              true,
+	"FetchUserPrivilegeOnEntities":      true,
+	"CreateCollectorForEvents":          true,
+	"ReadPreviousEvents":                true,
+	"DestroyCollector":                  true,
+	"AcquireTicket":                     true,
+	"GetAlarm":                          true,
+	// Rescanning storage refreshes what the host sees, but does not change its
+	// configuration. The vsphere_vmfs_disks data source does this on request.
+	"RescanAllHba": true,
+}
+
+// auditAllowedRESTActions is the list of REST actions, sent as POST requests,
+// that are let through in audit mode. All GET requests are let through as
+// well.
+var auditAllowedRESTActions = map[string]bool{
+	"list-attached-tags":     true,
+	"list-attached-objects":  true,
+	"list-tags-for-category": true,
+	"find":                   true,
+}
+
+// auditRoundTripper is a soap.RoundTripper that only lets through the methods
+// in auditAllowedMethods, and fails any other call with an error describing
+// the call.
+type auditRoundTripper struct {
+	rt soap.RoundTripper
+}
+
+// RoundTrip implements soap.RoundTripper for auditRoundTripper.
+func (a *auditRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	method, this, body := auditDescribeRequest(req)
+	if auditAllowedMethods[method] {
+		return a.rt.RoundTrip(ctx, req, res)
+	}
+	log.Printf("[DEBUG] Audit mode: blocking call to %s on %s", method, this)
+	return fmt.Errorf(formatAuditModeBlockedError, method, this, body)
+}
+
+// auditDescribeRequest returns the method name, the object that the method
+// is called on, and the XML request body of a SOAP request body, as generated
+// in the methods package. Passwords are left out of the request.
+func auditDescribeRequest(req interface{}) (string, string, string) {
+	v := reflect.Indirect(reflect.ValueOf(req))
+	if v.Kind() != reflect.Struct {
+		return "(unknown)", "(unknown)", ""
+	}
+	f, ok := v.Type().FieldByName("Req")
+	if !ok || v.FieldByName("Req").IsNil() {
+		return "(unknown)", "(unknown)", ""
+	}
+	// The method name is the name of the element in the tag, like
+	// `xml:"urn:vim25 CreateVM_Task,omitempty"`.
+	method := strings.Split(f.Tag.Get("xml"), ",")[0]
+	if i := strings.LastIndex(method, " "); i >= 0 {
+		method = method[i+1:]
+	}
+
+	r := v.FieldByName("Req")
+	this := "(unknown)"
+	if ref, ok := reflect.Indirect(r).FieldByName("This").Interface().(types.ManagedObjectReference); ok {
+		this = ref.String()
+	}
+
+	body, err := xml.MarshalIndent(r.Interface(), "", "  ")
+	if err != nil {
+		return method, this, fmt.Sprintf("(request body unavailable: %s)", err)
+	}
+	return method, this, redactPasswordElements(string(body))
+}
+
+// auditTransport is a http.RoundTripper for the CIS REST client that only
+// lets through GET requests, logins, and the POST actions in
+// auditAllowedRESTActions. Any other request fails with an error describing
+// the request.
+type auditTransport struct {
+	rt http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper for auditTransport.
+func (a *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if auditRESTAllowed(req) {
+		return a.rt.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	log.Printf("[DEBUG] Audit mode: blocking REST call to %s %s", req.Method, req.URL.RequestURI())
+	return nil, fmt.Errorf(formatAuditModeBlockedRESTError, req.Method, req.URL.RequestURI(), bytes.TrimSpace(body))
+}
+
+// auditRESTAllowed returns true if a REST request is allowed in audit mode.
+func auditRESTAllowed(req *http.Request) bool {
+	switch {
+	case req.Method == http.MethodGet:
+		return true
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/com/vmware/cis/session"):
+		return true
+	case req.Method == http.MethodPost:
+		return auditAllowedRESTActions[req.URL.Query().Get("~action")]
+	}
+	return false
+}
+
+// enableAuditMode wraps the SOAP and REST clients of a VSphereClient so that
+// only read-only calls are let through.
+func enableAuditMode(client *VSphereClient) {
+	log.Printf("[DEBUG] Audit mode enabled, only read-only API calls will be made")
+	client.vimClient.Client.RoundTripper = &auditRoundTripper{rt: client.vimClient.Client.RoundTripper}
+	if client.tagsClient != nil {
+		enableAuditModeREST(client.tagsClient)
+	}
+}
+
+// enableAuditModeREST wraps the HTTP transport of a CIS REST client in an
+// auditTransport.
+func enableAuditModeREST(client *tags.RestClient) {
+	rt := client.HTTP.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	client.HTTP.Transport = &auditTransport{rt: rt}
+}
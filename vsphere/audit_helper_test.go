@@ -0,0 +1,271 @@
+package vsphere
+
+import (
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAuditDescribeRequest(t *testing.T) {
+	req := &methods.LoginBody{
+		Req: &types.Login{
+			This:     types.ManagedObjectReference{Type: "SessionManager", Value: "SessionManager"},
+			UserName: "user",
+			Password: "secret",
+		},
+	}
+	method, this, body := auditDescribeRequest(req)
+	if method != "Login" {
+		t.Fatalf("expected method to be Login, got %q", method)
+	}
+	if this != "SessionManager:SessionManager" {
+		t.Fatalf("expected object to be SessionManager:SessionManager, got %q", this)
+	}
+	if strings.Contains(body, "secret") {
+		t.Fatalf("expected password to be redacted from body:\n%s", body)
+	}
+	if !strings.Contains(body, "<userName>user</userName>") {
+		t.Fatalf("expected user name in body:\n%s", body)
+	}
+}
+
+func TestAuditRESTAllowed(t *testing.T) {
+	cases := []struct {
+		method   string
+		url      string
+		expected bool
+	}{
+		{http.MethodGet, "https://vc/rest/com/vmware/cis/tagging/tag", true},
+		{http.MethodPost, "https://vc/rest/com/vmware/cis/session", true},
+		{http.MethodPost, "https://vc/rest/com/vmware/cis/tagging/tag-association?~action=list-attached-tags", true},
+		{http.MethodPost, "https://vc/rest/com/vmware/cis/tagging/tag-association/id:foo?~action=attach", false},
+		{http.MethodPost, "https://vc/rest/com/vmware/cis/tagging/tag", false},
+		{http.MethodDelete, "https://vc/rest/com/vmware/cis/tagging/tag/id:foo", false},
+	}
+	for _, tc := range cases {
+		req, err := http.NewRequest(tc.method, tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := auditRESTAllowed(req); actual != tc.expected {
+			t.Fatalf("%s %s: expected %t, got %t", tc.method, tc.url, tc.expected, actual)
+		}
+	}
+}
+
+// TestAuditModeReadPaths type-checks the provider from source and walks the
+// static call graph of every resource and data source Read function, and of
+// every CustomizeDiff function, into the vendored govmomi and tags libraries.
+// Every SOAP method and REST action that can be reached on these paths must be
+// allowed in audit mode, or plans would fail with audit_mode set.
+func TestAuditModeReadPaths(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bp, err := build.ImportDir(wd, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newAuditTestLoader()
+	pkg, err := l.load(bp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := Provider().(*schema.Provider)
+	roots := make(map[string]interface{})
+	for k, r := range p.ResourcesMap {
+		roots[k+" Read"] = r.Read
+		if r.CustomizeDiff != nil {
+			roots[k+" CustomizeDiff"] = r.CustomizeDiff
+		}
+	}
+	for k, r := range p.DataSourcesMap {
+		roots["data source "+k+" Read"] = r.Read
+	}
+
+	for name, fn := range roots {
+		full := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+		start := auditTestLookupFunc(pkg, full)
+		if start == nil {
+			t.Fatalf("%s: cannot find %s in the type-checked package", name, full)
+		}
+		var blocked []string
+		for call, from := range l.calls(start) {
+			if strings.HasPrefix(call, "~action=") {
+				if !auditAllowedRESTActions[strings.TrimPrefix(call, "~action=")] {
+					blocked = append(blocked, call+" (from "+from+")")
+				}
+				continue
+			}
+			if !auditAllowedMethods[call] {
+				blocked = append(blocked, call+" (from "+from+")")
+			}
+		}
+		sort.Strings(blocked)
+		if len(blocked) > 0 {
+			t.Errorf("%s makes calls that are blocked in audit mode:\n  %s", name, strings.Join(blocked, "\n  "))
+		}
+	}
+}
+
+// auditTestRESTActionRe matches the REST action in the query string of a CIS
+// REST call.
+var auditTestRESTActionRe = regexp.MustCompile(`~action=[a-z-]+`)
+
+// auditTestLoader is a gotypes.ImporterFrom that type-checks packages from
+// source, keeping the function declarations of the provider and the vendored
+// VMware libraries so that their call graph can be walked.
+type auditTestLoader struct {
+	fset  *token.FileSet
+	std   gotypes.Importer
+	pkgs  map[string]*gotypes.Package
+	info  *gotypes.Info
+	decls map[*gotypes.Func]*ast.FuncDecl
+}
+
+func newAuditTestLoader() *auditTestLoader {
+	fset := token.NewFileSet()
+	return &auditTestLoader{
+		fset: fset,
+		std:  importer.ForCompiler(fset, "source", nil),
+		pkgs: make(map[string]*gotypes.Package),
+		info: &gotypes.Info{
+			Defs: make(map[*ast.Ident]gotypes.Object),
+			Uses: make(map[*ast.Ident]gotypes.Object),
+		},
+		decls: make(map[*gotypes.Func]*ast.FuncDecl),
+	}
+}
+
+// Import implements types.Importer for auditTestLoader.
+func (l *auditTestLoader) Import(path string) (*gotypes.Package, error) {
+	return l.ImportFrom(path, ".", 0)
+}
+
+// ImportFrom implements types.ImporterFrom for auditTestLoader. Standard
+// library packages are passed to the source importer, everything else is
+// resolved from dir so that the vendor directory is used.
+func (l *auditTestLoader) ImportFrom(path, dir string, _ gotypes.ImportMode) (*gotypes.Package, error) {
+	if path == "unsafe" {
+		return gotypes.Unsafe, nil
+	}
+	bp, err := build.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if bp.Goroot {
+		return l.std.Import(path)
+	}
+	return l.load(bp)
+}
+
+func (l *auditTestLoader) load(bp *build.Package) (*gotypes.Package, error) {
+	if pkg, ok := l.pkgs[bp.ImportPath]; ok {
+		return pkg, nil
+	}
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(l.fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	keep := strings.Contains(bp.ImportPath, "terraform-provider-vsphere/vsphere") || strings.Contains(bp.ImportPath, "github.com/vmware/")
+	var info *gotypes.Info
+	if keep {
+		info = l.info
+	}
+	conf := gotypes.Config{Importer: l, FakeImportC: true}
+	pkg, err := conf.Check(bp.ImportPath, l.fset, files, info)
+	if err != nil {
+		return nil, err
+	}
+	l.pkgs[bp.ImportPath] = pkg
+	if !keep {
+		return pkg, nil
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Body != nil {
+				l.decls[l.info.Defs[fd.Name].(*gotypes.Func)] = fd
+			}
+		}
+	}
+	return pkg, nil
+}
+
+// calls returns the SOAP methods and REST actions that can be reached from
+// start, mapped to the function that makes the call. Functions are followed
+// whenever they are referenced, called or not, so that callbacks are walked
+// too. Calls through interfaces are not followed.
+func (l *auditTestLoader) calls(start *gotypes.Func) map[string]string {
+	result := make(map[string]string)
+	seen := map[*gotypes.Func]bool{start: true}
+	queue := []*gotypes.Func{start}
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+		ast.Inspect(l.decls[fn], func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Ident:
+				callee, ok := l.info.Uses[n].(*gotypes.Func)
+				if !ok || callee.Pkg() == nil {
+					return true
+				}
+				if strings.HasSuffix(callee.Pkg().Path(), "govmomi/vim25/methods") {
+					result[callee.Name()] = fn.FullName()
+					return true
+				}
+				if _, ok := l.decls[callee]; ok && !seen[callee] {
+					seen[callee] = true
+					queue = append(queue, callee)
+				}
+			case *ast.BasicLit:
+				if n.Kind != token.STRING {
+					return true
+				}
+				s, _ := strconv.Unquote(n.Value)
+				for _, action := range auditTestRESTActionRe.FindAllString(s, -1) {
+					result[action] = fn.FullName()
+				}
+			}
+			return true
+		})
+	}
+	return result
+}
+
+// auditTestLookupFunc finds the function with the runtime name full in pkg.
+// Method values, like entityNameRule.customizeDiff-fm, are resolved to the
+// method, and closures, like resourceVSphereFooRead.func1, to the function
+// that they are declared in.
+func auditTestLookupFunc(pkg *gotypes.Package, full string) *gotypes.Func {
+	name := strings.TrimSuffix(full[strings.LastIndex(full, "/")+1:], "-fm")
+	parts := strings.Split(name, ".")[1:]
+	obj := pkg.Scope().Lookup(strings.Trim(parts[0], "(*)"))
+	if tn, ok := obj.(*gotypes.TypeName); ok && len(parts) > 1 {
+		obj, _, _ = gotypes.LookupFieldOrMethod(gotypes.NewPointer(tn.Type()), true, pkg, parts[1])
+	}
+	fn, _ := obj.(*gotypes.Func)
+	return fn
+}
@@ -33,6 +33,22 @@ type VSphereClient struct {
 
 	// The specialized tags client SDK imported from vmware/vic.
 	tagsClient *tags.RestClient
+
+	// The soft destroy settings for virtual machines, or nil if soft destroy is
+	// disabled.
+	softDestroy *softDestroyOptions
+
+	// Whether or not to check privileges for planned operations at plan time.
+	preflightPrivilegeCheck bool
+
+	// Whether or not to sanitize invalid object names instead of failing.
+	sanitizeNames bool
+
+	// Whether or not to adapt to the restrictions of VMware Cloud on AWS.
+	vmcMode bool
+
+	// Whether or not the endpoint is a vCenter simulator.
+	simulatorMode bool
 }
 
 // TagsClient returns the embedded REST client used for tags, after determining
@@ -60,6 +76,9 @@ func (c *VSphereClient) TagsClient() (*tags.RestClient, error) {
 	if err := viapi.ValidateVirtualCenter(c.vimClient); err != nil {
 		return nil, err
 	}
+	if c.simulatorMode {
+		return nil, fmt.Errorf("tags are not supported in simulator mode")
+	}
 	if c.tagsClient == nil {
 		return nil, fmt.Errorf("tags require %s or higher", tagsMinVersion)
 	}
@@ -77,8 +96,37 @@ type Config struct {
 	VSphereServer   string
 	DebugPath       string
 	DebugPathRun    string
+	DebugSOAP       bool
 	VimSessionPath  string
 	RestSessionPath string
+	AuditMode       bool
+
+	PreflightPrivilegeCheck bool
+
+	SanitizeNames bool
+
+	VMCMode bool
+
+	SimulatorMode bool
+
+	// The User-Agent of the API calls, and the prefix of the operation IDs
+	// that the SOAP calls are stamped with.
+	UserAgent   string
+	OperationID string
+
+	HTTPSProxy   string
+	NoProxy      string
+	CABundleFile string
+	CABundle     string
+
+	SoftDestroy            bool
+	SoftDestroyFolder      string
+	SoftDestroyTTL         int
+	SoftDestroyTagCategory string
+
+	// The context that is cancelled when Terraform is interrupted. vSphere
+	// tasks that are still running at that point are cancelled.
+	StopContext context.Context
 }
 
 // NewConfig returns a new Config from a supplied ResourceData.
@@ -104,9 +152,32 @@ func NewConfig(d *schema.ResourceData) (*Config, error) {
 		Debug:           d.Get("client_debug").(bool),
 		DebugPathRun:    d.Get("client_debug_path_run").(string),
 		DebugPath:       d.Get("client_debug_path").(string),
+		DebugSOAP:       d.Get("debug_soap").(bool),
 		Persist:         d.Get("persist_session").(bool),
 		VimSessionPath:  d.Get("vim_session_path").(string),
 		RestSessionPath: d.Get("rest_session_path").(string),
+		AuditMode:       d.Get("audit_mode").(bool),
+
+		PreflightPrivilegeCheck: d.Get("preflight_privilege_check").(bool),
+
+		SanitizeNames: d.Get("sanitize_names").(bool),
+
+		VMCMode: d.Get("vmc_mode").(bool),
+
+		SimulatorMode: d.Get("simulator_mode").(bool),
+
+		UserAgent:   d.Get("user_agent").(string),
+		OperationID: d.Get("operation_id").(string),
+
+		HTTPSProxy:   d.Get("https_proxy").(string),
+		NoProxy:      d.Get("no_proxy").(string),
+		CABundleFile: d.Get("ca_bundle_file").(string),
+		CABundle:     d.Get("ca_bundle").(string),
+
+		SoftDestroy:            d.Get("soft_destroy").(bool),
+		SoftDestroyFolder:      d.Get("soft_destroy_folder").(string),
+		SoftDestroyTTL:         d.Get("soft_destroy_ttl").(int),
+		SoftDestroyTagCategory: d.Get("soft_destroy_tag_category").(string),
 	}
 
 	return c, nil
@@ -138,6 +209,13 @@ func (c *Config) Client() (*VSphereClient, error) {
 		return nil, fmt.Errorf("Error setting up client debug: %s", err)
 	}
 
+	if c.OperationID == "" {
+		c.OperationID, err = newOperationID()
+		if err != nil {
+			return nil, fmt.Errorf("error generating operation ID: %s", err)
+		}
+	}
+
 	// Set up the VIM/govmomi client connection, or load a previous session
 	client.vimClient, err = c.SavedVimSessionOrNew(u)
 	if err != nil {
@@ -146,19 +224,61 @@ func (c *Config) Client() (*VSphereClient, error) {
 
 	log.Printf("[DEBUG] VMWare vSphere Client configured for URL: %s", c.VSphereServer)
 
-	if isEligibleTagEndpoint(client.vimClient) {
+	switch {
+	case c.SimulatorMode:
+		// The simulator reports itself as vCenter, but does not serve the CIS
+		// REST endpoint.
+		log.Println("[DEBUG] Simulator mode enabled, not connecting to the CIS REST endpoint")
+	case isEligibleTagEndpoint(client.vimClient):
 		// Connect to the CIS REST endpoint for tagging, or load a previous session
 		client.tagsClient, err = c.SavedRestSessionOrNew(u)
 		if err != nil {
 			return nil, err
 		}
 		log.Println("[DEBUG] CIS REST client configuration successful")
-	} else {
+		if c.DebugSOAP {
+			enableRESTDebug(client.tagsClient)
+		}
+	default:
 		// Just print a log message so that we know that tags are not available on
 		// this connection.
 		log.Printf("[DEBUG] Connected endpoint does not support tags (%s)", viapi.ParseVersionFromClient(client.vimClient))
 	}
 
+	if c.SoftDestroy {
+		if c.SimulatorMode {
+			return nil, fmt.Errorf("soft_destroy cannot be used in simulator mode")
+		}
+		if client.tagsClient == nil {
+			return nil, fmt.Errorf("soft_destroy requires a connection to vCenter 6.0 or higher")
+		}
+		client.softDestroy = &softDestroyOptions{
+			Folder:      c.SoftDestroyFolder,
+			TTL:         c.SoftDestroyTTL,
+			TagCategory: c.SoftDestroyTagCategory,
+		}
+		// Virtual machines can only be moved to folders in the workload folder on
+		// VMware Cloud on AWS.
+		if c.VMCMode {
+			client.softDestroy.Folder = vmcWorkloadFolderPath(c.SoftDestroyFolder)
+		}
+	}
+
+	client.preflightPrivilegeCheck = c.PreflightPrivilegeCheck
+	client.sanitizeNames = c.SanitizeNames
+	client.vmcMode = c.VMCMode
+	client.simulatorMode = c.SimulatorMode
+
+	enableOperationIDs(client, c.OperationID, c.UserAgent)
+
+	if c.StopContext != nil {
+		enableTaskCancellation(c.StopContext, client)
+	}
+
+	if c.AuditMode {
+		enableAuditMode(client)
+	}
+
 	// Done, save sessions if we need to and return
 	if err := c.SaveVimClient(client.vimClient); err != nil {
 		return nil, fmt.Errorf("error persisting SOAP session to disk: %s", err)
@@ -171,9 +291,10 @@ func (c *Config) Client() (*VSphereClient, error) {
 }
 
 // EnableDebug turns on govmomi API operation logging, if appropriate settings
-// are set on the provider.
+// are set on the provider. When debug_soap is set, passwords and session
+// tokens are removed from the output.
 func (c *Config) EnableDebug() error {
-	if !c.Debug {
+	if !c.Debug && !c.DebugSOAP {
 		return nil
 	}
 
@@ -205,6 +326,10 @@ func (c *Config) EnableDebug() error {
 		Path: r,
 	}
 
+	if c.DebugSOAP {
+		debug.SetProvider(&sanitizingDebugProvider{p: &p})
+		return nil
+	}
 	debug.SetProvider(&p)
 	return nil
 }
@@ -404,6 +529,9 @@ func (c *Config) LoadVimClient() (*govmomi.Client, error) {
 		log.Println("[DEBUG] Cached SOAP client session data not valid or persistence not enabled, new session necessary")
 		return nil, nil
 	}
+	if err := c.configureTransport(&client.Client.Client); err != nil {
+		return nil, err
+	}
 
 	m := session.NewManager(client)
 	u, err := m.UserSession(context.TODO())
@@ -444,6 +572,9 @@ func (c *Config) LoadRestClient(ctx context.Context, u *url.URL) (*tags.RestClie
 	}
 
 	client := tags.NewClientWithSessionID(u, c.InsecureFlag, "", id)
+	if err := c.configureTransport(client.HTTP); err != nil {
+		return nil, false, err
+	}
 
 	if id == "" {
 		log.Println("[DEBUG] No cached REST session data found or persistence not enabled, new session necessary")
@@ -471,7 +602,7 @@ func (c *Config) SavedVimSessionOrNew(u *url.URL) (*govmomi.Client, error) {
 	}
 	if client == nil {
 		log.Printf("[DEBUG] Creating new SOAP API session on endpoint %s", c.VSphereServer)
-		client, err = govmomi.NewClient(ctx, u, c.InsecureFlag)
+		client, err = c.newVimClient(ctx, u)
 		if err != nil {
 			return nil, fmt.Errorf("error setting up new vSphere SOAP client: %s", err)
 		}
@@ -480,6 +611,33 @@ func (c *Config) SavedVimSessionOrNew(u *url.URL) (*govmomi.Client, error) {
 	return client, nil
 }
 
+// newVimClient creates a new SOAP client and logs in. This is
+// govmomi.NewClient, with the User-Agent set before the login so that the
+// session shows the configured user agent in vCenter, and the login calls
+// stamped with the operation ID of the run.
+func (c *Config) newVimClient(ctx context.Context, u *url.URL) (*govmomi.Client, error) {
+	ctx = operationIDContext(ctx, c.OperationID+"-login")
+	soapClient := soap.NewClient(u, c.InsecureFlag)
+	soapClient.UserAgent = c.UserAgent
+	if err := c.configureTransport(&soapClient.Client); err != nil {
+		return nil, err
+	}
+	vimClient, err := vim25.NewClient(ctx, soapClient)
+	if err != nil {
+		return nil, err
+	}
+	client := &govmomi.Client{
+		Client:         vimClient,
+		SessionManager: session.NewManager(vimClient),
+	}
+	if u.User != nil {
+		if err := client.Login(ctx, u.User); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
 // SavedRestSessionOrNew either loads a saved REST session from disk, or creates
 // a new one.
 func (c *Config) SavedRestSessionOrNew(u *url.URL) (*tags.RestClient, error) {
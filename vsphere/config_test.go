@@ -168,9 +168,20 @@ func TestNewConfig(t *testing.T) {
 		Debug:           true,
 		DebugPathRun:    "./foo",
 		DebugPath:       "./bar",
+		DebugSOAP:       true,
 		Persist:         true,
 		VimSessionPath:  "./baz",
 		RestSessionPath: "./qux",
+		AuditMode:       true,
+
+		PreflightPrivilegeCheck: true,
+
+		SimulatorMode: true,
+
+		SoftDestroy:            true,
+		SoftDestroyFolder:      "quarantine",
+		SoftDestroyTTL:         7,
+		SoftDestroyTagCategory: "expiry",
 	}
 
 	r := &schema.Resource{Schema: Provider().(*schema.Provider).Schema}
@@ -182,9 +193,17 @@ func TestNewConfig(t *testing.T) {
 	d.Set("client_debug", expected.Debug)
 	d.Set("client_debug_path_run", expected.DebugPathRun)
 	d.Set("client_debug_path", expected.DebugPath)
+	d.Set("debug_soap", expected.DebugSOAP)
 	d.Set("persist_session", expected.Persist)
 	d.Set("vim_session_path", expected.VimSessionPath)
 	d.Set("rest_session_path", expected.RestSessionPath)
+	d.Set("audit_mode", expected.AuditMode)
+	d.Set("preflight_privilege_check", expected.PreflightPrivilegeCheck)
+	d.Set("simulator_mode", expected.SimulatorMode)
+	d.Set("soft_destroy", expected.SoftDestroy)
+	d.Set("soft_destroy_folder", expected.SoftDestroyFolder)
+	d.Set("soft_destroy_ttl", expected.SoftDestroyTTL)
+	d.Set("soft_destroy_tag_category", expected.SoftDestroyTagCategory)
 
 	actual, err := NewConfig(d)
 	if err != nil {
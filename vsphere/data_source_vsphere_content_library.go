@@ -0,0 +1,46 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func dataSourceVSphereContentLibrary() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereContentLibraryRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the content library.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the content library.",
+				Computed:    true,
+			},
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore that the items of the content library are stored on.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereContentLibraryRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+	library, err := contentlibrary.LibraryFromName(tagsClient, client.vimClient.URL(), name)
+	if err != nil {
+		return fmt.Errorf("error fetching content library %q: %s", name, err)
+	}
+	d.SetId(library.ID)
+	return resourceVSphereContentLibraryRead(d, meta)
+}
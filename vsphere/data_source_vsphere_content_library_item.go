@@ -0,0 +1,51 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func dataSourceVSphereContentLibraryItem() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereContentLibraryItemRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the item.",
+				Required:    true,
+			},
+			"library_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the content library that the item is in.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the item.",
+				Computed:    true,
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Description: "The type of the item, such as ovf, vm-template, or iso.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereContentLibraryItemRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+	item, err := contentlibrary.ItemFromName(tagsClient, client.vimClient.URL(), d.Get("library_id").(string), name)
+	if err != nil {
+		return fmt.Errorf("error fetching content library item %q: %s", name, err)
+	}
+	d.SetId(item.ID)
+	return resourceVSphereContentLibraryItemRead(d, meta)
+}
@@ -0,0 +1,42 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereContentLibraryItem_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereContentLibraryPreCheck(t)
+			testAccResourceVSphereContentLibraryItemPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereContentLibraryItemConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.vsphere_content_library_item.item", "id", "vsphere_content_library_item.item", "id"),
+					resource.TestCheckResourceAttr("data.vsphere_content_library_item.item", "type", "iso"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereContentLibraryItemConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_content_library_item" "item" {
+  name       = "${vsphere_content_library_item.item.name}"
+  library_id = "${vsphere_content_library.library.id}"
+}
+`,
+		testAccResourceVSphereContentLibraryItemConfig("terraform-test-item"),
+	)
+}
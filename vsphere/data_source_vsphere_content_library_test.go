@@ -0,0 +1,40 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereContentLibrary_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereContentLibraryPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereContentLibraryConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.vsphere_content_library.library", "id", "vsphere_content_library.library", "id"),
+					resource.TestCheckResourceAttrPair("data.vsphere_content_library.library", "datastore_id", "vsphere_content_library.library", "datastore_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereContentLibraryConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_content_library" "library" {
+  name = "${vsphere_content_library.library.name}"
+}
+`,
+		testAccResourceVSphereContentLibraryConfig("terraform-test-library", "Managed by Terraform"),
+	)
+}
@@ -1,6 +1,7 @@
 package vsphere
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -16,6 +17,26 @@ func dataSourceVSphereDatacenter() *schema.Resource {
 				Description: "The name of the datacenter. This can be a name or path.	Can be omitted if there is only one datacenter in your inventory.",
 				Optional: true,
 			},
+			"vm_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root VM folder of the datacenter.",
+				Computed:    true,
+			},
+			"host_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root host folder of the datacenter.",
+				Computed:    true,
+			},
+			"datastore_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root datastore folder of the datacenter.",
+				Computed:    true,
+			},
+			"network_folder_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the root network folder of the datacenter.",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -30,5 +51,16 @@ func dataSourceVSphereDatacenterRead(d *schema.ResourceData, meta interface{}) e
 	id := dc.Reference().Value
 	d.SetId(id)
 
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	folders, err := dc.Folders(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching datacenter folders: %s", err)
+	}
+	d.Set("vm_folder_id", folders.VmFolder.Reference().Value)
+	d.Set("host_folder_id", folders.HostFolder.Reference().Value)
+	d.Set("datastore_folder_id", folders.DatastoreFolder.Reference().Value)
+	d.Set("network_folder_id", folders.NetworkFolder.Reference().Value)
+
 	return nil
 }
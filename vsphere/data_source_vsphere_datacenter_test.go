@@ -27,6 +27,26 @@ func TestAccDataSourceVSphereDatacenter_basic(t *testing.T) {
 						"id",
 						testAccDataSourceVSphereDatacenterExpectedRegexp,
 					),
+					resource.TestMatchResourceAttr(
+						"data.vsphere_datacenter.dc",
+						"vm_folder_id",
+						regexp.MustCompile("^group-v"),
+					),
+					resource.TestMatchResourceAttr(
+						"data.vsphere_datacenter.dc",
+						"host_folder_id",
+						regexp.MustCompile("^group-h"),
+					),
+					resource.TestMatchResourceAttr(
+						"data.vsphere_datacenter.dc",
+						"datastore_folder_id",
+						regexp.MustCompile("^group-s"),
+					),
+					resource.TestMatchResourceAttr(
+						"data.vsphere_datacenter.dc",
+						"network_folder_id",
+						regexp.MustCompile("^group-n"),
+					),
 				),
 			},
 		},
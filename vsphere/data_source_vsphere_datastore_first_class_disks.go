@@ -0,0 +1,142 @@
+package vsphere
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereDatastoreFirstClassDisks() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereDatastoreFirstClassDisksRead,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore to list first class disks on.",
+				Required:    true,
+			},
+			"filter": {
+				Type:         schema.TypeString,
+				Description:  "A regular expression to filter the disks against. Only disks with names that match will be included.",
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Description: "The IDs of the first class disks found, in the same order as disks.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"disks": {
+				Type:        schema.TypeList,
+				Description: "The first class disks found, sorted by name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "The ID of the disk.",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the disk.",
+							Computed:    true,
+						},
+						"capacity_mb": {
+							Type:        schema.TypeInt,
+							Description: "The capacity of the disk, in MB.",
+							Computed:    true,
+						},
+						"file_path": {
+							Type:        schema.TypeString,
+							Description: "The datastore path of the file backing the disk.",
+							Computed:    true,
+						},
+						"create_time": {
+							Type:        schema.TypeString,
+							Description: "The time the disk was created, in RFC3339 format.",
+							Computed:    true,
+						},
+						"consumer_ids": {
+							Type:        schema.TypeList,
+							Description: "The IDs of the objects consuming the disk.",
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereDatastoreFirstClassDisksRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	dsID := d.Get("datastore_id").(string)
+	ds, err := datastore.FromID(client, dsID)
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+	objs, err := datastore.FirstClassDisks(client, ds)
+	if err != nil {
+		return fmt.Errorf("error listing first class disks: %s", err)
+	}
+	filter := regexp.MustCompile(d.Get("filter").(string))
+
+	var matched []types.VStorageObject
+	for _, obj := range objs {
+		if filter.MatchString(obj.Config.Name) {
+			matched = append(matched, obj)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Config.Name < matched[j].Config.Name })
+
+	var ids []string
+	var disks []interface{}
+	for _, obj := range matched {
+		ids = append(ids, obj.Config.Id.Id)
+		disks = append(disks, flattenVStorageObject(obj))
+	}
+
+	d.SetId(dsID)
+	if err := d.Set("ids", ids); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	if err := d.Set("disks", disks); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	return nil
+}
+
+// flattenVStorageObject returns a map for the disks attribute of the
+// vsphere_datastore_first_class_disks data source from a VStorageObject.
+func flattenVStorageObject(obj types.VStorageObject) map[string]interface{} {
+	var filePath string
+	if backing, ok := obj.Config.Backing.(types.BaseBaseConfigInfoFileBackingInfo); ok {
+		filePath = backing.GetBaseConfigInfoFileBackingInfo().FilePath
+	}
+	var consumers []string
+	for _, id := range obj.Config.ConsumerId {
+		consumers = append(consumers, id.Id)
+	}
+	return map[string]interface{}{
+		"id":           obj.Config.Id.Id,
+		"name":         obj.Config.Name,
+		"capacity_mb":  int(obj.Config.CapacityInMB),
+		"file_path":    filePath,
+		"create_time":  obj.Config.CreateTime.Format(time.RFC3339),
+		"consumer_ids": consumers,
+	}
+}
@@ -0,0 +1,198 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereDatastoreStats() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereDatastoreStatsRead,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the datastore to report the usage of.",
+				Optional:      true,
+				ConflictsWith: []string{"datastore_cluster_id"},
+			},
+			"datastore_cluster_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the datastore cluster to report the usage of.",
+				Optional:      true,
+				ConflictsWith: []string{"datastore_id"},
+			},
+			"capacity": {
+				Type:        schema.TypeInt,
+				Description: "The total capacity, in bytes.",
+				Computed:    true,
+			},
+			"free_space": {
+				Type:        schema.TypeInt,
+				Description: "The free space, in bytes.",
+				Computed:    true,
+			},
+			"uncommitted": {
+				Type:        schema.TypeInt,
+				Description: "The storage, in bytes, that thin provisioned disks and swap files may still take up.",
+				Computed:    true,
+			},
+			"virtual_machines": {
+				Type:        schema.TypeList,
+				Description: "The usage of each virtual machine on each datastore.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"datastore_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"committed": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"uncommitted": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"unshared": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereDatastoreStatsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	var id string
+	var refs []types.ManagedObjectReference
+	switch {
+	case d.Get("datastore_id").(string) != "":
+		id = d.Get("datastore_id").(string)
+		refs = []types.ManagedObjectReference{{Type: "Datastore", Value: id}}
+	case d.Get("datastore_cluster_id").(string) != "":
+		id = d.Get("datastore_cluster_id").(string)
+		pod, err := storagepod.FromID(client, id)
+		if err != nil {
+			return fmt.Errorf("cannot locate datastore cluster: %s", err)
+		}
+		refs, err = storagepod.Datastores(pod)
+		if err != nil {
+			return fmt.Errorf("error fetching datastores of datastore cluster %q: %s", pod.InventoryPath, err)
+		}
+	default:
+		return fmt.Errorf("one of datastore_id or datastore_cluster_id must be set")
+	}
+
+	var capacity, free, uncommitted int64
+	vms := make(map[types.ManagedObjectReference]bool)
+	var vmRefs []types.ManagedObjectReference
+	for _, ref := range refs {
+		props, err := dataSourceVSphereDatastoreStatsDatastoreProperties(client, ref.Value)
+		if err != nil {
+			return err
+		}
+		capacity += props.Summary.Capacity
+		free += props.Summary.FreeSpace
+		uncommitted += props.Summary.Uncommitted
+		for _, vm := range props.Vm {
+			if !vms[vm] {
+				vms[vm] = true
+				vmRefs = append(vmRefs, vm)
+			}
+		}
+	}
+
+	vmProps, err := virtualmachine.StorageProperties(client, vmRefs)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine storage usage: %s", err)
+	}
+	inScope := make(map[string]bool)
+	for _, ref := range refs {
+		inScope[ref.Value] = true
+	}
+	var result []map[string]interface{}
+	for _, vm := range vmProps {
+		if vm.Storage == nil {
+			continue
+		}
+		var uuid string
+		if vm.Config != nil {
+			uuid = vm.Config.Uuid
+		}
+		for _, usage := range vm.Storage.PerDatastoreUsage {
+			if !inScope[usage.Datastore.Value] {
+				continue
+			}
+			result = append(result, map[string]interface{}{
+				"id":           vm.Self.Value,
+				"uuid":         uuid,
+				"name":         vm.Name,
+				"datastore_id": usage.Datastore.Value,
+				"committed":    int(usage.Committed),
+				"uncommitted":  int(usage.Uncommitted),
+				"unshared":     int(usage.Unshared),
+			})
+		}
+	}
+	// Sort for a stable result, largest consumers first.
+	sort.Slice(result, func(i, j int) bool {
+		if result[i]["committed"].(int) != result[j]["committed"].(int) {
+			return result[i]["committed"].(int) > result[j]["committed"].(int)
+		}
+		return fmt.Sprint(result[i]["id"], result[i]["datastore_id"]) < fmt.Sprint(result[j]["id"], result[j]["datastore_id"])
+	})
+	log.Printf("[DEBUG] Found %d virtual machine usage entries on %q", len(result), id)
+	var usage []interface{}
+	for _, m := range result {
+		usage = append(usage, m)
+	}
+
+	d.SetId(id)
+	d.Set("capacity", int(capacity))
+	d.Set("free_space", int(free))
+	d.Set("uncommitted", int(uncommitted))
+	if err := d.Set("virtual_machines", usage); err != nil {
+		return fmt.Errorf("error setting virtual_machines: %s", err)
+	}
+	return nil
+}
+
+// dataSourceVSphereDatastoreStatsDatastoreProperties fetches the properties of
+// the datastore with the supplied ID.
+func dataSourceVSphereDatastoreStatsDatastoreProperties(client *govmomi.Client, id string) (*mo.Datastore, error) {
+	ds, err := datastore.FromID(client, id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate datastore %q: %s", id, err)
+	}
+	props, err := datastore.Properties(ds)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching properties of datastore %q: %s", ds.InventoryPath, err)
+	}
+	return props, nil
+}
@@ -0,0 +1,40 @@
+package vsphere
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereDatastoreStats_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereDatastoreStatsConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("data.vsphere_datastore_stats.stats", "capacity", regexp.MustCompile("^[1-9][0-9]*$")),
+					resource.TestMatchResourceAttr("data.vsphere_datastore_stats.stats", "virtual_machines.#", regexp.MustCompile("^[1-9][0-9]*$")),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereDatastoreStatsConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_datastore_stats" "stats" {
+  datastore_id = "${vsphere_virtual_machine.vm.datastore_id}"
+}
+`,
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+	)
+}
@@ -0,0 +1,191 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereDRSVMPlacement() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereDRSVMPlacementRead,
+
+		Schema: map[string]*schema.Schema{
+			"resource_pool_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of a resource pool in the cluster to request placement from.",
+				Required:    true,
+			},
+			"num_cpus": {
+				Type:         schema.TypeInt,
+				Description:  "The number of virtual processors of the candidate virtual machine.",
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"memory": {
+				Type:         schema.TypeInt,
+				Description:  "The size of the candidate virtual machine's memory, in MB.",
+				Optional:     true,
+				Default:      1024,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"guest_id": {
+				Type:        schema.TypeString,
+				Description: "The guest ID of the candidate virtual machine.",
+				Optional:    true,
+				Default:     "other-64",
+			},
+			"host_system_ids": {
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of the hosts to consider for placement. All hosts in the cluster are considered when this is not set.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"include_unavailable_hosts": {
+				Type:        schema.TypeBool,
+				Description: "Consider the hosts in the cluster that are in maintenance or quarantine mode when host_system_ids is not set.",
+				Optional:    true,
+			},
+			"datastore_ids": {
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of the datastores to consider for placement. All datastores available to the candidate hosts are considered when this is not set.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host recommended by DRS.",
+				Computed:    true,
+			},
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore recommended by DRS, if any.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereDRSVMPlacementRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	pprops, err := resourcepool.Properties(pool)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool properties: %s", err)
+	}
+	if pprops.Owner.Type != "ClusterComputeResource" {
+		return fmt.Errorf("resource pool %q is not in a cluster", poolID)
+	}
+	cluster, err := computeresource.ClusterFromID(client, pprops.Owner.Value)
+	if err != nil {
+		return fmt.Errorf("error fetching cluster for resource pool %q: %s", poolID, err)
+	}
+
+	poolRef := pool.Reference()
+	spec := types.PlacementSpec{
+		PlacementType: string(types.PlacementSpecPlacementTypeCreate),
+		ConfigSpec: &types.VirtualMachineConfigSpec{
+			NumCPUs:  int32(d.Get("num_cpus").(int)),
+			MemoryMB: int64(d.Get("memory").(int)),
+			GuestId:  d.Get("guest_id").(string),
+		},
+		RelocateSpec: &types.VirtualMachineRelocateSpec{
+			Pool: &poolRef,
+		},
+	}
+	for _, id := range structure.SliceInterfacesToStrings(d.Get("host_system_ids").([]interface{})) {
+		spec.Hosts = append(spec.Hosts, types.ManagedObjectReference{Type: "HostSystem", Value: id})
+	}
+	if len(spec.Hosts) == 0 && !d.Get("include_unavailable_hosts").(bool) {
+		cprops, err := computeresource.BaseProperties(cluster)
+		if err != nil {
+			return fmt.Errorf("error fetching cluster properties: %s", err)
+		}
+		spec.Hosts, err = hostsystem.FilterAvailable(client, cprops.Host)
+		if err != nil {
+			return fmt.Errorf("error fetching host properties: %s", err)
+		}
+		if len(spec.Hosts) == 0 {
+			return fmt.Errorf("all hosts in cluster %q are in maintenance or quarantine mode", cluster.Reference().Value)
+		}
+	}
+	for _, id := range structure.SliceInterfacesToStrings(d.Get("datastore_ids").([]interface{})) {
+		spec.Datastores = append(spec.Datastores, types.ManagedObjectReference{Type: "Datastore", Value: id})
+	}
+
+	result, err := computeresource.PlaceVM(cluster, spec)
+	if err != nil {
+		return fmt.Errorf("error requesting placement from cluster %q: %s", cluster.Reference().Value, err)
+	}
+	action, err := dataSourceVSphereDRSVMPlacementAction(result)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Placement recommendation for cluster %q: host %q", cluster.Reference().Value, action.TargetHost.Value)
+
+	d.SetId(poolID)
+	d.Set("host_system_id", action.TargetHost.Value)
+	var dsID string
+	if action.RelocateSpec != nil && action.RelocateSpec.Datastore != nil {
+		dsID = action.RelocateSpec.Datastore.Value
+	}
+	d.Set("datastore_id", dsID)
+	return nil
+}
+
+// dataSourceVSphereDRSVMPlacementAction returns the placement action of the
+// highest rated recommendation in a PlacementResult.
+func dataSourceVSphereDRSVMPlacementAction(result *types.PlacementResult) (*types.PlacementAction, error) {
+	var best *types.ClusterRecommendation
+	for i := range result.Recommendations {
+		r := &result.Recommendations[i]
+		if best == nil || r.Rating > best.Rating {
+			best = r
+		}
+	}
+	if best == nil {
+		if result.DrsFault != nil && len(result.DrsFault.FaultsByVm) > 0 {
+			return nil, fmt.Errorf("DRS could not place the virtual machine: %s", dataSourceVSphereDRSVMPlacementFaults(result.DrsFault))
+		}
+		return nil, errors.New("DRS returned no placement recommendations")
+	}
+	for _, a := range best.Action {
+		if pa, ok := a.(*types.PlacementAction); ok && pa.TargetHost != nil {
+			return pa, nil
+		}
+	}
+	return nil, fmt.Errorf("recommendation %q does not contain a placement action", best.Key)
+}
+
+// dataSourceVSphereDRSVMPlacementFaults returns a string describing the
+// faults in a ClusterDrsFaults, for use in error messages.
+func dataSourceVSphereDRSVMPlacementFaults(faults *types.ClusterDrsFaults) string {
+	var s string
+	for _, vf := range faults.FaultsByVm {
+		for _, f := range vf.GetClusterDrsFaultsFaultsByVm().Fault {
+			if s != "" {
+				s += "; "
+			}
+			s += f.LocalizedMessage
+		}
+	}
+	return s
+}
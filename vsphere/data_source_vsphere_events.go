@@ -0,0 +1,272 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/event"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// eventsPageSize is the number of events read from the event history
+// collector in one call.
+const eventsPageSize = 100
+
+var eventsRecursionAllowedValues = []string{
+	string(types.EventFilterSpecRecursionOptionSelf),
+	string(types.EventFilterSpecRecursionOptionChildren),
+	string(types.EventFilterSpecRecursionOptionAll),
+}
+
+func dataSourceVSphereEvents() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereEventsRead,
+
+		Schema: map[string]*schema.Schema{
+			"entity_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the entity to read the events of. If not set, the events of all entities are read.",
+				Optional:    true,
+			},
+			"entity_type": {
+				Type:         schema.TypeString,
+				Description:  "The managed object type of the entity, such as VirtualMachine or Folder. Required if entity_id is set.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(entityPermissionEntityTypeAllowedValues, false),
+			},
+			"recursion": {
+				Type:         schema.TypeString,
+				Description:  "Which events to read for the entity. Can be one of self, children, or all.",
+				Optional:     true,
+				Default:      string(types.EventFilterSpecRecursionOptionSelf),
+				ValidateFunc: validation.StringInSlice(eventsRecursionAllowedValues, false),
+			},
+			"event_types": {
+				Type:        schema.TypeList,
+				Description: "The names of the event types to read, such as VmReconfiguredEvent. If not set, events of all types are read.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"begin_time": {
+				Type:          schema.TypeString,
+				Description:   "Only read events created at or after this time, in RFC3339 format.",
+				Optional:      true,
+				ConflictsWith: []string{"max_age"},
+				ValidateFunc:  validateEventsTime,
+			},
+			"end_time": {
+				Type:         schema.TypeString,
+				Description:  "Only read events created at or before this time, in RFC3339 format.",
+				Optional:     true,
+				ValidateFunc: validateEventsTime,
+			},
+			"max_age": {
+				Type:          schema.TypeString,
+				Description:   "Only read events created within this duration before now, such as 24h.",
+				Optional:      true,
+				ConflictsWith: []string{"begin_time"},
+				ValidateFunc:  validateEventsDuration,
+			},
+			"user_name": {
+				Type:        schema.TypeString,
+				Description: "Only read events caused by this user.",
+				Optional:    true,
+			},
+			"max_events": {
+				Type:         schema.TypeInt,
+				Description:  "The maximum number of events to read. The most recent events are read first.",
+				Optional:     true,
+				Default:      1000,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"events": {
+				Type:        schema.TypeList,
+				Description: "The events found, most recent first.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"chain_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"message": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereEventsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	filter, err := expandEventFilterSpec(d)
+	if err != nil {
+		return err
+	}
+	events, err := readEvents(client, filter, d.Get("max_events").(int))
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Found %d events", len(events))
+
+	var result []interface{}
+	for _, be := range events {
+		e := be.GetEvent()
+		result = append(result, map[string]interface{}{
+			"key":          int(e.Key),
+			"chain_id":     int(e.ChainId),
+			"type":         reflect.TypeOf(be).Elem().Name(),
+			"created_time": e.CreatedTime.Format(time.RFC3339),
+			"user_name":    e.UserName,
+			"message":      e.FullFormattedMessage,
+		})
+	}
+
+	id := "all"
+	if filter.Entity != nil {
+		id = fmt.Sprintf("%s:%s", filter.Entity.Entity.Type, filter.Entity.Entity.Value)
+	}
+	d.SetId(id)
+	if err := d.Set("events", result); err != nil {
+		return fmt.Errorf("error setting events: %s", err)
+	}
+	return nil
+}
+
+// readEvents reads up to max events that match filter, most recent first.
+func readEvents(client *govmomi.Client, filter types.EventFilterSpec, max int) ([]types.BaseEvent, error) {
+	mgr := event.NewManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	collector, err := mgr.CreateCollectorForEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error creating event collector: %s", err)
+	}
+	defer func() {
+		if err := collector.Destroy(context.Background()); err != nil {
+			log.Printf("[DEBUG] Error destroying event collector: %s", err)
+		}
+	}()
+
+	// The scrollable view of a new collector is positioned right before its
+	// latest page, so the latest page is read first, and older events are
+	// read backwards from there.
+	events, err := collector.LatestPage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading events: %s", err)
+	}
+	for len(events) < max {
+		count := max - len(events)
+		if count > eventsPageSize {
+			count = eventsPageSize
+		}
+		page, err := collector.ReadPreviousEvents(ctx, int32(count))
+		if err != nil {
+			return nil, fmt.Errorf("error reading events: %s", err)
+		}
+		if len(page) < 1 {
+			break
+		}
+		events = append(events, page...)
+	}
+	// Event keys increase over time, so sorting on them puts the most recent
+	// event first.
+	sort.Slice(events, func(i, j int) bool { return events[i].GetEvent().Key > events[j].GetEvent().Key })
+	if len(events) > max {
+		events = events[:max]
+	}
+	return events, nil
+}
+
+// expandEventFilterSpec reads the filter settings of the vsphere_events data
+// source into an EventFilterSpec.
+func expandEventFilterSpec(d *schema.ResourceData) (types.EventFilterSpec, error) {
+	var filter types.EventFilterSpec
+	id := d.Get("entity_id").(string)
+	typ := d.Get("entity_type").(string)
+	switch {
+	case id != "" && typ != "":
+		filter.Entity = &types.EventFilterSpecByEntity{
+			Entity:    types.ManagedObjectReference{Type: typ, Value: id},
+			Recursion: types.EventFilterSpecRecursionOption(d.Get("recursion").(string)),
+		}
+	case id != "" || typ != "":
+		return filter, fmt.Errorf("entity_id and entity_type must be set together")
+	}
+	filter.Type = structure.SliceInterfacesToStrings(d.Get("event_types").([]interface{}))
+	if v, ok := d.GetOk("user_name"); ok {
+		filter.UserName = &types.EventFilterSpecByUsername{
+			UserList: []string{v.(string)},
+		}
+	}
+
+	var begin, end *time.Time
+	if v, ok := d.GetOk("begin_time"); ok {
+		t, _ := time.Parse(time.RFC3339, v.(string))
+		begin = &t
+	}
+	if v, ok := d.GetOk("max_age"); ok {
+		age, _ := time.ParseDuration(v.(string))
+		t := time.Now().Add(-age)
+		begin = &t
+	}
+	if v, ok := d.GetOk("end_time"); ok {
+		t, _ := time.Parse(time.RFC3339, v.(string))
+		end = &t
+	}
+	if begin != nil || end != nil {
+		filter.Time = &types.EventFilterSpecByTime{
+			BeginTime: begin,
+			EndTime:   end,
+		}
+	}
+	return filter, nil
+}
+
+// validateEventsTime checks that a value is a time in RFC3339 format.
+func validateEventsTime(v interface{}, k string) ([]string, []error) {
+	if _, err := time.Parse(time.RFC3339, v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s: invalid RFC3339 time %q: %s", k, v.(string), err)}
+	}
+	return nil, nil
+}
+
+// validateEventsDuration checks that a value is a positive duration, as
+// understood by time.ParseDuration.
+func validateEventsDuration(v interface{}, k string) ([]string, []error) {
+	d, err := time.ParseDuration(v.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: invalid duration %q: %s", k, v.(string), err)}
+	}
+	if d <= 0 {
+		return nil, []error{fmt.Errorf("%s: duration must be positive", k)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,51 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereEvents_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereDatacenterPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereEventsConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("data.vsphere_events.events", "events.#", regexp.MustCompile("^([1-9]|10)$")),
+					resource.TestCheckResourceAttrSet("data.vsphere_events.events", "events.0.type"),
+					resource.TestCheckResourceAttrSet("data.vsphere_events.events", "events.0.created_time"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereEventsConfig() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_events" "events" {
+  entity_id   = "${data.vsphere_datacenter.dc.id}"
+  entity_type = "Datacenter"
+  recursion   = "all"
+  max_events  = 10
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
@@ -0,0 +1,121 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereGuestOSIDs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereGuestOSIDsRead,
+
+		Schema: map[string]*schema.Schema{
+			"resource_pool_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the resource pool to look up supported guest operating systems for. The list is taken from the standalone host or cluster that owns the resource pool.",
+				Required:    true,
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of a host in the resource pool's cluster to restrict the list to.",
+				Optional:    true,
+			},
+			"hardware_version": {
+				Type:         schema.TypeInt,
+				Description:  "The virtual machine hardware version to look up supported guest operating systems for. The newest hardware version is used when this is not set.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(4),
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Description: "The guest IDs of the supported guest operating systems, sorted by ID.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"guest_os": {
+				Type:        schema.TypeList,
+				Description: "Details of the supported guest operating systems, sorted by ID.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The guest ID of the operating system.",
+						},
+						"full_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The full name of the operating system.",
+						},
+						"family": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The family of the operating system.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereGuestOSIDsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	var hs *object.HostSystem
+	if v, ok := d.GetOk("host_system_id"); ok {
+		hsID := v.(string)
+		if hs, err = hostsystem.FromID(client, hsID); err != nil {
+			return fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
+		}
+	}
+	var key string
+	if v, ok := d.GetOk("hardware_version"); ok {
+		key = viapi.HardwareVersionKey(v.(int))
+	}
+
+	descs, err := resourcepool.GuestOSDescriptors(client, pool, key, hs)
+	if err != nil {
+		return fmt.Errorf("error fetching supported guest operating systems: %s", err)
+	}
+
+	d.SetId(poolID)
+	return flattenGuestOsDescriptors(d, descs)
+}
+
+// flattenGuestOsDescriptors saves a list of GuestOsDescriptor to the ids and
+// guest_os attributes, sorted by guest ID.
+func flattenGuestOsDescriptors(d *schema.ResourceData, descs []types.GuestOsDescriptor) error {
+	sort.Slice(descs, func(i, j int) bool { return descs[i].Id < descs[j].Id })
+	var ids []string
+	var guests []map[string]interface{}
+	for _, desc := range descs {
+		ids = append(ids, desc.Id)
+		guests = append(guests, map[string]interface{}{
+			"id":        desc.Id,
+			"full_name": desc.FullName,
+			"family":    desc.Family,
+		})
+	}
+	if err := d.Set("ids", ids); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	if err := d.Set("guest_os", guests); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	return nil
+}
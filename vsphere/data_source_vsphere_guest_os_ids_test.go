@@ -0,0 +1,91 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereGuestOSIDs_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereGuestOSIDsPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereGuestOSIDsConfig(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("found", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceVSphereGuestOSIDs_hardwareVersion(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereGuestOSIDsPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereGuestOSIDsConfig("hardware_version = 10"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("found", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereGuestOSIDsPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_guest_os_ids acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_guest_os_ids acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_guest_os_ids acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereGuestOSIDsConfig(extra string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_guest_os_ids" "guests" {
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  %s
+}
+
+output "found" {
+  value = "${contains(data.vsphere_guest_os_ids.guests.ids, "otherGuest64")}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		extra,
+	)
+}
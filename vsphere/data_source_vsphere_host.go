@@ -22,6 +22,21 @@ func dataSourceVSphereHost() *schema.Resource {
 				Description: "The managed object ID of the datacenter to look for the host in.",
 				Required:    true,
 			},
+			"num_cpu_cores": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The number of physical CPU cores of the host.",
+				Computed:    true,
+			},
+			"num_cpu_threads": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The number of logical processors of the host.",
+				Computed:    true,
+			},
+			"numa_node_count": &schema.Schema{
+				Type:        schema.TypeInt,
+				Description: "The number of NUMA nodes of the host.",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -42,5 +57,17 @@ func dataSourceVSphereHostRead(d *schema.ResourceData, meta interface{}) error {
 	id := hs.Reference().Value
 	d.SetId(id)
 
+	props, err := hostsystem.Properties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.Hardware != nil {
+		d.Set("num_cpu_cores", props.Hardware.CpuInfo.NumCpuCores)
+		d.Set("num_cpu_threads", props.Hardware.CpuInfo.NumCpuThreads)
+		if props.Hardware.NumaInfo != nil {
+			d.Set("numa_node_count", props.Hardware.NumaInfo.NumNodes)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+func dataSourceVSphereHostDatastores() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereHostDatastoresRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to look for local datastores on.",
+				Required:    true,
+			},
+			"filter": {
+				Type:         schema.TypeString,
+				Description:  "A regular expression to filter the datastores against. Only datastores with names that match will be included.",
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of the local datastores found, in the same order as names.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Description: "The names of the local datastores found, sorted lexicographically.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereHostDatastoresRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return err
+	}
+	hprops, err := hostsystem.Properties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	filter := regexp.MustCompile(d.Get("filter").(string))
+
+	var local []*mo.Datastore
+	for _, ref := range hprops.Datastore {
+		props, err := datastore.Properties(object.NewDatastore(client.Client, ref))
+		if err != nil {
+			return fmt.Errorf("error fetching properties for datastore %q: %s", ref.Value, err)
+		}
+		if !datastoreIsHostLocal(props) {
+			log.Printf("[DEBUG] Skipping datastore %q: datastore is shared or in a datastore cluster", props.Name)
+			continue
+		}
+		if !filter.MatchString(props.Name) {
+			continue
+		}
+		local = append(local, props)
+	}
+	sort.Slice(local, func(i, j int) bool { return local[i].Name < local[j].Name })
+
+	var ids, names []string
+	for _, props := range local {
+		ids = append(ids, props.Reference().Value)
+		names = append(names, props.Name)
+	}
+
+	d.SetId(hsID)
+	if err := d.Set("ids", ids); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	if err := d.Set("names", names); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	return nil
+}
+
+// datastoreIsHostLocal returns true if a datastore is only accessible from a
+// single host, and is not a member of a datastore cluster.
+func datastoreIsHostLocal(props *mo.Datastore) bool {
+	if props.Summary.MultipleHostAccess != nil && *props.Summary.MultipleHostAccess {
+		return false
+	}
+	if len(props.Host) > 1 {
+		return false
+	}
+	if props.Parent != nil && props.Parent.Type == "StoragePod" {
+		return false
+	}
+	return true
+}
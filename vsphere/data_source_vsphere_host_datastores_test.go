@@ -0,0 +1,73 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereHostDatastores_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereHostDatastoresPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereHostDatastoresConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("found", "true"),
+					resource.TestCheckOutput("shared_excluded", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereHostDatastoresPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_host_datastores acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_datastores acceptance tests")
+	}
+	if os.Getenv("VSPHERE_LOCAL_DATASTORE") == "" {
+		t.Skip("set VSPHERE_LOCAL_DATASTORE to run vsphere_host_datastores acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_host_datastores acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereHostDatastoresConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_host_datastores" "local" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+}
+
+output "found" {
+  value = "${contains(data.vsphere_host_datastores.local.names, "%s")}"
+}
+
+output "shared_excluded" {
+  value = "${contains(data.vsphere_host_datastores.local.names, "%s") ? "false" : "true"}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_LOCAL_DATASTORE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
@@ -0,0 +1,331 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// vgpuProfileMatcher matches NVIDIA GRID vGPU profile names, like
+// grid_p40-2q, capturing the board and the frame buffer size in GB.
+var vgpuProfileMatcher = regexp.MustCompile(`^grid_([a-z0-9]+)-(\d+)[a-z]*$`)
+
+// hostGPUSharedTypes are the graphics types of GPUs that can run vGPU
+// profiles.
+var hostGPUSharedTypes = map[string]bool{
+	"shared":       true,
+	"sharedDirect": true,
+}
+
+func dataSourceVSphereHostGPUs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereHostGPUsRead,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:          schema.TypeString,
+				Description:   "The managed object ID of the host to list GPUs for.",
+				Optional:      true,
+				ConflictsWith: []string{"compute_resource"},
+			},
+			"compute_resource": {
+				Type:          schema.TypeString,
+				Description:   "The name or path of a cluster or standalone host to list GPUs for.",
+				Optional:      true,
+				ConflictsWith: []string{"host_system_id"},
+			},
+			"datacenter_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datacenter to look for compute_resource in.",
+				Optional:    true,
+			},
+			"include_unavailable_hosts": {
+				Type:        schema.TypeBool,
+				Description: "Include the hosts of compute_resource that are in maintenance or quarantine mode.",
+				Optional:    true,
+			},
+			"gpus": {
+				Type:        schema.TypeList,
+				Description: "The GPUs found, sorted by host and PCI ID.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host_system_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"device_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vendor_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"pci_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"graphics_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"memory": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"virtual_machine_ids": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"vgpu_profile": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"vgpu_profiles": {
+				Type:        schema.TypeList,
+				Description: "The vGPU profiles available on each host, sorted by host and name.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host_system_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"frame_buffer": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"remaining": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereHostGPUsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	var hosts []types.ManagedObjectReference
+	var id string
+	switch {
+	case d.Get("host_system_id").(string) != "":
+		id = d.Get("host_system_id").(string)
+		hosts = append(hosts, types.ManagedObjectReference{Type: "HostSystem", Value: id})
+	case d.Get("compute_resource").(string) != "":
+		var dc *object.Datacenter
+		if dcID, ok := d.GetOk("datacenter_id"); ok {
+			var err error
+			dc, err = datacenterFromID(client, dcID.(string))
+			if err != nil {
+				return fmt.Errorf("cannot locate datacenter: %s", err)
+			}
+		}
+		cr, err := computeresource.BaseFromPath(client, d.Get("compute_resource").(string), dc)
+		if err != nil {
+			return fmt.Errorf("error fetching compute resource: %s", err)
+		}
+		props, err := computeresource.BaseProperties(cr)
+		if err != nil {
+			return fmt.Errorf("error fetching compute resource properties: %s", err)
+		}
+		id = cr.Reference().Value
+		hosts = props.Host
+		if !d.Get("include_unavailable_hosts").(bool) {
+			hosts, err = hostsystem.FilterAvailable(client, hosts)
+			if err != nil {
+				return fmt.Errorf("error fetching host properties: %s", err)
+			}
+		}
+	default:
+		return fmt.Errorf("one of host_system_id or compute_resource must be set")
+	}
+
+	var gpus, profiles []interface{}
+	for _, ref := range hosts {
+		hg, hp, err := dataSourceVSphereHostGPUsForHost(client, ref.Value)
+		if err != nil {
+			return err
+		}
+		gpus = append(gpus, hg...)
+		profiles = append(profiles, hp...)
+	}
+
+	d.SetId(id)
+	if err := d.Set("gpus", gpus); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	if err := d.Set("vgpu_profiles", profiles); err != nil {
+		return fmt.Errorf("error saving results to state: %s", err)
+	}
+	return nil
+}
+
+// dataSourceVSphereHostGPUsForHost returns the GPUs and vGPU profiles of a
+// single host, in the format saved to state.
+func dataSourceVSphereHostGPUsForHost(client *govmomi.Client, hsID string) ([]interface{}, []interface{}, error) {
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return nil, nil, err
+	}
+	hprops, err := hostsystem.Properties(hs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if hprops.Config == nil {
+		log.Printf("[DEBUG] Host %q has no configuration, skipping", hsID)
+		return nil, nil, nil
+	}
+
+	infos := hprops.Config.GraphicsInfo
+	sort.Slice(infos, func(i, j int) bool { return infos[i].PciId < infos[j].PciId })
+
+	var gpus []interface{}
+	inUse := make([]string, len(infos))
+	used := make([]int, len(infos))
+	for i, info := range infos {
+		var vmIDs []string
+		for _, ref := range info.Vm {
+			vmIDs = append(vmIDs, ref.Value)
+			profile, err := dataSourceVSphereHostGPUsVirtualMachineProfile(client, ref)
+			if err != nil {
+				return nil, nil, err
+			}
+			if profile != "" {
+				inUse[i] = profile
+				used[i]++
+			}
+		}
+		gpus = append(gpus, map[string]interface{}{
+			"host_system_id":      hsID,
+			"device_name":         info.DeviceName,
+			"vendor_name":         info.VendorName,
+			"pci_id":              info.PciId,
+			"graphics_type":       info.GraphicsType,
+			"memory":              int(info.MemorySizeInKB / 1024),
+			"virtual_machine_ids": vmIDs,
+			"vgpu_profile":        inUse[i],
+		})
+	}
+
+	names := append([]string{}, hprops.Config.SharedPassthruGpuTypes...)
+	sort.Strings(names)
+	var profiles []interface{}
+	for _, name := range names {
+		fb, remaining := dataSourceVSphereHostGPUsProfileCapacity(name, infos, inUse, used)
+		profiles = append(profiles, map[string]interface{}{
+			"host_system_id": hsID,
+			"name":           name,
+			"frame_buffer":   fb,
+			"remaining":      remaining,
+		})
+	}
+	return gpus, profiles, nil
+}
+
+// dataSourceVSphereHostGPUsVirtualMachineProfile returns the vGPU profile of
+// the first vGPU of a virtual machine, or an empty string if the virtual
+// machine has no vGPU.
+func dataSourceVSphereHostGPUsVirtualMachineProfile(client *govmomi.Client, ref types.ManagedObjectReference) (string, error) {
+	vm, err := virtualmachine.FromMOID(client, ref.Value)
+	if err != nil {
+		return "", fmt.Errorf("error locating virtual machine %q: %s", ref.Value, err)
+	}
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return "", fmt.Errorf("error fetching properties for virtual machine %q: %s", ref.Value, err)
+	}
+	return vgpuProfileFromVirtualMachine(props), nil
+}
+
+// vgpuProfileFromVirtualMachine returns the vGPU profile of the first vGPU in
+// the properties of a virtual machine, or an empty string if there is none.
+func vgpuProfileFromVirtualMachine(props *mo.VirtualMachine) string {
+	if props.Config == nil {
+		return ""
+	}
+	for _, dev := range props.Config.Hardware.Device {
+		pt, ok := dev.(*types.VirtualPCIPassthrough)
+		if !ok {
+			continue
+		}
+		if b, ok := pt.Backing.(*types.VirtualPCIPassthroughVmiopBackingInfo); ok {
+			return b.Vgpu
+		}
+	}
+	return ""
+}
+
+// dataSourceVSphereHostGPUsProfileCapacity returns the frame buffer size in
+// MB of a vGPU profile, and the number of additional vGPUs with this profile
+// that the GPUs of a host can run. A GPU runs vGPUs of a single profile at a
+// time, so only GPUs that are free or already running the profile are
+// counted. The frame buffer size is taken from the NVIDIA GRID profile name,
+// and -1 is returned for both values if the name is not in that format.
+func dataSourceVSphereHostGPUsProfileCapacity(name string, infos []types.HostGraphicsInfo, inUse []string, used []int) (int, int) {
+	m := vgpuProfileMatcher.FindStringSubmatch(name)
+	if m == nil {
+		return -1, -1
+	}
+	board := m[1]
+	fbGB, _ := strconv.Atoi(m[2])
+	if fbGB < 1 {
+		return -1, -1
+	}
+
+	var remaining int
+	for i, info := range infos {
+		if !hostGPUSharedTypes[info.GraphicsType] {
+			continue
+		}
+		if !vgpuBoardMatchesDevice(board, info.DeviceName) {
+			continue
+		}
+		if inUse[i] != "" && inUse[i] != name {
+			continue
+		}
+		if free := int(info.MemorySizeInKB/1024/1024)/fbGB - used[i]; free > 0 {
+			remaining += free
+		}
+	}
+	return fbGB * 1024, remaining
+}
+
+// vgpuBoardMatchesDevice returns true if the board part of a vGPU profile
+// name, like p40, is part of a GPU device name, like "NVIDIA Tesla P40".
+func vgpuBoardMatchesDevice(board, device string) bool {
+	normalized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, device)
+	return strings.Contains(normalized, board)
+}
@@ -0,0 +1,86 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccDataSourceVSphereHostGPUs_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereHostGPUsPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereHostGPUsConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.vsphere_host_gpus.gpus", "gpus.#"),
+					resource.TestCheckResourceAttrSet("data.vsphere_host_gpus.gpus", "vgpu_profiles.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceVSphereHostGPUsProfileCapacity(t *testing.T) {
+	infos := []types.HostGraphicsInfo{
+		{DeviceName: "NVIDIA Tesla P40", GraphicsType: "shared", MemorySizeInKB: 24 * 1024 * 1024},
+		{DeviceName: "NVIDIA Tesla P40", GraphicsType: "shared", MemorySizeInKB: 24 * 1024 * 1024},
+		{DeviceName: "NVIDIA Tesla T4", GraphicsType: "shared", MemorySizeInKB: 16 * 1024 * 1024},
+		{DeviceName: "NVIDIA Tesla P40", GraphicsType: "direct", MemorySizeInKB: 24 * 1024 * 1024},
+	}
+	inUse := []string{"grid_p40-4q", "", "", ""}
+	used := []int{2, 0, 0, 0}
+
+	cases := []struct {
+		name      string
+		fb        int
+		remaining int
+	}{
+		{"grid_p40-4q", 4096, 10},
+		{"grid_p40-2q", 2048, 12},
+		{"grid_t4-8c", 8192, 2},
+		{"custom", -1, -1},
+	}
+	for _, tc := range cases {
+		fb, remaining := dataSourceVSphereHostGPUsProfileCapacity(tc.name, infos, inUse, used)
+		if fb != tc.fb || remaining != tc.remaining {
+			t.Fatalf("%s: expected %d/%d, got %d/%d", tc.name, tc.fb, tc.remaining, fb, remaining)
+		}
+	}
+}
+
+func testAccDataSourceVSphereHostGPUsPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_host_gpus acceptance tests")
+	}
+	if os.Getenv("VSPHERE_GPU_HOST") == "" {
+		t.Skip("set VSPHERE_GPU_HOST to run vsphere_host_gpus acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereHostGPUsConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_host_gpus" "gpus" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_GPU_HOST"),
+	)
+}
@@ -0,0 +1,259 @@
+package vsphere
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ovf"
+)
+
+func dataSourceVSphereOvfVMTemplate() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereOvfVMTemplateRead,
+
+		Schema: map[string]*schema.Schema{
+			"local_ovf_path": {
+				Type:          schema.TypeString,
+				Description:   "The path to a local OVF or OVA file.",
+				Optional:      true,
+				ConflictsWith: []string{"remote_ovf_url"},
+			},
+			"remote_ovf_url": {
+				Type:          schema.TypeString,
+				Description:   "The URL of a remote OVF or OVA file.",
+				Optional:      true,
+				ConflictsWith: []string{"local_ovf_path"},
+			},
+			"allow_unverified_ssl_cert": {
+				Type:        schema.TypeBool,
+				Description: "Allow unverified SSL certificates when fetching remote_ovf_url.",
+				Optional:    true,
+				Default:     false,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the virtual system in the OVF.",
+				Computed:    true,
+			},
+			"annotation": {
+				Type:        schema.TypeString,
+				Description: "The annotation of the virtual system.",
+				Computed:    true,
+			},
+			"guest_id": {
+				Type:        schema.TypeString,
+				Description: "The guest ID of the virtual system.",
+				Computed:    true,
+			},
+			"firmware": {
+				Type:        schema.TypeString,
+				Description: "The firmware of the virtual system.",
+				Computed:    true,
+			},
+			"hardware_version": {
+				Type:        schema.TypeInt,
+				Description: "The highest virtual hardware version listed in the OVF.",
+				Computed:    true,
+			},
+			"num_cpus": {
+				Type:        schema.TypeInt,
+				Description: "The number of virtual CPUs.",
+				Computed:    true,
+			},
+			"memory": {
+				Type:        schema.TypeInt,
+				Description: "The memory size in MB.",
+				Computed:    true,
+			},
+			"disks": {
+				Type:        schema.TypeList,
+				Description: "The disks of the virtual system, in the order of the virtual hardware section.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"label": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"network_names": {
+				Type:        schema.TypeList,
+				Description: "The networks in the network section of the OVF.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"network_interfaces": {
+				Type:        schema.TypeList,
+				Description: "The network interfaces of the virtual system, in the order of the virtual hardware section.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"network_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"adapter_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"properties": {
+				Type:        schema.TypeList,
+				Description: "The properties in the product sections of the OVF.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"default_value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"label": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_configurable": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"password": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereOvfVMTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	var e *ovf.Envelope
+	var err error
+	var id string
+	switch {
+	case d.Get("local_ovf_path").(string) != "":
+		id = d.Get("local_ovf_path").(string)
+		e, err = ovf.FromFile(id)
+	case d.Get("remote_ovf_url").(string) != "":
+		id = d.Get("remote_ovf_url").(string)
+		client := &http.Client{Timeout: ovf.RemoteTimeout}
+		if d.Get("allow_unverified_ssl_cert").(bool) {
+			client.Transport = &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}
+		}
+		e, err = ovf.FromURL(client, id)
+	default:
+		return fmt.Errorf("one of local_ovf_path or remote_ovf_url must be set")
+	}
+	if err != nil {
+		return fmt.Errorf("error reading OVF %q: %s", id, err)
+	}
+
+	memory, err := e.MemoryMB()
+	if err != nil {
+		return err
+	}
+	vdisks, err := e.VirtualDisks()
+	if err != nil {
+		return err
+	}
+	var disks []interface{}
+	for _, vd := range vdisks {
+		disks = append(disks, map[string]interface{}{
+			"label": vd.Name,
+			// Sizes are rounded up to the next GB, as this is the unit used for
+			// disks in vsphere_virtual_machine.
+			"size": int((vd.Capacity + (1 << 30) - 1) / (1 << 30)),
+		})
+	}
+	var networkNames []string
+	for _, n := range e.Networks {
+		networkNames = append(networkNames, n.Name)
+	}
+	var nics []interface{}
+	for _, item := range e.EthernetAdapters() {
+		nics = append(nics, map[string]interface{}{
+			"network_name": item.Connection,
+			"adapter_type": strings.ToLower(item.ResourceSubType),
+		})
+	}
+	var props []interface{}
+	for _, ps := range e.VirtualSystem.Products {
+		for _, p := range ps.Properties {
+			key := p.Key
+			// Properties in product sections with a class or instance are
+			// addressed as class.key.instance.
+			if ps.Class != "" {
+				key = ps.Class + "." + key
+			}
+			if ps.Instance != "" {
+				key = key + "." + ps.Instance
+			}
+			props = append(props, map[string]interface{}{
+				"key":               key,
+				"type":              p.Type,
+				"default_value":     p.Default,
+				"label":             p.Label,
+				"description":       strings.TrimSpace(p.Description),
+				"user_configurable": p.UserConfigurable != nil && *p.UserConfigurable,
+				"password":          p.Password != nil && *p.Password,
+			})
+		}
+	}
+
+	d.SetId(id)
+	name := e.VirtualSystem.Name
+	if name == "" {
+		name = e.VirtualSystem.ID
+	}
+	d.Set("name", name)
+	d.Set("annotation", e.VirtualSystem.Annotation)
+	d.Set("guest_id", e.VirtualSystem.OperatingSystem.OSType)
+	// Descriptors without a firmware setting are for BIOS virtual machines.
+	firmware := e.Firmware()
+	if firmware == "" {
+		firmware = "bios"
+	}
+	d.Set("firmware", firmware)
+	d.Set("hardware_version", e.HardwareVersion())
+	d.Set("num_cpus", e.NumCPUs())
+	d.Set("memory", memory)
+	if err := d.Set("disks", disks); err != nil {
+		return fmt.Errorf("error setting disks: %s", err)
+	}
+	if err := d.Set("network_names", networkNames); err != nil {
+		return fmt.Errorf("error setting network_names: %s", err)
+	}
+	if err := d.Set("network_interfaces", nics); err != nil {
+		return fmt.Errorf("error setting network_interfaces: %s", err)
+	}
+	if err := d.Set("properties", props); err != nil {
+		return fmt.Errorf("error setting properties: %s", err)
+	}
+	return nil
+}
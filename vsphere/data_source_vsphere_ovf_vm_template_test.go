@@ -0,0 +1,47 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereOvfVMTemplate_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereOvfVMTemplatePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereOvfVMTemplateConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("data.vsphere_ovf_vm_template.ovf", "num_cpus", regexp.MustCompile("^[1-9][0-9]*$")),
+					resource.TestMatchResourceAttr("data.vsphere_ovf_vm_template.ovf", "memory", regexp.MustCompile("^[1-9][0-9]*$")),
+					resource.TestMatchResourceAttr("data.vsphere_ovf_vm_template.ovf", "disks.0.size", regexp.MustCompile("^[1-9][0-9]*$")),
+					resource.TestCheckResourceAttrSet("data.vsphere_ovf_vm_template.ovf", "guest_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereOvfVMTemplatePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_OVF_LOCAL_PATH") == "" {
+		t.Skip("set VSPHERE_OVF_LOCAL_PATH to run vsphere_ovf_vm_template acceptance tests")
+	}
+}
+
+func testAccDataSourceVSphereOvfVMTemplateConfig() string {
+	return fmt.Sprintf(`
+data "vsphere_ovf_vm_template" "ovf" {
+  local_ovf_path = "%s"
+}
+`,
+		os.Getenv("VSPHERE_OVF_LOCAL_PATH"),
+	)
+}
@@ -4,27 +4,72 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 func dataSourceVSphereResourcePool() *schema.Resource {
-	return &schema.Resource{
-		Read: dataSourceVSphereResourcePoolRead,
-
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:        schema.TypeString,
-				Description: "The name or path of the resource pool.",
-				Optional:    true,
-			},
-			"datacenter_id": {
-				Type:        schema.TypeString,
-				Description: "The managed object ID of the datacenter the resource pool is in. This is not required when using ESXi directly, or if there is only one datacenter in your infrastructure.",
-				Optional:    true,
-			},
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:          schema.TypeString,
+			Description:   "The name or path of the resource pool.",
+			Optional:      true,
+			ConflictsWith: []string{"compute_resource", "host_system_id"},
+		},
+		"datacenter_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter the resource pool is in. This is not required when using ESXi directly, or if there is only one datacenter in your infrastructure.",
+			Optional:    true,
+		},
+		"compute_resource": {
+			Type:          schema.TypeString,
+			Description:   "The name or path of a cluster or standalone host to look up the root resource pool of.",
+			Optional:      true,
+			ConflictsWith: []string{"name", "host_system_id"},
 		},
+		"host_system_id": {
+			Type:          schema.TypeString,
+			Description:   "The managed object ID of a host to look up the root resource pool of. For a host in a cluster, this is the root resource pool of the cluster.",
+			Optional:      true,
+			ConflictsWith: []string{"name", "compute_resource"},
+		},
+	}
+	for _, t := range virtualMachineResourceAllocationTypeValues {
+		s[fmt.Sprintf("%s_share_level", t)] = &schema.Schema{
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: fmt.Sprintf("The allocation level for %s resources.", t),
+		}
+		s[fmt.Sprintf("%s_share_count", t)] = &schema.Schema{
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: fmt.Sprintf("The amount of shares allocated to %s resources.", t),
+		}
+		s[fmt.Sprintf("%s_limit", t)] = &schema.Schema{
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "The maximum amount of memory (in MB) or CPU (in MHz) that the resource pool can consume, or -1 for no limit.",
+		}
+		s[fmt.Sprintf("%s_reservation", t)] = &schema.Schema{
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "The amount of memory (in MB) or CPU (in MHz) that the resource pool is guaranteed.",
+		}
+		s[fmt.Sprintf("%s_expandable_reservation", t)] = &schema.Schema{
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "Whether or not the reservation can grow beyond the specified value if the parent resource pool has unreserved resources.",
+		}
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceVSphereResourcePoolRead,
+		Schema: s,
 	}
 }
 
@@ -32,9 +77,11 @@ func dataSourceVSphereResourcePoolRead(d *schema.ResourceData, meta interface{})
 	client := meta.(*VSphereClient).vimClient
 
 	name := d.Get("name").(string)
+	crName := d.Get("compute_resource").(string)
+	hsID := d.Get("host_system_id").(string)
 	if err := viapi.ValidateVirtualCenter(client); err == nil {
-		if name == "" {
-			return fmt.Errorf("name cannot be empty when using vCenter")
+		if name == "" && crName == "" && hsID == "" {
+			return fmt.Errorf("name cannot be empty when using vCenter, unless compute_resource or host_system_id is set")
 		}
 	}
 
@@ -46,11 +93,82 @@ func dataSourceVSphereResourcePoolRead(d *schema.ResourceData, meta interface{})
 			return fmt.Errorf("cannot locate datacenter: %s", err)
 		}
 	}
-	rp, err := resourcepool.FromPathOrDefault(client, name, dc)
+
+	var rp *object.ResourcePool
+	var err error
+	switch {
+	case crName != "":
+		rp, err = dataSourceVSphereResourcePoolRootFromPath(client, crName, dc)
+	case hsID != "":
+		rp, err = dataSourceVSphereResourcePoolRootFromHost(client, hsID)
+	default:
+		rp, err = resourcepool.FromPathOrDefault(client, name, dc)
+	}
 	if err != nil {
 		return fmt.Errorf("error fetching resource pool: %s", err)
 	}
 
+	props, err := resourcepool.Properties(rp)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool properties: %s", err)
+	}
 	d.SetId(rp.Reference().Value)
+	if err := dataSourceVSphereResourcePoolFlattenAllocation(d, &props.Config.CpuAllocation, "cpu"); err != nil {
+		return err
+	}
+	return dataSourceVSphereResourcePoolFlattenAllocation(d, &props.Config.MemoryAllocation, "memory")
+}
+
+// dataSourceVSphereResourcePoolRootFromPath returns the root resource pool of
+// the cluster or standalone host at the supplied path.
+func dataSourceVSphereResourcePoolRootFromPath(client *govmomi.Client, path string, dc *object.Datacenter) (*object.ResourcePool, error) {
+	cr, err := computeresource.BaseFromPath(client, path, dc)
+	if err != nil {
+		return nil, err
+	}
+	props, err := computeresource.BaseProperties(cr)
+	if err != nil {
+		return nil, err
+	}
+	if props.ResourcePool == nil {
+		return nil, fmt.Errorf("compute resource %q has no root resource pool", path)
+	}
+	return resourcepool.FromID(client, props.ResourcePool.Value)
+}
+
+// dataSourceVSphereResourcePoolRootFromHost returns the root resource pool of
+// the cluster or standalone host that the host with the supplied ID belongs
+// to.
+func dataSourceVSphereResourcePoolRootFromHost(client *govmomi.Client, id string) (*object.ResourcePool, error) {
+	hs, err := hostsystem.FromID(client, id)
+	if err != nil {
+		return nil, err
+	}
+	hprops, err := hostsystem.Properties(hs)
+	if err != nil {
+		return nil, err
+	}
+	if hprops.Parent == nil {
+		return nil, fmt.Errorf("host %q has no parent compute resource", id)
+	}
+	props, err := computeresource.BasePropertiesFromReference(client, *hprops.Parent)
+	if err != nil {
+		return nil, err
+	}
+	if props.ResourcePool == nil {
+		return nil, fmt.Errorf("compute resource for host %q has no root resource pool", id)
+	}
+	return resourcepool.FromID(client, props.ResourcePool.Value)
+}
+
+// dataSourceVSphereResourcePoolFlattenAllocation saves the allocation
+// settings of a resource pool for the resource type supplied by key.
+func dataSourceVSphereResourcePoolFlattenAllocation(d *schema.ResourceData, obj *types.ResourceAllocationInfo, key string) error {
+	if err := flattenVirtualMachineResourceAllocation(d, obj, key); err != nil {
+		return err
+	}
+	if obj.ExpandableReservation != nil {
+		d.Set(fmt.Sprintf("%s_expandable_reservation", key), *obj.ExpandableReservation)
+	}
 	return nil
 }
@@ -47,6 +47,29 @@ func TestAccDataSourceVSphereResourcePool_noDatacenterAndAbsolutePath(t *testing
 	})
 }
 
+func TestAccDataSourceVSphereResourcePool_clusterRootPool(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccDataSourceVSphereResourcePoolPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereResourcePoolConfigClusterRootPool(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_resource_pool.pool", "id",
+						"data.vsphere_resource_pool.path", "id",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_resource_pool.pool", "cpu_limit", "-1"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccDataSourceVSphereResourcePool_defaultResourcePoolForESXi(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -153,6 +176,35 @@ data "vsphere_resource_pool" "pool" {
 	)
 }
 
+func testAccDataSourceVSphereResourcePoolConfigClusterRootPool() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  compute_resource = "${var.cluster}"
+  datacenter_id    = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "path" {
+  name          = "${var.cluster}/Resources"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+	)
+}
+
 const testAccDataSourceVSphereResourcePoolConfigDefault = `
 data "vsphere_resource_pool" "pool" {}
 `
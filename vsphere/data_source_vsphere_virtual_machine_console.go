@@ -0,0 +1,83 @@
+package vsphere
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+)
+
+func dataSourceVSphereVirtualMachineConsole() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachineConsoleRead,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_id": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine to acquire a console ticket for.",
+				Required:    true,
+			},
+			"ticket": {
+				Type:        schema.TypeString,
+				Description: "The WebMKS ticket.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"host": {
+				Type:        schema.TypeString,
+				Description: "The host to connect to with the ticket.",
+				Computed:    true,
+			},
+			"port": {
+				Type:        schema.TypeInt,
+				Description: "The port to connect to with the ticket.",
+				Computed:    true,
+			},
+			"ssl_thumbprint": {
+				Type:        schema.TypeString,
+				Description: "The SHA1 thumbprint of the SSL certificate of host.",
+				Computed:    true,
+			},
+			"url": {
+				Type:        schema.TypeString,
+				Description: "The WebSocket URL of the console, including the ticket.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func dataSourceVSphereVirtualMachineConsoleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	id := d.Get("virtual_machine_id").(string)
+	vm, err := virtualmachine.FromUUID(client, id)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", id, err)
+	}
+	ticket, err := virtualmachine.AcquireTicket(vm, "webmks")
+	if err != nil {
+		return viapi.NewDiagnostic(err, "error acquiring console ticket for virtual machine %q", id)
+	}
+	// The host and port are left out of the ticket when the console is
+	// reached through the endpoint that the provider is connected to.
+	host := ticket.Host
+	if host == "" {
+		host = client.URL().Hostname()
+	}
+	port := int(ticket.Port)
+	if port == 0 {
+		port = 443
+	}
+
+	d.SetId(id)
+	d.Set("ticket", ticket.Ticket)
+	d.Set("host", host)
+	d.Set("port", port)
+	d.Set("ssl_thumbprint", ticket.SslThumbprint)
+	d.Set("url", fmt.Sprintf("wss://%s/ticket/%s", net.JoinHostPort(host, strconv.Itoa(port)), ticket.Ticket))
+	return nil
+}
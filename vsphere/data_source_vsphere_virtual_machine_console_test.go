@@ -0,0 +1,41 @@
+package vsphere
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereVirtualMachineConsole_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachineConsoleConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machine_console.console", "ticket"),
+					resource.TestCheckResourceAttrSet("data.vsphere_virtual_machine_console.console", "host"),
+					resource.TestMatchResourceAttr("data.vsphere_virtual_machine_console.console", "url", regexp.MustCompile("^wss://.+/ticket/.+$")),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereVirtualMachineConsoleConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_virtual_machine_console" "console" {
+  virtual_machine_id = "${vsphere_virtual_machine.vm.id}"
+}
+`,
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+	)
+}
@@ -0,0 +1,173 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualMachineMigrationEventTypes are the event types that are logged when
+// a virtual machine has moved to another host or datastore.
+var virtualMachineMigrationEventTypes = []string{
+	"VmMigratedEvent",
+	"DrsVmMigratedEvent",
+	"VmRelocatedEvent",
+}
+
+func dataSourceVSphereVirtualMachineMigrations() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachineMigrationsRead,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_id": {
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine to read the migration history of.",
+				Required:    true,
+			},
+			"max_migrations": {
+				Type:         schema.TypeInt,
+				Description:  "The maximum number of migrations to read. The most recent migrations are read first.",
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host that the virtual machine is currently on.",
+				Computed:    true,
+			},
+			"last_migration_time": {
+				Type:        schema.TypeString,
+				Description: "The time of the most recent migration, in RFC3339 format. Empty if no migration was found.",
+				Computed:    true,
+			},
+			"migrations": {
+				Type:        schema.TypeList,
+				Description: "The migrations found, most recent first.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source_host_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"destination_host_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source_datastore_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"destination_datastore_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"message": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVSphereVirtualMachineMigrationsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	id := d.Get("virtual_machine_id").(string)
+	vm, err := virtualmachine.FromUUID(client, id)
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", id, err)
+	}
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+
+	filter := types.EventFilterSpec{
+		Entity: &types.EventFilterSpecByEntity{
+			Entity:    vm.Reference(),
+			Recursion: types.EventFilterSpecRecursionOptionSelf,
+		},
+		Type: virtualMachineMigrationEventTypes,
+	}
+	events, err := readEvents(client, filter, d.Get("max_migrations").(int))
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Found %d migrations of virtual machine %q", len(events), id)
+
+	var result []interface{}
+	for _, be := range events {
+		result = append(result, flattenVirtualMachineMigrationEvent(be))
+	}
+	var last string
+	if len(events) > 0 {
+		last = events[0].GetEvent().CreatedTime.Format(time.RFC3339)
+	}
+
+	d.SetId(id)
+	if props.Runtime.Host != nil {
+		d.Set("host_system_id", props.Runtime.Host.Value)
+	}
+	d.Set("last_migration_time", last)
+	if err := d.Set("migrations", result); err != nil {
+		return fmt.Errorf("error setting migrations: %s", err)
+	}
+	return nil
+}
+
+// flattenVirtualMachineMigrationEvent reads a migration event into a
+// migrations entry of the vsphere_virtual_machine_migrations data source.
+// The destination of the migration is the host and datastore of the event
+// itself.
+func flattenVirtualMachineMigrationEvent(be types.BaseEvent) map[string]interface{} {
+	e := be.GetEvent()
+	m := map[string]interface{}{
+		"type":         reflect.TypeOf(be).Elem().Name(),
+		"created_time": e.CreatedTime.Format(time.RFC3339),
+		"user_name":    e.UserName,
+		"message":      e.FullFormattedMessage,
+	}
+	if e.Host != nil {
+		m["destination_host_id"] = e.Host.Host.Value
+	}
+	if e.Ds != nil {
+		m["destination_datastore_id"] = e.Ds.Datastore.Value
+	}
+	var srcHost types.HostEventArgument
+	var srcDs *types.DatastoreEventArgument
+	switch t := be.(type) {
+	case *types.VmMigratedEvent:
+		srcHost, srcDs = t.SourceHost, t.SourceDatastore
+	case *types.DrsVmMigratedEvent:
+		srcHost, srcDs = t.SourceHost, t.SourceDatastore
+	case *types.VmRelocatedEvent:
+		srcHost, srcDs = t.SourceHost, t.SourceDatastore
+	}
+	m["source_host_id"] = srcHost.Host.Value
+	if srcDs != nil {
+		m["source_datastore_id"] = srcDs.Datastore.Value
+	}
+	return m
+}
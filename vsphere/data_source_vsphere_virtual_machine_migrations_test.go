@@ -0,0 +1,43 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataSourceVSphereVirtualMachineMigrations_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVSphereVirtualMachineMigrationsConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.vsphere_virtual_machine_migrations.history", "host_system_id",
+						"vsphere_virtual_machine.vm", "host_system_id",
+					),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_migrations.history", "migrations.#", "0"),
+					resource.TestCheckResourceAttr("data.vsphere_virtual_machine_migrations.history", "last_migration_time", ""),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVSphereVirtualMachineMigrationsConfig() string {
+	return fmt.Sprintf(`
+%s
+
+data "vsphere_virtual_machine_migrations" "history" {
+  virtual_machine_id = "${vsphere_virtual_machine.vm.id}"
+}
+`,
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+	)
+}
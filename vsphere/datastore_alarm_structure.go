@@ -0,0 +1,207 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/alarm"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// datastoreDiskUsageSystemAlarm is the system name of the built-in datastore
+// usage alarm. The usage metric of the capacity alarm is taken from it, as
+// the metric is not exposed through the performance manager.
+const datastoreDiskUsageSystemAlarm = "alarm.DatastoreDiskUsageAlarm"
+
+// datastoreCapacityAlarmReportingFrequency is the minimum time, in seconds,
+// between two status changes of a capacity alarm.
+const datastoreCapacityAlarmReportingFrequency = 300
+
+// schemaDatastoreCapacityAlarm returns the schema for the capacity alarm of
+// the datastore resources.
+func schemaDatastoreCapacityAlarm() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"capacity_alarm": {
+			Type:        schema.TypeList,
+			Description: "An alarm that is triggered when the usage of the datastore crosses a threshold.",
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Description: "The name of the alarm. The default is the name of the datastore, followed by \"usage\".",
+						Optional:    true,
+						Computed:    true,
+					},
+					"enabled": {
+						Type:        schema.TypeBool,
+						Description: "Whether or not the alarm is enabled.",
+						Optional:    true,
+						Default:     true,
+					},
+					"warning_threshold": {
+						Type:         schema.TypeInt,
+						Description:  "The usage, in percent, at which the alarm turns yellow.",
+						Optional:     true,
+						Default:      75,
+						ValidateFunc: validation.IntBetween(1, 100),
+					},
+					"critical_threshold": {
+						Type:         schema.TypeInt,
+						Description:  "The usage, in percent, at which the alarm turns red.",
+						Optional:     true,
+						Default:      85,
+						ValidateFunc: validation.IntBetween(1, 100),
+					},
+				},
+			},
+		},
+		"capacity_alarm_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the alarm created for capacity_alarm.",
+			Computed:    true,
+		},
+	}
+}
+
+// expandDatastoreCapacityAlarmSpec reads capacity_alarm into an AlarmSpec.
+func expandDatastoreCapacityAlarmSpec(client *govmomi.Client, d *schema.ResourceData) (types.AlarmSpec, error) {
+	warning := d.Get("capacity_alarm.0.warning_threshold").(int)
+	critical := d.Get("capacity_alarm.0.critical_threshold").(int)
+	if warning > critical {
+		return types.AlarmSpec{}, fmt.Errorf("capacity_alarm: warning_threshold (%d) must not be higher than critical_threshold (%d)", warning, critical)
+	}
+	builtin, err := alarm.BySystemName(client, datastoreDiskUsageSystemAlarm)
+	if err != nil {
+		return types.AlarmSpec{}, fmt.Errorf("error looking up datastore usage metric: %s", err)
+	}
+	expr := datastoreCapacityAlarmMetricExpression(builtin.Info.Expression)
+	if expr == nil {
+		return types.AlarmSpec{}, fmt.Errorf("built-in alarm %q has no metric expression", datastoreDiskUsageSystemAlarm)
+	}
+	name := d.Get("capacity_alarm.0.name").(string)
+	if name == "" {
+		name = fmt.Sprintf("%s usage", d.Get("name").(string))
+	}
+	return types.AlarmSpec{
+		Name:        name,
+		Description: "Managed by Terraform.",
+		Enabled:     d.Get("capacity_alarm.0.enabled").(bool),
+		Expression: &types.OrAlarmExpression{
+			Expression: []types.BaseAlarmExpression{
+				&types.MetricAlarmExpression{
+					Operator: types.MetricAlarmOperatorIsAbove,
+					Type:     "Datastore",
+					Metric:   expr.Metric,
+					// Thresholds are in hundredths of a percent.
+					Yellow: int32(warning * 100),
+					Red:    int32(critical * 100),
+				},
+			},
+		},
+		Setting: &types.AlarmSetting{
+			ReportingFrequency: datastoreCapacityAlarmReportingFrequency,
+		},
+	}, nil
+}
+
+// datastoreCapacityAlarmMetricExpression returns the first metric
+// expression found in an alarm expression.
+func datastoreCapacityAlarmMetricExpression(expr types.BaseAlarmExpression) *types.MetricAlarmExpression {
+	switch e := expr.(type) {
+	case *types.MetricAlarmExpression:
+		return e
+	case *types.OrAlarmExpression:
+		for _, sub := range e.Expression {
+			if m := datastoreCapacityAlarmMetricExpression(sub); m != nil {
+				return m
+			}
+		}
+	case *types.AndAlarmExpression:
+		for _, sub := range e.Expression {
+			if m := datastoreCapacityAlarmMetricExpression(sub); m != nil {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// processDatastoreCapacityAlarm creates, updates, or removes the capacity
+// alarm of a datastore to match capacity_alarm.
+func processDatastoreCapacityAlarm(client *govmomi.Client, d *schema.ResourceData, ds *object.Datastore) error {
+	id := d.Get("capacity_alarm_id").(string)
+	if len(d.Get("capacity_alarm").([]interface{})) < 1 {
+		if id == "" {
+			return nil
+		}
+		if err := alarm.Remove(client, id); err != nil && !viapi.IsManagedObjectNotFoundError(err) {
+			return fmt.Errorf("error removing capacity alarm: %s", err)
+		}
+		d.Set("capacity_alarm_id", "")
+		return nil
+	}
+	spec, err := expandDatastoreCapacityAlarmSpec(client, d)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if err := alarm.Reconfigure(client, id, spec); err != nil {
+			return fmt.Errorf("error updating capacity alarm: %s", err)
+		}
+		return nil
+	}
+	id, err = alarm.Create(client, ds.Reference(), spec)
+	if err != nil {
+		return fmt.Errorf("error creating capacity alarm: %s", err)
+	}
+	d.Set("capacity_alarm_id", id)
+	return nil
+}
+
+// flattenDatastoreCapacityAlarm reads the capacity alarm of a datastore into
+// capacity_alarm. If the alarm has been removed outside of Terraform,
+// capacity_alarm is cleared so that the alarm is created again.
+func flattenDatastoreCapacityAlarm(client *govmomi.Client, d *schema.ResourceData) error {
+	id := d.Get("capacity_alarm_id").(string)
+	if id == "" {
+		return d.Set("capacity_alarm", nil)
+	}
+	props, err := alarm.Properties(client, id)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Capacity alarm %q not found, removing from state", id)
+			d.Set("capacity_alarm_id", "")
+			return d.Set("capacity_alarm", nil)
+		}
+		return fmt.Errorf("error fetching capacity alarm: %s", err)
+	}
+	m := map[string]interface{}{
+		"name":    props.Info.Name,
+		"enabled": props.Info.Enabled,
+	}
+	if expr := datastoreCapacityAlarmMetricExpression(props.Info.Expression); expr != nil {
+		m["warning_threshold"] = int(expr.Yellow / 100)
+		m["critical_threshold"] = int(expr.Red / 100)
+	}
+	return d.Set("capacity_alarm", []interface{}{m})
+}
+
+// removeDatastoreCapacityAlarm removes the capacity alarm of a datastore, if
+// it has one.
+func removeDatastoreCapacityAlarm(client *govmomi.Client, d *schema.ResourceData) error {
+	id := d.Get("capacity_alarm_id").(string)
+	if id == "" {
+		return nil
+	}
+	if err := alarm.Remove(client, id); err != nil && !viapi.IsManagedObjectNotFoundError(err) {
+		return fmt.Errorf("error removing capacity alarm: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package vsphere
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+)
+
+// datastoreByReference locates a datastore that a resource references by name.
+//
+// If the managed object ID of the datastore has been saved in state, it is
+// supplied in id, and the datastore is located by its ID first, so that
+// renaming the datastore outside of Terraform does not break the resource. If
+// no ID has been saved yet, or no datastore with the ID exists anymore, the
+// datastore is located by name through finder.
+func datastoreByReference(client *govmomi.Client, finder *find.Finder, name, id string) (*object.Datastore, error) {
+	if id != "" {
+		ds, err := datastore.FromID(client, id)
+		if err == nil {
+			return ds, nil
+		}
+		if !viapi.IsManagedObjectNotFoundError(err) {
+			return nil, err
+		}
+		log.Printf("[DEBUG] Datastore with ID %q no longer exists, locating datastore %q by name", id, name)
+	}
+	return getDatastore(finder, name)
+}
+
+// datastoreReferenceDiffSuppress suppresses the diff on a datastore name when
+// the new name is the current name of the datastore that the resource is
+// tracking, in the attribute at nameKey. This allows the configuration to be
+// updated after a datastore is renamed outside of Terraform without moving or
+// re-creating anything.
+func datastoreReferenceDiffSuppress(nameKey string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		return d.Id() != "" && new != "" && new == d.Get(nameKey).(string)
+	}
+}
@@ -0,0 +1,178 @@
+package vsphere
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/debug"
+	"github.com/vmware/vic/pkg/vsphere/tags"
+)
+
+// sensitiveValue is the text that sensitive values are replaced with in
+// sanitized output.
+const sensitiveValue = "(sensitive value)"
+
+// passwordElementMatcher matches XML elements with passwords in them, such as
+// the password field of a Login request or the password of a guest
+// customization spec.
+var passwordElementMatcher = regexp.MustCompile(`(?s)<(\w*[pP]assword\w*)(\s[^>]*)?>.*?</\w*[pP]assword\w*>`)
+
+// sensitiveHeaderMatcher matches HTTP headers that carry credentials or
+// session tokens.
+var sensitiveHeaderMatcher = regexp.MustCompile(`(?mi)^((?:Set-)?Cookie|Authorization|vmware-api-session-id):[^\r\n]*`)
+
+// redactPasswordElements replaces the contents of any XML elements with
+// passwords in them.
+func redactPasswordElements(s string) string {
+	return passwordElementMatcher.ReplaceAllString(s, "<$1$2>"+sensitiveValue+"</$1>")
+}
+
+// sanitizeDebugOutput removes passwords and session tokens from debug output.
+func sanitizeDebugOutput(b []byte) []byte {
+	s := redactPasswordElements(string(b))
+	s = sensitiveHeaderMatcher.ReplaceAllString(s, "$1: "+sensitiveValue)
+	return []byte(s)
+}
+
+// sanitizingDebugProvider is a govmomi debug.Provider that removes passwords
+// and session tokens from the files written by an underlying provider. The
+// file naming is left untouched, so that the output can be read by the same
+// tooling as regular govmomi debug output.
+type sanitizingDebugProvider struct {
+	p debug.Provider
+}
+
+// NewFile implements debug.Provider for sanitizingDebugProvider.
+func (p *sanitizingDebugProvider) NewFile(s string) io.WriteCloser {
+	wc := p.p.NewFile(s)
+	if strings.HasSuffix(s, ".log") {
+		// Request logs are written to a line at a time for the lifetime of a
+		// client, and only hold method names and timings.
+		return wc
+	}
+	return &sanitizingWriteCloser{wc: wc}
+}
+
+// Flush implements debug.Provider for sanitizingDebugProvider.
+func (p *sanitizingDebugProvider) Flush() {
+	p.p.Flush()
+}
+
+// sanitizingWriteCloser buffers everything written to it, and writes a
+// sanitized copy to the underlying io.WriteCloser when closed. Buffering
+// ensures that a value is redacted even if it is split across writes.
+type sanitizingWriteCloser struct {
+	wc  io.WriteCloser
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer for sanitizingWriteCloser.
+func (w *sanitizingWriteCloser) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+// Close implements io.Closer for sanitizingWriteCloser.
+func (w *sanitizingWriteCloser) Close() error {
+	if _, err := w.wc.Write(sanitizeDebugOutput(w.buf.Bytes())); err != nil {
+		w.wc.Close()
+		return err
+	}
+	return w.wc.Close()
+}
+
+// restDebugSessionPath is the path of the CIS REST session endpoint. The
+// response body for this path is the session token, and is not recorded.
+const restDebugSessionPath = "/com/vmware/cis/session"
+
+// restDebugTransport is a http.RoundTripper for the CIS REST client that
+// records requests and responses to the current govmomi debug provider, in
+// the same layout that govmomi uses for SOAP calls. Files are prefixed with
+// "rest-" to keep them apart from the SOAP calls.
+type restDebugTransport struct {
+	rt  http.RoundTripper
+	rn  uint64
+	log io.WriteCloser
+	mu  sync.Mutex
+}
+
+// RoundTrip implements http.RoundTripper for restDebugTransport.
+func (t *restDebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !debug.Enabled() {
+		return t.rt.RoundTrip(req)
+	}
+	rn := atomic.AddUint64(&t.rn, 1)
+
+	b, _ := httputil.DumpRequestOut(req, false)
+	t.writeFile(rn, "req.headers", b)
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		t.writeFile(rn, "req.json", body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	started := time.Now()
+	res, err := t.rt.RoundTrip(req)
+	if err != nil {
+		t.logf(rn, "%s %s: %s", req.Method, req.URL.Path, err)
+		return nil, err
+	}
+	t.logf(rn, "%s %s: %s (%s)", req.Method, req.URL.Path, res.Status, time.Since(started))
+
+	b, _ = httputil.DumpResponse(res, false)
+	t.writeFile(rn, "res.headers", b)
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if strings.HasSuffix(req.URL.Path, restDebugSessionPath) {
+		body = []byte(sensitiveValue)
+	}
+	t.writeFile(rn, "res.json", body)
+	return res, nil
+}
+
+// writeFile writes the supplied data to the debug file for request number rn
+// with the supplied suffix.
+func (t *restDebugTransport) writeFile(rn uint64, suffix string, b []byte) {
+	wc := debug.NewFile(fmt.Sprintf("rest-%04d.%s", rn, suffix))
+	wc.Write(b)
+	wc.Close()
+}
+
+// logf writes a line to the REST request log.
+func (t *restDebugTransport) logf(rn uint64, format string, a ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.log == nil {
+		t.log = debug.NewFile("rest-client.log")
+	}
+	now := time.Now().Format("2006-01-02T15-04-05.000000000")
+	fmt.Fprintf(t.log, "%s - %04d: ", now, rn)
+	fmt.Fprintf(t.log, format, a...)
+	fmt.Fprintf(t.log, "\n")
+}
+
+// enableRESTDebug wraps the HTTP transport of a CIS REST client in a
+// restDebugTransport.
+func enableRESTDebug(client *tags.RestClient) {
+	rt := client.HTTP.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	client.HTTP.Transport = &restDebugTransport{rt: rt}
+}
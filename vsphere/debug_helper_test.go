@@ -0,0 +1,23 @@
+package vsphere
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeDebugOutput(t *testing.T) {
+	in := "POST /sdk HTTP/1.1\r\nCookie: vmware_soap_session=\"abc123\"\r\nSoapaction: urn:vim25/6.5\r\n\r\n" +
+		"<Login><userName>user</userName><password>secret</password></Login>" +
+		"<password xsi:type=\"CustomizationPassword\"><value>hunter2</value><plainText>true</plainText></password>"
+	out := string(sanitizeDebugOutput([]byte(in)))
+	for _, s := range []string{"abc123", "secret", "hunter2"} {
+		if strings.Contains(out, s) {
+			t.Fatalf("expected %q to be removed from output:\n%s", s, out)
+		}
+	}
+	for _, s := range []string{"Cookie: (sensitive value)\r\n", "Soapaction: urn:vim25/6.5", "<userName>user</userName>"} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expected %q in output:\n%s", s, out)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// dvPortOverrideVlanInherit is the vlan_id of a port_override that keeps the
+// VLAN setting of the portgroup.
+const dvPortOverrideVlanInherit = -1
+
+// schemaDVPortOverride returns the schema for the port_override sub-resource
+// of a portgroup, which holds settings that override the portgroup settings
+// on individual ports.
+func schemaDVPortOverride() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "Settings that override the portgroup settings on individual ports. Requires the earlyBinding portgroup type.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "The key of the port.",
+					ValidateFunc: validation.NoZeroValues,
+				},
+				"name": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The name of the port.",
+				},
+				"vlan_id": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      dvPortOverrideVlanInherit,
+					Description:  "The VLAN ID of the port, overriding the VLAN setting of the portgroup. -1 keeps the portgroup setting. Requires vlan_override_allowed.",
+					ValidateFunc: validation.IntBetween(dvPortOverrideVlanInherit, 4094),
+				},
+				"blocked": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Block all traffic on the port. Requires block_override_allowed.",
+				},
+			},
+		},
+	}
+}
+
+// validateDVPortOverrides checks that the port overrides of a portgroup are
+// allowed by its type and policy.
+func validateDVPortOverrides(d *schema.ResourceDiff) error {
+	overrides := d.Get("port_override").(*schema.Set).List()
+	if len(overrides) < 1 {
+		return nil
+	}
+	if d.Get("type").(string) != string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding) {
+		return fmt.Errorf("port_override requires the %s portgroup type", types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding)
+	}
+	for _, v := range overrides {
+		o := v.(map[string]interface{})
+		if o["vlan_id"].(int) != dvPortOverrideVlanInherit && !d.Get("vlan_override_allowed").(bool) {
+			return fmt.Errorf("port_override %q: vlan_id requires vlan_override_allowed", o["key"].(string))
+		}
+		if o["blocked"].(bool) && !d.Get("block_override_allowed").(bool) {
+			return fmt.Errorf("port_override %q: blocked requires block_override_allowed", o["key"].(string))
+		}
+	}
+	return nil
+}
+
+// expandDVPortOverrides returns the DVPortConfigSpecs that apply the changes
+// between the old and new port_override sets. Ports that are removed from the
+// set get the portgroup settings back.
+func expandDVPortOverrides(d *schema.ResourceData) []types.DVPortConfigSpec {
+	o, n := d.GetChange("port_override")
+	keep := make(map[string]bool)
+	var specs []types.DVPortConfigSpec
+	for _, v := range n.(*schema.Set).List() {
+		port := v.(map[string]interface{})
+		keep[port["key"].(string)] = true
+		specs = append(specs, types.DVPortConfigSpec{
+			Operation: string(types.ConfigSpecOperationEdit),
+			Key:       port["key"].(string),
+			Name:      port["name"].(string),
+			Setting:   expandDVPortOverrideSetting(port["vlan_id"].(int), port["blocked"].(bool)),
+		})
+	}
+	for _, v := range o.(*schema.Set).List() {
+		key := v.(map[string]interface{})["key"].(string)
+		if keep[key] {
+			continue
+		}
+		specs = append(specs, types.DVPortConfigSpec{
+			Operation: string(types.ConfigSpecOperationEdit),
+			Key:       key,
+			Setting:   expandDVPortOverrideSetting(dvPortOverrideVlanInherit, false),
+		})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Key < specs[j].Key })
+	return specs
+}
+
+// expandDVPortOverrideSetting returns the port setting for a port override.
+// The VLAN and blocked settings are inherited from the portgroup when they
+// are not overridden.
+func expandDVPortOverrideSetting(vlanID int, blocked bool) *types.VMwareDVSPortSetting {
+	obj := &types.VMwareDVSPortSetting{
+		DVPortSetting: types.DVPortSetting{
+			Blocked: &types.BoolPolicy{
+				InheritablePolicy: types.InheritablePolicy{Inherited: !blocked},
+			},
+		},
+		Vlan: &types.VmwareDistributedVirtualSwitchVlanIdSpec{
+			InheritablePolicy: types.InheritablePolicy{Inherited: vlanID == dvPortOverrideVlanInherit},
+		},
+	}
+	if blocked {
+		obj.Blocked.Value = &blocked
+	}
+	if vlanID != dvPortOverrideVlanInherit {
+		obj.Vlan.(*types.VmwareDistributedVirtualSwitchVlanIdSpec).VlanId = int32(vlanID)
+	}
+	return obj
+}
+
+// flattenDVPortOverrides reads the overrides of the ports in port_override
+// from the supplied ports into the passed in ResourceData. Ports that do not
+// exist any more are removed from the set.
+func flattenDVPortOverrides(d *schema.ResourceData, ports []types.DistributedVirtualPort) error {
+	byKey := make(map[string]types.DistributedVirtualPort)
+	for _, port := range ports {
+		byKey[port.Key] = port
+	}
+	var overrides []interface{}
+	for _, v := range d.Get("port_override").(*schema.Set).List() {
+		key := v.(map[string]interface{})["key"].(string)
+		port, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		vlanID := dvPortOverrideVlanInherit
+		var blocked bool
+		if setting, ok := port.Config.Setting.(*types.VMwareDVSPortSetting); ok {
+			if vlan, ok := setting.Vlan.(*types.VmwareDistributedVirtualSwitchVlanIdSpec); ok && !vlan.Inherited {
+				vlanID = int(vlan.VlanId)
+			}
+			if setting.Blocked != nil && !setting.Blocked.Inherited && setting.Blocked.Value != nil {
+				blocked = *setting.Blocked.Value
+			}
+		}
+		overrides = append(overrides, map[string]interface{}{
+			"key":     key,
+			"name":    port.Config.Name,
+			"vlan_id": vlanID,
+			"blocked": blocked,
+		})
+	}
+	return d.Set("port_override", overrides)
+}
+
+// dvPortOverrideKeys returns the keys of the ports in port_override.
+func dvPortOverrideKeys(d *schema.ResourceData) []string {
+	var keys []string
+	for _, v := range d.Get("port_override").(*schema.Set).List() {
+		keys = append(keys, v.(map[string]interface{})["key"].(string))
+	}
+	sort.Strings(keys)
+	return keys
+}
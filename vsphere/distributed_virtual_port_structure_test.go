@@ -0,0 +1,61 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestExpandDVPortOverrideSetting(t *testing.T) {
+	cases := []struct {
+		name             string
+		vlanID           int
+		blocked          bool
+		vlanInherited    bool
+		expectedVlan     int32
+		blockedInherited bool
+	}{
+		{
+			name:             "inherit all",
+			vlanID:           dvPortOverrideVlanInherit,
+			vlanInherited:    true,
+			blockedInherited: true,
+		},
+		{
+			name:             "vlan override",
+			vlanID:           100,
+			expectedVlan:     100,
+			blockedInherited: true,
+		},
+		{
+			name:             "no vlan override",
+			vlanID:           0,
+			expectedVlan:     0,
+			vlanInherited:    false,
+			blockedInherited: true,
+		},
+		{
+			name:          "blocked",
+			vlanID:        dvPortOverrideVlanInherit,
+			blocked:       true,
+			vlanInherited: true,
+		},
+	}
+
+	for _, tc := range cases {
+		setting := expandDVPortOverrideSetting(tc.vlanID, tc.blocked)
+		vlan := setting.Vlan.(*types.VmwareDistributedVirtualSwitchVlanIdSpec)
+		if vlan.Inherited != tc.vlanInherited {
+			t.Fatalf("%q: expected VLAN inherited to be %t, got %t", tc.name, tc.vlanInherited, vlan.Inherited)
+		}
+		if vlan.VlanId != tc.expectedVlan {
+			t.Fatalf("%q: expected VLAN ID %d, got %d", tc.name, tc.expectedVlan, vlan.VlanId)
+		}
+		if setting.Blocked.Inherited != tc.blockedInherited {
+			t.Fatalf("%q: expected blocked inherited to be %t, got %t", tc.name, tc.blockedInherited, setting.Blocked.Inherited)
+		}
+		if tc.blocked && (setting.Blocked.Value == nil || !*setting.Blocked.Value) {
+			t.Fatalf("%q: expected port to be blocked", tc.name)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package vsphere
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
@@ -109,6 +111,108 @@ func upgradeDVS(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwit
 	return nil
 }
 
+// checkDVSUpgradeCompatibility checks that the hosts with the supplied IDs can
+// be members of a DVS of a specific version, and returns an error that lists
+// the hosts that cannot and why if there are any. This should be checked
+// before upgrading a DVS, as an upgrade cannot be undone.
+func checkDVSUpgradeCompatibility(client *govmomi.Client, version string, hsIDs []string) error {
+	if len(hsIDs) < 1 {
+		return nil
+	}
+	var hosts []types.ManagedObjectReference
+	for _, id := range hsIDs {
+		hosts = append(hosts, types.ManagedObjectReference{Type: "HostSystem", Value: id})
+	}
+	req := &types.QueryDvsCheckCompatibility{
+		This: types.ManagedObjectReference{Type: "DistributedVirtualSwitchManager", Value: "DVSManager"},
+		HostContainer: types.DistributedVirtualSwitchManagerHostContainer{
+			Container: client.ServiceContent.RootFolder,
+			Recursive: true,
+		},
+		DvsProductSpec: &types.DistributedVirtualSwitchManagerDvsProductSpec{
+			NewSwitchProductSpec: &types.DistributedVirtualSwitchProductSpec{
+				Version: version,
+			},
+		},
+		HostFilterSpec: []types.BaseDistributedVirtualSwitchManagerHostDvsFilterSpec{
+			&types.DistributedVirtualSwitchManagerHostArrayFilter{
+				DistributedVirtualSwitchManagerHostDvsFilterSpec: types.DistributedVirtualSwitchManagerHostDvsFilterSpec{
+					Inclusive: true,
+				},
+				Host: hosts,
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.QueryDvsCheckCompatibility(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	return dvsCompatibilityResultsError(version, resp.Returnval, func(id string) string {
+		return hostsystem.NameOrID(client, id)
+	})
+}
+
+// dvsCompatibilityResultsError returns an error listing the hosts in the
+// supplied QueryDvsCheckCompatibility results that have errors, and why. nil
+// is returned if all hosts are compatible. hostName is used to look up the
+// display name of a host from its ID.
+func dvsCompatibilityResultsError(version string, results []types.DistributedVirtualSwitchManagerCompatibilityResult, hostName func(string) string) error {
+	var incompatible []string
+	for _, result := range results {
+		if len(result.Error) < 1 {
+			continue
+		}
+		var reasons []string
+		for _, fault := range result.Error {
+			reasons = append(reasons, fault.LocalizedMessage)
+		}
+		incompatible = append(incompatible, fmt.Sprintf("%s (%s)", hostName(result.Host.Value), strings.Join(reasons, "; ")))
+	}
+	if len(incompatible) > 0 {
+		return fmt.Errorf("the following hosts are not compatible with DVS version %s: %s", version, strings.Join(incompatible, ", "))
+	}
+	return nil
+}
+
+// fetchDVPorts fetches the ports of a DVS that match the supplied criteria.
+func fetchDVPorts(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, criteria *types.DistributedVirtualSwitchPortCriteria) ([]types.DistributedVirtualPort, error) {
+	req := &types.FetchDVPorts{
+		This:     dvs.Reference(),
+		Criteria: criteria,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.FetchDVPorts(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Returnval, nil
+}
+
+// reconfigureDVPorts contains the atomic reconfigure/wait operation for the
+// individual ports of a DVS.
+func reconfigureDVPorts(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, specs []types.DVPortConfigSpec) error {
+	req := &types.ReconfigureDVPort_Task{
+		This: dvs.Reference(),
+		Port: specs,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.ReconfigureDVPort_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
 // updateDVSConfiguration contains the atomic update/wait operation for a DVS.
 func updateDVSConfiguration(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, spec *types.VMwareDVSConfigSpec) error {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
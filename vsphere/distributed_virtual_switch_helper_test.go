@@ -0,0 +1,63 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestDVSCompatibilityResultsError(t *testing.T) {
+	hostName := func(id string) string {
+		return "name-" + id
+	}
+	result := func(id string, messages ...string) types.DistributedVirtualSwitchManagerCompatibilityResult {
+		r := types.DistributedVirtualSwitchManagerCompatibilityResult{
+			Host: types.ManagedObjectReference{Type: "HostSystem", Value: id},
+		}
+		for _, m := range messages {
+			r.Error = append(r.Error, types.LocalizedMethodFault{LocalizedMessage: m})
+		}
+		return r
+	}
+	cases := []struct {
+		name     string
+		results  []types.DistributedVirtualSwitchManagerCompatibilityResult
+		expected string
+	}{
+		{
+			name: "no results",
+		},
+		{
+			name:    "all compatible",
+			results: []types.DistributedVirtualSwitchManagerCompatibilityResult{result("host-1"), result("host-2")},
+		},
+		{
+			name: "one host blocked",
+			results: []types.DistributedVirtualSwitchManagerCompatibilityResult{
+				result("host-1"),
+				result("host-2", "host version too old"),
+			},
+			expected: "the following hosts are not compatible with DVS version 6.5.0: name-host-2 (host version too old)",
+		},
+		{
+			name: "several hosts blocked",
+			results: []types.DistributedVirtualSwitchManagerCompatibilityResult{
+				result("host-1", "host version too old", "host is disconnected"),
+				result("host-2"),
+				result("host-3", "host version too old"),
+			},
+			expected: "the following hosts are not compatible with DVS version 6.5.0: name-host-1 (host version too old; host is disconnected), name-host-3 (host version too old)",
+		},
+	}
+	for _, tc := range cases {
+		err := dvsCompatibilityResultsError("6.5.0", tc.results, hostName)
+		switch {
+		case tc.expected == "" && err != nil:
+			t.Fatalf("%s: expected no error, got %s", tc.name, err)
+		case tc.expected != "" && err == nil:
+			t.Fatalf("%s: expected error %q, got none", tc.name, tc.expected)
+		case tc.expected != "" && err.Error() != tc.expected:
+			t.Fatalf("%s: expected error %q, got %q", tc.name, tc.expected, err)
+		}
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
@@ -261,6 +263,36 @@ func testPowerOffVM(s *terraform.State, resourceName string) error {
 	return nil
 }
 
+// testReconfigureVMCPUMem powers off the supplied virtual machine and changes
+// its CPU and memory settings out-of-band of Terraform.
+func testReconfigureVMCPUMem(s *terraform.State, resourceName string, cpus int32, mem int64) error {
+	vm, err := testGetVirtualMachine(s, resourceName)
+	if err != nil {
+		return err
+	}
+	if err := testPowerOffVM(s, resourceName); err != nil {
+		return err
+	}
+	spec := types.VirtualMachineConfigSpec{
+		NumCPUs:  cpus,
+		MemoryMB: mem,
+	}
+	return virtualmachine.Reconfigure(vm, spec)
+}
+
+// testReconfigureVMAnnotation sets the annotation of a virtual machine out of
+// band.
+func testReconfigureVMAnnotation(s *terraform.State, resourceName string, annotation string) error {
+	vm, err := testGetVirtualMachine(s, resourceName)
+	if err != nil {
+		return err
+	}
+	spec := types.VirtualMachineConfigSpec{
+		Annotation: annotation,
+	}
+	return virtualmachine.Reconfigure(vm, spec)
+}
+
 // testRenameVMFirstDisk renames the first disk in a virtual machine
 // configuration and re-attaches it to the virtual machine under the new name.
 func testRenameVMFirstDisk(s *terraform.State, resourceName string, new string) error {
@@ -396,6 +428,32 @@ func testDeleteVM(s *terraform.State, resourceName string) error {
 	return task.Wait(tctx)
 }
 
+// testCloneVM clones the virtual machine into a new, powered off virtual
+// machine with the supplied name in the same folder, out-of-band of
+// Terraform.
+func testCloneVM(s *terraform.State, resourceName string, name string) error {
+	vm, err := testGetVirtualMachine(s, resourceName)
+	if err != nil {
+		return err
+	}
+	props, err := testGetVirtualMachineProperties(s, resourceName)
+	if err != nil {
+		return err
+	}
+	if props.Parent == nil {
+		return fmt.Errorf("virtual machine %q has no parent folder", vm.InventoryPath)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.Clone(ctx, object.NewFolder(vm.Client(), *props.Parent), name, types.VirtualMachineCloneSpec{})
+	if err != nil {
+		return fmt.Errorf("error cloning virtual machine: %s", err)
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
 // testGetTagCategory gets a tag category by name.
 func testGetTagCategory(s *terraform.State, resourceName string) (*tags.Category, error) {
 	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_tag_category.%s", resourceName))
@@ -684,3 +742,40 @@ func testGetDatastoreClusterProperties(s *terraform.State, resourceName string)
 	}
 	return storagepod.Properties(pod)
 }
+
+// testResourceDiff runs the diff of a resource, including its CustomizeDiff
+// function, against a state with the supplied ID and attributes, and the
+// supplied raw configuration. An empty ID diffs against no state, like a
+// create.
+func testResourceDiff(r *schema.Resource, id string, attrs map[string]string, raw map[string]interface{}, meta interface{}) (*terraform.InstanceDiff, error) {
+	var state *terraform.InstanceState
+	if id != "" {
+		state = &terraform.InstanceState{ID: id, Attributes: attrs}
+	}
+	rc, err := config.NewRawConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return r.Diff(state, terraform.NewResourceConfig(rc), meta)
+}
+
+// testResourceDataForUpdate returns the ResourceData that the Update function
+// of a resource is called with, for a state with the supplied ID and
+// attributes and the supplied raw configuration. The CustomizeDiff function of
+// the resource is not run.
+func testResourceDataForUpdate(r *schema.Resource, id string, attrs map[string]string, raw map[string]interface{}) (*schema.ResourceData, error) {
+	r.CustomizeDiff = nil
+	diff, err := testResourceDiff(r, id, attrs, raw, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result *schema.ResourceData
+	r.Update = func(d *schema.ResourceData, _ interface{}) error {
+		result = d
+		return nil
+	}
+	if _, err := r.Apply(&terraform.InstanceState{ID: id, Attributes: attrs}, diff, nil); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
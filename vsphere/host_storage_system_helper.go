@@ -6,6 +6,8 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 // hostStorageSystemFromHostSystemID locates a HostStorageSystem from a
@@ -19,3 +21,31 @@ func hostStorageSystemFromHostSystemID(client *govmomi.Client, hsID string) (*ob
 	defer cancel()
 	return hs.ConfigManager().StorageSystem(ctx)
 }
+
+// mountVmfsVolume is a stop-gap method that implements MountVmfsVolume. It
+// will be removed once the higher level HostStorageSystem object supports
+// this method.
+func mountVmfsVolume(s *object.HostStorageSystem, vmfsUUID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := types.MountVmfsVolume{
+		This:     s.Reference(),
+		VmfsUuid: vmfsUUID,
+	}
+	_, err := methods.MountVmfsVolume(ctx, s.Client(), &req)
+	return err
+}
+
+// unmountVmfsVolume is a stop-gap method that implements UnmountVmfsVolume.
+// It will be removed once the higher level HostStorageSystem object supports
+// this method.
+func unmountVmfsVolume(s *object.HostStorageSystem, vmfsUUID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	req := types.UnmountVmfsVolume{
+		This:     s.Reference(),
+		VmfsUuid: vmfsUUID,
+	}
+	_, err := methods.UnmountVmfsVolume(ctx, s.Client(), &req)
+	return err
+}
@@ -0,0 +1,125 @@
+package alarm
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// reference returns a ManagedObjectReference for an Alarm from its managed
+// object ID.
+func reference(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{
+		Type:  "Alarm",
+		Value: id,
+	}
+}
+
+// alarmManager returns the reference to the alarm manager, or an error if
+// alarms are not supported on the connection.
+func alarmManager(client *govmomi.Client) (types.ManagedObjectReference, error) {
+	if client.ServiceContent.AlarmManager == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("alarms are not supported on this connection")
+	}
+	return *client.ServiceContent.AlarmManager, nil
+}
+
+// Properties fetches the Alarm MO for the alarm with the supplied managed
+// object ID.
+func Properties(client *govmomi.Client, id string) (*mo.Alarm, error) {
+	log.Printf("[DEBUG] Fetching properties for alarm %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.Alarm
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, reference(id), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// BySystemName returns the Alarm MO of the built-in alarm with the supplied
+// system name, such as alarm.DatastoreDiskUsageAlarm.
+func BySystemName(client *govmomi.Client, name string) (*mo.Alarm, error) {
+	log.Printf("[DEBUG] Looking for built-in alarm %q", name)
+	am, err := alarmManager(client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	res, err := methods.GetAlarm(ctx, client.Client, &types.GetAlarm{This: am})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Returnval) < 1 {
+		return nil, fmt.Errorf("built-in alarm %q not found", name)
+	}
+	var alarms []mo.Alarm
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.Retrieve(ctx, res.Returnval, []string{"info"}, &alarms); err != nil {
+		return nil, err
+	}
+	for i := range alarms {
+		if alarms[i].Info.SystemName == name {
+			return &alarms[i], nil
+		}
+	}
+	return nil, fmt.Errorf("built-in alarm %q not found", name)
+}
+
+// Create creates an alarm on the supplied managed entity, and returns the
+// managed object ID of the new alarm.
+func Create(client *govmomi.Client, entity types.ManagedObjectReference, spec types.AlarmSpec) (string, error) {
+	log.Printf("[DEBUG] Creating alarm %q on %s %q", spec.Name, entity.Type, entity.Value)
+	am, err := alarmManager(client)
+	if err != nil {
+		return "", err
+	}
+	req := types.CreateAlarm{
+		This:   am,
+		Entity: entity,
+		Spec:   &spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	res, err := methods.CreateAlarm(ctx, client.Client, &req)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[DEBUG] Alarm %q created with ID %q", spec.Name, res.Returnval.Value)
+	return res.Returnval.Value, nil
+}
+
+// Reconfigure replaces the specification of the alarm with the supplied
+// managed object ID.
+func Reconfigure(client *govmomi.Client, id string, spec types.AlarmSpec) error {
+	log.Printf("[DEBUG] Reconfiguring alarm %q", id)
+	req := types.ReconfigureAlarm{
+		This: reference(id),
+		Spec: &spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.ReconfigureAlarm(ctx, client.Client, &req)
+	return err
+}
+
+// Remove removes the alarm with the supplied managed object ID.
+func Remove(client *govmomi.Client, id string) error {
+	log.Printf("[DEBUG] Removing alarm %q", id)
+	req := types.RemoveAlarm{
+		This: reference(id),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.RemoveAlarm(ctx, client.Client, &req)
+	return err
+}
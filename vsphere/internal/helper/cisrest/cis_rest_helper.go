@@ -0,0 +1,76 @@
+package cisrest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/vic/pkg/vsphere/tags"
+)
+
+// sessionIDHeader is the header that the CIS REST API session ID is sent in.
+const sessionIDHeader = "vmware-api-session-id"
+
+// ErrNotFound is returned by Call when the CIS REST API responds with 404 Not
+// Found.
+var ErrNotFound = errors.New("not found")
+
+// Call sends a request to the CIS REST API at server, using the session of
+// client. query is the raw query string of the request, which carries the
+// action for POST requests. The "value" field of the response is decoded into
+// out, if out is not nil.
+func Call(client *tags.RestClient, server *url.URL, method, path, query string, in, out interface{}) error {
+	return CallWithTimeout(client, server, method, path, query, in, out, provider.DefaultAPITimeout)
+}
+
+// CallWithTimeout is Call with a timeout other than the default API timeout,
+// for requests that do their work before they respond, such as deploying an
+// OVF template.
+func CallWithTimeout(client *tags.RestClient, server *url.URL, method, path, query string, in, out interface{}, timeout time.Duration) error {
+	u := url.URL{Scheme: server.Scheme, Host: server.Host, Path: path, RawQuery: query}
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, u.String(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, client.SessionID())
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	resp, err := client.HTTP.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, bytes.TrimSpace(b))
+	case out == nil:
+		return nil
+	}
+	var v struct {
+		Value interface{} `json:"value"`
+	}
+	v.Value = out
+	return json.Unmarshal(b, &v)
+}
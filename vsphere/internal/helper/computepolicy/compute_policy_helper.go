@@ -0,0 +1,112 @@
+package computepolicy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/cisrest"
+	"github.com/vmware/vic/pkg/vsphere/tags"
+)
+
+// policyPath is the path of the compute policy service in the CIS REST API.
+const policyPath = "/rest/vcenter/compute/policies"
+
+// capabilityPrefix is the prefix of the identifiers of compute policy
+// capabilities.
+const capabilityPrefix = "com.vmware.vcenter.compute.policies.capabilities."
+
+// The following are the capabilities of compute policies that the provider
+// supports, without capabilityPrefix.
+const (
+	CapabilityVMHostAffinity     = "vm_host_affinity"
+	CapabilityVMHostAntiAffinity = "vm_host_anti_affinity"
+	CapabilityVMVMAffinity       = "vm_vm_affinity"
+	CapabilityVMVMAntiAffinity   = "vm_vm_anti_affinity"
+	CapabilityDisableDRSVMotion  = "disable_drs_vmotion"
+)
+
+// Capabilities is the list of the capabilities of compute policies that the
+// provider supports.
+var Capabilities = []string{
+	CapabilityVMHostAffinity,
+	CapabilityVMHostAntiAffinity,
+	CapabilityVMVMAffinity,
+	CapabilityVMVMAntiAffinity,
+	CapabilityDisableDRSVMotion,
+}
+
+// ErrNotFound is returned when a compute policy does not exist.
+var ErrNotFound = errors.New("compute policy not found")
+
+// Spec is the definition of a compute policy. Capability is one of
+// Capabilities. HostTag is only used by the VM-host capabilities.
+type Spec struct {
+	Capability  string
+	Name        string
+	Description string
+	VMTag       string
+	HostTag     string
+}
+
+// Policy is the part of a compute policy that the provider uses.
+type Policy struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Capability  string `json:"capability"`
+	VMTag       string `json:"vm_tag"`
+	HostTag     string `json:"host_tag"`
+}
+
+// UsesHostTag returns true if compute policies with the supplied capability
+// apply to hosts with a tag, as well as to virtual machines.
+func UsesHostTag(capability string) bool {
+	return capability == CapabilityVMHostAffinity || capability == CapabilityVMHostAntiAffinity
+}
+
+// Create creates a compute policy, returning its ID.
+func Create(client *tags.RestClient, server *url.URL, spec Spec) (string, error) {
+	log.Printf("[DEBUG] Creating compute policy %q (capability %s)", spec.Name, spec.Capability)
+	s := map[string]interface{}{
+		"@class":      capabilityPrefix + spec.Capability + ".create_spec",
+		"name":        spec.Name,
+		"description": spec.Description,
+		"vm_tag":      spec.VMTag,
+	}
+	if UsesHostTag(spec.Capability) {
+		s["host_tag"] = spec.HostTag
+	}
+	var id string
+	if err := cisrest.Call(client, server, http.MethodPost, policyPath, "", map[string]interface{}{"spec": s}, &id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// FromID returns the compute policy with the supplied ID. The capability of
+// the policy is returned without capabilityPrefix.
+func FromID(client *tags.RestClient, server *url.URL, id string) (*Policy, error) {
+	log.Printf("[DEBUG] Fetching compute policy %q", id)
+	var policy Policy
+	if err := cisrest.Call(client, server, http.MethodGet, fmt.Sprintf("%s/%s", policyPath, id), "", nil, &policy); err != nil {
+		if err == cisrest.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	policy.Capability = strings.TrimPrefix(policy.Capability, capabilityPrefix)
+	return &policy, nil
+}
+
+// Delete deletes the compute policy with the supplied ID.
+func Delete(client *tags.RestClient, server *url.URL, id string) error {
+	log.Printf("[DEBUG] Deleting compute policy %q", id)
+	err := cisrest.Call(client, server, http.MethodDelete, fmt.Sprintf("%s/%s", policyPath, id), "", nil, nil)
+	if err == cisrest.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
@@ -8,9 +8,11 @@ import (
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/envbrowse"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -76,9 +78,31 @@ func ClusterFromID(client *govmomi.Client, id string) (*object.ClusterComputeRes
 	return obj.(*object.ClusterComputeResource), nil
 }
 
-// BaseFromPath returns a BaseComputeResource for a given path.
-func BaseFromPath(client *govmomi.Client, path string) (BaseComputeResource, error) {
+// PlaceVM runs a DRS placement request for the supplied PlacementSpec against
+// a cluster, and returns the result. The request is advisory only - no action
+// is taken on the recommendations returned.
+func PlaceVM(cluster *object.ClusterComputeResource, spec types.PlacementSpec) (*types.PlacementResult, error) {
+	log.Printf("[DEBUG] Requesting DRS placement recommendations from cluster %q", cluster.Reference().Value)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.PlaceVm{
+		This:          cluster.Reference(),
+		PlacementSpec: spec,
+	}
+	res, err := methods.PlaceVm(ctx, cluster.Client(), &req)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Returnval, nil
+}
+
+// BaseFromPath returns a BaseComputeResource for a given path. If a
+// datacenter is supplied, relative paths are searched for in that datacenter.
+func BaseFromPath(client *govmomi.Client, path string, dc *object.Datacenter) (BaseComputeResource, error) {
 	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
 	defer cancel()
@@ -161,6 +185,58 @@ func OSFamily(client *govmomi.Client, ref types.ManagedObjectReference, guest st
 	return b.OSFamily(ctx, guest)
 }
 
+// GuestOSDescriptorsFromReference uses the compute resource's environment
+// browser to get the guest operating systems supported for the optionally
+// supplied hardware version key and host.
+func GuestOSDescriptorsFromReference(client *govmomi.Client, ref types.ManagedObjectReference, key string, host *object.HostSystem) ([]types.GuestOsDescriptor, error) {
+	log.Printf("[DEBUG] Fetching guest OS descriptors for object reference %q (key %q)", ref.Value, key)
+	b, err := EnvironmentBrowserFromReference(client, ref)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return b.GuestOSDescriptors(ctx, key, host)
+}
+
+// MaxHardwareVersionFromReference uses the compute resource's environment
+// browser to get the highest virtual machine hardware version that can be used
+// to either create or upgrade virtual machines on the compute resource.
+func MaxHardwareVersionFromReference(client *govmomi.Client, ref types.ManagedObjectReference) (int, error) {
+	log.Printf("[DEBUG] Fetching maximum hardware version for object reference %q", ref.Value)
+	b, err := EnvironmentBrowserFromReference(client, ref)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	descs, err := b.QueryConfigOptionDescriptor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var max int
+	for _, desc := range descs {
+		createSupported := desc.CreateSupported != nil && *desc.CreateSupported
+		upgradeSupported := desc.UpgradeSupported != nil && *desc.UpgradeSupported
+		if !createSupported && !upgradeSupported {
+			continue
+		}
+		v, err := viapi.ParseHardwareVersion(desc.Key)
+		if err != nil {
+			log.Printf("[DEBUG] Skipping config option descriptor %q: %s", desc.Key, err)
+			continue
+		}
+		if v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		return 0, fmt.Errorf("no supported hardware versions found for compute resource %q", ref.Value)
+	}
+	log.Printf("[DEBUG] Maximum hardware version for object reference %q is %d", ref.Value, max)
+	return max, nil
+}
+
 // EnvironmentBrowserFromReference loads an environment browser for the
 // specific compute resource reference. The reference can be either a
 // standalone host or cluster.
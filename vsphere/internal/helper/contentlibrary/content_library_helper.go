@@ -0,0 +1,474 @@
+package contentlibrary
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/cisrest"
+	"github.com/vmware/vic/pkg/vsphere/tags"
+)
+
+// libraryPath is the path of the content library service in the CIS REST API.
+const libraryPath = "/rest/com/vmware/content/library"
+
+// localLibraryPath is the path of the local content library service in the
+// CIS REST API, which creates, updates, and deletes local libraries.
+const localLibraryPath = "/rest/com/vmware/content/local-library"
+
+// itemPath is the path of the content library item service in the CIS REST
+// API.
+const itemPath = "/rest/com/vmware/content/library/item"
+
+// itemFilePath is the path of the content library item file service in the
+// CIS REST API.
+const itemFilePath = "/rest/com/vmware/content/library/item/file"
+
+// updateSessionPath is the path of the content library item update session
+// service in the CIS REST API, which uploads files to items.
+const updateSessionPath = "/rest/com/vmware/content/library/item/update-session"
+
+// updateSessionFilePath is the path of the content library item update
+// session file service in the CIS REST API.
+const updateSessionFilePath = "/rest/com/vmware/content/library/item/updatesession/file"
+
+// ovfLibraryItemPath is the path of the OVF library item service in the CIS
+// REST API, which deploys OVF templates in content libraries.
+const ovfLibraryItemPath = "/rest/com/vmware/vcenter/ovf/library-item"
+
+// vmTemplateLibraryItemPath is the path of the VM template library item
+// service in the CIS REST API, which deploys VM templates in content
+// libraries. It is only available on vCenter 6.7 Update 1 and higher.
+const vmTemplateLibraryItemPath = "/rest/vcenter/vm-template/library-items"
+
+// subscribedItemPath is the path of the subscribed content library item
+// service in the CIS REST API.
+const subscribedItemPath = "/rest/com/vmware/content/library/subscribed-item"
+
+// syncPollInterval is the interval at which a library item is checked while
+// waiting for a sync to complete.
+const syncPollInterval = time.Second * 5
+
+// The types of content library items that the provider deploys virtual
+// machines from.
+const (
+	ItemTypeOVF        = "ovf"
+	ItemTypeVMTemplate = "vm-template"
+)
+
+// The states of an update session.
+const (
+	updateSessionStateActive = "ACTIVE"
+	updateSessionStateDone   = "DONE"
+)
+
+// ErrNotFound is returned when a content library or content library item does
+// not exist.
+var ErrNotFound = errors.New("content library object not found")
+
+// Library is the part of a content library that the provider uses.
+type Library struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	Type            string           `json:"type"`
+	StorageBackings []StorageBacking `json:"storage_backings"`
+}
+
+// StorageBacking is a storage location of a content library.
+type StorageBacking struct {
+	Type        string `json:"type"`
+	DatastoreID string `json:"datastore_id,omitempty"`
+}
+
+// Item is the part of a content library item that the provider uses.
+type Item struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	LibraryID      string `json:"library_id"`
+	Type           string `json:"type"`
+	ContentVersion string `json:"content_version"`
+	LastSyncTime   string `json:"last_sync_time"`
+	Cached         bool   `json:"cached"`
+	Description    string `json:"description"`
+}
+
+// File is the part of a file in a content library item that the provider
+// uses.
+type File struct {
+	Name         string `json:"name"`
+	Size         int64  `json:"size"`
+	Cached       bool   `json:"cached"`
+	ChecksumInfo *struct {
+		Algorithm string `json:"algorithm"`
+		Checksum  string `json:"checksum"`
+	} `json:"checksum_info"`
+}
+
+// call sends a request to the CIS REST API with cisrest.Call, returning
+// ErrNotFound if the item does not exist.
+func call(client *tags.RestClient, server *url.URL, method, path, query string, in, out interface{}) error {
+	err := cisrest.Call(client, server, method, path, query, in, out)
+	if err == cisrest.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// FromID returns the content library item with the supplied ID.
+func FromID(client *tags.RestClient, server *url.URL, id string) (*Item, error) {
+	log.Printf("[DEBUG] Fetching content library item %q", id)
+	var item Item
+	if err := call(client, server, http.MethodGet, fmt.Sprintf("%s/id:%s", itemPath, id), "", nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Files returns the files of the content library item with the supplied ID.
+func Files(client *tags.RestClient, server *url.URL, id string) ([]File, error) {
+	log.Printf("[DEBUG] Fetching files of content library item %q", id)
+	var files []File
+	query := url.Values{"library_item_id": []string{id}}.Encode()
+	if err := call(client, server, http.MethodGet, itemFilePath, query, nil, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Sync starts a sync of the subscribed content library item with the
+// supplied ID. When forceContent is true, the content of the item is
+// downloaded as well, even if the library only downloads content on demand.
+// The sync runs in the background, use WaitForSync to wait for it.
+func Sync(client *tags.RestClient, server *url.URL, id string, forceContent bool) error {
+	log.Printf("[DEBUG] Starting sync of content library item %q", id)
+	in := map[string]interface{}{
+		"force_sync_content": forceContent,
+	}
+	return call(client, server, http.MethodPost, fmt.Sprintf("%s/id:%s", subscribedItemPath, id), "~action=sync", in, nil)
+}
+
+// WaitForSync waits until the last sync time of the content library item
+// with the supplied ID differs from before, and, when forceContent is true,
+// the content of the item is cached. It returns the synced item.
+func WaitForSync(client *tags.RestClient, server *url.URL, id, before string, forceContent bool, timeout time.Duration) (*Item, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		item, err := FromID(client, server, id)
+		if err != nil {
+			return nil, err
+		}
+		if item.LastSyncTime != before && (item.Cached || !forceContent) {
+			log.Printf("[DEBUG] Content library item %q synced at %s", id, item.LastSyncTime)
+			return item, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for content library item %q to sync", id)
+		}
+		time.Sleep(syncPollInterval)
+	}
+}
+
+// LibraryFromID returns the content library with the supplied ID.
+func LibraryFromID(client *tags.RestClient, server *url.URL, id string) (*Library, error) {
+	log.Printf("[DEBUG] Fetching content library %q", id)
+	var library Library
+	if err := call(client, server, http.MethodGet, fmt.Sprintf("%s/id:%s", libraryPath, id), "", nil, &library); err != nil {
+		return nil, err
+	}
+	return &library, nil
+}
+
+// LibraryFromName returns the content library with the supplied name.
+func LibraryFromName(client *tags.RestClient, server *url.URL, name string) (*Library, error) {
+	log.Printf("[DEBUG] Looking up content library %q", name)
+	in := map[string]interface{}{
+		"spec": map[string]interface{}{"name": name},
+	}
+	var ids []string
+	if err := call(client, server, http.MethodPost, libraryPath, "~action=find", in, &ids); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(ids) < 1:
+		return nil, fmt.Errorf("content library %q not found", name)
+	case len(ids) > 1:
+		return nil, fmt.Errorf("multiple content libraries named %q found", name)
+	}
+	return LibraryFromID(client, server, ids[0])
+}
+
+// CreateLocalLibrary creates a local content library that stores its items
+// on the datastore with the supplied ID, returning the ID of the library.
+func CreateLocalLibrary(client *tags.RestClient, server *url.URL, name, description, datastoreID string) (string, error) {
+	log.Printf("[DEBUG] Creating content library %q on datastore %q", name, datastoreID)
+	in := map[string]interface{}{
+		"create_spec": map[string]interface{}{
+			"name":        name,
+			"description": description,
+			"type":        "LOCAL",
+			"storage_backings": []StorageBacking{
+				{Type: "DATASTORE", DatastoreID: datastoreID},
+			},
+		},
+	}
+	var id string
+	if err := call(client, server, http.MethodPost, localLibraryPath, "", in, &id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateLocalLibrary changes the name and description of the local content
+// library with the supplied ID.
+func UpdateLocalLibrary(client *tags.RestClient, server *url.URL, id, name, description string) error {
+	log.Printf("[DEBUG] Updating content library %q", id)
+	in := map[string]interface{}{
+		"update_spec": map[string]interface{}{
+			"name":        name,
+			"description": description,
+		},
+	}
+	return call(client, server, http.MethodPatch, fmt.Sprintf("%s/id:%s", localLibraryPath, id), "", in, nil)
+}
+
+// DeleteLocalLibrary deletes the local content library with the supplied ID,
+// and all of its items.
+func DeleteLocalLibrary(client *tags.RestClient, server *url.URL, id string) error {
+	log.Printf("[DEBUG] Deleting content library %q", id)
+	return call(client, server, http.MethodDelete, fmt.Sprintf("%s/id:%s", localLibraryPath, id), "", nil, nil)
+}
+
+// ItemFromName returns the item with the supplied name in the content library
+// with the supplied ID.
+func ItemFromName(client *tags.RestClient, server *url.URL, libraryID, name string) (*Item, error) {
+	log.Printf("[DEBUG] Looking up item %q in content library %q", name, libraryID)
+	in := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"name":       name,
+			"library_id": libraryID,
+		},
+	}
+	var ids []string
+	if err := call(client, server, http.MethodPost, itemPath, "~action=find", in, &ids); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(ids) < 1:
+		return nil, fmt.Errorf("item %q not found in content library %q", name, libraryID)
+	case len(ids) > 1:
+		return nil, fmt.Errorf("multiple items named %q found in content library %q", name, libraryID)
+	}
+	return FromID(client, server, ids[0])
+}
+
+// CreateItem creates an empty item in the content library with the supplied
+// ID, returning the ID of the item. Use Upload to add files to it.
+func CreateItem(client *tags.RestClient, server *url.URL, libraryID, name, description, itemType string) (string, error) {
+	log.Printf("[DEBUG] Creating item %q in content library %q", name, libraryID)
+	in := map[string]interface{}{
+		"create_spec": map[string]interface{}{
+			"library_id":  libraryID,
+			"name":        name,
+			"description": description,
+			"type":        itemType,
+		},
+	}
+	var id string
+	if err := call(client, server, http.MethodPost, itemPath, "", in, &id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateItem changes the name and description of the content library item
+// with the supplied ID.
+func UpdateItem(client *tags.RestClient, server *url.URL, id, name, description string) error {
+	log.Printf("[DEBUG] Updating content library item %q", id)
+	in := map[string]interface{}{
+		"update_spec": map[string]interface{}{
+			"name":        name,
+			"description": description,
+		},
+	}
+	return call(client, server, http.MethodPatch, fmt.Sprintf("%s/id:%s", itemPath, id), "", in, nil)
+}
+
+// DeleteItem deletes the content library item with the supplied ID.
+func DeleteItem(client *tags.RestClient, server *url.URL, id string) error {
+	log.Printf("[DEBUG] Deleting content library item %q", id)
+	return call(client, server, http.MethodDelete, fmt.Sprintf("%s/id:%s", itemPath, id), "", nil, nil)
+}
+
+// Upload adds files to the content library item with the supplied ID, and
+// waits for the upload to complete. files maps the names of the files in the
+// item to the URLs that vCenter downloads them from. The update session of
+// the upload is removed when it is done, whether it succeeded or not.
+func Upload(client *tags.RestClient, server *url.URL, id string, files map[string]string, timeout time.Duration) error {
+	log.Printf("[DEBUG] Uploading %d file(s) to content library item %q", len(files), id)
+	in := map[string]interface{}{
+		"create_spec": map[string]interface{}{"library_item_id": id},
+	}
+	var sessionID string
+	if err := call(client, server, http.MethodPost, updateSessionPath, "", in, &sessionID); err != nil {
+		return fmt.Errorf("error creating update session: %s", err)
+	}
+	defer func() {
+		if err := call(client, server, http.MethodDelete, fmt.Sprintf("%s/id:%s", updateSessionPath, sessionID), "", nil, nil); err != nil {
+			log.Printf("[DEBUG] Error removing update session %q: %s", sessionID, err)
+		}
+	}()
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		in := map[string]interface{}{
+			"file_spec": map[string]interface{}{
+				"name":            name,
+				"source_type":     "PULL",
+				"source_endpoint": map[string]interface{}{"uri": files[name]},
+			},
+		}
+		if err := call(client, server, http.MethodPost, fmt.Sprintf("%s/id:%s", updateSessionFilePath, sessionID), "~action=add", in, nil); err != nil {
+			return fmt.Errorf("error adding file %q: %s", name, err)
+		}
+	}
+	if err := call(client, server, http.MethodPost, fmt.Sprintf("%s/id:%s", updateSessionPath, sessionID), "~action=complete", nil, nil); err != nil {
+		return fmt.Errorf("error completing update session: %s", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var session struct {
+			State        string `json:"state"`
+			ErrorMessage *struct {
+				DefaultMessage string `json:"default_message"`
+			} `json:"error_message"`
+		}
+		if err := call(client, server, http.MethodGet, fmt.Sprintf("%s/id:%s", updateSessionPath, sessionID), "", nil, &session); err != nil {
+			return fmt.Errorf("error fetching update session: %s", err)
+		}
+		switch session.State {
+		case updateSessionStateDone:
+			log.Printf("[DEBUG] Upload to content library item %q complete", id)
+			return nil
+		case updateSessionStateActive:
+		default:
+			if session.ErrorMessage != nil {
+				return fmt.Errorf("upload %s: %s", strings.ToLower(session.State), session.ErrorMessage.DefaultMessage)
+			}
+			return fmt.Errorf("upload %s", strings.ToLower(session.State))
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for upload to content library item %q to complete", id)
+		}
+		time.Sleep(syncPollInterval)
+	}
+}
+
+// DeploySpec is the placement of a virtual machine deployed from a content
+// library item. HostID is optional.
+type DeploySpec struct {
+	Name           string
+	ResourcePoolID string
+	FolderID       string
+	HostID         string
+	DatastoreID    string
+}
+
+// Deploy deploys a virtual machine from the OVF template or VM template in
+// the supplied content library item, returning the managed object ID of the
+// virtual machine. The virtual machine is left powered off.
+func Deploy(client *tags.RestClient, server *url.URL, item *Item, spec DeploySpec, timeout time.Duration) (string, error) {
+	switch item.Type {
+	case ItemTypeOVF:
+		return deployOVF(client, server, item.ID, spec, timeout)
+	case ItemTypeVMTemplate:
+		return deployVMTemplate(client, server, item.ID, spec, timeout)
+	}
+	return "", fmt.Errorf("content library item %q is of type %q, only %s and %s items can be deployed", item.Name, item.Type, ItemTypeOVF, ItemTypeVMTemplate)
+}
+
+// deployOVF deploys the OVF template in a content library item.
+func deployOVF(client *tags.RestClient, server *url.URL, id string, spec DeploySpec, timeout time.Duration) (string, error) {
+	log.Printf("[DEBUG] Deploying virtual machine %q from OVF template in content library item %q", spec.Name, id)
+	target := map[string]interface{}{
+		"resource_pool_id": spec.ResourcePoolID,
+		"folder_id":        spec.FolderID,
+	}
+	if spec.HostID != "" {
+		target["host_id"] = spec.HostID
+	}
+	in := map[string]interface{}{
+		"target": target,
+		"deployment_spec": map[string]interface{}{
+			"name":                 spec.Name,
+			"accept_all_EULA":      true,
+			"default_datastore_id": spec.DatastoreID,
+		},
+	}
+	var result struct {
+		Succeeded  bool `json:"succeeded"`
+		ResourceID struct {
+			ID string `json:"id"`
+		} `json:"resource_id"`
+		Error struct {
+			Errors []struct {
+				Error struct {
+					Messages []struct {
+						DefaultMessage string `json:"default_message"`
+					} `json:"messages"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	err := cisrest.CallWithTimeout(client, server, http.MethodPost, fmt.Sprintf("%s/id:%s", ovfLibraryItemPath, id), "~action=deploy", in, &result, timeout)
+	if err != nil {
+		return "", err
+	}
+	if !result.Succeeded {
+		var msgs []string
+		for _, e := range result.Error.Errors {
+			for _, m := range e.Error.Messages {
+				msgs = append(msgs, m.DefaultMessage)
+			}
+		}
+		return "", fmt.Errorf("deployment failed: %s", strings.Join(msgs, "; "))
+	}
+	return result.ResourceID.ID, nil
+}
+
+// deployVMTemplate deploys the VM template in a content library item.
+func deployVMTemplate(client *tags.RestClient, server *url.URL, id string, spec DeploySpec, timeout time.Duration) (string, error) {
+	log.Printf("[DEBUG] Deploying virtual machine %q from VM template in content library item %q", spec.Name, id)
+	placement := map[string]interface{}{
+		"resource_pool": spec.ResourcePoolID,
+		"folder":        spec.FolderID,
+	}
+	if spec.HostID != "" {
+		placement["host"] = spec.HostID
+	}
+	in := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"name":            spec.Name,
+			"placement":       placement,
+			"vm_home_storage": map[string]interface{}{"datastore": spec.DatastoreID},
+			"disk_storage":    map[string]interface{}{"datastore": spec.DatastoreID},
+			"powered_on":      false,
+		},
+	}
+	var vmID string
+	err := cisrest.CallWithTimeout(client, server, http.MethodPost, fmt.Sprintf("%s/%s", vmTemplateLibraryItemPath, id), "action=deploy", in, &vmID, timeout)
+	if err == cisrest.ErrNotFound {
+		return "", fmt.Errorf("deploying VM templates from content libraries requires vCenter 6.7 Update 1 or higher")
+	}
+	return vmID, err
+}
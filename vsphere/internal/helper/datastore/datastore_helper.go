@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
@@ -9,10 +10,13 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -177,3 +181,98 @@ func FileExists(ds *object.Datastore, name string) (bool, error) {
 	}
 	return path.Base(name) == files[0].Path, nil
 }
+
+// FirstClassDisks returns the first class disks (virtual storage objects)
+// that reside on a datastore. This requires vCenter 6.5 or higher.
+func FirstClassDisks(client *govmomi.Client, ds *object.Datastore) ([]types.VStorageObject, error) {
+	log.Printf("[DEBUG] Listing first class disks on datastore %q", ds)
+	if client.ServiceContent.VStorageObjectManager == nil {
+		return nil, fmt.Errorf("first class disks are not supported on this connection")
+	}
+	mgr := *client.ServiceContent.VStorageObjectManager
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	lreq := types.ListVStorageObject{
+		This:      mgr,
+		Datastore: ds.Reference(),
+	}
+	lres, err := methods.ListVStorageObject(ctx, client.Client, &lreq)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []types.VStorageObject
+	for _, id := range lres.Returnval {
+		rreq := types.RetrieveVStorageObject{
+			This:      mgr,
+			Id:        id,
+			Datastore: ds.Reference(),
+		}
+		rres, err := methods.RetrieveVStorageObject(ctx, client.Client, &rreq)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving first class disk %q: %s", id.Id, err)
+		}
+		objs = append(objs, rres.Returnval)
+	}
+	log.Printf("[DEBUG] Found %d first class disks on datastore %q", len(objs), ds)
+	return objs, nil
+}
+
+// Datacenter returns the datacenter that a datastore is in, from the
+// inventory path of the datastore.
+func Datacenter(client *govmomi.Client, ds *object.Datastore) (*object.Datacenter, error) {
+	dcp, err := folder.RootPathParticleDatastore.SplitDatacenter(ds.InventoryPath)
+	if err != nil {
+		return nil, err
+	}
+	finder := find.NewFinder(client.Client, false)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return finder.Datacenter(ctx, dcp)
+}
+
+// UploadFile writes data to a file on a datastore, overwriting the file if it
+// exists. The path is a bare path, not a datastore path. If createDirectories
+// is true, the missing directories of the path are created first.
+func UploadFile(client *govmomi.Client, ds *object.Datastore, name string, data []byte, createDirectories bool) error {
+	dc, err := Datacenter(client, ds)
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter of datastore %q: %s", ds.Name(), err)
+	}
+	if dir := path.Dir(name); createDirectories && dir != "." {
+		log.Printf("[DEBUG] Creating directory %q on datastore %q", dir, ds.Name())
+		fm := object.NewFileManager(client.Client)
+		ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		defer cancel()
+		if err := fm.MakeDirectory(ctx, ds.Path(dir), dc, true); err != nil && !viapi.IsFileAlreadyExistsError(err) {
+			return fmt.Errorf("error creating directory %q: %s", dir, err)
+		}
+	}
+	log.Printf("[DEBUG] Uploading %d bytes to %q", len(data), ds.Path(name))
+	// Uploads through the datastore URL need the datacenter path, which is not
+	// set on datastores located by ID.
+	ds.DatacenterPath = dc.InventoryPath
+	p := soap.DefaultUpload
+	p.ContentLength = int64(len(data))
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return ds.Upload(ctx, bytes.NewReader(data), name, &p)
+}
+
+// DeleteFile deletes a file from a datastore. The path is a bare path, not a
+// datastore path.
+func DeleteFile(client *govmomi.Client, ds *object.Datastore, name string) error {
+	dc, err := Datacenter(client, ds)
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter of datastore %q: %s", ds.Name(), err)
+	}
+	log.Printf("[DEBUG] Deleting %q", ds.Path(name))
+	fm := object.NewFileManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := fm.DeleteDatastoreFile(ctx, ds.Path(name), dc)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
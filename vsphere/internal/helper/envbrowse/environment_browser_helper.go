@@ -97,6 +97,29 @@ func (b *EnvironmentBrowser) OSFamily(ctx context.Context, guest string) (string
 	return "", fmt.Errorf("could not find guest ID %q", guest)
 }
 
+// GuestOSDescriptors returns the list of guest operating systems supported by
+// the config option for the optionally supplied host and descriptor key. If no
+// key is supplied, the results generally reflect the most recent VM hardware
+// version.
+func (b *EnvironmentBrowser) GuestOSDescriptors(ctx context.Context, key string, host *object.HostSystem) ([]types.GuestOsDescriptor, error) {
+	req := types.QueryConfigOption{
+		This: b.Reference(),
+		Key:  key,
+	}
+	if host != nil {
+		ref := host.Reference()
+		req.Host = &ref
+	}
+	res, err := methods.QueryConfigOption(ctx, b.Client(), &req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Returnval == nil {
+		return nil, errors.New("no config options were found for the supplied criteria")
+	}
+	return res.Returnval.GuestOSDescriptor, nil
+}
+
 // QueryConfigOptionDescriptor returns a list the list of ConfigOption keys
 // available on the environment that this browser targets. The keys can be used
 // as query options for DefaultDevices and other functions, facilitating the
@@ -0,0 +1,75 @@
+package extension
+
+import (
+	"context"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// FromKey returns the extension registered with vCenter under the supplied
+// key. nil is returned if there is no such extension.
+func FromKey(client *govmomi.Client, key string) (*types.Extension, error) {
+	log.Printf("[DEBUG] Locating extension %q", key)
+	m, err := object.GetExtensionManager(client.Client)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.Find(ctx, key)
+}
+
+// Register registers an extension with vCenter.
+func Register(client *govmomi.Client, ext types.Extension) error {
+	log.Printf("[DEBUG] Registering extension %q", ext.Key)
+	m, err := object.GetExtensionManager(client.Client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.Register(ctx, ext)
+}
+
+// Update replaces the registration of an extension with vCenter. The
+// registration is matched by the key of the extension.
+func Update(client *govmomi.Client, ext types.Extension) error {
+	log.Printf("[DEBUG] Updating extension %q", ext.Key)
+	m, err := object.GetExtensionManager(client.Client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.Update(ctx, ext)
+}
+
+// SetCertificate sets the certificate that the extension with the supplied
+// key uses to log in to vCenter. certificatePem is a PEM-encoded X.509
+// certificate.
+func SetCertificate(client *govmomi.Client, key, certificatePem string) error {
+	log.Printf("[DEBUG] Setting certificate of extension %q", key)
+	m, err := object.GetExtensionManager(client.Client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.SetCertificate(ctx, key, certificatePem)
+}
+
+// Unregister removes the extension with the supplied key from vCenter.
+func Unregister(client *govmomi.Client, key string) error {
+	log.Printf("[DEBUG] Unregistering extension %q", key)
+	m, err := object.GetExtensionManager(client.Client)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return m.Unregister(ctx, key)
+}
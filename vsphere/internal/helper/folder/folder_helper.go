@@ -233,6 +233,101 @@ func VirtualMachineFolderFromObject(client *govmomi.Client, obj interface{}, rel
 	return validateVirtualMachineFolder(folder)
 }
 
+// CreateVirtualMachineFolderFromObject returns an *object.Folder from a given
+// object and relative VM folder path, like VirtualMachineFolderFromObject.
+// Any folders in the path that do not exist are created, starting from the
+// VM root folder of the object's datacenter.
+func CreateVirtualMachineFolderFromObject(client *govmomi.Client, obj interface{}, relative string) (*object.Folder, error) {
+	log.Printf("[DEBUG] Locating or creating folder at path %q relative to virtual machine root", relative)
+	parent, err := folderFromObject(client, obj, RootPathParticleVM, "")
+	if err != nil {
+		return nil, err
+	}
+	p := NormalizePath(relative)
+	if p == "" {
+		return validateVirtualMachineFolder(parent)
+	}
+	for _, name := range strings.Split(p, "/") {
+		fp := parent.InventoryPath + "/" + name
+		f, err := FromAbsolutePath(client, fp)
+		if err != nil {
+			if _, ok := err.(*find.NotFoundError); !ok {
+				return nil, err
+			}
+			log.Printf("[DEBUG] Creating folder %q", fp)
+			ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+			f, err = parent.CreateFolder(ctx, name)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("error creating folder %q: %s", fp, err)
+			}
+			f.InventoryPath = fp
+		}
+		if parent, err = validateVirtualMachineFolder(f); err != nil {
+			return nil, err
+		}
+	}
+	return parent, nil
+}
+
+// NearestVirtualMachineFolderFromObject returns the VM folder at the supplied
+// relative path, or if it does not exist, its nearest parent that does. The
+// returned bool is true if the folder at the full path exists.
+func NearestVirtualMachineFolderFromObject(client *govmomi.Client, obj interface{}, relative string) (*object.Folder, bool, error) {
+	exists := true
+	for p := NormalizePath(relative); ; p = path.Dir(p) {
+		if p == "." {
+			p = ""
+		}
+		f, err := VirtualMachineFolderFromObject(client, obj, p)
+		if err == nil {
+			return f, exists, nil
+		}
+		if _, ok := err.(*find.NotFoundError); !ok || p == "" {
+			return nil, false, err
+		}
+		exists = false
+	}
+}
+
+// RemoveEmptyVirtualMachineFolders removes the VM folder at the supplied
+// relative path, and then each of its parents in turn, for as long as they
+// are empty. Folders that do not exist are skipped. The VM root folder is
+// never removed.
+func RemoveEmptyVirtualMachineFolders(client *govmomi.Client, obj interface{}, relative string) error {
+	for p := NormalizePath(relative); p != "" && p != "."; p = path.Dir(p) {
+		f, err := folderFromObject(client, obj, RootPathParticleVM, p)
+		if err != nil {
+			if _, ok := err.(*find.NotFoundError); ok {
+				continue
+			}
+			return err
+		}
+		ne, err := HasChildren(f)
+		if err != nil {
+			return fmt.Errorf("error checking for contents of folder %q: %s", f.InventoryPath, err)
+		}
+		if ne {
+			log.Printf("[DEBUG] Folder %q is not empty, stopping folder removal", f.InventoryPath)
+			return nil
+		}
+		log.Printf("[DEBUG] Removing empty folder %q", f.InventoryPath)
+		ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		task, err := f.Destroy(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("cannot delete folder %q: %s", f.InventoryPath, err)
+		}
+		tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		err = task.Wait(tctx)
+		tcancel()
+		if err != nil {
+			return fmt.Errorf("error on waiting for deletion of folder %q: %s", f.InventoryPath, err)
+		}
+	}
+	return nil
+}
+
 // networkFolderFromObject returns an *object.Folder from a given object,
 // and relative network folder path. If no such folder is found, of if it is
 // not a network folder, an appropriate error will be returned.
@@ -0,0 +1,54 @@
+package hostsystem
+
+import (
+	"context"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// accountManager returns the HostLocalAccountManager of a host. On vCenter,
+// this is the manager of the host itself, which is available on ESXi 6.0 and
+// higher.
+func accountManager(ctx context.Context, host *object.HostSystem) (*object.HostAccountManager, error) {
+	return host.ConfigManager().AccountManager(ctx)
+}
+
+// CreateLocalUser creates a local user account on a host.
+func CreateLocalUser(host *object.HostSystem, spec *types.HostAccountSpec) error {
+	log.Printf("[DEBUG] Creating local user %q on host %q", spec.Id, host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := accountManager(ctx, host)
+	if err != nil {
+		return err
+	}
+	return m.Create(ctx, spec)
+}
+
+// UpdateLocalUser updates the description or password of a local user account
+// on a host. Fields that are empty in the spec are left as is.
+func UpdateLocalUser(host *object.HostSystem, spec *types.HostAccountSpec) error {
+	log.Printf("[DEBUG] Updating local user %q on host %q", spec.Id, host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := accountManager(ctx, host)
+	if err != nil {
+		return err
+	}
+	return m.Update(ctx, spec)
+}
+
+// RemoveLocalUser removes a local user account from a host.
+func RemoveLocalUser(host *object.HostSystem, name string) error {
+	log.Printf("[DEBUG] Removing local user %q from host %q", name, host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m, err := accountManager(ctx, host)
+	if err != nil {
+		return err
+	}
+	return m.Remove(ctx, name)
+}
@@ -0,0 +1,88 @@
+package hostsystem
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// UpdateGraphicsConfig sets the graphics configuration of a host. Changes take
+// effect once the Xorg service on the host is restarted, or the host is
+// rebooted.
+func UpdateGraphicsConfig(client *govmomi.Client, host *object.HostSystem, config types.HostGraphicsConfig) error {
+	props, err := Properties(host)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.ConfigManager.GraphicsManager == nil {
+		return fmt.Errorf("host %q does not support graphics configuration", host.Name())
+	}
+	log.Printf("[DEBUG] Updating graphics configuration of host %q (default type %q)", host.Name(), config.HostDefaultGraphicsType)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.UpdateGraphicsConfig{
+		This:   *props.ConfigManager.GraphicsManager,
+		Config: config,
+	}
+	_, err = methods.UpdateGraphicsConfig(ctx, client.Client, &req)
+	return err
+}
+
+// kernelModuleSystemRef returns the reference to the HostKernelModuleSystem of
+// a host.
+func kernelModuleSystemRef(host *object.HostSystem) (types.ManagedObjectReference, error) {
+	props, err := Properties(host)
+	if err != nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.ConfigManager.KernelModuleSystem == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("host %q does not support kernel module configuration", host.Name())
+	}
+	return *props.ConfigManager.KernelModuleSystem, nil
+}
+
+// ModuleOptions returns the configured option string of a kernel module on a
+// host.
+func ModuleOptions(client *govmomi.Client, host *object.HostSystem, name string) (string, error) {
+	ref, err := kernelModuleSystemRef(host)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.QueryConfiguredModuleOptionString{
+		This: ref,
+		Name: name,
+	}
+	res, err := methods.QueryConfiguredModuleOptionString(ctx, client.Client, &req)
+	if err != nil {
+		return "", err
+	}
+	return res.Returnval, nil
+}
+
+// UpdateModuleOptions sets the option string of a kernel module on a host.
+// The options are used the next time that the module is loaded, which usually
+// means the next time that the host is rebooted.
+func UpdateModuleOptions(client *govmomi.Client, host *object.HostSystem, name, options string) error {
+	ref, err := kernelModuleSystemRef(host)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Setting options of kernel module %q on host %q to %q", name, host.Name(), options)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.UpdateModuleOptionString{
+		This:    ref,
+		Name:    name,
+		Options: options,
+	}
+	_, err = methods.UpdateModuleOptionString(ctx, client.Client, &req)
+	return err
+}
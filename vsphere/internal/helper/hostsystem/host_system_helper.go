@@ -9,6 +9,7 @@ import (
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -54,6 +55,59 @@ func FromID(client *govmomi.Client, id string) (*object.HostSystem, error) {
 	return hs.(*object.HostSystem), nil
 }
 
+// Properties is a convenience method that wraps fetching the HostSystem MO
+// from its higher-level object.
+func Properties(host *object.HostSystem) (*mo.HostSystem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.HostSystem
+	if err := host.Properties(ctx, host.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// IsUnavailable returns true if a host is in maintenance mode or in quarantine
+// mode, in which case new virtual machines should not be placed on it.
+func IsUnavailable(props *mo.HostSystem) bool {
+	return props.Runtime.InMaintenanceMode || (props.Runtime.InQuarantineMode != nil && *props.Runtime.InQuarantineMode)
+}
+
+// FilterAvailable returns the hosts in refs that are not in maintenance mode
+// or in quarantine mode, in the same order.
+func FilterAvailable(client *govmomi.Client, refs []types.ManagedObjectReference) ([]types.ManagedObjectReference, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var hosts []mo.HostSystem
+	if err := client.PropertyCollector().Retrieve(ctx, refs, []string{"name", "runtime"}, &hosts); err != nil {
+		return nil, err
+	}
+	return filterAvailable(refs, hosts), nil
+}
+
+// filterAvailable returns the hosts in refs that are not in maintenance mode
+// or in quarantine mode according to the supplied host properties, in the
+// same order. Hosts without properties are kept.
+func filterAvailable(refs []types.ManagedObjectReference, hosts []mo.HostSystem) []types.ManagedObjectReference {
+	unavailable := make(map[string]bool)
+	for i := range hosts {
+		if IsUnavailable(&hosts[i]) {
+			log.Printf("[DEBUG] Skipping host %q: host is in maintenance or quarantine mode", hosts[i].Name)
+			unavailable[hosts[i].Reference().Value] = true
+		}
+	}
+	var available []types.ManagedObjectReference
+	for _, ref := range refs {
+		if !unavailable[ref.Value] {
+			available = append(available, ref)
+		}
+	}
+	return available
+}
+
 // hostSystemNameFromID returns the name of a host via its its managed object
 // reference ID.
 func hostSystemNameFromID(client *govmomi.Client, id string) (string, error) {
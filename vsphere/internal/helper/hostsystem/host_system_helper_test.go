@@ -0,0 +1,124 @@
+package hostsystem
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testHost(id string, maintenance bool, quarantine *bool) mo.HostSystem {
+	host := mo.HostSystem{
+		Runtime: types.HostRuntimeInfo{
+			InMaintenanceMode: maintenance,
+			InQuarantineMode:  quarantine,
+		},
+	}
+	host.Self = types.ManagedObjectReference{Type: "HostSystem", Value: id}
+	host.Name = id
+	return host
+}
+
+func TestIsUnavailable(t *testing.T) {
+	yes, no := true, false
+	cases := []struct {
+		Name     string
+		host     mo.HostSystem
+		expected bool
+	}{
+		{
+			Name:     "connected",
+			host:     testHost("host-1", false, nil),
+			expected: false,
+		},
+		{
+			Name:     "quarantine mode not set",
+			host:     testHost("host-1", false, &no),
+			expected: false,
+		},
+		{
+			Name:     "maintenance mode",
+			host:     testHost("host-1", true, nil),
+			expected: true,
+		},
+		{
+			Name:     "quarantine mode",
+			host:     testHost("host-1", false, &yes),
+			expected: true,
+		},
+		{
+			Name:     "maintenance and quarantine mode",
+			host:     testHost("host-1", true, &yes),
+			expected: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if actual := IsUnavailable(&tc.host); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFilterAvailable(t *testing.T) {
+	yes, no := true, false
+	ref := func(id string) types.ManagedObjectReference {
+		return types.ManagedObjectReference{Type: "HostSystem", Value: id}
+	}
+	cases := []struct {
+		Name     string
+		refs     []types.ManagedObjectReference
+		hosts    []mo.HostSystem
+		expected []types.ManagedObjectReference
+	}{
+		{
+			Name: "all available",
+			refs: []types.ManagedObjectReference{ref("host-1"), ref("host-2")},
+			hosts: []mo.HostSystem{
+				testHost("host-1", false, nil),
+				testHost("host-2", false, &no),
+			},
+			expected: []types.ManagedObjectReference{ref("host-1"), ref("host-2")},
+		},
+		{
+			Name: "maintenance and quarantine mode hosts skipped",
+			refs: []types.ManagedObjectReference{ref("host-1"), ref("host-2"), ref("host-3"), ref("host-4")},
+			hosts: []mo.HostSystem{
+				testHost("host-1", false, nil),
+				testHost("host-2", true, nil),
+				testHost("host-3", false, &yes),
+				testHost("host-4", false, &no),
+			},
+			expected: []types.ManagedObjectReference{ref("host-1"), ref("host-4")},
+		},
+		{
+			Name: "order of refs kept",
+			refs: []types.ManagedObjectReference{ref("host-3"), ref("host-1"), ref("host-2")},
+			hosts: []mo.HostSystem{
+				testHost("host-1", false, nil),
+				testHost("host-2", true, nil),
+				testHost("host-3", false, nil),
+			},
+			expected: []types.ManagedObjectReference{ref("host-3"), ref("host-1")},
+		},
+		{
+			Name: "all unavailable",
+			refs: []types.ManagedObjectReference{ref("host-1"), ref("host-2")},
+			hosts: []mo.HostSystem{
+				testHost("host-1", true, &yes),
+				testHost("host-2", false, &yes),
+			},
+			expected: nil,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actual := filterAvailable(tc.refs, tc.hosts)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package hostsystem
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// DCUIAccessOptionKey is the advanced option that holds the users that can
+// log in to the DCUI of a host while it is in lockdown mode.
+const DCUIAccessOptionKey = "DCUI.Access"
+
+// accessManagerRef returns the reference to the HostAccessManager of a host.
+// This manager is only available on ESXi 6.0 and higher.
+func accessManagerRef(host *object.HostSystem) (types.ManagedObjectReference, error) {
+	props, err := Properties(host)
+	if err != nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.ConfigManager.HostAccessManager == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("host %q does not support managing lockdown mode. ESXi 6.0 or higher is required", host.Name())
+	}
+	return *props.ConfigManager.HostAccessManager, nil
+}
+
+// LockdownMode returns the current lockdown mode of a host.
+func LockdownMode(client *govmomi.Client, host *object.HostSystem) (types.HostLockdownMode, error) {
+	ref, err := accessManagerRef(host)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.HostAccessManager
+	if err := client.PropertyCollector().RetrieveOne(ctx, ref, []string{"lockdownMode"}, &props); err != nil {
+		return "", err
+	}
+	return props.LockdownMode, nil
+}
+
+// ChangeLockdownMode sets the lockdown mode of a host.
+func ChangeLockdownMode(client *govmomi.Client, host *object.HostSystem, mode types.HostLockdownMode) error {
+	ref, err := accessManagerRef(host)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Setting lockdown mode on host %q to %q", host.Name(), mode)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.ChangeLockdownMode{
+		This: ref,
+		Mode: mode,
+	}
+	_, err = methods.ChangeLockdownMode(ctx, client.Client, &req)
+	return err
+}
+
+// LockdownExceptions returns the users that keep their permissions on a host
+// while it is in lockdown mode.
+func LockdownExceptions(client *govmomi.Client, host *object.HostSystem) ([]string, error) {
+	ref, err := accessManagerRef(host)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.QueryLockdownExceptions{
+		This: ref,
+	}
+	res, err := methods.QueryLockdownExceptions(ctx, client.Client, &req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Returnval, nil
+}
+
+// UpdateLockdownExceptions replaces the lockdown mode exception users of a
+// host.
+func UpdateLockdownExceptions(client *govmomi.Client, host *object.HostSystem, users []string) error {
+	ref, err := accessManagerRef(host)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Setting lockdown exception users on host %q to %s", host.Name(), strings.Join(users, ", "))
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.UpdateLockdownExceptions{
+		This:  ref,
+		Users: users,
+	}
+	_, err = methods.UpdateLockdownExceptions(ctx, client.Client, &req)
+	return err
+}
+
+// DCUIAccess returns the users that can log in to the DCUI of a host while it
+// is in lockdown mode.
+func DCUIAccess(host *object.HostSystem) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	om, err := host.ConfigManager().OptionManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := om.Query(ctx, DCUIAccessOptionKey)
+	if err != nil {
+		return nil, err
+	}
+	var users []string
+	for _, opt := range opts {
+		v, ok := opt.GetOptionValue().Value.(string)
+		if !ok {
+			continue
+		}
+		for _, u := range strings.Split(v, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				users = append(users, u)
+			}
+		}
+	}
+	return users, nil
+}
+
+// UpdateDCUIAccess replaces the users that can log in to the DCUI of a host
+// while it is in lockdown mode.
+func UpdateDCUIAccess(host *object.HostSystem, users []string) error {
+	log.Printf("[DEBUG] Setting DCUI access users on host %q to %s", host.Name(), strings.Join(users, ", "))
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	om, err := host.ConfigManager().OptionManager(ctx)
+	if err != nil {
+		return err
+	}
+	opts := []types.BaseOptionValue{
+		&types.OptionValue{
+			Key:   DCUIAccessOptionKey,
+			Value: strings.Join(users, ","),
+		},
+	}
+	return om.Update(ctx, opts)
+}
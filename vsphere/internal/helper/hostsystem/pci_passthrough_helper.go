@@ -0,0 +1,62 @@
+package hostsystem
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// pciPassthruSystemRef returns the reference to the HostPciPassthruSystem of a
+// host.
+func pciPassthruSystemRef(host *object.HostSystem) (types.ManagedObjectReference, error) {
+	props, err := Properties(host)
+	if err != nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("error fetching host properties: %s", err)
+	}
+	if props.ConfigManager.PciPassthruSystem == nil {
+		return types.ManagedObjectReference{}, fmt.Errorf("host %q does not support PCI passthrough", host.Name())
+	}
+	return *props.ConfigManager.PciPassthruSystem, nil
+}
+
+// PciPassthruInfo returns the PCI passthrough information of the devices of a
+// host. Devices that support SR-IOV are returned as *types.HostSriovInfo.
+func PciPassthruInfo(client *govmomi.Client, host *object.HostSystem) ([]types.BaseHostPciPassthruInfo, error) {
+	ref, err := pciPassthruSystemRef(host)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.HostPciPassthruSystem
+	if err := client.PropertyCollector().RetrieveOne(ctx, ref, []string{"pciPassthruInfo"}, &props); err != nil {
+		return nil, err
+	}
+	return props.PciPassthruInfo, nil
+}
+
+// UpdatePassthruConfig changes the PCI passthrough and SR-IOV settings of the
+// devices of a host. Most changes only take effect after the host is
+// rebooted.
+func UpdatePassthruConfig(client *govmomi.Client, host *object.HostSystem, config []types.BaseHostPciPassthruConfig) error {
+	ref, err := pciPassthruSystemRef(host)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Updating PCI passthrough settings of %d devices on host %q", len(config), host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.UpdatePassthruConfig{
+		This:   ref,
+		Config: config,
+	}
+	_, err = methods.UpdatePassthruConfig(ctx, client.Client, &req)
+	return err
+}
@@ -0,0 +1,153 @@
+package hostsystem
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// rebootPollInterval is the interval at which a host is checked while waiting
+// for it to reboot.
+const rebootPollInterval = time.Second * 10
+
+// EnterMaintenanceMode puts a host in maintenance mode, and waits for it to
+// get there. If evacuate is true, the powered off and suspended virtual
+// machines are moved off the host as well. In a DRS cluster, the powered on
+// virtual machines are always migrated off the host.
+func EnterMaintenanceMode(host *object.HostSystem, timeout time.Duration, evacuate bool) error {
+	log.Printf("[DEBUG] Putting host %q in maintenance mode (evacuate = %t)", host.Name(), evacuate)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	task, err := host.EnterMaintenanceMode(ctx, int32(timeout.Seconds()), evacuate, nil)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// ExitMaintenanceMode takes a host out of maintenance mode, and waits for it to
+// leave it.
+func ExitMaintenanceMode(host *object.HostSystem, timeout time.Duration) error {
+	log.Printf("[DEBUG] Taking host %q out of maintenance mode", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	task, err := host.ExitMaintenanceMode(ctx, int32(timeout.Seconds()))
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// PowerDownToStandby puts a host in standby mode, from where it can be powered
+// on again through vCenter.
+func PowerDownToStandby(client *govmomi.Client, host *object.HostSystem, timeout time.Duration) error {
+	log.Printf("[DEBUG] Putting host %q in standby mode", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req := types.PowerDownHostToStandBy_Task{
+		This:                  host.Reference(),
+		TimeoutSec:            int32(timeout.Seconds()),
+		EvacuatePoweredOffVms: types.NewBool(true),
+	}
+	res, err := methods.PowerDownHostToStandBy_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	return object.NewTask(client.Client, res.Returnval).Wait(ctx)
+}
+
+// PowerUpFromStandby powers on a host that is in standby mode, using the IPMI
+// or iLO settings of the host, or Wake-on-LAN.
+func PowerUpFromStandby(client *govmomi.Client, host *object.HostSystem, timeout time.Duration) error {
+	log.Printf("[DEBUG] Powering on host %q from standby mode", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req := types.PowerUpHostFromStandBy_Task{
+		This:       host.Reference(),
+		TimeoutSec: int32(timeout.Seconds()),
+	}
+	res, err := methods.PowerUpHostFromStandBy_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	return object.NewTask(client.Client, res.Returnval).Wait(ctx)
+}
+
+// Shutdown shuts a host down. The host needs to be in maintenance mode
+// first. Once shut down, the host cannot be powered on through vCenter.
+func Shutdown(client *govmomi.Client, host *object.HostSystem) error {
+	log.Printf("[DEBUG] Shutting down host %q", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.ShutdownHost_Task{
+		This:  host.Reference(),
+		Force: false,
+	}
+	res, err := methods.ShutdownHost_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	return object.NewTask(client.Client, res.Returnval).Wait(ctx)
+}
+
+// Reboot reboots a host, and waits for it to be connected again with a new
+// boot time. The host needs to be in maintenance mode first.
+func Reboot(client *govmomi.Client, host *object.HostSystem, timeout time.Duration) error {
+	props, err := Properties(host)
+	if err != nil {
+		return err
+	}
+	bootTime := props.Runtime.BootTime
+	log.Printf("[DEBUG] Rebooting host %q", host.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.RebootHost_Task{
+		This:  host.Reference(),
+		Force: false,
+	}
+	res, err := methods.RebootHost_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+	if err := object.NewTask(client.Client, res.Returnval).Wait(ctx); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		time.Sleep(rebootPollInterval)
+		props, err := Properties(host)
+		switch {
+		case err != nil:
+			log.Printf("[DEBUG] Error fetching properties of host %q while it reboots: %s", host.Name(), err)
+		case props.Runtime.ConnectionState == types.HostSystemConnectionStateConnected &&
+			props.Runtime.BootTime != nil && (bootTime == nil || props.Runtime.BootTime.After(*bootTime)):
+			log.Printf("[DEBUG] Host %q rebooted at %s", host.Name(), props.Runtime.BootTime)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for host %q to reboot", host.Name())
+		}
+	}
+}
+
+// UpdateIPMI sets the IPMI or iLO settings that vCenter uses to power on a
+// host from standby mode.
+func UpdateIPMI(client *govmomi.Client, host *object.HostSystem, info types.HostIpmiInfo) error {
+	log.Printf("[DEBUG] Updating IPMI settings of host %q (BMC address %q)", host.Name(), info.BmcIpAddress)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.UpdateIpmi{
+		This:     host.Reference(),
+		IpmiInfo: info,
+	}
+	_, err := methods.UpdateIpmi(ctx, client.Client, &req)
+	return err
+}
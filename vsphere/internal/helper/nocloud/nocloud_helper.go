@@ -0,0 +1,280 @@
+// Package nocloud builds the seed ISO images read by the NoCloud data source
+// of cloud-init.
+//
+// An image is an ISO 9660 file system with the volume label cidata and the
+// seed files, such as user-data and meta-data, in its root directory. As these
+// names are not valid ISO 9660 file names, the image also carries a Joliet
+// directory tree that holds the real names. Both trees point at the same file
+// data.
+package nocloud
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// VolumeLabel is the volume label that cloud-init looks for when searching
+// for a NoCloud seed.
+const VolumeLabel = "cidata"
+
+// The following are the well-known names of the seed files.
+const (
+	UserDataFile      = "user-data"
+	MetaDataFile      = "meta-data"
+	NetworkConfigFile = "network-config"
+	VendorDataFile    = "vendor-data"
+)
+
+// sectorSize is the logical sector and block size of the image.
+const sectorSize = 2048
+
+// The layout of the image. The first 16 sectors are the unused system area.
+// The volume descriptors, path tables and root directories of both trees take
+// one sector each, and the file data starts right after them.
+const (
+	sectorPrimaryDescriptor    = 16
+	sectorJolietDescriptor     = 17
+	sectorTerminatorDescriptor = 18
+	sectorPrimaryPathTableL    = 19
+	sectorPrimaryPathTableM    = 20
+	sectorJolietPathTableL     = 21
+	sectorJolietPathTableM     = 22
+	sectorPrimaryRoot          = 23
+	sectorJolietRoot           = 24
+	sectorFirstFile            = 25
+)
+
+// pathTableSize is the size of a path table that only holds the root
+// directory.
+const pathTableSize = 10
+
+// seedFile is a file of the image, along with its location and the names it
+// has in both directory trees.
+type seedFile struct {
+	name       string
+	primary    []byte
+	joliet     []byte
+	data       []byte
+	sector     uint32
+	sectorSpan uint32
+}
+
+// SeedISO returns an ISO image with the volume label cidata and the supplied
+// files in its root directory, keyed by name. The image only depends on the
+// files, so that the same files always produce the same image.
+func SeedISO(files map[string][]byte) ([]byte, error) {
+	if len(files) < 1 {
+		return nil, fmt.Errorf("at least one file is required")
+	}
+	var seeds []*seedFile
+	used := make(map[string]bool)
+	for name, data := range files {
+		if name == "" || len(name) > 64 || strings.ContainsAny(name, "/\\;") {
+			return nil, fmt.Errorf("invalid file name %q", name)
+		}
+		primary := primaryFileName(name, used)
+		used[primary] = true
+		seeds = append(seeds, &seedFile{
+			name:    name,
+			primary: []byte(primary + ".;1"),
+			joliet:  ucs2(name),
+			data:    data,
+		})
+	}
+	sort.Slice(seeds, func(i, j int) bool { return seeds[i].name < seeds[j].name })
+
+	sector := uint32(sectorFirstFile)
+	for _, f := range seeds {
+		f.sector = sector
+		f.sectorSpan = uint32((len(f.data) + sectorSize - 1) / sectorSize)
+		sector += f.sectorSpan
+	}
+	total := sector
+
+	primaryRoot := directory(seeds, sectorPrimaryRoot, func(f *seedFile) []byte { return f.primary })
+	jolietRoot := directory(seeds, sectorJolietRoot, func(f *seedFile) []byte { return f.joliet })
+	if len(primaryRoot) > sectorSize || len(jolietRoot) > sectorSize {
+		return nil, fmt.Errorf("too many files for the root directory")
+	}
+
+	img := make([]byte, int(total)*sectorSize)
+	copy(sectorAt(img, sectorPrimaryDescriptor), volumeDescriptor(false, total))
+	copy(sectorAt(img, sectorJolietDescriptor), volumeDescriptor(true, total))
+	copy(sectorAt(img, sectorTerminatorDescriptor), terminatorDescriptor())
+	copy(sectorAt(img, sectorPrimaryPathTableL), pathTable(binary.LittleEndian, sectorPrimaryRoot))
+	copy(sectorAt(img, sectorPrimaryPathTableM), pathTable(binary.BigEndian, sectorPrimaryRoot))
+	copy(sectorAt(img, sectorJolietPathTableL), pathTable(binary.LittleEndian, sectorJolietRoot))
+	copy(sectorAt(img, sectorJolietPathTableM), pathTable(binary.BigEndian, sectorJolietRoot))
+	copy(sectorAt(img, sectorPrimaryRoot), primaryRoot)
+	copy(sectorAt(img, sectorJolietRoot), jolietRoot)
+	for _, f := range seeds {
+		copy(img[int(f.sector)*sectorSize:], f.data)
+	}
+	return img, nil
+}
+
+// sectorAt returns the part of the image that starts at the supplied sector.
+func sectorAt(img []byte, sector int) []byte {
+	return img[sector*sectorSize : (sector+1)*sectorSize]
+}
+
+// primaryFileName returns an ISO 9660 level 1 name for a file, without the
+// extension separator and version: up to 8 upper case letters, digits and
+// underscores. A counter is added to names that have already been used.
+func primaryFileName(name string, used map[string]bool) string {
+	mapped := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, name)
+	if len(mapped) > 8 {
+		mapped = mapped[:8]
+	}
+	candidate := mapped
+	for i := 1; used[candidate]; i++ {
+		suffix := fmt.Sprintf("%d", i)
+		base := mapped
+		if len(base)+len(suffix) > 8 {
+			base = base[:8-len(suffix)]
+		}
+		candidate = base + suffix
+	}
+	return candidate
+}
+
+// ucs2 encodes a string as big-endian UCS-2, as used by Joliet.
+func ucs2(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(c>>8), byte(c))
+	}
+	return b
+}
+
+// putBoth16 writes v in both byte orders, little-endian first.
+func putBoth16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b, v)
+	binary.BigEndian.PutUint16(b[2:], v)
+}
+
+// putBoth32 writes v in both byte orders, little-endian first.
+func putBoth32(b []byte, v uint32) {
+	binary.LittleEndian.PutUint32(b, v)
+	binary.BigEndian.PutUint32(b[4:], v)
+}
+
+// directoryRecord returns a directory record for an extent.
+func directoryRecord(id []byte, sector, size uint32, dir bool) []byte {
+	n := 33 + len(id)
+	if n%2 != 0 {
+		n++
+	}
+	r := make([]byte, n)
+	r[0] = byte(n)
+	putBoth32(r[2:], sector)
+	putBoth32(r[10:], size)
+	if dir {
+		r[25] = 2
+	}
+	putBoth16(r[28:], 1)
+	r[32] = byte(len(id))
+	copy(r[33:], id)
+	return r
+}
+
+// directory returns the root directory of a tree, with the name of each file
+// in the tree supplied by name. The root directory is its own parent.
+func directory(seeds []*seedFile, sector uint32, name func(*seedFile) []byte) []byte {
+	var b bytes.Buffer
+	b.Write(directoryRecord([]byte{0}, sector, sectorSize, true))
+	b.Write(directoryRecord([]byte{1}, sector, sectorSize, true))
+	sorted := make([]*seedFile, len(seeds))
+	copy(sorted, seeds)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(name(sorted[i]), name(sorted[j])) < 0 })
+	for _, f := range sorted {
+		b.Write(directoryRecord(name(f), f.sector, uint32(len(f.data)), false))
+	}
+	return b.Bytes()
+}
+
+// pathTable returns a path table in the supplied byte order that only holds
+// the root directory.
+func pathTable(order binary.ByteOrder, root uint32) []byte {
+	t := make([]byte, pathTableSize)
+	t[0] = 1
+	order.PutUint32(t[2:], root)
+	order.PutUint16(t[6:], 1)
+	return t
+}
+
+// volumeDescriptor returns the primary volume descriptor, or the Joliet
+// supplementary volume descriptor if joliet is true.
+func volumeDescriptor(joliet bool, total uint32) []byte {
+	d := make([]byte, sectorSize)
+	text := func(offset, length int, s string) {
+		if joliet {
+			for i := 0; i+1 < length; i += 2 {
+				d[offset+i], d[offset+i+1] = 0, ' '
+			}
+			copy(d[offset:offset+length], ucs2(s))
+			return
+		}
+		copy(d[offset:offset+length], strings.Repeat(" ", length))
+		copy(d[offset:offset+length], s)
+	}
+	unsetDate := func(offset int) {
+		copy(d[offset:], strings.Repeat("0", 16))
+	}
+
+	d[0] = 1
+	root, tableL, tableM := uint32(sectorPrimaryRoot), uint32(sectorPrimaryPathTableL), uint32(sectorPrimaryPathTableM)
+	if joliet {
+		d[0] = 2
+		root, tableL, tableM = sectorJolietRoot, sectorJolietPathTableL, sectorJolietPathTableM
+		// The escape sequence of UCS-2 level 3.
+		copy(d[88:], "%/E")
+	}
+	copy(d[1:], "CD001")
+	d[6] = 1
+	text(8, 32, "")
+	text(40, 32, VolumeLabel)
+	putBoth32(d[80:], total)
+	putBoth16(d[120:], 1)
+	putBoth16(d[124:], 1)
+	putBoth16(d[128:], sectorSize)
+	putBoth32(d[132:], pathTableSize)
+	binary.LittleEndian.PutUint32(d[140:], tableL)
+	binary.BigEndian.PutUint32(d[148:], tableM)
+	copy(d[156:], directoryRecord([]byte{0}, root, sectorSize, true))
+	text(190, 128, "")
+	text(318, 128, "")
+	text(446, 128, "")
+	text(574, 128, "")
+	text(702, 37, "")
+	text(739, 37, "")
+	text(776, 37, "")
+	unsetDate(813)
+	unsetDate(830)
+	unsetDate(847)
+	unsetDate(864)
+	d[881] = 1
+	return d
+}
+
+// terminatorDescriptor returns the descriptor that ends the volume descriptor
+// set.
+func terminatorDescriptor() []byte {
+	d := make([]byte, 7)
+	d[0] = 255
+	copy(d[1:], "CD001")
+	d[6] = 1
+	return d
+}
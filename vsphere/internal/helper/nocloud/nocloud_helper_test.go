@@ -0,0 +1,100 @@
+package nocloud
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// readRoot reads the files in the root directory of the tree described by
+// the volume descriptor at the supplied sector, keyed by their raw name.
+func readRoot(t *testing.T, img []byte, descriptor int) map[string][]byte {
+	d := sectorAt(img, descriptor)
+	if string(d[1:6]) != "CD001" {
+		t.Fatalf("sector %d: expected volume descriptor, got %q", descriptor, d[1:6])
+	}
+	root := binary.LittleEndian.Uint32(d[158:])
+	dir := sectorAt(img, int(root))
+	files := make(map[string][]byte)
+	for off := 0; off < len(dir) && dir[off] != 0; off += int(dir[off]) {
+		r := dir[off:]
+		id := r[33 : 33+int(r[32])]
+		if r[25]&2 != 0 {
+			continue
+		}
+		start := int(binary.LittleEndian.Uint32(r[2:])) * sectorSize
+		size := int(binary.LittleEndian.Uint32(r[10:]))
+		files[string(id)] = img[start : start+size]
+	}
+	return files
+}
+
+func TestSeedISO(t *testing.T) {
+	files := map[string][]byte{
+		UserDataFile:      []byte("#cloud-config\nhostname: test\n"),
+		MetaDataFile:      []byte("instance-id: test\n"),
+		NetworkConfigFile: bytes.Repeat([]byte("x"), sectorSize+1),
+	}
+	img, err := SeedISO(files)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if len(img)%sectorSize != 0 {
+		t.Fatalf("expected image size to be a multiple of %d, got %d", sectorSize, len(img))
+	}
+	if label := string(sectorAt(img, sectorPrimaryDescriptor)[40:46]); label != VolumeLabel {
+		t.Fatalf("expected volume label %q, got %q", VolumeLabel, label)
+	}
+	if total := binary.LittleEndian.Uint32(sectorAt(img, sectorPrimaryDescriptor)[80:]); int(total)*sectorSize != len(img) {
+		t.Fatalf("expected volume size %d, got %d", len(img)/sectorSize, total)
+	}
+
+	expectedPrimary := map[string][]byte{
+		"USER_DAT.;1": files[UserDataFile],
+		"META_DAT.;1": files[MetaDataFile],
+		"NETWORK_.;1": files[NetworkConfigFile],
+	}
+	if actual := readRoot(t, img, sectorPrimaryDescriptor); !reflect.DeepEqual(expectedPrimary, actual) {
+		t.Fatalf("expected primary tree %q, got %q", expectedPrimary, actual)
+	}
+	expectedJoliet := make(map[string][]byte)
+	for name, data := range files {
+		expectedJoliet[string(ucs2(name))] = data
+	}
+	if actual := readRoot(t, img, sectorJolietDescriptor); !reflect.DeepEqual(expectedJoliet, actual) {
+		t.Fatalf("expected Joliet tree %q, got %q", expectedJoliet, actual)
+	}
+
+	again, err := SeedISO(files)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if !bytes.Equal(img, again) {
+		t.Fatal("expected the same files to produce the same image")
+	}
+}
+
+func TestSeedISONoFiles(t *testing.T) {
+	if _, err := SeedISO(nil); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
+
+func TestPrimaryFileName(t *testing.T) {
+	used := map[string]bool{}
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{"user-data", "USER_DAT"},
+		{"user-data-2", "USER_DA1"},
+		{"a.b", "A_B"},
+	} {
+		actual := primaryFileName(tc.name, used)
+		if actual != tc.expected {
+			t.Fatalf("%q: expected %q, got %q", tc.name, tc.expected, actual)
+		}
+		used[actual] = true
+	}
+}
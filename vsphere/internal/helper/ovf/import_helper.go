@@ -0,0 +1,319 @@
+package ovf
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/nfc"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Source is an OVF descriptor or OVA archive to import, in a local file or at
+// a remote URL. The files that an OVF descriptor references are read relative
+// to it, and the files of an OVA archive are read from the archive.
+type Source struct {
+	// The path of the local file, or the URL of the remote file.
+	Location string
+
+	// Whether or not Location is a URL.
+	Remote bool
+
+	// The HTTP client to fetch remote files with. A client with a timeout of
+	// RemoteTimeout is used if this is nil.
+	Client *http.Client
+}
+
+// isOVA returns true if the source is an OVA archive.
+func (s *Source) isOVA() bool {
+	name := s.Location
+	if s.Remote {
+		if u, err := url.Parse(s.Location); err == nil {
+			name = u.Path
+		}
+	}
+	return strings.EqualFold(path.Ext(name), ".ova")
+}
+
+// open opens the file at the supplied location, which is a path or a URL
+// depending on the source, returning its size, or -1 if it is not known.
+func (s *Source) open(location string) (io.ReadCloser, int64, error) {
+	if !s.Remote {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, 0, err
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, fi.Size(), nil
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: RemoteTimeout}
+	}
+	log.Printf("[DEBUG] Fetching %q", location)
+	res, err := client.Get(location)
+	if err != nil {
+		return nil, 0, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, 0, fmt.Errorf("unexpected status fetching %q: %s", location, res.Status)
+	}
+	return res.Body, res.ContentLength, nil
+}
+
+// Descriptor returns the OVF descriptor of the source.
+func (s *Source) Descriptor() ([]byte, error) {
+	if !s.isOVA() {
+		r, _, err := s.open(s.Location)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+	r, _, err := s.openInOVA(func(name string) bool {
+		return strings.EqualFold(path.Ext(name), ".ovf")
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// Open opens a file that the OVF descriptor of the source references,
+// returning its size, or -1 if it is not known.
+func (s *Source) Open(name string) (io.ReadCloser, int64, error) {
+	if s.isOVA() {
+		return s.openInOVA(func(n string) bool { return n == name })
+	}
+	if !s.Remote {
+		return s.open(filepath.Join(filepath.Dir(s.Location), filepath.FromSlash(name)))
+	}
+	base, err := url.Parse(s.Location)
+	if err != nil {
+		return nil, 0, err
+	}
+	ref, err := url.Parse(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.open(base.ResolveReference(ref).String())
+}
+
+// openInOVA opens the first file in the OVA archive of the source whose name
+// matches. The archive is read from the start every time, so that remote
+// archives are streamed and not stored.
+func (s *Source) openInOVA(match func(name string) bool) (io.ReadCloser, int64, error) {
+	r, _, err := s.open(s.Location)
+	if err != nil {
+		return nil, 0, err
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			r.Close()
+			return nil, 0, errors.New("file not found in OVA archive")
+		}
+		if err != nil {
+			r.Close()
+			return nil, 0, fmt.Errorf("error reading OVA archive: %s", err)
+		}
+		if match(hdr.Name) {
+			return ovaFile{Reader: tr, Closer: r}, hdr.Size, nil
+		}
+	}
+}
+
+// ovaFile is a file in an OVA archive. Closing it closes the archive.
+type ovaFile struct {
+	io.Reader
+	io.Closer
+}
+
+// ImportSpec is the placement and settings of a virtual machine that is
+// imported from an OVF descriptor or OVA archive.
+type ImportSpec struct {
+	Name      string
+	Pool      *object.ResourcePool
+	Folder    *object.Folder
+	Datastore *object.Datastore
+
+	// The host to place the virtual machine on. Optional when the resource pool
+	// is in a DRS cluster.
+	Host *object.HostSystem
+
+	// The networks to connect the networks in the network section of the
+	// descriptor to, keyed by the name of the network in the descriptor.
+	NetworkMapping map[string]types.ManagedObjectReference
+
+	// The values of the properties in the product sections of the descriptor,
+	// keyed by property ID.
+	Properties map[string]string
+
+	DiskProvisioning   string
+	DeploymentOption   string
+	IPAllocationPolicy string
+	IPProtocol         string
+}
+
+// Import imports a virtual machine from an OVF descriptor or OVA archive,
+// uploading its disks through an NFC lease, and returns a reference to the
+// virtual machine. The virtual machine is left powered off.
+func Import(client *govmomi.Client, src *Source, spec ImportSpec, timeout time.Duration) (types.ManagedObjectReference, error) {
+	var ref types.ManagedObjectReference
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	descriptor, err := src.Descriptor()
+	if err != nil {
+		return ref, fmt.Errorf("error reading OVF descriptor: %s", err)
+	}
+	isr, err := createImportSpec(ctx, client, string(descriptor), spec)
+	if err != nil {
+		return ref, err
+	}
+
+	log.Printf("[DEBUG] Importing virtual machine %q from %q", spec.Name, src.Location)
+	lease, err := spec.Pool.ImportVApp(ctx, isr.ImportSpec, spec.Folder, spec.Host)
+	if err != nil {
+		return ref, fmt.Errorf("error starting import: %s", err)
+	}
+	info, err := lease.Wait(ctx, isr.FileItem)
+	if err != nil {
+		return ref, fmt.Errorf("error waiting for import lease: %s", err)
+	}
+	if err := uploadLeaseItems(ctx, lease, info, src); err != nil {
+		if aerr := lease.Abort(context.Background(), nil); aerr != nil {
+			log.Printf("[DEBUG] Error aborting import lease: %s", aerr)
+		}
+		return ref, err
+	}
+	if err := lease.Complete(ctx); err != nil {
+		return ref, fmt.Errorf("error completing import: %s", err)
+	}
+	if info.Entity.Type != "VirtualMachine" {
+		return ref, fmt.Errorf("OVF imported as a %s, only OVFs with a single virtual machine are supported", info.Entity.Type)
+	}
+	return info.Entity, nil
+}
+
+// createImportSpec has the OVF manager turn an OVF descriptor into an import
+// spec. Warnings are logged, and errors are returned.
+func createImportSpec(ctx context.Context, client *govmomi.Client, descriptor string, spec ImportSpec) (*types.OvfCreateImportSpecResult, error) {
+	params := types.OvfCreateImportSpecParams{
+		OvfManagerCommonParams: types.OvfManagerCommonParams{
+			DeploymentOption: spec.DeploymentOption,
+		},
+		EntityName:         spec.Name,
+		DiskProvisioning:   spec.DiskProvisioning,
+		IpAllocationPolicy: spec.IPAllocationPolicy,
+		IpProtocol:         spec.IPProtocol,
+		NetworkMapping:     expandNetworkMapping(spec.NetworkMapping),
+		PropertyMapping:    expandPropertyMapping(spec.Properties),
+	}
+	if spec.Host != nil {
+		ref := spec.Host.Reference()
+		params.HostSystem = &ref
+	}
+	req := types.CreateImportSpec{
+		This:          *client.ServiceContent.OvfManager,
+		OvfDescriptor: descriptor,
+		ResourcePool:  spec.Pool.Reference(),
+		Datastore:     spec.Datastore.Reference(),
+		Cisp:          params,
+	}
+	res, err := methods.CreateImportSpec(ctx, client, &req)
+	if err != nil {
+		return nil, fmt.Errorf("error creating import spec: %s", err)
+	}
+	isr := res.Returnval
+	for _, w := range isr.Warning {
+		log.Printf("[DEBUG] OVF import warning: %s", w.LocalizedMessage)
+	}
+	if len(isr.Error) > 0 {
+		var msgs []string
+		for _, e := range isr.Error {
+			msgs = append(msgs, e.LocalizedMessage)
+		}
+		return nil, fmt.Errorf("error creating import spec: %s", strings.Join(msgs, "; "))
+	}
+	return &isr, nil
+}
+
+// uploadLeaseItems uploads the files of an import lease from the source. The
+// lease is kept alive with progress updates during the upload.
+func uploadLeaseItems(ctx context.Context, lease *nfc.Lease, info *nfc.LeaseInfo, src *Source) error {
+	updater := lease.StartUpdater(ctx, info)
+	defer updater.Done()
+	for _, item := range info.Items {
+		if err := uploadLeaseItem(ctx, lease, item, src); err != nil {
+			return fmt.Errorf("error uploading %q: %s", item.Path, err)
+		}
+	}
+	return nil
+}
+
+// uploadLeaseItem uploads a single file of an import lease from the source.
+func uploadLeaseItem(ctx context.Context, lease *nfc.Lease, item nfc.FileItem, src *Source) error {
+	f, size, err := src.Open(item.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.Printf("[DEBUG] Uploading %q (%d bytes)", item.Path, size)
+	opts := soap.Upload{ContentLength: size}
+	return lease.Upload(ctx, item, f, opts)
+}
+
+// expandNetworkMapping returns the network mapping of an import spec, sorted
+// by the name of the network in the descriptor.
+func expandNetworkMapping(m map[string]types.ManagedObjectReference) []types.OvfNetworkMapping {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var result []types.OvfNetworkMapping
+	for _, name := range names {
+		result = append(result, types.OvfNetworkMapping{Name: name, Network: m[name]})
+	}
+	return result
+}
+
+// expandPropertyMapping returns the property mapping of an import spec,
+// sorted by property ID.
+func expandPropertyMapping(m map[string]string) []types.KeyValue {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var result []types.KeyValue
+	for _, k := range keys {
+		result = append(result, types.KeyValue{Key: k, Value: m[k]})
+	}
+	return result
+}
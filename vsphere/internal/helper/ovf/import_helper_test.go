@@ -0,0 +1,135 @@
+package ovf
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const testDiskContents = "not really a disk"
+
+func TestSourceLocalOVF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-vsphere-ovf")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "photon.ovf"), []byte(testDescriptor), 0644); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "disk-0.vmdk"), []byte(testDiskContents), 0644); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	testCheckSource(t, &Source{Location: filepath.Join(dir, "photon.ovf")})
+}
+
+func TestSourceLocalOVA(t *testing.T) {
+	f, err := ioutil.TempFile("", "tf-vsphere-ova")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer os.Remove(f.Name())
+	tw := tar.NewWriter(f)
+	for _, file := range []struct{ name, contents string }{
+		{"photon.ovf", testDescriptor},
+		{"disk-0.vmdk", testDiskContents},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.contents))}); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		if _, err := tw.Write([]byte(file.contents)); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	f.Close()
+	ova := f.Name() + ".ova"
+	if err := os.Rename(f.Name(), ova); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer os.Remove(ova)
+
+	testCheckSource(t, &Source{Location: ova})
+}
+
+func TestSourceRemoteOVF(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/photon/photon.ovf", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testDescriptor))
+	})
+	mux.HandleFunc("/photon/disk-0.vmdk", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testDiskContents))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	testCheckSource(t, &Source{Location: ts.URL + "/photon/photon.ovf", Remote: true})
+}
+
+func testCheckSource(t *testing.T, src *Source) {
+	descriptor, err := src.Descriptor()
+	if err != nil {
+		t.Fatalf("error reading descriptor: %s", err)
+	}
+	if string(descriptor) != testDescriptor {
+		t.Fatalf("unexpected descriptor: %s", descriptor)
+	}
+
+	r, size, err := src.Open("disk-0.vmdk")
+	if err != nil {
+		t.Fatalf("error opening disk: %s", err)
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("error reading disk: %s", err)
+	}
+	if string(b) != testDiskContents {
+		t.Fatalf("unexpected disk contents: %q", b)
+	}
+	if size != int64(len(testDiskContents)) {
+		t.Fatalf("expected size %d, got %d", len(testDiskContents), size)
+	}
+
+	if _, _, err := src.Open("disk-1.vmdk"); err == nil {
+		t.Fatalf("expected error opening missing file")
+	}
+}
+
+func TestExpandNetworkMapping(t *testing.T) {
+	m := map[string]types.ManagedObjectReference{
+		"VM Network": {Type: "Network", Value: "network-1"},
+		"Management": {Type: "DistributedVirtualPortgroup", Value: "dvportgroup-2"},
+	}
+	expected := []types.OvfNetworkMapping{
+		{Name: "Management", Network: types.ManagedObjectReference{Type: "DistributedVirtualPortgroup", Value: "dvportgroup-2"}},
+		{Name: "VM Network", Network: types.ManagedObjectReference{Type: "Network", Value: "network-1"}},
+	}
+	if actual := expandNetworkMapping(m); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestExpandPropertyMapping(t *testing.T) {
+	m := map[string]string{
+		"guestinfo.hostname": "photon",
+		"ip0":                "10.0.0.10",
+	}
+	expected := []types.KeyValue{
+		{Key: "guestinfo.hostname", Value: "photon"},
+		{Key: "ip0", Value: "10.0.0.10"},
+	}
+	if actual := expandPropertyMapping(m); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
@@ -0,0 +1,340 @@
+package ovf
+
+import (
+	"archive/tar"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resource types for items in the virtual hardware section, from the
+// CIM_ResourceAllocationSettingData schema.
+const (
+	resourceTypeProcessor = 3
+	resourceTypeMemory    = 4
+	resourceTypeEthernet  = 10
+	resourceTypeDisk      = 17
+)
+
+// RemoteTimeout is the timeout for fetching a remote OVF or OVA file.
+const RemoteTimeout = time.Minute * 10
+
+// Envelope is the root element of an OVF descriptor. Only the parts of the
+// descriptor that are needed to describe the virtual machine are parsed.
+//
+// This fills the role of the ovf package in newer versions of govmomi, which
+// is not available in the version that this provider uses.
+type Envelope struct {
+	Disks         []Disk        `xml:"DiskSection>Disk"`
+	Networks      []Network     `xml:"NetworkSection>Network"`
+	VirtualSystem VirtualSystem `xml:"VirtualSystem"`
+}
+
+// Disk is a virtual disk in the disk section of an OVF descriptor.
+type Disk struct {
+	DiskID                  string `xml:"diskId,attr"`
+	Capacity                string `xml:"capacity,attr"`
+	CapacityAllocationUnits string `xml:"capacityAllocationUnits,attr"`
+	PopulatedSize           int64  `xml:"populatedSize,attr"`
+}
+
+// Network is a network in the network section of an OVF descriptor.
+type Network struct {
+	Name        string `xml:"name,attr"`
+	Description string `xml:"Description"`
+}
+
+// VirtualSystem is the virtual system in an OVF descriptor.
+type VirtualSystem struct {
+	ID              string           `xml:"id,attr"`
+	Name            string           `xml:"Name"`
+	Annotation      string           `xml:"AnnotationSection>Annotation"`
+	OperatingSystem OperatingSystem  `xml:"OperatingSystemSection"`
+	Hardware        VirtualHardware  `xml:"VirtualHardwareSection"`
+	Products        []ProductSection `xml:"ProductSection"`
+}
+
+// OperatingSystem is the operating system section of a virtual system.
+type OperatingSystem struct {
+	ID          string `xml:"id,attr"`
+	OSType      string `xml:"osType,attr"`
+	Description string `xml:"Description"`
+}
+
+// VirtualHardware is the virtual hardware section of a virtual system.
+type VirtualHardware struct {
+	SystemType string        `xml:"System>VirtualSystemType"`
+	Items      []Item        `xml:"Item"`
+	Config     []ConfigValue `xml:"Config"`
+}
+
+// Item is an item of virtual hardware, such as a CPU, memory, or a disk.
+type Item struct {
+	InstanceID      string `xml:"InstanceID"`
+	ElementName     string `xml:"ElementName"`
+	ResourceType    int    `xml:"ResourceType"`
+	ResourceSubType string `xml:"ResourceSubType"`
+	VirtualQuantity int64  `xml:"VirtualQuantity"`
+	AllocationUnits string `xml:"AllocationUnits"`
+	HostResource    string `xml:"HostResource"`
+	Connection      string `xml:"Connection"`
+}
+
+// ConfigValue is an extra VMware configuration value in the virtual hardware
+// section, like the firmware type.
+type ConfigValue struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// ProductSection is a product section of a virtual system, which holds the
+// properties of the OVF.
+type ProductSection struct {
+	Class      string     `xml:"class,attr"`
+	Instance   string     `xml:"instance,attr"`
+	Product    string     `xml:"Product"`
+	Properties []Property `xml:"Property"`
+}
+
+// Property is a property in a product section.
+type Property struct {
+	Key              string `xml:"key,attr"`
+	Type             string `xml:"type,attr"`
+	Qualifiers       string `xml:"qualifiers,attr"`
+	UserConfigurable *bool  `xml:"userConfigurable,attr"`
+	Default          string `xml:"value,attr"`
+	Password         *bool  `xml:"password,attr"`
+	Label            string `xml:"Label"`
+	Description      string `xml:"Description"`
+}
+
+// NumCPUs returns the number of virtual CPUs of the virtual system.
+func (e *Envelope) NumCPUs() int {
+	for _, item := range e.VirtualSystem.Hardware.Items {
+		if item.ResourceType == resourceTypeProcessor {
+			return int(item.VirtualQuantity)
+		}
+	}
+	return 0
+}
+
+// MemoryMB returns the memory size of the virtual system in MB.
+func (e *Envelope) MemoryMB() (int, error) {
+	for _, item := range e.VirtualSystem.Hardware.Items {
+		if item.ResourceType != resourceTypeMemory {
+			continue
+		}
+		m, err := AllocationUnitsMultiplier(item.AllocationUnits)
+		if err != nil {
+			return 0, fmt.Errorf("memory: %s", err)
+		}
+		return int(item.VirtualQuantity * m / (1 << 20)), nil
+	}
+	return 0, nil
+}
+
+// Firmware returns the firmware of the virtual system, or an empty string if
+// it is not set in the descriptor.
+func (e *Envelope) Firmware() string {
+	for _, c := range e.VirtualSystem.Hardware.Config {
+		if c.Key == "firmware" {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// HardwareVersion returns the virtual hardware version of the virtual system,
+// or 0 if it is not a VMware hardware version.
+func (e *Envelope) HardwareVersion() int {
+	// The system type can list more than one type, like "vmx-10 vmx-11".
+	var version int
+	for _, t := range strings.Fields(e.VirtualSystem.Hardware.SystemType) {
+		if !strings.HasPrefix(t, "vmx-") {
+			continue
+		}
+		if v, err := strconv.Atoi(strings.TrimPrefix(t, "vmx-")); err == nil && v > version {
+			version = v
+		}
+	}
+	return version
+}
+
+// VirtualDisk is a disk of the virtual system, with its capacity.
+type VirtualDisk struct {
+	// The name of the disk in the virtual hardware section.
+	Name string
+
+	// The ID of the disk in the disk section.
+	DiskID string
+
+	// The capacity of the disk in bytes.
+	Capacity int64
+}
+
+// VirtualDisks returns the disks of the virtual system, in the order that
+// they appear in the virtual hardware section.
+func (e *Envelope) VirtualDisks() ([]VirtualDisk, error) {
+	disks := make(map[string]Disk)
+	for _, d := range e.Disks {
+		disks[d.DiskID] = d
+	}
+	var result []VirtualDisk
+	for _, item := range e.VirtualSystem.Hardware.Items {
+		if item.ResourceType != resourceTypeDisk {
+			continue
+		}
+		// Host resources are in the form of ovf:/disk/<diskId>, or
+		// /disk/<diskId> in older descriptors.
+		id := path.Base(item.HostResource)
+		d, ok := disks[id]
+		if !ok {
+			return nil, fmt.Errorf("disk %q references unknown disk %q", item.ElementName, item.HostResource)
+		}
+		c, err := d.DiskCapacity()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, VirtualDisk{
+			Name:     item.ElementName,
+			DiskID:   id,
+			Capacity: c,
+		})
+	}
+	return result, nil
+}
+
+// EthernetAdapters returns the network adapters of the virtual system, in the
+// order that they appear in the virtual hardware section.
+func (e *Envelope) EthernetAdapters() []Item {
+	var result []Item
+	for _, item := range e.VirtualSystem.Hardware.Items {
+		if item.ResourceType == resourceTypeEthernet {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// DiskCapacity returns the capacity of a disk in bytes.
+func (d *Disk) DiskCapacity() (int64, error) {
+	c, err := strconv.ParseInt(d.Capacity, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("disk %q: invalid capacity %q", d.DiskID, d.Capacity)
+	}
+	m, err := AllocationUnitsMultiplier(d.CapacityAllocationUnits)
+	if err != nil {
+		return 0, fmt.Errorf("disk %q: %s", d.DiskID, err)
+	}
+	return c * m, nil
+}
+
+// allocationUnitsMatcher matches programmatic units in the form of
+// "byte * 2^20".
+var allocationUnitsMatcher = regexp.MustCompile(`^byte\s*(?:\*\s*2\^(\d+))?$`)
+
+// AllocationUnitsMultiplier returns the number of bytes in one of the
+// supplied allocation units, like "byte * 2^20" or "MegaBytes". An empty unit
+// is in bytes.
+func AllocationUnitsMultiplier(units string) (int64, error) {
+	u := strings.TrimSpace(units)
+	switch strings.ToLower(u) {
+	case "", "byte", "bytes":
+		return 1, nil
+	case "kilobytes", "kb":
+		return 1 << 10, nil
+	case "megabytes", "mb":
+		return 1 << 20, nil
+	case "gigabytes", "gb":
+		return 1 << 30, nil
+	case "terabytes", "tb":
+		return 1 << 40, nil
+	}
+	m := allocationUnitsMatcher.FindStringSubmatch(u)
+	if m == nil {
+		return 0, fmt.Errorf("unsupported allocation units %q", units)
+	}
+	if m[1] == "" {
+		return 1, nil
+	}
+	exp, err := strconv.Atoi(m[1])
+	if err != nil || exp > 62 {
+		return 0, fmt.Errorf("unsupported allocation units %q", units)
+	}
+	return 1 << uint(exp), nil
+}
+
+// Parse parses an OVF descriptor.
+func Parse(r io.Reader) (*Envelope, error) {
+	var e Envelope
+	if err := xml.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("error parsing OVF descriptor: %s", err)
+	}
+	return &e, nil
+}
+
+// ParseOVA finds the OVF descriptor in an OVA archive and parses it.
+func ParseOVA(r io.Reader) (*Envelope, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("no OVF descriptor found in OVA archive")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading OVA archive: %s", err)
+		}
+		if strings.EqualFold(path.Ext(hdr.Name), ".ovf") {
+			log.Printf("[DEBUG] Found OVF descriptor %q in OVA archive", hdr.Name)
+			return Parse(tr)
+		}
+	}
+}
+
+// parseByExtension parses the supplied reader as an OVA archive if name ends
+// in .ova, and as an OVF descriptor otherwise.
+func parseByExtension(name string, r io.Reader) (*Envelope, error) {
+	if strings.EqualFold(path.Ext(name), ".ova") {
+		return ParseOVA(r)
+	}
+	return Parse(r)
+}
+
+// FromFile parses a local OVF descriptor or OVA archive.
+func FromFile(name string) (*Envelope, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseByExtension(name, f)
+}
+
+// FromURL parses a remote OVF descriptor or OVA archive. For an OVA archive,
+// only the start of the archive up to the descriptor is downloaded, as the
+// descriptor is the first file in an OVA.
+func FromURL(client *http.Client, url string) (*Envelope, error) {
+	if client == nil {
+		client = &http.Client{Timeout: RemoteTimeout}
+	}
+	log.Printf("[DEBUG] Fetching OVF from %q", url)
+	res, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching %q: %s", url, res.Status)
+	}
+	name := res.Request.URL.Path
+	return parseByExtension(name, res.Body)
+}
@@ -0,0 +1,197 @@
+package ovf
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDescriptor = `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1" xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData" xmlns:vssd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_VirtualSystemSettingData" xmlns:vmw="http://www.vmware.com/schema/ovf">
+  <References>
+    <File ovf:href="disk-0.vmdk" ovf:id="file1"/>
+  </References>
+  <DiskSection>
+    <Info>Virtual disk information</Info>
+    <Disk ovf:capacity="16" ovf:capacityAllocationUnits="byte * 2^30" ovf:diskId="vmdisk1" ovf:fileRef="file1"/>
+    <Disk ovf:capacity="536870912" ovf:diskId="vmdisk2"/>
+  </DiskSection>
+  <NetworkSection>
+    <Info>The list of logical networks</Info>
+    <Network ovf:name="VM Network">
+      <Description>The VM Network network</Description>
+    </Network>
+  </NetworkSection>
+  <VirtualSystem ovf:id="photon">
+    <Info>A virtual machine</Info>
+    <Name>photon-ova</Name>
+    <AnnotationSection>
+      <Info>A human-readable annotation</Info>
+      <Annotation>Photon OS</Annotation>
+    </AnnotationSection>
+    <OperatingSystemSection ovf:id="36" vmw:osType="other3xLinux64Guest">
+      <Info>The kind of installed guest operating system</Info>
+    </OperatingSystemSection>
+    <VirtualHardwareSection>
+      <Info>Virtual hardware requirements</Info>
+      <System>
+        <vssd:VirtualSystemType>vmx-11 vmx-13</vssd:VirtualSystemType>
+      </System>
+      <Item>
+        <rasd:AllocationUnits>hertz * 10^6</rasd:AllocationUnits>
+        <rasd:ElementName>2 virtual CPU(s)</rasd:ElementName>
+        <rasd:InstanceID>1</rasd:InstanceID>
+        <rasd:ResourceType>3</rasd:ResourceType>
+        <rasd:VirtualQuantity>2</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:AllocationUnits>byte * 2^20</rasd:AllocationUnits>
+        <rasd:ElementName>2048MB of memory</rasd:ElementName>
+        <rasd:InstanceID>2</rasd:InstanceID>
+        <rasd:ResourceType>4</rasd:ResourceType>
+        <rasd:VirtualQuantity>2048</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:ElementName>Hard disk 1</rasd:ElementName>
+        <rasd:HostResource>ovf:/disk/vmdisk1</rasd:HostResource>
+        <rasd:InstanceID>3</rasd:InstanceID>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:ElementName>Hard disk 2</rasd:ElementName>
+        <rasd:HostResource>ovf:/disk/vmdisk2</rasd:HostResource>
+        <rasd:InstanceID>4</rasd:InstanceID>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:Connection>VM Network</rasd:Connection>
+        <rasd:ElementName>Network adapter 1</rasd:ElementName>
+        <rasd:InstanceID>5</rasd:InstanceID>
+        <rasd:ResourceSubType>VmxNet3</rasd:ResourceSubType>
+        <rasd:ResourceType>10</rasd:ResourceType>
+      </Item>
+      <vmw:Config ovf:required="false" vmw:key="firmware" vmw:value="efi"/>
+    </VirtualHardwareSection>
+    <ProductSection ovf:class="vami" ovf:instance="photon">
+      <Info>Information about the installed software</Info>
+      <Product>Photon OS</Product>
+      <Category>Networking</Category>
+      <Property ovf:key="ip0" ovf:type="string" ovf:userConfigurable="true">
+        <Label>IP Address</Label>
+        <Description>The IP address of the appliance.</Description>
+      </Property>
+    </ProductSection>
+    <ProductSection>
+      <Info>Appliance properties</Info>
+      <Property ovf:key="root_password" ovf:password="true" ovf:type="string" ovf:userConfigurable="true" ovf:value="">
+        <Label>Root password</Label>
+      </Property>
+    </ProductSection>
+  </VirtualSystem>
+</Envelope>
+`
+
+func TestParse(t *testing.T) {
+	e, err := Parse(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	testCheckEnvelope(t, e)
+}
+
+func TestParseOVA(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "photon.ovf", Mode: 0644, Size: int64(len(testDescriptor))}); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if _, err := tw.Write([]byte(testDescriptor)); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	e, err := ParseOVA(&buf)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	testCheckEnvelope(t, e)
+}
+
+func testCheckEnvelope(t *testing.T, e *Envelope) {
+	if e.VirtualSystem.Name != "photon-ova" {
+		t.Fatalf("expected name to be photon-ova, got %q", e.VirtualSystem.Name)
+	}
+	if e.VirtualSystem.OperatingSystem.OSType != "other3xLinux64Guest" {
+		t.Fatalf("expected guest ID to be other3xLinux64Guest, got %q", e.VirtualSystem.OperatingSystem.OSType)
+	}
+	if n := e.NumCPUs(); n != 2 {
+		t.Fatalf("expected 2 CPUs, got %d", n)
+	}
+	if m, err := e.MemoryMB(); err != nil || m != 2048 {
+		t.Fatalf("expected 2048MB of memory, got %d (err: %v)", m, err)
+	}
+	if f := e.Firmware(); f != "efi" {
+		t.Fatalf("expected firmware to be efi, got %q", f)
+	}
+	if v := e.HardwareVersion(); v != 13 {
+		t.Fatalf("expected hardware version 13, got %d", v)
+	}
+
+	disks, err := e.VirtualDisks()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	expectedDisks := []VirtualDisk{
+		{Name: "Hard disk 1", DiskID: "vmdisk1", Capacity: 16 << 30},
+		{Name: "Hard disk 2", DiskID: "vmdisk2", Capacity: 512 << 20},
+	}
+	if !reflect.DeepEqual(disks, expectedDisks) {
+		t.Fatalf("expected disks %#v, got %#v", expectedDisks, disks)
+	}
+
+	nics := e.EthernetAdapters()
+	if len(nics) != 1 || nics[0].Connection != "VM Network" || nics[0].ResourceSubType != "VmxNet3" {
+		t.Fatalf("unexpected network adapters: %#v", nics)
+	}
+
+	if len(e.VirtualSystem.Products) != 2 {
+		t.Fatalf("expected 2 product sections, got %d", len(e.VirtualSystem.Products))
+	}
+	p := e.VirtualSystem.Products[0]
+	if p.Class != "vami" || p.Instance != "photon" || len(p.Properties) != 1 || p.Properties[0].Key != "ip0" {
+		t.Fatalf("unexpected product section: %#v", p)
+	}
+	pw := e.VirtualSystem.Products[1].Properties[0]
+	if pw.Password == nil || !*pw.Password {
+		t.Fatalf("expected root_password to be a password property")
+	}
+}
+
+func TestAllocationUnitsMultiplier(t *testing.T) {
+	cases := map[string]int64{
+		"":             1,
+		"byte":         1,
+		"byte * 2^20":  1 << 20,
+		"byte*2^30":    1 << 30,
+		"MegaBytes":    1 << 20,
+		"GigaBytes":    1 << 30,
+		"KiloBytes":    1 << 10,
+		"byte * 2^40 ": 1 << 40,
+	}
+	for units, expected := range cases {
+		actual, err := AllocationUnitsMultiplier(units)
+		if err != nil {
+			t.Fatalf("%q: bad: %s", units, err)
+		}
+		if actual != expected {
+			t.Fatalf("%q: expected %d, got %d", units, expected, actual)
+		}
+	}
+	if _, err := AllocationUnitsMultiplier("hertz * 10^6"); err == nil {
+		t.Fatalf("expected error for hertz * 10^6")
+	}
+}
@@ -126,3 +126,27 @@ func OSFamily(client *govmomi.Client, pool *object.ResourcePool, guest string) (
 	}
 	return computeresource.OSFamily(client, pprops.Owner, guest)
 }
+
+// MaxHardwareVersion uses the resource pool's environment browser to get the
+// highest virtual machine hardware version supported by the pool's owning
+// compute resource.
+func MaxHardwareVersion(client *govmomi.Client, pool *object.ResourcePool) (int, error) {
+	log.Printf("[DEBUG] Looking for maximum hardware version for resource pool %q", pool.Reference().Value)
+	pprops, err := Properties(pool)
+	if err != nil {
+		return 0, err
+	}
+	return computeresource.MaxHardwareVersionFromReference(client, pprops.Owner)
+}
+
+// GuestOSDescriptors uses the resource pool's environment browser to get the
+// guest operating systems supported for the optionally supplied hardware
+// version key and host.
+func GuestOSDescriptors(client *govmomi.Client, pool *object.ResourcePool, key string, host *object.HostSystem) ([]types.GuestOsDescriptor, error) {
+	log.Printf("[DEBUG] Looking for guest OS descriptors for resource pool %q", pool.Reference().Value)
+	pprops, err := Properties(pool)
+	if err != nil {
+		return nil, err
+	}
+	return computeresource.GuestOSDescriptorsFromReference(client, pprops.Owner, key, host)
+}
@@ -0,0 +1,150 @@
+package scheduledtask
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// reference returns a ManagedObjectReference for a ScheduledTask from its
+// managed object ID.
+func reference(id string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{
+		Type:  "ScheduledTask",
+		Value: id,
+	}
+}
+
+// Properties fetches the ScheduledTask MO for the scheduled task with the
+// supplied managed object ID.
+func Properties(client *govmomi.Client, id string) (*mo.ScheduledTask, error) {
+	log.Printf("[DEBUG] Fetching properties for scheduled task %q", id)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props mo.ScheduledTask
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, reference(id), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// Create creates a scheduled task that triggers an action against the
+// supplied managed object, and returns the managed object ID of the new
+// scheduled task.
+func Create(client *govmomi.Client, obj types.ManagedObjectReference, spec types.ScheduledTaskSpec) (string, error) {
+	log.Printf("[DEBUG] Creating scheduled task %q on %s %q", spec.Name, obj.Type, obj.Value)
+	if client.ServiceContent.ScheduledTaskManager == nil {
+		return "", fmt.Errorf("scheduled tasks are not supported on this connection")
+	}
+	req := types.CreateObjectScheduledTask{
+		This: *client.ServiceContent.ScheduledTaskManager,
+		Obj:  obj,
+		Spec: &spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	res, err := methods.CreateObjectScheduledTask(ctx, client.Client, &req)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[DEBUG] Scheduled task %q created with ID %q", spec.Name, res.Returnval.Value)
+	return res.Returnval.Value, nil
+}
+
+// Reconfigure replaces the specification of the scheduled task with the
+// supplied managed object ID.
+func Reconfigure(client *govmomi.Client, id string, spec types.ScheduledTaskSpec) error {
+	log.Printf("[DEBUG] Reconfiguring scheduled task %q", id)
+	req := types.ReconfigureScheduledTask{
+		This: reference(id),
+		Spec: &spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.ReconfigureScheduledTask(ctx, client.Client, &req)
+	return err
+}
+
+// Remove removes the scheduled task with the supplied managed object ID.
+func Remove(client *govmomi.Client, id string) error {
+	log.Printf("[DEBUG] Removing scheduled task %q", id)
+	req := types.RemoveScheduledTask{
+		This: reference(id),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	_, err := methods.RemoveScheduledTask(ctx, client.Client, &req)
+	return err
+}
+
+// DailyScheduler returns a scheduler that runs every day at the supplied
+// time of day, in UTC, if no days are supplied, or a weekly scheduler that
+// runs at that time on the supplied days.
+func DailyScheduler(hour, minute int32, days []time.Weekday) types.BaseTaskScheduler {
+	daily := types.DailyTaskScheduler{
+		HourlyTaskScheduler: types.HourlyTaskScheduler{
+			RecurrentTaskScheduler: types.RecurrentTaskScheduler{
+				Interval: 1,
+			},
+			Minute: minute,
+		},
+		Hour: hour,
+	}
+	if len(days) < 1 {
+		return &daily
+	}
+
+	weekly := &types.WeeklyTaskScheduler{
+		DailyTaskScheduler: daily,
+	}
+	for _, day := range days {
+		switch day {
+		case time.Sunday:
+			weekly.Sunday = true
+		case time.Monday:
+			weekly.Monday = true
+		case time.Tuesday:
+			weekly.Tuesday = true
+		case time.Wednesday:
+			weekly.Wednesday = true
+		case time.Thursday:
+			weekly.Thursday = true
+		case time.Friday:
+			weekly.Friday = true
+		case time.Saturday:
+			weekly.Saturday = true
+		}
+	}
+	return weekly
+}
+
+// DailySchedulerTime returns the time of day and the days of the week that a
+// scheduler created by DailyScheduler runs on. No days are returned for
+// schedulers that run every day.
+func DailySchedulerTime(obj types.BaseTaskScheduler) (int32, int32, []time.Weekday, error) {
+	var days []time.Weekday
+	var daily *types.DailyTaskScheduler
+	switch t := obj.(type) {
+	case *types.WeeklyTaskScheduler:
+		daily = &t.DailyTaskScheduler
+		for i, on := range []bool{t.Sunday, t.Monday, t.Tuesday, t.Wednesday, t.Thursday, t.Friday, t.Saturday} {
+			if on {
+				days = append(days, time.Weekday(i))
+			}
+		}
+	case *types.DailyTaskScheduler:
+		daily = t
+	default:
+		return 0, 0, nil, fmt.Errorf("unexpected scheduler type %T", obj)
+	}
+	return daily.Hour, daily.Minute, days, nil
+}
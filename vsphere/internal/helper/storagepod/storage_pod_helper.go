@@ -10,6 +10,7 @@ import (
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -90,6 +91,54 @@ func ApplyDRSConfiguration(client *govmomi.Client, pod *object.StoragePod, spec
 	return task.Wait(ctx)
 }
 
+// ApplyDRSRule applies a single storage DRS rule operation against the
+// specified StoragePod. This is a convenience wrapper for
+// ApplyDRSConfiguration that only touches the rules of the pod.
+func ApplyDRSRule(client *govmomi.Client, pod *object.StoragePod, spec types.ClusterRuleSpec) error {
+	log.Printf("[DEBUG] Applying storage DRS rule operation %q against datastore cluster %q", spec.Operation, pod.InventoryPath)
+	return ApplyDRSConfiguration(client, pod, types.StorageDrsConfigSpec{
+		PodConfigSpec: &types.StorageDrsPodConfigSpec{
+			Rule: []types.ClusterRuleSpec{spec},
+		},
+	})
+}
+
+// AntiAffinityRule locates a VM anti-affinity rule in a StoragePod by its
+// key. If the rule does not exist, nil is returned without an error.
+func AntiAffinityRule(pod *object.StoragePod, key int32) (*types.ClusterAntiAffinityRuleSpec, error) {
+	return antiAffinityRule(pod, func(rule *types.ClusterAntiAffinityRuleSpec) bool {
+		return rule.Key == key
+	})
+}
+
+// AntiAffinityRuleByName locates a VM anti-affinity rule in a StoragePod by
+// its name. If the rule does not exist, nil is returned without an error.
+func AntiAffinityRuleByName(pod *object.StoragePod, name string) (*types.ClusterAntiAffinityRuleSpec, error) {
+	return antiAffinityRule(pod, func(rule *types.ClusterAntiAffinityRuleSpec) bool {
+		return rule.Name == name
+	})
+}
+
+func antiAffinityRule(pod *object.StoragePod, match func(*types.ClusterAntiAffinityRuleSpec) bool) (*types.ClusterAntiAffinityRuleSpec, error) {
+	props, err := Properties(pod)
+	if err != nil {
+		return nil, err
+	}
+	if props.PodStorageDrsEntry == nil {
+		return nil, nil
+	}
+	for _, info := range props.PodStorageDrsEntry.StorageDrsConfig.PodConfig.Rule {
+		rule, ok := info.(*types.ClusterAntiAffinityRuleSpec)
+		if !ok {
+			continue
+		}
+		if match(rule) {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
 // Rename renames a StoragePod.
 func Rename(pod *object.StoragePod, name string) error {
 	log.Printf("[DEBUG] Renaming storage pod %q to %s", pod.InventoryPath, name)
@@ -123,6 +172,66 @@ func HasChildren(pod *object.StoragePod) (bool, error) {
 	return folder.HasChildren(pod.Folder)
 }
 
+// Datastores returns references to the datastores that are members of a
+// StoragePod.
+func Datastores(pod *object.StoragePod) ([]types.ManagedObjectReference, error) {
+	props, err := Properties(pod)
+	if err != nil {
+		return nil, err
+	}
+	var refs []types.ManagedObjectReference
+	for _, ref := range props.ChildEntity {
+		if ref.Type == "Datastore" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// DatacenterDatastores returns the names and parents of all of the datastores
+// in the datacenter of a StoragePod. This includes datastores in datastore
+// folders and in other StoragePods.
+func DatacenterDatastores(client *govmomi.Client, pod *object.StoragePod) ([]mo.Datastore, error) {
+	dcPath, err := folder.RootPathParticleDatastore.SplitDatacenter(pod.InventoryPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := folder.FromAbsolutePath(client, dcPath+folder.RootPathParticleDatastore.Delimiter())
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Listing datastores in %q", root.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	v, err := view.NewManager(client.Client).CreateContainerView(ctx, root.Reference(), []string{"Datastore"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := v.Destroy(ctx); err != nil {
+			log.Printf("[DEBUG] DatacenterDatastores: Unexpected error destroying container view: %s", err)
+		}
+	}()
+	var result []mo.Datastore
+	if err := v.Retrieve(ctx, []string{"Datastore"}, []string{"name", "parent"}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MoveDatastoresInto moves several datastores into a StoragePod in a single
+// task.
+func MoveDatastoresInto(pod *object.StoragePod, refs []types.ManagedObjectReference) error {
+	log.Printf("[DEBUG] Moving %d datastores into datastore cluster %q", len(refs), pod.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := pod.MoveInto(ctx, refs)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
 // Delete destroys a StoragePod.
 func Delete(pod *object.StoragePod) error {
 	log.Printf("[DEBUG] Deleting datastore cluster %q", pod.InventoryPath)
@@ -45,6 +45,17 @@ func SliceStringsToInterfaces(s []string) []interface{} {
 	return d
 }
 
+// SliceInterfacesToInts converts an interface slice to an int slice. The
+// function does not attempt to do any sanity checking and will panic if one of
+// the items in the slice is not an int.
+func SliceInterfacesToInts(s []interface{}) []int {
+	var d []int
+	for _, v := range s {
+		d = append(d, v.(int))
+	}
+	return d
+}
+
 // MergeSchema merges the map[string]*schema.Schema from src into dst. Safety
 // against conflicts is enforced by panicing.
 func MergeSchema(dst, src map[string]*schema.Schema) {
@@ -0,0 +1,144 @@
+package viapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/provider"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/license"
+)
+
+// licenseEditionEval is the edition key of the evaluation license, which
+// includes every feature.
+const licenseEditionEval = "eval"
+
+// Capability describes a vSphere feature that is only available from a
+// certain version of vSphere, on vCenter, or with a certain license feature.
+//
+// All of the features that the provider gates on are listed below, so that
+// resources check them the same way and fail with the same errors.
+type Capability struct {
+	// The name of the feature, as shown in errors. This is usually the name of
+	// the attribute that uses the feature.
+	Name string
+
+	// The minimum major and minor version of vSphere.
+	Major int
+	Minor int
+
+	// Whether or not the feature is only available on vCenter.
+	VirtualCenterOnly bool
+
+	// The key of the license feature that the feature needs, such as dvs, or
+	// empty if the feature does not depend on the license.
+	LicenseFeature string
+}
+
+// The capability matrix of the provider.
+var (
+	// CapabilityNetworkResourceAllocation covers the bandwidth settings of
+	// virtual machine network interfaces.
+	CapabilityNetworkResourceAllocation = Capability{Name: "network interface bandwidth control", Major: 6}
+
+	// CapabilityMultiWriterDiskSharing covers multi-writer virtual disks.
+	CapabilityMultiWriterDiskSharing = Capability{Name: "multi-writer disk_sharing", Major: 6}
+
+	// CapabilityEFISecureBoot covers EFI secure boot of virtual machines.
+	CapabilityEFISecureBoot = Capability{Name: "efi_secure_boot_enabled", Major: 6, Minor: 5}
+
+	// CapabilityEncryptedVMotion covers the encryption settings of vMotion.
+	CapabilityEncryptedVMotion = Capability{Name: "migrate_encryption", Major: 6, Minor: 5}
+
+	// CapabilityVMUUIDSearchIndex covers locating virtual machines by UUID
+	// through the SearchIndex.
+	CapabilityVMUUIDSearchIndex = Capability{Name: "virtual machine UUID search", Major: 6, Minor: 5}
+
+	// CapabilityStorageDRSAutomationOverrides covers the per-behavior automation
+	// levels of Storage DRS.
+	CapabilityStorageDRSAutomationOverrides = Capability{Name: "Storage DRS per-behavior automation", Major: 6}
+
+	// CapabilityStorageDRSReservableIO covers the reservable IOPS thresholds of
+	// Storage DRS.
+	CapabilityStorageDRSReservableIO = Capability{Name: "Storage DRS reservable IOPS control", Major: 6}
+
+	// CapabilityStorageDRSFreeSpaceThreshold covers the free space threshold
+	// mode of Storage DRS.
+	CapabilityStorageDRSFreeSpaceThreshold = Capability{Name: "Storage DRS free space threshold mode", Major: 6}
+
+	// CapabilityDistributedVirtualSwitch covers vSphere distributed virtual
+	// switches.
+	CapabilityDistributedVirtualSwitch = Capability{Name: "vSphere Distributed Switch", VirtualCenterOnly: true, LicenseFeature: "dvs"}
+)
+
+// Supported returns true if the connection is to a version and product of
+// vSphere that supports the feature. The license is not checked, as that
+// needs an API call. Use Validate for that.
+func (c Capability) Supported(client *govmomi.Client) bool {
+	if c.VirtualCenterOnly && ValidateVirtualCenter(client) != nil {
+		return false
+	}
+	return c.SupportedBy(ParseVersionFromClient(client))
+}
+
+// SupportedBy returns true if the supplied version is at or above the minimum
+// version of the feature. Whether the feature is only available on vCenter is
+// not checked.
+func (c Capability) SupportedBy(v VSphereVersion) bool {
+	return !v.Older(VSphereVersion{Product: v.Product, Major: c.Major, Minor: c.Minor})
+}
+
+// Validate returns an error describing why the feature cannot be used on the
+// connection, or nil if it can. Unlike Supported, this also checks that a
+// license with the feature is installed.
+func (c Capability) Validate(client *govmomi.Client) error {
+	if c.VirtualCenterOnly && ValidateVirtualCenter(client) != nil {
+		return fmt.Errorf("%s requires vCenter", c.Name)
+	}
+	version := ParseVersionFromClient(client)
+	if !c.SupportedBy(version) {
+		return fmt.Errorf("%s requires vSphere %d.%d or higher, but the provider is connected to %s", c.Name, c.Major, c.Minor, version)
+	}
+	if c.LicenseFeature == "" {
+		return nil
+	}
+	return c.checkLicense(hasLicenseFeature(client, c.LicenseFeature))
+}
+
+// checkLicense returns an error for the result of a license feature check,
+// or nil if the feature is licensed. Reading licenses needs the
+// Global.Licenses privilege, which many accounts that can use the feature do
+// not have. If the licenses cannot be read for that reason, the license is
+// treated as unknown and the check is skipped, leaving vSphere to reject the
+// operation itself if the feature is not licensed.
+func (c Capability) checkLicense(ok bool, err error) error {
+	if err != nil {
+		if ClassifyFault(err) == FaultClassPermission {
+			log.Printf("[DEBUG] Cannot read licenses to check for %s, skipping the license check: %s", c.Name, err)
+			return nil
+		}
+		return fmt.Errorf("error checking licenses for %s: %s", c.Name, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s requires a license with the %q feature, such as vSphere Enterprise Plus", c.Name, c.LicenseFeature)
+	}
+	return nil
+}
+
+// hasLicenseFeature returns true if any of the licenses installed on the
+// connection, including the evaluation license, has the supplied feature.
+func hasLicenseFeature(client *govmomi.Client, key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	licenses, err := license.NewManager(client.Client).List(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, l := range licenses {
+		if l.EditionKey == licenseEditionEval || license.HasFeature(l, key) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,94 @@
+package viapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestCapabilitySupportedBy(t *testing.T) {
+	cases := []struct {
+		Name       string
+		capability Capability
+		version    VSphereVersion
+		expected   bool
+	}{
+		{
+			Name:       "older major version",
+			capability: Capability{Name: "test", Major: 6},
+			version:    VSphereVersion{Product: "VMware ESXi", Major: 5, Minor: 5, Build: 1000000},
+			expected:   false,
+		},
+		{
+			Name:       "older minor version",
+			capability: Capability{Name: "test", Major: 6, Minor: 5},
+			version:    VSphereVersion{Product: "VMware vCenter Server", Major: 6, Minor: 0, Patch: 3, Build: 1000000},
+			expected:   false,
+		},
+		{
+			Name:       "same version",
+			capability: Capability{Name: "test", Major: 6, Minor: 5},
+			version:    VSphereVersion{Product: "VMware vCenter Server", Major: 6, Minor: 5, Build: 1000000},
+			expected:   true,
+		},
+		{
+			Name:       "newer version",
+			capability: Capability{Name: "test", Major: 6, Minor: 5},
+			version:    VSphereVersion{Product: "VMware ESXi", Major: 6, Minor: 7, Patch: 1, Build: 1000000},
+			expected:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if actual := tc.capability.SupportedBy(tc.version); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCapabilityCheckLicense(t *testing.T) {
+	capability := Capability{Name: "test", LicenseFeature: "dvs"}
+	cases := []struct {
+		Name     string
+		ok       bool
+		err      error
+		expected bool
+	}{
+		{
+			Name:     "licensed",
+			ok:       true,
+			expected: true,
+		},
+		{
+			Name:     "not licensed",
+			ok:       false,
+			expected: false,
+		},
+		{
+			Name:     "no permission to read licenses",
+			err:      soap.WrapVimFault(&types.NoPermission{PrivilegeId: "Global.Licenses"}),
+			expected: true,
+		},
+		{
+			Name:     "other error",
+			err:      errors.New("connection reset"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := capability.checkLicense(tc.ok, tc.err)
+			if tc.expected && err != nil {
+				t.Fatalf("expected no error, got %s", err)
+			}
+			if !tc.expected && err == nil {
+				t.Fatalf("expected error, got none")
+			}
+		})
+	}
+}
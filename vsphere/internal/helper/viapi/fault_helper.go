@@ -0,0 +1,153 @@
+package viapi
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// FaultClass is a broad classification of a vSphere API fault, used to
+// suggest a remediation to the user.
+type FaultClass int
+
+const (
+	// FaultClassUnknown is a fault that is not classified, or an error that is
+	// not a vSphere API fault.
+	FaultClassUnknown FaultClass = iota
+
+	// FaultClassPermission is a fault caused by missing privileges or an
+	// invalid session.
+	FaultClassPermission
+
+	// FaultClassNotFound is a fault caused by an object that does not exist.
+	FaultClassNotFound
+
+	// FaultClassCapacity is a fault caused by a host, cluster, resource pool,
+	// or datastore running out of resources.
+	FaultClassCapacity
+
+	// FaultClassConcurrentModification is a fault caused by another operation
+	// on the same object.
+	FaultClassConcurrentModification
+)
+
+// faultClasses maps the names of the fault types that we classify to their
+// class. Subtypes of these faults are separate types in the vim25 types
+// package, and need to be listed separately.
+var faultClasses = map[string]FaultClass{
+	"NoPermission":                     FaultClassPermission,
+	"NoPermissionOnHost":               FaultClassPermission,
+	"NotAuthenticated":                 FaultClassPermission,
+	"InvalidLogin":                     FaultClassPermission,
+	"ManagedObjectNotFound":            FaultClassNotFound,
+	"NotFound":                         FaultClassNotFound,
+	"FileNotFound":                     FaultClassNotFound,
+	"InsufficientResourcesFault":       FaultClassCapacity,
+	"InsufficientCpuResourcesFault":    FaultClassCapacity,
+	"InsufficientMemoryResourcesFault": FaultClassCapacity,
+	"InsufficientHostCapacityFault":    FaultClassCapacity,
+	"InsufficientStorageSpace":         FaultClassCapacity,
+	"InsufficientDisks":                FaultClassCapacity,
+	"NoDiskSpace":                      FaultClassCapacity,
+	"ConcurrentAccess":                 FaultClassConcurrentModification,
+	"TaskInProgress":                   FaultClassConcurrentModification,
+	"ResourceInUse":                    FaultClassConcurrentModification,
+}
+
+// String implements fmt.Stringer for FaultClass.
+func (c FaultClass) String() string {
+	switch c {
+	case FaultClassPermission:
+		return "permission"
+	case FaultClassNotFound:
+		return "not found"
+	case FaultClassCapacity:
+		return "capacity"
+	case FaultClassConcurrentModification:
+		return "concurrent modification"
+	}
+	return "unknown"
+}
+
+// Remediation returns a suggestion on how to resolve a fault of this class,
+// or an empty string if there is none.
+func (c FaultClass) Remediation() string {
+	switch c {
+	case FaultClassPermission:
+		return "Check that the vSphere user configured in the provider is logged in and has the privileges required for this operation on the object and its parents."
+	case FaultClassNotFound:
+		return "The object may have been removed or renamed outside of Terraform. Check that the IDs and paths in your configuration are correct, and refresh the state if the object was removed."
+	case FaultClassCapacity:
+		return "The target does not have enough free resources. Free up capacity, lower the resources requested, or choose a different host, resource pool, or datastore."
+	case FaultClassConcurrentModification:
+		return "Another operation was working on the object at the same time. Wait for the other operation to finish and try again."
+	}
+	return ""
+}
+
+// methodFault extracts the vSphere fault from an error returned by a SOAP
+// call or a task, if it exists.
+func methodFault(err error) (types.AnyType, bool) {
+	if f, ok := vimSoapFault(err); ok && f != nil {
+		return f, true
+	}
+	if soap.IsVimFault(err) {
+		return soap.ToVimFault(err), true
+	}
+	if f, ok := taskFault(err); ok && f != nil {
+		return f, true
+	}
+	return nil, false
+}
+
+// ClassifyFault returns the FaultClass of the fault in an error. Errors that
+// are already a Diagnostic keep their class.
+func ClassifyFault(err error) FaultClass {
+	if d, ok := err.(*Diagnostic); ok {
+		return d.Class
+	}
+	f, ok := methodFault(err)
+	if !ok {
+		return FaultClassUnknown
+	}
+	return faultClasses[reflect.Indirect(reflect.ValueOf(f)).Type().Name()]
+}
+
+// Diagnostic is an error from a vSphere API call, with the fault classified
+// so that a remediation can be suggested.
+type Diagnostic struct {
+	// A short description of the operation that failed.
+	Summary string
+
+	// The class of the fault.
+	Class FaultClass
+
+	// The original error.
+	Err error
+}
+
+// Error implements error for Diagnostic.
+func (d *Diagnostic) Error() string {
+	msg := fmt.Sprintf("%s: %s", d.Summary, d.Err)
+	if _, ok := d.Err.(*Diagnostic); ok {
+		// The remediation is already in the message of the wrapped error.
+		return msg
+	}
+	if r := d.Class.Remediation(); r != "" {
+		msg += fmt.Sprintf("\n\nThis is a %s error. %s", d.Class, r)
+	}
+	return msg
+}
+
+// NewDiagnostic wraps an error from a vSphere API call in a Diagnostic, with a
+// summary in the supplied format. The summary follows the usual style of error
+// messages, like "error cloning virtual machine".
+func NewDiagnostic(err error, format string, a ...interface{}) error {
+	return &Diagnostic{
+		Summary: fmt.Sprintf(format, a...),
+		Class:   ClassifyFault(err),
+		Err:     err,
+	}
+}
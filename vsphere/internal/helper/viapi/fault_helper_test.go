@@ -0,0 +1,74 @@
+package viapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestClassifyFault(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected FaultClass
+	}{
+		{
+			name:     "plain error",
+			err:      errors.New("foo"),
+			expected: FaultClassUnknown,
+		},
+		{
+			name:     "vim fault",
+			err:      soap.WrapVimFault(&types.NoPermission{}),
+			expected: FaultClassPermission,
+		},
+		{
+			name: "task fault",
+			err: task.Error{
+				LocalizedMethodFault: &types.LocalizedMethodFault{
+					Fault:            &types.InsufficientMemoryResourcesFault{},
+					LocalizedMessage: "Insufficient resources.",
+				},
+			},
+			expected: FaultClassCapacity,
+		},
+		{
+			name:     "unclassified fault",
+			err:      soap.WrapVimFault(&types.InvalidArgument{}),
+			expected: FaultClassUnknown,
+		},
+		{
+			name:     "diagnostic",
+			err:      NewDiagnostic(soap.WrapVimFault(&types.ConcurrentAccess{}), "error reconfiguring virtual machine"),
+			expected: FaultClassConcurrentModification,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := ClassifyFault(tc.err); actual != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDiagnosticError(t *testing.T) {
+	err := NewDiagnostic(soap.WrapVimFault(&types.NoPermission{}), "error cloning %q", "foo")
+	msg := err.Error()
+	if !strings.HasPrefix(msg, `error cloning "foo": `) {
+		t.Fatalf("unexpected summary in message: %s", msg)
+	}
+	if !strings.Contains(msg, "This is a permission error.") {
+		t.Fatalf("expected remediation in message: %s", msg)
+	}
+
+	msg = NewDiagnostic(errors.New("bar"), "error cloning").Error()
+	if msg != "error cloning: bar" {
+		t.Fatalf("expected plain message for unclassified error, got: %s", msg)
+	}
+}
@@ -19,6 +19,9 @@ import (
 // ErrVirtualCenterOnly is the error message that validateVirtualCenter returns.
 const ErrVirtualCenterOnly = "this operation is only supported on vCenter"
 
+// ErrESXiOnly is the error message that ValidateESXi returns.
+const ErrESXiOnly = "this operation is only supported on direct ESXi connections"
+
 // soapFault extracts the SOAP fault from an error fault, if it exists. Check
 // the returned boolean value to see if you have a SoapFault.
 func soapFault(err error) (*soap.Fault, bool) {
@@ -96,6 +99,28 @@ func IsResourceInUseError(err error) bool {
 	return false
 }
 
+// IsFileAlreadyExistsError checks an error to see if it's of the
+// FileAlreadyExists type.
+func IsFileAlreadyExistsError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.FileAlreadyExists); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUserNotFoundError checks an error to see if it's of the UserNotFound
+// type.
+func IsUserNotFoundError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.UserNotFound); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // isConcurrentAccessError checks an error to see if it's of the
 // ConcurrentAccess type.
 func isConcurrentAccessError(err error) bool {
@@ -136,6 +161,37 @@ func RenameObject(client *govmomi.Client, ref types.ManagedObjectReference, new
 	return t.Wait(tctx)
 }
 
+// MissingPrivileges returns the privileges out of privs that the current
+// session does not hold on the entity with the supplied reference.
+func MissingPrivileges(client *govmomi.Client, ref types.ManagedObjectReference, privs []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	us, err := client.SessionManager.UserSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if us == nil {
+		return nil, errors.New("no active session")
+	}
+	req := types.HasPrivilegeOnEntity{
+		This:      *client.ServiceContent.AuthorizationManager,
+		Entity:    ref,
+		SessionId: us.Key,
+		PrivId:    privs,
+	}
+	res, err := methods.HasPrivilegeOnEntity(ctx, client.Client, &req)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for i, ok := range res.Returnval {
+		if !ok && i < len(privs) {
+			missing = append(missing, privs[i])
+		}
+	}
+	return missing, nil
+}
+
 // ValidateVirtualCenter ensures that the client is connected to vCenter.
 func ValidateVirtualCenter(c *govmomi.Client) error {
 	if c.ServiceContent.About.ApiType != "VirtualCenter" {
@@ -144,6 +200,15 @@ func ValidateVirtualCenter(c *govmomi.Client) error {
 	return nil
 }
 
+// ValidateESXi ensures that the client is connected directly to an ESXi
+// host.
+func ValidateESXi(c *govmomi.Client) error {
+	if c.ServiceContent.About.ApiType != "HostAgent" {
+		return errors.New(ErrESXiOnly)
+	}
+	return nil
+}
+
 // VSphereVersion represents a version number of a ESXi/vCenter server
 // instance.
 type VSphereVersion struct {
@@ -301,3 +366,22 @@ func (v VSphereVersion) Older(other VSphereVersion) bool {
 func (v VSphereVersion) Equal(other VSphereVersion) bool {
 	return v.ProductEqual(other) && !v.Older(other) && !v.Newer(other)
 }
+
+// ParseHardwareVersion parses a virtual machine hardware version key, such as
+// vmx-13, into its integer version number.
+func ParseHardwareVersion(key string) (int, error) {
+	if !strings.HasPrefix(key, "vmx-") {
+		return 0, fmt.Errorf("invalid hardware version key %q", key)
+	}
+	v, err := strconv.Atoi(strings.TrimPrefix(key, "vmx-"))
+	if err != nil {
+		return 0, fmt.Errorf("could not parse hardware version key %q: %s", key, err)
+	}
+	return v, nil
+}
+
+// HardwareVersionKey returns the virtual machine hardware version key, such as
+// vmx-13, for the supplied version number.
+func HardwareVersionKey(version int) string {
+	return fmt.Sprintf("vmx-%02d", version)
+}
@@ -295,3 +295,56 @@ func TestCompareVersion(t *testing.T) {
 		t.Run(tc.Name, tc.Test)
 	}
 }
+
+type testParseHardwareVersion struct {
+	Name string
+
+	key         string
+	expected    int
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testParseHardwareVersion) Test(t *testing.T) {
+	actual, err := ParseHardwareVersion(tc.key)
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+		return
+	}
+	if tc.expected != actual {
+		t.Fatalf("expected %d, got %d", tc.expected, actual)
+	}
+	if key := HardwareVersionKey(actual); key != tc.key {
+		t.Fatalf("expected key %q, got %q", tc.key, key)
+	}
+}
+
+func TestParseHardwareVersion(t *testing.T) {
+	cases := []testParseHardwareVersion{
+		{
+			Name:     "basic",
+			key:      "vmx-13",
+			expected: 13,
+		},
+		{
+			Name:     "zero-padded",
+			key:      "vmx-04",
+			expected: 4,
+		},
+		{
+			Name:        "bad prefix",
+			key:         "vmx13",
+			expectedErr: regexp.MustCompile("invalid hardware version key"),
+		},
+		{
+			Name:        "bad version",
+			key:         "vmx-1a",
+			expectedErr: regexp.MustCompile("could not parse hardware version key"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
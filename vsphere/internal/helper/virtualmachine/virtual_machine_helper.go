@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
@@ -17,20 +18,13 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
 var errGuestShutdownTimeout = errors.New("the VM did not power off within the specified amount of time")
 
-// vmUUIDSearchIndexVersion denotes the minimum version we use the SearchIndex
-// VM UUID search for. All versions lower than this use ContainerView to find
-// the VM.
-var vmUUIDSearchIndexVersion = viapi.VSphereVersion{
-	Major: 6,
-	Minor: 5,
-}
-
 // UUIDNotFoundError is an error type that is returned when a
 // virtual machine could not be found by UUID.
 type UUIDNotFoundError struct {
@@ -59,10 +53,8 @@ func FromUUID(client *govmomi.Client, uuid string) (*object.VirtualMachine, erro
 
 	var result object.Reference
 	var err error
-	version := viapi.ParseVersionFromClient(client)
-	expected := vmUUIDSearchIndexVersion
-	expected.Product = version.Product
-	if version.Older(expected) {
+	// Versions older than 6.5 use ContainerView to find the VM.
+	if !viapi.CapabilityVMUUIDSearchIndex.Supported(client) {
 		result, err = virtualMachineFromContainerView(ctx, client, uuid)
 	} else {
 		result, err = virtualMachineFromSearchIndex(ctx, client, uuid)
@@ -186,6 +178,36 @@ func FromPath(client *govmomi.Client, path string, dc *object.Datacenter) (*obje
 	return finder.VirtualMachine(ctx, path)
 }
 
+// ListFromPath returns the virtual machines matching the supplied path, which
+// can contain wildcards, such as /dc1/vm/web/*. As a special case, when the
+// path ends in /* and the rest of it is the path of a resource pool, the
+// virtual machines in that resource pool are returned.
+func ListFromPath(client *govmomi.Client, path string) ([]*object.VirtualMachine, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	if strings.HasSuffix(path, "/*") {
+		pool, err := finder.ResourcePool(ctx, strings.TrimSuffix(path, "/*"))
+		switch err.(type) {
+		case nil:
+			var props mo.ResourcePool
+			if err := pool.Properties(ctx, pool.Reference(), []string{"vm"}, &props); err != nil {
+				return nil, err
+			}
+			var vms []*object.VirtualMachine
+			for _, ref := range props.Vm {
+				vms = append(vms, object.NewVirtualMachine(client.Client, ref))
+			}
+			return vms, nil
+		case *find.NotFoundError:
+		default:
+			return nil, err
+		}
+	}
+	return finder.VirtualMachineList(ctx, path)
+}
+
 // Properties is a convenience method that wraps fetching the
 // VirtualMachine MO from its higher-level object.
 func Properties(vm *object.VirtualMachine) (*mo.VirtualMachine, error) {
@@ -199,6 +221,100 @@ func Properties(vm *object.VirtualMachine) (*mo.VirtualMachine, error) {
 	return &props, nil
 }
 
+// ConfigFiles returns the datastore paths of the configuration files of a
+// virtual machine, from its extended file layout. The VMX path is returned
+// alone if the layout is not available.
+func ConfigFiles(props *mo.VirtualMachine) []string {
+	if props.LayoutEx == nil {
+		if props.Config == nil {
+			return nil
+		}
+		return []string{props.Config.Files.VmPathName}
+	}
+	var files []string
+	for _, f := range props.LayoutEx.File {
+		switch types.VirtualMachineFileLayoutExFileType(f.Type) {
+		case types.VirtualMachineFileLayoutExFileTypeConfig,
+			types.VirtualMachineFileLayoutExFileTypeExtendedConfig,
+			types.VirtualMachineFileLayoutExFileTypeNvram:
+			files = append(files, f.Name)
+		}
+	}
+	return files
+}
+
+// StorageProperties fetches the name, UUID, and storage usage of the virtual
+// machines in refs in a single call.
+func StorageProperties(client *govmomi.Client, refs []types.ManagedObjectReference) ([]mo.VirtualMachine, error) {
+	if len(refs) < 1 {
+		return nil, nil
+	}
+	log.Printf("[DEBUG] Fetching storage properties for %d virtual machines", len(refs))
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var props []mo.VirtualMachine
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.Retrieve(ctx, refs, []string{"name", "config.uuid", "storage"}, &props); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// guestProcessPollInterval is the interval at which a program started with
+// RunProgramInGuest is checked for completion.
+const guestProcessPollInterval = time.Second * 2
+
+// RunProgramInGuest starts a program in the guest operating system of a
+// virtual machine through guest operations, waits for it to exit, and
+// returns its exit code. VMware Tools must be running in the guest.
+func RunProgramInGuest(client *govmomi.Client, vm *object.VirtualMachine, auth types.BaseGuestAuthentication, spec types.GuestProgramSpec, timeout time.Duration) (int32, error) {
+	if client.ServiceContent.GuestOperationsManager == nil {
+		return 0, errors.New("guest operations are not supported on this connection")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var gom mo.GuestOperationsManager
+	pc := property.DefaultCollector(client.Client)
+	if err := pc.RetrieveOne(ctx, *client.ServiceContent.GuestOperationsManager, []string{"processManager"}, &gom); err != nil {
+		return 0, err
+	}
+	if gom.ProcessManager == nil {
+		return 0, errors.New("guest process manager is not available")
+	}
+
+	log.Printf("[DEBUG] Starting %q in the guest of VM %q", spec.ProgramPath, vm.InventoryPath)
+	res, err := methods.StartProgramInGuest(ctx, client.Client, &types.StartProgramInGuest{
+		This: *gom.ProcessManager,
+		Vm:   vm.Reference(),
+		Auth: auth,
+		Spec: &spec,
+	})
+	if err != nil {
+		return 0, err
+	}
+	pid := res.Returnval
+	for {
+		lres, err := methods.ListProcessesInGuest(ctx, client.Client, &types.ListProcessesInGuest{
+			This: *gom.ProcessManager,
+			Vm:   vm.Reference(),
+			Auth: auth,
+			Pids: []int64{pid},
+		})
+		if err != nil {
+			return 0, err
+		}
+		if len(lres.Returnval) > 0 && lres.Returnval[0].EndTime != nil {
+			log.Printf("[DEBUG] Process %d in the guest of VM %q exited with code %d", pid, vm.InventoryPath, lres.Returnval[0].ExitCode)
+			return lres.Returnval[0].ExitCode, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("timeout waiting for process %d in the guest to exit", pid)
+		case <-time.After(guestProcessPollInterval):
+		}
+	}
+}
+
 // WaitForGuestNet waits for a virtual machine to have routeable network
 // access. This is denoted as a gateway, and at least one IP address that can
 // reach that gateway. This function supports both IPv4 and IPv6, and returns
@@ -274,19 +390,24 @@ func WaitForGuestNet(client *govmomi.Client, vm *object.VirtualMachine, timeout
 }
 
 // Create wraps the creation of a virtual machine and the subsequent waiting of
-// the task. A higher-level virtual machine object is returned.
-func Create(c *govmomi.Client, f *object.Folder, s types.VirtualMachineConfigSpec, p *object.ResourcePool, h *object.HostSystem) (*object.VirtualMachine, error) {
-	log.Printf("[DEBUG] Creating virtual machine %q", fmt.Sprintf("%s/%s", f.InventoryPath, s.Name))
-	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+// the task. A higher-level virtual machine object is returned. The timeout is
+// in minutes.
+func Create(c *govmomi.Client, f *object.Folder, s types.VirtualMachineConfigSpec, p *object.ResourcePool, h *object.HostSystem, timeout int) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] Creating virtual machine %q (timeout %d)", fmt.Sprintf("%s/%s", f.InventoryPath, s.Name), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*time.Duration(timeout))
 	defer cancel()
 	task, err := f.CreateVM(ctx, s, p, h)
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errors.New("timeout waiting for virtual machine creation to complete")
+		}
 		return nil, err
 	}
-	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
-	defer tcancel()
-	result, err := task.WaitForResult(tctx, nil)
+	result, err := task.WaitForResult(ctx, nil)
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errors.New("timeout waiting for virtual machine creation to complete")
+		}
 		return nil, err
 	}
 	log.Printf("[DEBUG] Virtual machine %q: creation complete (MOID: %q)", fmt.Sprintf("%s/%s", f.InventoryPath, s.Name), result.Result.(types.ManagedObjectReference).Value)
@@ -447,6 +568,16 @@ func MoveToFolder(client *govmomi.Client, vm *object.VirtualMachine, relative st
 	return folder.MoveObjectTo(vm.Reference(), f)
 }
 
+// AcquireTicket acquires a ticket of the specified kind, such as webmks, for
+// remote access to the console of a virtual machine. The virtual machine must
+// be powered on.
+func AcquireTicket(vm *object.VirtualMachine, kind string) (*types.VirtualMachineTicket, error) {
+	log.Printf("[DEBUG] Acquiring %s ticket for virtual machine %q", kind, vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return vm.AcquireTicket(ctx, kind)
+}
+
 // Reconfigure wraps the Reconfigure task and the subsequent waiting for
 // the task to complete.
 func Reconfigure(vm *object.VirtualMachine, spec types.VirtualMachineConfigSpec) error {
@@ -462,6 +593,117 @@ func Reconfigure(vm *object.VirtualMachine, spec types.VirtualMachineConfigSpec)
 	return task.Wait(tctx)
 }
 
+// ReconfigureWithTimeout works like Reconfigure, but waits for the whole
+// operation for up to timeout minutes instead of the default API timeout. This
+// is used for reconfigurations that can take a long time, like the one that
+// resizes and adds the disks of a freshly cloned virtual machine.
+func ReconfigureWithTimeout(vm *object.VirtualMachine, spec types.VirtualMachineConfigSpec, timeout int) error {
+	log.Printf("[DEBUG] Reconfiguring virtual machine %q (timeout %d)", vm.InventoryPath, timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*time.Duration(timeout))
+	defer cancel()
+	task, err := vm.Reconfigure(ctx, spec)
+	if err == nil {
+		err = task.Wait(ctx)
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = errors.New("timeout waiting for reconfiguration to complete")
+	}
+	return err
+}
+
+// UpgradeHardware wraps the UpgradeVM task and the subsequent waiting for the
+// task to complete. The virtual machine must be powered off.
+func UpgradeHardware(vm *object.VirtualMachine, version string) error {
+	log.Printf("[DEBUG] Upgrading hardware of virtual machine %q to %q", vm.InventoryPath, version)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := vm.UpgradeVM(ctx, version)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// DisableMethods disables the supplied methods on a virtual machine, so that
+// they fail for any user until they are enabled again by the same source.
+// Requires vCenter.
+func DisableMethods(client *govmomi.Client, vm *object.VirtualMachine, methods []string, source, reason string) error {
+	log.Printf("[DEBUG] Disabling methods %v on virtual machine %q", methods, vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	var req []object.DisabledMethodRequest
+	for _, method := range methods {
+		req = append(req, object.DisabledMethodRequest{Method: method, Reason: reason})
+	}
+	m := object.NewAuthorizationManager(client.Client)
+	return m.DisableMethods(ctx, []types.ManagedObjectReference{vm.Reference()}, req, source)
+}
+
+// EnableMethods enables methods on a virtual machine that were disabled by the
+// supplied source with DisableMethods.
+func EnableMethods(client *govmomi.Client, vm *object.VirtualMachine, methods []string, source string) error {
+	log.Printf("[DEBUG] Enabling methods %v on virtual machine %q", methods, vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	m := object.NewAuthorizationManager(client.Client)
+	return m.EnableMethods(ctx, []types.ManagedObjectReference{vm.Reference()}, methods, source)
+}
+
+// CreateSnapshot wraps the CreateSnapshot task and the subsequent waiting for
+// the task to complete.
+func CreateSnapshot(vm *object.VirtualMachine, name, description string, memory, quiesce bool) error {
+	log.Printf("[DEBUG] Creating snapshot %q on virtual machine %q", name, vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := vm.CreateSnapshot(ctx, name, description, memory, quiesce)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// RemoveSnapshot wraps the RemoveSnapshot task for the snapshot with the
+// supplied reference, and the subsequent waiting for the task to complete.
+// Any child snapshots are kept, and disks are consolidated.
+func RemoveSnapshot(client *govmomi.Client, ref types.ManagedObjectReference) error {
+	log.Printf("[DEBUG] Removing snapshot %q", ref.Value)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	req := types.RemoveSnapshot_Task{
+		This:           ref,
+		RemoveChildren: false,
+		Consolidate:    structure.BoolPtr(true),
+	}
+	res, err := methods.RemoveSnapshot_Task(ctx, client, &req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// Snapshots returns a flat list of all of the snapshots in the snapshot tree
+// of the supplied virtual machine properties.
+func Snapshots(props *mo.VirtualMachine) []types.VirtualMachineSnapshotTree {
+	if props.Snapshot == nil {
+		return nil
+	}
+	var result []types.VirtualMachineSnapshotTree
+	trees := props.Snapshot.RootSnapshotList
+	for len(trees) > 0 {
+		tree := trees[0]
+		trees = append(trees[1:], tree.ChildSnapshotList...)
+		result = append(result, tree)
+	}
+	return result
+}
+
 // Relocate wraps the Relocate task and the subsequent waiting for the task to
 // complete.
 func Relocate(vm *object.VirtualMachine, spec types.VirtualMachineRelocateSpec, timeout int) error {
@@ -495,3 +737,33 @@ func Destroy(vm *object.VirtualMachine) error {
 	defer tcancel()
 	return task.Wait(tctx)
 }
+
+// Register wraps the registration of an existing virtual machine
+// configuration file as a virtual machine in folder f, and the subsequent
+// waiting of the task. The host is optional when the pool is in a cluster. A
+// higher-level virtual machine object is returned.
+func Register(c *govmomi.Client, f *object.Folder, path, name string, asTemplate bool, p *object.ResourcePool, h *object.HostSystem) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] Registering virtual machine %q in folder %q", path, f.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	task, err := f.RegisterVM(ctx, path, name, asTemplate, p, h)
+	if err != nil {
+		return nil, err
+	}
+	result, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	ref := result.Result.(types.ManagedObjectReference)
+	log.Printf("[DEBUG] Virtual machine %q: registration complete (MOID: %q)", path, ref.Value)
+	return FromMOID(c, ref.Value)
+}
+
+// Unregister removes a virtual machine from the inventory, without deleting
+// its files.
+func Unregister(vm *object.VirtualMachine) error {
+	log.Printf("[DEBUG] Unregistering virtual machine %q", vm.InventoryPath)
+	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	defer cancel()
+	return vm.Unregister(ctx)
+}
@@ -0,0 +1,138 @@
+package virtualdevice
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// deviceLayout is the layout of the controllers, disks, and network
+// interfaces of a virtual machine, as exported in device_layout_json.
+type deviceLayout struct {
+	Controllers       []deviceLayoutController       `json:"controllers"`
+	Disks             []deviceLayoutDisk             `json:"disks"`
+	NetworkInterfaces []deviceLayoutNetworkInterface `json:"network_interfaces"`
+}
+
+// deviceLayoutController is a controller in a deviceLayout.
+type deviceLayoutController struct {
+	Key        int32   `json:"key"`
+	Type       string  `json:"type"`
+	Label      string  `json:"label"`
+	BusNumber  int32   `json:"bus_number"`
+	SharedBus  string  `json:"shared_bus,omitempty"`
+	DeviceKeys []int32 `json:"device_keys"`
+}
+
+// deviceLayoutDisk is a virtual disk in a deviceLayout.
+type deviceLayoutDisk struct {
+	Key           int32  `json:"key"`
+	Label         string `json:"label"`
+	ControllerKey int32  `json:"controller_key"`
+	UnitNumber    *int32 `json:"unit_number"`
+	CapacityKB    int64  `json:"capacity_kb"`
+	FileName      string `json:"file_name,omitempty"`
+	UUID          string `json:"uuid,omitempty"`
+}
+
+// deviceLayoutNetworkInterface is a network interface in a deviceLayout.
+type deviceLayoutNetworkInterface struct {
+	Key             int32  `json:"key"`
+	Label           string `json:"label"`
+	Type            string `json:"type"`
+	ControllerKey   int32  `json:"controller_key"`
+	UnitNumber      *int32 `json:"unit_number"`
+	MacAddress      string `json:"mac_address,omitempty"`
+	NetworkID       string `json:"network_id,omitempty"`
+	PortgroupKey    string `json:"portgroup_key,omitempty"`
+	PortKey         string `json:"port_key,omitempty"`
+	OpaqueNetworkID string `json:"opaque_network_id,omitempty"`
+}
+
+// DeviceLayoutJSON returns the layout of the controllers, disks, and network
+// interfaces in a device list as JSON, with the devices of each kind ordered
+// by device key, so that the output only changes when the hardware does.
+func DeviceLayoutJSON(l object.VirtualDeviceList) (string, error) {
+	layout := deviceLayout{
+		Controllers:       []deviceLayoutController{},
+		Disks:             []deviceLayoutDisk{},
+		NetworkInterfaces: []deviceLayoutNetworkInterface{},
+	}
+	for _, device := range l {
+		vd := device.GetVirtualDevice()
+		switch d := device.(type) {
+		case types.BaseVirtualController:
+			c := d.GetVirtualController()
+			obj := deviceLayoutController{
+				Key:        c.Key,
+				Type:       l.Type(device),
+				Label:      deviceLabel(vd),
+				BusNumber:  c.BusNumber,
+				DeviceKeys: append([]int32{}, c.Device...),
+			}
+			if sc, ok := device.(types.BaseVirtualSCSIController); ok {
+				obj.SharedBus = string(sc.GetVirtualSCSIController().SharedBus)
+			}
+			sort.Slice(obj.DeviceKeys, func(i, j int) bool { return obj.DeviceKeys[i] < obj.DeviceKeys[j] })
+			layout.Controllers = append(layout.Controllers, obj)
+		case *types.VirtualDisk:
+			obj := deviceLayoutDisk{
+				Key:           vd.Key,
+				Label:         deviceLabel(vd),
+				ControllerKey: vd.ControllerKey,
+				UnitNumber:    vd.UnitNumber,
+				CapacityKB:    d.CapacityInKB,
+			}
+			if b, ok := vd.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+				obj.FileName = b.GetVirtualDeviceFileBackingInfo().FileName
+			}
+			if b, ok := vd.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+				obj.UUID = b.Uuid
+			}
+			layout.Disks = append(layout.Disks, obj)
+		case types.BaseVirtualEthernetCard:
+			card := d.GetVirtualEthernetCard()
+			obj := deviceLayoutNetworkInterface{
+				Key:           vd.Key,
+				Label:         deviceLabel(vd),
+				Type:          virtualEthernetCardString(d),
+				ControllerKey: vd.ControllerKey,
+				UnitNumber:    vd.UnitNumber,
+				MacAddress:    card.MacAddress,
+			}
+			switch b := vd.Backing.(type) {
+			case *types.VirtualEthernetCardNetworkBackingInfo:
+				if b.Network != nil {
+					obj.NetworkID = b.Network.Value
+				}
+			case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+				obj.PortgroupKey = b.Port.PortgroupKey
+				obj.PortKey = b.Port.PortKey
+			case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+				obj.OpaqueNetworkID = b.OpaqueNetworkId
+			}
+			layout.NetworkInterfaces = append(layout.NetworkInterfaces, obj)
+		}
+	}
+
+	sort.Slice(layout.Controllers, func(i, j int) bool { return layout.Controllers[i].Key < layout.Controllers[j].Key })
+	sort.Slice(layout.Disks, func(i, j int) bool { return layout.Disks[i].Key < layout.Disks[j].Key })
+	sort.Slice(layout.NetworkInterfaces, func(i, j int) bool { return layout.NetworkInterfaces[i].Key < layout.NetworkInterfaces[j].Key })
+
+	b, err := json.Marshal(layout)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// deviceLabel returns the label of a device, such as "Hard disk 1", or an
+// empty string if the device has no description.
+func deviceLabel(vd *types.VirtualDevice) string {
+	if vd.DeviceInfo == nil {
+		return ""
+	}
+	return vd.DeviceInfo.GetDescription().Label
+}
@@ -0,0 +1,74 @@
+package virtualdevice
+
+import (
+	"testing"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestDeviceLayoutJSON(t *testing.T) {
+	disk := &types.VirtualDisk{
+		VirtualDevice: types.VirtualDevice{
+			Key:           2000,
+			ControllerKey: 1000,
+			UnitNumber:    structure.Int32Ptr(0),
+			DeviceInfo:    &types.Description{Label: "Hard disk 1"},
+			Backing: &types.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: "[datastore1] vm/vm.vmdk"},
+				Uuid:                         "6000C29a-0000",
+			},
+		},
+		CapacityInKB: 1024,
+	}
+	nic := &types.VirtualVmxnet3{
+		VirtualVmxnet: types.VirtualVmxnet{
+			VirtualEthernetCard: types.VirtualEthernetCard{
+				VirtualDevice: types.VirtualDevice{
+					Key:           4000,
+					ControllerKey: 100,
+					UnitNumber:    structure.Int32Ptr(7),
+					Backing: &types.VirtualEthernetCardDistributedVirtualPortBackingInfo{
+						Port: types.DistributedVirtualSwitchPortConnection{PortgroupKey: "dvportgroup-1", PortKey: "12"},
+					},
+				},
+				MacAddress: "00:50:56:00:00:01",
+			},
+		},
+	}
+	scsi := &types.ParaVirtualSCSIController{
+		VirtualSCSIController: types.VirtualSCSIController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 1000},
+				Device:        []int32{2001, 2000},
+			},
+			SharedBus: types.VirtualSCSISharingNoSharing,
+		},
+	}
+	pci := &types.VirtualPCIController{
+		VirtualController: types.VirtualController{
+			VirtualDevice: types.VirtualDevice{Key: 100},
+			Device:        []int32{4000, 1000},
+		},
+	}
+	expected := `{"controllers":[` +
+		`{"key":100,"type":"pci","label":"","bus_number":0,"device_keys":[1000,4000]},` +
+		`{"key":1000,"type":"pvscsi","label":"","bus_number":0,"shared_bus":"noSharing","device_keys":[2000,2001]}],` +
+		`"disks":[{"key":2000,"label":"Hard disk 1","controller_key":1000,"unit_number":0,"capacity_kb":1024,"file_name":"[datastore1] vm/vm.vmdk","uuid":"6000C29a-0000"}],` +
+		`"network_interfaces":[{"key":4000,"label":"","type":"vmxnet3","controller_key":100,"unit_number":7,"mac_address":"00:50:56:00:00:01","portgroup_key":"dvportgroup-1","port_key":"12"}]}`
+
+	// The order of the device list must not matter.
+	for _, l := range []object.VirtualDeviceList{
+		{nic, disk, scsi, pci},
+		{pci, scsi, disk, nic},
+	} {
+		actual, err := DeviceLayoutJSON(l)
+		if err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		if actual != expected {
+			t.Fatalf("expected %s, got %s", expected, actual)
+		}
+	}
+}
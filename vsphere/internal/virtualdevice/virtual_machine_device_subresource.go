@@ -572,6 +572,28 @@ func NormalizeSCSIBus(l object.VirtualDeviceList, ct string, count int) (object.
 	return l, spec, nil
 }
 
+// SCSIBusChangeHotAddable returns true if a set of SCSI bus changes from
+// NormalizeSCSIBus only adds controllers without bus sharing. Such controllers
+// can be hot-added to a powered on virtual machine. Changing the type of a
+// controller, or adding a shared one, needs the virtual machine to be powered
+// off.
+func SCSIBusChangeHotAddable(spec []types.BaseVirtualDeviceConfigSpec) bool {
+	for _, s := range spec {
+		dspec := s.GetVirtualDeviceConfigSpec()
+		if dspec.Operation != types.VirtualDeviceConfigSpecOperationAdd {
+			return false
+		}
+		sc, ok := dspec.Device.(types.BaseVirtualSCSIController)
+		if !ok {
+			return false
+		}
+		if sharing := sc.GetVirtualSCSIController().SharedBus; sharing != "" && sharing != types.VirtualSCSISharingNoSharing {
+			return false
+		}
+	}
+	return true
+}
+
 // ReadSCSIBusState checks the SCSI bus state and returns a device type
 // depending on if all controllers are one specific kind or not. Only the first
 // number of controllers specified by count are checked.
@@ -595,6 +617,92 @@ func ReadSCSIBusState(l object.VirtualDeviceList, count int) string {
 	return last
 }
 
+// NormalizeSCSIBusSharing sets the bus sharing mode of the SCSI controller at
+// the supplied bus number. The controller needs to exist, which is ensured by
+// running NormalizeSCSIBus first. A spec slice is returned with the changes.
+func NormalizeSCSIBusSharing(l object.VirtualDeviceList, bus int, sharing string) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] NormalizeSCSIBusSharing: Setting bus sharing on SCSI bus number %d to %s", bus, sharing)
+	ctlr, err := pickSCSIController(l, bus)
+	if err != nil {
+		return nil, nil, err
+	}
+	sc := ctlr.(types.BaseVirtualSCSIController).GetVirtualSCSIController()
+	if string(sc.SharedBus) == sharing {
+		return l, nil, nil
+	}
+	sc.SharedBus = types.VirtualSCSISharing(sharing)
+	if sc.Key < 0 {
+		// The controller is being added in the same operation, so the change
+		// has already been made to the device in the add spec.
+		return l, nil, nil
+	}
+	spec, err := object.VirtualDeviceList{ctlr.(types.BaseVirtualDevice)}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+	if err != nil {
+		return nil, nil, err
+	}
+	l = applyDeviceChange(l, spec)
+	log.Printf("[DEBUG] NormalizeSCSIBusSharing: Outgoing device config spec: %s", DeviceChangeString(spec))
+	return l, spec, nil
+}
+
+// ReadSCSIBusSharing returns the bus sharing mode of the SCSI controller at
+// the supplied bus number, or an empty string if there is no controller there.
+func ReadSCSIBusSharing(l object.VirtualDeviceList, bus int) string {
+	ctlr, err := pickSCSIController(l, bus)
+	if err != nil {
+		return ""
+	}
+	return string(ctlr.(types.BaseVirtualSCSIController).GetVirtualSCSIController().SharedBus)
+}
+
+// NormalizeVideoCard sets the video memory size, the number of displays, and
+// the 3D support of the video card of a virtual machine. Zero values and a nil
+// enable3D leave the current settings of the card alone. A spec slice is
+// returned with the changes.
+func NormalizeVideoCard(l object.VirtualDeviceList, videoRAMKB int64, displays int32, enable3D *bool) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	card := ReadVideoCard(l)
+	if card == nil {
+		return nil, nil, fmt.Errorf("no video card found on virtual machine")
+	}
+	var changed bool
+	if videoRAMKB > 0 && card.VideoRamSizeInKB != videoRAMKB {
+		card.VideoRamSizeInKB = videoRAMKB
+		// An explicit video memory size replaces the automatic size.
+		card.UseAutoDetect = structure.BoolPtr(false)
+		changed = true
+	}
+	if displays > 0 && card.NumDisplays != displays {
+		card.NumDisplays = displays
+		changed = true
+	}
+	current3D := card.Enable3DSupport != nil && *card.Enable3DSupport
+	if enable3D != nil && current3D != *enable3D {
+		card.Enable3DSupport = structure.BoolPtr(*enable3D)
+		changed = true
+	}
+	if !changed {
+		return l, nil, nil
+	}
+	log.Printf("[DEBUG] NormalizeVideoCard: Setting video card to %d KB and %d displays", card.VideoRamSizeInKB, card.NumDisplays)
+	spec, err := object.VirtualDeviceList{card}.ConfigSpec(types.VirtualDeviceConfigSpecOperationEdit)
+	if err != nil {
+		return nil, nil, err
+	}
+	l = applyDeviceChange(l, spec)
+	log.Printf("[DEBUG] NormalizeVideoCard: Outgoing device config spec: %s", DeviceChangeString(spec))
+	return l, spec, nil
+}
+
+// ReadVideoCard returns the video card of a virtual machine, or nil if there
+// is none.
+func ReadVideoCard(l object.VirtualDeviceList) *types.VirtualMachineVideoCard {
+	cards := l.SelectByType((*types.VirtualMachineVideoCard)(nil))
+	if len(cards) < 1 {
+		return nil
+	}
+	return cards[0].(*types.VirtualMachineVideoCard)
+}
+
 // getSCSIController picks a SCSI controller at the specific bus number supplied.
 func pickSCSIController(l object.VirtualDeviceList, bus int) (types.BaseVirtualController, error) {
 	log.Printf("[DEBUG] pickSCSIController: Looking for SCSI controller at bus number %d", bus)
@@ -0,0 +1,53 @@
+package virtualdevice
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestSCSIBusChangeHotAddable(t *testing.T) {
+	newController := func(sharing types.VirtualSCSISharing) types.BaseVirtualDevice {
+		return &types.ParaVirtualSCSIController{
+			VirtualSCSIController: types.VirtualSCSIController{SharedBus: sharing},
+		}
+	}
+	cases := []struct {
+		name     string
+		spec     []types.BaseVirtualDeviceConfigSpec
+		expected bool
+	}{
+		{
+			name: "added controllers",
+			spec: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{Operation: types.VirtualDeviceConfigSpecOperationAdd, Device: newController(types.VirtualSCSISharingNoSharing)},
+				&types.VirtualDeviceConfigSpec{Operation: types.VirtualDeviceConfigSpecOperationAdd, Device: newController(types.VirtualSCSISharingNoSharing)},
+			},
+			expected: true,
+		},
+		{
+			name: "added shared controller",
+			spec: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{Operation: types.VirtualDeviceConfigSpecOperationAdd, Device: newController(types.VirtualSCSISharingPhysicalSharing)},
+			},
+			expected: false,
+		},
+		{
+			name: "swapped controller",
+			spec: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{Operation: types.VirtualDeviceConfigSpecOperationRemove, Device: newController(types.VirtualSCSISharingNoSharing)},
+				&types.VirtualDeviceConfigSpec{Operation: types.VirtualDeviceConfigSpecOperationAdd, Device: newController(types.VirtualSCSISharingNoSharing)},
+				&types.VirtualDeviceConfigSpec{Operation: types.VirtualDeviceConfigSpecOperationEdit, Device: &types.VirtualDisk{}},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := SCSIBusChangeHotAddable(tc.spec); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
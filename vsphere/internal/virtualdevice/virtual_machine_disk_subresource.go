@@ -99,6 +99,11 @@ func DiskSubresourceSchema() map[string]*schema.Schema {
 				return nil, nil
 			},
 		},
+		"datastore_path": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The full datastore path of the virtual disk, such as [datastore1] vm/vm.vmdk.",
+		},
 		"disk_mode": {
 			Type:         schema.TypeString,
 			Optional:     true,
@@ -631,6 +636,7 @@ nextNew:
 		nm["key"] = 0
 		nm["device_address"] = ""
 		nm["uuid"] = ""
+		nm["datastore_path"] = ""
 		if a, ok := nm["attach"]; !ok || !a.(bool) {
 			nm["path"] = ""
 		}
@@ -782,6 +788,13 @@ func DiskCloneValidateOperation(d *schema.ResourceDiff, c *govmomi.Client, l obj
 func DiskMigrateRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) ([]types.VirtualMachineRelocateSpecDiskLocator, error) {
 	log.Printf("[DEBUG] DiskMigrateRelocateOperation: Generating any necessary disk relocate specs")
 	ods, nds := d.GetChange(subresourceTypeDisk)
+	// Disks that are not on the datastore of the configuration files need
+	// relocators to stay where they are, as the rest follow the configuration
+	// files.
+	home := d.Get("vmx_datastore_id").(string)
+	if home == "" {
+		home = d.Get("datastore_id").(string)
+	}
 
 	var relocators []types.VirtualMachineRelocateSpecDiskLocator
 
@@ -801,8 +814,9 @@ func DiskMigrateRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l o
 		for _, oe := range ods.([]interface{}) {
 			om := oe.(map[string]interface{})
 			if nm["uuid"] == om["uuid"] {
-				// No change in datastore is a no-op, unless we are changing default datastores
-				if nm["datastore_id"] == om["datastore_id"] && !d.HasChange("datastore_id") {
+				// No change in datastore is a no-op, unless we are changing default
+				// datastores or the datastore of the configuration files
+				if nm["datastore_id"] == om["datastore_id"] && !d.HasChange("datastore_id") && !d.HasChange("vmx_datastore_id") {
 					break
 				}
 				r := NewDiskSubresource(c, d, nm, om, ni)
@@ -810,7 +824,7 @@ func DiskMigrateRelocateOperation(d *schema.ResourceData, c *govmomi.Client, l o
 				if err != nil {
 					return nil, fmt.Errorf("%s: %s", r.Addr(), err)
 				}
-				if d.Get("datastore_id").(string) == relocator.Datastore.Value {
+				if home == relocator.Datastore.Value {
 					log.Printf("[DEBUG] %s: Datastore in spec is same as default, dropping in favor of implicit relocation", r.Addr())
 					break
 				}
@@ -938,13 +952,14 @@ func DiskPostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.
 			return nil, nil, fmt.Errorf("error copying current device state for disk at unit_number %d: %s", src["unit_number"].(int), err)
 		}
 		for k, v := range src {
-			// Skip label, path (path will always be computed here as cloned disks
-			// are not being attached externally), name, datastore_id, and uuid. Also
-			// skip share_count if we the share level isn't custom.
+			// Skip label, path and datastore_path (paths will always be computed
+			// here as cloned disks are not being attached externally), name,
+			// datastore_id, and uuid. Also skip share_count if we the share level
+			// isn't custom.
 			//
 			// TODO: Remove "name" after 2.0.
 			switch k {
-			case "label", "path", "name", "datastore_id", "uuid":
+			case "label", "path", "datastore_path", "name", "datastore_id", "uuid":
 				continue
 			case "io_share_count":
 				if src["io_share_level"] != string(types.SharesLevelCustom) {
@@ -1180,8 +1195,7 @@ func (r *DiskSubresource) Read(l object.VirtualDeviceList) error {
 	// situations where the VM hardware version does not actually allow disk
 	// sharing. In this situation, the value will be blank, and setting it will
 	// actually result in an error.
-	version := viapi.ParseVersionFromClient(r.client)
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) && b.Sharing != "" {
+	if viapi.CapabilityMultiWriterDiskSharing.Supported(r.client) && b.Sharing != "" {
 		r.Set("disk_sharing", b.Sharing)
 	}
 
@@ -1190,6 +1204,7 @@ func (r *DiskSubresource) Read(l object.VirtualDeviceList) error {
 		r.Set("eagerly_scrub", b.EagerlyScrub)
 	}
 	r.Set("datastore_id", b.Datastore.Value)
+	r.Set("datastore_path", b.FileName)
 
 	// Disk settings
 	if !attach {
@@ -1303,14 +1318,16 @@ func (r *DiskSubresource) Diff() error {
 		r.Set("name", oname.(string))
 	}
 
-	// set some computed fields: key, device_address, and uuid will always be
-	// non-populated, so copy those.
+	// set some computed fields: key, device_address, uuid, and datastore_path
+	// will always be non-populated, so copy those.
 	okey, _ := r.GetChange("key")
 	odaddr, _ := r.GetChange("device_address")
 	ouuid, _ := r.GetChange("uuid")
+	odspath, _ := r.GetChange("datastore_path")
 	r.Set("key", okey)
 	r.Set("device_address", odaddr)
 	r.Set("uuid", ouuid)
+	r.Set("datastore_path", odspath)
 
 	// Enforce the maximum unit number, which is the current value of
 	// scsi_controller_count * 15 - 1.
@@ -1395,10 +1412,9 @@ func (r *DiskSubresource) Diff() error {
 	}
 
 	// Block certain options from being set depending on the vSphere version.
-	version := viapi.ParseVersionFromClient(r.client)
 	if r.Get("disk_sharing").(string) != string(types.VirtualDiskSharingSharingNone) {
-		if version.Older(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
-			return fmt.Errorf("multi-writer disk_sharing is only supported on vSphere 6 and higher")
+		if err := viapi.CapabilityMultiWriterDiskSharing.Validate(r.client); err != nil {
+			return err
 		}
 	}
 
@@ -1512,8 +1528,7 @@ func (r *DiskSubresource) expandDiskSettings(disk *types.VirtualDisk) error {
 	b.WriteThrough = structure.BoolPtr(r.GetWithRestart("write_through").(bool))
 
 	// Only use disk_sharing if we are on vSphere 6.0 and higher
-	version := viapi.ParseVersionFromClient(r.client)
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityMultiWriterDiskSharing.Supported(r.client) {
 		b.Sharing = r.GetWithRestart("disk_sharing").(string)
 	}
 
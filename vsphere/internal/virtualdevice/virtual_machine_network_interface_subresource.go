@@ -85,6 +85,12 @@ func NetworkInterfaceSubresourceSchema() map[string]*schema.Schema {
 			Description:  "The ID of the network to connect this network interface to.",
 			ValidateFunc: validation.NoZeroValues,
 		},
+		"distributed_port_key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The key of the distributed port to connect this network interface to, when network_id is a static binding distributed port group.",
+		},
 		"adapter_type": {
 			Type:         schema.TypeString,
 			Optional:     true,
@@ -369,57 +375,110 @@ func NetworkInterfaceDiffOperation(d *schema.ResourceDiff, c *govmomi.Client) er
 	return nil
 }
 
-// NetworkInterfacePostCloneOperation normalizes the network interfaces on a
-// freshly-cloned virtual machine and outputs any necessary device change
-// operations. It also sets the state in advance of the post-create read.
-//
-// This differs from a regular apply operation in that a configuration is
-// already present, but we don't have any existing state, which the standard
-// virtual device operations rely pretty heavily on.
-func NetworkInterfacePostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
-	log.Printf("[DEBUG] NetworkInterfacePostCloneOperation: Looking for post-clone device changes")
+// networkInterfaceSourceSet reads the network interfaces on a freshly-cloned
+// virtual machine as if they were orphaned, and returns them laid out by their
+// unit number. Unit numbers that do not have a device are left as nil.
+func networkInterfaceSourceSet(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) ([]interface{}, error) {
 	devices := l.Select(func(device types.BaseVirtualDevice) bool {
 		if _, ok := device.(types.BaseVirtualEthernetCard); ok {
 			return true
 		}
 		return false
 	})
-	log.Printf("[DEBUG] NetworkInterfacePostCloneOperation: Network devices located: %s", DeviceListString(devices))
-	curSet := d.Get(subresourceTypeNetworkInterface).([]interface{})
-	log.Printf("[DEBUG] NetworkInterfacePostCloneOperation: Current resource set from configuration: %s", subresourceListString(curSet))
+	log.Printf("[DEBUG] networkInterfaceSourceSet: Network devices located: %s", DeviceListString(devices))
 	urange, err := nicUnitRange(devices)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error calculating network device range: %s", err)
+		return nil, fmt.Errorf("error calculating network device range: %s", err)
 	}
 	srcSet := make([]interface{}, urange)
-	log.Printf("[DEBUG] NetworkInterfacePostCloneOperation: Layout from source: %d devices over a %d unit range", len(devices), urange)
+	log.Printf("[DEBUG] networkInterfaceSourceSet: Layout from source: %d devices over a %d unit range", len(devices), urange)
 
 	// Populate the source set as if the devices were orphaned. This give us a
 	// base to diff off of.
-	log.Printf("[DEBUG] NetworkInterfacePostCloneOperation: Reading existing devices")
+	log.Printf("[DEBUG] networkInterfaceSourceSet: Reading existing devices")
 	for n, device := range devices {
 		m := make(map[string]interface{})
 		vd := device.GetVirtualDevice()
 		ctlr := l.FindByKey(vd.ControllerKey)
 		if ctlr == nil {
-			return nil, nil, fmt.Errorf("could not find controller with key %d", vd.Key)
+			return nil, fmt.Errorf("could not find controller with key %d", vd.Key)
 		}
 		m["key"] = int(vd.Key)
 		var err error
 		m["device_address"], err = computeDevAddr(vd, ctlr.(types.BaseVirtualController))
 		if err != nil {
-			return nil, nil, fmt.Errorf("error computing device address: %s", err)
+			return nil, fmt.Errorf("error computing device address: %s", err)
 		}
 		r := NewNetworkInterfaceSubresource(c, d, m, nil, n)
 		if err := r.Read(l); err != nil {
-			return nil, nil, fmt.Errorf("%s: %s", r.Addr(), err)
+			return nil, fmt.Errorf("%s: %s", r.Addr(), err)
 		}
 		_, _, idx, err := splitDevAddr(r.Get("device_address").(string))
 		if err != nil {
-			return nil, nil, fmt.Errorf("%s: error parsing device address: %s", r, err)
+			return nil, fmt.Errorf("%s: error parsing device address: %s", r, err)
 		}
 		srcSet[idx-networkInterfacePciDeviceOffset] = r.Data()
 	}
+	return srcSet, nil
+}
+
+// NetworkInterfaceNetworkMapOperation populates the network_interface
+// configuration of a freshly-cloned virtual machine from the network
+// interfaces of its source, so that the subsequent post-clone operation keeps
+// them as they are. The network of each interface is passed to mapFunc by
+// name, and if mapFunc returns true, the interface is moved to the network ID
+// that it returns.
+//
+// This is used when the clone block has a network map and there are no
+// network_interface blocks in configuration.
+func NetworkInterfaceNetworkMapOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList, mapFunc func(name string) (string, bool)) error {
+	log.Printf("[DEBUG] NetworkInterfaceNetworkMapOperation: Mapping networks of source network interfaces")
+	srcSet, err := networkInterfaceSourceSet(d, c, l)
+	if err != nil {
+		return err
+	}
+	var curSet []interface{}
+	for _, si := range srcSet {
+		if si == nil {
+			continue
+		}
+		sm := si.(map[string]interface{})
+		netID := sm["network_id"].(string)
+		net, err := network.FromID(c, netID)
+		if err != nil {
+			return fmt.Errorf("could not find network ID %q: %s", netID, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+		name, err := object.NewCommon(c.Client, net.Reference()).ObjectName(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("error fetching name of network ID %q: %s", netID, err)
+		}
+		if id, ok := mapFunc(name); ok {
+			log.Printf("[DEBUG] NetworkInterfaceNetworkMapOperation: Mapping network %q (%s) to %s", name, netID, id)
+			sm["network_id"] = id
+		}
+		curSet = append(curSet, sm)
+	}
+	log.Printf("[DEBUG] NetworkInterfaceNetworkMapOperation: Resource set after mapping: %s", subresourceListString(curSet))
+	return d.Set(subresourceTypeNetworkInterface, curSet)
+}
+
+// NetworkInterfacePostCloneOperation normalizes the network interfaces on a
+// freshly-cloned virtual machine and outputs any necessary device change
+// operations. It also sets the state in advance of the post-create read.
+//
+// This differs from a regular apply operation in that a configuration is
+// already present, but we don't have any existing state, which the standard
+// virtual device operations rely pretty heavily on.
+func NetworkInterfacePostCloneOperation(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	log.Printf("[DEBUG] NetworkInterfacePostCloneOperation: Looking for post-clone device changes")
+	curSet := d.Get(subresourceTypeNetworkInterface).([]interface{})
+	log.Printf("[DEBUG] NetworkInterfacePostCloneOperation: Current resource set from configuration: %s", subresourceListString(curSet))
+	srcSet, err := networkInterfaceSourceSet(d, c, l)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Now go over our current set, kind of treating it like an apply:
 	//
@@ -594,6 +653,9 @@ func (r *NetworkInterfaceSubresource) Create(l object.VirtualDeviceList) ([]type
 	if err != nil {
 		return nil, err
 	}
+	if err := r.setDistributedPortKey(backing); err != nil {
+		return nil, err
+	}
 	device, err := l.CreateEthernetCard(r.Get("adapter_type").(string), backing)
 	if err != nil {
 		return nil, err
@@ -619,8 +681,7 @@ func (r *NetworkInterfaceSubresource) Create(l object.VirtualDeviceList) ([]type
 		card.AddressType = string(types.VirtualEthernetCardMacTypeManual)
 		card.MacAddress = r.Get("mac_address").(string)
 	}
-	version := viapi.ParseVersionFromClient(r.client)
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityNetworkResourceAllocation.Supported(r.client) {
 		alloc := &types.VirtualEthernetCardResourceAllocation{
 			Limit:       structure.Int64Ptr(int64(r.Get("bandwidth_limit").(int))),
 			Reservation: structure.Int64Ptr(int64(r.Get("bandwidth_reservation").(int))),
@@ -689,6 +750,7 @@ func (r *NetworkInterfaceSubresource) Read(l object.VirtualDeviceList) error {
 			return err
 		}
 		netID = pg.Reference().Value
+		r.Set("distributed_port_key", backing.Port.PortKey)
 	default:
 		return fmt.Errorf("unknown network interface backing %T", card.Backing)
 	}
@@ -697,8 +759,7 @@ func (r *NetworkInterfaceSubresource) Read(l object.VirtualDeviceList) error {
 	r.Set("use_static_mac", card.AddressType == string(types.VirtualEthernetCardMacTypeManual))
 	r.Set("mac_address", card.MacAddress)
 
-	version := viapi.ParseVersionFromClient(r.client)
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityNetworkResourceAllocation.Supported(r.client) {
 		if card.ResourceAllocation != nil {
 			r.Set("bandwidth_limit", card.ResourceAllocation.Limit)
 			r.Set("bandwidth_reservation", card.ResourceAllocation.Reservation)
@@ -782,7 +843,7 @@ func (r *NetworkInterfaceSubresource) Update(l object.VirtualDeviceList) ([]type
 	card := device.GetVirtualEthernetCard()
 
 	// Has the backing changed?
-	if r.HasChange("network_id") {
+	if r.HasChange("network_id") || r.HasChange("distributed_port_key") {
 		net, err := network.FromID(r.client, r.Get("network_id").(string))
 		if err != nil {
 			return nil, err
@@ -793,6 +854,15 @@ func (r *NetworkInterfaceSubresource) Update(l object.VirtualDeviceList) ([]type
 		if err != nil {
 			return nil, err
 		}
+		// distributed_port_key is computed, so it holds the port that the
+		// interface was connected to when it is not set in configuration. That
+		// port is only kept when it is changed explicitly, and vCenter picks a
+		// free port in the new network otherwise.
+		if r.HasChange("distributed_port_key") {
+			if err := r.setDistributedPortKey(backing); err != nil {
+				return nil, err
+			}
+		}
 		card.Backing = backing
 	}
 
@@ -867,6 +937,41 @@ func (r *NetworkInterfaceSubresource) Delete(l object.VirtualDeviceList) ([]type
 	return spec, nil
 }
 
+// setDistributedPortKey pins a distributed port group backing to the port in
+// distributed_port_key, if it is set.
+func (r *NetworkInterfaceSubresource) setDistributedPortKey(backing types.BaseVirtualDeviceBackingInfo) error {
+	if r.Get("distributed_port_key").(string) == "" {
+		return nil
+	}
+	dvpBacking, ok := backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+	if !ok {
+		return fmt.Errorf("%s: distributed_port_key can only be set when network_id is a distributed port group", r)
+	}
+	pg, err := dvportgroup.FromKey(r.client, dvpBacking.Port.SwitchUuid, dvpBacking.Port.PortgroupKey)
+	if err != nil {
+		return err
+	}
+	props, err := dvportgroup.Properties(pg)
+	if err != nil {
+		return err
+	}
+	return r.pinDistributedPort(dvpBacking, props.Config.Type)
+}
+
+// pinDistributedPort sets the port in distributed_port_key on a distributed
+// port group backing. Only static binding port groups have ports that can be
+// connected to directly, so an error is returned for any other port group
+// type.
+func (r *NetworkInterfaceSubresource) pinDistributedPort(backing *types.VirtualEthernetCardDistributedVirtualPortBackingInfo, pgType string) error {
+	if pgType != string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding) {
+		return fmt.Errorf("%s: distributed_port_key requires a distributed port group of the %s type, got %s", r, types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding, pgType)
+	}
+	key := r.Get("distributed_port_key").(string)
+	log.Printf("[DEBUG] %s: Connecting to distributed port %q", r, key)
+	backing.Port.PortKey = key
+	return nil
+}
+
 // ValidateDiff performs any complex validation of an individual
 // network_interface sub-resource that can't be done in schema alone.
 func (r *NetworkInterfaceSubresource) ValidateDiff() error {
@@ -874,8 +979,7 @@ func (r *NetworkInterfaceSubresource) ValidateDiff() error {
 
 	// Ensure that network resource allocation options are only set on vSphere
 	// 6.0 and higher.
-	version := viapi.ParseVersionFromClient(r.client)
-	if version.Older(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if !viapi.CapabilityNetworkResourceAllocation.Supported(r.client) {
 		if err := r.restrictResourceAllocationSettings(); err != nil {
 			return err
 		}
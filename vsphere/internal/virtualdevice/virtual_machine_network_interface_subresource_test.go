@@ -1,6 +1,7 @@
 package virtualdevice
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
@@ -115,3 +116,109 @@ func TestNicUnitRange(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkInterfacePinDistributedPort(t *testing.T) {
+	cases := []struct {
+		name     string
+		pgType   types.DistributedVirtualPortgroupPortgroupType
+		expected string
+		err      string
+	}{
+		{
+			name:     "static binding",
+			pgType:   types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding,
+			expected: "42",
+		},
+		{
+			name:   "ephemeral binding",
+			pgType: types.DistributedVirtualPortgroupPortgroupTypeEphemeral,
+			err:    "distributed_port_key requires a distributed port group of the earlyBinding type, got ephemeral",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewNetworkInterfaceSubresource(nil, nil, map[string]interface{}{
+				"distributed_port_key": "42",
+				"device_address":       "",
+				"key":                  0,
+			}, nil, 0)
+			backing := &types.VirtualEthernetCardDistributedVirtualPortBackingInfo{
+				Port: types.DistributedVirtualSwitchPortConnection{
+					PortgroupKey: "dvportgroup-1",
+					SwitchUuid:   "dvs-uuid",
+				},
+			}
+			err := r.pinDistributedPort(backing, string(tc.pgType))
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				if backing.Port.PortKey != "" {
+					t.Fatalf("expected port key to be left unset, got %q", backing.Port.PortKey)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if backing.Port.PortKey != tc.expected {
+				t.Fatalf("expected port key %q, got %q", tc.expected, backing.Port.PortKey)
+			}
+		})
+	}
+}
+
+func TestNetworkInterfaceSetDistributedPortKey(t *testing.T) {
+	cases := []struct {
+		name    string
+		key     string
+		backing types.BaseVirtualDeviceBackingInfo
+		err     string
+	}{
+		{
+			name: "no port key",
+			backing: &types.VirtualEthernetCardDistributedVirtualPortBackingInfo{
+				Port: types.DistributedVirtualSwitchPortConnection{
+					PortgroupKey: "dvportgroup-1",
+					SwitchUuid:   "dvs-uuid",
+				},
+			},
+		},
+		{
+			name: "no port key on standard port group",
+			backing: &types.VirtualEthernetCardNetworkBackingInfo{
+				Network: &types.ManagedObjectReference{Type: "Network", Value: "network-1"},
+			},
+		},
+		{
+			name: "port key on standard port group",
+			key:  "42",
+			backing: &types.VirtualEthernetCardNetworkBackingInfo{
+				Network: &types.ManagedObjectReference{Type: "Network", Value: "network-1"},
+			},
+			err: "distributed_port_key can only be set when network_id is a distributed port group",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewNetworkInterfaceSubresource(nil, nil, map[string]interface{}{
+				"distributed_port_key": tc.key,
+				"device_address":       "",
+				"key":                  0,
+			}, nil, 0)
+			err := r.setDistributedPortKey(tc.backing)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if dvp, ok := tc.backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo); ok && dvp.Port.PortKey != "" {
+				t.Fatalf("expected port key to be left unset, got %q", dvp.Port.PortKey)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package vmworkflow
 import (
 	"fmt"
 	"log"
+	"regexp"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -20,41 +21,104 @@ import (
 // VirtualMachineCloneSchema represents the schema for the VM clone sub-resource.
 //
 // This is a workflow for vsphere_virtual_machine that facilitates the creation
-// of a virtual machine through cloning from an existing template.
+// of a virtual machine through cloning from an existing template, or through
+// deploying an OVF template or VM template from a content library.
 // Customization is nested here, even though it exists in its own workflow.
+//
+// None of the attributes in this sub-resource force a new resource. The clone
+// workflow is only consulted when the virtual machine is created, and any
+// changes made to it after that are ignored in CustomizeDiff. This allows
+// templates to be rotated without affecting existing virtual machines.
 func VirtualMachineCloneSchema() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
 		"template_uuid": {
 			Type:        schema.TypeString,
-			Required:    true,
-			ForceNew:    true,
-			Description: "The UUID of the source virtual machine or template.",
+			Optional:    true,
+			Description: "The UUID of the source virtual machine or template. Conflicts with content_library_item_id.",
+		},
+		"content_library_item_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The ID of an OVF template or VM template in a content library to deploy the virtual machine from. Conflicts with template_uuid.",
 		},
 		"linked_clone": {
 			Type:        schema.TypeBool,
 			Optional:    true,
-			ForceNew:    true,
 			Description: "Whether or not to create a linked clone when cloning. When this option is used, the source VM must have a single snapshot associated with it.",
 		},
 		"timeout": {
 			Type:         schema.TypeInt,
 			Optional:     true,
-			ForceNew:     true,
 			Default:      30,
 			Description:  "The timeout, in minutes, to wait for the virtual machine clone to complete.",
 			ValidateFunc: validation.IntAtLeast(10),
 		},
+		"cleanup_on_failure": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Delete the virtual machine if any operation after the clone itself fails, like reconfiguration or customization. When not set, the virtual machine is kept in state as tainted.",
+		},
 		"customize": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			ForceNew:    true,
 			MaxItems:    1,
 			Description: "The customization spec for this clone. This allows the user to configure the virtual machine post-clone.",
 			Elem:        &schema.Resource{Schema: VirtualMachineCustomizeSchema()},
 		},
+		"network_map": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Maps the networks of the network interfaces on the source virtual machine or template to new networks. Only used when no network_interface blocks are defined, in which case the network interfaces of the source are kept.",
+			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"source": {
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "The name of a network on the source, or a regular expression that matches the whole name.",
+					ValidateFunc: validation.ValidateRegexp,
+				},
+				"network_id": {
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "The ID of the network to connect matching network interfaces to.",
+					ValidateFunc: validation.NoZeroValues,
+				},
+			}},
+		},
 	}
 }
 
+// VirtualMachineCloneNetworkMapper returns a function that maps the name of a
+// network on the source of a clone to the ID of a network from the clone's
+// network_map. The first entry whose source is equal to, or as a regular
+// expression matches, the whole name is used. The function returns false if
+// no entry matches, in which case the network interface is left on its
+// original network.
+func VirtualMachineCloneNetworkMapper(d *schema.ResourceData) (func(name string) (string, bool), error) {
+	type mapping struct {
+		source string
+		re     *regexp.Regexp
+		netID  string
+	}
+	var mappings []mapping
+	for i, v := range d.Get("clone.0.network_map").([]interface{}) {
+		m := v.(map[string]interface{})
+		source := m["source"].(string)
+		re, err := regexp.Compile("^(?:" + source + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("clone.0.network_map.%d.source: invalid regular expression %q: %s", i, source, err)
+		}
+		mappings = append(mappings, mapping{source: source, re: re, netID: m["network_id"].(string)})
+	}
+	return func(name string) (string, bool) {
+		for _, m := range mappings {
+			if m.source == name || m.re.MatchString(name) {
+				return m.netID, true
+			}
+		}
+		return "", false
+	}, nil
+}
+
 // ValidateVirtualMachineClone does pre-creation validation of a virtual
 // machine's configuration to make sure it's suitable for use in cloning.
 // This includes, but is not limited to checking to make sure that the disks in
@@ -63,10 +127,19 @@ func VirtualMachineCloneSchema() map[string]*schema.Schema {
 // use in the even that linked clones are enabled.
 func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) error {
 	tUUID := d.Get("clone.0.template_uuid").(string)
+	itemID := d.Get("clone.0.content_library_item_id").(string)
+	switch {
+	case tUUID == "" && itemID == "":
+		return fmt.Errorf("one of clone.0.template_uuid or clone.0.content_library_item_id must be set")
+	case tUUID != "" && itemID != "":
+		return fmt.Errorf("clone.0.template_uuid and clone.0.content_library_item_id cannot be used together")
+	case itemID != "":
+		return validateVirtualMachineCloneLibraryItem(d, c)
+	}
 	log.Printf("[DEBUG] ValidateVirtualMachineClone: Validating fitness of source VM/template %s", tUUID)
 	vm, err := virtualmachine.FromUUID(c, tUUID)
 	if err != nil {
-		return fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", tUUID, err)
+		return CloneSourceLocateError(c, tUUID, err)
 	}
 	vprops, err := virtualmachine.Properties(vm)
 	if err != nil {
@@ -100,19 +173,8 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 	}
 
 	// If a customization spec was defined, we need to check some items in it as well.
-	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
-		poolID := d.Get("resource_pool_id").(string)
-		pool, err := resourcepool.FromID(c, poolID)
-		if err != nil {
-			return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
-		}
-		family, err := resourcepool.OSFamily(c, pool, d.Get("guest_id").(string))
-		if err != nil {
-			return fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
-		}
-		if err := ValidateCustomizationSpec(d, family); err != nil {
-			return err
-		}
+	if err := validateCloneCustomization(d, c); err != nil {
+		return err
 	}
 	vconfig := vprops.Config.VAppConfig
 	if vconfig != nil {
@@ -126,6 +188,36 @@ func ValidateVirtualMachineClone(d *schema.ResourceDiff, c *govmomi.Client) erro
 	return nil
 }
 
+// validateVirtualMachineCloneLibraryItem validates a clone from a content
+// library item. The item is only read through the CIS REST API when the
+// virtual machine is created, so the disks and guest ID of the template in it
+// cannot be checked here, and the disks in configuration need to match the
+// disks of the template, like they do for clones from virtual machines.
+func validateVirtualMachineCloneLibraryItem(d *schema.ResourceDiff, c *govmomi.Client) error {
+	if d.Get("clone.0.linked_clone").(bool) {
+		return fmt.Errorf("linked_clone cannot be used with content_library_item_id")
+	}
+	return validateCloneCustomization(d, c)
+}
+
+// validateCloneCustomization checks the customization spec of a clone, if
+// one is defined, against the OS family of the guest ID.
+func validateCloneCustomization(d *schema.ResourceDiff, c *govmomi.Client) error {
+	if len(d.Get("clone.0.customize").([]interface{})) < 1 {
+		return nil
+	}
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(c, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	family, err := resourcepool.OSFamily(c, pool, d.Get("guest_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
+	}
+	return ValidateCustomizationSpec(d, family)
+}
+
 // validateCloneSnapshots checks a VM to make sure it has a single snapshot
 // with no children, to make sure there is no ambiguity when selecting a
 // snapshot for linked clones.
@@ -157,7 +249,13 @@ func validateCloneSnapshots(props *mo.VirtualMachine) error {
 func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (types.VirtualMachineCloneSpec, *object.VirtualMachine, error) {
 	var spec types.VirtualMachineCloneSpec
 	log.Printf("[DEBUG] ExpandVirtualMachineCloneSpec: Preparing clone spec for VM")
-	ds, err := datastore.FromID(c, d.Get("datastore_id").(string))
+	// The configuration files go to vmx_datastore_id if it is set. The disks
+	// get relocators of their own, so they are not affected by this.
+	dsID := d.Get("vmx_datastore_id").(string)
+	if dsID == "" {
+		dsID = d.Get("datastore_id").(string)
+	}
+	ds, err := datastore.FromID(c, dsID)
 	if err != nil {
 		return spec, nil, fmt.Errorf("error locating datastore for VM: %s", err)
 	}
@@ -167,7 +265,7 @@ func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (t
 	log.Printf("[DEBUG] ExpandVirtualMachineCloneSpec: Cloning from UUID: %s", tUUID)
 	vm, err := virtualmachine.FromUUID(c, tUUID)
 	if err != nil {
-		return spec, nil, fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", tUUID, err)
+		return spec, nil, CloneSourceLocateError(c, tUUID, err)
 	}
 	vprops, err := virtualmachine.Properties(vm)
 	if err != nil {
@@ -222,3 +320,22 @@ func ExpandVirtualMachineCloneSpec(d *schema.ResourceData, c *govmomi.Client) (t
 	log.Printf("[DEBUG] ExpandVirtualMachineCloneSpec: Clone spec prep complete")
 	return spec, vm, nil
 }
+
+// CloneSourceLocateError returns the error for a clone source that could not
+// be located. Virtual machines are only searched for in the inventory of the
+// vCenter Server that the client is connected to, even in linked mode, so
+// the error for a UUID that is not found explains how to clone from another
+// vCenter Server.
+func CloneSourceLocateError(c *govmomi.Client, uuid string, err error) error {
+	if _, ok := err.(*virtualmachine.UUIDNotFoundError); ok {
+		return fmt.Errorf(
+			"cannot locate virtual machine or template with UUID %q on %s. "+
+				"Templates in other vCenter Servers, including those in the same linked mode group, cannot be cloned from this one: "+
+				"use a provider configured for the vCenter Server that has the template, "+
+				"or publish the template to a content library that this vCenter Server subscribes to",
+			uuid,
+			c.URL().Host,
+		)
+	}
+	return fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", uuid, err)
+}
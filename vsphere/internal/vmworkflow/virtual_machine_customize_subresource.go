@@ -58,14 +58,12 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 		"dns_server_list": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			ForceNew:    true,
 			Description: "The list of DNS servers for a virtual network adapter with a static IP address.",
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
 		"dns_suffix_list": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			ForceNew:    true,
 			Description: "A list of DNS search domains to add to the DNS configuration on the virtual machine.",
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
@@ -74,7 +72,6 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 		"linux_options": {
 			Type:          schema.TypeList,
 			Optional:      true,
-			ForceNew:      true,
 			MaxItems:      1,
 			ConflictsWith: []string{cKeyPrefix + "." + "windows_options", cKeyPrefix + "." + "windows_sysprep_text"},
 			Description:   "A list of configuration options specific to Linux virtual machines.",
@@ -82,26 +79,22 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"domain": {
 					Type:        schema.TypeString,
 					Required:    true,
-					ForceNew:    true,
 					Description: "The FQDN for this virtual machine.",
 				},
 				"host_name": {
 					Type:        schema.TypeString,
 					Required:    true,
-					ForceNew:    true,
 					Description: "The host name for this virtual machine.",
 				},
 				"hw_clock_utc": {
 					Type:        schema.TypeBool,
 					Optional:    true,
-					ForceNew:    true,
 					Default:     true,
 					Description: "Specifies whether or not the hardware clock should be in UTC or not.",
 				},
 				"time_zone": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "Customize the time zone on the VM. This should be a time zone-style entry, like America/Los_Angeles.",
 					ValidateFunc: validation.StringMatch(
 						regexp.MustCompile("^[-+/_a-zA-Z0-9]+$"),
@@ -115,7 +108,6 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 		"windows_options": {
 			Type:          schema.TypeList,
 			Optional:      true,
-			ForceNew:      true,
 			MaxItems:      1,
 			ConflictsWith: []string{cKeyPrefix + "." + "linux_options", cKeyPrefix + "." + "windows_sysprep_text"},
 			Description:   "A list of configuration options specific to Windows virtual machines.",
@@ -124,7 +116,6 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"run_once_command_list": {
 					Type:        schema.TypeList,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "A list of commands to run at first user logon, after guest customization.",
 					Elem:        &schema.Schema{Type: schema.TypeString},
 				},
@@ -132,27 +123,23 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"auto_logon": {
 					Type:        schema.TypeBool,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "Specifies whether or not the VM automatically logs on as Administrator.",
 				},
 				"auto_logon_count": {
 					Type:        schema.TypeInt,
 					Optional:    true,
-					ForceNew:    true,
 					Default:     1,
 					Description: "Specifies how many times the VM should auto-logon the Administrator account when auto_logon is true.",
 				},
 				"admin_password": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Sensitive:   true,
 					Description: "The new administrator password for this virtual machine.",
 				},
 				"time_zone": {
 					Type:        schema.TypeInt,
 					Optional:    true,
-					ForceNew:    true,
 					Default:     85,
 					Description: "The new time zone for the virtual machine. This is a sysprep-dictated timezone code.",
 				},
@@ -161,14 +148,12 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"domain_admin_user": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ForceNew:      true,
 					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
 					Description:   "The user account of the domain administrator used to join this virtual machine to the domain.",
 				},
 				"domain_admin_password": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ForceNew:      true,
 					Sensitive:     true,
 					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
 					Description:   "The password of the domain administrator used to join this virtual machine to the domain.",
@@ -176,14 +161,12 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"join_domain": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ForceNew:      true,
 					ConflictsWith: []string{cWindowsKeyPrefix + "." + "workgroup"},
 					Description:   "The domain that the virtual machine should join.",
 				},
 				"workgroup": {
 					Type:          schema.TypeString,
 					Optional:      true,
-					ForceNew:      true,
 					ConflictsWith: []string{cWindowsKeyPrefix + "." + "join_domain"},
 					Description:   "The workgroup for this virtual machine if not joining a domain.",
 				},
@@ -192,27 +175,23 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 				"computer_name": {
 					Type:        schema.TypeString,
 					Required:    true,
-					ForceNew:    true,
 					Description: "The host name for this virtual machine.",
 				},
 				"full_name": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Default:     "Administrator",
 					Description: "The full name of the user of this virtual machine.",
 				},
 				"organization_name": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Default:     "Managed by Terraform",
 					Description: "The organization name this virtual machine is being installed for.",
 				},
 				"product_key": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "The product key for this virtual machine.",
 				},
 			}},
@@ -222,8 +201,8 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 		"windows_sysprep_text": {
 			Type:          schema.TypeString,
 			Optional:      true,
-			ForceNew:      true,
 			ConflictsWith: []string{cKeyPrefix + "." + "linux_options", cKeyPrefix + "." + "windows_options"},
+			Sensitive:     true,
 			Description:   "Use this option to specify a windows sysprep file directly.",
 		},
 
@@ -231,44 +210,37 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 		"network_interface": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			ForceNew:    true,
 			Description: "A specification of network interface configuration options.",
 			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
 				"dns_server_list": {
 					Type:        schema.TypeList,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "Network-interface specific DNS settings for Windows operating systems. Ignored on Linux.",
 					Elem:        &schema.Schema{Type: schema.TypeString},
 				},
 				"dns_domain": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "A DNS search domain to add to the DNS configuration on the virtual machine.",
 				},
 				"ipv4_address": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "The IPv4 address assigned to this network adapter. If left blank, DHCP is used.",
 				},
 				"ipv4_netmask": {
 					Type:        schema.TypeInt,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "The IPv4 CIDR netmask for the supplied IP address. Ignored if DHCP is selected.",
 				},
 				"ipv6_address": {
 					Type:        schema.TypeString,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "The IPv6 address assigned to this network adapter. If left blank, default auto-configuration is used.",
 				},
 				"ipv6_netmask": {
 					Type:        schema.TypeInt,
 					Optional:    true,
-					ForceNew:    true,
 					Description: "The IPv6 CIDR netmask for the supplied IP address. Ignored if auto-configuration is selected.",
 				},
 			}},
@@ -278,19 +250,16 @@ func VirtualMachineCustomizeSchema() map[string]*schema.Schema {
 		"ipv4_gateway": {
 			Type:        schema.TypeString,
 			Optional:    true,
-			ForceNew:    true,
 			Description: "The IPv4 default gateway when using network_interface customization on the virtual machine. This address must be local to a static IPv4 address configured in an interface sub-resource.",
 		},
 		"ipv6_gateway": {
 			Type:        schema.TypeString,
 			Optional:    true,
-			ForceNew:    true,
 			Description: "The IPv6 default gateway when using network_interface customization on the virtual machine. This address must be local to a static IPv4 address configured in an interface sub-resource.",
 		},
 		"timeout": {
 			Type:        schema.TypeInt,
 			Optional:    true,
-			ForceNew:    true,
 			Default:     10,
 			Description: "The amount of time, in minutes, to wait for guest OS customization to complete before returning with an error. Setting this value to 0 or a negative value skips the waiter.",
 		},
@@ -511,6 +480,42 @@ func ExpandCustomizationSpec(d *schema.ResourceData, family string) types.Custom
 	return obj
 }
 
+// customizationWindowsSecretKeys are the keys in windows_options that hold
+// passwords.
+var customizationWindowsSecretKeys = []string{"admin_password", "domain_admin_password"}
+
+// ScrubCustomizationSecrets removes the passwords in the customize block from
+// the resource data, so that they are not saved to state. Customization is
+// only sent when a virtual machine is cloned, and changes to the clone block
+// are ignored on existing virtual machines, so these values are not needed
+// after the clone. The sysprep text is also removed, as it normally holds the
+// administrator password.
+func ScrubCustomizationSecrets(d *schema.ResourceData) error {
+	if len(d.Get("clone.0.customize").([]interface{})) < 1 {
+		return nil
+	}
+	clone := d.Get("clone").([]interface{})
+	customize := clone[0].(map[string]interface{})["customize"].([]interface{})[0].(map[string]interface{})
+	var scrubbed bool
+	if v, ok := customize["windows_sysprep_text"].(string); ok && v != "" {
+		customize["windows_sysprep_text"] = ""
+		scrubbed = true
+	}
+	if wo, ok := customize["windows_options"].([]interface{}); ok && len(wo) > 0 && wo[0] != nil {
+		m := wo[0].(map[string]interface{})
+		for _, k := range customizationWindowsSecretKeys {
+			if v, ok := m[k].(string); ok && v != "" {
+				m[k] = ""
+				scrubbed = true
+			}
+		}
+	}
+	if !scrubbed {
+		return nil
+	}
+	return d.Set("clone", clone)
+}
+
 // ValidateCustomizationSpec checks the validity of the supplied customization
 // spec. It should be called during diff customization to veto invalid configs.
 func ValidateCustomizationSpec(d *schema.ResourceDiff, family string) error {
@@ -0,0 +1,106 @@
+package vmworkflow
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var ovfDeployDiskProvisioningAllowedValues = []string{
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin),
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThick),
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick),
+}
+
+var ovfDeployIPAllocationPolicyAllowedValues = []string{
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyDhcpPolicy),
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyTransientPolicy),
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedPolicy),
+	string(types.VAppIPAssignmentInfoIpAllocationPolicyFixedAllocatedPolicy),
+}
+
+var ovfDeployIPProtocolAllowedValues = []string{
+	string(types.VAppIPAssignmentInfoProtocolsIPv4),
+	string(types.VAppIPAssignmentInfoProtocolsIPv6),
+}
+
+// VirtualMachineOvfDeploySchema represents the schema for the VM OVF deploy
+// sub-resource.
+//
+// This is a workflow for vsphere_virtual_machine that facilitates the creation
+// of a virtual machine by importing an OVF descriptor or OVA archive from a
+// local file or a remote URL. The values of the OVF properties are taken from
+// the vapp sub-resource.
+//
+// Like the clone workflow, this is only consulted when the virtual machine is
+// created, and changes to it after that are ignored in CustomizeDiff.
+func VirtualMachineOvfDeploySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"local_ovf_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The path to a local OVF descriptor or OVA archive. The files that an OVF descriptor references are read from the same directory.",
+		},
+		"remote_ovf_url": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The URL of a remote OVF descriptor or OVA archive. The files that an OVF descriptor references are fetched relative to its URL.",
+		},
+		"allow_unverified_ssl_cert": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Allow unverified SSL certificates when fetching remote_ovf_url.",
+		},
+		"ovf_network_map": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Description: "The networks to connect the networks of the OVF to, keyed by the name of the network in the OVF. The values are network IDs.",
+		},
+		"disk_provisioning": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The provisioning type of the disks of the virtual machine. Can be one of thin, thick, or eagerZeroedThick. Defaults to the provisioning type in the OVF.",
+			ValidateFunc: validation.StringInSlice(ovfDeployDiskProvisioningAllowedValues, false),
+		},
+		"deployment_option": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The key of the deployment option of the OVF to use, such as a size profile. Defaults to the default option of the OVF.",
+		},
+		"ip_allocation_policy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The IP allocation policy of the OVF. Can be one of dhcpPolicy, transientPolicy, fixedPolicy, or fixedAllocatedPolicy.",
+			ValidateFunc: validation.StringInSlice(ovfDeployIPAllocationPolicyAllowedValues, false),
+		},
+		"ip_protocol": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "The IP protocol of the OVF. Can be one of IPv4 or IPv6.",
+			ValidateFunc: validation.StringInSlice(ovfDeployIPProtocolAllowedValues, false),
+		},
+		"timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      30,
+			Description:  "The timeout, in minutes, to wait for the OVF to be imported, including the upload of its disks.",
+			ValidateFunc: validation.IntAtLeast(10),
+		},
+	}
+}
+
+// ValidateVirtualMachineOvfDeploy does pre-creation validation of the OVF
+// deploy workflow of a virtual machine.
+func ValidateVirtualMachineOvfDeploy(d *schema.ResourceDiff) error {
+	local := d.Get("ovf_deploy.0.local_ovf_path").(string)
+	remote := d.Get("ovf_deploy.0.remote_ovf_url").(string)
+	switch {
+	case local == "" && remote == "":
+		return fmt.Errorf("one of ovf_deploy.0.local_ovf_path or ovf_deploy.0.remote_ovf_url must be set")
+	case local != "" && remote != "":
+		return fmt.Errorf("ovf_deploy.0.local_ovf_path and ovf_deploy.0.remote_ovf_url cannot be used together")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package vsphere
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// entityNameReplacement is the character that sanitize_names puts in place
+// of the characters that cannot be used in names.
+const entityNameReplacement = '-'
+
+// entityNameInvalidCharacters are the characters that vSphere escapes in
+// inventory names. A name with one of these characters is read back in its
+// escaped form, such as %2f for a slash, which never matches configuration.
+const entityNameInvalidCharacters = "%/\\"
+
+// namePrefixSuffixLength is the length of the unique suffix that is added to
+// name_prefix to generate a name.
+const namePrefixSuffixLength = 26
+
+// entityNameRule describes the limits on the names of one kind of vSphere
+// object.
+type entityNameRule struct {
+	// The kind of object, used in error messages.
+	kind string
+
+	// The maximum length of the name, in characters.
+	maxLength int
+
+	// Whether the length of the name is checked by the ValidateFunc of the name
+	// attribute. Only the characters of the name are checked and sanitized by
+	// the rule then, so that a name that is too long always fails validation.
+	lengthInSchema bool
+}
+
+// The following are the rules for the names of the objects that are checked
+// at plan time.
+var (
+	virtualMachineNameRule       = entityNameRule{kind: "virtual machine", maxLength: 80, lengthInSchema: true}
+	distributedPortGroupNameRule = entityNameRule{kind: "distributed port group", maxLength: 80}
+	hostPortGroupNameRule        = entityNameRule{kind: "port group", maxLength: 59}
+	datastoreNameRule            = entityNameRule{kind: "datastore", maxLength: 42}
+	folderNameRule               = entityNameRule{kind: "folder", maxLength: 80}
+)
+
+// validate checks a name against the rule, and returns an error that
+// describes the first problem found.
+func (r entityNameRule) validate(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("%s name cannot be empty", r.kind)
+	case utf8.RuneCountInString(name) > r.maxLength:
+		return fmt.Errorf("%s name %q is %d characters long, the maximum is %d", r.kind, name, utf8.RuneCountInString(name), r.maxLength)
+	}
+	return r.validateCharacters(name)
+}
+
+// validateCharacters checks the characters of a name against the rule, and
+// returns an error that describes the first problem found.
+func (r entityNameRule) validateCharacters(name string) error {
+	switch {
+	case strings.TrimSpace(name) != name:
+		return fmt.Errorf("%s name %q cannot start or end with whitespace", r.kind, name)
+	case strings.ContainsAny(name, entityNameInvalidCharacters):
+		return fmt.Errorf("%s name %q cannot contain any of %q", r.kind, name, entityNameInvalidCharacters)
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return fmt.Errorf("%s name %q cannot contain control characters", r.kind, name)
+	}
+	return nil
+}
+
+// sanitize returns a name that passes validate: invalid and control
+// characters are replaced, whitespace is trimmed, and the name is truncated
+// to the maximum length, unless the length is checked in the schema. An empty
+// name is returned as is.
+func (r entityNameRule) sanitize(name string) string {
+	name = strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) || strings.ContainsRune(entityNameInvalidCharacters, c) {
+			return entityNameReplacement
+		}
+		return c
+	}, name)
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); !r.lengthInSchema && len(runes) > r.maxLength {
+		name = strings.TrimSpace(string(runes[:r.maxLength]))
+	}
+	return name
+}
+
+// diffSuppressFunc returns a DiffSuppressFunc for the name attribute that
+// suppresses the diff between a name in configuration and its sanitized form
+// in state, so that names that were sanitized by sanitize_names do not show
+// a diff on every plan.
+func (r entityNameRule) diffSuppressFunc() schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		return old != "" && old != new && old == r.sanitize(new)
+	}
+}
+
+// customizeDiff checks the name attribute against the rule at plan time. No
+// error is returned when the provider sanitizes names, as the name is
+// sanitized during the apply instead.
+func (r entityNameRule) customizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// Names that are not known yet read as empty, and are checked during the
+	// apply instead.
+	name, ok := d.GetOk("name")
+	if meta.(*VSphereClient).sanitizeNames || !ok || !d.HasChange("name") {
+		return nil
+	}
+	validate := r.validate
+	if r.lengthInSchema {
+		validate = r.validateCharacters
+	}
+	if err := validate(name.(string)); err != nil {
+		return fmt.Errorf("%s. Change the name, or set sanitize_names in the provider configuration to fix it automatically", err)
+	}
+	return nil
+}
+
+// apply replaces the name attribute with its sanitized form when the provider
+// sanitizes names. It is called at the start of Create and Update, before the
+// name is sent to vSphere.
+func (r entityNameRule) apply(d *schema.ResourceData, meta interface{}) error {
+	if !meta.(*VSphereClient).sanitizeNames {
+		return nil
+	}
+	name := d.Get("name").(string)
+	if sanitized := r.sanitize(name); sanitized != name {
+		if sanitized == "" {
+			return fmt.Errorf("%s name %q cannot be sanitized", r.kind, name)
+		}
+		return d.Set("name", sanitized)
+	}
+	return nil
+}
+
+// validatePrefix checks that the names generated from a name_prefix pass the
+// rule.
+func (r entityNameRule) validatePrefix(prefix string) error {
+	if strings.TrimLeftFunc(prefix, unicode.IsSpace) != prefix {
+		return fmt.Errorf("%s name prefix %q cannot start with whitespace", r.kind, prefix)
+	}
+	if n := utf8.RuneCountInString(prefix); n+namePrefixSuffixLength > r.maxLength {
+		return fmt.Errorf("%s name prefix %q is %d characters long, the maximum is %d", r.kind, prefix, n, r.maxLength-namePrefixSuffixLength)
+	}
+	// The suffix never has whitespace or invalid characters, so a single
+	// character stands in for it when checking the rest of the rule.
+	return r.validate(prefix + "0")
+}
+
+// prefixValidateFunc returns a ValidateFunc for name_prefix that checks the
+// prefix with validatePrefix.
+func (r entityNameRule) prefixValidateFunc() schema.SchemaValidateFunc {
+	return func(v interface{}, k string) ([]string, []error) {
+		if err := r.validatePrefix(v.(string)); err != nil {
+			return nil, []error{fmt.Errorf("%s: %s", k, err)}
+		}
+		return nil, nil
+	}
+}
+
+// setNameFromPrefix sets the attribute named by key to a unique name that
+// starts with name_prefix, if the attribute is not already set. The names
+// are unique even when several resources are created in parallel.
+func setNameFromPrefix(d *schema.ResourceData, key string) error {
+	if _, ok := d.GetOk(key); ok {
+		return nil
+	}
+	prefix, ok := d.GetOk("name_prefix")
+	if !ok {
+		return fmt.Errorf("one of %s or name_prefix must be set", key)
+	}
+	return d.Set(key, resource.PrefixedUniqueId(prefix.(string)))
+}
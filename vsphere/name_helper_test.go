@@ -0,0 +1,172 @@
+package vsphere
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestEntityNameRuleValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected bool
+	}{
+		{"vm-01", true},
+		{strings.Repeat("a", 42), true},
+		{strings.Repeat("a", 43), false},
+		{strings.Repeat("é", 42), true},
+		{"", false},
+		{"  ", false},
+		{" vm", false},
+		{"vm ", false},
+		{"a/b", false},
+		{"a\\b", false},
+		{"100%", false},
+		{"a\tb", false},
+	}
+	for _, tc := range cases {
+		err := datastoreNameRule.validate(tc.name)
+		if tc.expected && err != nil {
+			t.Fatalf("%q: expected no error, got %s", tc.name, err)
+		}
+		if !tc.expected && err == nil {
+			t.Fatalf("%q: expected error, got none", tc.name)
+		}
+	}
+}
+
+func TestEntityNameRuleSanitize(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"vm-01", "vm-01"},
+		{" a/b\\c%d ", "a-b-c-d"},
+		{"a\nb", "a-b"},
+		{strings.Repeat("a", 41) + " b", strings.Repeat("a", 41)},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		actual := datastoreNameRule.sanitize(tc.name)
+		if actual != tc.expected {
+			t.Fatalf("%q: expected %q, got %q", tc.name, tc.expected, actual)
+		}
+		if actual != "" {
+			if err := datastoreNameRule.validate(actual); err != nil {
+				t.Fatalf("%q: expected sanitized name to be valid, got %s", tc.name, err)
+			}
+		}
+	}
+}
+
+func TestVirtualMachineNameRuleSanitize(t *testing.T) {
+	// Virtual machine names are limited in the schema, so only their characters
+	// are sanitized.
+	name := strings.Repeat("a", 80) + "/b"
+	expected := strings.Repeat("a", 80) + "-b"
+	if actual := virtualMachineNameRule.sanitize(name); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestVirtualMachineNameRuleCustomizeDiff(t *testing.T) {
+	r := resourceVSphereVirtualMachine()
+	r.CustomizeDiff = virtualMachineNameRule.customizeDiff
+	cases := []struct {
+		name          string
+		id            string
+		old           string
+		new           string
+		sanitizeNames bool
+		expectedErr   string
+		expectedValid bool
+	}{
+		{"valid name", "", "", "vm-01", false, "", true},
+		{"invalid character", "", "", "web/01", false, "cannot contain", true},
+		{"invalid character, sanitize_names set", "", "", "web/01", true, "", true},
+		{"leading whitespace", "", "", " vm-01", false, "cannot start or end with whitespace", true},
+		{"control character", "", "", "vm\t01", false, "cannot contain control characters", true},
+		{"unchanged invalid name", "uuid", "web/01", "web/01", false, "", true},
+		{"renamed to invalid name", "uuid", "vm-01", "web/01", false, "cannot contain", true},
+		// The length is left to the ValidateFunc of the attribute, which fails
+		// even when sanitize_names is set.
+		{"too long", "", "", strings.Repeat("a", 81), false, "", false},
+		{"too long, sanitize_names set", "", "", strings.Repeat("a", 81), true, "", false},
+	}
+	for _, tc := range cases {
+		var attrs map[string]string
+		if tc.id != "" {
+			attrs = map[string]string{"name": tc.old}
+		}
+		raw := map[string]interface{}{"name": tc.new}
+		_, err := testResourceDiff(r, tc.id, attrs, raw, &VSphereClient{sanitizeNames: tc.sanitizeNames})
+		switch {
+		case tc.expectedErr == "" && err != nil:
+			t.Fatalf("%q: expected no error, got %s", tc.name, err)
+		case tc.expectedErr != "" && err == nil:
+			t.Fatalf("%q: expected error %q, got none", tc.name, tc.expectedErr)
+		case tc.expectedErr != "" && !strings.Contains(err.Error(), tc.expectedErr):
+			t.Fatalf("%q: expected error %q, got %s", tc.name, tc.expectedErr, err)
+		}
+		_, errs := r.Schema["name"].ValidateFunc(tc.new, "name")
+		if valid := len(errs) == 0; valid != tc.expectedValid {
+			t.Fatalf("%q: expected name to be valid in schema to be %t, got %t", tc.name, tc.expectedValid, valid)
+		}
+	}
+}
+
+func TestEntityNameRuleValidatePrefix(t *testing.T) {
+	cases := []struct {
+		prefix   string
+		expected bool
+	}{
+		{"web-", true},
+		{"web ", true},
+		{strings.Repeat("a", 16), true},
+		{strings.Repeat("a", 17), false},
+		{" web", false},
+		{"web/", false},
+	}
+	for _, tc := range cases {
+		err := datastoreNameRule.validatePrefix(tc.prefix)
+		if tc.expected && err != nil {
+			t.Fatalf("%q: expected no error, got %s", tc.prefix, err)
+		}
+		if !tc.expected && err == nil {
+			t.Fatalf("%q: expected error, got none", tc.prefix)
+		}
+	}
+}
+
+func TestSetNameFromPrefix(t *testing.T) {
+	s := resourceVSphereFolder().Schema
+	names := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		d := schema.TestResourceDataRaw(t, s, map[string]interface{}{"name_prefix": "web/fleet-"})
+		if err := setNameFromPrefix(d, "path"); err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		name := d.Get("path").(string)
+		if !strings.HasPrefix(name, "web/fleet-") || len(name) != len("web/fleet-")+namePrefixSuffixLength {
+			t.Fatalf("expected a name with prefix web/fleet- and a %d character suffix, got %q", namePrefixSuffixLength, name)
+		}
+		if names[name] {
+			t.Fatalf("expected unique names, got %q twice", name)
+		}
+		names[name] = true
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{"path": "web"})
+	if err := setNameFromPrefix(d, "path"); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if actual := d.Get("path").(string); actual != "web" {
+		t.Fatalf("expected path to be kept, got %q", actual)
+	}
+
+	d = schema.TestResourceDataRaw(t, s, map[string]interface{}{})
+	if err := setNameFromPrefix(d, "path"); err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
@@ -0,0 +1,104 @@
+package vsphere
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/vic/pkg/vsphere/tags"
+)
+
+// operationIDRoundTripper is a soap.RoundTripper that stamps every SOAP call
+// with an operation ID in the form PREFIX-N, where PREFIX is the operation ID
+// of the Terraform run and N is a counter. vCenter records the operation ID
+// as the opID of the call in its logs, so that the calls of a Terraform run
+// can be found with the prefix.
+type operationIDRoundTripper struct {
+	rt     soap.RoundTripper
+	prefix string
+	n      uint64
+}
+
+// RoundTrip implements soap.RoundTripper for operationIDRoundTripper. Calls
+// that already have an operation ID in their context keep it.
+func (o *operationIDRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if _, ok := ctx.Value(types.ID{}).(string); !ok {
+		ctx = context.WithValue(ctx, types.ID{}, o.next())
+	}
+	return o.rt.RoundTrip(ctx, req, res)
+}
+
+// next returns the next operation ID.
+func (o *operationIDRoundTripper) next() string {
+	return fmt.Sprintf("%s-%d", o.prefix, atomic.AddUint64(&o.n, 1))
+}
+
+// userAgentTransport is a http.RoundTripper that sets the User-Agent header
+// of the requests of the CIS REST client.
+type userAgentTransport struct {
+	rt        http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip implements http.RoundTripper for userAgentTransport.
+func (u *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it is given.
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set("User-Agent", u.userAgent)
+	return u.rt.RoundTrip(r)
+}
+
+// newOperationID returns a random operation ID for a Terraform run.
+func newOperationID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "terraform-" + hex.EncodeToString(b), nil
+}
+
+// operationIDContext returns a copy of ctx that stamps the SOAP calls made
+// with it with the operation ID id. It is used for the calls that are made
+// before the SOAP client is wrapped with enableOperationIDs, like the login.
+func operationIDContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, types.ID{}, id)
+}
+
+// enableOperationIDs wraps the SOAP client of a VSphereClient so that its
+// calls are stamped with operation IDs prefixed with id, and sets the
+// User-Agent of the SOAP and REST clients to userAgent if it is not empty.
+func enableOperationIDs(client *VSphereClient, id, userAgent string) {
+	log.Printf("[INFO] vSphere operation ID prefix for this run: %s", id)
+	client.vimClient.Client.RoundTripper = &operationIDRoundTripper{
+		rt:     client.vimClient.Client.RoundTripper,
+		prefix: id,
+	}
+	if userAgent == "" {
+		return
+	}
+	client.vimClient.Client.Client.UserAgent = userAgent
+	if client.tagsClient != nil {
+		enableUserAgentREST(client.tagsClient, userAgent)
+	}
+}
+
+// enableUserAgentREST wraps the HTTP transport of a CIS REST client in a
+// userAgentTransport.
+func enableUserAgentREST(client *tags.RestClient, userAgent string) {
+	rt := client.HTTP.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	client.HTTP.Transport = &userAgentTransport{rt: rt, userAgent: userAgent}
+}
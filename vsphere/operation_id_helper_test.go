@@ -0,0 +1,80 @@
+package vsphere
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type operationIDRecorder struct {
+	ids []string
+}
+
+func (r *operationIDRecorder) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	id, _ := ctx.Value(types.ID{}).(string)
+	r.ids = append(r.ids, id)
+	return nil
+}
+
+func TestOperationIDRoundTripper(t *testing.T) {
+	rec := new(operationIDRecorder)
+	rt := &operationIDRoundTripper{rt: rec, prefix: "run"}
+	ctx := context.Background()
+	rt.RoundTrip(ctx, nil, nil)
+	rt.RoundTrip(ctx, nil, nil)
+	rt.RoundTrip(operationIDContext(ctx, "explicit"), nil, nil)
+	expected := []string{"run-1", "run-2", "explicit"}
+	for i, id := range expected {
+		if rec.ids[i] != id {
+			t.Fatalf("call %d: expected operation ID %q, got %q", i, id, rec.ids[i])
+		}
+	}
+}
+
+func TestNewOperationID(t *testing.T) {
+	a, err := newOperationID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newOperationID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(a, "terraform-") {
+		t.Fatalf("expected operation ID to start with terraform-, got %q", a)
+	}
+	if a == b {
+		t.Fatalf("expected different operation IDs, got %q twice", a)
+	}
+}
+
+type userAgentRecorder struct {
+	userAgent string
+}
+
+func (r *userAgentRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.userAgent = req.Header.Get("User-Agent")
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestUserAgentTransport(t *testing.T) {
+	rec := new(userAgentRecorder)
+	rt := &userAgentTransport{rt: rec, userAgent: "ci-job/42"}
+	req, err := http.NewRequest(http.MethodGet, "https://vc/rest/com/vmware/cis/tagging/tag", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if rec.userAgent != "ci-job/42" {
+		t.Fatalf("expected User-Agent to be ci-job/42, got %q", rec.userAgent)
+	}
+	if req.Header.Get("User-Agent") != "" {
+		t.Fatalf("expected original request to be unchanged, got User-Agent %q", req.Header.Get("User-Agent"))
+	}
+}
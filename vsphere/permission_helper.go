@@ -0,0 +1,130 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// permissionRoles returns all of the roles defined in vCenter.
+func permissionRoles(client *govmomi.Client) (object.AuthorizationRoleList, error) {
+	am := object.NewAuthorizationManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	roles, err := am.RoleList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching roles: %s", err)
+	}
+	return roles, nil
+}
+
+// permissionRoleByName returns the role with the supplied name.
+func permissionRoleByName(client *govmomi.Client, name string) (*types.AuthorizationRole, error) {
+	am := object.NewAuthorizationManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	roles, err := am.RoleList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching roles: %s", err)
+	}
+	role := roles.ByName(name)
+	if role == nil {
+		return nil, fmt.Errorf("role %q not found", name)
+	}
+	return role, nil
+}
+
+// permissionRoleByID returns the role with the supplied ID.
+func permissionRoleByID(client *govmomi.Client, id int32) (*types.AuthorizationRole, error) {
+	am := object.NewAuthorizationManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	roles, err := am.RoleList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching roles: %s", err)
+	}
+	role := roles.ById(id)
+	if role == nil {
+		return nil, fmt.Errorf("role ID %d not found", id)
+	}
+	return role, nil
+}
+
+// entityPermission returns the permission for a user or group that is defined
+// directly on an entity, or nil if there is none. A user and a group can have
+// the same name, so both the principal and whether or not it is a group are
+// matched. Permissions that the entity inherits from its parents are not
+// returned, so that a propagating permission on a folder or datacenter is not
+// mistaken for a permission on each of the objects below it.
+func entityPermission(client *govmomi.Client, ref types.ManagedObjectReference, principal string, group bool) (*types.Permission, error) {
+	perms, err := entityPermissions(client, ref)
+	if err != nil {
+		return nil, err
+	}
+	for _, perm := range perms {
+		if perm.Principal == principal && perm.Group == group {
+			return &perm, nil
+		}
+	}
+	return nil, nil
+}
+
+// entityPermissions returns all of the permissions that are defined directly
+// on an entity. Like entityPermission, inherited permissions are skipped.
+func entityPermissions(client *govmomi.Client, ref types.ManagedObjectReference) ([]types.Permission, error) {
+	am := object.NewAuthorizationManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	perms, err := am.RetrieveEntityPermissions(ctx, ref, false)
+	if err != nil {
+		return nil, err
+	}
+	var result []types.Permission
+	for _, perm := range perms {
+		if perm.Entity != nil && *perm.Entity != ref {
+			continue
+		}
+		result = append(result, perm)
+	}
+	return result, nil
+}
+
+// setEntityPermission creates or replaces the permission for a principal on
+// an entity.
+func setEntityPermission(client *govmomi.Client, ref types.ManagedObjectReference, perm types.Permission) error {
+	log.Printf("[DEBUG] Setting role ID %d for %q on %s (propagate: %t)", perm.RoleId, perm.Principal, ref, perm.Propagate)
+	am := object.NewAuthorizationManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return am.SetEntityPermissions(ctx, ref, []types.Permission{perm})
+}
+
+// setEntityPermissions creates or replaces the permissions for several
+// principals on an entity in a single call. Permissions for principals that
+// are not in perms are left alone.
+func setEntityPermissions(client *govmomi.Client, ref types.ManagedObjectReference, perms []types.Permission) error {
+	log.Printf("[DEBUG] Setting %d permissions on %s", len(perms), ref)
+	am := object.NewAuthorizationManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return am.SetEntityPermissions(ctx, ref, perms)
+}
+
+// removeEntityPermission removes the permission for a principal from an
+// entity. It is not an error if the permission does not exist.
+func removeEntityPermission(client *govmomi.Client, ref types.ManagedObjectReference, principal string, group bool) error {
+	log.Printf("[DEBUG] Removing permission for %q from %s", principal, ref)
+	am := object.NewAuthorizationManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	err := am.RemoveEntityPermission(ctx, ref, principal, group)
+	if err != nil && viapi.IsAnyNotFoundError(err) {
+		return nil
+	}
+	return err
+}
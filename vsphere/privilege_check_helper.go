@@ -0,0 +1,76 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// privilegeRequirement is a set of privileges that a planned operation needs
+// on a single managed object.
+type privilegeRequirement struct {
+	// The managed object that the privileges are needed on.
+	Ref types.ManagedObjectReference
+
+	// A description of the object for error messages, like
+	// "resource pool resgroup-10".
+	Description string
+
+	// The IDs of the needed privileges, like "Datastore.AllocateSpace".
+	Privileges []string
+}
+
+// checkPrivileges checks that the current session holds all of the privileges
+// in reqs, and returns an error listing every missing privilege if it does
+// not. Requirements for the same object are merged.
+func checkPrivileges(client *govmomi.Client, reqs []privilegeRequirement) error {
+	var order []string
+	merged := make(map[string]*privilegeRequirement)
+	for _, req := range reqs {
+		key := req.Ref.String()
+		m, ok := merged[key]
+		if !ok {
+			m = &privilegeRequirement{Ref: req.Ref, Description: req.Description}
+			merged[key] = m
+			order = append(order, key)
+		}
+		for _, p := range req.Privileges {
+			if !privilegeListContains(m.Privileges, p) {
+				m.Privileges = append(m.Privileges, p)
+			}
+		}
+	}
+
+	var lines []string
+	for _, key := range order {
+		req := merged[key]
+		log.Printf("[DEBUG] Checking privileges %s on %s", strings.Join(req.Privileges, ", "), req.Description)
+		missing, err := viapi.MissingPrivileges(client, req.Ref, req.Privileges)
+		if err != nil {
+			return fmt.Errorf("error checking privileges on %s: %s", req.Description, err)
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			lines = append(lines, fmt.Sprintf("  %s: %s", req.Description, strings.Join(missing, ", ")))
+		}
+	}
+	if len(lines) > 0 {
+		return fmt.Errorf("the vSphere user is missing privileges needed for this operation:\n\n%s", strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// privilegeListContains returns true if the privilege p is in the list l.
+func privilegeListContains(l []string, p string) bool {
+	for _, v := range l {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
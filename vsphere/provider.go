@@ -15,7 +15,7 @@ const defaultAPITimeout = time.Minute * 5
 
 // Provider returns a terraform.ResourceProvider.
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"user": &schema.Schema{
 				Type:        schema.TypeString,
@@ -67,6 +67,12 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_DEBUG_PATH", ""),
 				Description: "govomomi debug path for debug",
 			},
+			"debug_soap": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_DEBUG_SOAP", false),
+				Description: "Record sanitized SOAP and REST API calls in govmomi debug format",
+			},
 			"persist_session": &schema.Schema{
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -85,51 +91,177 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_REST_SESSION_PATH", filepath.Join(os.Getenv("HOME"), ".govmomi", "rest_sessions")),
 				Description: "The directory to save vSphere REST API sessions to",
 			},
+			"preflight_privilege_check": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_PREFLIGHT_PRIVILEGE_CHECK", false),
+				Description: "Check that the user holds the privileges needed for planned operations at plan time.",
+			},
+			"sanitize_names": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SANITIZE_NAMES", false),
+				Description: "Fix the names of virtual machines, port groups, and datastores that vSphere does not accept, instead of failing at plan time.",
+			},
+			"vmc_mode": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_VMC_MODE", false),
+				Description: "Adapt to the restrictions of VMware Cloud on AWS, and fail early on operations that it does not permit.",
+			},
+			"simulator_mode": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SIMULATOR_MODE", false),
+				Description: "Connect to a vCenter simulator (vcsim), which only serves the SOAP API, so that configurations can be validated without a lab vCenter.",
+			},
+			"user_agent": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_USER_AGENT", ""),
+				Description: "The User-Agent of the API calls made by the provider.",
+			},
+			"operation_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_OPERATION_ID", ""),
+				Description: "The prefix of the operation IDs that the SOAP calls made by the provider are stamped with. A random prefix is generated when not set.",
+			},
+			"https_proxy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_HTTPS_PROXY", ""),
+				Description: "The URL of the proxy to use for the API calls made by the provider, instead of the proxy in the HTTPS_PROXY environment variable.",
+			},
+			"no_proxy": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_NO_PROXY", ""),
+				Description: "A comma-separated list of host names, domains, IP addresses, and CIDR blocks that are reached without https_proxy.",
+			},
+			"ca_bundle_file": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CA_BUNDLE_FILE", ""),
+				Description: "The path to a file of PEM-encoded CA certificates to trust in addition to the system ones.",
+			},
+			"ca_bundle": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CA_BUNDLE", ""),
+				Description: "PEM-encoded CA certificates to trust in addition to the system ones.",
+			},
+			"audit_mode": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_AUDIT_MODE", false),
+				Description: "Only make read-only vSphere API calls, and fail any call that would make a change with an error describing it.",
+			},
+			"soft_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SOFT_DESTROY", false),
+				Description: "Power off virtual machines and move them to soft_destroy_folder on destroy, instead of deleting them.",
+			},
+			"soft_destroy_folder": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SOFT_DESTROY_FOLDER", "terraform-quarantine"),
+				Description: "The path of the VM folder, relative to the datacenter of each virtual machine, to move soft-destroyed virtual machines to.",
+			},
+			"soft_destroy_ttl": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SOFT_DESTROY_TTL", 30),
+				Description: "The number of days that soft-destroyed virtual machines are to be kept for, recorded in the expiry tag attached to them.",
+			},
+			"soft_destroy_tag_category": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_SOFT_DESTROY_TAG_CATEGORY", "terraform-soft-destroy"),
+				Description: "The name of the tag category for the expiry tags attached to soft-destroyed virtual machines. Created if it does not exist.",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"vsphere_custom_attribute":           resourceVSphereCustomAttribute(),
-			"vsphere_datacenter":                 resourceVSphereDatacenter(),
-			"vsphere_datastore_cluster":          resourceVSphereDatastoreCluster(),
-			"vsphere_distributed_port_group":     resourceVSphereDistributedPortGroup(),
-			"vsphere_distributed_virtual_switch": resourceVSphereDistributedVirtualSwitch(),
-			"vsphere_file":                       resourceVSphereFile(),
-			"vsphere_folder":                     resourceVSphereFolder(),
-			"vsphere_host_port_group":            resourceVSphereHostPortGroup(),
-			"vsphere_host_virtual_switch":        resourceVSphereHostVirtualSwitch(),
-			"vsphere_license":                    resourceVSphereLicense(),
-			"vsphere_tag":                        resourceVSphereTag(),
-			"vsphere_tag_category":               resourceVSphereTagCategory(),
-			"vsphere_virtual_disk":               resourceVSphereVirtualDisk(),
-			"vsphere_virtual_machine":            resourceVSphereVirtualMachine(),
-			"vsphere_nas_datastore":              resourceVSphereNasDatastore(),
-			"vsphere_vmfs_datastore":             resourceVSphereVmfsDatastore(),
-			"vsphere_virtual_machine_snapshot":   resourceVSphereVirtualMachineSnapshot(),
+			"vsphere_custom_attribute":                        resourceVSphereCustomAttribute(),
+			"vsphere_datacenter":                              resourceVSphereDatacenter(),
+			"vsphere_datastore_cluster":                       resourceVSphereDatastoreCluster(),
+			"vsphere_datastore_cluster_membership":            resourceVSphereDatastoreClusterMembership(),
+			"vsphere_datastore_cluster_sdrs_schedule":         resourceVSphereDatastoreClusterSDRSSchedule(),
+			"vsphere_datastore_cluster_vm_anti_affinity_rule": resourceVSphereDatastoreClusterVMAntiAffinityRule(),
+			"vsphere_datastore_host_mount":                    resourceVSphereDatastoreHostMount(),
+			"vsphere_distributed_port_group":                  resourceVSphereDistributedPortGroup(),
+			"vsphere_distributed_virtual_switch":              resourceVSphereDistributedVirtualSwitch(),
+			"vsphere_entity_permission":                       resourceVSphereEntityPermission(),
+			"vsphere_entity_permissions":                      resourceVSphereEntityPermissions(),
+			"vsphere_extension":                               resourceVSphereExtension(),
+			"vsphere_file":                                    resourceVSphereFile(),
+			"vsphere_folder":                                  resourceVSphereFolder(),
+			"vsphere_host_graphics":                           resourceVSphereHostGraphics(),
+			"vsphere_host_local_permission":                   resourceVSphereHostLocalPermission(),
+			"vsphere_host_local_user":                         resourceVSphereHostLocalUser(),
+			"vsphere_host_lockdown":                           resourceVSphereHostLockdown(),
+			"vsphere_host_power_state":                        resourceVSphereHostPowerState(),
+			"vsphere_host_pci_passthrough":                    resourceVSphereHostPciPassthrough(),
+			"vsphere_host_port_group":                         resourceVSphereHostPortGroup(),
+			"vsphere_host_storage_rescan":                     resourceVSphereHostStorageRescan(),
+			"vsphere_host_virtual_switch":                     resourceVSphereHostVirtualSwitch(),
+			"vsphere_license":                                 resourceVSphereLicense(),
+			"vsphere_tag":                                     resourceVSphereTag(),
+			"vsphere_tag_category":                            resourceVSphereTagCategory(),
+			"vsphere_virtual_disk":                            resourceVSphereVirtualDisk(),
+			"vsphere_compute_policy":                          resourceVSphereComputePolicy(),
+			"vsphere_content_library":                         resourceVSphereContentLibrary(),
+			"vsphere_content_library_item":                    resourceVSphereContentLibraryItem(),
+			"vsphere_content_library_item_sync":               resourceVSphereContentLibraryItemSync(),
+			"vsphere_cloud_init_iso":                          resourceVSphereCloudInitISO(),
+			"vsphere_virtual_machine":                         resourceVSphereVirtualMachine(),
+			"vsphere_virtual_machine_group":                   resourceVSphereVirtualMachineGroup(),
+			"vsphere_nas_datastore":                           resourceVSphereNasDatastore(),
+			"vsphere_vmfs_datastore":                          resourceVSphereVmfsDatastore(),
+			"vsphere_virtual_machine_snapshot":                resourceVSphereVirtualMachineSnapshot(),
+			"vsphere_virtual_machine_registration":            resourceVSphereVirtualMachineRegistration(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"vsphere_custom_attribute":           dataSourceVSphereCustomAttribute(),
-			"vsphere_datacenter":                 dataSourceVSphereDatacenter(),
-			"vsphere_datastore":                  dataSourceVSphereDatastore(),
-			"vsphere_datastore_cluster":          dataSourceVSphereDatastoreCluster(),
-			"vsphere_distributed_virtual_switch": dataSourceVSphereDistributedVirtualSwitch(),
-			"vsphere_host":                       dataSourceVSphereHost(),
-			"vsphere_network":                    dataSourceVSphereNetwork(),
-			"vsphere_resource_pool":              dataSourceVSphereResourcePool(),
-			"vsphere_tag":                        dataSourceVSphereTag(),
-			"vsphere_tag_category":               dataSourceVSphereTagCategory(),
-			"vsphere_virtual_machine":            dataSourceVSphereVirtualMachine(),
-			"vsphere_vmfs_disks":                 dataSourceVSphereVmfsDisks(),
+			"vsphere_custom_attribute":            dataSourceVSphereCustomAttribute(),
+			"vsphere_content_library":             dataSourceVSphereContentLibrary(),
+			"vsphere_content_library_item":        dataSourceVSphereContentLibraryItem(),
+			"vsphere_datacenter":                  dataSourceVSphereDatacenter(),
+			"vsphere_datastore":                   dataSourceVSphereDatastore(),
+			"vsphere_datastore_cluster":           dataSourceVSphereDatastoreCluster(),
+			"vsphere_datastore_first_class_disks": dataSourceVSphereDatastoreFirstClassDisks(),
+			"vsphere_datastore_stats":             dataSourceVSphereDatastoreStats(),
+			"vsphere_distributed_virtual_switch":  dataSourceVSphereDistributedVirtualSwitch(),
+			"vsphere_drs_vm_placement":            dataSourceVSphereDRSVMPlacement(),
+			"vsphere_events":                      dataSourceVSphereEvents(),
+			"vsphere_guest_os_ids":                dataSourceVSphereGuestOSIDs(),
+			"vsphere_host":                        dataSourceVSphereHost(),
+			"vsphere_host_datastores":             dataSourceVSphereHostDatastores(),
+			"vsphere_host_gpus":                   dataSourceVSphereHostGPUs(),
+			"vsphere_network":                     dataSourceVSphereNetwork(),
+			"vsphere_ovf_vm_template":             dataSourceVSphereOvfVMTemplate(),
+			"vsphere_resource_pool":               dataSourceVSphereResourcePool(),
+			"vsphere_tag":                         dataSourceVSphereTag(),
+			"vsphere_tag_category":                dataSourceVSphereTagCategory(),
+			"vsphere_virtual_machine":             dataSourceVSphereVirtualMachine(),
+			"vsphere_virtual_machine_console":     dataSourceVSphereVirtualMachineConsole(),
+			"vsphere_virtual_machine_migrations":  dataSourceVSphereVirtualMachineMigrations(),
+			"vsphere_vmfs_disks":                  dataSourceVSphereVmfsDisks(),
 		},
-
-		ConfigureFunc: providerConfigure,
 	}
+	p.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return providerConfigure(d, p)
+	}
+	return p
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+func providerConfigure(d *schema.ResourceData, p *schema.Provider) (interface{}, error) {
 	c, err := NewConfig(d)
 	if err != nil {
 		return nil, err
 	}
+	c.StopContext = p.StopContext()
 	return c.Client()
 }
@@ -62,5 +62,5 @@ func testAccPreCheck(t *testing.T) {
 func testAccProviderMeta(t *testing.T) (interface{}, error) {
 	t.Helper()
 	d := schema.TestResourceDataRaw(t, testAccProvider.Schema, make(map[string]interface{}))
-	return providerConfigure(d)
+	return providerConfigure(d, testAccProvider)
 }
@@ -0,0 +1,143 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/nocloud"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+)
+
+func resourceVSphereCloudInitISO() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereCloudInitISOCreate,
+		Read:   resourceVSphereCloudInitISORead,
+		Update: resourceVSphereCloudInitISOUpdate,
+		Delete: resourceVSphereCloudInitISODelete,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore to upload the ISO to.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Description: "The path of the ISO on the datastore, such as seeds/vm1.iso.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"create_directories": {
+				Type:        schema.TypeBool,
+				Description: "Create the missing directories of path.",
+				Optional:    true,
+			},
+			"user_data": {
+				Type:        schema.TypeString,
+				Description: "The content of the user-data file.",
+				Required:    true,
+			},
+			"meta_data": {
+				Type:        schema.TypeString,
+				Description: "The content of the meta-data file.",
+				Optional:    true,
+			},
+			"network_config": {
+				Type:        schema.TypeString,
+				Description: "The content of the network-config file. The file is left out if not set.",
+				Optional:    true,
+			},
+			"vendor_data": {
+				Type:        schema.TypeString,
+				Description: "The content of the vendor-data file. The file is left out if not set.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereCloudInitISOCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereCloudInitISOUpload(d, meta); err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s:%s", d.Get("datastore_id").(string), d.Get("path").(string)))
+	return resourceVSphereCloudInitISORead(d, meta)
+}
+
+func resourceVSphereCloudInitISORead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	dsID := d.Get("datastore_id").(string)
+	ds, err := datastore.FromID(client, dsID)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Datastore %q not found, removing cloud-init ISO %q from state", dsID, d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+	p := d.Get("path").(string)
+	exists, err := datastore.FileExists(ds, p)
+	if err != nil {
+		return fmt.Errorf("error searching for %q: %s", ds.Path(p), err)
+	}
+	if !exists {
+		log.Printf("[DEBUG] %q not found, removing cloud-init ISO from state", ds.Path(p))
+		d.SetId("")
+	}
+	return nil
+}
+
+func resourceVSphereCloudInitISOUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereCloudInitISOUpload(d, meta); err != nil {
+		return err
+	}
+	return resourceVSphereCloudInitISORead(d, meta)
+}
+
+func resourceVSphereCloudInitISODelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ds, err := datastore.FromID(client, d.Get("datastore_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+	p := d.Get("path").(string)
+	if err := datastore.DeleteFile(client, ds, p); err != nil {
+		return fmt.Errorf("error deleting %q: %s", ds.Path(p), err)
+	}
+	d.SetId("")
+	return nil
+}
+
+// resourceVSphereCloudInitISOUpload builds the seed ISO from the
+// configuration and uploads it, overwriting the ISO if it already exists.
+func resourceVSphereCloudInitISOUpload(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	files := map[string][]byte{
+		nocloud.UserDataFile: []byte(d.Get("user_data").(string)),
+		nocloud.MetaDataFile: []byte(d.Get("meta_data").(string)),
+	}
+	if v, ok := d.GetOk("network_config"); ok {
+		files[nocloud.NetworkConfigFile] = []byte(v.(string))
+	}
+	if v, ok := d.GetOk("vendor_data"); ok {
+		files[nocloud.VendorDataFile] = []byte(v.(string))
+	}
+	img, err := nocloud.SeedISO(files)
+	if err != nil {
+		return fmt.Errorf("error building cloud-init ISO: %s", err)
+	}
+
+	ds, err := datastore.FromID(client, d.Get("datastore_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+	p := d.Get("path").(string)
+	if err := datastore.UploadFile(client, ds, p, img, d.Get("create_directories").(bool)); err != nil {
+		return fmt.Errorf("error uploading cloud-init ISO to %q: %s", ds.Path(p), err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+)
+
+func TestAccResourceVSphereCloudInitISO_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereCloudInitISOPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereCloudInitISOExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereCloudInitISOConfig("first"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereCloudInitISOExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereCloudInitISOConfig("second"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereCloudInitISOExists(true),
+					resource.TestCheckResourceAttr("vsphere_cloud_init_iso.seed", "user_data", "#cloud-config\nhostname: second\n"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereCloudInitISOPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_cloud_init_iso acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_cloud_init_iso acceptance tests")
+	}
+}
+
+// testAccResourceVSphereCloudInitISOExists checks that the ISO of the test
+// resource exists, or does not exist, on the datastore.
+func testAccResourceVSphereCloudInitISOExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return err
+		}
+		ds, err := datastore.FromPath(client, os.Getenv("VSPHERE_DATASTORE"), dc)
+		if err != nil {
+			return err
+		}
+		exists, err := datastore.FileExists(ds, "terraform-test-cloud-init/seed.iso")
+		if err != nil {
+			return err
+		}
+		switch {
+		case exists && !expected:
+			return errors.New("expected cloud-init ISO to be missing")
+		case !exists && expected:
+			return errors.New("expected cloud-init ISO to exist")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereCloudInitISOConfig(hostname string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_cloud_init_iso" "seed" {
+  datastore_id       = "${data.vsphere_datastore.datastore.id}"
+  path               = "terraform-test-cloud-init/seed.iso"
+  create_directories = true
+  meta_data          = "instance-id: terraform-test\n"
+  user_data          = "#cloud-config\nhostname: %s\n"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		hostname,
+	)
+}
@@ -0,0 +1,129 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computepolicy"
+)
+
+func resourceVSphereComputePolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereComputePolicyCreate,
+		Read:   resourceVSphereComputePolicyRead,
+		Delete: resourceVSphereComputePolicyDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the compute policy.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the compute policy.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"capability": {
+				Type:         schema.TypeString,
+				Description:  "The capability of the compute policy. Can be one of vm_host_affinity, vm_host_anti_affinity, vm_vm_affinity, vm_vm_anti_affinity, or disable_drs_vmotion.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(computepolicy.Capabilities, false),
+			},
+			"vm_tag": {
+				Type:        schema.TypeString,
+				Description: "The ID of the tag that attaches the compute policy to virtual machines.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"host_tag": {
+				Type:        schema.TypeString,
+				Description: "The ID of the tag of the hosts that the compute policy applies to. Required for, and only allowed with, the vm_host_affinity and vm_host_anti_affinity capabilities.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereComputePolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	spec := computepolicy.Spec{
+		Capability:  d.Get("capability").(string),
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		VMTag:       d.Get("vm_tag").(string),
+		HostTag:     d.Get("host_tag").(string),
+	}
+	// Tag IDs usually come from vsphere_tag resources and are not known at plan
+	// time, so host_tag is checked here rather than in the diff.
+	if err := resourceVSphereComputePolicyValidateHostTag(spec.Capability, spec.HostTag); err != nil {
+		return err
+	}
+	id, err := computepolicy.Create(tagsClient, client.vimClient.URL(), spec)
+	if err != nil {
+		return fmt.Errorf("error creating compute policy %q: %s", spec.Name, err)
+	}
+	d.SetId(id)
+	return resourceVSphereComputePolicyRead(d, meta)
+}
+
+func resourceVSphereComputePolicyRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	policy, err := computepolicy.FromID(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil {
+		if err == computepolicy.ErrNotFound {
+			log.Printf("[DEBUG] Compute policy %q not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching compute policy %q: %s", d.Id(), err)
+	}
+	d.Set("name", policy.Name)
+	d.Set("description", policy.Description)
+	d.Set("capability", policy.Capability)
+	d.Set("vm_tag", policy.VMTag)
+	d.Set("host_tag", policy.HostTag)
+	return nil
+}
+
+func resourceVSphereComputePolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	err = computepolicy.Delete(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil && err != computepolicy.ErrNotFound {
+		return fmt.Errorf("error deleting compute policy %q: %s", d.Id(), err)
+	}
+	return nil
+}
+
+// resourceVSphereComputePolicyValidateHostTag checks that host_tag is set for
+// the capabilities that apply to hosts, and not set for the others.
+func resourceVSphereComputePolicyValidateHostTag(capability, hostTag string) error {
+	switch {
+	case computepolicy.UsesHostTag(capability) && hostTag == "":
+		return fmt.Errorf("host_tag is required for compute policies with the %s capability", capability)
+	case !computepolicy.UsesHostTag(capability) && hostTag != "":
+		return fmt.Errorf("host_tag is not allowed for compute policies with the %s capability", capability)
+	}
+	return nil
+}
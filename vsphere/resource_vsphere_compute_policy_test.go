@@ -0,0 +1,133 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computepolicy"
+)
+
+func TestResourceVSphereComputePolicyValidateHostTag(t *testing.T) {
+	cases := []struct {
+		name       string
+		capability string
+		hostTag    string
+		expectErr  bool
+	}{
+		{
+			name:       "host affinity with host tag",
+			capability: computepolicy.CapabilityVMHostAffinity,
+			hostTag:    "urn:vmomi:InventoryServiceTag:host:GLOBAL",
+		},
+		{
+			name:       "host anti-affinity without host tag",
+			capability: computepolicy.CapabilityVMHostAntiAffinity,
+			expectErr:  true,
+		},
+		{
+			name:       "vm anti-affinity without host tag",
+			capability: computepolicy.CapabilityVMVMAntiAffinity,
+		},
+		{
+			name:       "disable vMotion with host tag",
+			capability: computepolicy.CapabilityDisableDRSVMotion,
+			hostTag:    "urn:vmomi:InventoryServiceTag:host:GLOBAL",
+			expectErr:  true,
+		},
+	}
+	for _, tc := range cases {
+		err := resourceVSphereComputePolicyValidateHostTag(tc.capability, tc.hostTag)
+		if tc.expectErr != (err != nil) {
+			t.Fatalf("%q: expected error %t, got %v", tc.name, tc.expectErr, err)
+		}
+	}
+}
+
+func TestAccResourceVSphereComputePolicy_vmAntiAffinity(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereComputePolicyPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereComputePolicyExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereComputePolicyConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereComputePolicyExists(true),
+					resource.TestCheckResourceAttr("vsphere_compute_policy.policy", "capability", computepolicy.CapabilityVMVMAntiAffinity),
+				),
+			},
+			{
+				ResourceName:      "vsphere_compute_policy.policy",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereComputePolicyPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_TEST_COMPUTE_POLICIES") == "" {
+		t.Skip("set VSPHERE_TEST_COMPUTE_POLICIES to run vsphere_compute_policy acceptance tests (requires vCenter 7.0 or VMC on AWS)")
+	}
+}
+
+func testAccResourceVSphereComputePolicyExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_compute_policy.policy"]
+		if !ok {
+			if expected {
+				return fmt.Errorf("vsphere_compute_policy.policy not found in state")
+			}
+			return nil
+		}
+		client := testAccProvider.Meta().(*VSphereClient)
+		tagsClient, err := client.TagsClient()
+		if err != nil {
+			return err
+		}
+		_, err = computepolicy.FromID(tagsClient, client.vimClient.URL(), rs.Primary.ID)
+		switch {
+		case err == computepolicy.ErrNotFound && !expected:
+			return nil
+		case err != nil:
+			return err
+		case !expected:
+			return fmt.Errorf("compute policy %q still exists", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputePolicyConfig() string {
+	return fmt.Sprintf(`
+resource "vsphere_tag_category" "category" {
+  name        = "terraform-test-compute-policy"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "VirtualMachine",
+  ]
+}
+
+resource "vsphere_tag" "tag" {
+  name        = "terraform-test-anti-affinity"
+  category_id = "${vsphere_tag_category.category.id}"
+}
+
+resource "vsphere_compute_policy" "policy" {
+  name        = "terraform-test-anti-affinity"
+  description = "Keeps the tagged virtual machines on separate hosts."
+  capability  = "%s"
+  vm_tag      = "${vsphere_tag.tag.id}"
+}
+`,
+		computepolicy.CapabilityVMVMAntiAffinity,
+	)
+}
@@ -0,0 +1,112 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func resourceVSphereContentLibrary() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereContentLibraryCreate,
+		Read:   resourceVSphereContentLibraryRead,
+		Update: resourceVSphereContentLibraryUpdate,
+		Delete: resourceVSphereContentLibraryDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the content library.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the content library.",
+				Optional:    true,
+			},
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore that the items of the content library are stored on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereContentLibraryCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+	id, err := contentlibrary.CreateLocalLibrary(
+		tagsClient,
+		client.vimClient.URL(),
+		name,
+		d.Get("description").(string),
+		d.Get("datastore_id").(string),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating content library %q: %s", name, err)
+	}
+	d.SetId(id)
+	return resourceVSphereContentLibraryRead(d, meta)
+}
+
+func resourceVSphereContentLibraryRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	library, err := contentlibrary.LibraryFromID(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil {
+		if err == contentlibrary.ErrNotFound {
+			log.Printf("[DEBUG] Content library %q not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching content library %q: %s", d.Id(), err)
+	}
+	d.Set("name", library.Name)
+	d.Set("description", library.Description)
+	for _, backing := range library.StorageBackings {
+		if backing.DatastoreID != "" {
+			d.Set("datastore_id", backing.DatastoreID)
+			break
+		}
+	}
+	return nil
+}
+
+func resourceVSphereContentLibraryUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	if err := contentlibrary.UpdateLocalLibrary(tagsClient, client.vimClient.URL(), d.Id(), d.Get("name").(string), d.Get("description").(string)); err != nil {
+		return fmt.Errorf("error updating content library %q: %s", d.Id(), err)
+	}
+	return resourceVSphereContentLibraryRead(d, meta)
+}
+
+func resourceVSphereContentLibraryDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	err = contentlibrary.DeleteLocalLibrary(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil && err != contentlibrary.ErrNotFound {
+		return fmt.Errorf("error deleting content library %q: %s", d.Id(), err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+)
+
+func resourceVSphereContentLibraryItem() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereContentLibraryItemCreate,
+		Read:   resourceVSphereContentLibraryItemRead,
+		Update: resourceVSphereContentLibraryItemUpdate,
+		Delete: resourceVSphereContentLibraryItemDelete,
+
+		Schema: map[string]*schema.Schema{
+			"library_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the content library to create the item in.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the item.",
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the item.",
+				Optional:    true,
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Description: "The type of the item, such as ovf or iso. Items of type ovf can be deployed as virtual machines.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"file_urls": {
+				Type:        schema.TypeList,
+				Description: "The URLs that vCenter downloads the files of the item from. The files are named after the last element of the path of their URL. For OVF templates, include the OVF descriptor and all files that it references.",
+				Required:    true,
+				ForceNew:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"upload_timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for the files of the item to be uploaded.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+	}
+}
+
+func resourceVSphereContentLibraryItemCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	server := client.vimClient.URL()
+	files, err := contentLibraryItemFiles(structure.SliceInterfacesToStrings(d.Get("file_urls").([]interface{})))
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+	id, err := contentlibrary.CreateItem(
+		tagsClient,
+		server,
+		d.Get("library_id").(string),
+		name,
+		d.Get("description").(string),
+		d.Get("type").(string),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating content library item %q: %s", name, err)
+	}
+	// Set the ID before uploading the files, so that an item with a failed
+	// upload is tainted and replaced on the next apply.
+	d.SetId(id)
+	timeout := time.Duration(d.Get("upload_timeout").(int)) * time.Minute
+	if err := contentlibrary.Upload(tagsClient, server, id, files, timeout); err != nil {
+		return fmt.Errorf("error uploading files to content library item %q: %s", name, err)
+	}
+	return resourceVSphereContentLibraryItemRead(d, meta)
+}
+
+func resourceVSphereContentLibraryItemRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	item, err := contentlibrary.FromID(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil {
+		if err == contentlibrary.ErrNotFound {
+			log.Printf("[DEBUG] Content library item %q not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching content library item %q: %s", d.Id(), err)
+	}
+	d.Set("library_id", item.LibraryID)
+	d.Set("name", item.Name)
+	d.Set("description", item.Description)
+	d.Set("type", item.Type)
+	return nil
+}
+
+func resourceVSphereContentLibraryItemUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	if d.HasChange("name") || d.HasChange("description") {
+		if err := contentlibrary.UpdateItem(tagsClient, client.vimClient.URL(), d.Id(), d.Get("name").(string), d.Get("description").(string)); err != nil {
+			return fmt.Errorf("error updating content library item %q: %s", d.Id(), err)
+		}
+	}
+	return resourceVSphereContentLibraryItemRead(d, meta)
+}
+
+func resourceVSphereContentLibraryItemDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	err = contentlibrary.DeleteItem(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil && err != contentlibrary.ErrNotFound {
+		return fmt.Errorf("error deleting content library item %q: %s", d.Id(), err)
+	}
+	return nil
+}
+
+// contentLibraryItemFiles maps the URLs in file_urls to the names of the
+// files in the item, which are the last elements of the paths of the URLs.
+func contentLibraryItemFiles(urls []string) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file URL %q: %s", raw, err)
+		}
+		name := path.Base(u.Path)
+		if name == "." || name == "/" {
+			return nil, fmt.Errorf("file URL %q has no file name", raw)
+		}
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("more than one file URL has the file name %q", name)
+		}
+		files[name] = raw
+	}
+	return files, nil
+}
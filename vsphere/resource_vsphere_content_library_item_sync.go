@@ -0,0 +1,235 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+)
+
+func resourceVSphereContentLibraryItemSync() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereContentLibraryItemSyncCreate,
+		Read:          resourceVSphereContentLibraryItemSyncRead,
+		Update:        resourceVSphereContentLibraryItemSyncUpdate,
+		Delete:        resourceVSphereContentLibraryItemSyncDelete,
+		CustomizeDiff: resourceVSphereContentLibraryItemSyncCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"item_id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the item in a subscribed content library to sync.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"force_sync_content": {
+				Type:        schema.TypeBool,
+				Description: "Download the content of the item as well, even if the library only downloads content when it is needed.",
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Description: "Arbitrary values that cause the item to be synced again when they change.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"sync_timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for the sync to complete.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"expected_content_version": {
+				Type:        schema.TypeString,
+				Description: "Fail if the content version of the item is not this version.",
+				Optional:    true,
+			},
+			"expected_checksums": {
+				Type:        schema.TypeMap,
+				Description: "The checksums that the files of the item must have, keyed by file name.",
+				Optional:    true,
+			},
+			"fail_on_change": {
+				Type:        schema.TypeBool,
+				Description: "Fail the plan if the content version of the item changed since it was synced by this resource.",
+				Optional:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the item.",
+				Computed:    true,
+			},
+			"synced_content_version": {
+				Type:        schema.TypeString,
+				Description: "The version of the content of the item right after the sync by this resource.",
+				Computed:    true,
+			},
+			"file_checksums": {
+				Type:        schema.TypeMap,
+				Description: "The checksums of the files of the item, keyed by file name.",
+				Computed:    true,
+			},
+			"content_version": {
+				Type:        schema.TypeString,
+				Description: "The version of the content of the item after the sync.",
+				Computed:    true,
+			},
+			"last_sync_time": {
+				Type:        schema.TypeString,
+				Description: "The time that the item was last synced.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereContentLibraryItemSyncCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	server := client.vimClient.URL()
+	id := d.Get("item_id").(string)
+	item, err := contentlibrary.FromID(tagsClient, server, id)
+	if err != nil {
+		return fmt.Errorf("error fetching content library item %q: %s", id, err)
+	}
+	force := d.Get("force_sync_content").(bool)
+	if err := contentlibrary.Sync(tagsClient, server, id, force); err != nil {
+		return fmt.Errorf("error syncing content library item %q: %s", id, err)
+	}
+	timeout := time.Duration(d.Get("sync_timeout").(int)) * time.Minute
+	synced, err := contentlibrary.WaitForSync(tagsClient, server, id, item.LastSyncTime, force, timeout)
+	if err != nil {
+		return fmt.Errorf("error waiting for content library item %q to sync: %s", id, err)
+	}
+	d.SetId(id)
+	d.Set("synced_content_version", synced.ContentVersion)
+	if err := resourceVSphereContentLibraryItemSyncRead(d, meta); err != nil {
+		return err
+	}
+	return resourceVSphereContentLibraryItemSyncVerify(d)
+}
+
+func resourceVSphereContentLibraryItemSyncUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceVSphereContentLibraryItemSyncRead(d, meta); err != nil {
+		return err
+	}
+	return resourceVSphereContentLibraryItemSyncVerify(d)
+}
+
+func resourceVSphereContentLibraryItemSyncRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return err
+	}
+	item, err := contentlibrary.FromID(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil {
+		if err == contentlibrary.ErrNotFound {
+			log.Printf("[DEBUG] Content library item %q not found, removing sync from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching content library item %q: %s", d.Id(), err)
+	}
+	d.Set("name", item.Name)
+	d.Set("content_version", item.ContentVersion)
+	d.Set("last_sync_time", item.LastSyncTime)
+
+	files, err := contentlibrary.Files(tagsClient, client.vimClient.URL(), d.Id())
+	if err != nil {
+		return fmt.Errorf("error fetching files of content library item %q: %s", d.Id(), err)
+	}
+	checksums := make(map[string]interface{})
+	for _, f := range files {
+		if f.ChecksumInfo != nil {
+			checksums[f.Name] = f.ChecksumInfo.Checksum
+		}
+	}
+	if err := d.Set("file_checksums", checksums); err != nil {
+		return fmt.Errorf("error setting file_checksums: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereContentLibraryItemSyncDelete(d *schema.ResourceData, meta interface{}) error {
+	// Syncing cannot be undone, so this only removes the resource from state.
+	log.Printf("[DEBUG] Removing sync of content library item %q from state", d.Id())
+	return nil
+}
+
+// resourceVSphereContentLibraryItemSyncCustomizeDiff fails the plan if the
+// item, as read during the refresh, does not match the expected content
+// version and checksums, or has changed since it was synced when
+// fail_on_change is set. New resources and resources that are being
+// replaced are checked after the sync instead.
+func resourceVSphereContentLibraryItemSyncCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || d.HasChange("item_id") || d.HasChange("force_sync_content") || d.HasChange("triggers") {
+		return nil
+	}
+	// synced_content_version is empty for items that were synced by earlier
+	// versions of the provider, in which case there is nothing to compare to.
+	version := d.Get("content_version").(string)
+	synced := d.Get("synced_content_version").(string)
+	if d.Get("fail_on_change").(bool) && synced != "" && version != synced {
+		return fmt.Errorf(
+			"content library item %q changed since it was synced: content version is %s, was %s. Change triggers to sync it again",
+			d.Id(),
+			version,
+			synced,
+		)
+	}
+	return contentLibraryItemSyncCheckExpected(
+		d.Id(),
+		d.Get("expected_content_version").(string),
+		d.Get("expected_checksums").(map[string]interface{}),
+		version,
+		d.Get("file_checksums").(map[string]interface{}),
+	)
+}
+
+// resourceVSphereContentLibraryItemSyncVerify checks the item against the
+// expected content version and checksums after a sync or an update.
+func resourceVSphereContentLibraryItemSyncVerify(d *schema.ResourceData) error {
+	return contentLibraryItemSyncCheckExpected(
+		d.Id(),
+		d.Get("expected_content_version").(string),
+		d.Get("expected_checksums").(map[string]interface{}),
+		d.Get("content_version").(string),
+		d.Get("file_checksums").(map[string]interface{}),
+	)
+}
+
+// contentLibraryItemSyncCheckExpected compares the content version and file
+// checksums of an item with the expected ones. An empty expected version is
+// not checked, and only the files in expected checksums are checked.
+func contentLibraryItemSyncCheckExpected(id, expectedVersion string, expectedChecksums map[string]interface{}, version string, checksums map[string]interface{}) error {
+	if expectedVersion != "" && version != expectedVersion {
+		return fmt.Errorf("content library item %q has content version %s, expected %s", id, version, expectedVersion)
+	}
+	var names []string
+	for name := range expectedChecksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		actual, ok := checksums[name]
+		if !ok {
+			return fmt.Errorf("content library item %q has no checksum for file %q", id, name)
+		}
+		if !strings.EqualFold(actual.(string), expectedChecksums[name].(string)) {
+			return fmt.Errorf("content library item %q file %q has checksum %s, expected %s", id, name, actual, expectedChecksums[name])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereContentLibraryItemSync_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereContentLibraryItemSyncPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereContentLibraryItemSyncConfig("1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("vsphere_content_library_item_sync.sync", "last_sync_time"),
+					resource.TestCheckResourceAttrSet("vsphere_content_library_item_sync.sync", "name"),
+					resource.TestCheckResourceAttrPair("vsphere_content_library_item_sync.sync", "synced_content_version", "vsphere_content_library_item_sync.sync", "content_version"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereContentLibraryItemSyncConfig("2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("vsphere_content_library_item_sync.sync", "last_sync_time"),
+				),
+			},
+		},
+	})
+}
+
+func TestContentLibraryItemSyncCheckExpected(t *testing.T) {
+	checksums := map[string]interface{}{
+		"disk-0.vmdk": "ABCDEF",
+		"vm.ovf":      "123456",
+	}
+	cases := []struct {
+		name              string
+		expectedVersion   string
+		expectedChecksums map[string]interface{}
+		expected          bool
+	}{
+		{"nothing expected", "", nil, true},
+		{"matching version", "3", nil, true},
+		{"other version", "2", nil, false},
+		{"matching checksum", "", map[string]interface{}{"disk-0.vmdk": "abcdef"}, true},
+		{"other checksum", "", map[string]interface{}{"vm.ovf": "654321"}, false},
+		{"missing file", "", map[string]interface{}{"vm.mf": "123456"}, false},
+	}
+	for _, tc := range cases {
+		err := contentLibraryItemSyncCheckExpected("item", tc.expectedVersion, tc.expectedChecksums, "3", checksums)
+		if tc.expected && err != nil {
+			t.Fatalf("%s: expected no error, got %s", tc.name, err)
+		}
+		if !tc.expected && err == nil {
+			t.Fatalf("%s: expected error, got none", tc.name)
+		}
+	}
+}
+
+func testAccResourceVSphereContentLibraryItemSyncPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_SUBSCRIBED_LIBRARY_ITEM_ID") == "" {
+		t.Skip("set VSPHERE_SUBSCRIBED_LIBRARY_ITEM_ID to run vsphere_content_library_item_sync acceptance tests")
+	}
+}
+
+func testAccResourceVSphereContentLibraryItemSyncConfig(trigger string) string {
+	return fmt.Sprintf(`
+variable "item_id" {
+  default = "%s"
+}
+
+resource "vsphere_content_library_item_sync" "sync" {
+  item_id = "${var.item_id}"
+
+  triggers = {
+    run = "%s"
+  }
+}
+`,
+		os.Getenv("VSPHERE_SUBSCRIBED_LIBRARY_ITEM_ID"),
+		trigger,
+	)
+}
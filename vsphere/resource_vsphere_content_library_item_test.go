@@ -0,0 +1,98 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereContentLibraryItem_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereContentLibraryPreCheck(t)
+			testAccResourceVSphereContentLibraryItemPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereContentLibraryItemConfig("terraform-test-item"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_content_library_item.item", "type", "iso"),
+					resource.TestCheckResourceAttrPair("vsphere_content_library_item.item", "library_id", "vsphere_content_library.library", "id"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereContentLibraryItemConfig("terraform-test-item-renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_content_library_item.item", "name", "terraform-test-item-renamed"),
+				),
+			},
+		},
+	})
+}
+
+func TestContentLibraryItemFiles(t *testing.T) {
+	cases := []struct {
+		name     string
+		urls     []string
+		expected map[string]string
+	}{
+		{
+			"ovf",
+			[]string{"https://example.com/vm/vm.ovf", "https://example.com/vm/vm-disk1.vmdk?token=abc"},
+			map[string]string{
+				"vm.ovf":        "https://example.com/vm/vm.ovf",
+				"vm-disk1.vmdk": "https://example.com/vm/vm-disk1.vmdk?token=abc",
+			},
+		},
+		{"no file name", []string{"https://example.com/"}, nil},
+		{"duplicate names", []string{"https://a.example.com/vm.ovf", "https://b.example.com/vm.ovf"}, nil},
+	}
+	for _, tc := range cases {
+		files, err := contentLibraryItemFiles(tc.urls)
+		if tc.expected == nil {
+			if err == nil {
+				t.Fatalf("%s: expected error, got none", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %s", tc.name, err)
+		}
+		if len(files) != len(tc.expected) {
+			t.Fatalf("%s: expected %d files, got %d", tc.name, len(tc.expected), len(files))
+		}
+		for name, u := range tc.expected {
+			if files[name] != u {
+				t.Fatalf("%s: expected %q for %q, got %q", tc.name, u, name, files[name])
+			}
+		}
+	}
+}
+
+func testAccResourceVSphereContentLibraryItemPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_CONTENT_LIBRARY_ISO_URL") == "" {
+		t.Skip("set VSPHERE_CONTENT_LIBRARY_ISO_URL to run vsphere_content_library_item acceptance tests")
+	}
+}
+
+func testAccResourceVSphereContentLibraryItemConfig(name string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_content_library_item" "item" {
+  name       = "%s"
+  library_id = "${vsphere_content_library.library.id}"
+  type       = "iso"
+  file_urls  = ["%s"]
+}
+`,
+		testAccResourceVSphereContentLibraryConfig("terraform-test-library", ""),
+		name,
+		os.Getenv("VSPHERE_CONTENT_LIBRARY_ISO_URL"),
+	)
+}
@@ -0,0 +1,75 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereContentLibrary_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereContentLibraryPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereContentLibraryConfig("terraform-test-library", "first"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_content_library.library", "name", "terraform-test-library"),
+					resource.TestCheckResourceAttr("vsphere_content_library.library", "description", "first"),
+					resource.TestCheckResourceAttrPair("vsphere_content_library.library", "datastore_id", "data.vsphere_datastore.datastore", "id"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereContentLibraryConfig("terraform-test-library-renamed", "second"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_content_library.library", "name", "terraform-test-library-renamed"),
+					resource.TestCheckResourceAttr("vsphere_content_library.library", "description", "second"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_content_library.library",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereContentLibraryPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_content_library acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_content_library acceptance tests")
+	}
+}
+
+func testAccResourceVSphereContentLibraryConfig(name, description string) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_content_library" "library" {
+  name         = "%s"
+  description  = "%s"
+  datastore_id = "${data.vsphere_datastore.datastore.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		name,
+		description,
+	)
+}
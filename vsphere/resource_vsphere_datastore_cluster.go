@@ -329,7 +329,7 @@ func resourceVSphereDatastoreClusterApplyCreate(d *schema.ResourceData, meta int
 	// Create the storage pod (datastore cluster).
 	pod, err := storagepod.Create(f, d.Get("name").(string))
 	if err != nil {
-		return nil, fmt.Errorf("error creating datastore cluster: %s", err)
+		return nil, viapi.NewDiagnostic(err, "error creating datastore cluster")
 	}
 
 	// Set the ID now before proceeding with tags, custom attributes, and DRS.
@@ -682,7 +682,7 @@ func expandStorageDrsPodConfigSpec(d *schema.ResourceData, version viapi.VSphere
 		Option:                 expandStorageDrsOptionSpec(d),
 	}
 
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityStorageDRSAutomationOverrides.SupportedBy(version) {
 		obj.AutomationOverrides = expandStorageDrsAutomationConfig(d)
 	}
 
@@ -717,7 +717,7 @@ func flattenStorageDrsPodConfigInfo(d *schema.ResourceData, obj types.StorageDrs
 		return err
 	}
 
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityStorageDRSAutomationOverrides.SupportedBy(version) {
 		if err := flattenStorageDrsAutomationConfig(d, obj.AutomationOverrides); err != nil {
 			return err
 		}
@@ -765,7 +765,7 @@ func expandStorageDrsIoLoadBalanceConfig(d *schema.ResourceData, version viapi.V
 		IoLoadImbalanceThreshold: int32(d.Get("sdrs_io_load_imbalance_threshold").(int)),
 	}
 
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityStorageDRSReservableIO.SupportedBy(version) {
 		obj.ReservableIopsThreshold = int32(d.Get("sdrs_io_reservable_iops_threshold").(int))
 		obj.ReservablePercentThreshold = int32(d.Get("sdrs_io_reservable_percent_threshold").(int))
 		obj.ReservableThresholdMode = d.Get("sdrs_io_reservable_threshold_mode").(string)
@@ -785,7 +785,7 @@ func flattenStorageDrsIoLoadBalanceConfig(
 		"sdrs_io_latency_threshold":        obj.IoLatencyThreshold,
 		"sdrs_io_load_imbalance_threshold": obj.IoLoadImbalanceThreshold,
 	}
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityStorageDRSReservableIO.SupportedBy(version) {
 		attrs["sdrs_io_reservable_threshold_mode"] = obj.ReservableThresholdMode
 		if obj.ReservableThresholdMode == string(types.StorageDrsPodConfigInfoBehaviorManual) {
 			attrs["sdrs_io_reservable_iops_threshold"] = obj.ReservableIopsThreshold
@@ -814,7 +814,7 @@ func expandStorageDrsSpaceLoadBalanceConfig(
 		SpaceUtilizationThreshold:     int32(d.Get("sdrs_space_utilization_threshold").(int)),
 	}
 
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6}) {
+	if viapi.CapabilityStorageDRSFreeSpaceThreshold.SupportedBy(version) {
 		obj.FreeSpaceThresholdGB = int32(d.Get("sdrs_free_space_threshold").(int))
 		obj.SpaceThresholdMode = d.Get("sdrs_free_space_threshold_mode").(string)
 	}
@@ -834,7 +834,7 @@ func flattenStorageDrsSpaceLoadBalanceConfig(
 		"sdrs_free_space_threshold_mode":         obj.SpaceThresholdMode,
 	}
 
-	freeSpaceSupported := version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6})
+	freeSpaceSupported := viapi.CapabilityStorageDRSFreeSpaceThreshold.SupportedBy(version)
 	if freeSpaceSupported && obj.SpaceThresholdMode == string(types.StorageDrsSpaceLoadBalanceConfigSpaceThresholdModeFreeSpace) {
 		attrs["sdrs_free_space_threshold"] = obj.FreeSpaceThresholdGB
 	} else {
@@ -0,0 +1,261 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereDatastoreClusterMembership() *schema.Resource {
+	return &schema.Resource{
+		Create:        resourceVSphereDatastoreClusterMembershipCreate,
+		Read:          resourceVSphereDatastoreClusterMembershipRead,
+		Update:        resourceVSphereDatastoreClusterMembershipCreate,
+		Delete:        resourceVSphereDatastoreClusterMembershipDelete,
+		CustomizeDiff: resourceVSphereDatastoreClusterMembershipCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"datastore_cluster_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore cluster to manage the datastores of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Description:  "A regular expression that the names of the datastores in the datastore cluster must match.",
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+			"tag_ids": {
+				Type:        schema.TypeSet,
+				Description: "The IDs of tags. Datastores with any of these tags are put in the datastore cluster.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"folder": {
+				Type:        schema.TypeString,
+				Description: "The datastore folder to move datastores to when they no longer match. The default is the root datastore folder.",
+				Optional:    true,
+				StateFunc:   folder.NormalizePath,
+			},
+			"datastore_ids": {
+				Type:        schema.TypeSet,
+				Description: "The managed object IDs of the datastores in the datastore cluster.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereDatastoreClusterMembershipCreate(d *schema.ResourceData, meta interface{}) error {
+	if d.Get("name_regex").(string) == "" && d.Get("tag_ids").(*schema.Set).Len() < 1 {
+		return fmt.Errorf("at least one of name_regex or tag_ids must be set")
+	}
+	client := meta.(*VSphereClient).vimClient
+	podID := d.Get("datastore_cluster_id").(string)
+	pod, err := storagepod.FromID(client, podID)
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	desired, err := resourceVSphereDatastoreClusterMembershipMatches(
+		meta,
+		pod,
+		d.Get("name_regex").(string),
+		structure.SliceInterfacesToStrings(d.Get("tag_ids").(*schema.Set).List()),
+	)
+	if err != nil {
+		return err
+	}
+	current, err := storagepod.Datastores(pod)
+	if err != nil {
+		return fmt.Errorf("error fetching datastores of datastore cluster %q: %s", pod.InventoryPath, err)
+	}
+
+	var add []types.ManagedObjectReference
+	for _, id := range desired {
+		if !resourceVSphereDatastoreClusterMembershipHasID(current, id) {
+			add = append(add, types.ManagedObjectReference{Type: "Datastore", Value: id})
+		}
+	}
+	if len(add) > 0 {
+		if err := storagepod.MoveDatastoresInto(pod, add); err != nil {
+			return viapi.NewDiagnostic(err, "error moving datastores into datastore cluster %q", pod.InventoryPath)
+		}
+	}
+	f := d.Get("folder").(string)
+	for _, ref := range current {
+		if resourceVSphereDatastoreClusterMembershipHasString(desired, ref.Value) {
+			continue
+		}
+		ds, err := datastore.FromID(client, ref.Value)
+		if err != nil {
+			return fmt.Errorf("cannot locate datastore %q: %s", ref.Value, err)
+		}
+		log.Printf("[DEBUG] Datastore %q no longer matches, moving it to folder %q", ds.InventoryPath, f)
+		if err := datastore.MoveToFolder(client, ds, f); err != nil {
+			return fmt.Errorf("could not move datastore %q to folder %q: %s", ds.InventoryPath, f, err)
+		}
+	}
+
+	d.SetId(podID)
+	return resourceVSphereDatastoreClusterMembershipRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterMembershipRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	pod, err := storagepod.FromID(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Datastore cluster %q not found, removing membership from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	refs, err := storagepod.Datastores(pod)
+	if err != nil {
+		return fmt.Errorf("error fetching datastores of datastore cluster %q: %s", pod.InventoryPath, err)
+	}
+	var ids []string
+	for _, ref := range refs {
+		ids = append(ids, ref.Value)
+	}
+	d.Set("datastore_cluster_id", d.Id())
+	if err := d.Set("datastore_ids", ids); err != nil {
+		return fmt.Errorf("error setting datastore_ids: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereDatastoreClusterMembershipDelete(d *schema.ResourceData, meta interface{}) error {
+	// The datastores are left in the datastore cluster, so that destroying the
+	// resource does not evacuate the datastore cluster.
+	log.Printf("[DEBUG] Removing membership of datastore cluster %q from state, datastores are left in place", d.Id())
+	return nil
+}
+
+// resourceVSphereDatastoreClusterMembershipCustomizeDiff looks up the
+// datastores that currently match the configuration, so that an update is
+// planned when datastores that match have been added outside of Terraform, or
+// datastores in the datastore cluster no longer match.
+func resourceVSphereDatastoreClusterMembershipCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// The filters can only be trusted to be known when they have not changed.
+	// Otherwise, the matching datastores are found during the apply.
+	if d.Id() == "" || d.HasChange("name_regex") || d.HasChange("tag_ids") {
+		return d.SetNewComputed("datastore_ids")
+	}
+	pod, err := storagepod.FromID(meta.(*VSphereClient).vimClient, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	desired, err := resourceVSphereDatastoreClusterMembershipMatches(
+		meta,
+		pod,
+		d.Get("name_regex").(string),
+		structure.SliceInterfacesToStrings(d.Get("tag_ids").(*schema.Set).List()),
+	)
+	if err != nil {
+		return err
+	}
+	current := structure.SliceInterfacesToStrings(d.Get("datastore_ids").(*schema.Set).List())
+	sort.Strings(current)
+	if fmt.Sprint(current) != fmt.Sprint(desired) {
+		log.Printf("[DEBUG] Datastore cluster %q: datastores %v match, but datastores %v are members", d.Id(), desired, current)
+		return d.SetNew("datastore_ids", desired)
+	}
+	return nil
+}
+
+// resourceVSphereDatastoreClusterMembershipMatches returns the sorted managed
+// object IDs of the datastores in the datacenter of the datastore cluster that
+// match the name regular expression and have at least one of the tags. An
+// empty filter matches all datastores. Datastores in other datastore clusters
+// are never matched, so that they are not taken away from their current
+// datastore cluster.
+func resourceVSphereDatastoreClusterMembershipMatches(meta interface{}, pod *object.StoragePod, nameRegex string, tagIDs []string) ([]string, error) {
+	client := meta.(*VSphereClient).vimClient
+	re, err := regexp.Compile(nameRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name_regex: %s", err)
+	}
+	var tagged []string
+	if len(tagIDs) > 0 {
+		tagsClient, err := meta.(*VSphereClient).TagsClient()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range tagIDs {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+			objs, err := tagsClient.ListAttachedObjects(ctx, id)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("error fetching objects with tag %q: %s", id, err)
+			}
+			for _, obj := range objs {
+				if obj.Type != nil && *obj.Type == "Datastore" && obj.ID != nil {
+					tagged = append(tagged, *obj.ID)
+				}
+			}
+		}
+	}
+
+	all, err := storagepod.DatacenterDatastores(client, pod)
+	if err != nil {
+		return nil, fmt.Errorf("error listing datastores: %s", err)
+	}
+	var ids []string
+	for _, props := range all {
+		if props.Parent != nil && props.Parent.Type == "StoragePod" && props.Parent.Value != pod.Reference().Value {
+			log.Printf("[DEBUG] Skipping datastore %q: datastore is in datastore cluster %q", props.Name, props.Parent.Value)
+			continue
+		}
+		if !re.MatchString(props.Name) {
+			continue
+		}
+		if len(tagIDs) > 0 && !resourceVSphereDatastoreClusterMembershipHasString(tagged, props.Self.Value) {
+			continue
+		}
+		ids = append(ids, props.Self.Value)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// resourceVSphereDatastoreClusterMembershipHasID returns true if refs contains
+// a reference with the supplied ID.
+func resourceVSphereDatastoreClusterMembershipHasID(refs []types.ManagedObjectReference, id string) bool {
+	for _, ref := range refs {
+		if ref.Value == id {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceVSphereDatastoreClusterMembershipHasString returns true if s
+// contains v.
+func resourceVSphereDatastoreClusterMembershipHasString(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
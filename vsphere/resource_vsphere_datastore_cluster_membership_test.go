@@ -0,0 +1,117 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+)
+
+func TestAccResourceVSphereDatastoreClusterMembership_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDatastoreClusterPreCheck(t)
+			testAccResourceVSphereNasDatastorePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDatastoreClusterMembershipConfig("^terraform-test-nas$"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_datastore_cluster_membership.membership", "datastore_ids.#", "1"),
+					testAccResourceVSphereDatastoreClusterMembershipCheckParent("StoragePod"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDatastoreClusterMembershipConfig("^no-match$"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_datastore_cluster_membership.membership", "datastore_ids.#", "0"),
+					testAccResourceVSphereDatastoreClusterMembershipCheckParent("Folder"),
+				),
+			},
+		},
+	})
+}
+
+// testAccResourceVSphereDatastoreClusterMembershipCheckParent checks the type
+// of the parent of the test NAS datastore.
+func testAccResourceVSphereDatastoreClusterMembershipCheckParent(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_nas_datastore.datastore"]
+		if !ok {
+			return fmt.Errorf("vsphere_nas_datastore.datastore not found in state")
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		ds, err := datastore.FromID(client, rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		props, err := datastore.Properties(ds)
+		if err != nil {
+			return err
+		}
+		if props.Parent.Type != expected {
+			return fmt.Errorf("expected parent of datastore to be a %s, got %s", expected, props.Parent.Type)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterMembershipConfig(nameRegex string) string {
+	return fmt.Sprintf(`
+variable "nfs_host" {
+  default = "%s"
+}
+
+variable "nfs_path" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name            = "terraform-test-nas"
+  host_system_ids = ["${data.vsphere_host.esxi_host.id}"]
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+
+  # Destroy the datastore before the datastore cluster that it may be in.
+  depends_on = ["vsphere_datastore_cluster.datastore_cluster"]
+
+  lifecycle {
+    ignore_changes = ["datastore_cluster_id", "folder"]
+  }
+}
+
+resource "vsphere_datastore_cluster_membership" "membership" {
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+  name_regex           = "%s"
+
+  depends_on = ["vsphere_nas_datastore.datastore"]
+}
+`,
+		os.Getenv("VSPHERE_NAS_HOST"),
+		os.Getenv("VSPHERE_NFS_PATH"),
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		nameRegex,
+	)
+}
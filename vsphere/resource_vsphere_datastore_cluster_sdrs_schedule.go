@@ -0,0 +1,418 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/scheduledtask"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereDatastoreClusterSDRSScheduleName = "vsphere_datastore_cluster_sdrs_schedule"
+
+// datastoreClusterSDRSScheduleMethod is the name of the method that the
+// scheduled tasks managed by vsphere_datastore_cluster_sdrs_schedule run on
+// the StorageResourceManager.
+const datastoreClusterSDRSScheduleMethod = "ConfigureStorageDrsForPod_Task"
+
+// datastoreClusterSDRSScheduleStartSuffix and
+// datastoreClusterSDRSScheduleEndSuffix are appended to the name of the
+// schedule to get the names of the scheduled tasks that start and end the
+// window.
+const (
+	datastoreClusterSDRSScheduleStartSuffix = " (start)"
+	datastoreClusterSDRSScheduleEndSuffix   = " (end)"
+)
+
+// datastoreClusterSDRSScheduleTimeFormat is the format for the start_time and
+// end_time attributes.
+const datastoreClusterSDRSScheduleTimeFormat = "15:04"
+
+// datastoreClusterSDRSScheduleDays is the list of allowed values for the days
+// attribute, in the order of time.Weekday.
+var datastoreClusterSDRSScheduleDays = []string{
+	"sunday",
+	"monday",
+	"tuesday",
+	"wednesday",
+	"thursday",
+	"friday",
+	"saturday",
+}
+
+func resourceVSphereDatastoreClusterSDRSSchedule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereDatastoreClusterSDRSScheduleCreate,
+		Read:   resourceVSphereDatastoreClusterSDRSScheduleRead,
+		Update: resourceVSphereDatastoreClusterSDRSScheduleUpdate,
+		Delete: resourceVSphereDatastoreClusterSDRSScheduleDelete,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the datastore cluster to schedule the storage DRS configuration for.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the schedule. The scheduled tasks for the start and end of the window are named after this.",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "A description for the scheduled tasks.",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Enable the scheduled tasks for this schedule.",
+			},
+			"start_time": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The time of day, in UTC and HH:MM format, at which the window starts.",
+				ValidateFunc: validateDatastoreClusterSDRSScheduleTime,
+			},
+			"end_time": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The time of day, in UTC and HH:MM format, at which the window ends. If earlier than start_time, the window ends on the next day.",
+				ValidateFunc: validateDatastoreClusterSDRSScheduleTime,
+			},
+			"days": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The days of the week on which the window starts. The window starts every day when not set.",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(datastoreClusterSDRSScheduleDays, false),
+				},
+			},
+			"sdrs_automation_level": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      string(types.StorageDrsPodConfigInfoBehaviorAutomated),
+				Description:  "The default automation level applied to the datastore cluster at the start of the window.",
+				ValidateFunc: validation.StringInSlice(storageDrsPodConfigInfoBehaviorAllowedValues, false),
+			},
+			"sdrs_restore_automation_level": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      string(types.StorageDrsPodConfigInfoBehaviorManual),
+				Description:  "The default automation level applied to the datastore cluster at the end of the window.",
+				ValidateFunc: validation.StringInSlice(storageDrsPodConfigInfoBehaviorAllowedValues, false),
+			},
+		},
+	}
+}
+
+func resourceVSphereDatastoreClusterSDRSScheduleCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, err := storagepod.FromID(client, d.Get("datastore_cluster_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	startSpec, endSpec, err := expandDatastoreClusterSDRSScheduleSpecs(d, pod.Reference())
+	if err != nil {
+		return err
+	}
+	mgr := *client.ServiceContent.StorageResourceManager
+
+	startID, err := scheduledtask.Create(client, mgr, startSpec)
+	if err != nil {
+		return viapi.NewDiagnostic(err, "error creating scheduled task for start of window")
+	}
+	endID, err := scheduledtask.Create(client, mgr, endSpec)
+	if err != nil {
+		// Don't leave a window that never closes behind.
+		if rerr := scheduledtask.Remove(client, startID); rerr != nil {
+			log.Printf("[WARN] %s: Could not remove scheduled task %q: %s", resourceVSphereDatastoreClusterSDRSScheduleIDString(d), startID, rerr)
+		}
+		return viapi.NewDiagnostic(err, "error creating scheduled task for end of window")
+	}
+	d.SetId(fmt.Sprintf("%s:%s", startID, endID))
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+	return resourceVSphereDatastoreClusterSDRSScheduleRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterSDRSScheduleRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	startID, endID, err := splitDatastoreClusterSDRSScheduleID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	// If either side of the window has been removed out of band, the schedule
+	// is broken and needs to be re-created.
+	start, err := scheduledtask.Properties(client, startID)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Start task is missing, removing schedule from state", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching scheduled task for start of window: %s", err)
+	}
+	end, err := scheduledtask.Properties(client, endID)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: End task is missing, removing schedule from state", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching scheduled task for end of window: %s", err)
+	}
+
+	if err := flattenDatastoreClusterSDRSScheduleTasks(d, start.Info, end.Info); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+	return nil
+}
+
+func resourceVSphereDatastoreClusterSDRSScheduleUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	startID, endID, err := splitDatastoreClusterSDRSScheduleID(d.Id())
+	if err != nil {
+		return err
+	}
+	podRef := types.ManagedObjectReference{
+		Type:  "StoragePod",
+		Value: d.Get("datastore_cluster_id").(string),
+	}
+	startSpec, endSpec, err := expandDatastoreClusterSDRSScheduleSpecs(d, podRef)
+	if err != nil {
+		return err
+	}
+
+	if err := scheduledtask.Reconfigure(client, startID, startSpec); err != nil {
+		return viapi.NewDiagnostic(err, "error updating scheduled task for start of window")
+	}
+	if err := scheduledtask.Reconfigure(client, endID, endSpec); err != nil {
+		return viapi.NewDiagnostic(err, "error updating scheduled task for end of window")
+	}
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereDatastoreClusterSDRSScheduleIDString(d))
+	return resourceVSphereDatastoreClusterSDRSScheduleRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterSDRSScheduleDelete(d *schema.ResourceData, meta interface{}) error {
+	resourceIDString := resourceVSphereDatastoreClusterSDRSScheduleIDString(d)
+	log.Printf("[DEBUG] %s: Beginning delete", resourceIDString)
+	client := meta.(*VSphereClient).vimClient
+	startID, endID, err := splitDatastoreClusterSDRSScheduleID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range []string{startID, endID} {
+		if err := scheduledtask.Remove(client, id); err != nil && !viapi.IsManagedObjectNotFoundError(err) {
+			return fmt.Errorf("error removing scheduled task %q: %s", id, err)
+		}
+	}
+
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceIDString)
+	return nil
+}
+
+// expandDatastoreClusterSDRSScheduleSpecs reads certain ResourceData keys and
+// returns the ScheduledTaskSpecs for the tasks that start and end the window.
+func expandDatastoreClusterSDRSScheduleSpecs(d *schema.ResourceData, pod types.ManagedObjectReference) (types.ScheduledTaskSpec, types.ScheduledTaskSpec, error) {
+	var start, end types.ScheduledTaskSpec
+	startHour, startMinute, err := parseDatastoreClusterSDRSScheduleTime(d.Get("start_time").(string))
+	if err != nil {
+		return start, end, err
+	}
+	endHour, endMinute, err := parseDatastoreClusterSDRSScheduleTime(d.Get("end_time").(string))
+	if err != nil {
+		return start, end, err
+	}
+
+	var startDays []time.Weekday
+	for _, v := range d.Get("days").(*schema.Set).List() {
+		for i, day := range datastoreClusterSDRSScheduleDays {
+			if v.(string) == day {
+				startDays = append(startDays, time.Weekday(i))
+			}
+		}
+	}
+	// A window that ends earlier in the day than it starts closes on the day
+	// after it opens.
+	endDays := startDays
+	if endHour*60+endMinute <= startHour*60+startMinute {
+		endDays = make([]time.Weekday, len(startDays))
+		for i, day := range startDays {
+			endDays[i] = (day + 1) % 7
+		}
+	}
+
+	name := d.Get("name").(string)
+	start = types.ScheduledTaskSpec{
+		Name:        name + datastoreClusterSDRSScheduleStartSuffix,
+		Description: d.Get("description").(string),
+		Enabled:     d.Get("enabled").(bool),
+		Scheduler:   scheduledtask.DailyScheduler(startHour, startMinute, startDays),
+		Action:      expandDatastoreClusterSDRSScheduleAction(pod, d.Get("sdrs_automation_level").(string)),
+	}
+	end = types.ScheduledTaskSpec{
+		Name:        name + datastoreClusterSDRSScheduleEndSuffix,
+		Description: d.Get("description").(string),
+		Enabled:     d.Get("enabled").(bool),
+		Scheduler:   scheduledtask.DailyScheduler(endHour, endMinute, endDays),
+		Action:      expandDatastoreClusterSDRSScheduleAction(pod, d.Get("sdrs_restore_automation_level").(string)),
+	}
+	return start, end, nil
+}
+
+// expandDatastoreClusterSDRSScheduleAction returns an action that sets the
+// default automation level of the supplied StoragePod.
+func expandDatastoreClusterSDRSScheduleAction(pod types.ManagedObjectReference, level string) types.BaseAction {
+	return &types.MethodAction{
+		Name: datastoreClusterSDRSScheduleMethod,
+		Argument: []types.MethodActionArgument{
+			{Value: pod},
+			{
+				Value: types.StorageDrsConfigSpec{
+					PodConfigSpec: &types.StorageDrsPodConfigSpec{
+						DefaultVmBehavior: level,
+					},
+				},
+			},
+			{Value: true},
+		},
+	}
+}
+
+// flattenDatastoreClusterSDRSScheduleTasks saves the ScheduledTaskInfo of the
+// tasks that start and end the window into the supplied ResourceData.
+func flattenDatastoreClusterSDRSScheduleTasks(d *schema.ResourceData, start, end types.ScheduledTaskInfo) error {
+	podID, startLevel, err := flattenDatastoreClusterSDRSScheduleAction(start.Action)
+	if err != nil {
+		return err
+	}
+	_, endLevel, err := flattenDatastoreClusterSDRSScheduleAction(end.Action)
+	if err != nil {
+		return err
+	}
+	startTime, days, err := flattenDatastoreClusterSDRSScheduleScheduler(start.Scheduler)
+	if err != nil {
+		return err
+	}
+	endTime, _, err := flattenDatastoreClusterSDRSScheduleScheduler(end.Scheduler)
+	if err != nil {
+		return err
+	}
+
+	d.Set("datastore_cluster_id", podID)
+	d.Set("name", strings.TrimSuffix(start.Name, datastoreClusterSDRSScheduleStartSuffix))
+	d.Set("description", start.Description)
+	d.Set("enabled", start.Enabled)
+	d.Set("start_time", startTime)
+	d.Set("end_time", endTime)
+	d.Set("sdrs_automation_level", startLevel)
+	d.Set("sdrs_restore_automation_level", endLevel)
+	if err := d.Set("days", days); err != nil {
+		return fmt.Errorf("error setting days: %s", err)
+	}
+	return nil
+}
+
+// flattenDatastoreClusterSDRSScheduleScheduler returns the time of day and
+// the days of the week that a scheduler created by
+// scheduledtask.DailyScheduler runs on.
+func flattenDatastoreClusterSDRSScheduleScheduler(obj types.BaseTaskScheduler) (string, []string, error) {
+	hour, minute, weekdays, err := scheduledtask.DailySchedulerTime(obj)
+	if err != nil {
+		return "", nil, err
+	}
+	var days []string
+	for _, day := range weekdays {
+		days = append(days, datastoreClusterSDRSScheduleDays[day])
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), days, nil
+}
+
+// flattenDatastoreClusterSDRSScheduleAction returns the StoragePod ID and the
+// automation level from an action created by
+// expandDatastoreClusterSDRSScheduleAction.
+func flattenDatastoreClusterSDRSScheduleAction(obj types.BaseAction) (string, string, error) {
+	action, ok := obj.(*types.MethodAction)
+	if !ok || action.Name != datastoreClusterSDRSScheduleMethod || len(action.Argument) < 2 {
+		return "", "", fmt.Errorf("scheduled task does not configure storage DRS")
+	}
+
+	var podID string
+	switch t := action.Argument[0].Value.(type) {
+	case types.ManagedObjectReference:
+		podID = t.Value
+	case *types.ManagedObjectReference:
+		podID = t.Value
+	}
+
+	var spec *types.StorageDrsConfigSpec
+	switch t := action.Argument[1].Value.(type) {
+	case types.StorageDrsConfigSpec:
+		spec = &t
+	case *types.StorageDrsConfigSpec:
+		spec = t
+	}
+	if podID == "" || spec == nil || spec.PodConfigSpec == nil {
+		return "", "", fmt.Errorf("scheduled task has unexpected arguments")
+	}
+	return podID, spec.PodConfigSpec.DefaultVmBehavior, nil
+}
+
+// validateDatastoreClusterSDRSScheduleTime validates a time of day in the
+// format of datastoreClusterSDRSScheduleTimeFormat.
+func validateDatastoreClusterSDRSScheduleTime(v interface{}, k string) ([]string, []error) {
+	if _, _, err := parseDatastoreClusterSDRSScheduleTime(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// parseDatastoreClusterSDRSScheduleTime parses a time of day in the format of
+// datastoreClusterSDRSScheduleTimeFormat into its hour and minute.
+func parseDatastoreClusterSDRSScheduleTime(s string) (int32, int32, error) {
+	t, err := time.Parse(datastoreClusterSDRSScheduleTimeFormat, s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a valid time of day in HH:MM format", s)
+	}
+	return int32(t.Hour()), int32(t.Minute()), nil
+}
+
+// splitDatastoreClusterSDRSScheduleID splits a
+// vsphere_datastore_cluster_sdrs_schedule resource ID into its counterparts:
+// the IDs of the scheduled tasks that start and end the window.
+func splitDatastoreClusterSDRSScheduleID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[0], s[1], nil
+}
+
+// resourceVSphereDatastoreClusterSDRSScheduleIDString prints a friendly string
+// for the vsphere_datastore_cluster_sdrs_schedule resource.
+func resourceVSphereDatastoreClusterSDRSScheduleIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereDatastoreClusterSDRSScheduleName)
+}
@@ -0,0 +1,181 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/scheduledtask"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereDatastoreClusterSDRSSchedule_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDatastoreClusterPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDatastoreClusterSDRSScheduleCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDatastoreClusterSDRSScheduleConfigDaily(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDatastoreClusterSDRSScheduleCheckExists(true),
+					testAccResourceVSphereDatastoreClusterSDRSScheduleCheckWindow("22:00", "06:00", nil, nil),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDatastoreClusterSDRSScheduleConfigWeekend(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDatastoreClusterSDRSScheduleCheckExists(true),
+					testAccResourceVSphereDatastoreClusterSDRSScheduleCheckWindow(
+						"20:00",
+						"04:30",
+						[]string{"sunday", "saturday"},
+						[]string{"sunday", "monday"},
+					),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereDatastoreClusterSDRSScheduleCheckExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		_, _, err := testGetDatastoreClusterSDRSScheduleTasks(s, "schedule")
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return errors.New("expected scheduled tasks to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterSDRSScheduleCheckWindow(startTime, endTime string, startDays, endDays []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		start, end, err := testGetDatastoreClusterSDRSScheduleTasks(s, "schedule")
+		if err != nil {
+			return err
+		}
+		for _, tc := range []struct {
+			info         types.ScheduledTaskInfo
+			expectedTime string
+			expectedDays []string
+		}{
+			{info: start, expectedTime: startTime, expectedDays: startDays},
+			{info: end, expectedTime: endTime, expectedDays: endDays},
+		} {
+			actualTime, actualDays, err := flattenDatastoreClusterSDRSScheduleScheduler(tc.info.Scheduler)
+			if err != nil {
+				return err
+			}
+			if tc.expectedTime != actualTime {
+				return fmt.Errorf("%s: expected time to be %q, got %q", tc.info.Name, tc.expectedTime, actualTime)
+			}
+			if fmt.Sprint(tc.expectedDays) != fmt.Sprint(actualDays) {
+				return fmt.Errorf("%s: expected days to be %v, got %v", tc.info.Name, tc.expectedDays, actualDays)
+			}
+		}
+		return nil
+	}
+}
+
+// testGetDatastoreClusterSDRSScheduleTasks is a convenience method to fetch
+// the scheduled tasks of a vsphere_datastore_cluster_sdrs_schedule resource
+// by resource name.
+func testGetDatastoreClusterSDRSScheduleTasks(s *terraform.State, resourceName string) (types.ScheduledTaskInfo, types.ScheduledTaskInfo, error) {
+	var start, end types.ScheduledTaskInfo
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("%s.%s", resourceVSphereDatastoreClusterSDRSScheduleName, resourceName))
+	if err != nil {
+		return start, end, err
+	}
+	startID, endID, err := splitDatastoreClusterSDRSScheduleID(vars.resourceID)
+	if err != nil {
+		return start, end, err
+	}
+	startProps, err := scheduledtask.Properties(vars.client, startID)
+	if err != nil {
+		return start, end, err
+	}
+	endProps, err := scheduledtask.Properties(vars.client, endID)
+	if err != nil {
+		return start, end, err
+	}
+	return startProps.Info, endProps.Info, nil
+}
+
+func testAccResourceVSphereDatastoreClusterSDRSScheduleConfigDaily() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name                  = "terraform-datastore-cluster-test"
+  datacenter_id         = "${data.vsphere_datacenter.dc.id}"
+  sdrs_enabled          = true
+  sdrs_automation_level = "manual"
+
+  lifecycle {
+    ignore_changes = ["sdrs_automation_level"]
+  }
+}
+
+resource "vsphere_datastore_cluster_sdrs_schedule" "schedule" {
+  name                 = "terraform-test-sdrs-schedule"
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+  start_time           = "22:00"
+  end_time             = "06:00"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterSDRSScheduleConfigWeekend() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name                  = "terraform-datastore-cluster-test"
+  datacenter_id         = "${data.vsphere_datacenter.dc.id}"
+  sdrs_enabled          = true
+  sdrs_automation_level = "manual"
+
+  lifecycle {
+    ignore_changes = ["sdrs_automation_level"]
+  }
+}
+
+resource "vsphere_datastore_cluster_sdrs_schedule" "schedule" {
+  name                 = "terraform-test-sdrs-schedule"
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+  start_time           = "20:00"
+  end_time             = "04:30"
+  days                 = ["saturday", "sunday"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
@@ -0,0 +1,294 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereDatastoreClusterVMAntiAffinityRuleName = "vsphere_datastore_cluster_vm_anti_affinity_rule"
+
+func resourceVSphereDatastoreClusterVMAntiAffinityRule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereDatastoreClusterVMAntiAffinityRuleCreate,
+		Read:   resourceVSphereDatastoreClusterVMAntiAffinityRuleRead,
+		Update: resourceVSphereDatastoreClusterVMAntiAffinityRuleUpdate,
+		Delete: resourceVSphereDatastoreClusterVMAntiAffinityRuleDelete,
+
+		Schema: map[string]*schema.Schema{
+			"datastore_cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the datastore cluster to put the rule in.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the rule.",
+			},
+			"virtual_machine_ids": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    2,
+				Description: "The UUIDs of the virtual machines to keep on separate datastores.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Enable this rule.",
+			},
+			"mandatory": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, prevents any virtual machine operations that may violate this rule.",
+			},
+		},
+	}
+}
+
+func resourceVSphereDatastoreClusterVMAntiAffinityRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	pod, err := resourceVSphereDatastoreClusterVMAntiAffinityRulePod(d, client)
+	if err != nil {
+		return err
+	}
+
+	name := d.Get("name").(string)
+	existing, err := storagepod.AntiAffinityRuleByName(pod, name)
+	if err != nil {
+		return fmt.Errorf("error checking for existing rules: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("datastore cluster %q already has a rule named %q", pod.InventoryPath, name)
+	}
+
+	info, err := expandClusterAntiAffinityRuleSpec(d, client)
+	if err != nil {
+		return err
+	}
+	spec := types.ClusterRuleSpec{
+		ArrayUpdateSpec: types.ArrayUpdateSpec{
+			Operation: types.ArrayUpdateOperationAdd,
+		},
+		Info: info,
+	}
+	if err := storagepod.ApplyDRSRule(client, pod, spec); err != nil {
+		return viapi.NewDiagnostic(err, "error creating rule")
+	}
+
+	// The key of the new rule is assigned by vSphere, so we need to look the
+	// rule up by name to get it.
+	rule, err := storagepod.AntiAffinityRuleByName(pod, name)
+	if err != nil {
+		return fmt.Errorf("error fetching new rule: %s", err)
+	}
+	if rule == nil {
+		return fmt.Errorf("rule %q not found on datastore cluster %q after creation", name, pod.InventoryPath)
+	}
+	d.SetId(fmt.Sprintf("%s:%d", pod.Reference().Value, rule.Key))
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+	return resourceVSphereDatastoreClusterVMAntiAffinityRuleRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterVMAntiAffinityRuleRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	podID, key, err := splitDatastoreClusterVMAntiAffinityRuleID(d.Id())
+	if err != nil {
+		return err
+	}
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	pod, err := storagepod.FromID(client, podID)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Datastore cluster is gone, removing rule from state", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+
+	rule, err := storagepod.AntiAffinityRule(pod, key)
+	if err != nil {
+		return fmt.Errorf("error fetching rule: %s", err)
+	}
+	if rule == nil {
+		log.Printf("[DEBUG] %s: Rule not found, removing from state", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("datastore_cluster_id", podID)
+	if err := flattenClusterAntiAffinityRuleSpec(d, client, rule); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+	return nil
+}
+
+func resourceVSphereDatastoreClusterVMAntiAffinityRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	_, key, err := splitDatastoreClusterVMAntiAffinityRuleID(d.Id())
+	if err != nil {
+		return err
+	}
+	pod, err := resourceVSphereDatastoreClusterVMAntiAffinityRulePod(d, client)
+	if err != nil {
+		return err
+	}
+
+	info, err := expandClusterAntiAffinityRuleSpec(d, client)
+	if err != nil {
+		return err
+	}
+	info.Key = key
+	spec := types.ClusterRuleSpec{
+		ArrayUpdateSpec: types.ArrayUpdateSpec{
+			Operation: types.ArrayUpdateOperationEdit,
+		},
+		Info: info,
+	}
+	if err := storagepod.ApplyDRSRule(client, pod, spec); err != nil {
+		return fmt.Errorf("error updating rule: %s", err)
+	}
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d))
+	return resourceVSphereDatastoreClusterVMAntiAffinityRuleRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterVMAntiAffinityRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	resourceIDString := resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d)
+	log.Printf("[DEBUG] %s: Beginning delete", resourceIDString)
+	client := meta.(*VSphereClient).vimClient
+	_, key, err := splitDatastoreClusterVMAntiAffinityRuleID(d.Id())
+	if err != nil {
+		return err
+	}
+	pod, err := resourceVSphereDatastoreClusterVMAntiAffinityRulePod(d, client)
+	if err != nil {
+		return err
+	}
+
+	spec := types.ClusterRuleSpec{
+		ArrayUpdateSpec: types.ArrayUpdateSpec{
+			Operation: types.ArrayUpdateOperationRemove,
+			RemoveKey: key,
+		},
+	}
+	if err := storagepod.ApplyDRSRule(client, pod, spec); err != nil {
+		return viapi.NewDiagnostic(err, "error deleting rule")
+	}
+
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceIDString)
+	return nil
+}
+
+// resourceVSphereDatastoreClusterVMAntiAffinityRulePod loads the datastore
+// cluster referenced in datastore_cluster_id.
+func resourceVSphereDatastoreClusterVMAntiAffinityRulePod(d *schema.ResourceData, client *govmomi.Client) (*object.StoragePod, error) {
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	pod, err := storagepod.FromID(client, d.Get("datastore_cluster_id").(string))
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	return pod, nil
+}
+
+// expandClusterAntiAffinityRuleSpec reads certain ResourceData keys and
+// returns a ClusterAntiAffinityRuleSpec. The virtual machine UUIDs in the
+// resource are translated to managed object references.
+func expandClusterAntiAffinityRuleSpec(d *schema.ResourceData, client *govmomi.Client) (*types.ClusterAntiAffinityRuleSpec, error) {
+	var refs []types.ManagedObjectReference
+	for _, v := range d.Get("virtual_machine_ids").(*schema.Set).List() {
+		vm, err := virtualmachine.FromUUID(client, v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate virtual machine with UUID %q: %s", v.(string), err)
+		}
+		refs = append(refs, vm.Reference())
+	}
+
+	obj := &types.ClusterAntiAffinityRuleSpec{
+		ClusterRuleInfo: types.ClusterRuleInfo{
+			Name:        d.Get("name").(string),
+			Enabled:     structure.GetBool(d, "enabled"),
+			Mandatory:   structure.GetBool(d, "mandatory"),
+			UserCreated: structure.BoolPtr(true),
+		},
+		Vm: refs,
+	}
+	return obj, nil
+}
+
+// flattenClusterAntiAffinityRuleSpec saves a ClusterAntiAffinityRuleSpec
+// into the supplied ResourceData. The managed object references of the
+// virtual machines in the rule are translated to UUIDs.
+func flattenClusterAntiAffinityRuleSpec(d *schema.ResourceData, client *govmomi.Client, obj *types.ClusterAntiAffinityRuleSpec) error {
+	var uuids []string
+	for _, ref := range obj.Vm {
+		vm, err := virtualmachine.FromMOID(client, ref.Value)
+		if err != nil {
+			return fmt.Errorf("cannot locate virtual machine %q: %s", ref.Value, err)
+		}
+		props, err := virtualmachine.Properties(vm)
+		if err != nil {
+			return fmt.Errorf("error fetching properties for virtual machine %q: %s", ref.Value, err)
+		}
+		uuids = append(uuids, props.Config.Uuid)
+	}
+
+	d.Set("name", obj.Name)
+	if err := structure.SetBoolPtr(d, "enabled", obj.Enabled); err != nil {
+		return err
+	}
+	if err := structure.SetBoolPtr(d, "mandatory", obj.Mandatory); err != nil {
+		return err
+	}
+	if err := d.Set("virtual_machine_ids", uuids); err != nil {
+		return fmt.Errorf("error setting virtual_machine_ids: %s", err)
+	}
+	return nil
+}
+
+// splitDatastoreClusterVMAntiAffinityRuleID splits a
+// vsphere_datastore_cluster_vm_anti_affinity_rule resource ID into its
+// counterparts: the StoragePod ID and the rule key.
+func splitDatastoreClusterVMAntiAffinityRuleID(raw string) (string, int32, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return "", 0, fmt.Errorf("corrupt ID: %s", raw)
+	}
+	key, err := strconv.ParseInt(s[1], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[0], int32(key), nil
+}
+
+// resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString prints a friendly
+// string for the vsphere_datastore_cluster_vm_anti_affinity_rule resource.
+func resourceVSphereDatastoreClusterVMAntiAffinityRuleIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereDatastoreClusterVMAntiAffinityRuleName)
+}
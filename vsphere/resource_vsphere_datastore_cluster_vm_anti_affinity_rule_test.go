@@ -0,0 +1,222 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereDatastoreClusterVMAntiAffinityRule_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDatastoreClusterVMAntiAffinityRulePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleCheckExists(true),
+					testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleCheckEnabled(true),
+					resource.TestCheckResourceAttr("vsphere_datastore_cluster_vm_anti_affinity_rule.rule", "virtual_machine_ids.#", "2"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleCheckExists(true),
+					testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleCheckEnabled(false),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereDatastoreClusterVMAntiAffinityRulePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_datastore_cluster_vm_anti_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_datastore_cluster_vm_anti_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_datastore_cluster_vm_anti_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL_PXE") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL_PXE to run vsphere_datastore_cluster_vm_anti_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NAS_HOST") == "" {
+		t.Skip("set VSPHERE_NAS_HOST to run vsphere_datastore_cluster_vm_anti_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NFS_PATH") == "" {
+		t.Skip("set VSPHERE_NFS_PATH to run vsphere_datastore_cluster_vm_anti_affinity_rule acceptance tests")
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleCheckExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rule, err := testGetDatastoreClusterVMAntiAffinityRule(s, "rule")
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) && expected == false {
+				// Expected missing, as the datastore cluster is gone too
+				return nil
+			}
+			return err
+		}
+		switch {
+		case rule == nil && expected:
+			return errors.New("expected rule to exist")
+		case rule != nil && !expected:
+			return errors.New("expected rule to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleCheckEnabled(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rule, err := testGetDatastoreClusterVMAntiAffinityRule(s, "rule")
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return errors.New("rule not found")
+		}
+		actual := rule.Enabled != nil && *rule.Enabled
+		if expected != actual {
+			return fmt.Errorf("expected enabled to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testGetDatastoreClusterVMAntiAffinityRule is a convenience method to fetch
+// a datastore cluster VM anti-affinity rule by resource name.
+func testGetDatastoreClusterVMAntiAffinityRule(s *terraform.State, resourceName string) (*types.ClusterAntiAffinityRuleSpec, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("%s.%s", resourceVSphereDatastoreClusterVMAntiAffinityRuleName, resourceName))
+	if err != nil {
+		return nil, err
+	}
+	podID, key, err := splitDatastoreClusterVMAntiAffinityRuleID(vars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+	pod, err := storagepod.FromID(vars.client, podID)
+	if err != nil {
+		return nil, err
+	}
+	return storagepod.AntiAffinityRule(pod, key)
+}
+
+func testAccResourceVSphereDatastoreClusterVMAntiAffinityRuleConfig(enabled bool) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "nfs_host" {
+  default = "%s"
+}
+
+variable "nfs_path" {
+  default = "%s"
+}
+
+variable "rule_enabled" {
+  default = "%t"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  sdrs_enabled  = true
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name                 = "terraform-test-nas"
+  host_system_ids      = ["${data.vsphere_host.esxi_host.id}"]
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  count            = 2
+  name             = "terraform-test-${count.index}"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${vsphere_nas_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = -1
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 1
+  }
+}
+
+resource "vsphere_datastore_cluster_vm_anti_affinity_rule" "rule" {
+  name                 = "terraform-test-datastore-cluster-anti-affinity-rule"
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+  virtual_machine_ids  = ["${vsphere_virtual_machine.vm.*.id}"]
+  enabled              = "${var.rule_enabled}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_NAS_HOST"),
+		os.Getenv("VSPHERE_NFS_PATH"),
+		enabled,
+	)
+}
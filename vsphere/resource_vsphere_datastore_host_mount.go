@@ -0,0 +1,254 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereDatastoreHostMountName = "vsphere_datastore_host_mount"
+
+func resourceVSphereDatastoreHostMount() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereDatastoreHostMountCreate,
+		Read:   resourceVSphereDatastoreHostMountRead,
+		Update: resourceVSphereDatastoreHostMountUpdate,
+		Delete: resourceVSphereDatastoreHostMountDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDatastoreHostMountImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the VMFS datastore to mount or unmount.",
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the host to mount or unmount the datastore on.",
+			},
+			"mounted": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the datastore is mounted on the host. The datastore is unmounted when the resource is destroyed.",
+			},
+			"accessible": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the datastore is accessible from the host.",
+			},
+		},
+	}
+}
+
+func resourceVSphereDatastoreHostMountCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereDatastoreHostMountIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, resourceVSphereDatastoreHostMountName); err != nil {
+		return err
+	}
+	dsID := d.Get("datastore_id").(string)
+	hsID := d.Get("host_system_id").(string)
+	if err := resourceVSphereDatastoreHostMountApply(client, dsID, hsID, d.Get("mounted").(bool)); err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s:%s", dsID, hsID))
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereDatastoreHostMountIDString(d))
+	return resourceVSphereDatastoreHostMountRead(d, meta)
+}
+
+func resourceVSphereDatastoreHostMountRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereDatastoreHostMountIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dsID, hsID, err := splitDatastoreHostMountID(d.Id())
+	if err != nil {
+		return err
+	}
+	props, err := datastoreHostMountProperties(client, dsID)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Datastore not found, removing from state", resourceVSphereDatastoreHostMountIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	mount := datastoreHostMountInfo(props, hsID)
+	if mount == nil {
+		// The host cannot see the volume any more, for example because the LUN
+		// is not presented to it, so there is nothing to mount.
+		log.Printf("[DEBUG] %s: Datastore not presented to host, removing from state", resourceVSphereDatastoreHostMountIDString(d))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("datastore_id", dsID)
+	d.Set("host_system_id", hsID)
+	d.Set("mounted", mount.Mounted == nil || *mount.Mounted)
+	d.Set("accessible", mount.Accessible == nil || *mount.Accessible)
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereDatastoreHostMountIDString(d))
+	return nil
+}
+
+func resourceVSphereDatastoreHostMountUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereDatastoreHostMountIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	dsID, hsID, err := splitDatastoreHostMountID(d.Id())
+	if err != nil {
+		return err
+	}
+	if err := resourceVSphereDatastoreHostMountApply(client, dsID, hsID, d.Get("mounted").(bool)); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereDatastoreHostMountIDString(d))
+	return resourceVSphereDatastoreHostMountRead(d, meta)
+}
+
+func resourceVSphereDatastoreHostMountDelete(d *schema.ResourceData, meta interface{}) error {
+	resourceIDString := resourceVSphereDatastoreHostMountIDString(d)
+	log.Printf("[DEBUG] %s: Beginning delete", resourceIDString)
+	client := meta.(*VSphereClient).vimClient
+	dsID, hsID, err := splitDatastoreHostMountID(d.Id())
+	if err != nil {
+		return err
+	}
+	if err := resourceVSphereDatastoreHostMountApply(client, dsID, hsID, false); err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceIDString)
+	return nil
+}
+
+func resourceVSphereDatastoreHostMountImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if _, _, err := splitDatastoreHostMountID(d.Id()); err != nil {
+		return nil, fmt.Errorf("please supply the ID in the following format: DATASTOREID:HOSTID")
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereDatastoreHostMountApply mounts or unmounts the VMFS volume of
+// a datastore on a host. Nothing is done if the volume is already in the
+// requested state. The volume is only unmounted if no powered on virtual
+// machine on the host uses the datastore.
+func resourceVSphereDatastoreHostMountApply(client *govmomi.Client, dsID, hsID string, mounted bool) error {
+	props, err := datastoreHostMountProperties(client, dsID)
+	if err != nil {
+		return err
+	}
+	info, ok := props.Info.(*types.VmfsDatastoreInfo)
+	if !ok || info.Vmfs == nil {
+		return fmt.Errorf("datastore %q is not a VMFS datastore. NFS datastores are mounted on hosts through host_system_ids in vsphere_nas_datastore", props.Name)
+	}
+	mount := datastoreHostMountInfo(props, hsID)
+	if mount == nil {
+		return fmt.Errorf("datastore %q is not presented to host %q. Present its LUN to the host and rescan the host first, for example with vsphere_host_storage_rescan", props.Name, hostsystem.NameOrID(client, hsID))
+	}
+	if (mount.Mounted == nil || *mount.Mounted) == mounted {
+		return nil
+	}
+
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host storage system: %s", err)
+	}
+	if mounted {
+		log.Printf("[DEBUG] Mounting datastore %q on host %q", props.Name, hsID)
+		if err := mountVmfsVolume(ss, info.Vmfs.Uuid); err != nil {
+			return viapi.NewDiagnostic(err, "error mounting datastore %q", props.Name)
+		}
+		return nil
+	}
+	if err := datastoreHostMountCheckUnmount(client, props, hsID); err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Unmounting datastore %q from host %q", props.Name, hsID)
+	if err := unmountVmfsVolume(ss, info.Vmfs.Uuid); err != nil {
+		return viapi.NewDiagnostic(err, "error unmounting datastore %q", props.Name)
+	}
+	return nil
+}
+
+// datastoreHostMountCheckUnmount returns an error naming the powered on
+// virtual machines on a host that use a datastore, if there are any.
+func datastoreHostMountCheckUnmount(client *govmomi.Client, props *mo.Datastore, hsID string) error {
+	if len(props.Vm) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var vms []mo.VirtualMachine
+	if err := client.PropertyCollector().Retrieve(ctx, props.Vm, []string{"name", "runtime.host", "runtime.powerState"}, &vms); err != nil {
+		return fmt.Errorf("error fetching virtual machines on datastore %q: %s", props.Name, err)
+	}
+	var running []string
+	for _, vm := range vms {
+		if vm.Runtime.Host == nil || vm.Runtime.Host.Value != hsID {
+			continue
+		}
+		if vm.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+			running = append(running, vm.Name)
+		}
+	}
+	if len(running) > 0 {
+		return fmt.Errorf("cannot unmount datastore %q from host %q: virtual machines on the host use it: %s. Power them off or migrate them first", props.Name, hostsystem.NameOrID(client, hsID), strings.Join(running, ", "))
+	}
+	return nil
+}
+
+// datastoreHostMountProperties loads the properties of a datastore by ID.
+func datastoreHostMountProperties(client *govmomi.Client, dsID string) (*mo.Datastore, error) {
+	ds, err := datastore.FromID(client, dsID)
+	if err != nil {
+		return nil, err
+	}
+	props, err := datastore.Properties(ds)
+	if err != nil {
+		return nil, fmt.Errorf("could not get properties for datastore: %s", err)
+	}
+	return props, nil
+}
+
+// datastoreHostMountInfo returns the mount information of a datastore for a
+// host, or nil if the datastore is not presented to the host.
+func datastoreHostMountInfo(props *mo.Datastore, hsID string) *types.HostMountInfo {
+	for _, mount := range props.Host {
+		if mount.Key.Value == hsID {
+			return &mount.MountInfo
+		}
+	}
+	return nil
+}
+
+// splitDatastoreHostMountID splits a vsphere_datastore_host_mount resource ID
+// into its counterparts: the datastore ID and the host ID.
+func splitDatastoreHostMountID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[0], s[1], nil
+}
+
+// resourceVSphereDatastoreHostMountIDString prints a friendly string for the
+// vsphere_datastore_host_mount resource.
+func resourceVSphereDatastoreHostMountIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereDatastoreHostMountName)
+}
@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereDatastoreHostMount_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDatastoreHostMountPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDatastoreHostMountConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDatastoreHostMountMatches(false),
+				),
+			},
+			{
+				Config: testAccResourceVSphereDatastoreHostMountConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDatastoreHostMountMatches(true),
+					resource.TestCheckResourceAttr("vsphere_datastore_host_mount.mount", "accessible", "true"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_datastore_host_mount.mount",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereDatastoreHostMountPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_datastore_host_mount acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_datastore_host_mount acceptance tests")
+	}
+	if os.Getenv("VSPHERE_VMFS_DATASTORE") == "" {
+		t.Skip("set VSPHERE_VMFS_DATASTORE to a shared VMFS datastore that no running VM on VSPHERE_ESXI_HOST uses to run vsphere_datastore_host_mount acceptance tests")
+	}
+}
+
+func testAccResourceVSphereDatastoreHostMountMatches(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_datastore_host_mount.mount"]
+		if !ok {
+			return fmt.Errorf("vsphere_datastore_host_mount.mount not found in state")
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dsID, hsID, err := splitDatastoreHostMountID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		props, err := datastoreHostMountProperties(client, dsID)
+		if err != nil {
+			return err
+		}
+		mount := datastoreHostMountInfo(props, hsID)
+		if mount == nil {
+			return fmt.Errorf("datastore %q is not presented to host %q", dsID, hsID)
+		}
+		actual := mount.Mounted == nil || *mount.Mounted
+		if actual != expected {
+			return fmt.Errorf("expected datastore mounted to be %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreHostMountConfig(mounted bool) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_datastore_host_mount" "mount" {
+  datastore_id   = "${data.vsphere_datastore.datastore.id}"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  mounted        = %t
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_VMFS_DATASTORE"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		mounted,
+	)
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/dvportgroup"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -26,6 +28,13 @@ func resourceVSphereDistributedPortGroup() *schema.Resource {
 			Description: "The generated UUID of the portgroup.",
 			Computed:    true,
 		},
+		"port_override": schemaDVPortOverride(),
+		"port_keys": {
+			Type:        schema.TypeList,
+			Description: "The keys of the ports in the portgroup.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
 		// Tagging
 		vSphereTagAttributeKey: tagsSchema(),
 		// Custom Attributes
@@ -33,12 +42,14 @@ func resourceVSphereDistributedPortGroup() *schema.Resource {
 	}
 
 	structure.MergeSchema(s, schemaDVPortgroupConfigSpec())
+	s["name"].DiffSuppressFunc = distributedPortGroupNameRule.diffSuppressFunc()
 
 	return &schema.Resource{
-		Create: resourceVSphereDistributedPortGroupCreate,
-		Read:   resourceVSphereDistributedPortGroupRead,
-		Update: resourceVSphereDistributedPortGroupUpdate,
-		Delete: resourceVSphereDistributedPortGroupDelete,
+		Create:        resourceVSphereDistributedPortGroupCreate,
+		Read:          resourceVSphereDistributedPortGroupRead,
+		Update:        resourceVSphereDistributedPortGroupUpdate,
+		Delete:        resourceVSphereDistributedPortGroupDelete,
+		CustomizeDiff: resourceVSphereDistributedPortGroupCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereDistributedPortGroupImport,
 		},
@@ -48,6 +59,9 @@ func resourceVSphereDistributedPortGroup() *schema.Resource {
 
 func resourceVSphereDistributedPortGroupCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := distributedPortGroupNameRule.apply(d, meta); err != nil {
+		return err
+	}
 	if err := viapi.ValidateVirtualCenter(client); err != nil {
 		return err
 	}
@@ -70,7 +84,7 @@ func resourceVSphereDistributedPortGroupCreate(d *schema.ResourceData, meta inte
 	spec := expandDVPortgroupConfigSpec(d)
 	task, err := dvportgroup.Create(client, dvs, spec)
 	if err != nil {
-		return fmt.Errorf("error creating portgroup: %s", err)
+		return viapi.NewDiagnostic(err, "error creating portgroup")
 	}
 	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer tcancel()
@@ -90,6 +104,12 @@ func resourceVSphereDistributedPortGroupCreate(d *schema.ResourceData, meta inte
 	d.SetId(pg.Reference().Value)
 	d.Set("key", props.Key)
 
+	if d.Get("port_override").(*schema.Set).Len() > 0 {
+		if err := resourceVSphereDistributedPortGroupApplyPortOverrides(d, client, props); err != nil {
+			return err
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, object.NewReference(client.Client, pg.Reference())); err != nil {
@@ -123,10 +143,16 @@ func resourceVSphereDistributedPortGroupRead(d *schema.ResourceData, meta interf
 	}
 
 	d.Set("key", props.Key)
+	if err := d.Set("port_keys", props.PortKeys); err != nil {
+		return fmt.Errorf("error setting port_keys: %s", err)
+	}
 
 	if err := flattenDVPortgroupConfigInfo(d, props.Config); err != nil {
 		return err
 	}
+	if err := resourceVSphereDistributedPortGroupReadPortOverrides(d, client, props); err != nil {
+		return err
+	}
 
 	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
 		if err := readTagsForResource(tagsClient, pg, d); err != nil {
@@ -143,6 +169,9 @@ func resourceVSphereDistributedPortGroupRead(d *schema.ResourceData, meta interf
 
 func resourceVSphereDistributedPortGroupUpdate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := distributedPortGroupNameRule.apply(d, meta); err != nil {
+		return err
+	}
 	if err := viapi.ValidateVirtualCenter(client); err != nil {
 		return err
 	}
@@ -166,13 +195,22 @@ func resourceVSphereDistributedPortGroupUpdate(d *schema.ResourceData, meta inte
 	defer cancel()
 	task, err := pg.Reconfigure(ctx, spec)
 	if err != nil {
-		return fmt.Errorf("error reconfiguring portgroup: %s", err)
+		return viapi.NewDiagnostic(err, "error reconfiguring portgroup")
 	}
 	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer tcancel()
 	if err := task.Wait(tctx); err != nil {
 		return fmt.Errorf("error waiting for portgroup update to complete: %s", err)
 	}
+	if d.HasChange("port_override") {
+		props, err := dvportgroup.Properties(pg)
+		if err != nil {
+			return fmt.Errorf("error fetching portgroup properties: %s", err)
+		}
+		if err := resourceVSphereDistributedPortGroupApplyPortOverrides(d, client, props); err != nil {
+			return err
+		}
+	}
 
 	// Apply any pending tags now
 	if tagsClient != nil {
@@ -206,7 +244,7 @@ func resourceVSphereDistributedPortGroupDelete(d *schema.ResourceData, meta inte
 	defer cancel()
 	task, err := pg.Destroy(ctx)
 	if err != nil {
-		return fmt.Errorf("error deleting portgroup: %s", err)
+		return viapi.NewDiagnostic(err, "error deleting portgroup")
 	}
 	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer tcancel()
@@ -253,3 +291,57 @@ func resourceVSphereDistributedPortGroupImport(d *schema.ResourceData, meta inte
 
 	return []*schema.ResourceData{d}, nil
 }
+
+func resourceVSphereDistributedPortGroupCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := distributedPortGroupNameRule.customizeDiff(d, meta); err != nil {
+		return err
+	}
+	return validateDVPortOverrides(d)
+}
+
+// resourceVSphereDistributedPortGroupApplyPortOverrides applies the changes to
+// port_override to the ports of the portgroup.
+func resourceVSphereDistributedPortGroupApplyPortOverrides(d *schema.ResourceData, client *govmomi.Client, props *mo.DistributedVirtualPortgroup) error {
+	specs := expandDVPortOverrides(d)
+	if len(specs) < 1 {
+		return nil
+	}
+	dvs, err := dvsFromMOID(client, props.Config.DistributedVirtualSwitch.Value)
+	if err != nil {
+		return fmt.Errorf("error locating DVS of portgroup: %s", err)
+	}
+	inPortgroup := make(map[string]bool)
+	for _, key := range props.PortKeys {
+		inPortgroup[key] = true
+	}
+	for _, spec := range specs {
+		if !inPortgroup[spec.Key] {
+			return fmt.Errorf("port_override: port %q is not in portgroup %q", spec.Key, props.Name)
+		}
+	}
+	if err := reconfigureDVPorts(client, dvs, specs); err != nil {
+		return viapi.NewDiagnostic(err, "error reconfiguring ports")
+	}
+	return nil
+}
+
+// resourceVSphereDistributedPortGroupReadPortOverrides reads the settings of
+// the ports in port_override.
+func resourceVSphereDistributedPortGroupReadPortOverrides(d *schema.ResourceData, client *govmomi.Client, props *mo.DistributedVirtualPortgroup) error {
+	keys := dvPortOverrideKeys(d)
+	if len(keys) < 1 {
+		return nil
+	}
+	dvs, err := dvsFromMOID(client, props.Config.DistributedVirtualSwitch.Value)
+	if err != nil {
+		return fmt.Errorf("error locating DVS of portgroup: %s", err)
+	}
+	ports, err := fetchDVPorts(client, dvs, &types.DistributedVirtualSwitchPortCriteria{
+		PortgroupKey: []string{props.Key},
+		PortKey:      keys,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching ports: %s", err)
+	}
+	return flattenDVPortOverrides(d, ports)
+}
@@ -89,6 +89,27 @@ func TestAccResourceVSphereDistributedPortGroup_overrideVlan(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereDistributedPortGroup_staticPorts(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDistributedPortGroupPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereDistributedPortGroupConfigStaticPorts(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereDistributedPortGroupExists(true),
+					resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "number_of_ports", "4"),
+					resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "port_keys.#", "4"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereDistributedPortGroup_singleTag(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -466,6 +487,35 @@ resource "vsphere_distributed_port_group" "pg" {
 	)
 }
 
+func testAccResourceVSphereDistributedPortGroupConfigStaticPorts() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.id}"
+  type                            = "earlyBinding"
+  number_of_ports                 = 4
+  auto_expand                     = false
+  vlan_override_allowed           = true
+  block_override_allowed          = true
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
 func testAccResourceVSphereDistributedPortGroupConfigSingleTag() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -46,15 +46,29 @@ func resourceVSphereDistributedVirtualSwitch() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereDistributedVirtualSwitchImport,
 		},
-		Schema: s,
+		CustomizeDiff: resourceVSphereDistributedVirtualSwitchCustomizeDiff,
+		Schema:        s,
 	}
 }
 
+// resourceVSphereDistributedVirtualSwitchCustomizeDiff checks at plan time
+// that new switches can be created on the connection, so that a missing
+// license does not fail the apply part way through.
+func resourceVSphereDistributedVirtualSwitchCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" {
+		return nil
+	}
+	return viapi.CapabilityDistributedVirtualSwitch.Validate(meta.(*VSphereClient).vimClient)
+}
+
 func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 	if err := viapi.ValidateVirtualCenter(client); err != nil {
 		return err
 	}
+	if err := vmcCheckHostOperation(meta, "vsphere_distributed_virtual_switch"); err != nil {
+		return err
+	}
 	tagsClient, err := tagsClientIfDefined(d, meta)
 	if err != nil {
 		return err
@@ -79,7 +93,7 @@ func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta
 	spec := expandDVSCreateSpec(d)
 	task, err := fo.CreateDVS(ctx, spec)
 	if err != nil {
-		return fmt.Errorf("error creating DVS: %s", err)
+		return viapi.NewDiagnostic(err, "error creating DVS")
 	}
 	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer tcancel()
@@ -210,6 +224,15 @@ func resourceVSphereDistributedVirtualSwitchUpdate(d *schema.ResourceData, meta
 		if nvi < ovi {
 			return fmt.Errorf("downgrading dvSwitches are not allowed (old: %s new: %s)", old, new)
 		}
+		// Check the current members of the switch and the hosts that are about to
+		// be added to it, as a switch cannot be downgraded after an upgrade.
+		hsIDs, err := resourceVSphereDistributedVirtualSwitchHostIDs(d, dvs)
+		if err != nil {
+			return err
+		}
+		if err := checkDVSUpgradeCompatibility(client, new.(string), hsIDs); err != nil {
+			return fmt.Errorf("cannot upgrade DVS: %s", err)
+		}
 		if err := upgradeDVS(client, dvs, new.(string)); err != nil {
 			return fmt.Errorf("could not upgrade DVS: %s", err)
 		}
@@ -265,7 +288,7 @@ func resourceVSphereDistributedVirtualSwitchDelete(d *schema.ResourceData, meta
 	defer cancel()
 	task, err := dvs.Destroy(ctx)
 	if err != nil {
-		return fmt.Errorf("error deleting DVS: %s", err)
+		return viapi.NewDiagnostic(err, "error deleting DVS")
 	}
 	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer tcancel()
@@ -296,3 +319,29 @@ func resourceVSphereDistributedVirtualSwitchImport(d *schema.ResourceData, meta
 	d.SetId(props.Uuid)
 	return []*schema.ResourceData{d}, nil
 }
+
+// resourceVSphereDistributedVirtualSwitchHostIDs returns the IDs of the
+// current host members of a DVS and of the hosts in the host sub-resources,
+// without duplicates.
+func resourceVSphereDistributedVirtualSwitchHostIDs(d *schema.ResourceData, dvs *object.VmwareDistributedVirtualSwitch) ([]string, error) {
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return nil, fmt.Errorf("could not get DVS properties: %s", err)
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, ref := range props.Summary.HostMember {
+		if !seen[ref.Value] {
+			seen[ref.Value] = true
+			ids = append(ids, ref.Value)
+		}
+	}
+	for _, v := range d.Get("host").(*schema.Set).List() {
+		id := v.(map[string]interface{})["host_system_id"].(string)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,195 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// entityPermissionEntityTypeAllowedValues are the managed object types that
+// permissions can be set on with vsphere_entity_permission.
+var entityPermissionEntityTypeAllowedValues = []string{
+	"Folder",
+	"Datacenter",
+	"ClusterComputeResource",
+	"ComputeResource",
+	"HostSystem",
+	"ResourcePool",
+	"VirtualApp",
+	"VirtualMachine",
+	"Datastore",
+	"StoragePod",
+	"Network",
+	"DistributedVirtualPortgroup",
+	"VmwareDistributedVirtualSwitch",
+}
+
+func resourceVSphereEntityPermission() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereEntityPermissionCreate,
+		Read:   resourceVSphereEntityPermissionRead,
+		Update: resourceVSphereEntityPermissionCreate,
+		Delete: resourceVSphereEntityPermissionDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereEntityPermissionImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"entity_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the entity to set the permission on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"entity_type": {
+				Type:         schema.TypeString,
+				Description:  "The managed object type of the entity, such as Folder or Datacenter.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(entityPermissionEntityTypeAllowedValues, false),
+			},
+			"principal": {
+				Type:        schema.TypeString,
+				Description: "The user or group to grant the role to.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"is_group": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not principal is a group.",
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+			},
+			"role": {
+				Type:        schema.TypeString,
+				Description: "The name of the role to grant, such as ReadOnly or Admin.",
+				Required:    true,
+			},
+			"propagate": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the permission propagates to the objects below the entity.",
+				Optional:    true,
+				Default:     true,
+			},
+		},
+	}
+}
+
+func resourceVSphereEntityPermissionCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	role, err := permissionRoleByName(client, d.Get("role").(string))
+	if err != nil {
+		return err
+	}
+	ref := types.ManagedObjectReference{
+		Type:  d.Get("entity_type").(string),
+		Value: d.Get("entity_id").(string),
+	}
+	principal := d.Get("principal").(string)
+	perm := types.Permission{
+		Principal: principal,
+		Group:     d.Get("is_group").(bool),
+		RoleId:    role.RoleId,
+		Propagate: d.Get("propagate").(bool),
+	}
+	if err := setEntityPermission(client, ref, perm); err != nil {
+		return viapi.NewDiagnostic(err, "error setting permission on %s", ref)
+	}
+	d.SetId(fmt.Sprintf("%s:%s:%s", ref.Type, ref.Value, principal))
+	return resourceVSphereEntityPermissionRead(d, meta)
+}
+
+func resourceVSphereEntityPermissionRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ref, principal, err := splitEntityPermissionID(d.Id())
+	if err != nil {
+		return err
+	}
+	perm, err := entityPermission(client, ref, principal, d.Get("is_group").(bool))
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Entity %s not found, removing permission from state", ref)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching permissions on %s: %s", ref, err)
+	}
+	if perm == nil {
+		log.Printf("[DEBUG] Permission for %q on %s not found, removing from state", principal, ref)
+		d.SetId("")
+		return nil
+	}
+	role, err := permissionRoleByID(client, perm.RoleId)
+	if err != nil {
+		return err
+	}
+	d.Set("entity_id", ref.Value)
+	d.Set("entity_type", ref.Type)
+	d.Set("principal", perm.Principal)
+	d.Set("is_group", perm.Group)
+	d.Set("role", role.Name)
+	d.Set("propagate", perm.Propagate)
+	return nil
+}
+
+func resourceVSphereEntityPermissionDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ref, principal, err := splitEntityPermissionID(d.Id())
+	if err != nil {
+		return err
+	}
+	if err := removeEntityPermission(client, ref, principal, d.Get("is_group").(bool)); err != nil {
+		return viapi.NewDiagnostic(err, "error removing permission from %s", ref)
+	}
+	return nil
+}
+
+// resourceVSphereEntityPermissionImport sets is_group for an imported
+// permission, so that Read looks up the permission of the right principal.
+// The import fails if both a user and a group with the name of the principal
+// have a permission on the entity.
+func resourceVSphereEntityPermissionImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*VSphereClient).vimClient
+	ref, principal, err := splitEntityPermissionID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	perms, err := entityPermissions(client, ref)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching permissions on %s: %s", ref, err)
+	}
+	var found *types.Permission
+	for i := range perms {
+		if perms[i].Principal != principal {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("both a user and a group named %q have permissions on %s, cannot tell which one to import", principal, ref)
+		}
+		found = &perms[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no permission for %q found on %s", principal, ref)
+	}
+	d.Set("is_group", found.Group)
+	return []*schema.ResourceData{d}, nil
+}
+
+// splitEntityPermissionID splits a vsphere_entity_permission resource ID into
+// the reference to the entity and the principal.
+func splitEntityPermissionID(raw string) (types.ManagedObjectReference, string, error) {
+	s := strings.SplitN(raw, ":", 3)
+	if len(s) != 3 || s[0] == "" || s[1] == "" || s[2] == "" {
+		return types.ManagedObjectReference{}, "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return types.ManagedObjectReference{Type: s[0], Value: s[1]}, s[2], nil
+}
@@ -0,0 +1,95 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereEntityPermission_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereEntityPermissionPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereEntityPermissionConfigInherited(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_entity_permission.parent", "role", "ReadOnly"),
+					resource.TestCheckResourceAttr("vsphere_entity_permission.parent", "propagate", "true"),
+					resource.TestCheckResourceAttr("vsphere_entity_permission.child", "role", "NoAccess"),
+					resource.TestCheckResourceAttr("vsphere_entity_permission.child", "propagate", "false"),
+				),
+			},
+			{
+				// The propagated permission on the parent folder must not produce a
+				// diff on the permission for the same principal on the child folder.
+				Config:   testAccResourceVSphereEntityPermissionConfigInherited(),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "vsphere_entity_permission.child",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereEntityPermissionPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_PERMISSION_PRINCIPAL") == "" {
+		t.Skip("set VSPHERE_PERMISSION_PRINCIPAL to run vsphere_entity_permission acceptance tests")
+	}
+}
+
+func testAccResourceVSphereEntityPermissionConfigInherited() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "principal" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_folder" "parent" {
+  path          = "terraform-test-permission-parent"
+  type          = "vm"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_folder" "child" {
+  path          = "${vsphere_folder.parent.path}/terraform-test-permission-child"
+  type          = "vm"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_entity_permission" "parent" {
+  entity_id   = "${vsphere_folder.parent.id}"
+  entity_type = "Folder"
+  principal   = "${var.principal}"
+  role        = "ReadOnly"
+  propagate   = true
+}
+
+resource "vsphere_entity_permission" "child" {
+  entity_id   = "${vsphere_folder.child.id}"
+  entity_type = "Folder"
+  principal   = "${var.principal}"
+  role        = "NoAccess"
+  propagate   = false
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_PERMISSION_PRINCIPAL"),
+	)
+}
@@ -0,0 +1,214 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereEntityPermissions() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereEntityPermissionsCreate,
+		Read:   resourceVSphereEntityPermissionsRead,
+		Update: resourceVSphereEntityPermissionsCreate,
+		Delete: resourceVSphereEntityPermissionsDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"entity_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the entity to manage the permissions of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"entity_type": {
+				Type:         schema.TypeString,
+				Description:  "The managed object type of the entity, such as Folder or Datacenter.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(entityPermissionEntityTypeAllowedValues, false),
+			},
+			"permissions": {
+				Type:        schema.TypeSet,
+				Description: "The complete set of permissions defined on the entity. Permissions on the entity that are not in this set are removed.",
+				Required:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"principal": {
+							Type:        schema.TypeString,
+							Description: "The user or group to grant the role to.",
+							Required:    true,
+						},
+						"is_group": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not principal is a group.",
+							Optional:    true,
+							Default:     false,
+						},
+						"role": {
+							Type:        schema.TypeString,
+							Description: "The name of the role to grant, such as ReadOnly or Admin.",
+							Required:    true,
+						},
+						"propagate": {
+							Type:        schema.TypeBool,
+							Description: "Whether or not the permission propagates to the objects below the entity.",
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVSphereEntityPermissionsCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	ref := types.ManagedObjectReference{
+		Type:  d.Get("entity_type").(string),
+		Value: d.Get("entity_id").(string),
+	}
+	perms, err := expandEntityPermissions(client, d.Get("permissions").(*schema.Set))
+	if err != nil {
+		return err
+	}
+	if err := setEntityPermissions(client, ref, perms); err != nil {
+		return viapi.NewDiagnostic(err, "error setting permissions on %s", ref)
+	}
+	// Remove everything else that is defined on the entity, including
+	// permissions that were added outside of Terraform.
+	current, err := entityPermissions(client, ref)
+	if err != nil {
+		return fmt.Errorf("error fetching permissions on %s: %s", ref, err)
+	}
+	for _, perm := range current {
+		if entityPermissionsHasPrincipal(perms, perm.Principal, perm.Group) {
+			continue
+		}
+		if err := removeEntityPermission(client, ref, perm.Principal, perm.Group); err != nil {
+			return viapi.NewDiagnostic(err, "error removing permission for %q from %s", perm.Principal, ref)
+		}
+	}
+	d.SetId(fmt.Sprintf("%s:%s", ref.Type, ref.Value))
+	return resourceVSphereEntityPermissionsRead(d, meta)
+}
+
+func resourceVSphereEntityPermissionsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ref, err := splitEntityPermissionsID(d.Id())
+	if err != nil {
+		return err
+	}
+	perms, err := entityPermissions(client, ref)
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] Entity %s not found, removing permissions from state", ref)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching permissions on %s: %s", ref, err)
+	}
+	roles, err := permissionRoles(client)
+	if err != nil {
+		return err
+	}
+	var result []interface{}
+	for _, perm := range perms {
+		role := roles.ById(perm.RoleId)
+		if role == nil {
+			return fmt.Errorf("role ID %d not found", perm.RoleId)
+		}
+		result = append(result, map[string]interface{}{
+			"principal": perm.Principal,
+			"is_group":  perm.Group,
+			"role":      role.Name,
+			"propagate": perm.Propagate,
+		})
+	}
+	d.Set("entity_id", ref.Value)
+	d.Set("entity_type", ref.Type)
+	if err := d.Set("permissions", result); err != nil {
+		return fmt.Errorf("error setting permissions: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereEntityPermissionsDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ref, err := splitEntityPermissionsID(d.Id())
+	if err != nil {
+		return err
+	}
+	for _, v := range d.Get("permissions").(*schema.Set).List() {
+		perm := v.(map[string]interface{})
+		principal := perm["principal"].(string)
+		if err := removeEntityPermission(client, ref, principal, perm["is_group"].(bool)); err != nil {
+			return viapi.NewDiagnostic(err, "error removing permission for %q from %s", principal, ref)
+		}
+	}
+	return nil
+}
+
+// expandEntityPermissions reads the permissions attribute of a
+// vsphere_entity_permissions resource into a list of permissions, looking up
+// the role IDs by name.
+func expandEntityPermissions(client *govmomi.Client, s *schema.Set) ([]types.Permission, error) {
+	roles, err := permissionRoles(client)
+	if err != nil {
+		return nil, err
+	}
+	var perms []types.Permission
+	for _, v := range s.List() {
+		perm := v.(map[string]interface{})
+		principal := perm["principal"].(string)
+		group := perm["is_group"].(bool)
+		if entityPermissionsHasPrincipal(perms, principal, group) {
+			return nil, fmt.Errorf("principal %q is defined more than once", principal)
+		}
+		role := roles.ByName(perm["role"].(string))
+		if role == nil {
+			return nil, fmt.Errorf("role %q not found", perm["role"].(string))
+		}
+		perms = append(perms, types.Permission{
+			Principal: principal,
+			Group:     group,
+			RoleId:    role.RoleId,
+			Propagate: perm["propagate"].(bool),
+		})
+	}
+	return perms, nil
+}
+
+// entityPermissionsHasPrincipal returns true if there is a permission for
+// principal in perms. A user and a group with the same name are different
+// principals.
+func entityPermissionsHasPrincipal(perms []types.Permission, principal string, group bool) bool {
+	for _, perm := range perms {
+		if perm.Principal == principal && perm.Group == group {
+			return true
+		}
+	}
+	return false
+}
+
+// splitEntityPermissionsID splits a vsphere_entity_permissions resource ID
+// into the reference to the entity.
+func splitEntityPermissionsID(raw string) (types.ManagedObjectReference, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return types.ManagedObjectReference{}, fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return types.ManagedObjectReference{Type: s[0], Value: s[1]}, nil
+}
@@ -0,0 +1,152 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereEntityPermissions_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereEntityPermissionPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereEntityPermissionsConfig("ReadOnly"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_entity_permissions.permissions", "permissions.#", "1"),
+				),
+			},
+			{
+				// A permission added outside of Terraform must be removed.
+				PreConfig: testAccResourceVSphereEntityPermissionsAddDrift(t),
+				Config:    testAccResourceVSphereEntityPermissionsConfig("NoAccess"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_entity_permissions.permissions", "permissions.#", "1"),
+					testAccResourceVSphereEntityPermissionsCheckCount(1),
+				),
+			},
+			{
+				ResourceName:      "vsphere_entity_permissions.permissions",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testAccResourceVSphereEntityPermissionsAddDrift grants the Admin role to the
+// Administrator user on the test folder, outside of Terraform.
+func TestEntityPermissionsHasPrincipal(t *testing.T) {
+	perms := []types.Permission{
+		{Principal: "VSPHERE.LOCAL\\ops", Group: true},
+		{Principal: "VSPHERE.LOCAL\\alice", Group: false},
+	}
+	cases := []struct {
+		principal string
+		group     bool
+		expected  bool
+	}{
+		{"VSPHERE.LOCAL\\ops", true, true},
+		{"VSPHERE.LOCAL\\ops", false, false},
+		{"VSPHERE.LOCAL\\alice", false, true},
+		{"VSPHERE.LOCAL\\alice", true, false},
+		{"VSPHERE.LOCAL\\bob", false, false},
+	}
+	for _, tc := range cases {
+		if actual := entityPermissionsHasPrincipal(perms, tc.principal, tc.group); actual != tc.expected {
+			t.Fatalf("%q (group %t): expected %t, got %t", tc.principal, tc.group, tc.expected, actual)
+		}
+	}
+}
+
+func testAccResourceVSphereEntityPermissionsAddDrift(t *testing.T) func() {
+	return func() {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		f, err := folder.FromAbsolutePath(client, fmt.Sprintf("/%s/vm/terraform-test-permissions", os.Getenv("VSPHERE_DATACENTER")))
+		if err != nil {
+			t.Fatalf("error fetching folder: %s", err)
+		}
+		role, err := permissionRoleByName(client, "Admin")
+		if err != nil {
+			t.Fatalf("error fetching role: %s", err)
+		}
+		perm := types.Permission{
+			Principal: "VSPHERE.LOCAL\\Administrator",
+			RoleId:    role.RoleId,
+			Propagate: true,
+		}
+		if err := setEntityPermission(client, f.Reference(), perm); err != nil {
+			t.Fatalf("error adding permission: %s", err)
+		}
+	}
+}
+
+// testAccResourceVSphereEntityPermissionsCheckCount checks the number of
+// permissions defined on the test folder in vCenter.
+func testAccResourceVSphereEntityPermissionsCheckCount(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_entity_permissions.permissions"]
+		if !ok {
+			return fmt.Errorf("vsphere_entity_permissions.permissions not found in state")
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		ref, err := splitEntityPermissionsID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		perms, err := entityPermissions(client, ref)
+		if err != nil {
+			return err
+		}
+		if len(perms) != expected {
+			return fmt.Errorf("expected %d permissions on %s, got %d", expected, ref, len(perms))
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereEntityPermissionsConfig(role string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "principal" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "terraform-test-permissions"
+  type          = "vm"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_entity_permissions" "permissions" {
+  entity_id   = "${vsphere_folder.folder.id}"
+  entity_type = "Folder"
+
+  permissions {
+    principal = "${var.principal}"
+    role      = "%s"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_PERMISSION_PRINCIPAL"),
+		role,
+	)
+}
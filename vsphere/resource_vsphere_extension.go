@@ -0,0 +1,292 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/extension"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereExtension() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereExtensionCreate,
+		Read:   resourceVSphereExtensionRead,
+		Update: resourceVSphereExtensionUpdate,
+		Delete: resourceVSphereExtensionDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"key": {
+				Type:        schema.TypeString,
+				Description: "The unique key of the extension, for example com.example.backup.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Description: "The version of the extension.",
+				Required:    true,
+			},
+			"label": {
+				Type:        schema.TypeString,
+				Description: "The display name of the extension.",
+				Optional:    true,
+			},
+			"summary": {
+				Type:        schema.TypeString,
+				Description: "A short description of the extension.",
+				Optional:    true,
+			},
+			"company": {
+				Type:        schema.TypeString,
+				Description: "The company that provides the extension.",
+				Optional:    true,
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Description: "The type of the extension.",
+				Optional:    true,
+			},
+			"show_in_solution_manager": {
+				Type:        schema.TypeBool,
+				Description: "Show the extension in the solution manager of vCenter.",
+				Optional:    true,
+			},
+			"certificate": {
+				Type:        schema.TypeString,
+				Description: "The PEM-encoded X.509 certificate that the extension uses to log in to vCenter.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"server": {
+				Type:        schema.TypeList,
+				Description: "The servers that provide the extension.",
+				Optional:    true,
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"url": {
+						Type:        schema.TypeString,
+						Description: "The URL of the server.",
+						Required:    true,
+					},
+					"type": {
+						Type:        schema.TypeString,
+						Description: "The type of the server, for example HTTPS or SOAP.",
+						Required:    true,
+					},
+					"company": {
+						Type:        schema.TypeString,
+						Description: "The company that provides the server.",
+						Optional:    true,
+					},
+					"admin_email": {
+						Type:        schema.TypeList,
+						Description: "The email addresses of the administrators of the server.",
+						Optional:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
+					"server_thumbprint": {
+						Type:        schema.TypeString,
+						Description: "The SHA-1 thumbprint of the certificate of the server.",
+						Optional:    true,
+					},
+				}},
+			},
+			"client": {
+				Type:        schema.TypeList,
+				Description: "The clients of the extension, for example vSphere Client plugins.",
+				Optional:    true,
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"url": {
+						Type:        schema.TypeString,
+						Description: "The URL of the client.",
+						Required:    true,
+					},
+					"type": {
+						Type:        schema.TypeString,
+						Description: "The type of the client, for example vsphere-client-serenity.",
+						Required:    true,
+					},
+					"version": {
+						Type:        schema.TypeString,
+						Description: "The version of the client.",
+						Required:    true,
+					},
+					"company": {
+						Type:        schema.TypeString,
+						Description: "The company that provides the client.",
+						Optional:    true,
+					},
+				}},
+			},
+		},
+	}
+}
+
+func resourceVSphereExtensionCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	key := d.Get("key").(string)
+	existing, err := extension.FromKey(client, key)
+	if err != nil {
+		return fmt.Errorf("error locating extension %q: %s", key, err)
+	}
+	if existing != nil {
+		return fmt.Errorf("extension %q is already registered, import it to manage it with Terraform", key)
+	}
+	ext := types.Extension{
+		Key:               key,
+		LastHeartbeatTime: time.Now(),
+	}
+	expandExtension(d, &ext)
+	if err := extension.Register(client, ext); err != nil {
+		return viapi.NewDiagnostic(err, "error registering extension %q", key)
+	}
+	d.SetId(key)
+	if cert := d.Get("certificate").(string); cert != "" {
+		if err := extension.SetCertificate(client, key, cert); err != nil {
+			return viapi.NewDiagnostic(err, "error setting certificate of extension %q", key)
+		}
+	}
+	return resourceVSphereExtensionRead(d, meta)
+}
+
+func resourceVSphereExtensionRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ext, err := extension.FromKey(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating extension %q: %s", d.Id(), err)
+	}
+	if ext == nil {
+		log.Printf("[DEBUG] Extension %q not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	return flattenExtension(d, ext)
+}
+
+func resourceVSphereExtensionUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ext, err := extension.FromKey(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating extension %q: %s", d.Id(), err)
+	}
+	if ext == nil {
+		return fmt.Errorf("extension %q is no longer registered", d.Id())
+	}
+	// The extension is updated from its current registration, so that the
+	// settings that the solution registered itself, like its tasks, events and
+	// privileges, are kept.
+	expandExtension(d, ext)
+	if err := extension.Update(client, *ext); err != nil {
+		return viapi.NewDiagnostic(err, "error updating extension %q", d.Id())
+	}
+	if d.HasChange("certificate") {
+		if cert := d.Get("certificate").(string); cert != "" {
+			if err := extension.SetCertificate(client, d.Id(), cert); err != nil {
+				return viapi.NewDiagnostic(err, "error setting certificate of extension %q", d.Id())
+			}
+		}
+	}
+	return resourceVSphereExtensionRead(d, meta)
+}
+
+func resourceVSphereExtensionDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := extension.Unregister(client, d.Id()); err != nil {
+		return viapi.NewDiagnostic(err, "error unregistering extension %q", d.Id())
+	}
+	return nil
+}
+
+// expandExtension sets the settings of ext that are managed by the
+// vsphere_extension resource from the resource data.
+func expandExtension(d *schema.ResourceData, ext *types.Extension) {
+	ext.Description = &types.Description{
+		Label:   d.Get("label").(string),
+		Summary: d.Get("summary").(string),
+	}
+	ext.Version = d.Get("version").(string)
+	ext.Company = d.Get("company").(string)
+	ext.Type = d.Get("type").(string)
+	ext.ShownInSolutionManager = structure.GetBool(d, "show_in_solution_manager")
+
+	ext.Server = nil
+	for _, v := range d.Get("server").([]interface{}) {
+		m := v.(map[string]interface{})
+		ext.Server = append(ext.Server, types.ExtensionServerInfo{
+			Url:              m["url"].(string),
+			Description:      &types.Description{},
+			Company:          m["company"].(string),
+			Type:             m["type"].(string),
+			AdminEmail:       structure.SliceInterfacesToStrings(m["admin_email"].([]interface{})),
+			ServerThumbprint: m["server_thumbprint"].(string),
+		})
+	}
+
+	ext.Client = nil
+	for _, v := range d.Get("client").([]interface{}) {
+		m := v.(map[string]interface{})
+		ext.Client = append(ext.Client, types.ExtensionClientInfo{
+			Version:     m["version"].(string),
+			Description: &types.Description{},
+			Company:     m["company"].(string),
+			Type:        m["type"].(string),
+			Url:         m["url"].(string),
+		})
+	}
+}
+
+// flattenExtension saves the settings of ext that are managed by the
+// vsphere_extension resource to the resource data. The certificate cannot be
+// read back from vCenter and is left as is.
+func flattenExtension(d *schema.ResourceData, ext *types.Extension) error {
+	d.Set("key", ext.Key)
+	d.Set("version", ext.Version)
+	d.Set("company", ext.Company)
+	d.Set("type", ext.Type)
+	if ext.Description != nil {
+		desc := ext.Description.GetDescription()
+		d.Set("label", desc.Label)
+		d.Set("summary", desc.Summary)
+	}
+	if err := structure.SetBoolPtr(d, "show_in_solution_manager", ext.ShownInSolutionManager); err != nil {
+		return err
+	}
+
+	var servers []interface{}
+	for _, s := range ext.Server {
+		servers = append(servers, map[string]interface{}{
+			"url":               s.Url,
+			"type":              s.Type,
+			"company":           s.Company,
+			"admin_email":       structure.SliceStringsToInterfaces(s.AdminEmail),
+			"server_thumbprint": s.ServerThumbprint,
+		})
+	}
+	if err := d.Set("server", servers); err != nil {
+		return fmt.Errorf("error setting server: %s", err)
+	}
+
+	var clients []interface{}
+	for _, c := range ext.Client {
+		clients = append(clients, map[string]interface{}{
+			"url":     c.Url,
+			"type":    c.Type,
+			"version": c.Version,
+			"company": c.Company,
+		})
+	}
+	if err := d.Set("client", clients); err != nil {
+		return fmt.Errorf("error setting client: %s", err)
+	}
+	return nil
+}
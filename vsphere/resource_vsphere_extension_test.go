@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/extension"
+)
+
+const testAccResourceVSphereExtensionKey = "com.hashicorp.terraform.acctest"
+
+func TestAccResourceVSphereExtension_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereExtensionExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereExtensionConfig("1.0.0"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereExtensionExists(true),
+					testAccResourceVSphereExtensionHasVersion("1.0.0"),
+					resource.TestCheckResourceAttr("vsphere_extension.extension", "server.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_extension.extension", "server.0.admin_email.0", "admin@example.com"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereExtensionConfig("1.1.0"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereExtensionExists(true),
+					testAccResourceVSphereExtensionHasVersion("1.1.0"),
+				),
+			},
+			{
+				ResourceName:            "vsphere_extension.extension",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"certificate"},
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereExtensionExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		ext, err := extension.FromKey(client, testAccResourceVSphereExtensionKey)
+		if err != nil {
+			return err
+		}
+		switch {
+		case ext == nil && expected:
+			return fmt.Errorf("extension %q not found", testAccResourceVSphereExtensionKey)
+		case ext != nil && !expected:
+			return fmt.Errorf("extension %q still registered", testAccResourceVSphereExtensionKey)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereExtensionHasVersion(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		ext, err := extension.FromKey(client, testAccResourceVSphereExtensionKey)
+		if err != nil {
+			return err
+		}
+		if ext == nil {
+			return fmt.Errorf("extension %q not found", testAccResourceVSphereExtensionKey)
+		}
+		if ext.Version != expected {
+			return fmt.Errorf("expected version to be %q, got %q", expected, ext.Version)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereExtensionConfig(version string) string {
+	return fmt.Sprintf(`
+variable "version" {
+  default = "%s"
+}
+
+resource "vsphere_extension" "extension" {
+  key                      = "%s"
+  version                  = "${var.version}"
+  label                    = "Terraform acceptance test"
+  summary                  = "Registered by the vsphere_extension acceptance tests."
+  company                  = "HashiCorp"
+  show_in_solution_manager = true
+
+  server {
+    url         = "https://backup.example.com/api"
+    type        = "HTTPS"
+    company     = "HashiCorp"
+    admin_email = ["admin@example.com"]
+  }
+}
+`,
+		version,
+		testAccResourceVSphereExtensionKey,
+	)
+}
@@ -6,10 +6,13 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
 	"golang.org/x/net/context"
 )
 
@@ -18,6 +21,7 @@ type file struct {
 	datacenter        string
 	sourceDatastore   string
 	datastore         string
+	datastoreID       string
 	sourceFile        string
 	destinationFile   string
 	createDirectories bool
@@ -44,8 +48,27 @@ func resourceVSphereFile() *schema.Resource {
 			},
 
 			"datastore": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: datastoreReferenceDiffSuppress("current_datastore"),
+				ConflictsWith:    []string{"datastore_cluster_id"},
+			},
+
+			"datastore_cluster_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"datastore"},
+			},
+
+			"datastore_id": {
 				Type:     schema.TypeString,
-				Required: true,
+				Computed: true,
+			},
+
+			"current_datastore": {
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 
 			"source_datastore": {
@@ -96,8 +119,15 @@ func resourceVSphereFileCreate(d *schema.ResourceData, meta interface{}) error {
 
 	if v, ok := d.GetOk("datastore"); ok {
 		f.datastore = v.(string)
+	} else if v, ok := d.GetOk("datastore_cluster_id"); ok {
+		ds, err := fileDatastoreFromCluster(client, v.(string))
+		if err != nil {
+			return err
+		}
+		f.datastore = ds.Name()
+		f.datastoreID = ds.Reference().Value
 	} else {
-		return fmt.Errorf("datastore argument is required")
+		return fmt.Errorf("one of datastore or datastore_cluster_id must be set")
 	}
 
 	if v, ok := d.GetOk("source_file"); ok {
@@ -120,6 +150,7 @@ func resourceVSphereFileCreate(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return err
 	}
+	d.Set("datastore_id", f.datastoreID)
 
 	d.SetId(fmt.Sprintf("[%v] %v/%v", f.datastore, f.datacenter, f.destinationFile))
 	log.Printf("[INFO] Created file: %s", f.destinationFile)
@@ -137,7 +168,7 @@ func createFile(client *govmomi.Client, f *file) error {
 	}
 	finder = finder.SetDatacenter(dc)
 
-	ds, err := getDatastore(finder, f.datastore)
+	ds, err := datastoreByReference(client, finder, f.datastore, f.datastoreID)
 	if err != nil {
 		return fmt.Errorf("error %s", err)
 	}
@@ -211,7 +242,7 @@ func resourceVSphereFileRead(d *schema.ResourceData, meta interface{}) error {
 
 	if v, ok := d.GetOk("datastore"); ok {
 		f.datastore = v.(string)
-	} else {
+	} else if d.Get("datastore_id").(string) == "" {
 		return fmt.Errorf("datastore argument is required")
 	}
 
@@ -236,10 +267,15 @@ func resourceVSphereFileRead(d *schema.ResourceData, meta interface{}) error {
 	}
 	finder = finder.SetDatacenter(dc)
 
-	ds, err := getDatastore(finder, f.datastore)
+	// The datastore is tracked by its ID, so that a datastore renamed outside
+	// of Terraform can still be found. The new name is saved in
+	// current_datastore.
+	ds, err := datastoreByReference(client, finder, f.datastore, d.Get("datastore_id").(string))
 	if err != nil {
 		return fmt.Errorf("error %s", err)
 	}
+	d.Set("datastore_id", ds.Reference().Value)
+	d.Set("current_datastore", ds.Name())
 
 	_, err = ds.Stat(context.TODO(), f.destinationFile)
 	if err != nil {
@@ -301,14 +337,25 @@ func resourceVSphereFileUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 		finder := find.NewFinder(client.Client, true)
 		finder = finder.SetDatacenter(dcOld)
-		dsOld, err := getDatastore(finder, oldDatastore)
+		dsOld, err := datastoreByReference(client, finder, oldDatastore, d.Get("datastore_id").(string))
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
-		finder = finder.SetDatacenter(dcNew)
-		dsNew, err := getDatastore(finder, newDatastore)
-		if err != nil {
-			return fmt.Errorf("error %s", err)
+		dsNew := dsOld
+		// Files in a datastore cluster stay on the datastore that they were
+		// placed on.
+		if d.HasChange("datastore") || (d.HasChange("datacenter") && d.Get("datastore_cluster_id").(string) == "") {
+			finder = finder.SetDatacenter(dcNew)
+			dsNew, err = getDatastore(finder, newDatastore)
+			if err != nil {
+				return fmt.Errorf("error %s", err)
+			}
+		}
+		d.Set("datastore_id", dsNew.Reference().Value)
+		d.Set("current_datastore", dsNew.Name())
+		if dsOld.Reference() == dsNew.Reference() && oldDestinationFile == newDestinationFile {
+			log.Printf("[DEBUG] File %q is already in place on datastore %q", newDestinationFile, dsNew.Name())
+			return nil
 		}
 
 		// Move file between old/new dataceter, datastore and path (destination_file)
@@ -337,9 +384,10 @@ func resourceVSphereFileDelete(d *schema.ResourceData, meta interface{}) error {
 
 	if v, ok := d.GetOk("datastore"); ok {
 		f.datastore = v.(string)
-	} else {
+	} else if d.Get("datastore_id").(string) == "" {
 		return fmt.Errorf("datastore argument is required")
 	}
+	f.datastoreID = d.Get("datastore_id").(string)
 
 	if v, ok := d.GetOk("source_file"); ok {
 		f.sourceFile = v.(string)
@@ -374,7 +422,7 @@ func deleteFile(client *govmomi.Client, f *file) error {
 	finder := find.NewFinder(client.Client, true)
 	finder = finder.SetDatacenter(dc)
 
-	ds, err := getDatastore(finder, f.datastore)
+	ds, err := datastoreByReference(client, finder, f.datastore, f.datastoreID)
 	if err != nil {
 		return fmt.Errorf("error %s", err)
 	}
@@ -392,6 +440,48 @@ func deleteFile(client *govmomi.Client, f *file) error {
 	return nil
 }
 
+// fileDatastoreFromCluster picks the datastore in a datastore cluster to place
+// a file on. Storage DRS only makes recommendations for virtual machines, so
+// the accessible datastore with the most free space that is not in
+// maintenance mode is picked.
+func fileDatastoreFromCluster(client *govmomi.Client, id string) (*object.Datastore, error) {
+	pod, err := storagepod.FromID(client, id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	refs, err := storagepod.Datastores(pod)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching datastores of datastore cluster %q: %s", pod.InventoryPath, err)
+	}
+	var best *object.Datastore
+	var free int64
+	for _, ref := range refs {
+		ds, err := datastore.FromID(client, ref.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error locating datastore %q: %s", ref.Value, err)
+		}
+		props, err := datastore.Properties(ds)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching properties of datastore %q: %s", ds.Name(), err)
+		}
+		if !props.Summary.Accessible {
+			continue
+		}
+		if mode := props.Summary.MaintenanceMode; mode != "" && mode != string(types.DatastoreSummaryMaintenanceModeStateNormal) {
+			continue
+		}
+		if best == nil || props.Summary.FreeSpace > free {
+			best = ds
+			free = props.Summary.FreeSpace
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("datastore cluster %q has no accessible datastores that are not in maintenance mode", pod.InventoryPath)
+	}
+	log.Printf("[DEBUG] Placing file on datastore %q in datastore cluster %q", best.Name(), pod.InventoryPath)
+	return best, nil
+}
+
 // getDatastore gets datastore object
 func getDatastore(f *find.Finder, ds string) (*object.Datastore, error) {
 
@@ -243,6 +243,41 @@ func TestAccResourceVSphereFile_uploadAndCopyAndUpdate(t *testing.T) {
 	os.Remove(sourceFile)
 }
 
+// File upload to a datastore cluster
+func TestAccResourceVSphereFile_datastoreCluster(t *testing.T) {
+	testVmdkFileData := []byte("# Disk DescriptorFile\n")
+	testVmdkFile := "/tmp/tf_test.vmdk"
+	err := ioutil.WriteFile(testVmdkFile, testVmdkFileData, 0644)
+	if err != nil {
+		t.Errorf("error %s", err)
+		return
+	}
+
+	resourceName := "vsphere_file.datastore_cluster"
+	destinationFile := "tf_file_test.vmdk"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereDatastoreClusterPreCheck(t)
+			testAccResourceVSphereNasDatastorePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereFileDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereFileDatastoreClusterConfig(testVmdkFile, destinationFile),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVSphereFileExists(resourceName, destinationFile, true),
+					resource.TestCheckResourceAttrPair(resourceName, "datastore_id", "vsphere_nas_datastore.datastore", "id"),
+					resource.TestCheckResourceAttr(resourceName, "current_datastore", "terraform-test-nas"),
+				),
+			},
+		},
+	})
+	os.Remove(testVmdkFile)
+}
+
 func testAccCheckVSphereFileDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*VSphereClient).vimClient
 	finder := find.NewFinder(client.Client, true)
@@ -259,7 +294,7 @@ func testAccCheckVSphereFileDestroy(s *terraform.State) error {
 
 		finder = finder.SetDatacenter(dc)
 
-		ds, err := getDatastore(finder, rs.Primary.Attributes["datastore"])
+		ds, err := datastoreByReference(client, finder, rs.Primary.Attributes["datastore"], rs.Primary.Attributes["datastore_id"])
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
@@ -300,7 +335,7 @@ func testAccCheckVSphereFileExists(n string, df string, exists bool) resource.Te
 		}
 		finder = finder.SetDatacenter(dc)
 
-		ds, err := getDatastore(finder, rs.Primary.Attributes["datastore"])
+		ds, err := datastoreByReference(client, finder, rs.Primary.Attributes["datastore"], rs.Primary.Attributes["datastore_id"])
 		if err != nil {
 			return fmt.Errorf("error %s", err)
 		}
@@ -345,3 +380,55 @@ resource "vsphere_file" "%s" {
 	destination_file = "%s"
 }
 `
+
+func testAccCheckVSphereFileDatastoreClusterConfig(sourceFile, destinationFile string) string {
+	return fmt.Sprintf(`
+variable "nfs_host" {
+  default = "%s"
+}
+
+variable "nfs_path" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name                 = "terraform-test-nas"
+  host_system_ids      = ["${data.vsphere_host.esxi_host.id}"]
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+}
+
+resource "vsphere_file" "datastore_cluster" {
+  datacenter           = "${data.vsphere_datacenter.dc.name}"
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+  source_file          = "%s"
+  destination_file     = "%s"
+
+  depends_on = ["vsphere_nas_datastore.datastore"]
+}
+`,
+		os.Getenv("VSPHERE_NAS_HOST"),
+		os.Getenv("VSPHERE_NFS_PATH"),
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		sourceFile,
+		destinationFile,
+	)
+}
@@ -30,11 +30,21 @@ func resourceVSphereFolder() *schema.Resource {
 		MigrateState:  resourceVSphereFolderMigrateState,
 		Schema: map[string]*schema.Schema{
 			"path": {
-				Type:         schema.TypeString,
-				Description:  "The path of the folder and any parents, relative to the datacenter and folder type being defined.",
-				Required:     true,
-				StateFunc:    folder.NormalizePath,
-				ValidateFunc: validation.NoZeroValues,
+				Type:          schema.TypeString,
+				Description:   "The path of the folder and any parents, relative to the datacenter and folder type being defined. One of path or name_prefix must be set.",
+				Optional:      true,
+				Computed:      true,
+				StateFunc:     folder.NormalizePath,
+				ValidateFunc:  validation.NoZeroValues,
+				ConflictsWith: []string{"name_prefix"},
+			},
+			"name_prefix": {
+				Type:          schema.TypeString,
+				Description:   "Generate a unique path for the folder that starts with this prefix, such as parent/child-.",
+				Optional:      true,
+				ForceNew:      true,
+				ValidateFunc:  validateFolderNamePrefix,
+				ConflictsWith: []string{"path"},
 			},
 			"type": {
 				Type:        schema.TypeString,
@@ -92,6 +102,9 @@ func resourceVSphereFolderCreate(d *schema.ResourceData, meta interface{}) error
 		}
 	}
 
+	if err := setNameFromPrefix(d, "path"); err != nil {
+		return err
+	}
 	p := d.Get("path").(string)
 
 	// Determine the parent folder
@@ -105,7 +118,7 @@ func resourceVSphereFolderCreate(d *schema.ResourceData, meta interface{}) error
 
 	folder, err := parent.CreateFolder(ctx, path.Base(p))
 	if err != nil {
-		return fmt.Errorf("error creating folder: %s", err)
+		return viapi.NewDiagnostic(err, "error creating folder")
 	}
 
 	d.SetId(folder.Reference().Value)
@@ -318,3 +331,13 @@ func resourceVSphereFolderImport(d *schema.ResourceData, meta interface{}) ([]*s
 	d.SetId(folder.Reference().Value)
 	return []*schema.ResourceData{d}, nil
 }
+
+// validateFolderNamePrefix checks the name of the folder in a name_prefix,
+// which is the part of it after the last slash.
+func validateFolderNamePrefix(v interface{}, k string) ([]string, []error) {
+	prefix := v.(string)
+	if err := folderNameRule.validatePrefix(prefix[strings.LastIndex(prefix, "/")+1:]); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
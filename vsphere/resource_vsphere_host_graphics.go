@@ -0,0 +1,526 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// nvidiaModuleName is the name of the kernel module of the NVIDIA vGPU manager
+// on ESXi.
+const nvidiaModuleName = "nvidia"
+
+// nvidiaRegistryDwordsOption is the option of the NVIDIA kernel module that
+// holds driver registry settings, separated by semicolons.
+const nvidiaRegistryDwordsOption = "NVreg_RegistryDwords"
+
+// nvidiaRegistryDwordsPerDeviceOption is the option of the NVIDIA kernel
+// module that holds driver registry settings for individual GPUs. Each GPU
+// starts with a pci=<PCI ID> entry, followed by its settings, all separated by
+// semicolons.
+const nvidiaRegistryDwordsPerDeviceOption = "NVreg_RegistryDwordsPerDevice"
+
+// nvidiaSchedulingPolicyKey is the driver registry setting that selects the
+// vGPU scheduling policy.
+const nvidiaSchedulingPolicyKey = "RmPVMRL"
+
+// The following are the vGPU scheduling policies of the vsphere_host_graphics
+// resource.
+const (
+	vgpuSchedulingPolicyBestEffort = "best_effort"
+	vgpuSchedulingPolicyEqualShare = "equal_share"
+	vgpuSchedulingPolicyFixedShare = "fixed_share"
+)
+
+// vgpuSchedulingPolicyValues maps the vGPU scheduling policies to their
+// RmPVMRL values. Best effort is the default of the driver.
+var vgpuSchedulingPolicyValues = map[string]string{
+	vgpuSchedulingPolicyBestEffort: "0x00",
+	vgpuSchedulingPolicyEqualShare: "0x01",
+	vgpuSchedulingPolicyFixedShare: "0x11",
+}
+
+var vgpuSchedulingPolicyAllowedValues = []string{
+	vgpuSchedulingPolicyBestEffort,
+	vgpuSchedulingPolicyEqualShare,
+	vgpuSchedulingPolicyFixedShare,
+}
+
+var hostGraphicsTypeAllowedValues = []string{
+	string(types.HostGraphicsConfigGraphicsTypeShared),
+	string(types.HostGraphicsConfigGraphicsTypeSharedDirect),
+}
+
+var hostGraphicsAssignmentPolicyAllowedValues = []string{
+	string(types.HostGraphicsConfigSharedPassthruAssignmentPolicyPerformance),
+	string(types.HostGraphicsConfigSharedPassthruAssignmentPolicyConsolidation),
+}
+
+func resourceVSphereHostGraphics() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostGraphicsCreate,
+		Read:   resourceVSphereHostGraphicsRead,
+		Update: resourceVSphereHostGraphicsUpdate,
+		Delete: resourceVSphereHostGraphicsDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to configure the graphics of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"default_graphics_type": {
+				Type:         schema.TypeString,
+				Description:  "The graphics type of the GPUs of the host that have no type in device. Can be one of shared or sharedDirect.",
+				Optional:     true,
+				Default:      string(types.HostGraphicsConfigGraphicsTypeShared),
+				ValidateFunc: validation.StringInSlice(hostGraphicsTypeAllowedValues, false),
+			},
+			"shared_passthrough_assignment_policy": {
+				Type:         schema.TypeString,
+				Description:  "How virtual machines with vGPU profiles are spread over the GPUs of the host. Can be one of performance or consolidation.",
+				Optional:     true,
+				Default:      string(types.HostGraphicsConfigSharedPassthruAssignmentPolicyPerformance),
+				ValidateFunc: validation.StringInSlice(hostGraphicsAssignmentPolicyAllowedValues, false),
+			},
+			"device": {
+				Type:        schema.TypeSet,
+				Description: "The graphics type of individual GPUs.",
+				Optional:    true,
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"device_id": {
+						Type:        schema.TypeString,
+						Description: "The PCI ID of the GPU.",
+						Required:    true,
+					},
+					"graphics_type": {
+						Type:         schema.TypeString,
+						Description:  "The graphics type of the GPU. Can be one of shared or sharedDirect.",
+						Required:     true,
+						ValidateFunc: validation.StringInSlice(hostGraphicsTypeAllowedValues, false),
+					},
+					"vgpu_scheduling_policy": {
+						Type:         schema.TypeString,
+						Description:  "The scheduling policy of the NVIDIA vGPU manager for this GPU, overriding vgpu_scheduling_policy. Can be one of best_effort, equal_share, or fixed_share.",
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice(vgpuSchedulingPolicyAllowedValues, false),
+					},
+				}},
+			},
+			"vgpu_scheduling_policy": {
+				Type:         schema.TypeString,
+				Description:  "The scheduling policy of the NVIDIA vGPU manager for all GPUs of the host that have no policy in device. Can be one of best_effort, equal_share, or fixed_share.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(vgpuSchedulingPolicyAllowedValues, false),
+			},
+		},
+	}
+}
+
+func resourceVSphereHostGraphicsCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, "vsphere_host_graphics"); err != nil {
+		return err
+	}
+	hsID := d.Get("host_system_id").(string)
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostGraphicsApply(d, meta, hs); err != nil {
+		return err
+	}
+	d.SetId(hsID)
+	return resourceVSphereHostGraphicsRead(d, meta)
+}
+
+func resourceVSphereHostGraphicsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	props, err := hostsystem.Properties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	d.Set("host_system_id", d.Id())
+
+	// The scheduling policies are only read when they are managed, as hosts
+	// without the NVIDIA vGPU manager have no module to read them from.
+	managed := expandHostGraphicsDevicePolicies(d.Get("device").(*schema.Set))
+	var devicePolicies map[string]string
+	if d.Get("vgpu_scheduling_policy").(string) != "" || len(managed) > 0 {
+		options, err := hostsystem.ModuleOptions(client, hs, nvidiaModuleName)
+		if err != nil {
+			return viapi.NewDiagnostic(err, "error fetching options of the %s module on host %q", nvidiaModuleName, hs.Name())
+		}
+		if d.Get("vgpu_scheduling_policy").(string) != "" {
+			d.Set("vgpu_scheduling_policy", nvidiaSchedulingPolicyFromOptions(options))
+		}
+		devicePolicies = nvidiaDeviceSchedulingPoliciesFromOptions(options)
+	}
+
+	if props.Config != nil && props.Config.GraphicsConfig != nil {
+		config := props.Config.GraphicsConfig
+		d.Set("default_graphics_type", config.HostDefaultGraphicsType)
+		d.Set("shared_passthrough_assignment_policy", config.SharedPassthruAssignmentPolicy)
+		var devices []interface{}
+		for _, dt := range config.DeviceType {
+			var policy string
+			if _, ok := managed[dt.DeviceId]; ok {
+				policy = devicePolicies[dt.DeviceId]
+			}
+			devices = append(devices, map[string]interface{}{
+				"device_id":              dt.DeviceId,
+				"graphics_type":          dt.GraphicsType,
+				"vgpu_scheduling_policy": policy,
+			})
+		}
+		if err := d.Set("device", devices); err != nil {
+			return fmt.Errorf("error setting device: %s", err)
+		}
+	}
+	return nil
+}
+
+func resourceVSphereHostGraphicsUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostGraphicsApply(d, meta, hs); err != nil {
+		return err
+	}
+	return resourceVSphereHostGraphicsRead(d, meta)
+}
+
+func resourceVSphereHostGraphicsDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	config := types.HostGraphicsConfig{
+		HostDefaultGraphicsType:        string(types.HostGraphicsConfigGraphicsTypeShared),
+		SharedPassthruAssignmentPolicy: string(types.HostGraphicsConfigSharedPassthruAssignmentPolicyPerformance),
+	}
+	if err := hostsystem.UpdateGraphicsConfig(client, hs, config); err != nil {
+		return viapi.NewDiagnostic(err, "error resetting graphics configuration of host %q", hs.Name())
+	}
+	// Remove the scheduling policies that were managed by the resource.
+	policy := d.Get("vgpu_scheduling_policy").(string)
+	devicePolicies := expandHostGraphicsDevicePolicies(d.Get("device").(*schema.Set))
+	for id := range devicePolicies {
+		devicePolicies[id] = ""
+	}
+	if policy != "" || len(devicePolicies) > 0 {
+		return resourceVSphereHostGraphicsSetSchedulingPolicy(meta, hs, policy != "", "", devicePolicies)
+	}
+	return nil
+}
+
+// resourceVSphereHostGraphicsApply sets the graphics configuration and, if
+// they changed, the vGPU scheduling policies of a host.
+func resourceVSphereHostGraphicsApply(d *schema.ResourceData, meta interface{}, hs *object.HostSystem) error {
+	client := meta.(*VSphereClient).vimClient
+	config := types.HostGraphicsConfig{
+		HostDefaultGraphicsType:        d.Get("default_graphics_type").(string),
+		SharedPassthruAssignmentPolicy: d.Get("shared_passthrough_assignment_policy").(string),
+	}
+	for _, v := range d.Get("device").(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		config.DeviceType = append(config.DeviceType, types.HostGraphicsConfigDeviceType{
+			DeviceId:     m["device_id"].(string),
+			GraphicsType: m["graphics_type"].(string),
+		})
+	}
+	if err := hostsystem.UpdateGraphicsConfig(client, hs, config); err != nil {
+		return viapi.NewDiagnostic(err, "error updating graphics configuration of host %q", hs.Name())
+	}
+	// Device policies that are set are applied, and the ones that were set
+	// before and are no longer are removed. Devices without a policy are left
+	// as they are.
+	o, n := d.GetChange("device")
+	oldPolicies := expandHostGraphicsDevicePolicies(o.(*schema.Set))
+	newPolicies := expandHostGraphicsDevicePolicies(n.(*schema.Set))
+	devicePolicies := make(map[string]string)
+	for id, policy := range newPolicies {
+		if oldPolicies[id] != policy {
+			devicePolicies[id] = policy
+		}
+	}
+	for id := range oldPolicies {
+		if _, ok := newPolicies[id]; !ok {
+			devicePolicies[id] = ""
+		}
+	}
+	if d.HasChange("vgpu_scheduling_policy") || len(devicePolicies) > 0 {
+		return resourceVSphereHostGraphicsSetSchedulingPolicy(meta, hs, d.HasChange("vgpu_scheduling_policy"), d.Get("vgpu_scheduling_policy").(string), devicePolicies)
+	}
+	return nil
+}
+
+// expandHostGraphicsDevicePolicies returns the vGPU scheduling policies of
+// the devices in s that have one, keyed by PCI ID.
+func expandHostGraphicsDevicePolicies(s *schema.Set) map[string]string {
+	policies := make(map[string]string)
+	for _, v := range s.List() {
+		m := v.(map[string]interface{})
+		if policy := m["vgpu_scheduling_policy"].(string); policy != "" {
+			policies[m["device_id"].(string)] = policy
+		}
+	}
+	return policies
+}
+
+// resourceVSphereHostGraphicsSetSchedulingPolicy sets the vGPU scheduling
+// policies in the options of the NVIDIA kernel module, keeping the other
+// options. The host policy is only set if setHost is true. devicePolicies
+// holds the policies of individual GPUs to set, keyed by PCI ID. An empty
+// policy removes the setting, which restores the default of the driver, or
+// for a GPU, the policy of the host.
+func resourceVSphereHostGraphicsSetSchedulingPolicy(meta interface{}, hs *object.HostSystem, setHost bool, policy string, devicePolicies map[string]string) error {
+	client := meta.(*VSphereClient).vimClient
+	options, err := hostsystem.ModuleOptions(client, hs, nvidiaModuleName)
+	if err != nil {
+		return viapi.NewDiagnostic(err, "error fetching options of the %s module on host %q", nvidiaModuleName, hs.Name())
+	}
+	if setHost {
+		options = nvidiaOptionsWithSchedulingPolicy(options, policy)
+	}
+	options = nvidiaOptionsWithDeviceSchedulingPolicies(options, devicePolicies)
+	if err := hostsystem.UpdateModuleOptions(client, hs, nvidiaModuleName, options); err != nil {
+		return viapi.NewDiagnostic(err, "error updating options of the %s module on host %q", nvidiaModuleName, hs.Name())
+	}
+	return nil
+}
+
+// splitModuleOptions splits a kernel module option string into its
+// key=value options. Spaces in double quotes do not separate options.
+func splitModuleOptions(options string) []string {
+	var result []string
+	start := -1
+	quoted := false
+	for i, r := range options {
+		switch {
+		case r == ' ' && !quoted:
+			if start >= 0 {
+				result = append(result, options[start:i])
+				start = -1
+			}
+			continue
+		case r == '"':
+			quoted = !quoted
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		result = append(result, options[start:])
+	}
+	return result
+}
+
+// nvidiaSchedulingPolicyFromOptions returns the vGPU scheduling policy in the
+// options of the NVIDIA kernel module. The driver defaults to best effort
+// when no policy is set. An empty string is returned for values that do not
+// match one of the policies.
+func nvidiaSchedulingPolicyFromOptions(options string) string {
+	for _, opt := range splitModuleOptions(options) {
+		if !strings.HasPrefix(opt, nvidiaRegistryDwordsOption+"=") {
+			continue
+		}
+		dwords := strings.Trim(strings.TrimPrefix(opt, nvidiaRegistryDwordsOption+"="), `"`)
+		for _, dword := range strings.Split(dwords, ";") {
+			kv := strings.SplitN(strings.TrimSpace(dword), "=", 2)
+			if len(kv) != 2 || kv[0] != nvidiaSchedulingPolicyKey {
+				continue
+			}
+			for policy, value := range vgpuSchedulingPolicyValues {
+				if strings.EqualFold(kv[1], value) {
+					return policy
+				}
+			}
+			return ""
+		}
+	}
+	return vgpuSchedulingPolicyBestEffort
+}
+
+// nvidiaOptionsWithSchedulingPolicy returns the options of the NVIDIA kernel
+// module with the vGPU scheduling policy set to policy, or removed if policy
+// is empty. Other options and driver registry settings are kept.
+func nvidiaOptionsWithSchedulingPolicy(options, policy string) string {
+	var result []string
+	found := false
+	for _, opt := range splitModuleOptions(options) {
+		if !strings.HasPrefix(opt, nvidiaRegistryDwordsOption+"=") {
+			result = append(result, opt)
+			continue
+		}
+		found = true
+		var dwords []string
+		for _, dword := range strings.Split(strings.Trim(strings.TrimPrefix(opt, nvidiaRegistryDwordsOption+"="), `"`), ";") {
+			dword = strings.TrimSpace(dword)
+			if dword == "" || strings.HasPrefix(dword, nvidiaSchedulingPolicyKey+"=") {
+				continue
+			}
+			dwords = append(dwords, dword)
+		}
+		if policy != "" {
+			dwords = append(dwords, nvidiaSchedulingPolicyKey+"="+vgpuSchedulingPolicyValues[policy])
+		}
+		if len(dwords) > 0 {
+			result = append(result, fmt.Sprintf("%s=%q", nvidiaRegistryDwordsOption, strings.Join(dwords, ";")))
+		}
+	}
+	if !found && policy != "" {
+		result = append(result, fmt.Sprintf("%s=%q", nvidiaRegistryDwordsOption, nvidiaSchedulingPolicyKey+"="+vgpuSchedulingPolicyValues[policy]))
+	}
+	return strings.Join(result, " ")
+}
+
+// nvidiaDeviceSettings is the list of driver registry settings of one GPU in
+// the NVreg_RegistryDwordsPerDevice option of the NVIDIA kernel module.
+type nvidiaDeviceSettings struct {
+	pci    string
+	dwords []string
+}
+
+// splitNvidiaDeviceSettings splits the value of the
+// NVreg_RegistryDwordsPerDevice option into the settings of each GPU.
+// Settings before the first pci entry are kept in an entry with an empty PCI
+// ID.
+func splitNvidiaDeviceSettings(value string) []*nvidiaDeviceSettings {
+	var result []*nvidiaDeviceSettings
+	var current *nvidiaDeviceSettings
+	for _, dword := range strings.Split(strings.Trim(value, `"`), ";") {
+		dword = strings.TrimSpace(dword)
+		switch {
+		case dword == "":
+			continue
+		case strings.HasPrefix(dword, "pci="):
+			current = &nvidiaDeviceSettings{pci: strings.TrimPrefix(dword, "pci=")}
+			result = append(result, current)
+			continue
+		case current == nil:
+			current = &nvidiaDeviceSettings{}
+			result = append(result, current)
+		}
+		current.dwords = append(current.dwords, dword)
+	}
+	return result
+}
+
+// nvidiaDeviceSchedulingPoliciesFromOptions returns the vGPU scheduling
+// policies of individual GPUs in the options of the NVIDIA kernel module,
+// keyed by PCI ID. GPUs with a value that does not match one of the policies
+// map to an empty string.
+func nvidiaDeviceSchedulingPoliciesFromOptions(options string) map[string]string {
+	result := make(map[string]string)
+	for _, opt := range splitModuleOptions(options) {
+		if !strings.HasPrefix(opt, nvidiaRegistryDwordsPerDeviceOption+"=") {
+			continue
+		}
+		for _, device := range splitNvidiaDeviceSettings(strings.TrimPrefix(opt, nvidiaRegistryDwordsPerDeviceOption+"=")) {
+			for _, dword := range device.dwords {
+				kv := strings.SplitN(dword, "=", 2)
+				if len(kv) != 2 || kv[0] != nvidiaSchedulingPolicyKey || device.pci == "" {
+					continue
+				}
+				result[device.pci] = ""
+				for policy, value := range vgpuSchedulingPolicyValues {
+					if strings.EqualFold(kv[1], value) {
+						result[device.pci] = policy
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// nvidiaOptionsWithDeviceSchedulingPolicies returns the options of the NVIDIA
+// kernel module with the vGPU scheduling policies of the GPUs in policies set,
+// or removed for GPUs with an empty policy. Other options, other GPUs, and
+// other driver registry settings are kept. GPUs that are new to the option are
+// added in the order of their PCI IDs.
+func nvidiaOptionsWithDeviceSchedulingPolicies(options string, policies map[string]string) string {
+	if len(policies) == 0 {
+		return options
+	}
+	var result []string
+	var devices []*nvidiaDeviceSettings
+	for _, opt := range splitModuleOptions(options) {
+		if strings.HasPrefix(opt, nvidiaRegistryDwordsPerDeviceOption+"=") {
+			devices = append(devices, splitNvidiaDeviceSettings(strings.TrimPrefix(opt, nvidiaRegistryDwordsPerDeviceOption+"="))...)
+			continue
+		}
+		result = append(result, opt)
+	}
+
+	seen := make(map[string]bool)
+	for _, device := range devices {
+		policy, ok := policies[device.pci]
+		if !ok || device.pci == "" {
+			continue
+		}
+		seen[device.pci] = true
+		var dwords []string
+		for _, dword := range device.dwords {
+			if !strings.HasPrefix(dword, nvidiaSchedulingPolicyKey+"=") {
+				dwords = append(dwords, dword)
+			}
+		}
+		if policy != "" {
+			dwords = append(dwords, nvidiaSchedulingPolicyKey+"="+vgpuSchedulingPolicyValues[policy])
+		}
+		device.dwords = dwords
+	}
+	var added []string
+	for pci, policy := range policies {
+		if !seen[pci] && policy != "" {
+			added = append(added, pci)
+		}
+	}
+	sort.Strings(added)
+	for _, pci := range added {
+		devices = append(devices, &nvidiaDeviceSettings{
+			pci:    pci,
+			dwords: []string{nvidiaSchedulingPolicyKey + "=" + vgpuSchedulingPolicyValues[policies[pci]]},
+		})
+	}
+
+	var dwords []string
+	for _, device := range devices {
+		if len(device.dwords) == 0 {
+			continue
+		}
+		if device.pci != "" {
+			dwords = append(dwords, "pci="+device.pci)
+		}
+		dwords = append(dwords, device.dwords...)
+	}
+	if len(dwords) > 0 {
+		result = append(result, fmt.Sprintf("%s=%q", nvidiaRegistryDwordsPerDeviceOption, strings.Join(dwords, ";")))
+	}
+	return strings.Join(result, " ")
+}
@@ -0,0 +1,150 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitModuleOptions(t *testing.T) {
+	cases := []struct {
+		options  string
+		expected []string
+	}{
+		{options: "", expected: nil},
+		{options: "a=1  b=2", expected: []string{"a=1", "b=2"}},
+		{options: `NVreg_RegistryDwords="A=1; B=2" c=3`, expected: []string{`NVreg_RegistryDwords="A=1; B=2"`, "c=3"}},
+	}
+	for _, tc := range cases {
+		if actual := splitModuleOptions(tc.options); !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("%q: expected %q, got %q", tc.options, tc.expected, actual)
+		}
+	}
+}
+
+func TestNvidiaSchedulingPolicyFromOptions(t *testing.T) {
+	cases := []struct {
+		options  string
+		expected string
+	}{
+		{options: "", expected: vgpuSchedulingPolicyBestEffort},
+		{options: `NVreg_RegistryDwords="RmPVMRL=0x01"`, expected: vgpuSchedulingPolicyEqualShare},
+		{options: `NVreg_RegistryDwords="Foo=1;RmPVMRL=0x11" other=1`, expected: vgpuSchedulingPolicyFixedShare},
+		{options: `NVreg_RegistryDwords="Foo=1"`, expected: vgpuSchedulingPolicyBestEffort},
+		{options: `NVreg_RegistryDwords="RmPVMRL=0x21"`, expected: ""},
+	}
+	for _, tc := range cases {
+		if actual := nvidiaSchedulingPolicyFromOptions(tc.options); actual != tc.expected {
+			t.Fatalf("%q: expected %q, got %q", tc.options, tc.expected, actual)
+		}
+	}
+}
+
+func TestNvidiaOptionsWithSchedulingPolicy(t *testing.T) {
+	cases := []struct {
+		name     string
+		options  string
+		policy   string
+		expected string
+	}{
+		{
+			name:     "no options",
+			options:  "",
+			policy:   vgpuSchedulingPolicyEqualShare,
+			expected: `NVreg_RegistryDwords="RmPVMRL=0x01"`,
+		},
+		{
+			name:     "replace policy, keep other settings",
+			options:  `other=1 NVreg_RegistryDwords="Foo=1;RmPVMRL=0x01"`,
+			policy:   vgpuSchedulingPolicyFixedShare,
+			expected: `other=1 NVreg_RegistryDwords="Foo=1;RmPVMRL=0x11"`,
+		},
+		{
+			name:     "remove policy",
+			options:  `NVreg_RegistryDwords="Foo=1;RmPVMRL=0x01"`,
+			policy:   "",
+			expected: `NVreg_RegistryDwords="Foo=1"`,
+		},
+		{
+			name:     "remove only setting",
+			options:  `other=1 NVreg_RegistryDwords="RmPVMRL=0x01"`,
+			policy:   "",
+			expected: "other=1",
+		},
+	}
+	for _, tc := range cases {
+		if actual := nvidiaOptionsWithSchedulingPolicy(tc.options, tc.policy); actual != tc.expected {
+			t.Fatalf("%q: expected %q, got %q", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestNvidiaDeviceSchedulingPoliciesFromOptions(t *testing.T) {
+	cases := []struct {
+		options  string
+		expected map[string]string
+	}{
+		{options: "", expected: map[string]string{}},
+		{options: `NVreg_RegistryDwords="RmPVMRL=0x01"`, expected: map[string]string{}},
+		{
+			options: `other=1 NVreg_RegistryDwordsPerDevice="pci=0000:d8:00.0;RmPVMRL=0x01;pci=0000:3b:00.0;Foo=1;RmPVMRL=0x11"`,
+			expected: map[string]string{
+				"0000:d8:00.0": vgpuSchedulingPolicyEqualShare,
+				"0000:3b:00.0": vgpuSchedulingPolicyFixedShare,
+			},
+		},
+		{
+			options:  `NVreg_RegistryDwordsPerDevice="pci=0000:d8:00.0;RmPVMRL=0x21;pci=0000:3b:00.0;Foo=1"`,
+			expected: map[string]string{"0000:d8:00.0": ""},
+		},
+	}
+	for _, tc := range cases {
+		if actual := nvidiaDeviceSchedulingPoliciesFromOptions(tc.options); !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("%q: expected %q, got %q", tc.options, tc.expected, actual)
+		}
+	}
+}
+
+func TestNvidiaOptionsWithDeviceSchedulingPolicies(t *testing.T) {
+	cases := []struct {
+		name     string
+		options  string
+		policies map[string]string
+		expected string
+	}{
+		{
+			name:     "no policies",
+			options:  `other=1`,
+			policies: nil,
+			expected: `other=1`,
+		},
+		{
+			name:     "add devices in order",
+			options:  `NVreg_RegistryDwords="RmPVMRL=0x01"`,
+			policies: map[string]string{"0000:d8:00.0": vgpuSchedulingPolicyFixedShare, "0000:3b:00.0": vgpuSchedulingPolicyBestEffort},
+			expected: `NVreg_RegistryDwords="RmPVMRL=0x01" NVreg_RegistryDwordsPerDevice="pci=0000:3b:00.0;RmPVMRL=0x00;pci=0000:d8:00.0;RmPVMRL=0x11"`,
+		},
+		{
+			name:     "replace policy, keep other devices and settings",
+			options:  `NVreg_RegistryDwordsPerDevice="pci=0000:3b:00.0;Foo=1;RmPVMRL=0x01;pci=0000:d8:00.0;RmPVMRL=0x01"`,
+			policies: map[string]string{"0000:3b:00.0": vgpuSchedulingPolicyFixedShare},
+			expected: `NVreg_RegistryDwordsPerDevice="pci=0000:3b:00.0;Foo=1;RmPVMRL=0x11;pci=0000:d8:00.0;RmPVMRL=0x01"`,
+		},
+		{
+			name:     "remove policy, drop device without settings",
+			options:  `other=1 NVreg_RegistryDwordsPerDevice="pci=0000:3b:00.0;Foo=1;RmPVMRL=0x01;pci=0000:d8:00.0;RmPVMRL=0x01"`,
+			policies: map[string]string{"0000:3b:00.0": "", "0000:d8:00.0": ""},
+			expected: `other=1 NVreg_RegistryDwordsPerDevice="pci=0000:3b:00.0;Foo=1"`,
+		},
+		{
+			name:     "remove only setting",
+			options:  `other=1 NVreg_RegistryDwordsPerDevice="pci=0000:d8:00.0;RmPVMRL=0x01"`,
+			policies: map[string]string{"0000:d8:00.0": ""},
+			expected: `other=1`,
+		},
+	}
+	for _, tc := range cases {
+		if actual := nvidiaOptionsWithDeviceSchedulingPolicies(tc.options, tc.policies); actual != tc.expected {
+			t.Fatalf("%q: expected %q, got %q", tc.name, tc.expected, actual)
+		}
+	}
+}
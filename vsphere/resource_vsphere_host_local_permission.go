@@ -0,0 +1,105 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostLocalPermission() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostLocalPermissionCreate,
+		Read:   resourceVSphereHostLocalPermissionRead,
+		Update: resourceVSphereHostLocalPermissionCreate,
+		Delete: resourceVSphereHostLocalPermissionDelete,
+
+		Schema: map[string]*schema.Schema{
+			"principal": {
+				Type:        schema.TypeString,
+				Description: "The user or group to grant the role to.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"is_group": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not principal is a group.",
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+			},
+			"role": {
+				Type:        schema.TypeString,
+				Description: "The name of the role to grant, such as ReadOnly or Admin.",
+				Required:    true,
+			},
+			"propagate": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the permission propagates to the objects on the host.",
+				Optional:    true,
+				Default:     true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostLocalPermissionCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateESXi(client); err != nil {
+		return err
+	}
+	role, err := permissionRoleByName(client, d.Get("role").(string))
+	if err != nil {
+		return err
+	}
+	principal := d.Get("principal").(string)
+	perm := types.Permission{
+		Principal: principal,
+		Group:     d.Get("is_group").(bool),
+		RoleId:    role.RoleId,
+		Propagate: d.Get("propagate").(bool),
+	}
+	if err := setEntityPermission(client, client.ServiceContent.RootFolder, perm); err != nil {
+		return viapi.NewDiagnostic(err, "error setting host permission")
+	}
+	d.SetId(principal)
+	return resourceVSphereHostLocalPermissionRead(d, meta)
+}
+
+func resourceVSphereHostLocalPermissionRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateESXi(client); err != nil {
+		return err
+	}
+	perm, err := entityPermission(client, client.ServiceContent.RootFolder, d.Id(), d.Get("is_group").(bool))
+	if err != nil {
+		return fmt.Errorf("error fetching host permissions: %s", err)
+	}
+	if perm == nil {
+		log.Printf("[DEBUG] Host permission for %q not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	role, err := permissionRoleByID(client, perm.RoleId)
+	if err != nil {
+		return err
+	}
+	d.Set("principal", perm.Principal)
+	d.Set("is_group", perm.Group)
+	d.Set("role", role.Name)
+	d.Set("propagate", perm.Propagate)
+	return nil
+}
+
+func resourceVSphereHostLocalPermissionDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateESXi(client); err != nil {
+		return err
+	}
+	if err := removeEntityPermission(client, client.ServiceContent.RootFolder, d.Id(), d.Get("is_group").(bool)); err != nil {
+		return viapi.NewDiagnostic(err, "error removing host permission")
+	}
+	return nil
+}
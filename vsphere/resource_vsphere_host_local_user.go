@@ -0,0 +1,202 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostLocalUser() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostLocalUserCreate,
+		Read:   resourceVSphereHostLocalUserRead,
+		Update: resourceVSphereHostLocalUserUpdate,
+		Delete: resourceVSphereHostLocalUserDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the local user.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"host_system_ids": {
+				Type:        schema.TypeSet,
+				Description: "The managed object IDs of the hosts to create the local user on.",
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "A description of the local user.",
+				Optional:    true,
+			},
+			"password": {
+				Type:             schema.TypeString,
+				Description:      "The password of the local user. This value is not saved to state, and is only sent to the hosts when the user is created, when password_version changes, or when hosts are added.",
+				Required:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: resourceVSphereHostLocalUserPasswordDiffSuppress,
+			},
+			"password_version": {
+				Type:         schema.TypeInt,
+				Description:  "A version number for password. Change this value to set a new password on all hosts.",
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+	}
+}
+
+func resourceVSphereHostLocalUserCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	spec := &types.HostAccountSpec{
+		Id:          name,
+		Password:    d.Get("password").(string),
+		Description: d.Get("description").(string),
+	}
+	var created []string
+	for _, hsID := range structure.SliceInterfacesToStrings(d.Get("host_system_ids").(*schema.Set).List()) {
+		if err := resourceVSphereHostLocalUserCreateOnHost(meta, hsID, spec); err != nil {
+			if len(created) > 0 {
+				// Save the hosts that the user was created on, so that the user can
+				// be removed from them.
+				d.SetId(name)
+				d.Set("host_system_ids", created)
+				d.Set("password", "")
+			}
+			return err
+		}
+		created = append(created, hsID)
+	}
+	d.SetId(name)
+	return resourceVSphereHostLocalUserRead(d, meta)
+}
+
+func resourceVSphereHostLocalUserRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	var hosts []string
+	for _, hsID := range structure.SliceInterfacesToStrings(d.Get("host_system_ids").(*schema.Set).List()) {
+		if _, err := hostsystem.FromID(client, hsID); err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) {
+				log.Printf("[DEBUG] Host %q for local user %q no longer exists", hsID, d.Id())
+				continue
+			}
+			return fmt.Errorf("error locating host %q: %s", hsID, err)
+		}
+		hosts = append(hosts, hsID)
+	}
+	if len(hosts) < 1 {
+		log.Printf("[DEBUG] None of the hosts for local user %q exist, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	d.Set("name", d.Id())
+	if err := d.Set("host_system_ids", hosts); err != nil {
+		return fmt.Errorf("error setting host_system_ids: %s", err)
+	}
+	// The password is never saved to state.
+	d.Set("password", "")
+	return nil
+}
+
+func resourceVSphereHostLocalUserUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Id()
+	o, n := d.GetChange("host_system_ids")
+	oldHosts, newHosts := o.(*schema.Set), n.(*schema.Set)
+
+	for _, hsID := range structure.SliceInterfacesToStrings(oldHosts.Difference(newHosts).List()) {
+		hs, err := hostsystem.FromID(client, hsID)
+		if err != nil {
+			return fmt.Errorf("error locating host %q: %s", hsID, err)
+		}
+		if err := hostsystem.RemoveLocalUser(hs, name); err != nil && !viapi.IsUserNotFoundError(err) {
+			return viapi.NewDiagnostic(err, "error removing local user from host %q", hsID)
+		}
+	}
+
+	spec := &types.HostAccountSpec{
+		Id:          name,
+		Password:    d.Get("password").(string),
+		Description: d.Get("description").(string),
+	}
+	for _, hsID := range structure.SliceInterfacesToStrings(newHosts.Difference(oldHosts).List()) {
+		if err := resourceVSphereHostLocalUserCreateOnHost(meta, hsID, spec); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("description") || d.HasChange("password_version") {
+		if !d.HasChange("password_version") {
+			spec.Password = ""
+		}
+		for _, hsID := range structure.SliceInterfacesToStrings(oldHosts.Intersection(newHosts).List()) {
+			hs, err := hostsystem.FromID(client, hsID)
+			if err != nil {
+				return fmt.Errorf("error locating host %q: %s", hsID, err)
+			}
+			if err := hostsystem.UpdateLocalUser(hs, spec); err != nil {
+				return viapi.NewDiagnostic(err, "error updating local user on host %q", hsID)
+			}
+		}
+	}
+	return resourceVSphereHostLocalUserRead(d, meta)
+}
+
+func resourceVSphereHostLocalUserDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	for _, hsID := range structure.SliceInterfacesToStrings(d.Get("host_system_ids").(*schema.Set).List()) {
+		hs, err := hostsystem.FromID(client, hsID)
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) {
+				continue
+			}
+			return fmt.Errorf("error locating host %q: %s", hsID, err)
+		}
+		if err := hostsystem.RemoveLocalUser(hs, d.Id()); err != nil && !viapi.IsUserNotFoundError(err) {
+			return viapi.NewDiagnostic(err, "error removing local user from host %q", hsID)
+		}
+	}
+	return nil
+}
+
+// resourceVSphereHostLocalUserCreateOnHost creates the local user on a single
+// host.
+func resourceVSphereHostLocalUserCreateOnHost(meta interface{}, hsID string, spec *types.HostAccountSpec) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error locating host %q: %s", hsID, err)
+	}
+	if err := hostsystem.CreateLocalUser(hs, spec); err != nil {
+		return viapi.NewDiagnostic(err, "error creating local user on host %q", hsID)
+	}
+	return nil
+}
+
+// resourceVSphereHostLocalUserPasswordDiffSuppress suppresses the diff for
+// password, which is never saved to state, unless the password needs to be
+// sent to the hosts. This happens when the user is created, when
+// password_version changes, and when hosts are added.
+func resourceVSphereHostLocalUserPasswordDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	if d.Id() == "" {
+		return false
+	}
+	if d.HasChange("password_version") {
+		return false
+	}
+	o, n := d.GetChange("host_system_ids")
+	if n.(*schema.Set).Difference(o.(*schema.Set)).Len() > 0 {
+		return false
+	}
+	return true
+}
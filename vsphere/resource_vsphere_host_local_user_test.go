@@ -0,0 +1,81 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereHostLocalUser_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereHostLocalUserPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: nil,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostLocalUserConfig("Passw0rd!1", 0),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_host_local_user.user", "id", "tf-acc-test"),
+					resource.TestCheckResourceAttr("vsphere_host_local_user.user", "password", ""),
+				),
+			},
+			{
+				// Changing only the password does not produce a diff.
+				Config:   testAccResourceVSphereHostLocalUserConfig("Passw0rd!2", 0),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccResourceVSphereHostLocalUserConfig("Passw0rd!2", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_host_local_user.user", "password", ""),
+					resource.TestCheckResourceAttr("vsphere_host_local_user.user", "password_version", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostLocalUserPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_local_user acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostLocalUserConfig(password string, version int) string {
+	return fmt.Sprintf(`
+variable "password" {
+  default = "%s"
+}
+
+variable "password_version" {
+  default = %d
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_local_user" "user" {
+  name             = "tf-acc-test"
+  description      = "terraform-test"
+  host_system_ids  = ["${data.vsphere_host.esxi_host.id}"]
+  password         = "${var.password}"
+  password_version = "${var.password_version}"
+}
+`,
+		password,
+		version,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+	)
+}
@@ -0,0 +1,187 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostLockdownDefaultDCUIAccess is the default value of the DCUI.Access
+// advanced option on ESXi, and the value that is restored when a
+// vsphere_host_lockdown resource is destroyed.
+var hostLockdownDefaultDCUIAccess = []string{"root"}
+
+var hostLockdownModeAllowedValues = []string{
+	string(types.HostLockdownModeLockdownDisabled),
+	string(types.HostLockdownModeLockdownNormal),
+	string(types.HostLockdownModeLockdownStrict),
+}
+
+func resourceVSphereHostLockdown() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostLockdownCreate,
+		Read:   resourceVSphereHostLockdownRead,
+		Update: resourceVSphereHostLockdownUpdate,
+		Delete: resourceVSphereHostLockdownDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to manage lockdown settings for.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"lockdown_mode": {
+				Type:         schema.TypeString,
+				Description:  "The lockdown mode of the host. Can be one of lockdownDisabled, lockdownNormal, or lockdownStrict.",
+				Optional:     true,
+				Default:      string(types.HostLockdownModeLockdownDisabled),
+				ValidateFunc: validation.StringInSlice(hostLockdownModeAllowedValues, false),
+			},
+			"exception_users": {
+				Type:        schema.TypeSet,
+				Description: "The users that keep their permissions on the host while it is in lockdown mode.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"dcui_access": {
+				Type:        schema.TypeSet,
+				Description: "The users that can log in to the DCUI of the host while it is in lockdown mode.",
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostLockdownCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	if err := vmcCheckHostOperation(meta, "vsphere_host_lockdown"); err != nil {
+		return err
+	}
+	hsID := d.Get("host_system_id").(string)
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostLockdownApply(d, meta, hs); err != nil {
+		return err
+	}
+	d.SetId(hsID)
+	return resourceVSphereHostLockdownRead(d, meta)
+}
+
+func resourceVSphereHostLockdownRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	mode, err := hostsystem.LockdownMode(client, hs)
+	if err != nil {
+		return fmt.Errorf("error fetching lockdown mode: %s", err)
+	}
+	users, err := hostsystem.LockdownExceptions(client, hs)
+	if err != nil {
+		return fmt.Errorf("error fetching lockdown exception users: %s", err)
+	}
+	dcui, err := hostsystem.DCUIAccess(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching DCUI access users: %s", err)
+	}
+
+	d.Set("host_system_id", d.Id())
+	d.Set("lockdown_mode", string(mode))
+	if err := d.Set("exception_users", users); err != nil {
+		return fmt.Errorf("error setting exception_users: %s", err)
+	}
+	if err := d.Set("dcui_access", dcui); err != nil {
+		return fmt.Errorf("error setting dcui_access: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereHostLockdownUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostLockdownApply(d, meta, hs); err != nil {
+		return err
+	}
+	return resourceVSphereHostLockdownRead(d, meta)
+}
+
+func resourceVSphereHostLockdownDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	// Lockdown mode is turned off first, so that the users that are about to be
+	// removed from the exceptions list do not lose access to the host.
+	if err := hostsystem.ChangeLockdownMode(client, hs, types.HostLockdownModeLockdownDisabled); err != nil {
+		return viapi.NewDiagnostic(err, "error disabling lockdown mode")
+	}
+	if err := hostsystem.UpdateLockdownExceptions(client, hs, []string{}); err != nil {
+		return viapi.NewDiagnostic(err, "error removing lockdown exception users")
+	}
+	if err := hostsystem.UpdateDCUIAccess(hs, hostLockdownDefaultDCUIAccess); err != nil {
+		return viapi.NewDiagnostic(err, "error resetting DCUI access users")
+	}
+	return nil
+}
+
+// resourceVSphereHostLockdownApply applies the lockdown settings in the
+// resource data to a host. The exception and DCUI access lists are applied
+// before the lockdown mode, so that the users in them are not locked out when
+// lockdown mode is turned on.
+func resourceVSphereHostLockdownApply(d *schema.ResourceData, meta interface{}, hs *object.HostSystem) error {
+	client := meta.(*VSphereClient).vimClient
+	if d.IsNewResource() || d.HasChange("exception_users") {
+		users := resourceVSphereHostLockdownSortedSet(d, "exception_users")
+		if err := hostsystem.UpdateLockdownExceptions(client, hs, users); err != nil {
+			return viapi.NewDiagnostic(err, "error updating lockdown exception users")
+		}
+	}
+	if _, ok := d.GetOk("dcui_access"); ok && (d.IsNewResource() || d.HasChange("dcui_access")) {
+		users := resourceVSphereHostLockdownSortedSet(d, "dcui_access")
+		if err := hostsystem.UpdateDCUIAccess(hs, users); err != nil {
+			return viapi.NewDiagnostic(err, "error updating DCUI access users")
+		}
+	}
+	if d.IsNewResource() || d.HasChange("lockdown_mode") {
+		mode := types.HostLockdownMode(d.Get("lockdown_mode").(string))
+		if err := hostsystem.ChangeLockdownMode(client, hs, mode); err != nil {
+			return viapi.NewDiagnostic(err, "error changing lockdown mode")
+		}
+	}
+	return nil
+}
+
+// resourceVSphereHostLockdownSortedSet returns the strings in a set attribute
+// in sorted order.
+func resourceVSphereHostLockdownSortedSet(d *schema.ResourceData, key string) []string {
+	s := structure.SliceInterfacesToStrings(d.Get(key).(*schema.Set).List())
+	sort.Strings(s)
+	return s
+}
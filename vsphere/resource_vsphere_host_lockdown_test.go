@@ -0,0 +1,104 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereHostLockdown_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereHostLockdownPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereHostLockdownMatchesMode(types.HostLockdownModeLockdownDisabled),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostLockdownConfig("lockdownNormal"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereHostLockdownMatchesMode(types.HostLockdownModeLockdownNormal),
+					resource.TestCheckResourceAttr("vsphere_host_lockdown.lockdown", "exception_users.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_host_lockdown.lockdown", "dcui_access.#", "1"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereHostLockdownConfig("lockdownDisabled"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereHostLockdownMatchesMode(types.HostLockdownModeLockdownDisabled),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostLockdownPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_lockdown acceptance tests")
+	}
+	if os.Getenv("VSPHERE_LOCKDOWN_EXCEPTION_USER") == "" {
+		t.Skip("set VSPHERE_LOCKDOWN_EXCEPTION_USER to run vsphere_host_lockdown acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostLockdownMatchesMode(expected types.HostLockdownMode) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return err
+		}
+		hs, err := hostsystem.SystemOrDefault(client, os.Getenv("VSPHERE_ESXI_HOST"), dc)
+		if err != nil {
+			return err
+		}
+		actual, err := hostsystem.LockdownMode(client, hs)
+		if err != nil {
+			return err
+		}
+		if actual != expected {
+			return fmt.Errorf("expected lockdown mode to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostLockdownConfig(mode string) string {
+	return fmt.Sprintf(`
+variable "lockdown_mode" {
+  default = "%s"
+}
+
+variable "exception_user" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_lockdown" "lockdown" {
+  host_system_id  = "${data.vsphere_host.esxi_host.id}"
+  lockdown_mode   = "${var.lockdown_mode}"
+  exception_users = ["${var.exception_user}"]
+  dcui_access     = ["root"]
+}
+`,
+		mode,
+		os.Getenv("VSPHERE_LOCKDOWN_EXCEPTION_USER"),
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+	)
+}
@@ -0,0 +1,341 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereHostPciPassthrough() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostPciPassthroughCreate,
+		Read:   resourceVSphereHostPciPassthroughRead,
+		Update: resourceVSphereHostPciPassthroughUpdate,
+		Delete: resourceVSphereHostPciPassthroughDelete,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to configure the devices of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"passthrough_devices": {
+				Type:        schema.TypeSet,
+				Description: "The PCI IDs of the devices to enable for passthrough to virtual machines.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"sriov": {
+				Type:        schema.TypeSet,
+				Description: "The network adapters to enable SR-IOV on.",
+				Optional:    true,
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"device_id": {
+						Type:        schema.TypeString,
+						Description: "The PCI ID of the network adapter.",
+						Required:    true,
+					},
+					"num_virtual_functions": {
+						Type:         schema.TypeInt,
+						Description:  "The number of virtual functions to create on the network adapter.",
+						Required:     true,
+						ValidateFunc: validation.IntAtLeast(1),
+					},
+				}},
+			},
+			"reboot": {
+				Type:        schema.TypeBool,
+				Description: "Put the host in maintenance mode and reboot it when changes only take effect after a reboot.",
+				Optional:    true,
+			},
+			"reboot_timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for the host to enter maintenance mode and to reboot.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"reboot_pending": {
+				Type:        schema.TypeBool,
+				Description: "Whether or not the host needs to be rebooted for the settings of the devices to take effect.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereHostPciPassthroughCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, "vsphere_host_pci_passthrough"); err != nil {
+		return err
+	}
+	hsID := d.Get("host_system_id").(string)
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostPciPassthroughApply(d, client, hs, false); err != nil {
+		return err
+	}
+	d.SetId(hsID)
+	return resourceVSphereHostPciPassthroughRead(d, meta)
+}
+
+func resourceVSphereHostPciPassthroughRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	infos, err := hostsystem.PciPassthruInfo(client, hs)
+	if err != nil {
+		return viapi.NewDiagnostic(err, "error fetching PCI passthrough settings of host %q", hs.Name())
+	}
+	byID := hostPciPassthroughInfoByID(infos)
+
+	// Only the devices that this resource manages are read, as other devices
+	// can depend on them and be enabled along with them.
+	pending := false
+	var passthrough []interface{}
+	for _, id := range d.Get("passthrough_devices").(*schema.Set).List() {
+		info, ok := byID[id.(string)]
+		if !ok {
+			continue
+		}
+		pending = pending || hostPciPassthroughRebootPending(info)
+		if info.GetHostPciPassthruInfo().PassthruEnabled {
+			passthrough = append(passthrough, id)
+		}
+	}
+	var sriov []interface{}
+	for _, v := range d.Get("sriov").(*schema.Set).List() {
+		id := v.(map[string]interface{})["device_id"].(string)
+		info, ok := byID[id].(*types.HostSriovInfo)
+		if !ok {
+			continue
+		}
+		pending = pending || hostPciPassthroughRebootPending(info)
+		if info.SriovEnabled {
+			sriov = append(sriov, map[string]interface{}{
+				"device_id":             id,
+				"num_virtual_functions": int(info.NumVirtualFunctionRequested),
+			})
+		}
+	}
+
+	d.Set("host_system_id", d.Id())
+	if err := d.Set("passthrough_devices", passthrough); err != nil {
+		return fmt.Errorf("error setting passthrough_devices: %s", err)
+	}
+	if err := d.Set("sriov", sriov); err != nil {
+		return fmt.Errorf("error setting sriov: %s", err)
+	}
+	d.Set("reboot_pending", pending)
+	return nil
+}
+
+func resourceVSphereHostPciPassthroughUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostPciPassthroughApply(d, client, hs, false); err != nil {
+		return err
+	}
+	return resourceVSphereHostPciPassthroughRead(d, meta)
+}
+
+func resourceVSphereHostPciPassthroughDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	return resourceVSphereHostPciPassthroughApply(d, client, hs, true)
+}
+
+// resourceVSphereHostPciPassthroughApply enables passthrough and SR-IOV on the
+// devices in configuration, and disables them on the devices that were
+// removed from configuration, or on all managed devices if destroy is true.
+// The host is rebooted afterwards if reboot is set and a change is pending.
+func resourceVSphereHostPciPassthroughApply(d *schema.ResourceData, client *govmomi.Client, hs *object.HostSystem, destroy bool) error {
+	infos, err := hostsystem.PciPassthruInfo(client, hs)
+	if err != nil {
+		return viapi.NewDiagnostic(err, "error fetching PCI passthrough settings of host %q", hs.Name())
+	}
+	byID := hostPciPassthroughInfoByID(infos)
+
+	o, n := d.GetChange("passthrough_devices")
+	oldPassthrough := o.(*schema.Set)
+	newPassthrough := n.(*schema.Set)
+	o, n = d.GetChange("sriov")
+	oldSriov := hostPciPassthroughSriovByID(o.(*schema.Set))
+	newSriov := hostPciPassthroughSriovByID(n.(*schema.Set))
+	if destroy {
+		oldPassthrough = oldPassthrough.Union(newPassthrough)
+		newPassthrough = schema.NewSet(schema.HashString, nil)
+		for id, num := range newSriov {
+			oldSriov[id] = num
+		}
+		newSriov = make(map[string]int)
+	}
+
+	var config []types.BaseHostPciPassthruConfig
+	managed := make(map[string]bool)
+	for _, v := range newPassthrough.List() {
+		id := v.(string)
+		if _, ok := newSriov[id]; ok {
+			return fmt.Errorf("device %q cannot be in both passthrough_devices and sriov", id)
+		}
+		info, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("device %q not found on host %q", id, hs.Name())
+		}
+		if !info.GetHostPciPassthruInfo().PassthruCapable {
+			return fmt.Errorf("device %q on host %q does not support passthrough", id, hs.Name())
+		}
+		managed[id] = true
+		config = append(config, &types.HostPciPassthruConfig{
+			Id:              id,
+			PassthruEnabled: true,
+		})
+	}
+	for id, num := range newSriov {
+		info, ok := byID[id].(*types.HostSriovInfo)
+		if !ok || !info.SriovCapable {
+			return fmt.Errorf("device %q on host %q does not support SR-IOV", id, hs.Name())
+		}
+		if int32(num) > info.MaxVirtualFunctionSupported {
+			return fmt.Errorf("device %q on host %q supports at most %d virtual functions", id, hs.Name(), info.MaxVirtualFunctionSupported)
+		}
+		managed[id] = true
+		config = append(config, &types.HostSriovConfig{
+			HostPciPassthruConfig: types.HostPciPassthruConfig{
+				Id: id,
+			},
+			SriovEnabled:       true,
+			NumVirtualFunction: int32(num),
+		})
+	}
+	for _, v := range oldPassthrough.List() {
+		id := v.(string)
+		if _, ok := byID[id]; ok && !managed[id] {
+			managed[id] = true
+			config = append(config, &types.HostPciPassthruConfig{
+				Id:              id,
+				PassthruEnabled: false,
+			})
+		}
+	}
+	for id := range oldSriov {
+		if _, ok := byID[id].(*types.HostSriovInfo); ok && !managed[id] {
+			managed[id] = true
+			config = append(config, &types.HostSriovConfig{
+				HostPciPassthruConfig: types.HostPciPassthruConfig{
+					Id: id,
+				},
+				SriovEnabled: false,
+			})
+		}
+	}
+
+	if len(config) > 0 {
+		if err := hostsystem.UpdatePassthruConfig(client, hs, config); err != nil {
+			return viapi.NewDiagnostic(err, "error updating PCI passthrough settings of host %q", hs.Name())
+		}
+	}
+	if !d.Get("reboot").(bool) {
+		return nil
+	}
+
+	infos, err = hostsystem.PciPassthruInfo(client, hs)
+	if err != nil {
+		return viapi.NewDiagnostic(err, "error fetching PCI passthrough settings of host %q", hs.Name())
+	}
+	pending := false
+	for _, info := range infos {
+		if managed[info.GetHostPciPassthruInfo().Id] && hostPciPassthroughRebootPending(info) {
+			pending = true
+		}
+	}
+	if !pending {
+		return nil
+	}
+	timeout := time.Duration(d.Get("reboot_timeout").(int)) * time.Minute
+	return resourceVSphereHostPciPassthroughReboot(client, hs, timeout)
+}
+
+// resourceVSphereHostPciPassthroughReboot puts a host in maintenance mode,
+// reboots it, and takes it out of maintenance mode again. A host that was in
+// maintenance mode already is left there.
+func resourceVSphereHostPciPassthroughReboot(client *govmomi.Client, hs *object.HostSystem, timeout time.Duration) error {
+	props, err := hostsystem.Properties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	inMaintenanceMode := props.Runtime.InMaintenanceMode
+	log.Printf("[DEBUG] Host %q: rebooting for PCI passthrough changes (in maintenance mode: %t)", hs.Name(), inMaintenanceMode)
+	if !inMaintenanceMode {
+		if err := hostsystem.EnterMaintenanceMode(hs, timeout, false); err != nil {
+			return viapi.NewDiagnostic(err, "error putting host %q in maintenance mode", hs.Name())
+		}
+	}
+	if err := hostsystem.Reboot(client, hs, timeout); err != nil {
+		return viapi.NewDiagnostic(err, "error rebooting host %q", hs.Name())
+	}
+	if !inMaintenanceMode {
+		if err := hostsystem.ExitMaintenanceMode(hs, timeout); err != nil {
+			return viapi.NewDiagnostic(err, "error taking host %q out of maintenance mode", hs.Name())
+		}
+	}
+	return nil
+}
+
+// hostPciPassthroughInfoByID indexes the PCI passthrough information of the
+// devices of a host by PCI ID.
+func hostPciPassthroughInfoByID(infos []types.BaseHostPciPassthruInfo) map[string]types.BaseHostPciPassthruInfo {
+	byID := make(map[string]types.BaseHostPciPassthruInfo)
+	for _, info := range infos {
+		byID[info.GetHostPciPassthruInfo().Id] = info
+	}
+	return byID
+}
+
+// hostPciPassthroughSriovByID returns the number of virtual functions in a
+// set of sriov blocks, indexed by PCI ID.
+func hostPciPassthroughSriovByID(s *schema.Set) map[string]int {
+	byID := make(map[string]int)
+	for _, v := range s.List() {
+		m := v.(map[string]interface{})
+		byID[m["device_id"].(string)] = m["num_virtual_functions"].(int)
+	}
+	return byID
+}
+
+// hostPciPassthroughRebootPending returns true if the passthrough or SR-IOV
+// settings of a device differ from the ones that are active, which means that
+// the host needs to be rebooted.
+func hostPciPassthroughRebootPending(info types.BaseHostPciPassthruInfo) bool {
+	i := info.GetHostPciPassthruInfo()
+	if i.PassthruEnabled != i.PassthruActive {
+		return true
+	}
+	if s, ok := info.(*types.HostSriovInfo); ok {
+		return s.SriovEnabled != s.SriovActive || (s.SriovEnabled && s.NumVirtualFunction != s.NumVirtualFunctionRequested)
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestHostPciPassthroughRebootPending(t *testing.T) {
+	cases := []struct {
+		name     string
+		info     types.BaseHostPciPassthruInfo
+		expected bool
+	}{
+		{
+			name:     "passthrough active",
+			info:     &types.HostPciPassthruInfo{PassthruEnabled: true, PassthruActive: true},
+			expected: false,
+		},
+		{
+			name:     "passthrough enabled, not active",
+			info:     &types.HostPciPassthruInfo{PassthruEnabled: true},
+			expected: true,
+		},
+		{
+			name:     "passthrough disabled, still active",
+			info:     &types.HostPciPassthruInfo{PassthruActive: true},
+			expected: true,
+		},
+		{
+			name: "sriov active",
+			info: &types.HostSriovInfo{
+				SriovEnabled:                true,
+				SriovActive:                 true,
+				NumVirtualFunctionRequested: 8,
+				NumVirtualFunction:          8,
+			},
+			expected: false,
+		},
+		{
+			name: "sriov virtual functions changed",
+			info: &types.HostSriovInfo{
+				SriovEnabled:                true,
+				SriovActive:                 true,
+				NumVirtualFunctionRequested: 16,
+				NumVirtualFunction:          8,
+			},
+			expected: true,
+		},
+		{
+			name:     "sriov disabled, still active",
+			info:     &types.HostSriovInfo{SriovActive: true},
+			expected: true,
+		},
+	}
+	for _, tc := range cases {
+		if actual := hostPciPassthroughRebootPending(tc.info); actual != tc.expected {
+			t.Fatalf("%q: expected %t, got %t", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestAccResourceVSphereHostPciPassthrough_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereHostPciPassthroughPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereHostPciPassthroughEnabled(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostPciPassthroughConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereHostPciPassthroughEnabled(true),
+					resource.TestCheckResourceAttr("vsphere_host_pci_passthrough.passthrough", "passthrough_devices.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostPciPassthroughPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_PCI_PASSTHROUGH_DEVICE") == "" {
+		t.Skip("set VSPHERE_PCI_PASSTHROUGH_DEVICE to the PCI ID of a device on VSPHERE_ESXI_HOST to run vsphere_host_pci_passthrough acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostPciPassthroughEnabled(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return err
+		}
+		hs, err := hostsystem.SystemOrDefault(client, os.Getenv("VSPHERE_ESXI_HOST"), dc)
+		if err != nil {
+			return err
+		}
+		infos, err := hostsystem.PciPassthruInfo(client, hs)
+		if err != nil {
+			return err
+		}
+		id := os.Getenv("VSPHERE_PCI_PASSTHROUGH_DEVICE")
+		info, ok := hostPciPassthroughInfoByID(infos)[id]
+		if !ok {
+			return fmt.Errorf("device %q not found", id)
+		}
+		if actual := info.GetHostPciPassthruInfo().PassthruEnabled; actual != expected {
+			return fmt.Errorf("expected passthrough of device %q to be %t, got %t", id, expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostPciPassthroughConfig() string {
+	return fmt.Sprintf(`
+variable "passthrough_device" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_pci_passthrough" "passthrough" {
+  host_system_id      = "${data.vsphere_host.host.id}"
+  passthrough_devices = ["${var.passthrough_device}"]
+}
+`,
+		os.Getenv("VSPHERE_PCI_PASSTHROUGH_DEVICE"),
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+	)
+}
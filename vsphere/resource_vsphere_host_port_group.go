@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 )
 
 func resourceVSphereHostPortGroup() *schema.Resource {
@@ -41,18 +42,26 @@ func resourceVSphereHostPortGroup() *schema.Resource {
 	// specifically for this resource.
 	s["active_nics"].Optional = true
 	s["standby_nics"].Optional = true
+	s["name"].DiffSuppressFunc = hostPortGroupNameRule.diffSuppressFunc()
 
 	return &schema.Resource{
-		Create: resourceVSphereHostPortGroupCreate,
-		Read:   resourceVSphereHostPortGroupRead,
-		Update: resourceVSphereHostPortGroupUpdate,
-		Delete: resourceVSphereHostPortGroupDelete,
-		Schema: s,
+		Create:        resourceVSphereHostPortGroupCreate,
+		Read:          resourceVSphereHostPortGroupRead,
+		Update:        resourceVSphereHostPortGroupUpdate,
+		Delete:        resourceVSphereHostPortGroupDelete,
+		CustomizeDiff: hostPortGroupNameRule.customizeDiff,
+		Schema:        s,
 	}
 }
 
 func resourceVSphereHostPortGroupCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, "vsphere_host_port_group"); err != nil {
+		return err
+	}
+	if err := hostPortGroupNameRule.apply(d, meta); err != nil {
+		return err
+	}
 	name := d.Get("name").(string)
 	hsID := d.Get("host_system_id").(string)
 	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
@@ -108,6 +117,9 @@ func resourceVSphereHostPortGroupRead(d *schema.ResourceData, meta interface{})
 
 func resourceVSphereHostPortGroupUpdate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := hostPortGroupNameRule.apply(d, meta); err != nil {
+		return err
+	}
 	hsID, name, err := portGroupIDsFromResourceID(d)
 	if err != nil {
 		return err
@@ -141,7 +153,7 @@ func resourceVSphereHostPortGroupDelete(d *schema.ResourceData, meta interface{}
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
 	if err := ns.RemovePortGroup(ctx, name); err != nil {
-		return fmt.Errorf("error deleting port group: %s", err)
+		return viapi.NewDiagnostic(err, "error deleting port group")
 	}
 
 	return nil
@@ -0,0 +1,226 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// The following are the power states of the vsphere_host_power_state
+// resource.
+const (
+	hostPowerStateOn      = "on"
+	hostPowerStateStandby = "standby"
+	hostPowerStateOff     = "off"
+)
+
+var hostPowerStateAllowedValues = []string{
+	hostPowerStateOn,
+	hostPowerStateStandby,
+	hostPowerStateOff,
+}
+
+func resourceVSphereHostPowerState() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostPowerStateCreate,
+		Read:   resourceVSphereHostPowerStateRead,
+		Update: resourceVSphereHostPowerStateUpdate,
+		Delete: resourceVSphereHostPowerStateDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to manage the power state of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"power_state": {
+				Type:         schema.TypeString,
+				Description:  "The power state of the host. Can be one of on, standby, or off.",
+				Optional:     true,
+				Default:      hostPowerStateOn,
+				ValidateFunc: validation.StringInSlice(hostPowerStateAllowedValues, false),
+			},
+			"timeout": {
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for the host to evacuate and to change its power state.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"ipmi": {
+				Type:        schema.TypeList,
+				Description: "The IPMI or iLO settings that vCenter uses to power on the host from standby mode.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"bmc_ip_address": {
+						Type:        schema.TypeString,
+						Description: "The IP address of the baseboard management controller of the host.",
+						Required:    true,
+					},
+					"bmc_mac_address": {
+						Type:        schema.TypeString,
+						Description: "The MAC address of the baseboard management controller of the host.",
+						Required:    true,
+					},
+					"login": {
+						Type:        schema.TypeString,
+						Description: "The user to log in to the baseboard management controller as.",
+						Required:    true,
+					},
+					"password": {
+						Type:        schema.TypeString,
+						Description: "The password of login.",
+						Required:    true,
+						Sensitive:   true,
+					},
+				}},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostPowerStateCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return err
+	}
+	if err := vmcCheckHostOperation(meta, "vsphere_host_power_state"); err != nil {
+		return err
+	}
+	hsID := d.Get("host_system_id").(string)
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostPowerStateApply(d, meta, hs); err != nil {
+		return err
+	}
+	d.SetId(hsID)
+	return resourceVSphereHostPowerStateRead(d, meta)
+}
+
+func resourceVSphereHostPowerStateRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	props, err := hostsystem.Properties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	d.Set("host_system_id", d.Id())
+	d.Set("power_state", resourceVSphereHostPowerStateFromRuntime(props.Runtime))
+	return nil
+}
+
+func resourceVSphereHostPowerStateUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hs, err := hostsystem.FromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error locating host: %s", err)
+	}
+	if err := resourceVSphereHostPowerStateApply(d, meta, hs); err != nil {
+		return err
+	}
+	return resourceVSphereHostPowerStateRead(d, meta)
+}
+
+func resourceVSphereHostPowerStateDelete(d *schema.ResourceData, meta interface{}) error {
+	// The host is left in its current power state, so that destroying the
+	// resource does not power on or shut down hosts.
+	log.Printf("[DEBUG] Removing power state of host %q from state, host is left as is", d.Id())
+	return nil
+}
+
+// resourceVSphereHostPowerStateApply applies the IPMI settings, and moves the
+// host to the power state in configuration.
+func resourceVSphereHostPowerStateApply(d *schema.ResourceData, meta interface{}, hs *object.HostSystem) error {
+	client := meta.(*VSphereClient).vimClient
+	if d.HasChange("ipmi") {
+		var info types.HostIpmiInfo
+		if len(d.Get("ipmi").([]interface{})) > 0 {
+			info = types.HostIpmiInfo{
+				BmcIpAddress:  d.Get("ipmi.0.bmc_ip_address").(string),
+				BmcMacAddress: d.Get("ipmi.0.bmc_mac_address").(string),
+				Login:         d.Get("ipmi.0.login").(string),
+				Password:      d.Get("ipmi.0.password").(string),
+			}
+		}
+		if err := hostsystem.UpdateIPMI(client, hs, info); err != nil {
+			return viapi.NewDiagnostic(err, "error updating IPMI settings of host %q", hs.Name())
+		}
+	}
+
+	props, err := hostsystem.Properties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching host properties: %s", err)
+	}
+	current := resourceVSphereHostPowerStateFromRuntime(props.Runtime)
+	desired := d.Get("power_state").(string)
+	timeout := time.Duration(d.Get("timeout").(int)) * time.Minute
+	log.Printf("[DEBUG] Host %q: current power state %q, desired power state %q", hs.Name(), current, desired)
+	if current == desired {
+		return nil
+	}
+
+	switch desired {
+	case hostPowerStateOn:
+		if current != hostPowerStateStandby {
+			return fmt.Errorf("host %q is powered off and cannot be powered on through vCenter, only hosts in standby mode can", hs.Name())
+		}
+		if err := hostsystem.PowerUpFromStandby(client, hs, timeout); err != nil {
+			return viapi.NewDiagnostic(err, "error powering on host %q from standby mode", hs.Name())
+		}
+	case hostPowerStateStandby:
+		if current != hostPowerStateOn {
+			return fmt.Errorf("host %q is powered off and cannot be put in standby mode", hs.Name())
+		}
+		if err := hostsystem.PowerDownToStandby(client, hs, timeout); err != nil {
+			return viapi.NewDiagnostic(err, "error putting host %q in standby mode", hs.Name())
+		}
+	case hostPowerStateOff:
+		if current != hostPowerStateOn {
+			return fmt.Errorf("host %q is in standby mode and must be powered on before it can be shut down", hs.Name())
+		}
+		if !props.Runtime.InMaintenanceMode {
+			if err := hostsystem.EnterMaintenanceMode(hs, timeout, true); err != nil {
+				return viapi.NewDiagnostic(err, "error putting host %q in maintenance mode", hs.Name())
+			}
+		}
+		if err := hostsystem.Shutdown(client, hs); err != nil {
+			return viapi.NewDiagnostic(err, "error shutting down host %q", hs.Name())
+		}
+	}
+	return nil
+}
+
+// resourceVSphereHostPowerStateFromRuntime returns the power state of a host
+// from its runtime information. A host that has been shut down is no longer
+// responding, so its power state is unknown to vCenter, and it is reported as
+// off.
+func resourceVSphereHostPowerStateFromRuntime(runtime types.HostRuntimeInfo) string {
+	switch runtime.PowerState {
+	case types.HostSystemPowerStatePoweredOn:
+		return hostPowerStateOn
+	case types.HostSystemPowerStateStandBy:
+		return hostPowerStateStandby
+	}
+	return hostPowerStateOff
+}
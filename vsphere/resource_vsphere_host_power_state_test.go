@@ -0,0 +1,90 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+)
+
+func TestAccResourceVSphereHostPowerState_standby(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereHostPowerStatePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostPowerStateConfig("standby"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereHostPowerStateMatches("standby"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereHostPowerStateConfig("on"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereHostPowerStateMatches("on"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostPowerStatePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_STANDBY_HOST") == "" {
+		t.Skip("set VSPHERE_STANDBY_HOST to a host that supports standby mode to run vsphere_host_power_state acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostPowerStateMatches(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		dc, err := getDatacenter(client, os.Getenv("VSPHERE_DATACENTER"))
+		if err != nil {
+			return err
+		}
+		hs, err := hostsystem.SystemOrDefault(client, os.Getenv("VSPHERE_STANDBY_HOST"), dc)
+		if err != nil {
+			return err
+		}
+		props, err := hostsystem.Properties(hs)
+		if err != nil {
+			return err
+		}
+		if actual := resourceVSphereHostPowerStateFromRuntime(props.Runtime); actual != expected {
+			return fmt.Errorf("expected power state to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereHostPowerStateConfig(state string) string {
+	return fmt.Sprintf(`
+variable "power_state" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_power_state" "power" {
+  host_system_id = "${data.vsphere_host.host.id}"
+  power_state    = "${var.power_state}"
+}
+`,
+		state,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_STANDBY_HOST"),
+	)
+}
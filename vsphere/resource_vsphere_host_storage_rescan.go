@@ -0,0 +1,200 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereHostStorageRescanName = "vsphere_host_storage_rescan"
+
+func resourceVSphereHostStorageRescan() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereHostStorageRescanCreate,
+		Read:   resourceVSphereHostStorageRescanRead,
+		Delete: resourceVSphereHostStorageRescanDelete,
+
+		Schema: map[string]*schema.Schema{
+			"host_system_ids": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "The managed object IDs of the hosts to rescan.",
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"compute_cluster_id"},
+			},
+			"compute_cluster_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Description:   "The managed object ID of a cluster to rescan all connected hosts of.",
+				ConflictsWith: []string{"host_system_ids"},
+			},
+			"rescan_hba": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Rescan all host bus adapters for new storage devices.",
+			},
+			"rescan_vmfs": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Rescan for new VMFS volumes, after the host bus adapters are rescanned.",
+			},
+			"rescan_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ForceNew:     true,
+				Description:  "The time, in minutes, to wait for the rescan of each host to complete.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Arbitrary values that cause the hosts to be rescanned again when they change.",
+			},
+			"rescanned_host_system_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The managed object IDs of the hosts that were rescanned.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"last_rescan_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The time that the hosts were rescanned, in RFC3339 format.",
+			},
+		},
+	}
+}
+
+func resourceVSphereHostStorageRescanCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereHostStorageRescanIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, resourceVSphereHostStorageRescanName); err != nil {
+		return err
+	}
+	hosts, err := resourceVSphereHostStorageRescanHosts(d, client)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(d.Get("rescan_timeout").(int)) * time.Minute
+	for _, hsID := range hosts {
+		if err := rescanHostStorage(client, hsID, d.Get("rescan_hba").(bool), d.Get("rescan_vmfs").(bool), timeout); err != nil {
+			return err
+		}
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return fmt.Errorf("error generating ID: %s", err)
+	}
+	d.SetId(id)
+	if err := d.Set("rescanned_host_system_ids", hosts); err != nil {
+		return fmt.Errorf("error setting rescanned_host_system_ids: %s", err)
+	}
+	d.Set("last_rescan_time", time.Now().UTC().Format(time.RFC3339))
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereHostStorageRescanIDString(d))
+	return nil
+}
+
+func resourceVSphereHostStorageRescanRead(d *schema.ResourceData, meta interface{}) error {
+	// A rescan is a one-off operation, so there is nothing to read back.
+	return nil
+}
+
+func resourceVSphereHostStorageRescanDelete(d *schema.ResourceData, meta interface{}) error {
+	// A rescan cannot be undone, so this only removes the resource from state.
+	log.Printf("[DEBUG] %s: Removing from state", resourceVSphereHostStorageRescanIDString(d))
+	return nil
+}
+
+// resourceVSphereHostStorageRescanHosts returns the IDs of the hosts to
+// rescan: the hosts in host_system_ids, or the connected hosts of
+// compute_cluster_id.
+func resourceVSphereHostStorageRescanHosts(d *schema.ResourceData, client *govmomi.Client) ([]string, error) {
+	if hosts := structure.SliceInterfacesToStrings(d.Get("host_system_ids").([]interface{})); len(hosts) > 0 {
+		return hosts, nil
+	}
+	clusterID := d.Get("compute_cluster_id").(string)
+	if clusterID == "" {
+		return nil, fmt.Errorf("one of host_system_ids or compute_cluster_id must be set")
+	}
+	cluster, err := computeresource.ClusterFromID(client, clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	props, err := computeresource.BaseProperties(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cluster properties: %s", err)
+	}
+	var hosts []string
+	for _, ref := range props.Host {
+		hs, err := hostsystem.FromID(client, ref.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error locating host %q: %s", ref.Value, err)
+		}
+		hprops, err := hostsystem.Properties(hs)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching properties of host %q: %s", hs.Name(), err)
+		}
+		// Hosts that are disconnected or not responding cannot be rescanned, and
+		// will rescan their storage when they reconnect.
+		if hprops.Runtime.ConnectionState != types.HostSystemConnectionStateConnected {
+			log.Printf("[DEBUG] Skipping host %q: host is %s", hs.Name(), hprops.Runtime.ConnectionState)
+			continue
+		}
+		hosts = append(hosts, ref.Value)
+	}
+	if len(hosts) < 1 {
+		return nil, fmt.Errorf("cluster %q has no connected hosts", cluster.InventoryPath)
+	}
+	return hosts, nil
+}
+
+// rescanHostStorage rescans the host bus adapters of a host for new storage
+// devices, and then rescans its devices for new VMFS volumes, so that newly
+// presented storage can be used right away.
+func rescanHostStorage(client *govmomi.Client, hsID string, hba, vmfs bool, timeout time.Duration) error {
+	ss, err := hostStorageSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading storage system of host %q: %s", hsID, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if hba {
+		log.Printf("[DEBUG] Rescanning host bus adapters on host %q", hsID)
+		if err := ss.RescanAllHba(ctx); err != nil {
+			return viapi.NewDiagnostic(err, "error rescanning host bus adapters on host %q", hostsystem.NameOrID(client, hsID))
+		}
+	}
+	if vmfs {
+		log.Printf("[DEBUG] Rescanning VMFS volumes on host %q", hsID)
+		if err := ss.RescanVmfs(ctx); err != nil {
+			return viapi.NewDiagnostic(err, "error rescanning VMFS volumes on host %q", hostsystem.NameOrID(client, hsID))
+		}
+	}
+	return nil
+}
+
+// resourceVSphereHostStorageRescanIDString prints a friendly string for the
+// vsphere_host_storage_rescan resource.
+func resourceVSphereHostStorageRescanIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereHostStorageRescanName)
+}
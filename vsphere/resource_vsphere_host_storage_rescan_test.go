@@ -0,0 +1,68 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereHostStorageRescan_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereHostStorageRescanPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereHostStorageRescanConfig("one"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_host_storage_rescan.rescan", "rescanned_host_system_ids.#", "1"),
+					resource.TestCheckResourceAttrSet("vsphere_host_storage_rescan.rescan", "last_rescan_time"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereHostStorageRescanConfig("two"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_host_storage_rescan.rescan", "triggers.run", "two"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereHostStorageRescanPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_host_storage_rescan acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_host_storage_rescan acceptance tests")
+	}
+}
+
+func testAccResourceVSphereHostStorageRescanConfig(run string) string {
+	return fmt.Sprintf(`
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_storage_rescan" "rescan" {
+  host_system_ids = ["${data.vsphere_host.esxi_host.id}"]
+
+  triggers = {
+    run = "%s"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		run,
+	)
+}
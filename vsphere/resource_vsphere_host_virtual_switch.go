@@ -7,6 +7,7 @@ import (
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
 )
 
 func resourceVSphereHostVirtualSwitch() *schema.Resource {
@@ -54,6 +55,9 @@ func resourceVSphereHostVirtualSwitch() *schema.Resource {
 
 func resourceVSphereHostVirtualSwitchCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, "vsphere_host_virtual_switch"); err != nil {
+		return err
+	}
 	name := d.Get("name").(string)
 	hsID := d.Get("host_system_id").(string)
 	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
@@ -131,7 +135,7 @@ func resourceVSphereHostVirtualSwitchDelete(d *schema.ResourceData, meta interfa
 	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
 	defer cancel()
 	if err := ns.RemoveVirtualSwitch(ctx, name); err != nil {
-		return fmt.Errorf("error deleting host vSwitch: %s", err)
+		return viapi.NewDiagnostic(err, "error deleting host vSwitch")
 	}
 
 	return nil
@@ -20,9 +20,10 @@ const formatNasDatastoreIDMismatch = "datastore ID on host %q (%s) does not orig
 func resourceVSphereNasDatastore() *schema.Resource {
 	s := map[string]*schema.Schema{
 		"name": {
-			Type:        schema.TypeString,
-			Description: "The name of the datastore.",
-			Required:    true,
+			Type:             schema.TypeString,
+			Description:      "The name of the datastore.",
+			Required:         true,
+			DiffSuppressFunc: datastoreNameRule.diffSuppressFunc(),
 		},
 		"host_system_ids": &schema.Schema{
 			Type:        schema.TypeSet,
@@ -47,6 +48,7 @@ func resourceVSphereNasDatastore() *schema.Resource {
 	}
 	structure.MergeSchema(s, schemaHostNasVolumeSpec())
 	structure.MergeSchema(s, schemaDatastoreSummary())
+	structure.MergeSchema(s, schemaDatastoreCapacityAlarm())
 
 	// Add tags schema
 	s[vSphereTagAttributeKey] = tagsSchema()
@@ -54,10 +56,11 @@ func resourceVSphereNasDatastore() *schema.Resource {
 	s[customattribute.ConfigKey] = customattribute.ConfigSchema()
 
 	return &schema.Resource{
-		Create: resourceVSphereNasDatastoreCreate,
-		Read:   resourceVSphereNasDatastoreRead,
-		Update: resourceVSphereNasDatastoreUpdate,
-		Delete: resourceVSphereNasDatastoreDelete,
+		Create:        resourceVSphereNasDatastoreCreate,
+		Read:          resourceVSphereNasDatastoreRead,
+		Update:        resourceVSphereNasDatastoreUpdate,
+		Delete:        resourceVSphereNasDatastoreDelete,
+		CustomizeDiff: datastoreNameRule.customizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: resourceVSphereNasDatastoreImport,
 		},
@@ -67,6 +70,12 @@ func resourceVSphereNasDatastore() *schema.Resource {
 
 func resourceVSphereNasDatastoreCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, "vsphere_nas_datastore"); err != nil {
+		return err
+	}
+	if err := datastoreNameRule.apply(d, meta); err != nil {
+		return err
+	}
 
 	// Load up the tags client, which will validate a proper vCenter before
 	// attempting to proceed if we have tags defined.
@@ -121,6 +130,11 @@ func resourceVSphereNasDatastoreCreate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
+	// Create the capacity alarm
+	if err := processDatastoreCapacityAlarm(client, d, ds); err != nil {
+		return err
+	}
+
 	// Done
 	return resourceVSphereNasDatastoreRead(d, meta)
 }
@@ -171,11 +185,15 @@ func resourceVSphereNasDatastoreRead(d *schema.ResourceData, meta interface{}) e
 		customattribute.ReadFromResource(client, props.Entity(), d)
 	}
 
-	return nil
+	// Read the capacity alarm
+	return flattenDatastoreCapacityAlarm(client, d)
 }
 
 func resourceVSphereNasDatastoreUpdate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := datastoreNameRule.apply(d, meta); err != nil {
+		return err
+	}
 
 	// Load up the tags client, which will validate a proper vCenter before
 	// attempting to proceed if we have tags defined.
@@ -227,6 +245,13 @@ func resourceVSphereNasDatastoreUpdate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
+	// Update the capacity alarm
+	if d.HasChange("capacity_alarm") {
+		if err := processDatastoreCapacityAlarm(client, d, ds); err != nil {
+			return err
+		}
+	}
+
 	// Process mount/unmount operations.
 	o, n := d.GetChange("host_system_ids")
 
@@ -258,6 +283,10 @@ func resourceVSphereNasDatastoreDelete(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("cannot find datastore: %s", err)
 	}
 
+	if err := removeDatastoreCapacityAlarm(client, d); err != nil {
+		return err
+	}
+
 	// Unmount the datastore from every host. Once the last host is unmounted we
 	// are done and the datastore will delete itself.
 	hosts := structure.SliceInterfacesToStrings(d.Get("host_system_ids").(*schema.Set).List())
@@ -32,6 +32,41 @@ func TestAccResourceVSphereNasDatastore_basic(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereNasDatastore_capacityAlarm(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccSkipIfEsxi(t)
+			testAccResourceVSphereNasDatastorePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereNasDatastoreExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereNasDatastoreConfigCapacityAlarm(80),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereNasDatastoreExists(true),
+					resource.TestCheckResourceAttrSet("vsphere_nas_datastore.datastore", "capacity_alarm_id"),
+					resource.TestCheckResourceAttr("vsphere_nas_datastore.datastore", "capacity_alarm.0.name", "terraform-test-nas usage"),
+					resource.TestCheckResourceAttr("vsphere_nas_datastore.datastore", "capacity_alarm.0.warning_threshold", "80"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereNasDatastoreConfigCapacityAlarm(70),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_nas_datastore.datastore", "capacity_alarm.0.warning_threshold", "70"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereNasDatastoreConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_nas_datastore.datastore", "capacity_alarm_id", ""),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceVSphereNasDatastore_multiHost(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -465,6 +500,43 @@ resource "vsphere_nas_datastore" "datastore" {
 `, os.Getenv("VSPHERE_NAS_HOST"), os.Getenv("VSPHERE_NFS_PATH"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
 }
 
+func testAccResourceVSphereNasDatastoreConfigCapacityAlarm(warning int) string {
+	return fmt.Sprintf(`
+variable "nfs_host" {
+  type    = "string"
+  default = "%s"
+}
+
+variable "nfs_path" {
+  type    = "string"
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name            = "terraform-test-nas"
+  host_system_ids = ["${data.vsphere_host.esxi_host.id}"]
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+
+  capacity_alarm {
+    warning_threshold  = %d
+    critical_threshold = 90
+  }
+}
+`, os.Getenv("VSPHERE_NAS_HOST"), os.Getenv("VSPHERE_NFS_PATH"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"), warning)
+}
+
 func testAccResourceVSphereNasDatastoreConfigMultiHost() string {
 	return fmt.Sprintf(`
 variable "nfs_host" {
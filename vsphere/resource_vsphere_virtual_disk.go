@@ -89,9 +89,20 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 			},
 
 			"datastore": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: datastoreReferenceDiffSuppress("current_datastore"),
+			},
+
+			"datastore_id": &schema.Schema{
 				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
+				Computed: true,
+			},
+
+			"current_datastore": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 		},
 	}
@@ -185,10 +196,15 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 	finder := find.NewFinder(client.Client, true)
 	finder = finder.SetDatacenter(dc)
 
-	ds, err := finder.Datastore(context.TODO(), d.Get("datastore").(string))
+	// The datastore is tracked by its ID, so that a datastore renamed outside
+	// of Terraform can still be found. The new name is saved in
+	// current_datastore.
+	ds, err := datastoreByReference(client, finder, d.Get("datastore").(string), d.Get("datastore_id").(string))
 	if err != nil {
 		return err
 	}
+	d.Set("datastore_id", ds.Reference().Value)
+	d.Set("current_datastore", ds.Name())
 
 	ctx := context.TODO()
 	b, err := ds.Browser(ctx)
@@ -283,7 +299,7 @@ func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{})
 	finder := find.NewFinder(client.Client, true)
 	finder = finder.SetDatacenter(dc)
 
-	ds, err := getDatastore(finder, vDisk.datastore)
+	ds, err := datastoreByReference(client, finder, vDisk.datastore, d.Get("datastore_id").(string))
 	if err != nil {
 		return err
 	}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -11,6 +14,7 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
@@ -19,9 +23,22 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/vmworkflow"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
+// virtualMachineDeletionProtectionSource is the source ID that the methods
+// disabled by deletion_protection are disabled under.
+const virtualMachineDeletionProtectionSource = "terraform-provider-vsphere"
+
+// virtualMachineDeletionProtectionMethods are the methods that are disabled on
+// a virtual machine when deletion_protection is set.
+var virtualMachineDeletionProtectionMethods = []string{"Destroy_Task", "UnregisterVM"}
+
+// virtualMachinePreUpdateSnapshotPrefix is the prefix of the names of the
+// snapshots taken when snapshot_before_disruptive_update is set.
+const virtualMachinePreUpdateSnapshotPrefix = "terraform-pre-update-"
+
 // formatVirtualMachinePostCloneRollbackError defines the verbose error when
 // rollback fails on a post-clone virtual machine operation.
 const formatVirtualMachinePostCloneRollbackError = `
@@ -39,21 +56,19 @@ https://www.terraform.io/docs/commands/taint.html
 If the virtual machine does not exist in state, manually delete it to try again.
 `
 
-// formatVirtualMachineCustomizationWaitError defines the verbose error that is
-// sent when the customization waiter returns an error. This can either be due
-// to timeout waiting for respective events or a guest-specific customization
-// error. The resource does not roll back in this case, to assist with
-// troubleshooting.
-const formatVirtualMachineCustomizationWaitError = `
-Virtual machine customization failed on %q:
+// formatVirtualMachinePostCloneFailedError defines the verbose error that is
+// sent when a post-clone operation, like reconfiguration or customization,
+// fails and cleanup_on_failure is not set. The virtual machine is kept in
+// state as tainted, to assist with troubleshooting.
+const formatVirtualMachinePostCloneFailedError = `
+Post-clone operations failed on virtual machine %q:
 
 %s
 
-The virtual machine has not been deleted to assist with troubleshooting. If
-corrective steps are taken without modifying the "customize" block of the
-resource configuration, the resource will need to be tainted before trying
-again. For more information on how to do this, see the following page:
-https://www.terraform.io/docs/commands/taint.html
+The virtual machine has not been deleted to assist with troubleshooting. It
+has been saved in state as tainted under managed object ID %s, and will be
+replaced on the next apply. To have Terraform delete virtual machines that
+fail after cloning instead, set cleanup_on_failure.
 `
 
 func resourceVSphereVirtualMachine() *schema.Resource {
@@ -66,13 +81,45 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 		"datastore_id": {
 			Type:        schema.TypeString,
 			Required:    true,
-			Description: "The ID of the virtual machine's datastore. The virtual machine configuration is placed here, along with any virtual disks that are created without datastores.",
+			Description: "The ID of the virtual machine's datastore. The virtual machine configuration is placed here unless vmx_datastore_id is set, along with any virtual disks that are created without datastores.",
+		},
+		"vmx_datastore_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The ID of the datastore to place the virtual machine configuration files on, if different from datastore_id.",
 		},
 		"folder": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Description:      "The name of the folder to locate the virtual machine in.",
+			StateFunc:        folder.NormalizePath,
+			DiffSuppressFunc: resourceVSphereVirtualMachineFolderDiffSuppress,
+		},
+		"folder_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The managed object ID of the folder that the virtual machine is in.",
+		},
+		"current_folder": {
 			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The current path of the folder that the virtual machine is in. This differs from folder when the folder has been renamed outside of Terraform.",
+		},
+		"create_folders": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Create any folders in the path defined in folder that do not exist when creating or moving the virtual machine.",
+		},
+		"adopt_existing": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "When creating the virtual machine, adopt an existing virtual machine with the same name in the target folder instead of failing.",
+		},
+		"remove_empty_folders": {
+			Type:        schema.TypeBool,
 			Optional:    true,
-			Description: "The name of the folder to locate the virtual machine in.",
-			StateFunc:   folder.NormalizePath,
+			Description: "Remove the folder defined in folder, and each of its parents, if they are empty after the virtual machine is destroyed.",
 		},
 		"host_system_id": {
 			Type:        schema.TypeString,
@@ -100,12 +147,84 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Description:  "The amount of time, in minutes, to wait for a vMotion operation to complete before failing.",
 			ValidateFunc: validation.IntAtLeast(10),
 		},
+		"create_wait_timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      30,
+			Description:  "The amount of time, in minutes, to wait for the virtual machine to be created, or for a cloned virtual machine to be reconfigured, before failing.",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
 		"force_power_off": {
 			Type:        schema.TypeBool,
 			Optional:    true,
 			Default:     true,
 			Description: "Set to true to force power-off a virtual machine if a graceful guest shutdown failed for a necessary operation.",
 		},
+		"repair_orphaned": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Unregister the virtual machine and register it again from its configuration file on the next apply, if it is orphaned or invalid in vCenter.",
+		},
+		"connection_state": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The connection state of the virtual machine in vCenter: connected, disconnected, orphaned, inaccessible, or invalid.",
+		},
+		"deletion_protection": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Disable the destroy and unregister methods on the virtual machine in vCenter, and refuse to destroy it in Terraform. This is not read back from vCenter.",
+		},
+		"unregister_on_destroy": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Unregister the virtual machine from the inventory when it is destroyed, instead of deleting it, leaving its files on the datastore.",
+		},
+		"snapshot_before_disruptive_update": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Take a snapshot of the virtual machine after it has been powered off for an update that requires it, before the update is made.",
+		},
+		"snapshot_retention_count": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  "The number of snapshots taken by snapshot_before_disruptive_update to keep after a successful update. Older snapshots are removed.",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"sync_cpu_memory": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Refresh CPU, memory, and resource allocation settings from the virtual machine. When false, out-of-band changes to these settings are not read and do not produce a diff.",
+		},
+		"sync_disks": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Refresh the state of disk devices from the virtual machine. When false, out-of-band changes to disks are not read and do not produce a diff.",
+		},
+		"sync_network_interfaces": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Refresh the state of network interfaces from the virtual machine. When false, out-of-band changes to network interfaces are not read and do not produce a diff.",
+		},
+		"sync_extra_config": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Refresh extra_config from the virtual machine. When false, out-of-band changes to managed extra_config keys are not read and do not produce a diff.",
+		},
+		"sync_annotation": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Refresh the annotation from the virtual machine. When false, out-of-band changes to the annotation are not read and do not produce a diff.",
+		},
 		"scsi_controller_count": {
 			Type:         schema.TypeInt,
 			Optional:     true,
@@ -117,9 +236,33 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Type:         schema.TypeString,
 			Optional:     true,
 			Default:      virtualdevice.SubresourceControllerTypeParaVirtual,
-			Description:  "The type of SCSI bus this virtual machine will have. Can be one of lsilogic, lsilogic-sas or pvscsi.",
+			Description:  "The type of SCSI bus this virtual machine will have. Can be one of lsilogic, lsilogic-sas or pvscsi. Changing this on an existing virtual machine powers it off to swap the controllers, and the guest operating system must already have a driver for the new type.",
 			ValidateFunc: validation.StringInSlice(virtualdevice.SCSIBusTypeAllowedValues, false),
 		},
+		"wsfc": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "Prepares this virtual machine to be a node in a Windows Server Failover Cluster, by sharing a SCSI bus for the cluster disks and checking the settings that clustering requires.",
+			Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"shared_bus_number": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					Description:  "The number of the SCSI bus to share. Must be lower than scsi_controller_count.",
+					ValidateFunc: validation.IntBetween(0, 3),
+				},
+				"bus_sharing": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      string(types.VirtualSCSISharingPhysicalSharing),
+					Description:  "The sharing mode of the shared SCSI bus. Use physicalSharing for nodes on different hosts, and virtualSharing for nodes on the same host.",
+					ValidateFunc: validation.StringInSlice(virtualMachineWSFCBusSharingAllowedValues, false),
+				},
+			}},
+		},
+		"readiness_probe": schemaVirtualMachineReadinessProbe(),
+		"scheduled_power": schemaVirtualMachineScheduledPower(),
 		// NOTE: disk is only optional so that we can flag it as computed and use
 		// it in ResourceDiff. We validate this field in ResourceDiff to enforce it
 		// having a minimum count of 1 for now - but may support diskless VMs
@@ -132,9 +275,14 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			MaxItems:    60,
 			Elem:        &schema.Resource{Schema: virtualdevice.DiskSubresourceSchema()},
 		},
+		// NOTE: network_interface is only optional so that it can be computed when
+		// the network interfaces of a clone come from the source through
+		// clone.network_map, or come from the OVF through ovf_deploy. At least one
+		// is still required otherwise, which is validated in ResourceDiff.
 		"network_interface": {
 			Type:        schema.TypeList,
-			Required:    true,
+			Optional:    true,
+			Computed:    true,
 			Description: "A specification for a virtual NIC on this virtual machine.",
 			MaxItems:    10,
 			Elem:        &schema.Resource{Schema: virtualdevice.NetworkInterfaceSubresourceSchema()},
@@ -149,10 +297,35 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 		"clone": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			Description: "A specification for cloning a virtual machine from template.",
+			Computed:    true,
+			Description: "A specification for cloning a virtual machine from template. Only used when the virtual machine is created - changes after creation are ignored.",
 			MaxItems:    1,
 			Elem:        &schema.Resource{Schema: vmworkflow.VirtualMachineCloneSchema()},
 		},
+		"ovf_deploy": {
+			Type:          schema.TypeList,
+			Optional:      true,
+			Computed:      true,
+			Description:   "A specification for deploying a virtual machine from an OVF descriptor or OVA archive. Only used when the virtual machine is created - changes after creation are ignored.",
+			MaxItems:      1,
+			ConflictsWith: []string{"clone"},
+			Elem:          &schema.Resource{Schema: vmworkflow.VirtualMachineOvfDeploySchema()},
+		},
+		"source_template_instance_uuid": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The instance UUID of the virtual machine or template that this virtual machine was cloned from.",
+		},
+		"source_template_change_version": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The change version of the configuration of the virtual machine or template that this virtual machine was cloned from, at the time of the clone.",
+		},
+		"source_drifted": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "Whether the virtual machine or template that this virtual machine was cloned from has been changed, replaced, or removed since the clone.",
+		},
 		"reboot_required": {
 			Type:        schema.TypeBool,
 			Computed:    true,
@@ -168,6 +341,22 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			Computed:    true,
 			Description: "The path of the virtual machine's configuration file in the VM's datastore.",
 		},
+		"vmx_datastore_path": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The full datastore path of the virtual machine's configuration file, such as [datastore1] vm/vm.vmx.",
+		},
+		"config_files": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "The full datastore paths of the virtual machine's configuration files, such as the VMX, VMXF, and NVRAM files.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"device_layout_json": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The controllers, disks, and network interfaces of the virtual machine, with their device keys and unit numbers, as JSON.",
+		},
 		"imported": {
 			Type:        schema.TypeBool,
 			Computed:    true,
@@ -202,6 +391,15 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
+	if err := setNameFromPrefix(d, "name"); err != nil {
+		return err
+	}
+	if err := virtualMachineNameRule.apply(d, meta); err != nil {
+		return err
+	}
+	if err := vmcCheckVirtualMachinePlacement(d, meta); err != nil {
+		return err
+	}
 	tagsClient, err := tagsClientIfDefined(d, meta)
 	if err != nil {
 		return err
@@ -212,17 +410,35 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
-	var vm *object.VirtualMachine
+	// Look for an existing virtual machine to adopt before deploying a new one.
+	vm, err := resourceVSphereVirtualMachineAdopt(d, meta)
+	if err != nil {
+		return err
+	}
 	// This is where we process our various VM deploy workflows. We expect the ID
 	// of the resource to be set in the workflow to ensure that any post-create
 	// operations that fail during this process don't create a dangling resource.
 	// The VM should also be returned powered on.
 	switch {
+	case vm != nil:
+		log.Printf("[DEBUG] %s: Adopted existing virtual machine %q", resourceVSphereVirtualMachineIDString(d), vm.InventoryPath)
+	case d.Get("clone.0.content_library_item_id").(string) != "":
+		vm, err = resourceVSphereVirtualMachineCreateFromLibraryItem(d, meta)
 	case len(d.Get("clone").([]interface{})) > 0:
 		vm, err = resourceVSphereVirtualMachineCreateClone(d, meta)
+	case len(d.Get("ovf_deploy").([]interface{})) > 0:
+		vm, err = resourceVSphereVirtualMachineCreateFromOvf(d, meta)
 	default:
 		vm, err = resourceVSphereVirtualMachineCreateBare(d, meta)
 	}
+	// Customization has been sent by now, so remove its passwords from the
+	// resource data before anything is saved to state, even if the clone
+	// failed.
+	if d.Id() != "" {
+		if serr := vmworkflow.ScrubCustomizationSecrets(d); serr != nil {
+			return fmt.Errorf("error removing customization passwords from state: %s", serr)
+		}
+	}
 
 	if err != nil {
 		return err
@@ -242,10 +458,24 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		}
 	}
 
+	// Lock the VM against deletion if requested
+	if d.Get("deletion_protection").(bool) {
+		if err := resourceVSphereVirtualMachineApplyDeletionProtection(d, client, vm); err != nil {
+			return err
+		}
+	}
+	if err := resourceVSphereVirtualMachineApplyScheduledPower(d, client, vm); err != nil {
+		return err
+	}
+
 	// Wait for a routeable address if we have been set to wait for one
 	if err := virtualmachine.WaitForGuestNet(client, vm, d.Get("wait_for_guest_net_timeout").(int)); err != nil {
 		return err
 	}
+	// Wait for the readiness probe, if one is set
+	if err := resourceVSphereVirtualMachineWaitForReadiness(d, client, vm); err != nil {
+		return err
+	}
 
 	// All done!
 	log.Printf("[DEBUG] %s: Create complete", resourceVSphereVirtualMachineIDString(d))
@@ -271,6 +501,21 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		return fmt.Errorf("error fetching VM properties: %s", err)
 	}
 
+	// Orphaned, inaccessible, and invalid virtual machines have no usable
+	// configuration. The last known state is kept, so that the virtual machine
+	// can be repaired or removed.
+	d.Set("connection_state", string(vprops.Runtime.ConnectionState))
+	if virtualMachineConnectionStateUnreadable(vprops.Runtime.ConnectionState) {
+		log.Printf("[WARN] %s: Virtual machine is %s, keeping its last known state", resourceVSphereVirtualMachineIDString(d), vprops.Runtime.ConnectionState)
+		return nil
+	}
+
+	// Remove any customization passwords that were saved to state by earlier
+	// versions of the provider.
+	if err := vmworkflow.ScrubCustomizationSecrets(d); err != nil {
+		return fmt.Errorf("error removing customization passwords from state: %s", err)
+	}
+
 	// Set the managed object id.
 	moid := vm.Reference().Value
 	d.Set("moid", moid)
@@ -288,12 +533,26 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	if vprops.ResourcePool != nil {
 		d.Set("resource_pool_id", vprops.ResourcePool.Value)
 	}
-	// Set the folder
+	// Set the folder. The folder is tracked by its managed object ID, so that if
+	// it was renamed, or one of its parents was, the path in state is kept as
+	// long as the virtual machine is still in the same folder. The new path is
+	// available in current_folder.
 	f, err := folder.RootPathParticleVM.SplitRelativeFolder(vm.InventoryPath)
 	if err != nil {
 		return fmt.Errorf("error parsing virtual machine path %q: %s", vm.InventoryPath, err)
 	}
-	d.Set("folder", folder.NormalizePath(f))
+	f = folder.NormalizePath(f)
+	var folderID string
+	if vprops.Parent != nil {
+		folderID = vprops.Parent.Value
+	}
+	if folderID == "" || folderID != d.Get("folder_id").(string) {
+		d.Set("folder", f)
+	} else if f != d.Get("folder").(string) {
+		log.Printf("[DEBUG] %s: Folder %s was renamed from %q to %q", resourceVSphereVirtualMachineIDString(d), folderID, d.Get("folder").(string), f)
+	}
+	d.Set("folder_id", folderID)
+	d.Set("current_folder", f)
 	// Set VM's current host ID if available
 	if vprops.Runtime.Host != nil {
 		d.Set("host_system_id", vprops.Runtime.Host.Value)
@@ -326,25 +585,61 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	if ds == nil {
 		return fmt.Errorf("VMX datastore %s not found", dp.Datastore)
 	}
-	d.Set("datastore_id", ds.Reference().Value)
+	// datastore_id follows the VMX datastore, unless the VMX files were placed
+	// on a datastore of their own, in which case it is only the default for
+	// disks and is kept as is.
+	if vmxID := d.Get("vmx_datastore_id").(string); vmxID == "" || vmxID == d.Get("datastore_id").(string) {
+		d.Set("datastore_id", ds.Reference().Value)
+	}
+	d.Set("vmx_datastore_id", ds.Reference().Value)
 	d.Set("vmx_path", dp.Path)
+	d.Set("vmx_datastore_path", vprops.Config.Files.VmPathName)
+	if err := d.Set("config_files", virtualmachine.ConfigFiles(vprops)); err != nil {
+		return fmt.Errorf("error setting config_files: %s", err)
+	}
+	resourceVSphereVirtualMachineReadSourceDrift(d, client)
 
-	// Read general VM config info
+	// Read general VM config info. Attributes in any groups that have been
+	// excluded from sync are saved beforehand and restored afterwards, so that
+	// out-of-band changes to them do not show up in state.
+	unsynced := resourceVSphereVirtualMachineUnsyncedValues(d)
 	if err := flattenVirtualMachineConfigInfo(d, vprops.Config); err != nil {
 		return fmt.Errorf("error reading virtual machine configuration: %s", err)
 	}
+	for k, v := range unsynced {
+		if err := d.Set(k, v); err != nil {
+			return fmt.Errorf("error restoring unsynced attribute %q: %s", k, err)
+		}
+	}
 
 	// Perform pending device read operations.
 	devices := object.VirtualDeviceList(vprops.Config.Hardware.Device)
+	layout, err := virtualdevice.DeviceLayoutJSON(devices)
+	if err != nil {
+		return fmt.Errorf("error reading device layout: %s", err)
+	}
+	d.Set("device_layout_json", layout)
 	// Read the state of the SCSI bus.
 	d.Set("scsi_type", virtualdevice.ReadSCSIBusState(devices, d.Get("scsi_controller_count").(int)))
-	// Disks first
-	if err := virtualdevice.DiskRefreshOperation(d, client, devices); err != nil {
+	if err := resourceVSphereVirtualMachineReadWSFC(d, devices); err != nil {
 		return err
 	}
+	resourceVSphereVirtualMachineReadVideoCard(d, devices)
+	// Disks first
+	if resourceVSphereVirtualMachineSyncSkipped(d, "sync_disks") {
+		log.Printf("[DEBUG] %s: Disk sync disabled, skipping disk refresh", resourceVSphereVirtualMachineIDString(d))
+	} else {
+		if err := virtualdevice.DiskRefreshOperation(d, client, devices); err != nil {
+			return err
+		}
+	}
 	// Network devices
-	if err := virtualdevice.NetworkInterfaceRefreshOperation(d, client, devices); err != nil {
-		return err
+	if resourceVSphereVirtualMachineSyncSkipped(d, "sync_network_interfaces") {
+		log.Printf("[DEBUG] %s: Network interface sync disabled, skipping network interface refresh", resourceVSphereVirtualMachineIDString(d))
+	} else {
+		if err := virtualdevice.NetworkInterfaceRefreshOperation(d, client, devices); err != nil {
+			return err
+		}
 	}
 	// CDROM
 	if err := virtualdevice.CdromRefreshOperation(d, client, devices); err != nil {
@@ -358,6 +653,10 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		}
 	}
 
+	if err := resourceVSphereVirtualMachineReadScheduledPower(d, client); err != nil {
+		return err
+	}
+
 	// Read set custom attributes
 	if customattribute.IsSupported(client) {
 		customattribute.ReadFromResource(client, vprops.Entity(), d)
@@ -379,6 +678,14 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Performing update", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
+	if err := virtualMachineNameRule.apply(d, meta); err != nil {
+		return err
+	}
+	if d.HasChange("folder") || d.HasChange("resource_pool_id") {
+		if err := vmcCheckVirtualMachinePlacement(d, meta); err != nil {
+			return err
+		}
+	}
 	tagsClient, err := tagsClientIfDefined(d, meta)
 	if err != nil {
 		return err
@@ -394,12 +701,21 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	if err != nil {
 		return fmt.Errorf("cannot locate virtual machine with UUID %q: %s", id, err)
 	}
+	vm, err = resourceVSphereVirtualMachineCheckConnectionState(d, client, vm)
+	if err != nil {
+		return err
+	}
 
 	// Update folder if necessary
 	if d.HasChange("folder") {
-		folder := d.Get("folder").(string)
-		if err := virtualmachine.MoveToFolder(client, vm, folder); err != nil {
-			return fmt.Errorf("could not move virtual machine to folder %q: %s", folder, err)
+		folderPath := d.Get("folder").(string)
+		if d.Get("create_folders").(bool) {
+			if _, err := folder.CreateVirtualMachineFolderFromObject(client, vm, folderPath); err != nil {
+				return fmt.Errorf("could not create folder %q: %s", folderPath, err)
+			}
+		}
+		if err := virtualmachine.MoveToFolder(client, vm, folderPath); err != nil {
+			return fmt.Errorf("could not move virtual machine to folder %q: %s", folderPath, err)
 		}
 	}
 
@@ -417,6 +733,13 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		}
 	}
 
+	// Update deletion protection
+	if d.HasChange("deletion_protection") {
+		if err := resourceVSphereVirtualMachineApplyDeletionProtection(d, client, vm); err != nil {
+			return err
+		}
+	}
+
 	// Ready to start the VM update. All changes from here, until the update
 	// operation finishes successfully, need to be done in partial mode.
 	d.Partial(true)
@@ -435,20 +758,54 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	if spec.DeviceChange, err = applyVirtualDevices(d, client, devices); err != nil {
 		return err
 	}
+	// Check for a hardware version upgrade. Immediate upgrades need the VM to be
+	// powered off and are done along with the reconfigure below, while other
+	// policies are scheduled for vSphere to carry out on the next power cycle.
+	var upgradeKey string
+	if d.HasChange("hardware_version") {
+		if upgradeKey, err = virtualMachineHardwareUpgradeKey(d, vprops.Config.Version); err != nil {
+			return err
+		}
+		if policy := d.Get("hardware_upgrade_policy").(string); upgradeKey != "" && policy != virtualMachineHardwareUpgradePolicyImmediate {
+			log.Printf("[DEBUG] %s: Scheduling hardware upgrade to %q with policy %q", resourceVSphereVirtualMachineIDString(d), upgradeKey, policy)
+			spec.ScheduledHardwareUpgradeInfo = &types.ScheduledHardwareUpgradeInfo{
+				UpgradePolicy: policy,
+				VersionKey:    upgradeKey,
+			}
+			changed = true
+			upgradeKey = ""
+		}
+	}
 	// Only carry out the reconfigure if we actually have a change to process.
-	if changed || len(spec.DeviceChange) > 0 {
+	if changed || len(spec.DeviceChange) > 0 || upgradeKey != "" {
 		//Check to see if we need to shutdown the VM for this process.
-		if d.Get("reboot_required").(bool) && vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		if (d.Get("reboot_required").(bool) || upgradeKey != "") && vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
 			// Attempt a graceful shutdown of this process. We wrap this in a VM helper.
 			timeout := d.Get("shutdown_wait_timeout").(int)
 			force := d.Get("force_power_off").(bool)
 			if err := virtualmachine.GracefulPowerOff(client, vm, timeout, force); err != nil {
-				return fmt.Errorf("error shutting down virtual machine: %s", err)
+				return viapi.NewDiagnostic(err, "error shutting down virtual machine")
 			}
 		}
-		// Perform updates
-		if err := virtualmachine.Reconfigure(vm, spec); err != nil {
-			return fmt.Errorf("error reconfiguring virtual machine: %s", err)
+		// Take a snapshot to fall back to if the update goes wrong, if requested.
+		if (d.Get("reboot_required").(bool) || upgradeKey != "") && d.Get("snapshot_before_disruptive_update").(bool) {
+			if err := resourceVSphereVirtualMachineCreatePreUpdateSnapshot(d, vm); err != nil {
+				return err
+			}
+		}
+		// Perform updates. All changed settings, including CPU, memory,
+		// extra_config, and every disk, network interface, and CDROM change, are
+		// in the one spec, so they are applied in a single reconfigure task.
+		// Hardware upgrades and migrations need their own tasks.
+		if changed || len(spec.DeviceChange) > 0 {
+			if err := virtualmachine.Reconfigure(vm, spec); err != nil {
+				return viapi.NewDiagnostic(err, "error reconfiguring virtual machine")
+			}
+		}
+		if upgradeKey != "" {
+			if err := virtualmachine.UpgradeHardware(vm, upgradeKey); err != nil {
+				return viapi.NewDiagnostic(err, "error upgrading virtual machine hardware")
+			}
 		}
 		// Re-fetch properties
 		vprops, err = virtualmachine.Properties(vm)
@@ -458,17 +815,29 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		// Power back on the VM, and wait for network if necessary.
 		if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
 			if err := virtualmachine.PowerOn(vm); err != nil {
-				return fmt.Errorf("error powering on virtual machine: %s", err)
+				return viapi.NewDiagnostic(err, "error powering on virtual machine")
 			}
 			if err := virtualmachine.WaitForGuestNet(client, vm, d.Get("wait_for_guest_net_timeout").(int)); err != nil {
 				return err
 			}
+			if err := resourceVSphereVirtualMachineWaitForReadiness(d, client, vm); err != nil {
+				return err
+			}
+		}
+		if d.Get("snapshot_before_disruptive_update").(bool) {
+			if err := resourceVSphereVirtualMachinePrunePreUpdateSnapshots(d, client, vprops); err != nil {
+				return err
+			}
 		}
 	}
 	// Now safe to turn off partial mode.
 	d.Partial(false)
 	d.Set("reboot_required", false)
 
+	if err := resourceVSphereVirtualMachineApplyScheduledPower(d, client, vm); err != nil {
+		return err
+	}
+
 	// Now that any pending changes have been done (namely, any disks that don't
 	// need to be migrated have been deleted), proceed with vMotion if we have
 	// one pending.
@@ -483,6 +852,10 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 
 func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Performing delete", resourceVSphereVirtualMachineIDString(d))
+	mode, err := resourceVSphereVirtualMachineDestroyMode(d, meta.(*VSphereClient).softDestroy)
+	if err != nil {
+		return err
+	}
 	client := meta.(*VSphereClient).vimClient
 	id := d.Id()
 	vm, err := virtualmachine.FromUUID(client, id)
@@ -493,15 +866,52 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 	if err != nil {
 		return fmt.Errorf("error fetching VM properties: %s", err)
 	}
-	// Shutdown the VM first. We do attempt a graceful shutdown for the purpose
+	// Remove the scheduled power operations first, so that none of them run
+	// while the virtual machine is being destroyed.
+	if err := resourceVSphereVirtualMachineRemoveScheduledPower(d, client); err != nil {
+		return err
+	}
+	// vCenter cannot destroy virtual machines that have no usable
+	// configuration, so they are only removed from the inventory.
+	if virtualMachineConnectionStateUnreadable(vprops.Runtime.ConnectionState) {
+		log.Printf("[WARN] %s: Virtual machine is %s, unregistering it without deleting its files", resourceVSphereVirtualMachineIDString(d), vprops.Runtime.ConnectionState)
+		if err := virtualmachine.Unregister(vm); err != nil {
+			return viapi.NewDiagnostic(err, "error unregistering %s virtual machine", vprops.Runtime.ConnectionState)
+		}
+		return nil
+	}
+	// Then shut down the VM. We do attempt a graceful shutdown for the purpose
 	// of catching any edge data issues with associated virtual disks that we may
 	// need to retain on delete. However, we ignore the user-set force shutdown
 	// flag.
 	if vprops.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
 		timeout := d.Get("shutdown_wait_timeout").(int)
 		if err := virtualmachine.GracefulPowerOff(client, vm, timeout, true); err != nil {
-			return fmt.Errorf("error shutting down virtual machine: %s", err)
+			return viapi.NewDiagnostic(err, "error shutting down virtual machine")
+		}
+	}
+	// With unregister_on_destroy, the VM is removed from the inventory and all
+	// of its files are left on the datastore, so that it can be registered
+	// again, in this vCenter or another.
+	if mode == virtualMachineDestroyModeUnregister {
+		if err := virtualmachine.Unregister(vm); err != nil {
+			return viapi.NewDiagnostic(err, "error unregistering virtual machine")
+		}
+		if d.Get("remove_empty_folders").(bool) {
+			if err := resourceVSphereVirtualMachineRemoveEmptyFolders(d, client); err != nil {
+				return err
+			}
 		}
+		log.Printf("[DEBUG] %s: Unregistered, files were left on datastore", resourceVSphereVirtualMachineIDString(d))
+		return nil
+	}
+	// With soft destroy enabled, the VM is kept, along with all of its disks.
+	if mode == virtualMachineDestroyModeSoftDestroy {
+		if err := softDestroyVirtualMachine(d, client, meta.(*VSphereClient).tagsClient, meta.(*VSphereClient).softDestroy, vm); err != nil {
+			return fmt.Errorf("error soft-destroying virtual machine: %s", err)
+		}
+		log.Printf("[DEBUG] %s: Soft destroy complete", resourceVSphereVirtualMachineIDString(d))
+		return nil
 	}
 	// Now attempt to detach any virtual disks that may need to be preserved.
 	devices := object.VirtualDeviceList(vprops.Config.Hardware.Device)
@@ -518,21 +928,67 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 
 	// The final operation here is to destroy the VM.
 	if err := virtualmachine.Destroy(vm); err != nil {
-		return fmt.Errorf("error destroying virtual machine: %s", err)
+		return viapi.NewDiagnostic(err, "error destroying virtual machine")
+	}
+	if d.Get("remove_empty_folders").(bool) {
+		if err := resourceVSphereVirtualMachineRemoveEmptyFolders(d, client); err != nil {
+			return err
+		}
 	}
 	log.Printf("[DEBUG] %s: Delete complete", resourceVSphereVirtualMachineIDString(d))
 	return nil
 }
 
+// virtualMachineDestroyMode is the way that a virtual machine is removed by
+// Delete.
+type virtualMachineDestroyMode string
+
+const (
+	virtualMachineDestroyModeDestroy     = virtualMachineDestroyMode("destroy")
+	virtualMachineDestroyModeUnregister  = virtualMachineDestroyMode("unregister")
+	virtualMachineDestroyModeSoftDestroy = virtualMachineDestroyMode("softDestroy")
+)
+
+// resourceVSphereVirtualMachineDestroyMode returns the way that Delete removes
+// the virtual machine. deletion_protection blocks all of them, and
+// unregister_on_destroy takes precedence over provider-level soft destroy, as
+// it keeps the files of the virtual machine without keeping it in the
+// inventory.
+func resourceVSphereVirtualMachineDestroyMode(d *schema.ResourceData, sd *softDestroyOptions) (virtualMachineDestroyMode, error) {
+	switch {
+	case d.Get("deletion_protection").(bool):
+		return "", errors.New("cannot destroy virtual machine with deletion_protection set - set it to false and apply first")
+	case d.Get("unregister_on_destroy").(bool):
+		return virtualMachineDestroyModeUnregister, nil
+	case sd != nil:
+		return virtualMachineDestroyModeSoftDestroy, nil
+	}
+	return virtualMachineDestroyModeDestroy, nil
+}
+
 func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
 	log.Printf("[DEBUG] %s: Performing diff customization and validation", resourceVSphereVirtualMachineIDString(d))
 	client := meta.(*VSphereClient).vimClient
 
+	if err := virtualMachineNameRule.customizeDiff(d, meta); err != nil {
+		return err
+	}
+	if err := resourceVSphereVirtualMachineDiffVmxDatastore(d); err != nil {
+		return err
+	}
+	if err := resourceVSphereVirtualMachineDiffConnectionState(d); err != nil {
+		return err
+	}
+
 	// Block certain options from being set depending on the vSphere version.
-	version := viapi.ParseVersionFromClient(client)
 	if d.Get("efi_secure_boot_enabled").(bool) {
-		if version.Older(viapi.VSphereVersion{Product: version.Product, Major: 6, Minor: 5}) {
-			return fmt.Errorf("efi_secure_boot_enabled is only supported on vSphere 6.5 and higher")
+		if err := viapi.CapabilityEFISecureBoot.Validate(client); err != nil {
+			return err
+		}
+	}
+	if d.Get("migrate_encryption").(string) != "" && d.HasChange("migrate_encryption") {
+		if err := viapi.CapabilityEncryptedVMotion.Validate(client); err != nil {
+			return err
 		}
 	}
 
@@ -542,6 +998,9 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 	}
 
 	// Validate network device sub-resources
+	if err := resourceVSphereVirtualMachineValidateNetworkInterfaces(d); err != nil {
+		return err
+	}
 	if err := virtualdevice.NetworkInterfaceDiffOperation(d, client); err != nil {
 		return err
 	}
@@ -550,6 +1009,23 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 	if err := virtualdevice.DiskDiffOperation(d, client); err != nil {
 		return err
 	}
+	// Check the settings that Windows Server Failover Clustering needs.
+	if err := resourceVSphereVirtualMachineValidateWSFC(d); err != nil {
+		return err
+	}
+	// Block features that need vCenter when connected to ESXi directly, so that
+	// they fail at plan time instead of halfway through an apply.
+	if err := resourceVSphereVirtualMachineValidateESXi(d, client); err != nil {
+		return err
+	}
+	// Validate the readiness probe
+	if err := resourceVSphereVirtualMachineValidateReadinessProbe(d); err != nil {
+		return err
+	}
+	// Check the latency and affinity settings against the host.
+	if err := resourceVSphereVirtualMachineValidateScheduling(d, client); err != nil {
+		return err
+	}
 	// If this is a new resource and we are cloning, perform all clone validation
 	// operations.
 	if len(d.Get("clone").([]interface{})) > 0 {
@@ -562,40 +1038,462 @@ func resourceVSphereVirtualMachineCustomizeDiff(d *schema.ResourceDiff, meta int
 			return errors.New("this resource was imported or migrated from a previous version and does not support cloning. Please remove the \"clone\" block from its configuration")
 		}
 	}
+	// The clone workflow only applies to the creation of the virtual machine.
+	// Clear any changes to it on existing resources so that templates can be
+	// rotated or removed without producing a diff or forcing a new resource.
+	if d.Id() != "" && d.HasChange("clone") {
+		log.Printf("[DEBUG] %s: Ignoring changes to clone on existing virtual machine", resourceVSphereVirtualMachineIDString(d))
+		if err := d.Clear("clone"); err != nil {
+			return err
+		}
+	}
+	// The same goes for the OVF deploy workflow.
+	if len(d.Get("ovf_deploy").([]interface{})) > 0 && d.Id() == "" {
+		if err := vmworkflow.ValidateVirtualMachineOvfDeploy(d); err != nil {
+			return err
+		}
+	}
+	if d.Id() != "" && d.HasChange("ovf_deploy") {
+		log.Printf("[DEBUG] %s: Ignoring changes to ovf_deploy on existing virtual machine", resourceVSphereVirtualMachineIDString(d))
+		if err := d.Clear("ovf_deploy"); err != nil {
+			return err
+		}
+	}
+	// Changes to scsi_type are applied in place with a power cycle.
+	if err := resourceVSphereVirtualMachineDiffSCSIType(d); err != nil {
+		return err
+	}
+	// Validate the hardware version.
+	if err := resourceVSphereVirtualMachineValidateHardwareVersion(d, client); err != nil {
+		return err
+	}
+	// Validate the guest ID against the list of supported guest operating
+	// systems.
+	if err := resourceVSphereVirtualMachineValidateGuestID(d, client); err != nil {
+		return err
+	}
 	// Validate that the config has the necessary components for vApp support.
 	// Note that for clones the data is prepopulated in
 	// ValidateVirtualMachineClone.
 	if err := virtualdevice.VerifyVAppTransport(d, client); err != nil {
 		return err
 	}
+	// Check that the user has the privileges needed to apply the plan, if
+	// enabled.
+	if meta.(*VSphereClient).preflightPrivilegeCheck {
+		reqs, err := resourceVSphereVirtualMachinePrivilegeRequirements(d, client)
+		if err != nil {
+			return err
+		}
+		if err := checkPrivileges(client, reqs); err != nil {
+			return err
+		}
+	}
 
 	log.Printf("[DEBUG] %s: Diff customization and validation complete", resourceVSphereVirtualMachineIDString(d))
 	return nil
 }
 
-func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	client := meta.(*VSphereClient).vimClient
-
-	name := d.Id()
-	if name == "" {
-		return nil, fmt.Errorf("path cannot be empty")
-	}
-
-	log.Printf("[DEBUG] Looking for VM by name/path %q", name)
-	vm, err := virtualmachine.FromPath(client, name, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching virtual machine: %s", err)
+// resourceVSphereVirtualMachineValidateNetworkInterfaces checks that a new
+// virtual machine either has network_interface blocks, or takes its network
+// interfaces from the source of a clone through clone.network_map, or from
+// the OVF of ovf_deploy. clone.network_map and network_interface are mutually
+// exclusive.
+func resourceVSphereVirtualMachineValidateNetworkInterfaces(d *schema.ResourceDiff) error {
+	if d.Id() != "" {
+		return nil
 	}
-	props, err := virtualmachine.Properties(vm)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	nics := len(d.Get("network_interface").([]interface{}))
+	mapped := len(d.Get("clone.0.network_map").([]interface{}))
+	ovfDeploy := len(d.Get("ovf_deploy").([]interface{})) > 0
+	switch {
+	case nics == 0 && mapped == 0 && !ovfDeploy:
+		return errors.New("at least one network_interface is required, unless the network interfaces are taken from the source of a clone with clone.network_map, or from the OVF of ovf_deploy")
+	case nics > 0 && mapped > 0:
+		return errors.New("clone.network_map cannot be used together with network_interface. Either remove the network_interface blocks to keep the network interfaces of the source, or remove clone.network_map")
 	}
+	return nil
+}
 
-	// Block the import if the VM is a template.
-	if props.Config.Template {
+// resourceVSphereVirtualMachinePrivilegeRequirements returns the privileges
+// needed to create the virtual machine, or to move it to a new resource pool
+// or datastore. Objects with IDs that are not known yet at plan time are
+// skipped.
+func resourceVSphereVirtualMachinePrivilegeRequirements(d *schema.ResourceDiff, client *govmomi.Client) ([]privilegeRequirement, error) {
+	var reqs []privilegeRequirement
+	isNew := d.Id() == ""
+
+	if poolID := d.Get("resource_pool_id").(string); poolID != "" && (isNew || d.HasChange("resource_pool_id")) {
+		pool, err := resourcepool.FromID(client, poolID)
+		if err != nil {
+			return nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+		}
+		reqs = append(reqs, privilegeRequirement{
+			Ref:         pool.Reference(),
+			Description: fmt.Sprintf("resource pool %s", poolID),
+			Privileges:  []string{"Resource.AssignVMToPool"},
+		})
+		if isNew {
+			// With create_folders, missing folders are created in the nearest
+			// parent folder that exists, so check that folder instead.
+			fo, exists, err := folder.NearestVirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+			if err != nil {
+				return nil, err
+			}
+			if !exists && !d.Get("create_folders").(bool) {
+				return nil, fmt.Errorf("folder %q does not exist. Create it first, or set create_folders", d.Get("folder").(string))
+			}
+			privs := []string{"VirtualMachine.Inventory.Create", "VirtualMachine.Config.AddNewDisk"}
+			if d.Get("clone.0.template_uuid").(string) != "" {
+				privs = []string{"VirtualMachine.Inventory.CreateFromExisting"}
+			}
+			if len(d.Get("clone.0.customize").([]interface{})) > 0 {
+				privs = append(privs, "VirtualMachine.Provisioning.Customize")
+			}
+			if len(d.Get("ovf_deploy").([]interface{})) > 0 {
+				privs = append(privs, "VApp.Import")
+			}
+			if !exists {
+				privs = append(privs, "Folder.Create")
+			}
+			reqs = append(reqs, privilegeRequirement{
+				Ref:         fo.Reference(),
+				Description: fmt.Sprintf("folder %q", fo.InventoryPath),
+				Privileges:  privs,
+			})
+		}
+	}
+
+	checked := make(map[string]bool)
+	for _, k := range []string{"datastore_id", "vmx_datastore_id"} {
+		dsID := d.Get(k).(string)
+		if dsID == "" || checked[dsID] || !(isNew || d.HasChange(k)) {
+			continue
+		}
+		checked[dsID] = true
+		ds, err := datastore.FromID(client, dsID)
+		if err != nil {
+			return nil, fmt.Errorf("could not find datastore ID %q: %s", dsID, err)
+		}
+		reqs = append(reqs, privilegeRequirement{
+			Ref:         ds.Reference(),
+			Description: fmt.Sprintf("datastore %s", dsID),
+			Privileges:  []string{"Datastore.AllocateSpace"},
+		})
+	}
+
+	if !isNew {
+		return reqs, nil
+	}
+
+	if tUUID := d.Get("clone.0.template_uuid").(string); tUUID != "" {
+		vm, err := virtualmachine.FromUUID(client, tUUID)
+		if err != nil {
+			return nil, vmworkflow.CloneSourceLocateError(client, tUUID, err)
+		}
+		vprops, err := virtualmachine.Properties(vm)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching virtual machine or template properties: %s", err)
+		}
+		priv := "VirtualMachine.Provisioning.Clone"
+		if vprops.Config != nil && vprops.Config.Template {
+			priv = "VirtualMachine.Provisioning.DeployTemplate"
+		}
+		reqs = append(reqs, privilegeRequirement{
+			Ref:         vm.Reference(),
+			Description: fmt.Sprintf("source virtual machine %s", tUUID),
+			Privileges:  []string{priv},
+		})
+	}
+
+	var netIDs []string
+	for i := range d.Get("network_interface").([]interface{}) {
+		netIDs = append(netIDs, d.Get(fmt.Sprintf("network_interface.%d.network_id", i)).(string))
+	}
+	for i := range d.Get("clone.0.network_map").([]interface{}) {
+		netIDs = append(netIDs, d.Get(fmt.Sprintf("clone.0.network_map.%d.network_id", i)).(string))
+	}
+	for _, v := range d.Get("ovf_deploy.0.ovf_network_map").(map[string]interface{}) {
+		netIDs = append(netIDs, v.(string))
+	}
+	for _, netID := range netIDs {
+		if netID == "" {
+			continue
+		}
+		net, err := network.FromID(client, netID)
+		if err != nil {
+			return nil, fmt.Errorf("could not find network ID %q: %s", netID, err)
+		}
+		reqs = append(reqs, privilegeRequirement{
+			Ref:         net.Reference(),
+			Description: fmt.Sprintf("network %s", netID),
+			Privileges:  []string{"Network.Assign"},
+		})
+	}
+	return reqs, nil
+}
+
+// resourceVSphereVirtualMachineValidateESXi checks that no options that
+// require vCenter are in use when the provider is connected to ESXi directly.
+// On ESXi, virtual machines can only be created from scratch, and cannot be
+// migrated to another resource pool, host, or datastore afterwards.
+func resourceVSphereVirtualMachineValidateESXi(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if err := viapi.ValidateVirtualCenter(client); err == nil {
+		return nil
+	}
+	if d.Id() == "" && len(d.Get("clone").([]interface{})) > 0 {
+		return errors.New("cloning virtual machines requires vCenter. Remove the \"clone\" block to create the virtual machine from scratch")
+	}
+	if d.Id() != "" {
+		for _, k := range []string{"resource_pool_id", "host_system_id", "datastore_id"} {
+			if d.HasChange(k) {
+				return fmt.Errorf("changing %s migrates the virtual machine, which requires vCenter", k)
+			}
+		}
+	}
+	if d.Get("deletion_protection").(bool) {
+		return errors.New("deletion_protection requires vCenter")
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineValidateScheduling checks that a virtual
+// machine with high latency sensitivity has all of its memory reserved, and
+// that cpu_affinity and numa_node_affinity only refer to logical processors
+// and NUMA nodes that exist on the host in host_system_id. The affinity
+// settings are not checked when host_system_id is not set or not known yet.
+func resourceVSphereVirtualMachineValidateScheduling(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if d.Get("latency_sensitivity").(string) == string(types.LatencySensitivitySensitivityLevelHigh) {
+		if !d.Get("memory_reservation_locked_to_max").(bool) && d.Get("memory_reservation").(int) < d.Get("memory").(int) {
+			return errors.New("latency_sensitivity high requires the full memory of the virtual machine to be reserved. Set memory_reservation to memory, or set memory_reservation_locked_to_max")
+		}
+	}
+	cpus := structure.SliceInterfacesToInts(d.Get("cpu_affinity").(*schema.Set).List())
+	nodes := structure.SliceInterfacesToInts(d.Get("numa_node_affinity").(*schema.Set).List())
+	if len(cpus) < 1 && len(nodes) < 1 {
+		return nil
+	}
+	hsID := d.Get("host_system_id").(string)
+	if hsID == "" {
+		return nil
+	}
+	hs, err := hostsystem.FromID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error locating host system %q: %s", hsID, err)
+	}
+	props, err := hostsystem.Properties(hs)
+	if err != nil {
+		return fmt.Errorf("error fetching properties of host system %q: %s", hsID, err)
+	}
+	if props.Hardware == nil {
+		log.Printf("[DEBUG] %s: Hardware of host %q not available, skipping affinity validation", resourceVSphereVirtualMachineIDString(d), hsID)
+		return nil
+	}
+	threads := int(props.Hardware.CpuInfo.NumCpuThreads)
+	for _, cpu := range cpus {
+		if cpu >= threads {
+			return fmt.Errorf("cpu_affinity: logical processor %d does not exist on host %q, which has %d logical processors", cpu, props.Name, threads)
+		}
+	}
+	var numaNodes int
+	if props.Hardware.NumaInfo != nil {
+		numaNodes = int(props.Hardware.NumaInfo.NumNodes)
+	}
+	for _, node := range nodes {
+		if node >= numaNodes {
+			return fmt.Errorf("numa_node_affinity: NUMA node %d does not exist on host %q, which has %d NUMA nodes", node, props.Name, numaNodes)
+		}
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineValidateHardwareVersion checks that a change to
+// hardware_version is not a downgrade, and that the new version is supported
+// by the compute resource of the resource pool that the virtual machine is in.
+func resourceVSphereVirtualMachineValidateHardwareVersion(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if !d.HasChange("hardware_version") {
+		return nil
+	}
+	o, n := d.GetChange("hardware_version")
+	if n.(int) == 0 {
+		// Value is not set or not known yet.
+		return nil
+	}
+	if d.Id() != "" && n.(int) < o.(int) {
+		return fmt.Errorf("cannot downgrade hardware_version from %d to %d", o.(int), n.(int))
+	}
+	poolID := d.Get("resource_pool_id").(string)
+	if poolID == "" {
+		// Pool may not be known yet if it's being created in the same plan.
+		return nil
+	}
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	max, err := resourcepool.MaxHardwareVersion(client, pool)
+	if err != nil {
+		return fmt.Errorf("error fetching maximum supported hardware version: %s", err)
+	}
+	if n.(int) > max {
+		return fmt.Errorf("hardware_version %d is higher than the maximum version supported by resource pool %q (%d)", n.(int), poolID, max)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineValidateGuestID checks that a new or changed
+// guest_id is in the list of guest operating systems supported by the compute
+// resource of the resource pool that the virtual machine is in, for the
+// hardware version in use. This catches typos in guest_id at plan time, rather
+// than having the create or reconfigure fail during apply.
+func resourceVSphereVirtualMachineValidateGuestID(d *schema.ResourceDiff, client *govmomi.Client) error {
+	if !d.HasChange("guest_id") {
+		return nil
+	}
+	guestID := d.Get("guest_id").(string)
+	poolID := d.Get("resource_pool_id").(string)
+	if guestID == "other" || guestID == "other-64" {
+		// These are legacy aliases that vSphere accepts but does not list in
+		// the supported guest operating systems.
+		return nil
+	}
+	if guestID == "" || poolID == "" {
+		// Values may not be known yet if they are interpolated from resources
+		// that have not been created yet.
+		return nil
+	}
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	var key string
+	if v, ok := d.GetOk("hardware_version"); ok {
+		key = viapi.HardwareVersionKey(v.(int))
+	}
+	descs, err := resourcepool.GuestOSDescriptors(client, pool, key, nil)
+	if err != nil {
+		return fmt.Errorf("error fetching supported guest operating systems: %s", err)
+	}
+	for _, desc := range descs {
+		if desc.Id == guestID {
+			return nil
+		}
+	}
+	return fmt.Errorf("guest_id %q is not supported by resource pool %q. Use the vsphere_guest_os_ids data source to get a list of supported guest IDs", guestID, poolID)
+}
+
+func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*VSphereClient).vimClient
+
+	name := d.Id()
+	if name == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if strings.Contains(name, "*") {
+		return resourceVSphereVirtualMachineImportList(d, client, name)
+	}
+
+	log.Printf("[DEBUG] Looking for VM by name/path %q", name)
+	vm, err := virtualmachine.FromPath(client, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine: %s", err)
+	}
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+
+	// Block the import if the VM is a template.
+	if props.Config.Template {
 		return nil, fmt.Errorf("VM %q is a template and cannot be imported", name)
 	}
 
+	if err := resourceVSphereVirtualMachineImportProperties(d, client, name, props); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceVSphereVirtualMachineImportList imports all of the virtual machines
+// matching a path with wildcards, such as every virtual machine in a folder or
+// resource pool. Templates are skipped. The virtual machines are imported in
+// order of their names, the first one into the resource address that was
+// given to the import, and the rest into addresses with a numeric suffix.
+func resourceVSphereVirtualMachineImportList(d *schema.ResourceData, client *govmomi.Client, path string) ([]*schema.ResourceData, error) {
+	log.Printf("[DEBUG] Looking for VMs matching path %q", path)
+	vms, err := virtualmachine.ListFromPath(client, path)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machines: %s", err)
+	}
+	var vprops []*mo.VirtualMachine
+	for _, vm := range vms {
+		props, err := virtualmachine.Properties(vm)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+		}
+		if props.Config.Template {
+			log.Printf("[DEBUG] Skipping template %q", props.Name)
+			continue
+		}
+		vprops = append(vprops, props)
+	}
+	if len(vprops) < 1 {
+		return nil, fmt.Errorf("no virtual machines found matching %q", path)
+	}
+	sort.Slice(vprops, func(i, j int) bool {
+		return vprops[i].Name < vprops[j].Name
+	})
+
+	var results []*schema.ResourceData
+	for i, props := range vprops {
+		rd := d
+		if i > 0 {
+			rd = resourceVSphereVirtualMachine().Data(nil)
+			rd.SetType("vsphere_virtual_machine")
+		}
+		if err := resourceVSphereVirtualMachineImportProperties(rd, client, props.Name, props); err != nil {
+			return nil, err
+		}
+		results = append(results, rd)
+	}
+	return results, nil
+}
+
+// resourceVSphereVirtualMachineImportProperties validates a virtual machine
+// for import and sets the data needed for the first read in the supplied
+// ResourceData. The name is only used in log and error messages.
+func resourceVSphereVirtualMachineImportProperties(d *schema.ResourceData, client *govmomi.Client, name string, props *mo.VirtualMachine) error {
+	if err := resourceVSphereVirtualMachineImportDevices(d, client, name, props); err != nil {
+		return err
+	}
+	d.Set("imported", true)
+
+	// Set some defaults. This helps possibly prevent diffs where these values
+	// have not been changed.
+	rs := resourceVSphereVirtualMachine().Schema
+	d.Set("force_power_off", rs["force_power_off"].Default)
+	d.Set("migrate_wait_timeout", rs["migrate_wait_timeout"].Default)
+	d.Set("create_wait_timeout", rs["create_wait_timeout"].Default)
+	d.Set("shutdown_wait_timeout", rs["shutdown_wait_timeout"].Default)
+	d.Set("wait_for_guest_net_timeout", rs["wait_for_guest_net_timeout"].Default)
+	d.Set("hardware_upgrade_policy", rs["hardware_upgrade_policy"].Default)
+	d.Set("deletion_protection", rs["deletion_protection"].Default)
+	d.Set("unregister_on_destroy", rs["unregister_on_destroy"].Default)
+	d.Set("snapshot_before_disruptive_update", rs["snapshot_before_disruptive_update"].Default)
+	d.Set("snapshot_retention_count", rs["snapshot_retention_count"].Default)
+	for k := range resourceVSphereVirtualMachineSyncGroups {
+		d.Set(k, rs[k].Default)
+	}
+
+	log.Printf("[DEBUG] %s: Import complete, resource is ready for read", resourceVSphereVirtualMachineIDString(d))
+	return nil
+}
+
+// resourceVSphereVirtualMachineImportDevices validates the devices of a
+// virtual machine for import, sets up the SCSI controller count and disks so
+// that the next read picks them up, and sets the ID. It is shared by import
+// and adopt_existing.
+func resourceVSphereVirtualMachineImportDevices(d *schema.ResourceData, client *govmomi.Client, name string, props *mo.VirtualMachine) error {
 	// Quickly walk the SCSI bus and determine the number of contiguous
 	// controllers starting from bus number 0. This becomes the current SCSI
 	// controller count. Anything past this is managed by config.
@@ -616,7 +1514,7 @@ func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{
 		ctlrCnt++
 	}
 	if ctlrCnt < 1 {
-		return nil, fmt.Errorf("VM %q has no SCSI controllers", name)
+		return fmt.Errorf("VM %q has no SCSI controllers", name)
 	}
 	d.Set("scsi_controller_count", ctlrCnt)
 
@@ -624,23 +1522,12 @@ func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{
 	// resource. This is mainly ensuring that all disks are SCSI disks, but a
 	// Read operation is attempted as well to make sure it will survive that.
 	if err := virtualdevice.DiskImportOperation(d, client, object.VirtualDeviceList(props.Config.Hardware.Device)); err != nil {
-		return nil, err
+		return err
 	}
 	// The VM should be ready for reading now
 	log.Printf("[DEBUG] VM UUID for %q is %q", name, props.Config.Uuid)
 	d.SetId(props.Config.Uuid)
-	d.Set("imported", true)
-
-	// Set some defaults. This helps possibly prevent diffs where these values
-	// have not been changed.
-	rs := resourceVSphereVirtualMachine().Schema
-	d.Set("force_power_off", rs["force_power_off"].Default)
-	d.Set("migrate_wait_timeout", rs["migrate_wait_timeout"].Default)
-	d.Set("shutdown_wait_timeout", rs["shutdown_wait_timeout"].Default)
-	d.Set("wait_for_guest_net_timeout", rs["wait_for_guest_net_timeout"].Default)
-
-	log.Printf("[DEBUG] %s: Import complete, resource is ready for read", resourceVSphereVirtualMachineIDString(d))
-	return []*schema.ResourceData{d}, nil
+	return nil
 }
 
 // resourceVSphereVirtualMachineCreateBare contains the "bare metal" VM
@@ -658,7 +1545,7 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	// are saying here is that the VM folder that we are placing this VM in needs
 	// to be in the same hierarchy as the resource pool - so in other words, the
 	// same datacenter.
-	fo, err := folder.VirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+	fo, err := resourceVSphereVirtualMachineFolder(d, client, pool)
 	if err != nil {
 		return nil, err
 	}
@@ -682,8 +1569,12 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 		return nil, fmt.Errorf("error in virtual machine configuration: %s", err)
 	}
 
+	if v, ok := d.GetOk("hardware_version"); ok {
+		spec.Version = viapi.HardwareVersionKey(v.(int))
+	}
+
 	// Set the datastore for the VM.
-	ds, err := datastore.FromID(client, d.Get("datastore_id").(string))
+	ds, err := datastore.FromID(client, resourceVSphereVirtualMachineVmxDatastoreID(d))
 	if err != nil {
 		return nil, fmt.Errorf("error locating datastore for VM: %s", err)
 	}
@@ -706,9 +1597,9 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 	}
 
 	// We should now have a complete configSpec! Attempt to create the VM now.
-	vm, err := virtualmachine.Create(client, fo, spec, pool, hs)
+	vm, err := virtualmachine.Create(client, fo, spec, pool, hs, d.Get("create_wait_timeout").(int))
 	if err != nil {
-		return nil, fmt.Errorf("error creating virtual machine: %s", err)
+		return nil, viapi.NewDiagnostic(err, "error creating virtual machine")
 	}
 	// VM is created. Set the ID now before proceeding, in case the rest of the
 	// process here fails.
@@ -721,7 +1612,7 @@ func resourceVSphereVirtualMachineCreateBare(d *schema.ResourceData, meta interf
 
 	// Start the virtual machine
 	if err := virtualmachine.PowerOn(vm); err != nil {
-		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+		return nil, viapi.NewDiagnostic(err, "error powering on virtual machine")
 	}
 	return vm, nil
 }
@@ -741,7 +1632,7 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	if err != nil {
 		return nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
 	}
-	fo, err := folder.VirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+	fo, err := resourceVSphereVirtualMachineFolder(d, client, pool)
 	if err != nil {
 		return nil, err
 	}
@@ -751,13 +1642,17 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	if err != nil {
 		return nil, err
 	}
+	srcProps, err := virtualmachine.Properties(srcVM)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching source virtual machine or template properties: %s", err)
+	}
 
 	// Start the clone
 	name := d.Get("name").(string)
 	timeout := d.Get("clone.0.timeout").(int)
 	vm, err := virtualmachine.Clone(client, srcVM, fo, name, cloneSpec, timeout)
 	if err != nil {
-		return nil, fmt.Errorf("error cloning virtual machine: %s", err)
+		return nil, viapi.NewDiagnostic(err, "error cloning virtual machine")
 	}
 
 	// VM is created and updated. It's save to set the ID here now, in case the
@@ -768,15 +1663,82 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	}
 	log.Printf("[DEBUG] VM %q - UUID is %q", vm.InventoryPath, vprops.Config.Uuid)
 	d.SetId(vprops.Config.Uuid)
+	// The source is tracked so that changes to it can be reported in
+	// source_drifted, for pipelines that rebuild virtual machines when their
+	// template changes.
+	d.Set("source_template_instance_uuid", srcProps.Config.InstanceUuid)
+	d.Set("source_template_change_version", srcProps.Config.ChangeVersion)
+
+	// Any failure from here on leaves a virtual machine behind. It is either
+	// removed, if cleanup_on_failure is set, or kept in state so that Terraform
+	// marks it as tainted.
+	if err := resourceVSphereVirtualMachinePostClone(d, meta, vm, vprops, pool); err != nil {
+		return nil, resourceVSphereVirtualMachinePostCloneFailed(d, meta, vm, err)
+	}
+	// Clone is complete and ready to return
+	return vm, nil
+}
+
+// resourceVSphereVirtualMachineReadSourceDrift sets source_drifted for virtual
+// machines that were cloned. The source is looked up by the UUID in
+// clone.template_uuid, and has drifted if it is gone, if it was replaced by
+// another virtual machine or template with the same UUID, or if its
+// configuration changed since the clone. The source may be out of reach of
+// the provider, so errors other than the source being gone do not fail the
+// read.
+func resourceVSphereVirtualMachineReadSourceDrift(d *schema.ResourceData, client *govmomi.Client) {
+	tUUID := d.Get("clone.0.template_uuid").(string)
+	if d.Get("source_template_instance_uuid").(string) == "" || tUUID == "" {
+		d.Set("source_drifted", false)
+		return
+	}
+	src, err := virtualmachine.FromUUID(client, tUUID)
+	if err != nil {
+		resourceVSphereVirtualMachineSetSourceDrift(d, nil, err)
+		return
+	}
+	props, err := virtualmachine.Properties(src)
+	resourceVSphereVirtualMachineSetSourceDrift(d, props, err)
+}
+
+// resourceVSphereVirtualMachineSetSourceDrift sets source_drifted from the
+// result of fetching the properties of the source of a clone. When the
+// properties could not be fetched for any reason other than the source being
+// gone, source_drifted is left as it is.
+func resourceVSphereVirtualMachineSetSourceDrift(d *schema.ResourceData, props *mo.VirtualMachine, err error) {
+	tUUID := d.Get("clone.0.template_uuid").(string)
+	if err != nil {
+		if _, ok := err.(*virtualmachine.UUIDNotFoundError); ok {
+			log.Printf("[DEBUG] %s: Source %q not found, marking as drifted", resourceVSphereVirtualMachineIDString(d), tUUID)
+			d.Set("source_drifted", true)
+			return
+		}
+		log.Printf("[WARN] %s: Could not check source %q for drift, leaving source_drifted as it is: %s", resourceVSphereVirtualMachineIDString(d), tUUID, err)
+		return
+	}
+	drifted := props.Config == nil ||
+		props.Config.InstanceUuid != d.Get("source_template_instance_uuid").(string) ||
+		props.Config.ChangeVersion != d.Get("source_template_change_version").(string)
+	d.Set("source_drifted", drifted)
+}
 
+// resourceVSphereVirtualMachinePostClone carries out the operations on a
+// freshly cloned virtual machine: normalizing its configuration and devices,
+// upgrading its hardware, and sending and waiting on customization. The
+// virtual machine is powered on at the end.
+func resourceVSphereVirtualMachinePostClone(d *schema.ResourceData, meta interface{}, vm *object.VirtualMachine, vprops *mo.VirtualMachine, pool *object.ResourcePool) error {
+	client := meta.(*VSphereClient).vimClient
 	// Before starting or proceeding any further, we need to normalize the
 	// configuration of the newly cloned VM. This is basically a subset of update
 	// with the stipulation that there is currently no state to help move this
 	// along.
 	cfgSpec, err := expandVirtualMachineConfigSpec(d, client)
 	if err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(d, meta, vm, fmt.Errorf("error in virtual machine configuration: %s", err))
+		return fmt.Errorf("error in virtual machine configuration: %s", err)
 	}
+	// Preserve any notes that came from the source template if we are only
+	// managing a section of the annotation.
+	cfgSpec.Annotation = expandVirtualMachineAnnotation(d, vprops.Config.Annotation)
 
 	// To apply device changes, we need the current devicecfgSpec from the config
 	// info. We then filter this list through the same apply process we did for
@@ -786,33 +1748,69 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	// First check the state of our SCSI bus. Normalize it if we need to.
 	devices, delta, err = virtualdevice.NormalizeSCSIBus(devices, d.Get("scsi_type").(string), d.Get("scsi_controller_count").(int))
 	if err != nil {
-		return nil, err
+		return err
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	devices, delta, err = resourceVSphereVirtualMachineApplyWSFCBusSharing(d, devices)
+	if err != nil {
+		return err
+	}
+	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
+	devices, delta, err = resourceVSphereVirtualMachineApplyVideoCard(d, devices)
+	if err != nil {
+		return err
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	// Disks
 	devices, delta, err = virtualdevice.DiskPostCloneOperation(d, client, devices)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
-	// Network devices
+	// Network devices. With a network map and no network interfaces in
+	// configuration, the network interfaces of the source are kept, and only
+	// moved to their mapped networks. The network interfaces of an OVF deploy
+	// have been mapped in the import already, and are kept as they are.
+	nicsFromSource := len(d.Get("clone.0.network_map").([]interface{})) > 0 || len(d.Get("ovf_deploy").([]interface{})) > 0
+	if len(d.Get("network_interface").([]interface{})) == 0 && nicsFromSource {
+		mapFunc, err := vmworkflow.VirtualMachineCloneNetworkMapper(d)
+		if err != nil {
+			return err
+		}
+		if err := virtualdevice.NetworkInterfaceNetworkMapOperation(d, client, devices, mapFunc); err != nil {
+			return err
+		}
+	}
 	devices, delta, err = virtualdevice.NetworkInterfacePostCloneOperation(d, client, devices)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	// CDROM
 	devices, delta, err = virtualdevice.CdromPostCloneOperation(d, client, devices)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	cfgSpec.DeviceChange = virtualdevice.AppendDeviceChangeSpec(cfgSpec.DeviceChange, delta...)
 	log.Printf("[DEBUG] %s: Final device list: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceListString(devices))
 	log.Printf("[DEBUG] %s: Final device change cfgSpec: %s", resourceVSphereVirtualMachineIDString(d), virtualdevice.DeviceChangeString(cfgSpec.DeviceChange))
 
 	// Perform updates
-	if err := virtualmachine.Reconfigure(vm, cfgSpec); err != nil {
-		return nil, resourceVSphereVirtualMachineRollbackCreate(d, meta, vm, fmt.Errorf("error reconfiguring virtual machine: %s", err))
+	if err := virtualmachine.ReconfigureWithTimeout(vm, cfgSpec, d.Get("create_wait_timeout").(int)); err != nil {
+		return viapi.NewDiagnostic(err, "error reconfiguring virtual machine")
+	}
+
+	// Upgrade the hardware version if it is higher than the one of the source
+	// template. The VM is still powered off at this point, so this is done
+	// immediately regardless of hardware_upgrade_policy.
+	upgradeKey, err := virtualMachineHardwareUpgradeKey(d, vprops.Config.Version)
+	if err != nil {
+		return err
+	}
+	if upgradeKey != "" {
+		if err := virtualmachine.UpgradeHardware(vm, upgradeKey); err != nil {
+			return viapi.NewDiagnostic(err, "error upgrading virtual machine hardware")
+		}
 	}
 
 	var cw *virtualMachineCustomizationWaiter
@@ -820,40 +1818,47 @@ func resourceVSphereVirtualMachineCreateClone(d *schema.ResourceData, meta inter
 	if len(d.Get("clone.0.customize").([]interface{})) > 0 {
 		family, err := resourcepool.OSFamily(client, pool, d.Get("guest_id").(string))
 		if err != nil {
-			return nil, fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
+			return fmt.Errorf("cannot find OS family for guest ID %q: %s", d.Get("guest_id").(string), err)
 		}
 		custSpec := vmworkflow.ExpandCustomizationSpec(d, family)
 		cw = newVirtualMachineCustomizationWaiter(client, vm, d.Get("clone.0.customize.0.timeout").(int))
 		if err := virtualmachine.Customize(vm, custSpec); err != nil {
-			// Roll back the VMs as per the error handling in reconfigure.
-			if derr := resourceVSphereVirtualMachineDelete(d, meta); derr != nil {
-				return nil, fmt.Errorf(formatVirtualMachinePostCloneRollbackError, vm.InventoryPath, err, derr)
-			}
-			d.SetId("")
-			return nil, fmt.Errorf("error sending customization spec: %s", err)
+			return fmt.Errorf("error sending customization spec: %s", err)
 		}
 	}
 	// Finally time to power on the virtual machine!
 	if err := virtualmachine.PowerOn(vm); err != nil {
-		return nil, fmt.Errorf("error powering on virtual machine: %s", err)
+		return viapi.NewDiagnostic(err, "error powering on virtual machine")
 	}
 	// If we customized, wait on customization.
 	if cw != nil {
 		log.Printf("[DEBUG] %s: Waiting for VM customization to complete", resourceVSphereVirtualMachineIDString(d))
 		<-cw.Done()
 		if err := cw.Err(); err != nil {
-			return nil, fmt.Errorf(formatVirtualMachineCustomizationWaitError, vm.InventoryPath, err)
+			return fmt.Errorf("error waiting for customization: %s", err)
 		}
 	}
-	// Clone is complete and ready to return
-	return vm, nil
+	return nil
+}
+
+// resourceVSphereVirtualMachinePostCloneFailed handles an error in the
+// post-clone operations of a virtual machine. With cleanup_on_failure set,
+// the virtual machine is removed. Otherwise, its ID is kept so that Terraform
+// saves it in state as tainted, and it is replaced on the next apply instead
+// of being left behind unmanaged.
+func resourceVSphereVirtualMachinePostCloneFailed(d *schema.ResourceData, meta interface{}, vm *object.VirtualMachine, origErr error) error {
+	if d.Get("clone.0.cleanup_on_failure").(bool) {
+		log.Printf("[DEBUG] %s: Removing virtual machine after post-clone failure: %s", resourceVSphereVirtualMachineIDString(d), origErr)
+		return resourceVSphereVirtualMachineRollbackCreate(d, meta, vm, origErr)
+	}
+	d.Set("moid", vm.Reference().Value)
+	return fmt.Errorf(formatVirtualMachinePostCloneFailedError, vm.InventoryPath, origErr, vm.Reference().Value)
 }
 
 // resourceVSphereVirtualMachineRollbackCreate attempts to "roll back" a
 // resource due to an error that happened post-create that will put the VM in a
-// state where it cannot be worked with. This should only be done early on in
-// the process, namely on clone operations between when the clone actually
-// happens, and no later than after the initial post-clone update is complete.
+// state where it cannot be worked with. This is done on clone operations that
+// fail after the clone actually happens, when cleanup_on_failure is set.
 //
 // If the rollback fails, an error is displayed prompting the user to manually
 // delete the virtual machine before trying again.
@@ -871,7 +1876,7 @@ func resourceVSphereVirtualMachineRollbackCreate(
 		return fmt.Errorf(formatVirtualMachinePostCloneRollbackError, vm.InventoryPath, origErr, err)
 	}
 	d.SetId("")
-	return fmt.Errorf("error reconfiguring virtual machine: %s", origErr)
+	return origErr
 }
 
 // resourceVSphereVirtualMachineUpdateLocation manages vMotion. This includes
@@ -903,7 +1908,7 @@ func resourceVSphereVirtualMachineUpdateLocation(d *schema.ResourceData, meta in
 		return err
 	}
 	// If we don't have any changes, stop here.
-	if !d.HasChange("resource_pool_id") && !d.HasChange("host_system_id") && !d.HasChange("datastore_id") && len(relocators) < 1 {
+	if !d.HasChange("resource_pool_id") && !d.HasChange("host_system_id") && !d.HasChange("datastore_id") && !d.HasChange("vmx_datastore_id") && len(relocators) < 1 {
 		log.Printf("[DEBUG] %s: No migration operations found", resourceVSphereVirtualMachineIDString(d))
 		return nil
 	}
@@ -928,7 +1933,7 @@ func resourceVSphereVirtualMachineUpdateLocation(d *schema.ResourceData, meta in
 	}
 
 	// Fetch the datastore
-	ds, err := datastore.FromID(client, d.Get("datastore_id").(string))
+	ds, err := datastore.FromID(client, resourceVSphereVirtualMachineVmxDatastoreID(d))
 	if err != nil {
 		return fmt.Errorf("error locating datastore for VM: %s", err)
 	}
@@ -951,24 +1956,137 @@ func resourceVSphereVirtualMachineUpdateLocation(d *schema.ResourceData, meta in
 	return virtualmachine.Relocate(vm, spec, d.Get("migrate_wait_timeout").(int))
 }
 
+// resourceVSphereVirtualMachineVmxDatastoreID returns the ID of the datastore
+// that the virtual machine configuration files should be on: vmx_datastore_id
+// if it is set, and datastore_id otherwise.
+func resourceVSphereVirtualMachineVmxDatastoreID(d *schema.ResourceData) string {
+	if v := d.Get("vmx_datastore_id").(string); v != "" {
+		return v
+	}
+	return d.Get("datastore_id").(string)
+}
+
+// resourceVSphereVirtualMachineDiffVmxDatastore makes vmx_datastore_id follow
+// datastore_id when the configuration files are on the same datastore as
+// datastore_id and vmx_datastore_id has not been changed in configuration, so
+// that changing datastore_id still moves the whole virtual machine.
+func resourceVSphereVirtualMachineDiffVmxDatastore(d *schema.ResourceDiff) error {
+	if d.Id() == "" || d.HasChange("vmx_datastore_id") || !d.HasChange("datastore_id") {
+		return nil
+	}
+	oldDS, newDS := d.GetChange("datastore_id")
+	if d.Get("vmx_datastore_id").(string) != oldDS.(string) {
+		return nil
+	}
+	if newDS.(string) == "" {
+		return d.SetNewComputed("vmx_datastore_id")
+	}
+	return d.SetNew("vmx_datastore_id", newDS)
+}
+
+// resourceVSphereVirtualMachineApplyDeletionProtection disables or enables the
+// methods in virtualMachineDeletionProtectionMethods on the virtual machine,
+// depending on deletion_protection.
+func resourceVSphereVirtualMachineApplyDeletionProtection(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine) error {
+	if !d.Get("deletion_protection").(bool) {
+		if err := virtualmachine.EnableMethods(client, vm, virtualMachineDeletionProtectionMethods, virtualMachineDeletionProtectionSource); err != nil {
+			return fmt.Errorf("error removing deletion protection: %s", err)
+		}
+		return nil
+	}
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return fmt.Errorf("deletion_protection requires vCenter: %s", err)
+	}
+	reason := "The virtual machine is protected from deletion by Terraform."
+	if err := virtualmachine.DisableMethods(client, vm, virtualMachineDeletionProtectionMethods, virtualMachineDeletionProtectionSource, reason); err != nil {
+		return fmt.Errorf("error applying deletion protection: %s", err)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineCreatePreUpdateSnapshot takes a snapshot of
+// the virtual machine ahead of an update that needed it to be powered off.
+// The snapshot is named after virtualMachinePreUpdateSnapshotPrefix and the
+// current time.
+func resourceVSphereVirtualMachineCreatePreUpdateSnapshot(d *schema.ResourceData, vm *object.VirtualMachine) error {
+	name := virtualMachinePreUpdateSnapshotPrefix + time.Now().UTC().Format("20060102T150405Z")
+	log.Printf("[DEBUG] %s: Taking snapshot %q before update", resourceVSphereVirtualMachineIDString(d), name)
+	if err := virtualmachine.CreateSnapshot(vm, name, "Taken by Terraform before updating the virtual machine.", false, false); err != nil {
+		return fmt.Errorf("error taking snapshot before update: %s", err)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachinePrunePreUpdateSnapshots removes the oldest
+// snapshots taken by resourceVSphereVirtualMachineCreatePreUpdateSnapshot,
+// keeping snapshot_retention_count of them.
+func resourceVSphereVirtualMachinePrunePreUpdateSnapshots(d *schema.ResourceData, client *govmomi.Client, vprops *mo.VirtualMachine) error {
+	for _, snapshot := range virtualMachinePreUpdateSnapshotsToRemove(virtualmachine.Snapshots(vprops), d.Get("snapshot_retention_count").(int)) {
+		log.Printf("[DEBUG] %s: Removing snapshot %q", resourceVSphereVirtualMachineIDString(d), snapshot.Name)
+		if err := virtualmachine.RemoveSnapshot(client, snapshot.Snapshot); err != nil {
+			return fmt.Errorf("error removing snapshot %q: %s", snapshot.Name, err)
+		}
+	}
+	return nil
+}
+
+// virtualMachinePreUpdateSnapshotsToRemove returns the snapshots in
+// snapshots that were taken before an update, oldest first, leaving out the
+// newest keep of them. Other snapshots are never returned.
+func virtualMachinePreUpdateSnapshotsToRemove(snapshots []types.VirtualMachineSnapshotTree, keep int) []types.VirtualMachineSnapshotTree {
+	var result []types.VirtualMachineSnapshotTree
+	for _, snapshot := range snapshots {
+		if strings.HasPrefix(snapshot.Name, virtualMachinePreUpdateSnapshotPrefix) {
+			result = append(result, snapshot)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreateTime.Before(result[j].CreateTime)
+	})
+	if len(result) <= keep {
+		return nil
+	}
+	return result[:len(result)-keep]
+}
+
 // applyVirtualDevices is used by Create and Update to build a list of virtual
 // device changes.
 func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, l object.VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
 	// We filter this device list through each major device class' apply
 	// operation. This will give us a final set of changes that will be our
 	// deviceChange attribute.
-	var spec, delta []types.BaseVirtualDeviceConfigSpec
+	var spec, delta, scsiDelta []types.BaseVirtualDeviceConfigSpec
 	var err error
 	// First check the state of our SCSI bus. Normalize it if we need to.
-	l, delta, err = virtualdevice.NormalizeSCSIBus(l, d.Get("scsi_type").(string), d.Get("scsi_controller_count").(int))
+	l, scsiDelta, err = virtualdevice.NormalizeSCSIBus(l, d.Get("scsi_type").(string), d.Get("scsi_controller_count").(int))
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, scsiDelta...)
+	l, delta, err = resourceVSphereVirtualMachineApplyWSFCBusSharing(d, l)
 	if err != nil {
 		return nil, err
 	}
 	if len(delta) > 0 {
-		log.Printf("[DEBUG] %s: SCSI bus has changed and requires a VM restart", resourceVSphereVirtualMachineIDString(d))
+		log.Printf("[DEBUG] %s: SCSI bus sharing has changed and requires a VM restart", resourceVSphereVirtualMachineIDString(d))
 		d.Set("reboot_required", true)
 	}
 	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	// This is checked after bus sharing is applied, as that changes the
+	// controllers that are being added.
+	if len(scsiDelta) > 0 {
+		if virtualdevice.SCSIBusChangeHotAddable(scsiDelta) {
+			log.Printf("[DEBUG] %s: SCSI controllers are only being added, hot-adding them", resourceVSphereVirtualMachineIDString(d))
+		} else {
+			log.Printf("[DEBUG] %s: SCSI bus has changed and requires a VM restart", resourceVSphereVirtualMachineIDString(d))
+			d.Set("reboot_required", true)
+		}
+	}
+	l, delta, err = resourceVSphereVirtualMachineApplyVideoCard(d, l)
+	if err != nil {
+		return nil, err
+	}
+	spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
 	// Disks
 	l, delta, err = virtualdevice.DiskApplyOperation(d, c, l)
 	if err != nil {
@@ -992,6 +2110,109 @@ func applyVirtualDevices(d *schema.ResourceData, c *govmomi.Client, l object.Vir
 	return spec, nil
 }
 
+// resourceVSphereVirtualMachineSyncGroups maps each of the sync_* attributes
+// in the vsphere_virtual_machine resource to the attributes that it controls
+// the refresh of.
+var resourceVSphereVirtualMachineSyncGroups = map[string][]string{
+	"sync_cpu_memory": {
+		"num_cpus",
+		"num_cores_per_socket",
+		"memory",
+		"cpu_limit",
+		"cpu_reservation",
+		"cpu_share_level",
+		"cpu_share_count",
+		"memory_limit",
+		"memory_reservation",
+		"memory_share_level",
+		"memory_share_count",
+		"memory_reservation_locked_to_max",
+		"memory_balloon_max_size",
+	},
+	"sync_disks":              {"disk"},
+	"sync_network_interfaces": {"network_interface"},
+	"sync_extra_config":       {"extra_config"},
+	"sync_annotation":         {"annotation", "managed_annotation"},
+}
+
+// resourceVSphereVirtualMachineSyncSkipped returns true if the refresh of the
+// attribute group controlled by the sync_* attribute at key should be skipped.
+//
+// A group is only ever skipped when it has been explicitly excluded from sync
+// in configuration. Even then, the group is always read on new resources, and
+// when any attribute in the group has a pending change, so that the state
+// reflects the result of any changes Terraform has made.
+func resourceVSphereVirtualMachineSyncSkipped(d *schema.ResourceData, key string) bool {
+	if d.IsNewResource() {
+		return false
+	}
+	if v, ok := d.GetOkExists(key); !ok || v.(bool) {
+		return false
+	}
+	for _, k := range resourceVSphereVirtualMachineSyncGroups[key] {
+		if d.HasChange(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceVSphereVirtualMachineUnsyncedValues returns the current values of
+// any of the non-device attributes that are in groups that are being skipped
+// for sync. Device groups are handled by skipping their refresh operations
+// altogether.
+func resourceVSphereVirtualMachineUnsyncedValues(d *schema.ResourceData) map[string]interface{} {
+	m := make(map[string]interface{})
+	for _, key := range []string{"sync_cpu_memory", "sync_extra_config", "sync_annotation"} {
+		if !resourceVSphereVirtualMachineSyncSkipped(d, key) {
+			continue
+		}
+		log.Printf("[DEBUG] %s: Sync disabled via %s, preserving existing values", resourceVSphereVirtualMachineIDString(d), key)
+		for _, k := range resourceVSphereVirtualMachineSyncGroups[key] {
+			m[k] = d.Get(k)
+		}
+	}
+	return m
+}
+
+// resourceVSphereVirtualMachineFolderDiffSuppress suppresses the diff on
+// folder when the configured path matches the current path of a folder that
+// was renamed outside of Terraform, as the virtual machine is already in it.
+func resourceVSphereVirtualMachineFolderDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
+	if d.Id() == "" {
+		return false
+	}
+	return folder.NormalizePath(new) == d.Get("current_folder").(string)
+}
+
+// resourceVSphereVirtualMachineFolder returns the folder to create the
+// virtual machine in, creating it first if create_folders is set.
+func resourceVSphereVirtualMachineFolder(d *schema.ResourceData, client *govmomi.Client, pool *object.ResourcePool) (*object.Folder, error) {
+	p := d.Get("folder").(string)
+	if d.Get("create_folders").(bool) {
+		return folder.CreateVirtualMachineFolderFromObject(client, pool, p)
+	}
+	return folder.VirtualMachineFolderFromObject(client, pool, p)
+}
+
+// resourceVSphereVirtualMachineRemoveEmptyFolders removes the folder of a
+// destroyed virtual machine, and each of its parents, if they are empty.
+func resourceVSphereVirtualMachineRemoveEmptyFolders(d *schema.ResourceData, client *govmomi.Client) error {
+	p := d.Get("folder").(string)
+	if folder.PathIsEmpty(p) {
+		return nil
+	}
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	if err := folder.RemoveEmptyVirtualMachineFolders(client, pool, p); err != nil {
+		return fmt.Errorf("error removing empty folders: %s", err)
+	}
+	return nil
+}
+
 // resourceVSphereVirtualMachineIDString prints a friendly string for the
 // vsphere_virtual_machine resource.
 func resourceVSphereVirtualMachineIDString(d structure.ResourceIDStringer) string {
@@ -0,0 +1,81 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourceVSphereVirtualMachineAdopt looks for a virtual machine with the
+// configured name in the target folder when adopt_existing is set. If one is
+// found, it is set up in the resource data the same way an imported virtual
+// machine is, powered on, and returned. nil is returned if adopt_existing is
+// not set or there is no such virtual machine, in which case a new one should
+// be deployed.
+//
+// Only the devices and ID are taken from the adopted virtual machine. The rest
+// of the configuration is kept, so that the next plan shows the changes that
+// are needed to reconcile the virtual machine with the configuration.
+func resourceVSphereVirtualMachineAdopt(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+	if !d.Get("adopt_existing").(bool) {
+		return nil, nil
+	}
+	client := meta.(*VSphereClient).vimClient
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	fo, err := folder.VirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+	if err != nil {
+		if _, ok := err.(*find.NotFoundError); ok {
+			log.Printf("[DEBUG] %s: Folder not found, no virtual machine to adopt", resourceVSphereVirtualMachineIDString(d))
+			return nil, nil
+		}
+		return nil, err
+	}
+	p := fo.InventoryPath + "/" + d.Get("name").(string)
+	log.Printf("[DEBUG] %s: Looking for virtual machine %q to adopt", resourceVSphereVirtualMachineIDString(d), p)
+	vm, err := virtualmachine.FromPath(client, p, nil)
+	if err != nil {
+		if _, ok := err.(*find.NotFoundError); ok {
+			log.Printf("[DEBUG] %s: Virtual machine %q not found, creating a new one", resourceVSphereVirtualMachineIDString(d), p)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error looking for virtual machine %q: %s", p, err)
+	}
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Config.Template {
+		return nil, fmt.Errorf("%q is a template and cannot be adopted", p)
+	}
+
+	// Clear the configured network interfaces and CD-ROMs so that the next read
+	// picks up the devices of the virtual machine, as it does after an import.
+	// Disks are set up by resourceVSphereVirtualMachineImportDevices.
+	for _, k := range []string{"network_interface", "cdrom"} {
+		if err := d.Set(k, nil); err != nil {
+			return nil, fmt.Errorf("error clearing %s: %s", k, err)
+		}
+	}
+	if err := resourceVSphereVirtualMachineImportDevices(d, client, p, props); err != nil {
+		return nil, fmt.Errorf("virtual machine %q cannot be adopted: %s", p, err)
+	}
+
+	if props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		if err := virtualmachine.PowerOn(vm); err != nil {
+			return nil, viapi.NewDiagnostic(err, "error powering on adopted virtual machine")
+		}
+	}
+	return vm, nil
+}
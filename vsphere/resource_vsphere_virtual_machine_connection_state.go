@@ -0,0 +1,107 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualMachineConnectionStateUnreadable returns true for the connection
+// states in which vCenter has no usable configuration for a virtual machine,
+// so that it cannot be read or changed.
+func virtualMachineConnectionStateUnreadable(state types.VirtualMachineConnectionState) bool {
+	switch state {
+	case types.VirtualMachineConnectionStateOrphaned,
+		types.VirtualMachineConnectionStateInaccessible,
+		types.VirtualMachineConnectionStateInvalid:
+		return true
+	}
+	return false
+}
+
+// virtualMachineConnectionStateRepairable returns true for the connection
+// states that can be repaired by registering the virtual machine again.
+// Inaccessible virtual machines are not, as their files cannot be reached.
+func virtualMachineConnectionStateRepairable(state types.VirtualMachineConnectionState) bool {
+	return state == types.VirtualMachineConnectionStateOrphaned || state == types.VirtualMachineConnectionStateInvalid
+}
+
+// resourceVSphereVirtualMachineDiffConnectionState plans a repair of an
+// orphaned or invalid virtual machine when repair_orphaned is set, by
+// planning connection_state to become connected.
+func resourceVSphereVirtualMachineDiffConnectionState(d *schema.ResourceDiff) error {
+	if d.Id() == "" || !d.Get("repair_orphaned").(bool) {
+		return nil
+	}
+	state := types.VirtualMachineConnectionState(d.Get("connection_state").(string))
+	if !virtualMachineConnectionStateRepairable(state) {
+		return nil
+	}
+	log.Printf("[DEBUG] %s: Virtual machine is %s, planning repair", resourceVSphereVirtualMachineIDString(d), state)
+	return d.SetNew("connection_state", string(types.VirtualMachineConnectionStateConnected))
+}
+
+// resourceVSphereVirtualMachineCheckConnectionState makes sure that a virtual
+// machine can be updated. Orphaned and invalid virtual machines are
+// registered again from their configuration file when repair_orphaned is set,
+// in which case the new virtual machine is returned. Otherwise, an error
+// describing the state of the virtual machine is returned.
+func resourceVSphereVirtualMachineCheckConnectionState(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine) (*object.VirtualMachine, error) {
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	state := props.Runtime.ConnectionState
+	if !virtualMachineConnectionStateUnreadable(state) {
+		return vm, nil
+	}
+	if !virtualMachineConnectionStateRepairable(state) {
+		return nil, fmt.Errorf("virtual machine %q is %s: its files cannot be reached by its host. Restore access to its datastore before updating it", vm.InventoryPath, state)
+	}
+	if !d.Get("repair_orphaned").(bool) {
+		return nil, fmt.Errorf("virtual machine %q is %s. Set repair_orphaned to register it again from its configuration file, or repair it in vCenter", vm.InventoryPath, state)
+	}
+	return resourceVSphereVirtualMachineRepair(d, client, vm, props)
+}
+
+// resourceVSphereVirtualMachineRepair unregisters a virtual machine and
+// registers it again from its configuration file, in the same folder,
+// resource pool, and host.
+func resourceVSphereVirtualMachineRepair(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine, props *mo.VirtualMachine) (*object.VirtualMachine, error) {
+	path := d.Get("vmx_datastore_path").(string)
+	if props.Summary.Config.VmPathName != "" {
+		path = props.Summary.Config.VmPathName
+	}
+	if path == "" || props.Parent == nil || props.ResourcePool == nil {
+		return nil, fmt.Errorf("virtual machine %q is %s and cannot be repaired: its configuration file or location is unknown", vm.InventoryPath, props.Runtime.ConnectionState)
+	}
+	folder := object.NewFolder(client.Client, *props.Parent)
+	pool := object.NewResourcePool(client.Client, *props.ResourcePool)
+	var host *object.HostSystem
+	if props.Runtime.Host != nil {
+		h, err := hostsystem.FromID(client, props.Runtime.Host.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error locating host of virtual machine: %s", err)
+		}
+		host = h
+	}
+
+	log.Printf("[DEBUG] %s: Repairing %s virtual machine by registering %q again", resourceVSphereVirtualMachineIDString(d), props.Runtime.ConnectionState, path)
+	if err := virtualmachine.Unregister(vm); err != nil {
+		return nil, viapi.NewDiagnostic(err, "error unregistering %s virtual machine", props.Runtime.ConnectionState)
+	}
+	newVM, err := virtualmachine.Register(client, folder, path, props.Name, false, pool, host)
+	if err != nil {
+		return nil, viapi.NewDiagnostic(err, "virtual machine was unregistered, but registering %q again failed. Register it manually to recover it", path)
+	}
+	d.Set("moid", newVM.Reference().Value)
+	return newVM, nil
+}
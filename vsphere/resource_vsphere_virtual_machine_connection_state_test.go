@@ -0,0 +1,69 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestVirtualMachineConnectionStateUnreadable(t *testing.T) {
+	cases := []struct {
+		state              types.VirtualMachineConnectionState
+		expectedUnreadable bool
+		expectedRepairable bool
+	}{
+		{types.VirtualMachineConnectionStateConnected, false, false},
+		{types.VirtualMachineConnectionStateDisconnected, false, false},
+		{types.VirtualMachineConnectionStateOrphaned, true, true},
+		{types.VirtualMachineConnectionStateInvalid, true, true},
+		{types.VirtualMachineConnectionStateInaccessible, true, false},
+	}
+	for _, tc := range cases {
+		if actual := virtualMachineConnectionStateUnreadable(tc.state); actual != tc.expectedUnreadable {
+			t.Fatalf("%s: expected unreadable to be %t, got %t", tc.state, tc.expectedUnreadable, actual)
+		}
+		if actual := virtualMachineConnectionStateRepairable(tc.state); actual != tc.expectedRepairable {
+			t.Fatalf("%s: expected repairable to be %t, got %t", tc.state, tc.expectedRepairable, actual)
+		}
+	}
+}
+
+func TestResourceVSphereVirtualMachineDiffConnectionState(t *testing.T) {
+	r := resourceVSphereVirtualMachine()
+	r.CustomizeDiff = func(d *schema.ResourceDiff, _ interface{}) error {
+		return resourceVSphereVirtualMachineDiffConnectionState(d)
+	}
+	cases := []struct {
+		name           string
+		id             string
+		state          types.VirtualMachineConnectionState
+		repairOrphaned bool
+		expectRepair   bool
+	}{
+		{"orphaned, repair_orphaned set", "uuid", types.VirtualMachineConnectionStateOrphaned, true, true},
+		{"invalid, repair_orphaned set", "uuid", types.VirtualMachineConnectionStateInvalid, true, true},
+		{"orphaned, repair_orphaned not set", "uuid", types.VirtualMachineConnectionStateOrphaned, false, false},
+		{"inaccessible, repair_orphaned set", "uuid", types.VirtualMachineConnectionStateInaccessible, true, false},
+		{"connected, repair_orphaned set", "uuid", types.VirtualMachineConnectionStateConnected, true, false},
+		{"new resource", "", "", true, false},
+	}
+	for _, tc := range cases {
+		attrs := map[string]string{
+			"connection_state": string(tc.state),
+			"repair_orphaned":  "false",
+		}
+		raw := map[string]interface{}{
+			"repair_orphaned": tc.repairOrphaned,
+		}
+		diff, err := testResourceDiff(r, tc.id, attrs, raw, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		attr, ok := diff.Attributes["connection_state"]
+		repair := ok && attr.New == string(types.VirtualMachineConnectionStateConnected) && !attr.NewComputed
+		if repair != tc.expectRepair {
+			t.Fatalf("%s: expected repair to be planned to be %t, got %t (diff: %#v)", tc.name, tc.expectRepair, repair, attr)
+		}
+	}
+}
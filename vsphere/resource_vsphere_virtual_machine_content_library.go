@@ -0,0 +1,79 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/contentlibrary"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/object"
+)
+
+// resourceVSphereVirtualMachineCreateFromLibraryItem contains the deploy path
+// for virtual machines that are cloned from an OVF template or VM template in
+// a content library, through clone.content_library_item_id. The virtual
+// machine is deployed powered off, and then goes through the same post-clone
+// operations as a clone from a virtual machine. The VM is returned.
+func resourceVSphereVirtualMachineCreateFromLibraryItem(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] %s: VM being deployed from content library item", resourceVSphereVirtualMachineIDString(d))
+	client := meta.(*VSphereClient)
+	tagsClient, err := client.TagsClient()
+	if err != nil {
+		return nil, fmt.Errorf("deploying from a content library requires the CIS REST API: %s", err)
+	}
+	server := client.vimClient.URL()
+
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client.vimClient, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	fo, err := resourceVSphereVirtualMachineFolder(d, client.vimClient, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	itemID := d.Get("clone.0.content_library_item_id").(string)
+	item, err := contentlibrary.FromID(tagsClient, server, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching content library item %q: %s", itemID, err)
+	}
+	// The configuration files go to vmx_datastore_id if it is set, like they do
+	// for clones. The disks are moved to their own datastores in the post-clone
+	// operations.
+	dsID := d.Get("vmx_datastore_id").(string)
+	if dsID == "" {
+		dsID = d.Get("datastore_id").(string)
+	}
+	spec := contentlibrary.DeploySpec{
+		Name:           d.Get("name").(string),
+		ResourcePoolID: pool.Reference().Value,
+		FolderID:       fo.Reference().Value,
+		HostID:         d.Get("host_system_id").(string),
+		DatastoreID:    dsID,
+	}
+	timeout := time.Duration(d.Get("clone.0.timeout").(int)) * time.Minute
+	moid, err := contentlibrary.Deploy(tagsClient, server, item, spec, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error deploying virtual machine from content library item %q: %s", item.Name, err)
+	}
+
+	vm, err := virtualmachine.FromMOID(client.vimClient, moid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate deployed virtual machine %q: %s", moid, err)
+	}
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch properties of created virtual machine: %s", err)
+	}
+	log.Printf("[DEBUG] VM %q - UUID is %q", vm.InventoryPath, vprops.Config.Uuid)
+	d.SetId(vprops.Config.Uuid)
+
+	if err := resourceVSphereVirtualMachinePostClone(d, meta, vm, vprops, pool); err != nil {
+		return nil, resourceVSphereVirtualMachinePostCloneFailed(d, meta, vm, err)
+	}
+	return vm, nil
+}
@@ -0,0 +1,610 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/storagepod"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const resourceVSphereVirtualMachineGroupName = "vsphere_virtual_machine_group"
+
+// virtualMachineGroupTemplateKey is the extraConfig key that the UUID of the
+// source template is recorded under in each member of a
+// vsphere_virtual_machine_group. This is used to find members that need to
+// be replaced after the template has changed.
+const virtualMachineGroupTemplateKey = "terraform.virtualMachineGroup.templateUUID"
+
+// virtualMachineGroupSurgeSuffix is appended to the name of a member of a
+// vsphere_virtual_machine_group while its replacement is created ahead of
+// removing it.
+const virtualMachineGroupSurgeSuffix = "-replacement"
+
+func resourceVSphereVirtualMachineGroup() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVirtualMachineGroupCreate,
+		Read:   resourceVSphereVirtualMachineGroupRead,
+		Update: resourceVSphereVirtualMachineGroupUpdate,
+		Delete: resourceVSphereVirtualMachineGroupDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name_format": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "The format of the names of the virtual machines in the group, in fmt.Sprintf syntax. The 1-based index of the virtual machine is passed as the only argument.",
+				ValidateFunc: validateVirtualMachineGroupNameFormat,
+			},
+			"size": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "The number of virtual machines in the group.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"template_uuid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The UUID of the virtual machine or template to clone the virtual machines in the group from.",
+			},
+			"replace_on_template_change": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Replace the virtual machines in the group when template_uuid changes. When false, only virtual machines created after the change use the new template.",
+			},
+			"replace_batch_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				Description:  "The number of virtual machines to replace at a time when replace_on_template_change is set.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"replace_max_surge": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "The number of virtual machines in each batch for which the replacement is created before the old virtual machine is removed.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"resource_pool_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the resource pool to put the virtual machines in.",
+			},
+			"folder": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The name of the folder to locate the virtual machines in.",
+				StateFunc:   folder.NormalizePath,
+			},
+			"host_system_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The managed object IDs of the hosts to spread the virtual machines across.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"datastore_ids": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Description:   "The managed object IDs of the datastores to spread the virtual machines across.",
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"datastore_cluster_id"},
+			},
+			"datastore_cluster_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The managed object ID of a datastore cluster to spread the virtual machines across the member datastores of.",
+				ConflictsWith: []string{"datastore_ids"},
+			},
+			"clone_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				Description:  "The timeout, in minutes, to wait for each virtual machine clone to complete.",
+				ValidateFunc: validation.IntAtLeast(10),
+			},
+			"shutdown_wait_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				Description:  "The amount of time, in minutes, to wait for a virtual machine to shut down before it is removed.",
+				ValidateFunc: validation.IntBetween(1, 10),
+			},
+			"virtual_machine_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The UUIDs of the virtual machines in the group, in the same order as names.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The names of the virtual machines in the group, ordered by index.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereVirtualMachineGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereVirtualMachineGroupIDString(d))
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return fmt.Errorf("error generating ID: %s", err)
+	}
+	d.SetId(id)
+
+	if err := resourceVSphereVirtualMachineGroupApply(d, meta); err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereVirtualMachineGroupIDString(d))
+	return resourceVSphereVirtualMachineGroupRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineGroupRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereVirtualMachineGroupIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	members, err := virtualMachineGroupMembersFromState(d, client)
+	if err != nil {
+		return err
+	}
+
+	// Members that have gone missing are dropped from state, and the size is
+	// adjusted to what is left so that the next plan re-creates them.
+	if err := virtualMachineGroupSaveMembers(d, members); err != nil {
+		return err
+	}
+	d.Set("size", len(members))
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereVirtualMachineGroupIDString(d))
+	return nil
+}
+
+func resourceVSphereVirtualMachineGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning update", resourceVSphereVirtualMachineGroupIDString(d))
+	// Don't save the new template until all members have been replaced, so
+	// that an interrupted rolling replacement is resumed on the next apply.
+	d.Partial(true)
+	for k := range resourceVSphereVirtualMachineGroup().Schema {
+		if k != "template_uuid" || !d.Get("replace_on_template_change").(bool) {
+			d.SetPartial(k)
+		}
+	}
+	if err := resourceVSphereVirtualMachineGroupApply(d, meta); err != nil {
+		return err
+	}
+	d.Partial(false)
+
+	log.Printf("[DEBUG] %s: Update finished successfully", resourceVSphereVirtualMachineGroupIDString(d))
+	return resourceVSphereVirtualMachineGroupRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	resourceIDString := resourceVSphereVirtualMachineGroupIDString(d)
+	log.Printf("[DEBUG] %s: Beginning delete", resourceIDString)
+	client := meta.(*VSphereClient).vimClient
+	members, err := virtualMachineGroupMembersFromState(d, client)
+	if err != nil {
+		return err
+	}
+	for i := len(members) - 1; i >= 0; i-- {
+		if err := virtualMachineGroupDestroyMember(d, client, members[i]); err != nil {
+			return err
+		}
+		members = members[:i]
+		if err := virtualMachineGroupSaveMembers(d, members); err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceIDString)
+	return nil
+}
+
+// virtualMachineGroupMember describes a virtual machine in a
+// vsphere_virtual_machine_group.
+type virtualMachineGroupMember struct {
+	uuid         string
+	name         string
+	templateUUID string
+	vm           *object.VirtualMachine
+}
+
+// virtualMachineGroupMembersFromState loads the members of the group that
+// are recorded in state. Members that no longer exist are skipped.
+func virtualMachineGroupMembersFromState(d *schema.ResourceData, client *govmomi.Client) ([]*virtualMachineGroupMember, error) {
+	var members []*virtualMachineGroupMember
+	for _, v := range d.Get("virtual_machine_ids").([]interface{}) {
+		id := v.(string)
+		vm, err := virtualmachine.FromUUID(client, id)
+		if err != nil {
+			if _, ok := err.(*virtualmachine.UUIDNotFoundError); ok {
+				log.Printf("[DEBUG] %s: Virtual machine %q is gone, dropping it from the group", resourceVSphereVirtualMachineGroupIDString(d), id)
+				continue
+			}
+			return nil, fmt.Errorf("cannot locate virtual machine with UUID %q: %s", id, err)
+		}
+		props, err := virtualmachine.Properties(vm)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching properties for virtual machine %q: %s", id, err)
+		}
+		m := &virtualMachineGroupMember{
+			uuid: id,
+			name: props.Name,
+			vm:   vm,
+		}
+		for _, opt := range props.Config.ExtraConfig {
+			if opt.GetOptionValue().Key == virtualMachineGroupTemplateKey {
+				m.templateUUID = fmt.Sprint(opt.GetOptionValue().Value)
+			}
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// virtualMachineGroupSaveMembers saves the supplied members to state.
+func virtualMachineGroupSaveMembers(d *schema.ResourceData, members []*virtualMachineGroupMember) error {
+	var ids, names []string
+	for _, m := range members {
+		ids = append(ids, m.uuid)
+		names = append(names, m.name)
+	}
+	if err := d.Set("virtual_machine_ids", ids); err != nil {
+		return fmt.Errorf("error setting virtual_machine_ids: %s", err)
+	}
+	if err := d.Set("names", names); err != nil {
+		return fmt.Errorf("error setting names: %s", err)
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineGroupApply brings the members of the group in
+// line with the configuration. Members that are no longer wanted are
+// removed, members that were cloned from a different template are replaced
+// if replace_on_template_change is set, and missing members are cloned.
+// State is saved after every step so that a failure leaves an accurate record
+// of the group.
+func resourceVSphereVirtualMachineGroupApply(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	members, err := virtualMachineGroupMembersFromState(d, client)
+	if err != nil {
+		return err
+	}
+	size := d.Get("size").(int)
+
+	byName := make(map[string]*virtualMachineGroupMember)
+	for _, m := range members {
+		byName[m.name] = m
+	}
+	desired := make([]*virtualMachineGroupMember, size)
+	for i := range desired {
+		desired[i] = byName[virtualMachineGroupMemberName(d, i)]
+	}
+
+	// Members that are not part of the desired group are tracked in extra until
+	// they are gone, so that they stay in state if anything fails.
+	var extra []*virtualMachineGroupMember
+	for _, m := range members {
+		if virtualMachineGroupMemberIndex(desired, m) < 0 {
+			extra = append(extra, m)
+		}
+	}
+	save := func() error {
+		return virtualMachineGroupSaveMembers(d, append(virtualMachineGroupCompact(desired), extra...))
+	}
+
+	// Scale in first, so that capacity is freed up before anything else
+	// happens.
+	for len(extra) > 0 {
+		if err := virtualMachineGroupDestroyMember(d, client, extra[0]); err != nil {
+			return err
+		}
+		extra = extra[1:]
+		if err := save(); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("replace_on_template_change").(bool) {
+		if err := virtualMachineGroupReplaceStaleMembers(d, client, desired, &extra, save); err != nil {
+			return err
+		}
+	}
+
+	// Clone missing members.
+	for i, m := range desired {
+		if m != nil {
+			continue
+		}
+		nm, err := virtualMachineGroupCloneMember(d, client, i, virtualMachineGroupMemberName(d, i))
+		if nm != nil {
+			desired[i] = nm
+			if serr := save(); serr != nil {
+				return serr
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// virtualMachineGroupReplaceStaleMembers replaces the members of the group
+// that were cloned from a template other than template_uuid, in batches of
+// replace_batch_size members.
+//
+// For each batch, replacements for up to replace_max_surge members are cloned
+// under a temporary name before the old members are removed, and renamed once
+// the old members are gone. The rest of the batch is removed first and cloned
+// afterwards.
+func virtualMachineGroupReplaceStaleMembers(
+	d *schema.ResourceData,
+	client *govmomi.Client,
+	desired []*virtualMachineGroupMember,
+	extra *[]*virtualMachineGroupMember,
+	save func() error,
+) error {
+	templateUUID := d.Get("template_uuid").(string)
+	batchSize := d.Get("replace_batch_size").(int)
+	maxSurge := d.Get("replace_max_surge").(int)
+
+	var stale []int
+	for i, m := range desired {
+		if m != nil && m.templateUUID != templateUUID {
+			stale = append(stale, i)
+		}
+	}
+
+	for len(stale) > 0 {
+		n := batchSize
+		if n > len(stale) {
+			n = len(stale)
+		}
+		batch := stale[:n]
+		stale = stale[n:]
+		log.Printf("[DEBUG] %s: Replacing batch of %d members cloned from an old template", resourceVSphereVirtualMachineGroupIDString(d), len(batch))
+
+		surged := make(map[int]*virtualMachineGroupMember)
+		for _, i := range batch {
+			if len(surged) >= maxSurge {
+				break
+			}
+			nm, err := virtualMachineGroupCloneMember(d, client, i, virtualMachineGroupMemberName(d, i)+virtualMachineGroupSurgeSuffix)
+			if nm != nil {
+				surged[i] = nm
+				*extra = append(*extra, nm)
+				if serr := save(); serr != nil {
+					return serr
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, i := range batch {
+			if err := virtualMachineGroupDestroyMember(d, client, desired[i]); err != nil {
+				return err
+			}
+			desired[i] = nil
+			if err := save(); err != nil {
+				return err
+			}
+		}
+
+		for _, i := range batch {
+			name := virtualMachineGroupMemberName(d, i)
+			if nm, ok := surged[i]; ok {
+				if err := viapi.RenameObject(client, nm.vm.Reference(), name); err != nil {
+					return fmt.Errorf("error renaming %q to %q: %s", nm.name, name, err)
+				}
+				nm.name = name
+				desired[i] = nm
+				*extra = virtualMachineGroupRemoveMember(*extra, nm)
+				if err := save(); err != nil {
+					return err
+				}
+				continue
+			}
+			nm, err := virtualMachineGroupCloneMember(d, client, i, name)
+			if nm != nil {
+				desired[i] = nm
+				if serr := save(); serr != nil {
+					return serr
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// virtualMachineGroupCloneMember clones the member of the group at the
+// supplied index from the template under the supplied name, and powers it on.
+// If the clone succeeds but powering on fails, both the new member and an
+// error are returned.
+func virtualMachineGroupCloneMember(d *schema.ResourceData, client *govmomi.Client, index int, name string) (*virtualMachineGroupMember, error) {
+	templateUUID := d.Get("template_uuid").(string)
+	log.Printf("[DEBUG] %s: Cloning %q from template %q", resourceVSphereVirtualMachineGroupIDString(d), name, templateUUID)
+
+	src, err := virtualmachine.FromUUID(client, templateUUID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate virtual machine or template with UUID %q: %s", templateUUID, err)
+	}
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	fo, err := folder.VirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+	if err != nil {
+		return nil, err
+	}
+	spec, err := expandVirtualMachineGroupCloneSpec(d, client, pool, index)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := virtualmachine.Clone(client, src, fo, name, spec, d.Get("clone_timeout").(int))
+	if err != nil {
+		return nil, viapi.NewDiagnostic(err, "error cloning %q", name)
+	}
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch properties of %q: %s", name, err)
+	}
+	m := &virtualMachineGroupMember{
+		uuid:         props.Config.Uuid,
+		name:         name,
+		templateUUID: templateUUID,
+		vm:           vm,
+	}
+	if err := virtualmachine.PowerOn(vm); err != nil {
+		return m, viapi.NewDiagnostic(err, "error powering on %q", name)
+	}
+	return m, nil
+}
+
+// virtualMachineGroupDestroyMember shuts down and destroys a member of the
+// group.
+func virtualMachineGroupDestroyMember(d *schema.ResourceData, client *govmomi.Client, m *virtualMachineGroupMember) error {
+	log.Printf("[DEBUG] %s: Removing %q", resourceVSphereVirtualMachineGroupIDString(d), m.name)
+	props, err := virtualmachine.Properties(m.vm)
+	if err != nil {
+		return fmt.Errorf("error fetching properties for %q: %s", m.name, err)
+	}
+	if props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		if err := virtualmachine.GracefulPowerOff(client, m.vm, d.Get("shutdown_wait_timeout").(int), true); err != nil {
+			return viapi.NewDiagnostic(err, "error shutting down %q", m.name)
+		}
+	}
+	if err := virtualmachine.Destroy(m.vm); err != nil {
+		return viapi.NewDiagnostic(err, "error destroying %q", m.name)
+	}
+	return nil
+}
+
+// expandVirtualMachineGroupCloneSpec returns the clone spec for the member of
+// the group at the supplied index. Hosts and datastores are picked from
+// host_system_ids and datastore_ids, or the members of datastore_cluster_id,
+// in a round-robin fashion.
+func expandVirtualMachineGroupCloneSpec(d *schema.ResourceData, client *govmomi.Client, pool *object.ResourcePool, index int) (types.VirtualMachineCloneSpec, error) {
+	poolRef := pool.Reference()
+	spec := types.VirtualMachineCloneSpec{
+		Location: types.VirtualMachineRelocateSpec{
+			Pool: &poolRef,
+		},
+		Config: &types.VirtualMachineConfigSpec{
+			ExtraConfig: []types.BaseOptionValue{
+				&types.OptionValue{
+					Key:   virtualMachineGroupTemplateKey,
+					Value: d.Get("template_uuid").(string),
+				},
+			},
+		},
+	}
+
+	if hosts := structure.SliceInterfacesToStrings(d.Get("host_system_ids").([]interface{})); len(hosts) > 0 {
+		spec.Location.Host = &types.ManagedObjectReference{
+			Type:  "HostSystem",
+			Value: hosts[index%len(hosts)],
+		}
+	}
+
+	var datastores []types.ManagedObjectReference
+	for _, id := range structure.SliceInterfacesToStrings(d.Get("datastore_ids").([]interface{})) {
+		datastores = append(datastores, types.ManagedObjectReference{Type: "Datastore", Value: id})
+	}
+	if podID, ok := d.GetOk("datastore_cluster_id"); ok {
+		pod, err := storagepod.FromID(client, podID.(string))
+		if err != nil {
+			return spec, fmt.Errorf("cannot locate datastore cluster: %s", err)
+		}
+		if datastores, err = storagepod.Datastores(pod); err != nil {
+			return spec, fmt.Errorf("error fetching datastores in datastore cluster: %s", err)
+		}
+		if len(datastores) < 1 {
+			return spec, fmt.Errorf("datastore cluster %q has no datastores", pod.InventoryPath)
+		}
+	}
+	if len(datastores) > 0 {
+		spec.Location.Datastore = &datastores[index%len(datastores)]
+	}
+	return spec, nil
+}
+
+// virtualMachineGroupMemberName returns the name of the member of the group
+// at the supplied 0-based index.
+func virtualMachineGroupMemberName(d *schema.ResourceData, index int) string {
+	return fmt.Sprintf(d.Get("name_format").(string), index+1)
+}
+
+// virtualMachineGroupMemberIndex returns the index of a member in the
+// supplied list, or -1 if the member is not in the list.
+func virtualMachineGroupMemberIndex(members []*virtualMachineGroupMember, m *virtualMachineGroupMember) int {
+	for i, v := range members {
+		if v == m {
+			return i
+		}
+	}
+	return -1
+}
+
+// virtualMachineGroupRemoveMember returns the supplied list of members
+// without the supplied member.
+func virtualMachineGroupRemoveMember(members []*virtualMachineGroupMember, m *virtualMachineGroupMember) []*virtualMachineGroupMember {
+	var result []*virtualMachineGroupMember
+	for _, v := range members {
+		if v != m {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// virtualMachineGroupCompact returns the supplied list of members without any
+// empty slots.
+func virtualMachineGroupCompact(members []*virtualMachineGroupMember) []*virtualMachineGroupMember {
+	var result []*virtualMachineGroupMember
+	for _, m := range members {
+		if m != nil {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// validateVirtualMachineGroupNameFormat checks that name_format takes exactly
+// one integer argument.
+func validateVirtualMachineGroupNameFormat(v interface{}, k string) ([]string, []error) {
+	if strings.Contains(fmt.Sprintf(v.(string), 1), "%!") {
+		return nil, []error{fmt.Errorf("%q must contain exactly one integer verb, such as %%d or %%02d: %q", k, v.(string))}
+	}
+	return nil, nil
+}
+
+// resourceVSphereVirtualMachineGroupIDString prints a friendly string for the
+// vsphere_virtual_machine_group resource.
+func resourceVSphereVirtualMachineGroupIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereVirtualMachineGroupName)
+}
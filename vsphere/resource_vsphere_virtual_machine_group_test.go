@@ -0,0 +1,160 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+)
+
+func TestAccResourceVSphereVirtualMachineGroup_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineGroupPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineGroupCheckMembersExist(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineGroupConfig(2),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineGroupCheckMembersExist(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_group.group", "names.#", "2"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_group.group", "names.0", "terraform-test-group-01"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_group.group", "names.1", "terraform-test-group-02"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineGroupConfig(3),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineGroupCheckMembersExist(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_group.group", "names.#", "3"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_group.group", "names.2", "terraform-test-group-03"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineGroupConfig(1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineGroupCheckMembersExist(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_group.group", "names.#", "1"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_group.group", "names.0", "terraform-test-group-01"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVirtualMachineGroupPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE2") == "" {
+		t.Skip("set VSPHERE_DATASTORE2 to run vsphere_virtual_machine_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine_group acceptance tests")
+	}
+}
+
+// testAccResourceVSphereVirtualMachineGroupCheckMembersExist checks that all
+// of the virtual machines in the group exist, or that none of them do.
+func testAccResourceVSphereVirtualMachineGroupCheckMembersExist(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_virtual_machine_group.group"]
+		if !ok {
+			if expected {
+				return errors.New("vsphere_virtual_machine_group.group not found in state")
+			}
+			return nil
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		for k, v := range rs.Primary.Attributes {
+			var i int
+			if _, err := fmt.Sscanf(k, "virtual_machine_ids.%d", &i); err != nil {
+				continue
+			}
+			_, err := virtualmachine.FromUUID(client, v)
+			switch {
+			case err == nil && !expected:
+				return fmt.Errorf("expected virtual machine %q to be missing", v)
+			case err != nil && expected:
+				return err
+			case err != nil:
+				if _, ok := err.(*virtualmachine.UUIDNotFoundError); !ok {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineGroupConfig(size int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "datastores" {
+  default = ["%s", "%s"]
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "size" {
+  default = "%d"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastores" {
+  count         = "${length(var.datastores)}"
+  name          = "${var.datastores[count.index]}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine_group" "group" {
+  name_format      = "terraform-test-group-%%02d"
+  size             = "${var.size}"
+  template_uuid    = "${data.vsphere_virtual_machine.template.id}"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_ids    = ["${data.vsphere_datastore.datastores.*.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_DATASTORE2"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		size,
+	)
+}
@@ -200,15 +200,18 @@ func migrateVSphereVirtualMachineStateV2(is *terraform.InstanceState, meta inter
 	}
 
 	// The VM should be ready for reading now
+	oldAttrs := is.Attributes
 	is.Attributes = make(map[string]string)
 	is.ID = id
 	is.Attributes["imported"] = "true"
+	migrateVSphereVirtualMachineLegacyExtraConfig(oldAttrs, is.Attributes)
 
 	// Set some defaults. This helps possibly prevent diffs where these values
 	// have not been changed.
 	is.Attributes["scsi_controller_count"] = fmt.Sprintf("%v", rs["scsi_controller_count"].Default)
 	is.Attributes["force_power_off"] = fmt.Sprintf("%v", rs["force_power_off"].Default)
 	is.Attributes["migrate_wait_timeout"] = fmt.Sprintf("%v", rs["migrate_wait_timeout"].Default)
+	is.Attributes["create_wait_timeout"] = fmt.Sprintf("%v", rs["create_wait_timeout"].Default)
 	is.Attributes["shutdown_wait_timeout"] = fmt.Sprintf("%v", rs["shutdown_wait_timeout"].Default)
 	is.Attributes["wait_for_guest_net_timeout"] = guestNetTimeout
 	is.Attributes["scsi_controller_count"] = fmt.Sprintf("%v", maxBus+1)
@@ -225,6 +228,29 @@ func migrateVSphereVirtualMachineStateV2(is *terraform.InstanceState, meta inter
 	return nil
 }
 
+// migrateVSphereVirtualMachineLegacyExtraConfig copies the keys in the legacy
+// custom_configuration_parameters attribute to extra_config. Only the keys in
+// extra_config that are already in state are refreshed from the virtual
+// machine, so without this, the next plan would show all of these keys as new
+// and reconfigure the virtual machine.
+func migrateVSphereVirtualMachineLegacyExtraConfig(old, new map[string]string) {
+	var n int
+	for k, v := range old {
+		if !strings.HasPrefix(k, "custom_configuration_parameters.") {
+			continue
+		}
+		key := strings.TrimPrefix(k, "custom_configuration_parameters.")
+		if key == "%" || key == "#" {
+			continue
+		}
+		new["extra_config."+key] = v
+		n++
+	}
+	if n > 0 {
+		new["extra_config.%"] = strconv.Itoa(n)
+	}
+}
+
 func migrateVSphereVirtualMachineStateV1(is *terraform.InstanceState, meta interface{}) error {
 	if is.Empty() || is.Attributes == nil {
 		log.Println("[DEBUG] Empty VSphere Virtual Machine State; nothing to migrate.")
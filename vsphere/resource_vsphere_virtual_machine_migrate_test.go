@@ -3,6 +3,7 @@ package vsphere
 import (
 	"os"
 	"path"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -76,6 +77,51 @@ func TestVSphereVirtualMachineMigrateStateV1(t *testing.T) {
 	}
 }
 
+func TestVSphereVirtualMachineMigrateLegacyExtraConfig(t *testing.T) {
+	cases := map[string]struct {
+		Attributes map[string]string
+		Expected   map[string]string
+	}{
+		"no custom_configuration_parameters": {
+			Attributes: map[string]string{
+				"name": "foo",
+			},
+			Expected: map[string]string{},
+		},
+		"custom_configuration_parameters": {
+			Attributes: map[string]string{
+				"name":                                "foo",
+				"custom_configuration_parameters.%":   "2",
+				"custom_configuration_parameters.foo": "bar",
+				"custom_configuration_parameters.baz": "qux",
+			},
+			Expected: map[string]string{
+				"extra_config.%":   "2",
+				"extra_config.foo": "bar",
+				"extra_config.baz": "qux",
+			},
+		},
+		"legacy map count": {
+			Attributes: map[string]string{
+				"custom_configuration_parameters.#":   "1",
+				"custom_configuration_parameters.foo": "bar",
+			},
+			Expected: map[string]string{
+				"extra_config.%":   "1",
+				"extra_config.foo": "bar",
+			},
+		},
+	}
+
+	for tn, tc := range cases {
+		actual := make(map[string]string)
+		migrateVSphereVirtualMachineLegacyExtraConfig(tc.Attributes, actual)
+		if !reflect.DeepEqual(tc.Expected, actual) {
+			t.Fatalf("bad: %s\n\n expected: %#v\n got: %#v", tn, tc.Expected, actual)
+		}
+	}
+}
+
 func TestAccResourceVSphereVirtualMachineMigrateStateV3_fromV2(t *testing.T) {
 	testAccResourceVSphereVirtualMachineMigrateStatePreCheck(t)
 	testAccPreCheck(t)
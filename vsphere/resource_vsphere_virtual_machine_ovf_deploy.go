@@ -0,0 +1,139 @@
+package vsphere
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/network"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/ovf"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourceVSphereVirtualMachineCreateFromOvf contains the deploy path for
+// virtual machines that are imported from an OVF descriptor or OVA archive
+// through ovf_deploy. The virtual machine is imported powered off, and then
+// goes through the same post-clone operations as a clone, which applies the
+// hardware and device configuration. The VM is returned.
+func resourceVSphereVirtualMachineCreateFromOvf(d *schema.ResourceData, meta interface{}) (*object.VirtualMachine, error) {
+	log.Printf("[DEBUG] %s: VM being deployed from OVF", resourceVSphereVirtualMachineIDString(d))
+	client := meta.(*VSphereClient).vimClient
+
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	fo, err := resourceVSphereVirtualMachineFolder(d, client, pool)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := expandVirtualMachineOvfImportSpec(d, client)
+	if err != nil {
+		return nil, err
+	}
+	spec.Pool = pool
+	spec.Folder = fo
+
+	src := expandVirtualMachineOvfSource(d)
+	timeout := time.Duration(d.Get("ovf_deploy.0.timeout").(int)) * time.Minute
+	ref, err := ovf.Import(client, src, spec, timeout)
+	if err != nil {
+		return nil, viapi.NewDiagnostic(err, "error deploying virtual machine from OVF %q", src.Location)
+	}
+
+	vm, err := virtualmachine.FromMOID(client, ref.Value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate deployed virtual machine %q: %s", ref.Value, err)
+	}
+
+	vprops, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch properties of created virtual machine: %s", err)
+	}
+	log.Printf("[DEBUG] VM %q - UUID is %q", vm.InventoryPath, vprops.Config.Uuid)
+	d.SetId(vprops.Config.Uuid)
+
+	if err := resourceVSphereVirtualMachinePostClone(d, meta, vm, vprops, pool); err != nil {
+		return nil, resourceVSphereVirtualMachinePostCloneFailed(d, meta, vm, err)
+	}
+	return vm, nil
+}
+
+// expandVirtualMachineOvfSource returns the OVF source of ovf_deploy.
+func expandVirtualMachineOvfSource(d *schema.ResourceData) *ovf.Source {
+	if path := d.Get("ovf_deploy.0.local_ovf_path").(string); path != "" {
+		return &ovf.Source{Location: path}
+	}
+	src := &ovf.Source{
+		Location: d.Get("ovf_deploy.0.remote_ovf_url").(string),
+		Remote:   true,
+	}
+	if d.Get("ovf_deploy.0.allow_unverified_ssl_cert").(bool) {
+		src.Client = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+	}
+	return src
+}
+
+// expandVirtualMachineOvfImportSpec returns the import spec of ovf_deploy,
+// without the resource pool and folder. The values of the OVF properties are
+// taken from vapp.0.properties, so that they are set before the virtual
+// machine is first powered on.
+func expandVirtualMachineOvfImportSpec(d *schema.ResourceData, client *govmomi.Client) (ovf.ImportSpec, error) {
+	spec := ovf.ImportSpec{
+		Name:               d.Get("name").(string),
+		DiskProvisioning:   d.Get("ovf_deploy.0.disk_provisioning").(string),
+		DeploymentOption:   d.Get("ovf_deploy.0.deployment_option").(string),
+		IPAllocationPolicy: d.Get("ovf_deploy.0.ip_allocation_policy").(string),
+		IPProtocol:         d.Get("ovf_deploy.0.ip_protocol").(string),
+		NetworkMapping:     make(map[string]types.ManagedObjectReference),
+		Properties:         make(map[string]string),
+	}
+	// The configuration files go to vmx_datastore_id if it is set, like they do
+	// for clones. The disks are moved to their own datastores in the post-clone
+	// operations.
+	dsID := d.Get("vmx_datastore_id").(string)
+	if dsID == "" {
+		dsID = d.Get("datastore_id").(string)
+	}
+	ds, err := datastore.FromID(client, dsID)
+	if err != nil {
+		return spec, fmt.Errorf("error locating datastore for VM: %s", err)
+	}
+	spec.Datastore = ds
+	if hsID := d.Get("host_system_id").(string); hsID != "" {
+		hs, err := hostsystem.FromID(client, hsID)
+		if err != nil {
+			return spec, fmt.Errorf("error locating host system at ID %q: %s", hsID, err)
+		}
+		spec.Host = hs
+	}
+	for name, v := range d.Get("ovf_deploy.0.ovf_network_map").(map[string]interface{}) {
+		net, err := network.FromID(client, v.(string))
+		if err != nil {
+			return spec, fmt.Errorf("could not find network ID %q for OVF network %q: %s", v.(string), name, err)
+		}
+		spec.NetworkMapping[name] = net.Reference()
+	}
+	if props, ok := d.Get("vapp.0.properties").(map[string]interface{}); ok {
+		for k, v := range props {
+			spec.Properties[k] = v.(string)
+		}
+	}
+	return spec, nil
+}
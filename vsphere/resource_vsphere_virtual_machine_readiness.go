@@ -0,0 +1,153 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// schemaVirtualMachineReadinessProbe returns the schema for the
+// readiness_probe block of the virtual machine resource.
+func schemaVirtualMachineReadinessProbe() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "A check that must succeed before the virtual machine is considered ready, after it has been created or powered on again.",
+		Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+			"command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The absolute path of a program to run in the guest through VMware Tools. The check succeeds when the program exits with code 0.",
+			},
+			"arguments": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The arguments to pass to command.",
+			},
+			"guest_username": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The guest user to run command as.",
+			},
+			"guest_password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The password of guest_username.",
+			},
+			"tcp_port": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "A TCP port on the primary IP address of the guest. The check succeeds when Terraform can connect to the port.",
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+			"interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				Description:  "The time, in seconds, between two attempts of the check.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				Description:  "The time, in minutes, to wait for the check to succeed.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		}},
+	}
+}
+
+// resourceVSphereVirtualMachineValidateReadinessProbe checks that exactly one
+// kind of check is set in readiness_probe, and that command is accompanied by
+// guest credentials.
+func resourceVSphereVirtualMachineValidateReadinessProbe(d *schema.ResourceDiff) error {
+	if len(d.Get("readiness_probe").([]interface{})) < 1 {
+		return nil
+	}
+	command := d.Get("readiness_probe.0.command").(string)
+	port := d.Get("readiness_probe.0.tcp_port").(int)
+	switch {
+	case command == "" && port == 0:
+		return errors.New("readiness_probe: one of command or tcp_port must be set")
+	case command != "" && port != 0:
+		return errors.New("readiness_probe: only one of command or tcp_port can be set")
+	case command != "" && d.Get("readiness_probe.0.guest_username").(string) == "":
+		return errors.New("readiness_probe: guest_username is required with command")
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineWaitForReadiness runs the check in
+// readiness_probe until it succeeds or times out. It does nothing if no
+// readiness_probe is set.
+func resourceVSphereVirtualMachineWaitForReadiness(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine) error {
+	if len(d.Get("readiness_probe").([]interface{})) < 1 {
+		return nil
+	}
+	interval := time.Duration(d.Get("readiness_probe.0.interval").(int)) * time.Second
+	timeout := time.Duration(d.Get("readiness_probe.0.timeout").(int)) * time.Minute
+	deadline := time.Now().Add(timeout)
+	log.Printf("[DEBUG] %s: Waiting for readiness probe to succeed (timeout = %s)", resourceVSphereVirtualMachineIDString(d), timeout)
+	for {
+		err := resourceVSphereVirtualMachineRunReadinessProbe(d, client, vm, deadline)
+		if err == nil {
+			log.Printf("[DEBUG] %s: Readiness probe succeeded", resourceVSphereVirtualMachineIDString(d))
+			return nil
+		}
+		log.Printf("[DEBUG] %s: Readiness probe failed: %s", resourceVSphereVirtualMachineIDString(d), err)
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("timeout waiting for readiness probe to succeed, last error: %s", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// resourceVSphereVirtualMachineRunReadinessProbe runs the check in
+// readiness_probe once.
+func resourceVSphereVirtualMachineRunReadinessProbe(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine, deadline time.Time) error {
+	if command := d.Get("readiness_probe.0.command").(string); command != "" {
+		auth := &types.NamePasswordAuthentication{
+			Username: d.Get("readiness_probe.0.guest_username").(string),
+			Password: d.Get("readiness_probe.0.guest_password").(string),
+		}
+		spec := types.GuestProgramSpec{
+			ProgramPath: command,
+			Arguments:   d.Get("readiness_probe.0.arguments").(string),
+		}
+		code, err := virtualmachine.RunProgramInGuest(client, vm, auth, spec, time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		if code != 0 {
+			return fmt.Errorf("%s exited with code %d", command, code)
+		}
+		return nil
+	}
+
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return err
+	}
+	if props.Guest == nil || props.Guest.IpAddress == "" {
+		return errors.New("the guest has not reported an IP address yet")
+	}
+	addr := net.JoinHostPort(props.Guest.IpAddress, strconv.Itoa(d.Get("readiness_probe.0.tcp_port").(int)))
+	conn, err := net.DialTimeout("tcp", addr, time.Second*10)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,204 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/hostsystem"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/object"
+)
+
+const resourceVSphereVirtualMachineRegistrationName = "vsphere_virtual_machine_registration"
+
+func resourceVSphereVirtualMachineRegistration() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVirtualMachineRegistrationCreate,
+		Read:   resourceVSphereVirtualMachineRegistrationRead,
+		Delete: resourceVSphereVirtualMachineRegistrationDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"datastore_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the datastore that has the configuration file of the virtual machine.",
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The path of the configuration file of the virtual machine in the datastore, such as vm/vm.vmx.",
+			},
+			"resource_pool_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the resource pool to register the virtual machine in.",
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The managed object ID of the host to register the virtual machine on. Required if the resource pool is not in a cluster.",
+			},
+			"folder": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The path of the folder to register the virtual machine in, relative to the virtual machine root folder of the datacenter.",
+				StateFunc:   folder.NormalizePath,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The name of the virtual machine. Defaults to the display name in the configuration file.",
+			},
+			"template": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Register the configuration file as a template.",
+			},
+			"uuid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The UUID of the virtual machine.",
+			},
+		},
+	}
+}
+
+func resourceVSphereVirtualMachineRegistrationCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning create", resourceVSphereVirtualMachineRegistrationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	ds, err := datastore.FromID(client, d.Get("datastore_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+	pool, err := resourcepool.FromID(client, d.Get("resource_pool_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+	var host *object.HostSystem
+	if hsID := d.Get("host_system_id").(string); hsID != "" {
+		if host, err = hostsystem.FromID(client, hsID); err != nil {
+			return fmt.Errorf("cannot locate host: %s", err)
+		}
+	}
+	fo, err := folder.VirtualMachineFolderFromObject(client, pool, d.Get("folder").(string))
+	if err != nil {
+		return err
+	}
+	dp := object.DatastorePath{
+		Datastore: ds.Name(),
+		Path:      d.Get("path").(string),
+	}
+	vm, err := virtualmachine.Register(client, fo, dp.String(), d.Get("name").(string), d.Get("template").(bool), pool, host)
+	if err != nil {
+		return viapi.NewDiagnostic(err, "error registering virtual machine %q", dp.String())
+	}
+	// Virtual machines registered from copied files can share their UUID with
+	// the original, so they are tracked by their managed object ID.
+	d.SetId(vm.Reference().Value)
+
+	log.Printf("[DEBUG] %s: Create finished successfully", resourceVSphereVirtualMachineRegistrationIDString(d))
+	return resourceVSphereVirtualMachineRegistrationRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineRegistrationRead(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] %s: Beginning read", resourceVSphereVirtualMachineRegistrationIDString(d))
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualmachine.FromMOID(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			log.Printf("[DEBUG] %s: Virtual machine not found, removing from state", resourceVSphereVirtualMachineRegistrationIDString(d))
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error locating virtual machine: %s", err)
+	}
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+
+	d.Set("name", props.Name)
+	if props.ResourcePool != nil {
+		d.Set("resource_pool_id", props.ResourcePool.Value)
+	}
+	if props.Runtime.Host != nil {
+		d.Set("host_system_id", props.Runtime.Host.Value)
+	}
+	f, err := folder.RootPathParticleVM.SplitRelativeFolder(vm.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing virtual machine path %q: %s", vm.InventoryPath, err)
+	}
+	d.Set("folder", folder.NormalizePath(f))
+	if props.Config == nil {
+		// The configuration is not available for orphaned or inaccessible
+		// virtual machines.
+		log.Printf("[WARN] %s: Virtual machine is %s, keeping its last known state", resourceVSphereVirtualMachineRegistrationIDString(d), props.Runtime.ConnectionState)
+		return nil
+	}
+	d.Set("uuid", props.Config.Uuid)
+	d.Set("template", props.Config.Template)
+
+	// The datastore and path are read back from the location of the
+	// configuration file, for imports.
+	var dp object.DatastorePath
+	if !dp.FromString(props.Config.Files.VmPathName) {
+		return fmt.Errorf("could not parse VMX file path: %s", props.Config.Files.VmPathName)
+	}
+	for _, ref := range props.Datastore {
+		ds, err := datastore.FromID(client, ref.Value)
+		if err != nil {
+			return fmt.Errorf("error locating datastore %q: %s", ref.Value, err)
+		}
+		if ds.Name() == dp.Datastore {
+			d.Set("datastore_id", ref.Value)
+			d.Set("path", dp.Path)
+		}
+	}
+
+	log.Printf("[DEBUG] %s: Read completed successfully", resourceVSphereVirtualMachineRegistrationIDString(d))
+	return nil
+}
+
+func resourceVSphereVirtualMachineRegistrationDelete(d *schema.ResourceData, meta interface{}) error {
+	resourceIDString := resourceVSphereVirtualMachineRegistrationIDString(d)
+	log.Printf("[DEBUG] %s: Beginning delete", resourceIDString)
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualmachine.FromMOID(client, d.Id())
+	if err != nil {
+		if viapi.IsManagedObjectNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("error locating virtual machine: %s", err)
+	}
+	// Only the registration is removed. The files of the virtual machine are
+	// left on the datastore.
+	if err := virtualmachine.Unregister(vm); err != nil {
+		return viapi.NewDiagnostic(err, "error unregistering virtual machine")
+	}
+	log.Printf("[DEBUG] %s: Deleted successfully", resourceIDString)
+	return nil
+}
+
+// resourceVSphereVirtualMachineRegistrationIDString prints a friendly string
+// for the vsphere_virtual_machine_registration resource.
+func resourceVSphereVirtualMachineRegistrationIDString(d structure.ResourceIDStringer) string {
+	return structure.ResourceIDString(d, resourceVSphereVirtualMachineRegistrationName)
+}
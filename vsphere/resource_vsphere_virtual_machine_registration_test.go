@@ -0,0 +1,123 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+)
+
+func TestAccResourceVSphereVirtualMachineRegistration_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineRegistrationPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineRegistrationExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineRegistrationConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineRegistrationExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_registration.vm", "name", "terraform-test-registration"),
+					resource.TestCheckResourceAttrSet("vsphere_virtual_machine_registration.vm", "uuid"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_virtual_machine_registration.vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVirtualMachineRegistrationPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine_registration acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine_registration acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine_registration acceptance tests")
+	}
+	if os.Getenv("VSPHERE_VMX_PATH") == "" {
+		t.Skip("set VSPHERE_VMX_PATH to the path of an unregistered VMX file in VSPHERE_DATASTORE to run vsphere_virtual_machine_registration acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineRegistrationExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_virtual_machine_registration.vm"]
+		if !ok {
+			if expected {
+				return fmt.Errorf("vsphere_virtual_machine_registration.vm not found in state")
+			}
+			return nil
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		_, err := virtualmachine.FromMOID(client, rs.Primary.ID)
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected virtual machine %q to be unregistered", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineRegistrationConfig() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "vmx_path" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine_registration" "vm" {
+  name             = "terraform-test-registration"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+  path             = "${var.vmx_path}"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_VMX_PATH"),
+	)
+}
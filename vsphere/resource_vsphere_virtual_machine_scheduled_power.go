@@ -0,0 +1,307 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/scheduledtask"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualMachineScheduledPowerMethods maps the allowed values of the action
+// attribute of scheduled_power to the methods that the scheduled tasks run
+// on the virtual machine.
+var virtualMachineScheduledPowerMethods = map[string]string{
+	"power_on":       "PowerOnVM_Task",
+	"power_off":      "PowerOffVM_Task",
+	"shutdown_guest": "ShutdownGuest",
+}
+
+// virtualMachineScheduledPowerDays are the names of the days of the week
+// accepted in the day of week field of a schedule, in the order of
+// time.Weekday.
+var virtualMachineScheduledPowerDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// schemaVirtualMachineScheduledPower returns the schema for the
+// scheduled_power block of the virtual machine resource.
+func schemaVirtualMachineScheduledPower() *schema.Schema {
+	var actions []string
+	for k := range virtualMachineScheduledPowerMethods {
+		actions = append(actions, k)
+	}
+	sort.Strings(actions)
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Power operations that vCenter runs on the virtual machine on a schedule, through scheduled tasks.",
+		Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+			"action": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "The power operation to run. One of power_on, power_off, or shutdown_guest.",
+				ValidateFunc: validation.StringInSlice(actions, false),
+			},
+			"schedule": {
+				Type:         schema.TypeString,
+				Required:     true,
+				Description:  "When to run the power operation, as a cron expression in UTC in the form MINUTE HOUR * * DAYS, where DAYS is * or a list of days of the week.",
+				ValidateFunc: validateVirtualMachineScheduledPowerSchedule,
+			},
+			"task_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The managed object ID of the scheduled task.",
+			},
+		}},
+	}
+}
+
+// resourceVSphereVirtualMachineApplyScheduledPower replaces the scheduled
+// tasks of the previous scheduled_power configuration of the virtual machine
+// with tasks for the current one. Nothing is done if scheduled_power has not
+// changed.
+func resourceVSphereVirtualMachineApplyScheduledPower(d *schema.ResourceData, client *govmomi.Client, vm *object.VirtualMachine) error {
+	if !d.HasChange("scheduled_power") {
+		return nil
+	}
+	if err := viapi.ValidateVirtualCenter(client); err != nil {
+		return fmt.Errorf("scheduled_power requires vCenter: %s", err)
+	}
+	old, _ := d.GetChange("scheduled_power")
+	if err := removeVirtualMachineScheduledPowerTasks(client, old.([]interface{})); err != nil {
+		return err
+	}
+	var result []interface{}
+	for i, v := range d.Get("scheduled_power").([]interface{}) {
+		m := v.(map[string]interface{})
+		spec, err := expandVirtualMachineScheduledPowerSpec(vm, i, m["action"].(string), m["schedule"].(string))
+		if err != nil {
+			return err
+		}
+		id, err := scheduledtask.Create(client, vm.Reference(), spec)
+		if err != nil {
+			// Keep track of the tasks that were created, so they are removed on
+			// the next apply.
+			d.Set("scheduled_power", result)
+			return viapi.NewDiagnostic(err, "error creating scheduled task %q", spec.Name)
+		}
+		result = append(result, map[string]interface{}{
+			"action":   m["action"],
+			"schedule": m["schedule"],
+			"task_id":  id,
+		})
+	}
+	return d.Set("scheduled_power", result)
+}
+
+// resourceVSphereVirtualMachineReadScheduledPower reads the scheduled tasks
+// of scheduled_power. Tasks that were removed out of band are dropped, so
+// that they are created again on the next apply.
+func resourceVSphereVirtualMachineReadScheduledPower(d *schema.ResourceData, client *govmomi.Client) error {
+	var result []interface{}
+	for _, v := range d.Get("scheduled_power").([]interface{}) {
+		m := v.(map[string]interface{})
+		id := m["task_id"].(string)
+		if id == "" {
+			continue
+		}
+		props, err := scheduledtask.Properties(client, id)
+		if err != nil {
+			if viapi.IsManagedObjectNotFoundError(err) {
+				log.Printf("[DEBUG] %s: Scheduled task %q is missing", resourceVSphereVirtualMachineIDString(d), id)
+				continue
+			}
+			return fmt.Errorf("error fetching scheduled task %q: %s", id, err)
+		}
+		action, schedule, err := flattenVirtualMachineScheduledPowerTask(props.Info, m["schedule"].(string))
+		if err != nil {
+			return fmt.Errorf("error reading scheduled task %q: %s", id, err)
+		}
+		result = append(result, map[string]interface{}{
+			"action":   action,
+			"schedule": schedule,
+			"task_id":  id,
+		})
+	}
+	return d.Set("scheduled_power", result)
+}
+
+// resourceVSphereVirtualMachineRemoveScheduledPower removes the scheduled
+// tasks of scheduled_power, before the virtual machine is destroyed.
+func resourceVSphereVirtualMachineRemoveScheduledPower(d *schema.ResourceData, client *govmomi.Client) error {
+	return removeVirtualMachineScheduledPowerTasks(client, d.Get("scheduled_power").([]interface{}))
+}
+
+// removeVirtualMachineScheduledPowerTasks removes the scheduled tasks of the
+// supplied scheduled_power elements. Tasks that are already gone are
+// skipped.
+func removeVirtualMachineScheduledPowerTasks(client *govmomi.Client, elems []interface{}) error {
+	for _, v := range elems {
+		id := v.(map[string]interface{})["task_id"].(string)
+		if id == "" {
+			continue
+		}
+		if err := scheduledtask.Remove(client, id); err != nil && !viapi.IsManagedObjectNotFoundError(err) {
+			return fmt.Errorf("error removing scheduled task %q: %s", id, err)
+		}
+	}
+	return nil
+}
+
+// expandVirtualMachineScheduledPowerSpec returns the ScheduledTaskSpec for the
+// scheduled_power element at index i. Scheduled task names need to be
+// unique, so they contain the managed object ID of the virtual machine.
+func expandVirtualMachineScheduledPowerSpec(vm *object.VirtualMachine, i int, action, schedule string) (types.ScheduledTaskSpec, error) {
+	hour, minute, days, err := parseVirtualMachineScheduledPowerSchedule(schedule)
+	if err != nil {
+		return types.ScheduledTaskSpec{}, err
+	}
+	return types.ScheduledTaskSpec{
+		Name:        fmt.Sprintf("%s: %s #%d", vm.Reference().Value, action, i+1),
+		Description: fmt.Sprintf("Scheduled power operation of %s, managed by Terraform.", vm.Name()),
+		Enabled:     true,
+		Scheduler:   scheduledtask.DailyScheduler(hour, minute, days),
+		Action:      &types.MethodAction{Name: virtualMachineScheduledPowerMethods[action]},
+	}, nil
+}
+
+// flattenVirtualMachineScheduledPowerTask returns the action and schedule of
+// a scheduled task created by expandVirtualMachineScheduledPowerSpec. The
+// schedule in state is kept if it is equivalent to the one of the task, so
+// that schedules written differently, like with day ranges, do not show a
+// diff.
+func flattenVirtualMachineScheduledPowerTask(info types.ScheduledTaskInfo, current string) (string, string, error) {
+	action, ok := info.Action.(*types.MethodAction)
+	if !ok {
+		return "", "", fmt.Errorf("scheduled task does not run a method")
+	}
+	var name string
+	for k, v := range virtualMachineScheduledPowerMethods {
+		if v == action.Name {
+			name = k
+		}
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("scheduled task runs unsupported method %s", action.Name)
+	}
+	hour, minute, days, err := scheduledtask.DailySchedulerTime(info.Scheduler)
+	if err != nil {
+		return "", "", err
+	}
+	schedule := formatVirtualMachineScheduledPowerSchedule(hour, minute, days)
+	if h, m, d, err := parseVirtualMachineScheduledPowerSchedule(current); err == nil && formatVirtualMachineScheduledPowerSchedule(h, m, d) == schedule {
+		schedule = current
+	}
+	return name, schedule, nil
+}
+
+// validateVirtualMachineScheduledPowerSchedule validates a scheduled_power
+// schedule.
+func validateVirtualMachineScheduledPowerSchedule(v interface{}, k string) ([]string, []error) {
+	if _, _, _, err := parseVirtualMachineScheduledPowerSchedule(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// parseVirtualMachineScheduledPowerSchedule parses a cron expression in the
+// form MINUTE HOUR * * DAYS into its hour, minute, and days of the week. DAYS
+// is * or a comma-separated list of days and ranges of days, as numbers from
+// 0 (or 7) for Sunday to 6 for Saturday, or as names like mon. No days are
+// returned for schedules that run every day. Cron expressions that vCenter
+// schedulers cannot represent, like ones with steps or days of the month,
+// are not supported.
+func parseVirtualMachineScheduledPowerSchedule(s string) (int32, int32, []time.Weekday, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return 0, 0, nil, fmt.Errorf("%q is not a cron expression in the form MINUTE HOUR * * DAYS", s)
+	}
+	minute, err := strconv.Atoi(fields[0])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, 0, nil, fmt.Errorf("%q: minute must be a number from 0 to 59", s)
+	}
+	hour, err := strconv.Atoi(fields[1])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, nil, fmt.Errorf("%q: hour must be a number from 0 to 23", s)
+	}
+	if fields[2] != "*" || fields[3] != "*" {
+		return 0, 0, nil, fmt.Errorf("%q: day of month and month must be *", s)
+	}
+	if fields[4] == "*" {
+		return int32(hour), int32(minute), nil, nil
+	}
+
+	set := make(map[time.Weekday]bool)
+	for _, part := range strings.Split(fields[4], ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := parseVirtualMachineScheduledPowerDay(bounds[0])
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("%q: %s", s, err)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseVirtualMachineScheduledPowerDay(bounds[1]); err != nil {
+				return 0, 0, nil, fmt.Errorf("%q: %s", s, err)
+			}
+			// 7 is Sunday as the end of a range, like in 5-7.
+			if bounds[1] == "7" {
+				last = 7
+			}
+			if last < first {
+				return 0, 0, nil, fmt.Errorf("%q: invalid range of days %q", s, part)
+			}
+		}
+		for day := first; day <= last; day++ {
+			set[day%7] = true
+		}
+	}
+	var days []time.Weekday
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if set[day] {
+			days = append(days, day)
+		}
+	}
+	if len(days) == 7 {
+		days = nil
+	}
+	return int32(hour), int32(minute), days, nil
+}
+
+// parseVirtualMachineScheduledPowerDay parses a day of the week in a
+// schedule.
+func parseVirtualMachineScheduledPowerDay(s string) (time.Weekday, error) {
+	for i, name := range virtualMachineScheduledPowerDays {
+		if strings.EqualFold(s, name) {
+			return time.Weekday(i), nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 7 {
+		return 0, fmt.Errorf("invalid day of the week %q", s)
+	}
+	return time.Weekday(n % 7), nil
+}
+
+// formatVirtualMachineScheduledPowerSchedule returns the cron expression for
+// a time of day and days of the week, in the form that
+// parseVirtualMachineScheduledPowerSchedule accepts.
+func formatVirtualMachineScheduledPowerSchedule(hour, minute int32, days []time.Weekday) string {
+	dow := "*"
+	if len(days) > 0 {
+		var s []string
+		for _, day := range days {
+			s = append(s, strconv.Itoa(int(day)))
+		}
+		dow = strings.Join(s, ",")
+	}
+	return fmt.Sprintf("%d %d * * %s", minute, hour, dow)
+}
@@ -0,0 +1,79 @@
+package vsphere
+
+import (
+	"testing"
+	"time"
+
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/scheduledtask"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestParseVirtualMachineScheduledPowerSchedule(t *testing.T) {
+	cases := []struct {
+		schedule string
+		expected string
+		err      bool
+	}{
+		{schedule: "0 19 * * *", expected: "0 19 * * *"},
+		{schedule: "30 7 * * 1-5", expected: "30 7 * * 1,2,3,4,5"},
+		{schedule: "0 22 * * fri,SAT", expected: "0 22 * * 5,6"},
+		{schedule: "0 22 * * 5-7", expected: "0 22 * * 0,5,6"},
+		{schedule: "0 0 * * 0-6", expected: "0 0 * * *"},
+		{schedule: "0 0 * * 7", expected: "0 0 * * 0"},
+		{schedule: "0 19 * *", err: true},
+		{schedule: "*/15 19 * * *", err: true},
+		{schedule: "0 24 * * *", err: true},
+		{schedule: "0 19 1 * *", err: true},
+		{schedule: "0 19 * * 5-1", err: true},
+		{schedule: "0 19 * * funday", err: true},
+	}
+	for _, tc := range cases {
+		hour, minute, days, err := parseVirtualMachineScheduledPowerSchedule(tc.schedule)
+		if tc.err {
+			if err == nil {
+				t.Fatalf("%q: expected error, got none", tc.schedule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", tc.schedule, err)
+		}
+		if actual := formatVirtualMachineScheduledPowerSchedule(hour, minute, days); actual != tc.expected {
+			t.Fatalf("%q: expected %q, got %q", tc.schedule, tc.expected, actual)
+		}
+	}
+}
+
+func TestFlattenVirtualMachineScheduledPowerTask(t *testing.T) {
+	info := types.ScheduledTaskInfo{
+		ScheduledTaskSpec: types.ScheduledTaskSpec{
+			Scheduler: scheduledtask.DailyScheduler(19, 0, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}),
+			Action:    &types.MethodAction{Name: "ShutdownGuest"},
+		},
+	}
+	cases := []struct {
+		current  string
+		expected string
+	}{
+		{current: "0 19 * * mon-fri", expected: "0 19 * * mon-fri"},
+		{current: "0 20 * * mon-fri", expected: "0 19 * * 1,2,3,4,5"},
+		{current: "", expected: "0 19 * * 1,2,3,4,5"},
+	}
+	for _, tc := range cases {
+		action, schedule, err := flattenVirtualMachineScheduledPowerTask(info, tc.current)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", tc.current, err)
+		}
+		if action != "shutdown_guest" {
+			t.Fatalf("%q: expected action to be shutdown_guest, got %q", tc.current, action)
+		}
+		if schedule != tc.expected {
+			t.Fatalf("%q: expected schedule %q, got %q", tc.current, tc.expected, schedule)
+		}
+	}
+
+	info.Action = &types.MethodAction{Name: "ResetVM_Task"}
+	if _, _, err := flattenVirtualMachineScheduledPowerTask(info, ""); err == nil {
+		t.Fatalf("expected error for unsupported method, got none")
+	}
+}
@@ -0,0 +1,47 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
+)
+
+// resourceVSphereVirtualMachineDiffSCSIType handles changes to scsi_type on
+// an existing virtual machine. The controllers are swapped in place, which
+// needs the virtual machine to be powered off, so reboot_required is set in
+// the diff to show the power cycle in the plan. A warning about the drivers
+// the guest needs for the new controller type is logged.
+func resourceVSphereVirtualMachineDiffSCSIType(d *schema.ResourceDiff) error {
+	if d.Id() == "" || !d.HasChange("scsi_type") {
+		return nil
+	}
+	o, n := d.GetChange("scsi_type")
+	log.Printf("[WARN] %s: %s", resourceVSphereVirtualMachineIDString(d), virtualMachineSCSITypeChangeWarning(o.(string), n.(string), d.Get("guest_id").(string)))
+	return d.SetNew("reboot_required", true)
+}
+
+// virtualMachineSCSITypeChangeWarning returns the warning for a change of
+// the SCSI controller type of a virtual machine from one type to another. The
+// guest needs a driver for the new controller type before the change, or it
+// cannot find its boot disk.
+func virtualMachineSCSITypeChangeWarning(oldType, newType, guestID string) string {
+	msg := fmt.Sprintf(
+		"SCSI controller type is changing from %s to %s. The virtual machine will be powered off to swap the controllers, and the guest must already have a driver for %s, or it may fail to boot",
+		oldType,
+		newType,
+		newType,
+	)
+	if !strings.HasPrefix(guestID, "win") {
+		return msg
+	}
+	switch newType {
+	case virtualdevice.SubresourceControllerTypeParaVirtual:
+		msg += ". On Windows, install VMware Tools, which includes the pvscsi driver, and make sure the driver is loaded before the change"
+	case virtualdevice.SubresourceControllerTypeLsiLogicSAS:
+		msg += ". Windows Server 2003 and earlier do not include a driver for lsilogic-sas"
+	}
+	return msg
+}
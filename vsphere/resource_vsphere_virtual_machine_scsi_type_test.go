@@ -0,0 +1,31 @@
+package vsphere
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVirtualMachineSCSITypeChangeWarning(t *testing.T) {
+	cases := []struct {
+		oldType  string
+		newType  string
+		guestID  string
+		expected string
+	}{
+		{oldType: "lsilogic-sas", newType: "pvscsi", guestID: "windows9Server64Guest", expected: "includes the pvscsi driver"},
+		{oldType: "lsilogic", newType: "lsilogic-sas", guestID: "winNetStandardGuest", expected: "do not include a driver for lsilogic-sas"},
+		{oldType: "lsilogic-sas", newType: "pvscsi", guestID: "other3xLinux64Guest", expected: "must already have a driver for pvscsi"},
+	}
+	for _, tc := range cases {
+		actual := virtualMachineSCSITypeChangeWarning(tc.oldType, tc.newType, tc.guestID)
+		if !strings.Contains(actual, tc.expected) {
+			t.Fatalf("%s to %s on %s: expected warning to contain %q, got %q", tc.oldType, tc.newType, tc.guestID, tc.expected, actual)
+		}
+		if !strings.Contains(actual, "from "+tc.oldType+" to "+tc.newType) {
+			t.Fatalf("%s to %s on %s: expected warning to name both types, got %q", tc.oldType, tc.newType, tc.guestID, actual)
+		}
+	}
+	if actual := virtualMachineSCSITypeChangeWarning("pvscsi", "lsilogic", "other3xLinux64Guest"); strings.Contains(actual, "Windows") {
+		t.Fatalf("expected no Windows guidance for a Linux guest, got %q", actual)
+	}
+}
@@ -11,8 +11,10 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/computeresource"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/datastore"
@@ -20,8 +22,11 @@ import (
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualdisk"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
+	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -48,6 +53,9 @@ func TestAccResourceVSphereVirtualMachine_basic(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
 					resource.TestMatchResourceAttr("vsphere_virtual_machine.vm", "moid", regexp.MustCompile("^vm-")),
+					resource.TestMatchResourceAttr("vsphere_virtual_machine.vm", "vmx_datastore_path", regexp.MustCompile(`^\[.+\] terraform-test/terraform-test\.vmx$`)),
+					resource.TestMatchResourceAttr("vsphere_virtual_machine.vm", "config_files.#", regexp.MustCompile("^[1-9]")),
+					resource.TestMatchResourceAttr("vsphere_virtual_machine.vm", "disk.0.datastore_path", regexp.MustCompile(`^\[.+\] terraform-test/terraform-test\.vmdk$`)),
 				),
 			},
 		},
@@ -639,7 +647,7 @@ func TestAccResourceVSphereVirtualMachine_upgradeCPUAndRam(t *testing.T) {
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_modifyAnnotation(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_snapshotBeforeDisruptiveUpdate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -649,51 +657,69 @@ func TestAccResourceVSphereVirtualMachine_modifyAnnotation(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigSnapshotBeforeDisruptiveUpdate(2, 2048, 1),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckPreUpdateSnapshots(0),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicAnnotation(),
+				Config: testAccResourceVSphereVirtualMachineConfigSnapshotBeforeDisruptiveUpdate(4, 4096, 1),
 				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckAnnotation(),
-					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 4096),
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(true),
+					testAccResourceVSphereVirtualMachineCheckPreUpdateSnapshots(1),
+				),
+			},
+			{
+				// The snapshot from the last step is removed after this update, as
+				// only one is kept.
+				Config: testAccResourceVSphereVirtualMachineConfigSnapshotBeforeDisruptiveUpdate(2, 2048, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
+					testAccResourceVSphereVirtualMachineCheckPreUpdateSnapshots(1),
+				),
+			},
+			{
+				// With no snapshots kept, the snapshot is only there while the update
+				// runs.
+				Config: testAccResourceVSphereVirtualMachineConfigSnapshotBeforeDisruptiveUpdate(4, 4096, 0),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 4096),
+					testAccResourceVSphereVirtualMachineCheckPreUpdateSnapshots(0),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_growDisk(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_unregisterOnDestroy(t *testing.T) {
+	// Soft destroy is enabled as well, as unregister_on_destroy takes precedence
+	// over it.
+	var vmx testAccResourceVSphereVirtualMachineVMXLocation
+	defer os.Unsetenv("VSPHERE_SOFT_DESTROY")
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			os.Setenv("VSPHERE_SOFT_DESTROY", "true")
 		},
 		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckUnregistered(&vmx),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(10),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckDiskSize(10),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(20),
+				Config: testAccResourceVSphereVirtualMachineConfigUnregisterOnDestroy(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckDiskSize(20),
+					testAccResourceVSphereVirtualMachineSaveVMXLocation(&vmx),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_swapSCSIBus(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_modifyAnnotation(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -706,21 +732,23 @@ func TestAccResourceVSphereVirtualMachine_swapSCSIBus(t *testing.T) {
 				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeParaVirtual),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigLsiLogicSAS(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasicAnnotation(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeLsiLogicSAS),
+					testAccResourceVSphereVirtualMachineCheckAnnotation(),
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_extraConfig(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_managedAnnotation(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -730,17 +758,27 @@ func TestAccResourceVSphereVirtualMachine_extraConfig(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
+				Config: testAccResourceVSphereVirtualMachineConfigManagedAnnotation(),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
+					testAccResourceVSphereVirtualMachineCheckManagedAnnotation(""),
 				),
 			},
+			{
+				PreConfig: func() {
+					if err := testReconfigureVMAnnotation(state, "vm", joinManagedAnnotation("manual notes", "Managed by Terraform: terraform-test")); err != nil {
+						panic(err)
+					}
+				},
+				PlanOnly: true,
+				Config:   testAccResourceVSphereVirtualMachineConfigManagedAnnotation(),
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_extraConfigSwapKeys(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_upgradeHardwareVersion(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -750,45 +788,46 @@ func TestAccResourceVSphereVirtualMachine_extraConfigSwapKeys(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
+				Config: testAccResourceVSphereVirtualMachineConfigHardwareVersion(10),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
+					testAccResourceVSphereVirtualMachineCheckHardwareVersion("vmx-10"),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("baz", "qux"),
+				Config: testAccResourceVSphereVirtualMachineConfigHardwareVersion(13),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraConfig("baz", "qux"),
-					testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing("foo"),
+					testAccResourceVSphereVirtualMachineCheckHardwareVersion("vmx-13"),
 				),
 			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigHardwareVersion(11),
+				ExpectError: regexp.MustCompile("cannot downgrade hardware_version"),
+				PlanOnly:    true,
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_attachExistingVmdk(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_invalidGuestID(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigInvalidGuestID(),
+				ExpectError: regexp.MustCompile("guest_id \"otherGuest64bit\" is not supported"),
+				PlanOnly:    true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_inFolder(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_memoryReservationLockedAndBalloon(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -798,17 +837,18 @@ func TestAccResourceVSphereVirtualMachine_inFolder(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+				Config: testAccResourceVSphereVirtualMachineConfigMemoryReservationLocked(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+					testAccResourceVSphereVirtualMachineCheckMemoryReservationLocked(),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig(virtualMachineMemoryBalloonMaxSizeKey, "0"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_moveToFolder(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_latencySensitivityAndAffinity(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -818,23 +858,24 @@ func TestAccResourceVSphereVirtualMachine_moveToFolder(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigLatencySensitivity("${data.vsphere_host.host.num_cpu_threads}"),
+				ExpectError: regexp.MustCompile("does not exist on host"),
+				PlanOnly:    true,
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+				Config: testAccResourceVSphereVirtualMachineConfigLatencySensitivity("0"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "latency_sensitivity", "high"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "cpu_affinity.#", "1"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig(virtualMachineNumaNodeAffinityKey, "0"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_staticMAC(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_videoCardAndVNC(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -844,37 +885,51 @@ func TestAccResourceVSphereVirtualMachine_staticMAC(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStaticMAC(),
+				Config: testAccResourceVSphereVirtualMachineConfigVideoCardAndVNC(8192, 5901),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckStaticMACAddr(),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "video_memory", "8192"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "video_num_displays", "2"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig(virtualMachineVNCPortKey, "5901"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigVideoCardAndVNC(16384, 0),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "video_memory", "16384"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "vnc_port", "0"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing(virtualMachineVNCPortKey),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_singleTag(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_readinessProbeBadConfig(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigReadinessProbe(`command = "/bin/true"`),
+				ExpectError: regexp.MustCompile("guest_username is required with command"),
+				PlanOnly:    true,
+			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigReadinessProbe("interval = 5"),
+				ExpectError: regexp.MustCompile("one of command or tcp_port must be set"),
+				PlanOnly:    true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_multipleTags(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_adoptExisting(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -884,17 +939,35 @@ func TestAccResourceVSphereVirtualMachine_multipleTags(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
 				),
 			},
+			{
+				PreConfig: func() {
+					if err := testCloneVM(state, "vm", "terraform-test-adopt"); err != nil {
+						panic(err)
+					}
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigAdoptExisting(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.adopted", "name", "terraform-test-adopt"),
+					// Disks of adopted virtual machines are set up as on import, so
+					// the next apply reconciles them with the configuration.
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.adopted", "disk.0.label", "disk0"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.adopted", "disk.0.keep_on_remove", "true"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_switchTags(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_syncCPUMemoryDisabled(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -904,26 +977,27 @@ func TestAccResourceVSphereVirtualMachine_switchTags(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
+				Config: testAccResourceVSphereVirtualMachineConfigSyncCPUMemoryDisabled(),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
-				),
+				PreConfig: func() {
+					if err := testReconfigureVMCPUMem(state, "vm", 4, 4096); err != nil {
+						panic(err)
+					}
+				},
+				PlanOnly: true,
+				Config:   testAccResourceVSphereVirtualMachineConfigSyncCPUMemoryDisabled(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_renamedDiskInPlaceOfExisting(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_growDisk(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -933,87 +1007,71 @@ func TestAccResourceVSphereVirtualMachine_renamedDiskInPlaceOfExisting(t *testin
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(10),
 				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckDiskSize(10),
 				),
 			},
 			{
-				PreConfig: func() {
-					if err := testRenameVMFirstDisk(state, "vm", "foobar.vmdk"); err != nil {
-						panic(err)
-					}
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(20),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					// The only real way we can check to see if this is actually
-					// functional in the current test framework is by checking that
-					// the file we renamed to was not deleted (this is due to a lack
-					// of ability to check diff in the test framework right now).
-					testCheckVMDiskFileExists("terraform-test.vmdk"),
-					testCheckVMDiskFileExists("foobar.vmdk"),
+					testAccResourceVSphereVirtualMachineCheckDiskSize(20),
 				),
 			},
-			// The last step is a cleanup step. This assumes the test is
-			// functional as the orphaned disk will be now detached and not
-			// deleted when the VM is destroyed.
-			{
-				PreConfig: func() {
-					if err := testDeleteVMDisk(state, "vm", "foobar.vmdk"); err != nil {
-						panic(err)
-					}
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
-				Check:  resource.ComposeTestCheckFunc(),
-			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockComputedDiskName(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_swapSCSIBus(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigComputedDisk(),
-				ExpectError: regexp.MustCompile("disk label or name must be defined and cannot be computed"),
-				PlanOnly:    true,
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeParaVirtual),
+				),
 			},
 			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigLsiLogicSAS(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckSCSIBus(virtualdevice.SubresourceControllerTypeLsiLogicSAS),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClones(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_extraConfig(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
-				ExpectError: regexp.MustCompile("vApp properties can only be set on cloned virtual machines"),
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClonesAfterCreation(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_extraConfigSwapKeys(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1023,85 +1081,88 @@ func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClonesAfterCreat
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("foo", "bar"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("foo", "bar"),
 				),
 			},
 			{
-				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
-				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigExtraConfig("baz", "qux"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExtraConfig("baz", "qux"),
+					testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing("foo"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_blockDiskLabelStartingWithOrphanedPrefix(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_attachExistingVmdk(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadOrphanedLabel(),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta(`disk label "orphaned_disk_0" cannot start with "orphaned_disk_"`)),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdk(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckExistingVmdk(),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_createIntoEmptyClusterNoEnvironmentBrowser(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_inFolder(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBasicEmptyCluster(),
-				ExpectError: regexp.MustCompile("compute resource .* is missing an Environment Browser\\. Check host, cluster, and vSphere license health of all associated resources and try again"),
+				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneFromTemplate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_createFolders(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccResourceVSphereVirtualMachineCheckExists(false),
+			testAccResourceVSphereVirtualMachineCheckFolderRemoved("terraform-test-vms-auto"),
+		),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
+				Config: testAccResourceVSphereVirtualMachineConfigCreateFolders(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
+					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms-auto/nested"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneModifyDiskAndSCSITypeAtSameTime(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_moveToFolder(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1111,32 +1172,23 @@ func TestAccResourceVSphereVirtualMachine_cloneModifyDiskAndSCSITypeAtSameTime(t
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneChangeDiskAndSCSI(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						oldSize, _ := strconv.Atoi(state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["disks.0.size"])
-						newSize := oldSize * 2
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.size", strconv.Itoa(newSize))(s)
-					},
-					func(s *terraform.State) error {
-						oldBus := state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["scsi_type"]
-						var expected string
-						if oldBus == virtualdevice.SubresourceControllerTypeParaVirtual {
-							expected = virtualdevice.SubresourceControllerTypeLsiLogicSAS
-						} else {
-							expected = virtualdevice.SubresourceControllerTypeParaVirtual
-						}
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "scsi_type", expected)(s)
-					},
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneMultiNICFromSingleNICTemplate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_staticMAC(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1146,17 +1198,17 @@ func TestAccResourceVSphereVirtualMachine_cloneMultiNICFromSingleNICTemplate(t *
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneMultiNIC(),
+				Config: testAccResourceVSphereVirtualMachineConfigStaticMAC(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
+					testAccResourceVSphereVirtualMachineCheckStaticMACAddr(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_singleTag(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1166,79 +1218,115 @@ func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneTimeZone("America/Vancouver"),
+				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadTimezone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_multipleTags(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneTimeZone("Pacific Standard Time"),
-				ExpectError: regexp.MustCompile("must be similar to America/Los_Angeles or other Linux/Unix TZ format"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadEagerlyScrubWithLinkedClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_switchTags(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadEager(),
-				ExpectError: regexp.MustCompile("must have same value for eagerly_scrub as source when using linked_clone"),
-				PlanOnly:    true,
+				Config: testAccResourceVSphereVirtualMachineConfigSingleTag(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tag"),
+				),
 			},
 			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config: testAccResourceVSphereVirtualMachineConfigMultiTag(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckTags("terraform-test-tags-alt"),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadThinProvisionedWithLinkedClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_renamedDiskInPlaceOfExisting(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadThin(),
-				ExpectError: regexp.MustCompile("must have same value for thin_provisioned as source when using linked_clone"),
-				PlanOnly:    true,
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
 			},
 			{
-				Config: testAccResourceVSphereEmpty,
+				PreConfig: func() {
+					if err := testRenameVMFirstDisk(state, "vm", "foobar.vmdk"); err != nil {
+						panic(err)
+					}
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					// The only real way we can check to see if this is actually
+					// functional in the current test framework is by checking that
+					// the file we renamed to was not deleted (this is due to a lack
+					// of ability to check diff in the test framework right now).
+					testCheckVMDiskFileExists("terraform-test.vmdk"),
+					testCheckVMDiskFileExists("foobar.vmdk"),
+				),
+			},
+			// The last step is a cleanup step. This assumes the test is
+			// functional as the orphaned disk will be now detached and not
+			// deleted when the VM is destroyed.
+			{
+				PreConfig: func() {
+					if err := testDeleteVMDisk(state, "vm", "foobar.vmdk"); err != nil {
+						panic(err)
+					}
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
 				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_blockComputedDiskName(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1247,8 +1335,8 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *tes
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeLinked(),
-				ExpectError: regexp.MustCompile("must be the exact size of source when using linked_clone"),
+				Config:      testAccResourceVSphereVirtualMachineConfigComputedDisk(),
+				ExpectError: regexp.MustCompile("disk label or name must be defined and cannot be computed"),
 				PlanOnly:    true,
 			},
 			{
@@ -1259,7 +1347,7 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *tes
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithoutLinkedClone(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClones(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1268,9 +1356,8 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithoutLinkedClone(t *
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeUnlinked(),
-				ExpectError: regexp.MustCompile("must be at least the same size of source when cloning"),
-				PlanOnly:    true,
+				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
+				ExpectError: regexp.MustCompile("vApp properties can only be set on cloned virtual machines"),
 			},
 			{
 				Config: testAccResourceVSphereEmpty,
@@ -1280,46 +1367,57 @@ func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithoutLinkedClone(t *
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnCreate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_blockVAppSettingsOnNonClonesAfterCreation(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineVAppPropertiesNonClone(),
 				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
 			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnUpdate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_blockDiskLabelStartingWithOrphanedPrefix(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigClone(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadOrphanedLabel(),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta(`disk label "orphaned_disk_0" cannot start with "orphaned_disk_"`)),
+				PlanOnly:    true,
 			},
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
-				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneIntoEmptyCluster(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_createIntoEmptyClusterNoEnvironmentBrowser(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1328,19 +1426,14 @@ func TestAccResourceVSphereVirtualMachine_cloneIntoEmptyCluster(t *testing.T) {
 		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneEmptyCluster(),
+				Config:      testAccResourceVSphereVirtualMachineConfigBasicEmptyCluster(),
 				ExpectError: regexp.MustCompile("compute resource .* is missing an Environment Browser\\. Check host, cluster, and vSphere license health of all associated resources and try again"),
-				PlanOnly:    true,
-			},
-			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithDifferentHostname(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneFromTemplate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1350,17 +1443,19 @@ func TestAccResourceVSphereVirtualMachine_cloneWithDifferentHostname(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneHostname(),
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test-renamed"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithExtraDisks(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneModifyDiskAndSCSITypeAtSameTime(t *testing.T) {
+	var state *terraform.State
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1370,17 +1465,32 @@ func TestAccResourceVSphereVirtualMachine_cloneWithExtraDisks(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneExtraDisks(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneChangeDiskAndSCSI(),
 				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckExtraDisks(),
+					func(s *terraform.State) error {
+						oldSize, _ := strconv.Atoi(state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["disks.0.size"])
+						newSize := oldSize * 2
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.size", strconv.Itoa(newSize))(s)
+					},
+					func(s *terraform.State) error {
+						oldBus := state.RootModule().Resources["data.vsphere_virtual_machine.template"].Primary.Attributes["scsi_type"]
+						var expected string
+						if oldBus == virtualdevice.SubresourceControllerTypeParaVirtual {
+							expected = virtualdevice.SubresourceControllerTypeLsiLogicSAS
+						} else {
+							expected = virtualdevice.SubresourceControllerTypeParaVirtual
+						}
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "scsi_type", expected)(s)
+					},
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithCdrom(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneMultiNICFromSingleNICTemplate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1390,17 +1500,17 @@ func TestAccResourceVSphereVirtualMachine_cloneWithCdrom(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithCdrom(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneMultiNIC(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckIsoCdrom(),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithVAppProperties(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithNetworkMap(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1410,81 +1520,84 @@ func TestAccResourceVSphereVirtualMachine_cloneWithVAppProperties(t *testing.T)
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneUpdatingVAppProperties(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneNetworkMap(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", "8.8.8.8"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.#", "1"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "network_interface.0.network_id", "data.vsphere_network.network", "id"),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", ""),
-				),
+				Config:   testAccResourceVSphereVirtualMachineConfigCloneNetworkMap(),
+				PlanOnly: true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnCreate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneFromContentLibrary(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			testAccSkipIfEsxi(t)
+			if os.Getenv("VSPHERE_CONTENT_LIBRARY_OVF_ITEM_ID") == "" {
+				t.Skip("set VSPHERE_CONTENT_LIBRARY_OVF_ITEM_ID to the ID of an OVF template with a single disk of up to 20 GB to run this test")
+			}
 		},
-		Providers: testAccProviders,
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneContentLibrary(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.size", "20"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "network_interface.0.network_id", "data.vsphere_network.network", "id"),
+				),
 			},
 			{
-				Config: testAccResourceVSphereEmpty,
-				Check:  resource.ComposeTestCheckFunc(),
+				Config:   testAccResourceVSphereVirtualMachineConfigCloneContentLibrary(),
+				PlanOnly: true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnUpdate(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_ovfDeploy(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
+			if os.Getenv("VSPHERE_OVF_URL") == "" {
+				t.Skip("set VSPHERE_OVF_URL to the URL of an OVA with a single disk of up to 20 GB and a network named \"VM Network\" to run this test")
+			}
 		},
 		Providers:    testAccProviders,
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
+				Config: testAccResourceVSphereVirtualMachineConfigOvfDeploy(30),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
-					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.size", "20"),
+					resource.TestCheckResourceAttrPair("vsphere_virtual_machine.vm", "network_interface.0.network_id", "data.vsphere_network.network", "id"),
 				),
 			},
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
+				Config:   testAccResourceVSphereVirtualMachineConfigOvfDeploy(30),
+				PlanOnly: true,
+			},
+			{
+				// Changes to ovf_deploy after creation are ignored.
+				Config:   testAccResourceVSphereVirtualMachineConfigOvfDeploy(60),
+				PlanOnly: true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_cpuHotAdd(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithDifferentTimezone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1494,27 +1607,16 @@ func TestAccResourceVSphereVirtualMachine_cpuHotAdd(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				// Starting config
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneTimeZone("America/Vancouver"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
-				),
-			},
-			{
-				// Add CPU w/hot-add
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(4, 2048, true, false, true),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 2048),
-					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_memoryHotAdd(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneChangesIgnoredAfterCreate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1524,185 +1626,141 @@ func TestAccResourceVSphereVirtualMachine_memoryHotAdd(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				// Starting config
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneTimeZone("America/Vancouver"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
 				),
 			},
 			{
-				// Add memory with hot-add
-				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 3072, true, false, true),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 3072),
-					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
-				),
+				Config:   testAccResourceVSphereVirtualMachineConfigCloneTimeZone("America/Toronto"),
+				PlanOnly: true,
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_dualStackIPv4AndIPv6(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadTimezone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigDualStack(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
-					testAccResourceVSphereVirtualMachineCheckNet(
-						os.Getenv("VSPHERE_IPV4_ADDRESS"),
-						os.Getenv("VSPHERE_IPV4_PREFIX"),
-						os.Getenv("VSPHERE_IPV4_GATEWAY"),
-					),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneTimeZone("Pacific Standard Time"),
+				ExpectError: regexp.MustCompile("must be similar to America/Los_Angeles or other Linux/Unix TZ format"),
+				PlanOnly:    true,
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_IPv6Only(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadEagerlyScrubWithLinkedClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigIPv6Only(),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
-					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", "fd00::2"),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadEager(),
+				ExpectError: regexp.MustCompile("must have same value for eagerly_scrub as source when using linked_clone"),
+				PlanOnly:    true,
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_windowsTemplateCustomizationEventsAndProperIP(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_cloneWithBadThinProvisionedWithLinkedClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWindows(),
-				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
-					testAccResourceVSphereVirtualMachineCheckNet(
-						os.Getenv("VSPHERE_IPV4_ADDRESS"),
-						os.Getenv("VSPHERE_IPV4_PREFIX"),
-						os.Getenv("VSPHERE_IPV4_GATEWAY"),
-					),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadThin(),
+				ExpectError: regexp.MustCompile("must have same value for thin_provisioned as source when using linked_clone"),
+				PlanOnly:    true,
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_hostVMotion(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithLinkedClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeLinked(),
+				ExpectError: regexp.MustCompile("must be the exact size of source when using linked_clone"),
+				PlanOnly:    true,
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST2")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_resourcePoolVMotion(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithBadSizeWithoutLinkedClone(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(os.Getenv("VSPHERE_RESOURCE_POOL")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(os.Getenv("VSPHERE_RESOURCE_POOL")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigBadSizeUnlinked(),
+				ExpectError: regexp.MustCompile("must be at least the same size of source when cloning"),
+				PlanOnly:    true,
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionGlobalSetting(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnCreate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE2")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
+				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneUnsupportedVAppPropertiesOnUpdate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1712,59 +1770,41 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T)
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE")),
+				Config: testAccResourceVSphereVirtualMachineConfigClone(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE2")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneBadVAppSettings(),
+				ExpectError: regexp.MustCompile("this VM lacks a vApp configuration and cannot have vApp properties set on it"),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionPinDatastore(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneIntoEmptyCluster(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 			testAccResourceVSphereVirtualMachinePreCheck(t)
 		},
-		Providers:    testAccProviders,
-		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Providers: testAccProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-				),
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneEmptyCluster(),
+				ExpectError: regexp.MustCompile("compute resource .* is missing an Environment Browser\\. Check host, cluster, and vSphere license health of all associated resources and try again"),
+				PlanOnly:    true,
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-				),
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionRenamedVirtualMachine(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithDifferentHostname(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1774,36 +1814,17 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionRenamedVirtualMachine(t
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("terraform-test", os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE2")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneHostname(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("foobar-test.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckHostname("terraform-test-renamed"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionLinkedClones(t *testing.T) {
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_cloneWithExtraDisks(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1813,30 +1834,17 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionLinkedClones(t *testing.
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE")),
-				Check: resource.ComposeTestCheckFunc(
-					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
-				),
-			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE2")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneExtraDisks(),
 				Check: resource.ComposeTestCheckFunc(
-					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
-					func(s *terraform.State) error {
-						filename := path.Base(state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.path"])
-						return testAccResourceVSphereVirtualMachineCheckVmdkDatastore(filename, os.Getenv("VSPHERE_DATASTORE2"))(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckExtraDisks(),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_storageVMotionBlockExternallyAttachedDisks(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithCdrom(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1846,24 +1854,17 @@ func TestAccResourceVSphereVirtualMachine_storageVMotionBlockExternallyAttachedD
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE")),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithCdrom(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
-					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckIsoCdrom(),
 				),
 			},
-			{
-				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE2")),
-				ExpectError: regexp.MustCompile(regexp.QuoteMeta(
-					fmt.Sprintf("externally attached disk %q cannot be migrated", testAccResourceVSphereVirtualMachineDiskNameExtraVmdk),
-				)),
-			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_singleCustomAttribute(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cloneWithVAppProperties(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1873,17 +1874,56 @@ func TestAccResourceVSphereVirtualMachine_singleCustomAttribute(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
 				),
 			},
-		},
-	})
-}
-
-func TestAccResourceVSphereVirtualMachine_multiCustomAttribute(t *testing.T) {
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloneUpdatingVAppProperties(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", "8.8.8.8"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.1", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnCreate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
+			},
+			{
+				Config: testAccResourceVSphereEmpty,
+				Check:  resource.ComposeTestCheckFunc(),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_cloneWithBadVAppPropertyOnUpdate(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1893,17 +1933,22 @@ func TestAccResourceVSphereVirtualMachine_multiCustomAttribute(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				Config: testAccResourceVSphereVirtualMachineConfigCloneWithVAppProperties(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.hostname", "terraform-test.test.internal"),
+					testAccResourceVSphereVirtualMachineCheckVAppConfigKey("guestinfo.dns.server.0", os.Getenv("VSPHERE_DNS")),
 				),
 			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigCloneVAppPropertiesBadKey(),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta("unsupported vApp properties in vapp.properties: [foo]")),
+			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_switchCustomAttribute(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_cpuHotAdd(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1913,27 +1958,57 @@ func TestAccResourceVSphereVirtualMachine_switchCustomAttribute(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				// Starting config
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				// Add CPU w/hot-add
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(4, 2048, true, false, true),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(4, 2048),
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_transitionToLabel(t *testing.T) {
-	// TODO: Remove this test in 2.0
-	var state *terraform.State
+func TestAccResourceVSphereVirtualMachine_memoryHotAdd(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				// Starting config
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 2048, true, false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 2048),
+				),
+			},
+			{
+				// Add memory with hot-add
+				Config: testAccResourceVSphereVirtualMachineConfigWithHotAdd(2, 3072, true, false, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCPUMem(2, 3072),
+					testAccResourceVSphereVirtualMachineCheckPowerOffEvent(false),
+				),
+			},
+		},
+	})
+}
 
+func TestAccResourceVSphereVirtualMachine_dualStackIPv4AndIPv6(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -1943,28 +2018,44 @@ func TestAccResourceVSphereVirtualMachine_transitionToLabel(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Config: testAccResourceVSphereVirtualMachineConfigDualStack(),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
+					testAccResourceVSphereVirtualMachineCheckNet(
+						os.Getenv("VSPHERE_IPV4_ADDRESS"),
+						os.Getenv("VSPHERE_IPV4_PREFIX"),
+						os.Getenv("VSPHERE_IPV4_GATEWAY"),
+					),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", os.Getenv("VSPHERE_IPV4_ADDRESS")),
 				),
 			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_IPv6Only(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				Config: testAccResourceVSphereVirtualMachineConfigIPv6Only(),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "default_ip_address", "fd00::2"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_preventRevertToName(t *testing.T) {
-	// TODO: Remove this test in 2.0
+func TestAccResourceVSphereVirtualMachine_windowsTemplateCustomizationEventsAndProperIP(t *testing.T) {
 	var state *terraform.State
 
 	resource.Test(t, resource.TestCase{
@@ -1976,34 +2067,56 @@ func TestAccResourceVSphereVirtualMachine_preventRevertToName(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Config: testAccResourceVSphereVirtualMachineConfigWindows(),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
+					copyStatePtr(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
+					testAccResourceVSphereVirtualMachineCheckNet(
+						os.Getenv("VSPHERE_IPV4_ADDRESS"),
+						os.Getenv("VSPHERE_IPV4_PREFIX"),
+						os.Getenv("VSPHERE_IPV4_GATEWAY"),
+					),
+					resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "clone.0.customize.0.windows_options.0.admin_password", ""),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				// The password is not in state, but this must not produce a diff.
+				Config:   testAccResourceVSphereVirtualMachineConfigWindows(),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_hostVMotion(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST")),
 				),
 			},
 			{
-				Config:      testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
-				ExpectError: regexp.MustCompile("cannot migrate from label to name"),
+				Config: testAccResourceVSphereVirtualMachineConfigHostVMotion(os.Getenv("VSPHERE_ESXI_HOST2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckHost(os.Getenv("VSPHERE_ESXI_HOST2")),
+				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_transitionToLabelAttachedDisk(t *testing.T) {
-	// TODO: Remove this test in 2.0
-	var state *terraform.State
-
+func TestAccResourceVSphereVirtualMachine_resourcePoolVMotion(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2013,30 +2126,24 @@ func TestAccResourceVSphereVirtualMachine_transitionToLabelAttachedDisk(t *testi
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithName(),
+				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(os.Getenv("VSPHERE_RESOURCE_POOL")),
 				Check: resource.ComposeTestCheckFunc(
-					copyState(&state),
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckResourcePool(os.Getenv("VSPHERE_RESOURCE_POOL")),
 				),
 			},
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithLabel(),
+				Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
-					func(s *terraform.State) error {
-						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.1.uuid"]
-						if err := resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.uuid", uuid)(s); err != nil {
-							return err
-						}
-						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.attach", "true")(s)
-					},
+					testAccResourceVSphereVirtualMachineCheckResourcePool(fmt.Sprintf("%s/Resources", os.Getenv("VSPHERE_CLUSTER"))),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_import(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_storageVMotionGlobalSetting(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2046,36 +2153,26 @@ func TestAccResourceVSphereVirtualMachine_import(t *testing.T) {
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				ResourceName:      "vsphere_virtual_machine.vm",
-				ImportState:       true,
-				ImportStateVerify: true,
-				ImportStateVerifyIgnore: []string{
-					"disk",
-					"imported",
-				},
-				ImportStateIdFunc: func(s *terraform.State) (string, error) {
-					vm, err := testGetVirtualMachine(s, "vm")
-					if err != nil {
-						return "", err
-					}
-					return vm.InventoryPath, nil
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(os.Getenv("VSPHERE_DATASTORE2")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
 				),
 			},
 		},
 	})
 }
 
-func TestAccResourceVSphereVirtualMachine_importWithMultipleDisksAtDifferentSCSISlots(t *testing.T) {
+func TestAccResourceVSphereVirtualMachine_storageVMotionVmxDatastore(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -2085,893 +2182,2139 @@ func TestAccResourceVSphereVirtualMachine_importWithMultipleDisksAtDifferentSCSI
 		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
 		Steps: []resource.TestStep{
 			{
-				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Config: testAccResourceVSphereVirtualMachineConfigVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 			{
-				ResourceName:      "vsphere_virtual_machine.vm",
-				ImportState:       true,
-				ImportStateVerify: true,
-				ImportStateVerifyIgnore: []string{
-					"disk",
-					"imported",
-				},
-				ImportStateIdFunc: func(s *terraform.State) (string, error) {
-					vm, err := testGetVirtualMachine(s, "vm")
-					if err != nil {
-						return "", err
-					}
-					return vm.InventoryPath, nil
-				},
-				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Config: testAccResourceVSphereVirtualMachineConfigVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
 				Check: resource.ComposeTestCheckFunc(
 					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
 				),
 			},
 		},
 	})
 }
 
-func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
-	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
-	// speeds up tests greatly, but it's not a necessary variable, so we don't
-	// enforce it here.
-	if os.Getenv("VSPHERE_DATACENTER") == "" {
-		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_CLUSTER") == "" {
-		t.Skip("set VSPHERE_CLUSTER to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
-		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
-		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_virtual_machine acceptance tests")
-	}
-	if os.Getenv("VSPHERE_NETWORK_LABEL_PXE") == "" {
-		t.Skip("set VSPHERE_NETWORK_LABEL_PXE to run vsphere_virtual_machine acceptance tests")
-	}
+func TestAccResourceVSphereVirtualMachine_storageVMotionSingleDisk(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionSingleDisk(os.Getenv("VSPHERE_DATASTORE2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_storageVMotionPinDatastore(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionPinDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test_1.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_storageVMotionRenamedVirtualMachine(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("terraform-test", os.Getenv("VSPHERE_DATASTORE")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionRename("foobar-test", os.Getenv("VSPHERE_DATASTORE2")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("foobar-test.vmdk", os.Getenv("VSPHERE_DATASTORE2")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_storageVMotionLinkedClones(t *testing.T) {
+	var state *terraform.State
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionLinkedClone(os.Getenv("VSPHERE_DATASTORE2")),
+				Check: resource.ComposeTestCheckFunc(
+					copyStatePtr(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+					func(s *terraform.State) error {
+						filename := path.Base(state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.path"])
+						return testAccResourceVSphereVirtualMachineCheckVmdkDatastore(filename, os.Getenv("VSPHERE_DATASTORE2"))(s)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_storageVMotionBlockExternallyAttachedDisks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE")),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckVmxDatastore(os.Getenv("VSPHERE_DATASTORE")),
+					testAccResourceVSphereVirtualMachineCheckVmdkDatastore("terraform-test.vmdk", os.Getenv("VSPHERE_DATASTORE")),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigStorageVMotionAttachedDisk(os.Getenv("VSPHERE_DATASTORE2")),
+				ExpectError: regexp.MustCompile(regexp.QuoteMeta(
+					fmt.Sprintf("externally attached disk %q cannot be migrated", testAccResourceVSphereVirtualMachineDiskNameExtraVmdk),
+				)),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_singleCustomAttribute(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_multiCustomAttribute(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_switchCustomAttribute(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigWithMultiCustomAttribute(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					testAccResourceVSphereVirtualMachineCheckCustomAttributes(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_transitionToLabel(t *testing.T) {
+	// TODO: Remove this test in 2.0
+	var state *terraform.State
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_preventRevertToName(t *testing.T) {
+	// TODO: Remove this test in 2.0
+	var state *terraform.State
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("label"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.0.uuid"]
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.0.uuid", uuid)(s)
+					},
+				),
+			},
+			{
+				Config:      testAccResourceVSphereVirtualMachineConfigBasicDiskNameOrLabel("name"),
+				ExpectError: regexp.MustCompile("cannot migrate from label to name"),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_transitionToLabelAttachedDisk(t *testing.T) {
+	// TODO: Remove this test in 2.0
+	var state *terraform.State
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithName(),
+				Check: resource.ComposeTestCheckFunc(
+					copyState(&state),
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigExistingVmdkWithLabel(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+					func(s *terraform.State) error {
+						uuid := state.RootModule().Resources["vsphere_virtual_machine.vm"].Primary.Attributes["disk.1.uuid"]
+						if err := resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.uuid", uuid)(s); err != nil {
+							return err
+						}
+						return resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "disk.1.attach", "true")(s)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				ResourceName:      "vsphere_virtual_machine.vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"disk",
+					"imported",
+				},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					vm, err := testGetVirtualMachine(s, "vm")
+					if err != nil {
+						return "", err
+					}
+					return vm.InventoryPath, nil
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVSphereVirtualMachine_importWithMultipleDisksAtDifferentSCSISlots(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+			{
+				ResourceName:      "vsphere_virtual_machine.vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"disk",
+					"imported",
+				},
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					vm, err := testGetVirtualMachine(s, "vm")
+					if err != nil {
+						return "", err
+					}
+					return vm.InventoryPath, nil
+				},
+				Config: testAccResourceVSphereVirtualMachineConfigMultiHighBus(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+		},
+	})
+}
+
+func TestResourceVSphereVirtualMachineSetSourceDrift(t *testing.T) {
+	source := func(instanceUUID, changeVersion string) *mo.VirtualMachine {
+		return &mo.VirtualMachine{
+			Config: &types.VirtualMachineConfigInfo{
+				InstanceUuid:  instanceUUID,
+				ChangeVersion: changeVersion,
+			},
+		}
+	}
+	cases := []struct {
+		name     string
+		props    *mo.VirtualMachine
+		err      error
+		previous bool
+		expected bool
+	}{
+		{"not drifted", source("instance-uuid", "1"), nil, false, false},
+		{"changed", source("instance-uuid", "2"), nil, false, true},
+		{"replaced", source("other-instance-uuid", "1"), nil, false, true},
+		{"drift cleared", source("instance-uuid", "1"), nil, true, false},
+		{"source gone", nil, &virtualmachine.UUIDNotFoundError{}, false, true},
+		{"permission error, not drifted before", nil, errors.New("NoPermission"), false, false},
+		{"permission error, drifted before", nil, errors.New("NoPermission"), true, true},
+	}
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, resourceVSphereVirtualMachine().Schema, map[string]interface{}{
+			"clone": []interface{}{
+				map[string]interface{}{"template_uuid": "template-uuid"},
+			},
+		})
+		d.Set("source_template_instance_uuid", "instance-uuid")
+		d.Set("source_template_change_version", "1")
+		d.Set("source_drifted", tc.previous)
+		resourceVSphereVirtualMachineSetSourceDrift(d, tc.props, tc.err)
+		if actual := d.Get("source_drifted").(bool); actual != tc.expected {
+			t.Fatalf("%s: expected source_drifted to be %t, got %t", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestResourceVSphereVirtualMachineDeleteDeletionProtection(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceVSphereVirtualMachine().Schema, map[string]interface{}{
+		"deletion_protection": true,
+	})
+	d.SetId("42216f3a-bc0f-7e6b-2a3e-6c4a2c3d7b19")
+	// The client is never used, as the delete is refused first.
+	err := resourceVSphereVirtualMachineDelete(d, &VSphereClient{})
+	if err == nil || !strings.Contains(err.Error(), "deletion_protection") {
+		t.Fatalf("expected delete to be refused because of deletion_protection, got %v", err)
+	}
+	if d.Id() == "" {
+		t.Fatal("expected the virtual machine to stay in state")
+	}
+}
+
+func TestResourceVSphereVirtualMachineDestroyMode(t *testing.T) {
+	cases := []struct {
+		name                string
+		deletionProtection  bool
+		unregisterOnDestroy bool
+		softDestroy         bool
+		expected            virtualMachineDestroyMode
+		expectedErr         bool
+	}{
+		{"default", false, false, false, virtualMachineDestroyModeDestroy, false},
+		{"unregister_on_destroy", false, true, false, virtualMachineDestroyModeUnregister, false},
+		{"soft destroy", false, false, true, virtualMachineDestroyModeSoftDestroy, false},
+		{"unregister_on_destroy with soft destroy", false, true, true, virtualMachineDestroyModeUnregister, false},
+		{"deletion_protection", true, false, false, "", true},
+		{"deletion_protection with unregister_on_destroy", true, true, false, "", true},
+		{"deletion_protection with soft destroy", true, false, true, "", true},
+	}
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, resourceVSphereVirtualMachine().Schema, map[string]interface{}{
+			"deletion_protection":   tc.deletionProtection,
+			"unregister_on_destroy": tc.unregisterOnDestroy,
+		})
+		var sd *softDestroyOptions
+		if tc.softDestroy {
+			sd = &softDestroyOptions{Folder: "terraform-quarantine"}
+		}
+		actual, err := resourceVSphereVirtualMachineDestroyMode(d, sd)
+		switch {
+		case tc.expectedErr && err == nil:
+			t.Fatalf("%q: expected error, got none", tc.name)
+		case !tc.expectedErr && err != nil:
+			t.Fatalf("%q: expected no error, got %s", tc.name, err)
+		case actual != tc.expected:
+			t.Fatalf("%q: expected mode %q, got %q", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestVirtualMachinePreUpdateSnapshotsToRemove(t *testing.T) {
+	now := time.Now()
+	snapshots := []types.VirtualMachineSnapshotTree{
+		{Name: virtualMachinePreUpdateSnapshotPrefix + "2", CreateTime: now.Add(-2 * time.Hour)},
+		{Name: "manual", CreateTime: now.Add(-4 * time.Hour)},
+		{Name: virtualMachinePreUpdateSnapshotPrefix + "3", CreateTime: now.Add(-1 * time.Hour)},
+		{Name: virtualMachinePreUpdateSnapshotPrefix + "1", CreateTime: now.Add(-3 * time.Hour)},
+	}
+	cases := []struct {
+		keep     int
+		expected []string
+	}{
+		{0, []string{virtualMachinePreUpdateSnapshotPrefix + "1", virtualMachinePreUpdateSnapshotPrefix + "2", virtualMachinePreUpdateSnapshotPrefix + "3"}},
+		{1, []string{virtualMachinePreUpdateSnapshotPrefix + "1", virtualMachinePreUpdateSnapshotPrefix + "2"}},
+		{3, nil},
+		{5, nil},
+	}
+	for _, tc := range cases {
+		var actual []string
+		for _, snapshot := range virtualMachinePreUpdateSnapshotsToRemove(snapshots, tc.keep) {
+			actual = append(actual, snapshot.Name)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("keep %d: expected %q, got %q", tc.keep, tc.expected, actual)
+		}
+	}
+}
+
+func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
+	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
+	// speeds up tests greatly, but it's not a necessary variable, so we don't
+	// enforce it here.
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL_PXE") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL_PXE to run vsphere_virtual_machine acceptance tests")
+	}
 	if os.Getenv("VSPHERE_IPV4_ADDRESS") == "" {
 		t.Skip("set VSPHERE_IPV4_ADDRESS to run vsphere_virtual_machine acceptance tests")
 	}
-	if os.Getenv("VSPHERE_IPV4_PREFIX") == "" {
-		t.Skip("set VSPHERE_IPV4_PREFIX to run vsphere_virtual_machine acceptance tests")
+	if os.Getenv("VSPHERE_IPV4_PREFIX") == "" {
+		t.Skip("set VSPHERE_IPV4_PREFIX to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_IPV4_GATEWAY") == "" {
+		t.Skip("set VSPHERE_IPV4_GATEWAY to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DNS") == "" {
+		t.Skip("set VSPHERE_DNS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE2") == "" {
+		t.Skip("set VSPHERE_DATASTORE2 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_ISO_TRANSPORT to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_WINDOWS") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_WINDOWS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE_COREOS") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_COREOS to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST2") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST2 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST3") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST3 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DS_VMFS_DISK0") == "" {
+		t.Skip("set VSPHERE_DS_VMFS_DISK0 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DS_VMFS_DISK1") == "" {
+		t.Skip("set VSPHERE_DS_VMFS_DISK1 to run vsphere_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DS_VMFS_DISK2") == "" {
+		t.Skip("set VSPHERE_DS_VMFS_DISK2 to run vsphere_virtual_machine acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		_, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			if ok, _ := regexp.MatchString("virtual machine with UUID \"[-a-f0-9]+\" not found", err.Error()); ok && !expected {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return errors.New("expected VM to be missing")
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineVMXLocation is the location of the
+// configuration file of a virtual machine, saved for checks that run after
+// the virtual machine is removed from state.
+type testAccResourceVSphereVirtualMachineVMXLocation struct {
+	datastoreID string
+	path        string
+}
+
+// testAccResourceVSphereVirtualMachineSaveVMXLocation saves the location of
+// the configuration file of the test virtual machine.
+func testAccResourceVSphereVirtualMachineSaveVMXLocation(vmx *testAccResourceVSphereVirtualMachineVMXLocation) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_virtual_machine.vm"]
+		if !ok {
+			return errors.New("vsphere_virtual_machine.vm not found in state")
+		}
+		vmx.datastoreID = rs.Primary.Attributes["vmx_datastore_id"]
+		vmx.path = rs.Primary.Attributes["vmx_path"]
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckUnregistered checks that the test
+// virtual machine is no longer in the inventory and that its configuration
+// file was left on the datastore, and then deletes its files.
+func testAccResourceVSphereVirtualMachineCheckUnregistered(vmx *testAccResourceVSphereVirtualMachineVMXLocation) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if err := testAccResourceVSphereVirtualMachineCheckExists(false)(s); err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		ds, err := datastore.FromID(client, vmx.datastoreID)
+		if err != nil {
+			return err
+		}
+		exists, err := datastore.FileExists(ds, vmx.path)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("expected %q to be left on the datastore", vmx.path)
+		}
+		return datastore.DeleteFile(client, ds, path.Dir(vmx.path))
+	}
+}
+
+func testAccResourceVSphereVirtualMachineCheckVAppConfigKey(key, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Config.VAppConfig.GetVmConfigInfo().Property
+		for _, prop := range actual {
+			if prop.Id == key && prop.Value != value {
+				return fmt.Errorf("expected vAppConfig property %s to have value %s, got %s", key, value, prop.Value)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckPowerState is a check to check for
+// a VirtualMachine's power state.
+func testAccResourceVSphereVirtualMachineCheckPowerState(expected types.VirtualMachinePowerState) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Runtime.PowerState
+		if expected != actual {
+			return fmt.Errorf("expected power state to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckHostname is a check to check for a
+// VirtualMachine's hostname. The check uses guest info, so VMware tools needs
+// to be installed.
+func testAccResourceVSphereVirtualMachineCheckHostname(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Guest.HostName
+		if expected != actual {
+			return fmt.Errorf("expected hostname to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraDisks is a check for proper
+// parameters on the vsphere_virtual_machine extra disks test. This is a very
+// specific check that checks for the specific disk devices and respective
+// backings, and expects them in the exact order outlined in the function.
+func testAccResourceVSphereVirtualMachineCheckExtraDisks() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		var foundEager, foundLazy, foundThin bool
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					var eager bool
+					if info.EagerlyScrub != nil {
+						eager = *info.EagerlyScrub
+					}
+					switch {
+					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameEager) && eager:
+						foundEager = true
+					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameLazy) && !eager:
+						foundLazy = true
+					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameThin) && *info.ThinProvisioned:
+						foundThin = true
+					}
+				}
+			}
+		}
+
+		if !foundEager {
+			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameEager)
+		}
+		if !foundLazy {
+			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameLazy)
+		}
+		if !foundThin {
+			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameThin)
+		}
+
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskBus is a check that looks for a
+// disk with a specific name at a specific SCSI bus number and unit number.
+func testAccResourceVSphereVirtualMachineCheckDiskBus(name string, expectedBus, expectedUnit int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					dp := new(object.DatastorePath)
+					if ok := dp.FromString(info.FileName); !ok {
+						return fmt.Errorf("could not parse datastore path %q", info.FileName)
+					}
+					if path.Base(dp.Path) != name {
+						continue
+					}
+					l := object.VirtualDeviceList(props.Config.Hardware.Device)
+					ctlr := l.FindByKey(disk.ControllerKey)
+					if ctlr == nil {
+						return fmt.Errorf("could not find controller with key %d for disk %q", disk.ControllerKey, name)
+					}
+					sc, ok := ctlr.(types.BaseVirtualSCSIController)
+					if !ok {
+						return fmt.Errorf("disk %q not attached to a SCSI controller (actual: %T)", name, ctlr)
+					}
+					if sc.GetVirtualSCSIController().BusNumber != int32(expectedBus) {
+						return fmt.Errorf("disk %q: Expected controller bus to be %d, got %d", name, expectedBus, sc.GetVirtualSCSIController().BusNumber)
+					}
+					if disk.UnitNumber == nil {
+						return fmt.Errorf("disk %q has no unit number", name)
+					}
+					if *disk.UnitNumber != int32(expectedUnit) {
+						return fmt.Errorf("disk %q: Expected unit number to be %d, got %d", name, expectedUnit, *disk.UnitNumber)
+					}
+					return nil
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find disk path %q", name)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckFolder checks to make sure a
+// virtual machine's folder matches the folder supplied with expected.
+func testAccResourceVSphereVirtualMachineCheckFolder(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		expected, err := folder.RootPathParticleVM.PathFromNewRoot(vm.InventoryPath, folder.RootPathParticleVM, expected)
+		actual := path.Dir(vm.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckFolderRemoved checks that the VM
+// folder at the supplied path in the test datacenter no longer exists.
+func testAccResourceVSphereVirtualMachineCheckFolderRemoved(relative string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		p := fmt.Sprintf("/%s/vm/%s", os.Getenv("VSPHERE_DATACENTER"), relative)
+		_, err := folder.FromAbsolutePath(client, p)
+		if err == nil {
+			return fmt.Errorf("expected folder %s to be removed", p)
+		}
+		if _, ok := err.(*find.NotFoundError); !ok {
+			return fmt.Errorf("bad: %s", err)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExistingVmdk is a check to make
+// sure that the appropriate disk is attached in the existing VMDK test.
+func testAccResourceVSphereVirtualMachineCheckExistingVmdk() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		expected := testAccResourceVSphereVirtualMachineDiskNameExtraVmdk
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					if strings.HasSuffix(info.FileName, expected) {
+						return nil
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find attached disk matching %q", expected)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckCPUMem checks the CPU and RAM for a
+// VM.
+func testAccResourceVSphereVirtualMachineCheckCPUMem(expectedCPU, expectedMem int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actualCPU := props.Summary.Config.NumCpu
+		actualMem := props.Summary.Config.MemorySizeMB
+		if expectedCPU != actualCPU {
+			return fmt.Errorf("expected CPU count to be %d, got %d", expectedCPU, actualCPU)
+		}
+		if expectedMem != actualMem {
+			return fmt.Errorf("expected memory size to be %d, got %d", expectedMem, actualMem)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckPreUpdateSnapshots checks the
+// number of snapshots taken by snapshot_before_disruptive_update that the
+// virtual machine has.
+func testAccResourceVSphereVirtualMachineCheckPreUpdateSnapshots(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var actual int
+		for _, snapshot := range virtualmachine.Snapshots(props) {
+			if strings.HasPrefix(snapshot.Name, virtualMachinePreUpdateSnapshotPrefix) {
+				actual++
+			}
+		}
+		if actual != expected {
+			return fmt.Errorf("expected %d pre-update snapshots, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckNet checks to make sure a virtual
+// machine's primary NIC has the given IP address and netmask assigned to it,
+// and that the appropriate gateway is present.
+//
+// This uses VMware tools to check this, so it needs to be installed on the
+// guest.
+func testAccResourceVSphereVirtualMachineCheckNet(expectedAddr, expectedPrefix, expectedGW string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		res, err := strconv.Atoi(expectedPrefix)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		expectedPrefixInt := int32(res)
+
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var v4gw, v6gw net.IP
+		for _, s := range props.Guest.IpStack {
+			if s.IpRouteConfig != nil {
+				for _, r := range s.IpRouteConfig.IpRoute {
+					switch r.Network {
+					case "0.0.0.0":
+						v4gw = net.ParseIP(r.Gateway.IpAddress)
+					case "::":
+						v6gw = net.ParseIP(r.Gateway.IpAddress)
+					}
+				}
+			}
+		}
+		for _, n := range props.Guest.Net {
+			if n.IpConfig != nil {
+				for _, addr := range n.IpConfig.IpAddress {
+					ip := net.ParseIP(addr.IpAddress)
+					if !ip.Equal(net.ParseIP(expectedAddr)) {
+						continue
+					}
+					if addr.PrefixLength != expectedPrefixInt {
+						continue
+					}
+					var mask net.IPMask
+					if ip.To4() != nil {
+						mask = net.CIDRMask(int(addr.PrefixLength), 32)
+					} else {
+						mask = net.CIDRMask(int(addr.PrefixLength), 128)
+					}
+					switch {
+					case ip.Mask(mask).Equal(v4gw.Mask(mask)):
+						if net.ParseIP(expectedGW).Equal(v4gw) {
+							return nil
+						}
+					case ip.Mask(mask).Equal(v6gw.Mask(mask)):
+						if net.ParseIP(expectedGW).Equal(v6gw) {
+							return nil
+						}
+					}
+				}
+			}
+		}
+
+		return fmt.Errorf("could not find IP %s/%s, gateway %s", expectedAddr, expectedPrefix, expectedGW)
+	}
+}
+
+func testAccResourceVSpherevirtualMachineCheckHostname(hostname string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Guest.HostName != hostname {
+			return fmt.Errorf("expected host name: %s, got %s", hostname, props.Guest.HostName)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_IPV4_GATEWAY") == "" {
-		t.Skip("set VSPHERE_IPV4_GATEWAY to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckStaticMACAddr is a check to look
+// for the MAC address defined in the static MAC address test on the first
+// network interface.
+func testAccResourceVSphereVirtualMachineCheckStaticMACAddr() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		l := object.VirtualDeviceList(props.Config.Hardware.Device)
+		devices := l.Select(func(device types.BaseVirtualDevice) bool {
+			if _, ok := device.(types.BaseVirtualEthernetCard); ok {
+				return true
+			}
+			return false
+		})
+		if devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().AddressType != string(types.VirtualEthernetCardMacTypeManual) {
+			return fmt.Errorf("first network interface is not set to manual address type")
+		}
+		actual := devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
+		expected := testAccResourceVSphereVirtualMachineStaticMacAddr
+		if expected != actual {
+			return fmt.Errorf("expected MAC address to be %q, got %q", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_DNS") == "" {
-		t.Skip("set VSPHERE_DNS to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckAnnotation is a check to ensure
+// that a VM's annotation is correctly set in the annotation test.
+func testAccResourceVSphereVirtualMachineCheckAnnotation() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		expected := testAccResourceVSphereVirtualMachineAnnotation
+		actual := props.Config.Annotation
+		if expected != actual {
+			return fmt.Errorf("expected annotation to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckManagedAnnotation is a check to
+// ensure that a VM's annotation contains the interpolated managed section from
+// the managed annotation test, after the supplied manual notes.
+func testAccResourceVSphereVirtualMachineCheckManagedAnnotation(manual string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		expected := joinManagedAnnotation(manual, "Managed by Terraform: terraform-test")
+		actual := props.Config.Annotation
+		if expected != actual {
+			return fmt.Errorf("expected annotation to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckHardwareVersion is a check to
+// ensure that a VM's hardware version matches the supplied version key.
+func testAccResourceVSphereVirtualMachineCheckHardwareVersion(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := props.Config.Version
+		if expected != actual {
+			return fmt.Errorf("expected hardware version to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckMemoryReservationLocked is a check
+// to ensure that a VM's memory reservation is locked to its memory size.
+func testAccResourceVSphereVirtualMachineCheckMemoryReservationLocked() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Config.MemoryReservationLockedToMax == nil || !*props.Config.MemoryReservationLockedToMax {
+			return errors.New("expected memory reservation to be locked to max")
+		}
+		expected := int64(props.Config.Hardware.MemoryMB)
+		if actual := props.Config.MemoryAllocation.Reservation; actual == nil || *actual != expected {
+			return fmt.Errorf("expected memory reservation to be %d, got %v", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
+// to ensure that events have been received for customization success on a VM.
+func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
+		if err != nil {
+			return err
+		}
+		if len(actual) < 1 {
+			return errors.New("customization success event was not received")
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
+// tags that have been created with supplied resource name have been attached
+// to the virtual machine.
+func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, vm, tagResName)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckMultiDevice is a check for proper
+// parameters on the vsphere_virtual_machine multi-device test. This is a very
+// specific check that checks for the specific disk and network devices. The
+// configuration that this test asserts should be in the
+// testAccResourceVSphereVirtualMachineConfigMultiDevice resource.
+func testAccResourceVSphereVirtualMachineCheckMultiDevice(expectedD, expectedN []bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		actualD := make([]bool, 3)
+		actualN := make([]bool, 3)
+		expectedDisk0Size := structure.GiBToByte(20)
+		expectedDisk1Size := structure.GiBToByte(10)
+		expectedDisk2Size := structure.GiBToByte(5)
+		expectedNet0Level := types.SharesLevelNormal
+		expectedNet1Level := types.SharesLevelHigh
+		expectedNet2Level := types.SharesLevelLow
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				switch {
+				case disk.CapacityInBytes == expectedDisk0Size:
+					actualD[0] = true
+				case disk.CapacityInBytes == expectedDisk1Size:
+					actualD[1] = true
+				case disk.CapacityInBytes == expectedDisk2Size:
+					actualD[2] = true
+				}
+			}
+			if bvec, ok := dev.(types.BaseVirtualEthernetCard); ok {
+				card := bvec.GetVirtualEthernetCard()
+				switch {
+				case card.ResourceAllocation.Share.Level == expectedNet0Level:
+					actualN[0] = true
+				case card.ResourceAllocation.Share.Level == expectedNet1Level:
+					actualN[1] = true
+				case card.ResourceAllocation.Share.Level == expectedNet2Level:
+					actualN[2] = true
+				}
+			}
+		}
+
+		for n, actual := range actualD {
+			if actual != expectedD[n] {
+				return fmt.Errorf("expected disk at index %d to be %t, got %t", n, expectedD[n], actual)
+			}
+		}
+		for n, actual := range actualN {
+			if actual != expectedN[n] {
+				return fmt.Errorf("expected network interface at index %d to be %t, got %t", n, expectedN[n], actual)
+			}
+		}
+
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckIsoCdrom checks to make sure that the
+// subject VM has a CDROM device configured with iso backing and is connected.
+func testAccResourceVSphereVirtualMachineCheckIsoCdrom() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		for _, dev := range props.Config.Hardware.Device {
+			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
+				if !cdrom.Connectable.Connected {
+					return fmt.Errorf("expected CDROM device to be connected")
+				}
+				if backing, ok := cdrom.Backing.(*types.VirtualCdromIsoBackingInfo); ok {
+					expected := &object.DatastorePath{
+						Datastore: os.Getenv("VSPHERE_ISO_DATASTORE"),
+						Path:      os.Getenv("VSPHERE_ISO_FILE"),
+					}
+					actual := new(object.DatastorePath)
+					actual.FromString(backing.FileName)
+					if !reflect.DeepEqual(expected, actual) {
+						return fmt.Errorf("expected %#v, got %#v", expected, actual)
+					}
+					return nil
+				}
+				return errors.New("could not locate proper backing file on CDROM device")
+			}
+		}
+		return errors.New("could not locate CDROM device on VM")
 	}
-	if os.Getenv("VSPHERE_DATASTORE") == "" {
-		t.Skip("set VSPHERE_DATASTORE to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckClientCdrom checks to make sure that the
+// subject VM has a CDROM device mapped to a client device.
+func testAccResourceVSphereVirtualMachineCheckClientCdrom() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		for _, dev := range props.Config.Hardware.Device {
+			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
+				if backing, ok := cdrom.Backing.(*types.VirtualCdromRemoteAtapiBackingInfo); ok {
+					useAutoDetect := false
+					expected := &types.VirtualCdromRemoteAtapiBackingInfo{
+						VirtualDeviceRemoteDeviceBackingInfo: types.VirtualDeviceRemoteDeviceBackingInfo{
+							UseAutoDetect: &useAutoDetect,
+							DeviceName:    "",
+						},
+					}
+					if !reflect.DeepEqual(expected, backing) {
+						return fmt.Errorf("expected %#v, got %#v", expected, backing)
+					}
+					return nil
+				}
+				return errors.New("could not find CDROM with correct backing device")
+			}
+		}
+		return errors.New("could not locate CDROM device on VM")
 	}
-	if os.Getenv("VSPHERE_DATASTORE2") == "" {
-		t.Skip("set VSPHERE_DATASTORE2 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckPowerOffEvent is a check to see if
+// the VM has been powered off at any point in time.
+func testAccResourceVSphereVirtualMachineCheckPowerOffEvent(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeVmPoweredOffEvent})
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(actual) < 1 && expected:
+			return errors.New("expected power off, VM was not powered off")
+		case len(actual) > 1 && !expected:
+			return errors.New("VM was powered off when it should not have been")
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_TEMPLATE") == "" {
-		t.Skip("set VSPHERE_TEMPLATE to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskSize checks the first
+// VirtualDisk it encounters for a specific size in GiB. It should only be used
+// with test configurations with a single disk attached.
+func testAccResourceVSphereVirtualMachineCheckDiskSize(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		expectedBytes := structure.GiBToByte(expected)
+
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if expectedBytes != disk.CapacityInBytes {
+					return fmt.Errorf("expected disk size to be %d, got %d", expectedBytes, disk.CapacityInBytes)
+				}
+			}
+		}
+
+		return nil
 	}
-	if os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_ISO_TRANSPORT to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckSCSIBus checks to make sure the
+// test VM's SCSI bus is all of the specified SCSI type.
+func testAccResourceVSphereVirtualMachineCheckSCSIBus(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		actual, err := testGetVirtualMachineSCSIBusState(s, "vm")
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected SCSI bus to be %s, got %s", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_TEMPLATE_WINDOWS") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_WINDOWS to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckHost checks to make sure the
+// test VM is currently located on a specific host.
+func testAccResourceVSphereVirtualMachineCheckHost(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		hs, err := testGetVirtualMachineHost(s, "vm")
+		if err != nil {
+			return err
+		}
+		actual := hs.Name()
+		if expected != actual {
+			return fmt.Errorf("expected host to be %s, got %s", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_TEMPLATE_COREOS") == "" {
-		t.Skip("set VSPHERE_TEMPLATE_COREOS to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckResourcePool checks to make sure the
+// test VM is currently located in a specific resource pool.
+func testAccResourceVSphereVirtualMachineCheckResourcePool(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetVirtualMachineResourcePool(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		actual := pool.Name()
+		if actual == "Resources" && path.Base(expected) == "Resources" {
+			client := testAccProvider.Meta().(*VSphereClient).vimClient
+			expectedCluster, err := computeresource.BaseFromPath(client, path.Dir(expected), nil)
+			if err != nil {
+				return err
+			}
+			pprops, err := resourcepool.Properties(pool)
+			if err != nil {
+				return err
+			}
+			actualCluster, err := computeresource.BaseFromReference(client, *pprops.Parent)
+			if err != nil {
+				return err
+			}
+			if expectedCluster.Reference().Value != actualCluster.Reference().Value {
+				return fmt.Errorf("expected default resource pool of %q, got default resource pool of %q", expectedCluster.Reference().Value, actualCluster.Reference().Value)
+			}
+			return nil
+		}
+		if expected != actual {
+			return fmt.Errorf("expected resource pool or to be %q, got %q", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraConfig checks a key/expected
+// value combination in a VM's config.
+func testAccResourceVSphereVirtualMachineCheckExtraConfig(key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, bov := range props.Config.ExtraConfig {
+			ov := bov.GetOptionValue()
+			if ov.Key == key {
+				if ov.Value.(string) == expected {
+					return nil
+				}
+				return fmt.Errorf("expected key %s to be %s, got %s", key, expected, ov.Value.(string))
+			}
+		}
+		return fmt.Errorf("key %s not found", key)
 	}
-	if os.Getenv("VSPHERE_ESXI_HOST2") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST2 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing checks to
+// make sure that a key is missing in the VM's extraConfig.
+func testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing(key string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, bov := range props.Config.ExtraConfig {
+			ov := bov.GetOptionValue()
+			if ov.Key == key {
+				return fmt.Errorf("expected key %s to be missing", key)
+			}
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_ESXI_HOST3") == "" {
-		t.Skip("set VSPHERE_ESXI_HOST3 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckVmxDatastore checks the datastore
+// that the virtual machine's configuration is currently located.
+func testAccResourceVSphereVirtualMachineCheckVmxDatastore(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var dsPath object.DatastorePath
+		if ok := dsPath.FromString(props.Config.Files.VmPathName); !ok {
+			return fmt.Errorf("could not parse datastore path %q", props.Config.Files.VmPathName)
+		}
+		actual := dsPath.Datastore
+		if expected != actual {
+			return fmt.Errorf("expected VM configuration to be in datastore %s, got %s", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
-		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckVmdkDatastore checks the datastore
+// that a specific VMDK file is in.
+func testAccResourceVSphereVirtualMachineCheckVmdkDatastore(name, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, dev := range props.Config.Hardware.Device {
+			if disk, ok := dev.(*types.VirtualDisk); ok {
+				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+					var dsPath object.DatastorePath
+					if ok := dsPath.FromString(info.FileName); !ok {
+						return fmt.Errorf("could not parse datastore path %q", info.FileName)
+					}
+					if path.Base(dsPath.Path) == name {
+						actual := dsPath.Datastore
+						if expected == actual {
+							return nil
+						}
+						return fmt.Errorf("expected disk name %q to be on datastore %q, got %q", name, expected, actual)
+					}
+				}
+			}
+		}
+		return fmt.Errorf("could not find disk %q", name)
 	}
-	if os.Getenv("VSPHERE_DS_VMFS_DISK0") == "" {
-		t.Skip("set VSPHERE_DS_VMFS_DISK0 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testAccResourceVSphereVirtualMachineCheckNICCount checks the number of NICs
+// on the virtual machine.
+func testAccResourceVSphereVirtualMachineCheckNICCount(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+
+		var actual int
+		for _, dev := range props.Config.Hardware.Device {
+			if _, ok := dev.(types.BaseVirtualEthernetCard); ok {
+				actual++
+			}
+		}
+		if expected != actual {
+			return fmt.Errorf("expected %d number of NICs, got %d", expected, actual)
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_DS_VMFS_DISK1") == "" {
-		t.Skip("set VSPHERE_DS_VMFS_DISK1 to run vsphere_virtual_machine acceptance tests")
+}
+
+// testCheckVMDiskFileExists takes a VMDK filename and checks to see if it
+// exists within the same directory as the virtual machine's VMX file.
+func testCheckVMDiskFileExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+		if err != nil {
+			return err
+		}
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		vmxPath, success := virtualdisk.DatastorePathFromString(props.Config.Files.VmPathName)
+		if !success {
+			return fmt.Errorf("could not parse VMX path %q", props.Config.Files.VmPathName)
+		}
+		dcp, err := folder.RootPathParticleVM.SplitDatacenter(vm.InventoryPath)
+		if err != nil {
+			return err
+		}
+		dc, err := getDatacenter(tVars.client, dcp)
+		if err != nil {
+			return err
+		}
+		ds, err := datastore.FromPath(tVars.client, vmxPath.Datastore, dc)
+		if err != nil {
+			return err
+		}
+		p := path.Join(path.Dir(vmxPath.Path), name)
+		exists, err := datastore.FileExists(ds, p)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("file %q does not exist in datastore %q", p, ds.Name())
+		}
+		return nil
 	}
-	if os.Getenv("VSPHERE_DS_VMFS_DISK2") == "" {
-		t.Skip("set VSPHERE_DS_VMFS_DISK2 to run vsphere_virtual_machine acceptance tests")
+}
+
+func testAccResourceVSphereVirtualMachineCheckCustomAttributes() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		return testResourceHasCustomAttributeValues(s, "vsphere_virtual_machine", "vm", props.Entity())
 	}
 }
 
-func testAccResourceVSphereVirtualMachineCheckExists(expected bool) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		_, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			if ok, _ := regexp.MatchString("virtual machine with UUID \"[-a-f0-9]+\" not found", err.Error()); ok && !expected {
-				// Expected missing
-				return nil
-			}
-			return err
-		}
-		if !expected {
-			return errors.New("expected VM to be missing")
-		}
-		return nil
-	}
+func testAccResourceVSphereVirtualMachineConfigBasic() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigUnregisterOnDestroy() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  unregister_on_destroy = true
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigBasicESXiOnly() string {
+	return fmt.Sprintf(`
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigMultiDevice() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
 }
 
-func testAccResourceVSphereVirtualMachineCheckVAppConfigKey(key, value string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Config.VAppConfig.GetVmConfigInfo().Property
-		for _, prop := range actual {
-			if prop.Id == key && prop.Value != value {
-				return fmt.Errorf("expected vAppConfig property %s to have value %s, got %s", key, value, prop.Value)
-			}
-		}
-		return nil
-	}
+variable "resource_pool" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckPowerState is a check to check for
-// a VirtualMachine's power state.
-func testAccResourceVSphereVirtualMachineCheckPowerState(expected types.VirtualMachinePowerState) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Runtime.PowerState
-		if expected != actual {
-			return fmt.Errorf("expected power state to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "network_label" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckHostname is a check to check for a
-// VirtualMachine's hostname. The check uses guest info, so VMware tools needs
-// to be installed.
-func testAccResourceVSphereVirtualMachineCheckHostname(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := props.Guest.HostName
-		if expected != actual {
-			return fmt.Errorf("expected hostname to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "datastore" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExtraDisks is a check for proper
-// parameters on the vsphere_virtual_machine extra disks test. This is a very
-// specific check that checks for the specific disk devices and respective
-// backings, and expects them in the exact order outlined in the function.
-func testAccResourceVSphereVirtualMachineCheckExtraDisks() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
 
-		var foundEager, foundLazy, foundThin bool
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					var eager bool
-					if info.EagerlyScrub != nil {
-						eager = *info.EagerlyScrub
-					}
-					switch {
-					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameEager) && eager:
-						foundEager = true
-					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameLazy) && !eager:
-						foundLazy = true
-					case strings.HasSuffix(info.FileName, testAccResourceVSphereVirtualMachineDiskNameThin) && *info.ThinProvisioned:
-						foundThin = true
-					}
-				}
-			}
-		}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		if !foundEager {
-			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameEager)
-		}
-		if !foundLazy {
-			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameLazy)
-		}
-		if !foundThin {
-			return fmt.Errorf("could not locate disk: %s", testAccResourceVSphereVirtualMachineDiskNameThin)
-		}
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		return nil
-	}
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "normal"
+  }
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "high"
+  }
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "low"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  disk {
+    label       = "disk1"
+    unit_number = 1
+    size        = 10
+  }
+
+  disk {
+    label       = "disk2"
+    unit_number = 2
+    size        = 5
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigMultiHighBus() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  scsi_controller_count = 3
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "normal"
+  }
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "high"
+  }
+
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "low"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  disk {
+    label       = "disk1"
+    unit_number = 15
+    size        = 10
+  }
+
+  disk {
+    label       = "disk2"
+    unit_number = 31
+    size        = 5
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
 }
 
-// testAccResourceVSphereVirtualMachineCheckDiskBus is a check that looks for a
-// disk with a specific name at a specific SCSI bus number and unit number.
-func testAccResourceVSphereVirtualMachineCheckDiskBus(name string, expectedBus, expectedUnit int) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					dp := new(object.DatastorePath)
-					if ok := dp.FromString(info.FileName); !ok {
-						return fmt.Errorf("could not parse datastore path %q", info.FileName)
-					}
-					if path.Base(dp.Path) != name {
-						continue
-					}
-					l := object.VirtualDeviceList(props.Config.Hardware.Device)
-					ctlr := l.FindByKey(disk.ControllerKey)
-					if ctlr == nil {
-						return fmt.Errorf("could not find controller with key %d for disk %q", disk.ControllerKey, name)
-					}
-					sc, ok := ctlr.(types.BaseVirtualSCSIController)
-					if !ok {
-						return fmt.Errorf("disk %q not attached to a SCSI controller (actual: %T)", name, ctlr)
-					}
-					if sc.GetVirtualSCSIController().BusNumber != int32(expectedBus) {
-						return fmt.Errorf("disk %q: Expected controller bus to be %d, got %d", name, expectedBus, sc.GetVirtualSCSIController().BusNumber)
-					}
-					if disk.UnitNumber == nil {
-						return fmt.Errorf("disk %q has no unit number", name)
-					}
-					if *disk.UnitNumber != int32(expectedUnit) {
-						return fmt.Errorf("disk %q: Expected unit number to be %d, got %d", name, expectedUnit, *disk.UnitNumber)
-					}
-					return nil
-				}
-			}
-		}
+func testAccResourceVSphereVirtualMachineConfigRemoveMiddle() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
 
-		return fmt.Errorf("could not find disk path %q", name)
-	}
+variable "resource_pool" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckFolder checks to make sure a
-// virtual machine's folder matches the folder supplied with expected.
-func testAccResourceVSphereVirtualMachineCheckFolder(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		expected, err := folder.RootPathParticleVM.PathFromNewRoot(vm.InventoryPath, folder.RootPathParticleVM, expected)
-		actual := path.Dir(vm.InventoryPath)
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		if expected != actual {
-			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "network_label" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExistingVmdk is a check to make
-// sure that the appropriate disk is attached in the existing VMDK test.
-func testAccResourceVSphereVirtualMachineCheckExistingVmdk() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "datastore" {
+  default = "%s"
+}
 
-		expected := testAccResourceVSphereVirtualMachineDiskNameExtraVmdk
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					if strings.HasSuffix(info.FileName, expected) {
-						return nil
-					}
-				}
-			}
-		}
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		return fmt.Errorf("could not find attached disk matching %q", expected)
-	}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckCPUMem checks the CPU and RAM for a
-// VM.
-func testAccResourceVSphereVirtualMachineCheckCPUMem(expectedCPU, expectedMem int32) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		actualCPU := props.Summary.Config.NumCpu
-		actualMem := props.Summary.Config.MemorySizeMB
-		if expectedCPU != actualCPU {
-			return fmt.Errorf("expected CPU count to be %d, got %d", expectedCPU, actualCPU)
-		}
-		if expectedMem != actualMem {
-			return fmt.Errorf("expected memory size to be %d, got %d", expectedMem, actualMem)
-		}
-		return nil
-	}
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckNet checks to make sure a virtual
-// machine's primary NIC has the given IP address and netmask assigned to it,
-// and that the appropriate gateway is present.
-//
-// This uses VMware tools to check this, so it needs to be installed on the
-// guest.
-func testAccResourceVSphereVirtualMachineCheckNet(expectedAddr, expectedPrefix, expectedGW string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		res, err := strconv.Atoi(expectedPrefix)
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		expectedPrefixInt := int32(res)
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		var v4gw, v6gw net.IP
-		for _, s := range props.Guest.IpStack {
-			if s.IpRouteConfig != nil {
-				for _, r := range s.IpRouteConfig.IpRoute {
-					switch r.Network {
-					case "0.0.0.0":
-						v4gw = net.ParseIP(r.Gateway.IpAddress)
-					case "::":
-						v6gw = net.ParseIP(r.Gateway.IpAddress)
-					}
-				}
-			}
-		}
-		for _, n := range props.Guest.Net {
-			if n.IpConfig != nil {
-				for _, addr := range n.IpConfig.IpAddress {
-					ip := net.ParseIP(addr.IpAddress)
-					if !ip.Equal(net.ParseIP(expectedAddr)) {
-						continue
-					}
-					if addr.PrefixLength != expectedPrefixInt {
-						continue
-					}
-					var mask net.IPMask
-					if ip.To4() != nil {
-						mask = net.CIDRMask(int(addr.PrefixLength), 32)
-					} else {
-						mask = net.CIDRMask(int(addr.PrefixLength), 128)
-					}
-					switch {
-					case ip.Mask(mask).Equal(v4gw.Mask(mask)):
-						if net.ParseIP(expectedGW).Equal(v4gw) {
-							return nil
-						}
-					case ip.Mask(mask).Equal(v6gw.Mask(mask)):
-						if net.ParseIP(expectedGW).Equal(v6gw) {
-							return nil
-						}
-					}
-				}
-			}
-		}
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
 
-		return fmt.Errorf("could not find IP %s/%s, gateway %s", expectedAddr, expectedPrefix, expectedGW)
-	}
-}
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "normal"
+  }
 
-func testAccResourceVSpherevirtualMachineCheckHostname(hostname string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		if props.Guest.HostName != hostname {
-			return fmt.Errorf("expected host name: %s, got %s", hostname, props.Guest.HostName)
-		}
-		return nil
-	}
-}
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "low"
+  }
 
-// testAccResourceVSphereVirtualMachineCheckStaticMACAddr is a check to look
-// for the MAC address defined in the static MAC address test on the first
-// network interface.
-func testAccResourceVSphereVirtualMachineCheckStaticMACAddr() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		l := object.VirtualDeviceList(props.Config.Hardware.Device)
-		devices := l.Select(func(device types.BaseVirtualDevice) bool {
-			if _, ok := device.(types.BaseVirtualEthernetCard); ok {
-				return true
-			}
-			return false
-		})
-		if devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().AddressType != string(types.VirtualEthernetCardMacTypeManual) {
-			return fmt.Errorf("first network interface is not set to manual address type")
-		}
-		actual := devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
-		expected := testAccResourceVSphereVirtualMachineStaticMacAddr
-		if expected != actual {
-			return fmt.Errorf("expected MAC address to be %q, got %q", expected, actual)
-		}
-		return nil
-	}
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  disk {
+    label       = "disk2"
+    unit_number = 2
+    size        = 5
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
 }
 
-// testAccResourceVSphereVirtualMachineCheckAnnotation is a check to ensure
-// that a VM's annotation is correctly set in the annotation test.
-func testAccResourceVSphereVirtualMachineCheckAnnotation() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		expected := testAccResourceVSphereVirtualMachineAnnotation
-		actual := props.Config.Annotation
-		if expected != actual {
-			return fmt.Errorf("expected annotation to be %q, got %q", expected, actual)
-		}
-		return nil
-	}
+func testAccResourceVSphereVirtualMachineConfigRemoveMiddleChangeUnit() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
-// to ensure that events have been received for customization success on a VM.
-func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		client := testAccProvider.Meta().(*VSphereClient).vimClient
-		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
-		if err != nil {
-			return err
-		}
-		if len(actual) < 1 {
-			return errors.New("customization success event was not received")
-		}
-		return nil
-	}
+variable "resource_pool" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
-// tags that have been created with supplied resource name have been attached
-// to the virtual machine.
-func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
-		if err != nil {
-			return err
-		}
-		return testObjectHasTags(s, tagsClient, vm, tagResName)
-	}
+variable "network_label" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckMultiDevice is a check for proper
-// parameters on the vsphere_virtual_machine multi-device test. This is a very
-// specific check that checks for the specific disk and network devices. The
-// configuration that this test asserts should be in the
-// testAccResourceVSphereVirtualMachineConfigMultiDevice resource.
-func testAccResourceVSphereVirtualMachineCheckMultiDevice(expectedD, expectedN []bool) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "datastore" {
+  default = "%s"
+}
 
-		actualD := make([]bool, 3)
-		actualN := make([]bool, 3)
-		expectedDisk0Size := structure.GiBToByte(20)
-		expectedDisk1Size := structure.GiBToByte(10)
-		expectedDisk2Size := structure.GiBToByte(5)
-		expectedNet0Level := types.SharesLevelNormal
-		expectedNet1Level := types.SharesLevelHigh
-		expectedNet2Level := types.SharesLevelLow
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				switch {
-				case disk.CapacityInBytes == expectedDisk0Size:
-					actualD[0] = true
-				case disk.CapacityInBytes == expectedDisk1Size:
-					actualD[1] = true
-				case disk.CapacityInBytes == expectedDisk2Size:
-					actualD[2] = true
-				}
-			}
-			if bvec, ok := dev.(types.BaseVirtualEthernetCard); ok {
-				card := bvec.GetVirtualEthernetCard()
-				switch {
-				case card.ResourceAllocation.Share.Level == expectedNet0Level:
-					actualN[0] = true
-				case card.ResourceAllocation.Share.Level == expectedNet1Level:
-					actualN[1] = true
-				case card.ResourceAllocation.Share.Level == expectedNet2Level:
-					actualN[2] = true
-				}
-			}
-		}
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		for n, actual := range actualD {
-			if actual != expectedD[n] {
-				return fmt.Errorf("expected disk at index %d to be %t, got %t", n, expectedD[n], actual)
-			}
-		}
-		for n, actual := range actualN {
-			if actual != expectedN[n] {
-				return fmt.Errorf("expected network interface at index %d to be %t, got %t", n, expectedN[n], actual)
-			}
-		}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
-		return nil
-	}
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckIsoCdrom checks to make sure that the
-// subject VM has a CDROM device configured with iso backing and is connected.
-func testAccResourceVSphereVirtualMachineCheckIsoCdrom() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-		for _, dev := range props.Config.Hardware.Device {
-			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
-				if !cdrom.Connectable.Connected {
-					return fmt.Errorf("expected CDROM device to be connected")
-				}
-				if backing, ok := cdrom.Backing.(*types.VirtualCdromIsoBackingInfo); ok {
-					expected := &object.DatastorePath{
-						Datastore: os.Getenv("VSPHERE_ISO_DATASTORE"),
-						Path:      os.Getenv("VSPHERE_ISO_FILE"),
-					}
-					actual := new(object.DatastorePath)
-					actual.FromString(backing.FileName)
-					if !reflect.DeepEqual(expected, actual) {
-						return fmt.Errorf("expected %#v, got %#v", expected, actual)
-					}
-					return nil
-				}
-				return errors.New("could not locate proper backing file on CDROM device")
-			}
-		}
-		return errors.New("could not locate CDROM device on VM")
-	}
-}
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
 
-// testAccResourceVSphereVirtualMachineCheckClientCdrom checks to make sure that the
-// subject VM has a CDROM device mapped to a client device.
-func testAccResourceVSphereVirtualMachineCheckClientCdrom() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "normal"
+  }
 
-		for _, dev := range props.Config.Hardware.Device {
-			if cdrom, ok := dev.(*types.VirtualCdrom); ok {
-				if backing, ok := cdrom.Backing.(*types.VirtualCdromRemoteAtapiBackingInfo); ok {
-					useAutoDetect := false
-					expected := &types.VirtualCdromRemoteAtapiBackingInfo{
-						VirtualDeviceRemoteDeviceBackingInfo: types.VirtualDeviceRemoteDeviceBackingInfo{
-							UseAutoDetect: &useAutoDetect,
-							DeviceName:    "",
-						},
-					}
-					if !reflect.DeepEqual(expected, backing) {
-						return fmt.Errorf("expected %#v, got %#v", expected, backing)
-					}
-					return nil
-				}
-				return errors.New("could not find CDROM with correct backing device")
-			}
-		}
-		return errors.New("could not locate CDROM device on VM")
-	}
-}
+  network_interface {
+    network_id            = "${data.vsphere_network.network.id}"
+    bandwidth_share_level = "low"
+  }
 
-// testAccResourceVSphereVirtualMachineCheckPowerOffEvent is a check to see if
-// the VM has been powered off at any point in time.
-func testAccResourceVSphereVirtualMachineCheckPowerOffEvent(expected bool) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		client := testAccProvider.Meta().(*VSphereClient).vimClient
-		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeVmPoweredOffEvent})
-		if err != nil {
-			return err
-		}
-		switch {
-		case len(actual) < 1 && expected:
-			return errors.New("expected power off, VM was not powered off")
-		case len(actual) > 1 && !expected:
-			return errors.New("VM was powered off when it should not have been")
-		}
-		return nil
-	}
-}
+  disk {
+    label = "disk0"
+    size  = 20
+  }
 
-// testAccResourceVSphereVirtualMachineCheckDiskSize checks the first
-// VirtualDisk it encounters for a specific size in GiB. It should only be used
-// with test configurations with a single disk attached.
-func testAccResourceVSphereVirtualMachineCheckDiskSize(expected int) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+  disk {
+    label       = "disk2"
+    unit_number = 1
+    size        = 5
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
+}
 
-		expectedBytes := structure.GiBToByte(expected)
+func testAccResourceVSphereVirtualMachineConfigIsoCdrom() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
 
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if expectedBytes != disk.CapacityInBytes {
-					return fmt.Errorf("expected disk size to be %d, got %d", expectedBytes, disk.CapacityInBytes)
-				}
-			}
-		}
+variable "resource_pool" {
+  default = "%s"
+}
 
-		return nil
-	}
+variable "network_label" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckSCSIBus checks to make sure the
-// test VM's SCSI bus is all of the specified SCSI type.
-func testAccResourceVSphereVirtualMachineCheckSCSIBus(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		actual, err := testGetVirtualMachineSCSIBusState(s, "vm")
-		if err != nil {
-			return fmt.Errorf("bad: %s", err)
-		}
-		if expected != actual {
-			return fmt.Errorf("expected SCSI bus to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "datastore" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckHost checks to make sure the
-// test VM is currently located on a specific host.
-func testAccResourceVSphereVirtualMachineCheckHost(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		hs, err := testGetVirtualMachineHost(s, "vm")
-		if err != nil {
-			return err
-		}
-		actual := hs.Name()
-		if expected != actual {
-			return fmt.Errorf("expected host to be %s, got %s", expected, actual)
-		}
-		return nil
-	}
+variable "iso_datastore" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckResourcePool checks to make sure the
-// test VM is currently located in a specific resource pool.
-func testAccResourceVSphereVirtualMachineCheckResourcePool(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		pool, err := testGetVirtualMachineResourcePool(s, "vm")
-		if err != nil {
-			return err
-		}
+variable "iso_path" {
+  default = "%s"
+}
 
-		actual := pool.Name()
-		if actual == "Resources" && path.Base(expected) == "Resources" {
-			client := testAccProvider.Meta().(*VSphereClient).vimClient
-			expectedCluster, err := computeresource.BaseFromPath(client, path.Dir(expected))
-			if err != nil {
-				return err
-			}
-			pprops, err := resourcepool.Properties(pool)
-			if err != nil {
-				return err
-			}
-			actualCluster, err := computeresource.BaseFromReference(client, *pprops.Parent)
-			if err != nil {
-				return err
-			}
-			if expectedCluster.Reference().Value != actualCluster.Reference().Value {
-				return fmt.Errorf("expected default resource pool of %q, got default resource pool of %q", expectedCluster.Reference().Value, actualCluster.Reference().Value)
-			}
-			return nil
-		}
-		if expected != actual {
-			return fmt.Errorf("expected resource pool or to be %q, got %q", expected, actual)
-		}
-		return nil
-	}
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExtraConfig checks a key/expected
-// value combination in a VM's config.
-func testAccResourceVSphereVirtualMachineCheckExtraConfig(key, expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		for _, bov := range props.Config.ExtraConfig {
-			ov := bov.GetOptionValue()
-			if ov.Key == key {
-				if ov.Value.(string) == expected {
-					return nil
-				}
-				return fmt.Errorf("expected key %s to be %s, got %s", key, expected, ov.Value.(string))
-			}
-		}
-		return fmt.Errorf("key %s not found", key)
-	}
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing checks to
-// make sure that a key is missing in the VM's extraConfig.
-func testAccResourceVSphereVirtualMachineCheckExtraConfigKeyMissing(key string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		for _, bov := range props.Config.ExtraConfig {
-			ov := bov.GetOptionValue()
-			if ov.Key == key {
-				return fmt.Errorf("expected key %s to be missing", key)
-			}
-		}
-		return nil
-	}
+data "vsphere_datastore" "iso_datastore" {
+  name          = "${var.iso_datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckVmxDatastore checks the datastore
-// that the virtual machine's configuration is currently located.
-func testAccResourceVSphereVirtualMachineCheckVmxDatastore(expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		var dsPath object.DatastorePath
-		if ok := dsPath.FromString(props.Config.Files.VmPathName); !ok {
-			return fmt.Errorf("could not parse datastore path %q", props.Config.Files.VmPathName)
-		}
-		actual := dsPath.Datastore
-		if expected != actual {
-			return fmt.Errorf("expected VM configuration to be in datastore %s, got %s", expected, actual)
-		}
-		return nil
-	}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckVmdkDatastore checks the datastore
-// that a specific VMDK file is in.
-func testAccResourceVSphereVirtualMachineCheckVmdkDatastore(name, expected string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		for _, dev := range props.Config.Hardware.Device {
-			if disk, ok := dev.(*types.VirtualDisk); ok {
-				if info, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
-					var dsPath object.DatastorePath
-					if ok := dsPath.FromString(info.FileName); !ok {
-						return fmt.Errorf("could not parse datastore path %q", info.FileName)
-					}
-					if path.Base(dsPath.Path) == name {
-						actual := dsPath.Datastore
-						if expected == actual {
-							return nil
-						}
-						return fmt.Errorf("expected disk name %q to be on datastore %q, got %q", name, expected, actual)
-					}
-				}
-			}
-		}
-		return fmt.Errorf("could not find disk %q", name)
-	}
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-// testAccResourceVSphereVirtualMachineCheckNICCount checks the number of NICs
-// on the virtual machine.
-func testAccResourceVSphereVirtualMachineCheckNICCount(expected int) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-		var actual int
-		for _, dev := range props.Config.Hardware.Device {
-			if _, ok := dev.(types.BaseVirtualEthernetCard); ok {
-				actual++
-			}
-		}
-		if expected != actual {
-			return fmt.Errorf("expected %d number of NICs, got %d", expected, actual)
-		}
-		return nil
-	}
-}
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
 
-// testCheckVMDiskFileExists takes a VMDK filename and checks to see if it
-// exists within the same directory as the virtual machine's VMX file.
-func testCheckVMDiskFileExists(name string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
-		if err != nil {
-			return err
-		}
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		vmxPath, success := virtualdisk.DatastorePathFromString(props.Config.Files.VmPathName)
-		if !success {
-			return fmt.Errorf("could not parse VMX path %q", props.Config.Files.VmPathName)
-		}
-		dcp, err := folder.RootPathParticleVM.SplitDatacenter(vm.InventoryPath)
-		if err != nil {
-			return err
-		}
-		dc, err := getDatacenter(tVars.client, dcp)
-		if err != nil {
-			return err
-		}
-		ds, err := datastore.FromPath(tVars.client, vmxPath.Datastore, dc)
-		if err != nil {
-			return err
-		}
-		p := path.Join(path.Dir(vmxPath.Path), name)
-		exists, err := datastore.FileExists(ds, p)
-		if err != nil {
-			return err
-		}
-		if !exists {
-			return fmt.Errorf("file %q does not exist in datastore %q", p, ds.Name())
-		}
-		return nil
-	}
-}
+  wait_for_guest_net_timeout = -1
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
 
-func testAccResourceVSphereVirtualMachineCheckCustomAttributes() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		props, err := testGetVirtualMachineProperties(s, "vm")
-		if err != nil {
-			return err
-		}
-		return testResourceHasCustomAttributeValues(s, "vsphere_virtual_machine", "vm", props.Entity())
-	}
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  cdrom {
+    datastore_id = "${data.vsphere_datastore.iso_datastore.id}"
+    path         = "${var.iso_path}"
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_ISO_DATASTORE"),
+		os.Getenv("VSPHERE_ISO_FILE"),
+	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBasic() string {
+func testAccResourceVSphereVirtualMachineConfigIsoCdromCloneIsoVApp() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -2985,10 +4328,42 @@ variable "network_label" {
   default = "%s"
 }
 
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
 variable "datastore" {
   default = "%s"
 }
 
+variable "cdrom_datastore" {
+  default = "%s"
+}
+
+variable "cdrom_iso" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -3008,6 +4383,16 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_datastore" "cdrom_datastore" {
+  name = "${var.cdrom_datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
@@ -3015,49 +4400,118 @@ resource "vsphere_virtual_machine" "vm" {
 
   num_cpus = 2
   memory   = 2048
-  guest_id = "other3xLinux64Guest"
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
   network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
   }
 
   disk {
-    label = "disk0"
-    size  = 20
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  cdrom {
+    datastore_id  = "${data.vsphere_datastore.cdrom_datastore.id}"
+    path          = "${var.cdrom_iso}"
+  }
+
+  vapp {
+    properties {
+      "hostname" = "custom-hostname"
+    }
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_DHCP"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_ISO_DATASTORE"),
+		os.Getenv("VSPHERE_ISO_FILE"),
+		os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBasicESXiOnly() string {
+func testAccResourceVSphereVirtualMachineConfigNoCdromCloneIsoVApp() string {
 	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
 variable "network_label" {
   default = "%s"
 }
 
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
 variable "datastore" {
   default = "%s"
 }
 
-data "vsphere_datacenter" "dc" {}
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
 
 data "vsphere_datastore" "datastore" {
   name          = "${var.datastore}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_resource_pool" "pool" {}
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
 
 data "vsphere_network" "network" {
   name          = "${var.network_label}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
@@ -3065,24 +4519,46 @@ resource "vsphere_virtual_machine" "vm" {
 
   num_cpus = 2
   memory   = 2048
-  guest_id = "other3xLinux64Guest"
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
   network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
   }
 
   disk {
-    label = "disk0"
-    size  = 20
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  vapp {
+    properties {
+      "hostname" = "custom-hostname"
+    }
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
   }
 }
 `,
-		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_DHCP"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigMultiDevice() string {
+func testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3096,10 +4572,34 @@ variable "network_label" {
   default = "%s"
 }
 
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
 variable "datastore" {
   default = "%s"
 }
 
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -3119,6 +4619,11 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
@@ -3126,49 +4631,50 @@ resource "vsphere_virtual_machine" "vm" {
 
   num_cpus = 2
   memory   = 2048
-  guest_id = "other3xLinux64Guest"
-
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "normal"
-  }
-
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "high"
-  }
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
   network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "low"
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
   }
 
   disk {
-    label = "disk0"
-    size  = 20
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
   }
 
-  disk {
-    label       = "disk1"
-    unit_number = 1
-    size        = 10
+  cdrom {
+    client_device = true
   }
 
-  disk {
-    label       = "disk2"
-    unit_number = 2
-    size        = 5
+  vapp {
+    properties {
+      "hostname" = "custom-hostname"
+    }
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_DHCP"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigMultiHighBus() string {
+func testAccResourceVSphereVirtualMachineConfigClientCdromClone() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3182,10 +4688,34 @@ variable "network_label" {
   default = "%s"
 }
 
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
 variable "datastore" {
   default = "%s"
 }
 
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -3205,58 +4735,72 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  scsi_controller_count = 3
-
   num_cpus = 2
   memory   = 2048
-  guest_id = "other3xLinux64Guest"
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
   network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "normal"
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
   }
 
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "high"
+  disk {
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
   }
 
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "low"
+  cdrom {
+    client_device = true
   }
 
-  disk {
-    label = "disk0"
-    size  = 20
-  }
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
 
-  disk {
-    label       = "disk1"
-    unit_number = 15
-    size        = 10
-  }
+    customize {
+      linux_options {
+        host_name = "terraform-test"
+        domain    = "test.internal"
+      }
 
-  disk {
-    label       = "disk2"
-    unit_number = 31
-    size        = 5
+      network_interface {
+        ipv4_address = "${var.ipv4_address}"
+        ipv4_netmask = "${var.ipv4_netmask}"
+      }
+
+      ipv4_gateway    = "${var.ipv4_gateway}"
+      dns_server_list = ["${var.dns_server}"]
+      dns_suffix_list = ["test.internal"]
+    }
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigRemoveMiddle() string {
+func testAccResourceVSphereVirtualMachineConfigClientCdrom() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3302,14 +4846,10 @@ resource "vsphere_virtual_machine" "vm" {
   memory   = 2048
   guest_id = "other3xLinux64Guest"
 
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "normal"
-  }
+  wait_for_guest_net_timeout = -1
 
   network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "low"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
@@ -3317,10 +4857,8 @@ resource "vsphere_virtual_machine" "vm" {
     size  = 20
   }
 
-  disk {
-    label       = "disk2"
-    unit_number = 2
-    size        = 5
+  cdrom {
+    client_device = true
   }
 }
 `,
@@ -3331,7 +4869,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigRemoveMiddleChangeUnit() string {
+func testAccResourceVSphereVirtualMachineConfigNoCdromParameters() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3377,14 +4915,10 @@ resource "vsphere_virtual_machine" "vm" {
   memory   = 2048
   guest_id = "other3xLinux64Guest"
 
-  network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "normal"
-  }
+  wait_for_guest_net_timeout = -1
 
   network_interface {
-    network_id            = "${data.vsphere_network.network.id}"
-    bandwidth_share_level = "low"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
@@ -3392,11 +4926,7 @@ resource "vsphere_virtual_machine" "vm" {
     size  = 20
   }
 
-  disk {
-    label       = "disk2"
-    unit_number = 1
-    size        = 5
-  }
+  cdrom {}
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
@@ -3406,7 +4936,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigIsoCdrom() string {
+func testAccResourceVSphereVirtualMachineConfigConflictingCdromParameters() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3477,8 +5007,9 @@ resource "vsphere_virtual_machine" "vm" {
   }
 
   cdrom {
-    datastore_id = "${data.vsphere_datastore.iso_datastore.id}"
-    path         = "${var.iso_path}"
+    datastore_id  = "${data.vsphere_datastore.iso_datastore.id}"
+    path          = "${var.iso_path}"
+    client_device = true
   }
 }
 `,
@@ -3491,7 +5022,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigIsoCdromCloneIsoVApp() string {
+func testAccResourceVSphereVirtualMachineConfigBeefy() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3505,42 +5036,10 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "ipv4_address" {
-  default = "%s"
-}
-
-variable "ipv4_netmask" {
-  default = "%s"
-}
-
-variable "ipv4_gateway" {
-  default = "%s"
-}
-
-variable "dns_server" {
-  default = "%s"
-}
-
 variable "datastore" {
   default = "%s"
 }
 
-variable "cdrom_datastore" {
-  default = "%s"
-}
-
-variable "cdrom_iso" {
-  default = "%s"
-}
-
-variable "template" {
-  default = "%s"
-}
-
-variable "linked_clone" {
-  default = "%s"
-}
-
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -3560,108 +5059,47 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_virtual_machine" "template" {
-  name          = "${var.template}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
-data "vsphere_datastore" "cdrom_datastore" {
-  name = "${var.cdrom_datastore}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+  num_cpus = 4
+  memory   = 8192
+  guest_id = "other3xLinux64Guest"
 
   network_interface {
-    network_id   = "${data.vsphere_network.network.id}"
-    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
-    label            = "disk0"
-    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
-    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
-  }
-
-  cdrom {
-    datastore_id  = "${data.vsphere_datastore.cdrom_datastore.id}"
-    path          = "${var.cdrom_iso}"
-  }
-
-  vapp {
-    properties {
-      "hostname" = "custom-hostname"
-    }
-  }
-
-  clone {
-    template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+    label = "disk0"
+    size  = 20
   }
 }
 `,
-		os.Getenv("VSPHERE_DATACENTER"),
-		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_DHCP"),
-		os.Getenv("VSPHERE_IPV4_ADDRESS"),
-		os.Getenv("VSPHERE_IPV4_PREFIX"),
-		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DNS"),
-		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_ISO_DATASTORE"),
-		os.Getenv("VSPHERE_ISO_FILE"),
-		os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT"),
-		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
-	)
-}
-
-func testAccResourceVSphereVirtualMachineConfigNoCdromCloneIsoVApp() string {
-	return fmt.Sprintf(`
-variable "datacenter" {
-  default = "%s"
-}
-
-variable "resource_pool" {
-  default = "%s"
-}
-
-variable "network_label" {
-  default = "%s"
-}
-
-variable "ipv4_address" {
-  default = "%s"
-}
-
-variable "ipv4_netmask" {
-  default = "%s"
-}
-
-variable "ipv4_gateway" {
-  default = "%s"
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+	)
 }
 
-variable "dns_server" {
+func testAccResourceVSphereVirtualMachineConfigSnapshotBeforeDisruptiveUpdate(cpus, memory, retention int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
   default = "%s"
 }
 
-variable "datastore" {
+variable "resource_pool" {
   default = "%s"
 }
 
-variable "template" {
+variable "network_label" {
   default = "%s"
 }
 
-variable "linked_clone" {
+variable "datastore" {
   default = "%s"
 }
 
@@ -3684,58 +5122,39 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_virtual_machine" "template" {
-  name          = "${var.template}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+  num_cpus = %d
+  memory   = %d
+  guest_id = "other3xLinux64Guest"
+
+  snapshot_before_disruptive_update = true
+  snapshot_retention_count          = %d
 
   network_interface {
-    network_id   = "${data.vsphere_network.network.id}"
-    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
-    label            = "disk0"
-    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
-    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
-  }
-
-  vapp {
-    properties {
-      "hostname" = "custom-hostname"
-    }
-  }
-
-  clone {
-    template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+    label = "disk0"
+    size  = 20
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_DHCP"),
-		os.Getenv("VSPHERE_IPV4_ADDRESS"),
-		os.Getenv("VSPHERE_IPV4_PREFIX"),
-		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DNS"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT"),
-		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		cpus,
+		memory,
+		retention,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigClientCdromCloneIsoVApp() string {
+func testAccResourceVSphereVirtualMachineConfigMaxNIC() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3749,34 +5168,10 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "ipv4_address" {
-  default = "%s"
-}
-
-variable "ipv4_netmask" {
-  default = "%s"
-}
-
-variable "ipv4_gateway" {
-  default = "%s"
-}
-
-variable "dns_server" {
-  default = "%s"
-}
-
 variable "datastore" {
   default = "%s"
 }
 
-variable "template" {
-  default = "%s"
-}
-
-variable "linked_clone" {
-  default = "%s"
-}
-
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -3796,11 +5191,6 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_virtual_machine" "template" {
-  name          = "${var.template}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
@@ -3808,50 +5198,62 @@ resource "vsphere_virtual_machine" "vm" {
 
   num_cpus = 2
   memory   = 2048
-  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+  guest_id = "other3xLinux64Guest"
 
   network_interface {
-    network_id   = "${data.vsphere_network.network.id}"
-    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
-  disk {
-    label            = "disk0"
-    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
-    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
   }
 
-  cdrom {
-    client_device = true
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
   }
 
-  vapp {
-    properties {
-      "hostname" = "custom-hostname"
-    }
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
   }
 
-  clone {
-    template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
   }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_DHCP"),
-		os.Getenv("VSPHERE_IPV4_ADDRESS"),
-		os.Getenv("VSPHERE_IPV4_PREFIX"),
-		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DNS"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_TEMPLATE_ISO_TRANSPORT"),
-		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigClientCdromClone() string {
+func testAccResourceVSphereVirtualMachineConfigBasicAnnotation() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -3865,31 +5267,76 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "ipv4_address" {
+variable "datastore" {
   default = "%s"
 }
 
-variable "ipv4_netmask" {
+variable "annotation" {
   default = "%s"
 }
 
-variable "ipv4_gateway" {
-  default = "%s"
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
 }
 
-variable "dns_server" {
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus   = 2
+  memory     = 2048
+  guest_id   = "other3xLinux64Guest"
+  annotation = "${var.annotation}"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		testAccResourceVSphereVirtualMachineAnnotation,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigManagedAnnotation() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
   default = "%s"
 }
 
-variable "datastore" {
+variable "resource_pool" {
   default = "%s"
 }
 
-variable "template" {
+variable "network_label" {
   default = "%s"
 }
 
-variable "linked_clone" {
+variable "datastore" {
   default = "%s"
 }
 
@@ -3912,72 +5359,34 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_virtual_machine" "template" {
-  name          = "${var.template}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+  num_cpus           = 2
+  memory             = 2048
+  guest_id           = "other3xLinux64Guest"
+  managed_annotation = "Managed by Terraform: {{name}}"
 
   network_interface {
-    network_id   = "${data.vsphere_network.network.id}"
-    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
-    label            = "disk0"
-    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
-    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
-  }
-
-  cdrom {
-    client_device = true
-  }
-
-  clone {
-    template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
-
-    customize {
-      linux_options {
-        host_name = "terraform-test"
-        domain    = "test.internal"
-      }
-
-      network_interface {
-        ipv4_address = "${var.ipv4_address}"
-        ipv4_netmask = "${var.ipv4_netmask}"
-      }
-
-      ipv4_gateway    = "${var.ipv4_gateway}"
-      dns_server_list = ["${var.dns_server}"]
-      dns_suffix_list = ["test.internal"]
-    }
+    label = "disk0"
+    size  = 20
   }
-}
-`,
-		os.Getenv("VSPHERE_DATACENTER"),
-		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL"),
-		os.Getenv("VSPHERE_IPV4_ADDRESS"),
-		os.Getenv("VSPHERE_IPV4_PREFIX"),
-		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DNS"),
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_TEMPLATE"),
-		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigClientCdrom() string {
+func testAccResourceVSphereVirtualMachineConfigHardwareVersion(version int) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4019,11 +5428,10 @@ resource "vsphere_virtual_machine" "vm" {
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "other3xLinux64Guest"
-
-  wait_for_guest_net_timeout = -1
+  num_cpus         = 2
+  memory           = 2048
+  guest_id         = "other3xLinux64Guest"
+  hardware_version = %d
 
   network_interface {
     network_id = "${data.vsphere_network.network.id}"
@@ -4033,20 +5441,17 @@ resource "vsphere_virtual_machine" "vm" {
     label = "disk0"
     size  = 20
   }
-
-  cdrom {
-    client_device = true
-  }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		version,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigNoCdromParameters() string {
+func testAccResourceVSphereVirtualMachineConfigInvalidGuestID() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4056,10 +5461,6 @@ variable "resource_pool" {
   default = "%s"
 }
 
-variable "network_label" {
-  default = "%s"
-}
-
 variable "datastore" {
   default = "%s"
 }
@@ -4078,42 +5479,25 @@ data "vsphere_resource_pool" "pool" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_network" "network" {
-  name          = "${var.network_label}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
-
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "other3xLinux64Guest"
-
-  wait_for_guest_net_timeout = -1
-
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+  guest_id         = "otherGuest64bit"
 
   disk {
     label = "disk0"
     size  = 20
   }
-
-  cdrom {}
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
-		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigConflictingCdromParameters() string {
+func testAccResourceVSphereVirtualMachineConfigMemoryReservationLocked() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4131,14 +5515,6 @@ variable "datastore" {
   default = "%s"
 }
 
-variable "iso_datastore" {
-  default = "%s"
-}
-
-variable "iso_path" {
-  default = "%s"
-}
-
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -4148,11 +5524,6 @@ data "vsphere_datastore" "datastore" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_datastore" "iso_datastore" {
-  name          = "${var.iso_datastore}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 data "vsphere_resource_pool" "pool" {
   name          = "${var.resource_pool}"
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
@@ -4168,11 +5539,11 @@ resource "vsphere_virtual_machine" "vm" {
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus = 2
-  memory   = 2048
-  guest_id = "other3xLinux64Guest"
-
-  wait_for_guest_net_timeout = -1
+  num_cpus                         = 2
+  memory                           = 2048
+  guest_id                         = "other3xLinux64Guest"
+  memory_reservation_locked_to_max = true
+  memory_balloon_max_size          = 0
 
   network_interface {
     network_id = "${data.vsphere_network.network.id}"
@@ -4182,24 +5553,16 @@ resource "vsphere_virtual_machine" "vm" {
     label = "disk0"
     size  = 20
   }
-
-  cdrom {
-    datastore_id  = "${data.vsphere_datastore.iso_datastore.id}"
-    path          = "${var.iso_path}"
-    client_device = true
-  }
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_ISO_DATASTORE"),
-		os.Getenv("VSPHERE_ISO_FILE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBeefy() string {
+func testAccResourceVSphereVirtualMachineConfigVideoCardAndVNC(memory, vncPort int) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4241,9 +5604,13 @@ resource "vsphere_virtual_machine" "vm" {
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus = 4
-  memory   = 8192
-  guest_id = "other3xLinux64Guest"
+  num_cpus           = 2
+  memory             = 2048
+  guest_id           = "other3xLinux64Guest"
+  video_memory       = %d
+  video_num_displays = 2
+  vnc_port           = %d
+  vnc_password       = "terraform"
 
   network_interface {
     network_id = "${data.vsphere_network.network.id}"
@@ -4259,10 +5626,40 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		memory,
+		vncPort,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigMaxNIC() string {
+func testAccResourceVSphereVirtualMachineConfigAdoptExisting() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_virtual_machine" "adopted" {
+  name             = "terraform-test-adopt"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+  adopt_existing   = true
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigReadinessProbe(probe string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4312,37 +5709,83 @@ resource "vsphere_virtual_machine" "vm" {
     network_id = "${data.vsphere_network.network.id}"
   }
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+  disk {
+    label = "disk0"
+    size  = 20
   }
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
+  readiness_probe {
+    %s
   }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		probe,
+	)
+}
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+func testAccResourceVSphereVirtualMachineConfigLatencySensitivity(cpu string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+variable "resource_pool" {
+  default = "%s"
+}
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+variable "network_label" {
+  default = "%s"
+}
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+variable "datastore" {
+  default = "%s"
+}
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+variable "host" {
+  default = "%s"
+}
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  host_system_id   = "${data.vsphere_host.host.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus                         = 1
+  memory                           = 2048
+  guest_id                         = "other3xLinux64Guest"
+  memory_reservation_locked_to_max = true
+  latency_sensitivity              = "high"
+  cpu_affinity                     = ["%s"]
+  numa_node_affinity               = [0]
 
   network_interface {
     network_id = "${data.vsphere_network.network.id}"
@@ -4358,10 +5801,12 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		cpu,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBasicAnnotation() string {
+func testAccResourceVSphereVirtualMachineConfigSyncCPUMemoryDisabled() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4379,10 +5824,6 @@ variable "datastore" {
   default = "%s"
 }
 
-variable "annotation" {
-  default = "%s"
-}
-
 data "vsphere_datacenter" "dc" {
   name = "${var.datacenter}"
 }
@@ -4407,10 +5848,10 @@ resource "vsphere_virtual_machine" "vm" {
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
   datastore_id     = "${data.vsphere_datastore.datastore.id}"
 
-  num_cpus   = 2
-  memory     = 2048
-  guest_id   = "other3xLinux64Guest"
-  annotation = "${var.annotation}"
+  num_cpus        = 2
+  memory          = 2048
+  guest_id        = "other3xLinux64Guest"
+  sync_cpu_memory = false
 
   network_interface {
     network_id = "${data.vsphere_network.network.id}"
@@ -4426,7 +5867,6 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		testAccResourceVSphereVirtualMachineAnnotation,
 	)
 }
 
@@ -4709,11 +6149,81 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		testAccResourceVSphereVirtualMachineDiskNameExtraVmdk,
+		testAccResourceVSphereVirtualMachineDiskNameExtraVmdk,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigInFolder() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "terraform-test-vms"
+  type          = "vm"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+  folder           = "${vsphere_folder.folder.path}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigInFolder() string {
+func testAccResourceVSphereVirtualMachineConfigCreateFolders() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -4750,17 +6260,13 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-resource "vsphere_folder" "folder" {
-  path          = "terraform-test-vms"
-  type          = "vm"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
-  name             = "terraform-test"
-  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
-  datastore_id     = "${data.vsphere_datastore.datastore.id}"
-  folder           = "${vsphere_folder.folder.path}"
+  name                 = "terraform-test"
+  resource_pool_id     = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id         = "${data.vsphere_datastore.datastore.id}"
+  folder               = "terraform-test-vms-auto/nested"
+  create_folders       = true
+  remove_empty_folders = true
 
   num_cpus = 2
   memory   = 2048
@@ -5219,7 +6725,251 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigClone() string {
+func testAccResourceVSphereVirtualMachineConfigClone() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+
+  network_interface {
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+  }
+
+  disk {
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+
+    customize {
+      linux_options {
+        host_name = "terraform-test"
+        domain    = "test.internal"
+      }
+
+      network_interface {
+        ipv4_address = "${var.ipv4_address}"
+        ipv4_netmask = "${var.ipv4_netmask}"
+      }
+
+      ipv4_gateway    = "${var.ipv4_gateway}"
+      dns_server_list = ["${var.dns_server}"]
+      dns_suffix_list = ["test.internal"]
+    }
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigBadEager() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_netmask" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "dns_server" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_virtual_machine" "template" {
+  name          = "${var.template}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+
+  network_interface {
+    network_id   = "${data.vsphere_network.network.id}"
+    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+  }
+
+  disk {
+    label            = "disk0"
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub == "true" ? "false" : "true"}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+  }
+
+  clone {
+    template_uuid = "${data.vsphere_virtual_machine.template.id}"
+    linked_clone  = true
+
+    customize {
+      linux_options {
+        host_name = "terraform-test"
+        domain    = "test.internal"
+      }
+
+      network_interface {
+        ipv4_address = "${var.ipv4_address}"
+        ipv4_netmask = "${var.ipv4_netmask}"
+      }
+
+      ipv4_gateway    = "${var.ipv4_gateway}"
+      dns_server_list = ["${var.dns_server}"]
+      dns_suffix_list = ["test.internal"]
+    }
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DNS"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigBadThin() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -5303,12 +7053,12 @@ resource "vsphere_virtual_machine" "vm" {
     label            = "disk0"
     size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
     eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned == "true" ? "false" : "true"}"
   }
 
   clone {
     template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
+    linked_clone  = true
 
     customize {
       linux_options {
@@ -5341,7 +7091,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBadEager() string {
+func testAccResourceVSphereVirtualMachineConfigBadSizeLinked() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -5423,8 +7173,8 @@ resource "vsphere_virtual_machine" "vm" {
 
   disk {
     label            = "disk0"
-    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
-    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub == "true" ? "false" : "true"}"
+    size             = 999
+    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
     thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
   }
 
@@ -5463,7 +7213,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBadThin() string {
+func testAccResourceVSphereVirtualMachineConfigBadSizeUnlinked() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -5545,14 +7295,13 @@ resource "vsphere_virtual_machine" "vm" {
 
   disk {
     label            = "disk0"
-    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
+    size             = 1
     eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned == "true" ? "false" : "true"}"
+    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
   }
 
   clone {
     template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = true
 
     customize {
       linux_options {
@@ -5585,7 +7334,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBadSizeLinked() string {
+func testAccResourceVSphereVirtualMachineConfigCloneMultiNIC() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -5661,20 +7410,23 @@ resource "vsphere_virtual_machine" "vm" {
   guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
   network_interface {
-    network_id   = "${data.vsphere_network.network.id}"
-    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
   }
 
   disk {
     label            = "disk0"
-    size             = 999
+    size             = "${data.vsphere_virtual_machine.template.disks.0.size}"
     eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
     thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
   }
 
   clone {
     template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = true
+    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
 
     customize {
       linux_options {
@@ -5687,6 +7439,8 @@ resource "vsphere_virtual_machine" "vm" {
         ipv4_netmask = "${var.ipv4_netmask}"
       }
 
+      network_interface {}
+
       ipv4_gateway    = "${var.ipv4_gateway}"
       dns_server_list = ["${var.dns_server}"]
       dns_suffix_list = ["test.internal"]
@@ -5707,7 +7461,7 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigBadSizeUnlinked() string {
+func testAccResourceVSphereVirtualMachineConfigCloneContentLibrary() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -5721,31 +7475,11 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "ipv4_address" {
-  default = "%s"
-}
-
-variable "ipv4_netmask" {
-  default = "%s"
-}
-
-variable "ipv4_gateway" {
-  default = "%s"
-}
-
-variable "dns_server" {
-  default = "%s"
-}
-
 variable "datastore" {
   default = "%s"
 }
 
-variable "template" {
-  default = "%s"
-}
-
-variable "linked_clone" {
+variable "item_id" {
   default = "%s"
 }
 
@@ -5768,11 +7502,6 @@ data "vsphere_network" "network" {
   datacenter_id = "${data.vsphere_datacenter.dc.id}"
 }
 
-data "vsphere_virtual_machine" "template" {
-  name          = "${var.template}"
-  datacenter_id = "${data.vsphere_datacenter.dc.id}"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name             = "terraform-test"
   resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
@@ -5780,37 +7509,21 @@ resource "vsphere_virtual_machine" "vm" {
 
   num_cpus = 2
   memory   = 2048
-  guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
+  guest_id = "other3xLinux64Guest"
 
-  network_interface {
-    network_id   = "${data.vsphere_network.network.id}"
-    adapter_type = "${data.vsphere_virtual_machine.template.network_interface_types[0]}"
-  }
+  wait_for_guest_net_timeout = -1
 
   disk {
-    label            = "disk0"
-    size             = 1
-    eagerly_scrub    = "${data.vsphere_virtual_machine.template.disks.0.eagerly_scrub}"
-    thin_provisioned = "${data.vsphere_virtual_machine.template.disks.0.thin_provisioned}"
+    label = "disk0"
+    size  = 20
   }
 
   clone {
-    template_uuid = "${data.vsphere_virtual_machine.template.id}"
-
-    customize {
-      linux_options {
-        host_name = "terraform-test"
-        domain    = "test.internal"
-      }
-
-      network_interface {
-        ipv4_address = "${var.ipv4_address}"
-        ipv4_netmask = "${var.ipv4_netmask}"
-      }
+    content_library_item_id = "${var.item_id}"
 
-      ipv4_gateway    = "${var.ipv4_gateway}"
-      dns_server_list = ["${var.dns_server}"]
-      dns_suffix_list = ["test.internal"]
+    network_map {
+      source     = ".*"
+      network_id = "${data.vsphere_network.network.id}"
     }
   }
 }
@@ -5818,17 +7531,12 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL"),
-		os.Getenv("VSPHERE_IPV4_ADDRESS"),
-		os.Getenv("VSPHERE_IPV4_PREFIX"),
-		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_TEMPLATE"),
-		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		os.Getenv("VSPHERE_CONTENT_LIBRARY_OVF_ITEM_ID"),
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigCloneMultiNIC() string {
+func testAccResourceVSphereVirtualMachineConfigOvfDeploy(timeout int) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -5842,31 +7550,88 @@ variable "network_label" {
   default = "%s"
 }
 
-variable "ipv4_address" {
+variable "datastore" {
   default = "%s"
 }
 
-variable "ipv4_netmask" {
+variable "ovf_url" {
   default = "%s"
 }
 
-variable "ipv4_gateway" {
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  wait_for_guest_net_timeout = -1
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+
+  ovf_deploy {
+    remote_ovf_url    = "${var.ovf_url}"
+    disk_provisioning = "thin"
+    timeout           = %d
+
+    ovf_network_map = {
+      "VM Network" = "${data.vsphere_network.network.id}"
+    }
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_OVF_URL"),
+		timeout,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCloneNetworkMap() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
   default = "%s"
 }
 
-variable "dns_server" {
+variable "resource_pool" {
   default = "%s"
 }
 
-variable "datastore" {
+variable "network_label" {
   default = "%s"
 }
 
-variable "template" {
+variable "datastore" {
   default = "%s"
 }
 
-variable "linked_clone" {
+variable "template" {
   default = "%s"
 }
 
@@ -5903,13 +7668,7 @@ resource "vsphere_virtual_machine" "vm" {
   memory   = 2048
   guest_id = "${data.vsphere_virtual_machine.template.guest_id}"
 
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
-
-  network_interface {
-    network_id = "${data.vsphere_network.network.id}"
-  }
+  wait_for_guest_net_timeout = -1
 
   disk {
     label            = "disk0"
@@ -5920,24 +7679,10 @@ resource "vsphere_virtual_machine" "vm" {
 
   clone {
     template_uuid = "${data.vsphere_virtual_machine.template.id}"
-    linked_clone  = "${var.linked_clone != "" ? "true" : "false" }"
-
-    customize {
-      linux_options {
-        host_name = "terraform-test"
-        domain    = "test.internal"
-      }
-
-      network_interface {
-        ipv4_address = "${var.ipv4_address}"
-        ipv4_netmask = "${var.ipv4_netmask}"
-      }
-
-      network_interface {}
 
-      ipv4_gateway    = "${var.ipv4_gateway}"
-      dns_server_list = ["${var.dns_server}"]
-      dns_suffix_list = ["test.internal"]
+    network_map {
+      source     = ".*"
+      network_id = "${data.vsphere_network.network.id}"
     }
   }
 }
@@ -5945,13 +7690,8 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATACENTER"),
 		os.Getenv("VSPHERE_RESOURCE_POOL"),
 		os.Getenv("VSPHERE_NETWORK_LABEL"),
-		os.Getenv("VSPHERE_IPV4_ADDRESS"),
-		os.Getenv("VSPHERE_IPV4_PREFIX"),
-		os.Getenv("VSPHERE_IPV4_GATEWAY"),
-		os.Getenv("VSPHERE_DNS"),
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
-		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
 
@@ -6959,6 +8699,80 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
+func testAccResourceVSphereVirtualMachineConfigVmxDatastore(vmxDatastore string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "vmx_datastore" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_datastore" "datastore" {
+  name          = "${var.datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_datastore" "vmx_datastore" {
+  name          = "${var.vmx_datastore}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_resource_pool" "pool" {
+  name          = "${var.resource_pool}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+data "vsphere_network" "network" {
+  name          = "${var.network_label}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name             = "terraform-test"
+  resource_pool_id = "${data.vsphere_resource_pool.pool.id}"
+  datastore_id     = "${data.vsphere_datastore.datastore.id}"
+  vmx_datastore_id = "${data.vsphere_datastore.vmx_datastore.id}"
+
+  num_cpus = 2
+  memory   = 2048
+  guest_id = "other3xLinux64Guest"
+
+  network_interface {
+    network_id = "${data.vsphere_network.network.id}"
+  }
+
+  disk {
+    label = "disk0"
+    size  = 20
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL_PXE"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		vmxDatastore,
+	)
+}
+
 func testAccResourceVSphereVirtualMachineConfigStorageVMotionGlobal(datastore string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -0,0 +1,57 @@
+package vsphere
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourceVSphereVirtualMachineApplyVideoCard applies video_memory,
+// video_num_displays, and video_3d_enabled to the video card of the virtual
+// machine. Only settings that have changed are applied, so that the card of a
+// cloned virtual machine keeps the settings of its source unless they are
+// set in configuration.
+func resourceVSphereVirtualMachineApplyVideoCard(d *schema.ResourceData, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	var ram int64
+	var displays int32
+	var enable3D *bool
+	if d.HasChange("video_memory") {
+		ram = int64(d.Get("video_memory").(int))
+	}
+	if d.HasChange("video_num_displays") {
+		displays = int32(d.Get("video_num_displays").(int))
+	}
+	if d.HasChange("video_3d_enabled") {
+		enable3D = structure.BoolPtr(d.Get("video_3d_enabled").(bool))
+	}
+	if ram == 0 && displays == 0 && enable3D == nil {
+		return l, nil, nil
+	}
+	l, spec, err := virtualdevice.NormalizeVideoCard(l, ram, displays, enable3D)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(spec) > 0 {
+		// The video card can only be changed while the virtual machine is
+		// powered off.
+		log.Printf("[DEBUG] %s: Video card has changed and requires a VM restart", resourceVSphereVirtualMachineIDString(d))
+		d.Set("reboot_required", true)
+	}
+	return l, spec, nil
+}
+
+// resourceVSphereVirtualMachineReadVideoCard reads the settings of the video
+// card of the virtual machine.
+func resourceVSphereVirtualMachineReadVideoCard(d *schema.ResourceData, l object.VirtualDeviceList) {
+	card := virtualdevice.ReadVideoCard(l)
+	if card == nil {
+		return
+	}
+	d.Set("video_memory", card.VideoRamSizeInKB)
+	d.Set("video_num_displays", card.NumDisplays)
+	d.Set("video_3d_enabled", card.Enable3DSupport != nil && *card.Enable3DSupport)
+}
@@ -0,0 +1,103 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualMachineSCSIUnitsPerBus is the number of disk units on each SCSI bus,
+// used to work out the bus of a disk from its unit_number.
+const virtualMachineSCSIUnitsPerBus = 15
+
+var virtualMachineWSFCBusSharingAllowedValues = []string{
+	string(types.VirtualSCSISharingPhysicalSharing),
+	string(types.VirtualSCSISharingVirtualSharing),
+}
+
+// resourceVSphereVirtualMachineValidateWSFC checks that a virtual machine with
+// a wsfc block has the settings that Windows Server Failover Clustering
+// requires: disk UUIDs exposed to the guest, a SCSI controller for the shared
+// bus, and eager-zeroed thick disks on the shared bus. Disks that are attached
+// from another node are not checked, as their format is set by the node that
+// creates them.
+func resourceVSphereVirtualMachineValidateWSFC(d *schema.ResourceDiff) error {
+	if len(d.Get("wsfc").([]interface{})) < 1 {
+		return nil
+	}
+	bus := d.Get("wsfc.0.shared_bus_number").(int)
+	if !d.Get("enable_disk_uuid").(bool) {
+		return fmt.Errorf("wsfc: enable_disk_uuid must be set to true")
+	}
+	if count := d.Get("scsi_controller_count").(int); bus >= count {
+		return fmt.Errorf("wsfc: shared_bus_number %d requires scsi_controller_count to be at least %d", bus, bus+1)
+	}
+	for i, v := range d.Get("disk").([]interface{}) {
+		m := v.(map[string]interface{})
+		if m["unit_number"].(int)/virtualMachineSCSIUnitsPerBus != bus || m["attach"].(bool) {
+			continue
+		}
+		if m["thin_provisioned"].(bool) || !m["eagerly_scrub"].(bool) {
+			return fmt.Errorf("wsfc: disk.%d is on the shared SCSI bus and must be eager-zeroed thick. Set thin_provisioned to false and eagerly_scrub to true", i)
+		}
+	}
+	return nil
+}
+
+// resourceVSphereVirtualMachineApplyWSFCBusSharing sets the bus sharing mode
+// on the shared SCSI bus of a virtual machine with a wsfc block. If the block
+// was removed, or the shared bus was changed, bus sharing is turned off on the
+// previously shared bus.
+func resourceVSphereVirtualMachineApplyWSFCBusSharing(d *schema.ResourceData, l object.VirtualDeviceList) (object.VirtualDeviceList, []types.BaseVirtualDeviceConfigSpec, error) {
+	var spec, delta []types.BaseVirtualDeviceConfigSpec
+	var err error
+	o, n := d.GetChange("wsfc")
+	oldBus, newBus := -1, -1
+	if ol := o.([]interface{}); len(ol) > 0 && ol[0] != nil {
+		oldBus = ol[0].(map[string]interface{})["shared_bus_number"].(int)
+	}
+	if nl := n.([]interface{}); len(nl) > 0 && nl[0] != nil {
+		newBus = nl[0].(map[string]interface{})["shared_bus_number"].(int)
+	}
+	if oldBus >= 0 && oldBus != newBus {
+		log.Printf("[DEBUG] %s: Turning off bus sharing on SCSI bus %d", resourceVSphereVirtualMachineIDString(d), oldBus)
+		if virtualdevice.ReadSCSIBusSharing(l, oldBus) != "" {
+			l, delta, err = virtualdevice.NormalizeSCSIBusSharing(l, oldBus, string(types.VirtualSCSISharingNoSharing))
+			if err != nil {
+				return nil, nil, err
+			}
+			spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+		}
+	}
+	if newBus >= 0 {
+		l, delta, err = virtualdevice.NormalizeSCSIBusSharing(l, newBus, d.Get("wsfc.0.bus_sharing").(string))
+		if err != nil {
+			return nil, nil, err
+		}
+		spec = virtualdevice.AppendDeviceChangeSpec(spec, delta...)
+	}
+	return l, spec, nil
+}
+
+// resourceVSphereVirtualMachineReadWSFC reads the bus sharing mode of the
+// shared SCSI bus into the wsfc block, if it is set.
+func resourceVSphereVirtualMachineReadWSFC(d *schema.ResourceData, l object.VirtualDeviceList) error {
+	if len(d.Get("wsfc").([]interface{})) < 1 {
+		return nil
+	}
+	bus := d.Get("wsfc.0.shared_bus_number").(int)
+	wsfc := []interface{}{
+		map[string]interface{}{
+			"shared_bus_number": bus,
+			"bus_sharing":       virtualdevice.ReadSCSIBusSharing(l, bus),
+		},
+	}
+	if err := d.Set("wsfc", wsfc); err != nil {
+		return fmt.Errorf("error setting wsfc: %s", err)
+	}
+	return nil
+}
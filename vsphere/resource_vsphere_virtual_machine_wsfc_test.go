@@ -0,0 +1,216 @@
+package vsphere
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestResourceVSphereVirtualMachineValidateWSFC(t *testing.T) {
+	r := resourceVSphereVirtualMachine()
+	r.CustomizeDiff = func(d *schema.ResourceDiff, _ interface{}) error {
+		return resourceVSphereVirtualMachineValidateWSFC(d)
+	}
+	disk := func(label string, unit int, thin, eager, attach bool) map[string]interface{} {
+		return map[string]interface{}{
+			"label":            label,
+			"unit_number":      unit,
+			"thin_provisioned": thin,
+			"eagerly_scrub":    eager,
+			"attach":           attach,
+		}
+	}
+	cases := []struct {
+		name     string
+		config   map[string]interface{}
+		expected string
+	}{
+		{
+			name: "no wsfc block",
+			config: map[string]interface{}{
+				"disk": []interface{}{disk("disk0", 0, true, false, false)},
+			},
+		},
+		{
+			name: "valid",
+			config: map[string]interface{}{
+				"enable_disk_uuid":      true,
+				"scsi_controller_count": 2,
+				"wsfc":                  []interface{}{map[string]interface{}{"shared_bus_number": 1}},
+				"disk": []interface{}{
+					disk("disk0", 0, true, false, false),
+					disk("quorum", 15, false, true, false),
+				},
+			},
+		},
+		{
+			name: "disk uuid not enabled",
+			config: map[string]interface{}{
+				"scsi_controller_count": 2,
+				"wsfc":                  []interface{}{map[string]interface{}{"shared_bus_number": 1}},
+			},
+			expected: "enable_disk_uuid must be set to true",
+		},
+		{
+			name: "no controller for shared bus",
+			config: map[string]interface{}{
+				"enable_disk_uuid":      true,
+				"scsi_controller_count": 1,
+				"wsfc":                  []interface{}{map[string]interface{}{"shared_bus_number": 1}},
+			},
+			expected: "requires scsi_controller_count to be at least 2",
+		},
+		{
+			name: "thin disk on shared bus",
+			config: map[string]interface{}{
+				"enable_disk_uuid":      true,
+				"scsi_controller_count": 2,
+				"wsfc":                  []interface{}{map[string]interface{}{"shared_bus_number": 1}},
+				"disk": []interface{}{
+					disk("disk0", 0, true, false, false),
+					disk("quorum", 16, true, false, false),
+				},
+			},
+			expected: "disk.1 is on the shared SCSI bus and must be eager-zeroed thick",
+		},
+		{
+			name: "lazy-zeroed disk on shared bus",
+			config: map[string]interface{}{
+				"enable_disk_uuid":      true,
+				"scsi_controller_count": 2,
+				"wsfc":                  []interface{}{map[string]interface{}{"shared_bus_number": 1}},
+				"disk": []interface{}{
+					disk("quorum", 15, false, false, false),
+				},
+			},
+			expected: "disk.0 is on the shared SCSI bus and must be eager-zeroed thick",
+		},
+		{
+			name: "attached disk on shared bus",
+			config: map[string]interface{}{
+				"enable_disk_uuid":      true,
+				"scsi_controller_count": 2,
+				"wsfc":                  []interface{}{map[string]interface{}{"shared_bus_number": 1}},
+				"disk": []interface{}{
+					disk("quorum", 15, true, false, true),
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		_, err := testResourceDiff(r, "", nil, tc.config, nil)
+		switch {
+		case tc.expected == "" && err != nil:
+			t.Fatalf("%s: expected no error, got %s", tc.name, err)
+		case tc.expected != "" && err == nil:
+			t.Fatalf("%s: expected error %q, got none", tc.name, tc.expected)
+		case tc.expected != "" && !strings.Contains(err.Error(), tc.expected):
+			t.Fatalf("%s: expected error %q, got %s", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestResourceVSphereVirtualMachineApplyWSFCBusSharing(t *testing.T) {
+	controllers := func(sharing ...types.VirtualSCSISharing) object.VirtualDeviceList {
+		var l object.VirtualDeviceList
+		for i, s := range sharing {
+			ctlr := &types.ParaVirtualSCSIController{}
+			ctlr.Key = int32(1000 + i)
+			ctlr.BusNumber = int32(i)
+			ctlr.SharedBus = s
+			l = append(l, ctlr)
+		}
+		return l
+	}
+	wsfc := func(bus int, sharing types.VirtualSCSISharing) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"shared_bus_number": bus,
+				"bus_sharing":       string(sharing),
+			},
+		}
+	}
+	cases := []struct {
+		name     string
+		old      []interface{}
+		new      []interface{}
+		devices  object.VirtualDeviceList
+		expected map[int32]types.VirtualSCSISharing
+	}{
+		{
+			name:     "enable sharing",
+			new:      wsfc(1, types.VirtualSCSISharingPhysicalSharing),
+			devices:  controllers(types.VirtualSCSISharingNoSharing, types.VirtualSCSISharingNoSharing),
+			expected: map[int32]types.VirtualSCSISharing{1: types.VirtualSCSISharingPhysicalSharing},
+		},
+		{
+			name:     "sharing already set",
+			old:      wsfc(1, types.VirtualSCSISharingPhysicalSharing),
+			new:      wsfc(1, types.VirtualSCSISharingPhysicalSharing),
+			devices:  controllers(types.VirtualSCSISharingNoSharing, types.VirtualSCSISharingPhysicalSharing),
+			expected: map[int32]types.VirtualSCSISharing{},
+		},
+		{
+			name:     "change sharing mode",
+			old:      wsfc(1, types.VirtualSCSISharingPhysicalSharing),
+			new:      wsfc(1, types.VirtualSCSISharingVirtualSharing),
+			devices:  controllers(types.VirtualSCSISharingNoSharing, types.VirtualSCSISharingPhysicalSharing),
+			expected: map[int32]types.VirtualSCSISharing{1: types.VirtualSCSISharingVirtualSharing},
+		},
+		{
+			name:     "block removed",
+			old:      wsfc(1, types.VirtualSCSISharingPhysicalSharing),
+			devices:  controllers(types.VirtualSCSISharingNoSharing, types.VirtualSCSISharingPhysicalSharing),
+			expected: map[int32]types.VirtualSCSISharing{1: types.VirtualSCSISharingNoSharing},
+		},
+		{
+			name:    "shared bus moved",
+			old:     wsfc(1, types.VirtualSCSISharingPhysicalSharing),
+			new:     wsfc(2, types.VirtualSCSISharingPhysicalSharing),
+			devices: controllers(types.VirtualSCSISharingNoSharing, types.VirtualSCSISharingPhysicalSharing, types.VirtualSCSISharingNoSharing),
+			expected: map[int32]types.VirtualSCSISharing{
+				1: types.VirtualSCSISharingNoSharing,
+				2: types.VirtualSCSISharingPhysicalSharing,
+			},
+		},
+	}
+	for _, tc := range cases {
+		attrs := map[string]string{"wsfc.#": "0"}
+		if len(tc.old) > 0 {
+			m := tc.old[0].(map[string]interface{})
+			attrs = map[string]string{
+				"wsfc.#":                   "1",
+				"wsfc.0.shared_bus_number": "1",
+				"wsfc.0.bus_sharing":       m["bus_sharing"].(string),
+			}
+		}
+		d, err := testResourceDataForUpdate(resourceVSphereVirtualMachine(), "uuid", attrs, map[string]interface{}{"wsfc": tc.new})
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		_, spec, err := resourceVSphereVirtualMachineApplyWSFCBusSharing(d, tc.devices)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		actual := make(map[int32]types.VirtualSCSISharing)
+		for _, s := range spec {
+			cs := s.GetVirtualDeviceConfigSpec()
+			if cs.Operation != types.VirtualDeviceConfigSpecOperationEdit {
+				t.Fatalf("%s: expected edit operation, got %s", tc.name, cs.Operation)
+			}
+			ctlr := cs.Device.(types.BaseVirtualSCSIController).GetVirtualSCSIController()
+			actual[ctlr.BusNumber] = ctlr.SharedBus
+		}
+		if len(actual) != len(tc.expected) {
+			t.Fatalf("%s: expected changes %v, got %v", tc.name, tc.expected, actual)
+		}
+		for bus, sharing := range tc.expected {
+			if actual[bus] != sharing {
+				t.Fatalf("%s: expected changes %v, got %v", tc.name, tc.expected, actual)
+			}
+		}
+	}
+}
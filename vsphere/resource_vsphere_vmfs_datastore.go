@@ -41,9 +41,10 @@ You will need to remove this datastore manually before trying again.
 func resourceVSphereVmfsDatastore() *schema.Resource {
 	s := map[string]*schema.Schema{
 		"name": &schema.Schema{
-			Type:        schema.TypeString,
-			Description: "The name of the datastore.",
-			Required:    true,
+			Type:             schema.TypeString,
+			Description:      "The name of the datastore.",
+			Required:         true,
+			DiffSuppressFunc: datastoreNameRule.diffSuppressFunc(),
 		},
 		"host_system_id": &schema.Schema{
 			Type:        schema.TypeString,
@@ -73,6 +74,7 @@ func resourceVSphereVmfsDatastore() *schema.Resource {
 		},
 	}
 	structure.MergeSchema(s, schemaDatastoreSummary())
+	structure.MergeSchema(s, schemaDatastoreCapacityAlarm())
 
 	// Add tags schema
 	s[vSphereTagAttributeKey] = tagsSchema()
@@ -94,6 +96,12 @@ func resourceVSphereVmfsDatastore() *schema.Resource {
 
 func resourceVSphereVmfsDatastoreCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := vmcCheckHostOperation(meta, "vsphere_vmfs_datastore"); err != nil {
+		return err
+	}
+	if err := datastoreNameRule.apply(d, meta); err != nil {
+		return err
+	}
 
 	// Load up the tags client, which will validate a proper vCenter before
 	// attempting to proceed if we have tags defined.
@@ -127,7 +135,7 @@ func resourceVSphereVmfsDatastoreCreate(d *schema.ResourceData, meta interface{}
 	defer cancel()
 	ds, err := dss.CreateVmfsDatastore(ctx, *spec)
 	if err != nil {
-		return fmt.Errorf("error creating datastore with disk %s: %s", disk, err)
+		return viapi.NewDiagnostic(err, "error creating datastore with disk %s", disk)
 	}
 
 	// Add any remaining disks.
@@ -186,6 +194,11 @@ func resourceVSphereVmfsDatastoreCreate(d *schema.ResourceData, meta interface{}
 		}
 	}
 
+	// Create the capacity alarm
+	if err := processDatastoreCapacityAlarm(client, d, ds); err != nil {
+		return err
+	}
+
 	// Done
 	return resourceVSphereVmfsDatastoreRead(d, meta)
 }
@@ -231,11 +244,15 @@ func resourceVSphereVmfsDatastoreRead(d *schema.ResourceData, meta interface{})
 		customattribute.ReadFromResource(client, props.Entity(), d)
 	}
 
-	return nil
+	// Read the capacity alarm
+	return flattenDatastoreCapacityAlarm(client, d)
 }
 
 func resourceVSphereVmfsDatastoreUpdate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
+	if err := datastoreNameRule.apply(d, meta); err != nil {
+		return err
+	}
 
 	// Load up the tags client, which will validate a proper vCenter before
 	// attempting to proceed if we have tags defined.
@@ -293,6 +310,13 @@ func resourceVSphereVmfsDatastoreUpdate(d *schema.ResourceData, meta interface{}
 		}
 	}
 
+	// Update the capacity alarm
+	if d.HasChange("capacity_alarm") {
+		if err := processDatastoreCapacityAlarm(client, d, ds); err != nil {
+			return err
+		}
+	}
+
 	// Veto this update if it means a disk was removed. Shrinking
 	// datastores/removing extents is not supported.
 	old, new := d.GetChange("disks")
@@ -349,6 +373,10 @@ func resourceVSphereVmfsDatastoreDelete(d *schema.ResourceData, meta interface{}
 		return fmt.Errorf("cannot find datastore: %s", err)
 	}
 
+	if err := removeDatastoreCapacityAlarm(client, d); err != nil {
+		return err
+	}
+
 	// This is a race that more than likely will only come up during tests, but
 	// we still want to guard against it - when working with datastores that end
 	// up mounting across multiple hosts, removing the datastore will fail if
@@ -420,6 +448,9 @@ func resourceVSphereVmfsDatastoreDelete(d *schema.ResourceData, meta interface{}
 }
 
 func resourceVSphereVmfsDatastoreCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := datastoreNameRule.customizeDiff(d, meta); err != nil {
+		return err
+	}
 	// Check all disks and make sure that the entries are not nil, empty, or duplicates.
 	disks := make(map[string]struct{})
 	for i, v := range d.Get("disks").([]interface{}) {
@@ -0,0 +1,98 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/viapi"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/vic/pkg/vsphere/tags"
+)
+
+// softDestroyTagPrefix is the prefix of the names of the expiry tags attached
+// to soft-destroyed virtual machines. The expiry date follows in YYYY-MM-DD
+// format.
+const softDestroyTagPrefix = "expires-"
+
+// softDestroyOptions holds the provider-level settings for soft destroy of
+// virtual machines.
+type softDestroyOptions struct {
+	// The path of the VM folder to move virtual machines to, relative to their
+	// datacenter.
+	Folder string
+
+	// The number of days to keep virtual machines for.
+	TTL int
+
+	// The name of the tag category to create expiry tags in.
+	TagCategory string
+}
+
+// softDestroyVirtualMachine takes the place of destroying a virtual machine
+// when soft destroy is enabled. The virtual machine, which needs to be powered
+// off already, is tagged with its expiry date, moved to the quarantine folder,
+// and renamed so that its name can be reused.
+func softDestroyVirtualMachine(d *schema.ResourceData, client *govmomi.Client, tagsClient *tags.RestClient, opts *softDestroyOptions, vm *object.VirtualMachine) error {
+	now := time.Now().UTC()
+	expires := now.AddDate(0, 0, opts.TTL)
+	log.Printf("[DEBUG] %s: Soft-destroying virtual machine, expires %s", resourceVSphereVirtualMachineIDString(d), expires.Format("2006-01-02"))
+
+	tagID, err := softDestroyExpiryTag(tagsClient, opts.TagCategory, expires)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := tagsClient.AttachTagToObject(ctx, tagID, vm.Reference().Value, vSphereTagTypeVirtualMachine); err != nil {
+		return fmt.Errorf("error attaching expiry tag: %s", err)
+	}
+
+	// The quarantine folder is created the first time a virtual machine in the
+	// datacenter is soft-destroyed.
+	f, err := folder.CreateVirtualMachineFolderFromObject(client, vm, opts.Folder)
+	if err != nil {
+		return fmt.Errorf("could not create folder %q: %s", opts.Folder, err)
+	}
+	if err := folder.MoveObjectTo(vm.Reference(), f); err != nil {
+		return fmt.Errorf("could not move virtual machine to folder %q: %s", opts.Folder, err)
+	}
+
+	props, err := virtualmachine.Properties(vm)
+	if err != nil {
+		return fmt.Errorf("error fetching VM properties: %s", err)
+	}
+	name := fmt.Sprintf("%s-destroyed-%s", props.Name, now.Format("20060102T150405Z"))
+	if err := viapi.RenameObject(client, vm.Reference(), name); err != nil {
+		return fmt.Errorf("error renaming virtual machine to %q: %s", name, err)
+	}
+	return nil
+}
+
+// softDestroyExpiryTag returns the ID of the expiry tag for the supplied
+// date, creating it and its category if necessary.
+func softDestroyExpiryTag(client *tags.RestClient, category string, expires time.Time) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	categoryID, err := client.CreateCategoryIfNotExist(
+		ctx,
+		category,
+		"Expiry dates of virtual machines soft-destroyed by Terraform.",
+		vSphereTagTypeVirtualMachine,
+		false,
+	)
+	if err != nil {
+		return "", fmt.Errorf("error creating tag category %q: %s", category, err)
+	}
+	name := softDestroyTagPrefix + expires.Format("2006-01-02")
+	tagID, err := client.CreateTagIfNotExist(ctx, name, "Soft-destroyed by Terraform, to be deleted after this date.", *categoryID)
+	if err != nil {
+		return "", fmt.Errorf("error creating tag %q: %s", name, err)
+	}
+	return *tagID, nil
+}
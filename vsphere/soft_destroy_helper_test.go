@@ -0,0 +1,89 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/folder"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
+	"github.com/vmware/govmomi/object"
+)
+
+func TestAccResourceVSphereVirtualMachine_softDestroy(t *testing.T) {
+	// The quarantine folder does not exist yet, so this also checks that it is
+	// created on the first soft destroy.
+	quarantine := fmt.Sprintf("terraform-test-quarantine-%s", acctest.RandString(5))
+	defer os.Unsetenv("VSPHERE_SOFT_DESTROY")
+	defer os.Unsetenv("VSPHERE_SOFT_DESTROY_FOLDER")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachinePreCheck(t)
+			os.Setenv("VSPHERE_SOFT_DESTROY", "true")
+			os.Setenv("VSPHERE_SOFT_DESTROY_FOLDER", quarantine)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereVirtualMachineCheckSoftDestroyed(quarantine),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereVirtualMachineCheckExists(true),
+				),
+			},
+		},
+	})
+}
+
+// testAccResourceVSphereVirtualMachineCheckSoftDestroyed checks that the
+// virtual machine was kept, renamed, and moved to the quarantine folder, and
+// then deletes the virtual machine and the folder.
+func testAccResourceVSphereVirtualMachineCheckSoftDestroyed(quarantine string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+		if err != nil {
+			return err
+		}
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return fmt.Errorf("expected VM to be kept after soft destroy: %s", err)
+		}
+		props, err := virtualmachine.Properties(vm)
+		if err != nil {
+			return err
+		}
+		f := object.NewFolder(tVars.client.Client, *props.Parent)
+		fprops, err := folder.Properties(f)
+		if err != nil {
+			return err
+		}
+		if fprops.Name != quarantine {
+			return fmt.Errorf("expected VM to be in folder %q, got %q", quarantine, fprops.Name)
+		}
+		if !strings.HasPrefix(props.Name, "terraform-test-destroyed-") {
+			return fmt.Errorf("expected VM to be renamed, got %q", props.Name)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		task, err := vm.Destroy(ctx)
+		if err != nil {
+			return err
+		}
+		if err := task.Wait(ctx); err != nil {
+			return err
+		}
+		task, err = f.Destroy(ctx)
+		if err != nil {
+			return err
+		}
+		return task.Wait(ctx)
+	}
+}
@@ -0,0 +1,214 @@
+package vsphere
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// taskTrackingPruneThreshold is the number of tracked tasks at which the
+// tasks that have completed are dropped from the list of tracked tasks.
+const taskTrackingPruneThreshold = 25
+
+// taskTrackingCancelTimeout is the overall timeout for cancelling the tracked
+// tasks when Terraform is interrupted.
+const taskTrackingCancelTimeout = 30 * time.Second
+
+// taskTrackingRoundTripper is a soap.RoundTripper that records the tasks that
+// are started through it, so that they can be cancelled when Terraform is
+// interrupted. Without this, tasks such as clones and migrations keep running
+// in vSphere after Terraform exits, and conflict with the next apply.
+type taskTrackingRoundTripper struct {
+	rt     soap.RoundTripper
+	client *vim25.Client
+
+	mu      sync.Mutex
+	tasks   []types.ManagedObjectReference
+	pruning bool
+}
+
+// RoundTrip implements soap.RoundTripper for taskTrackingRoundTripper.
+func (t *taskTrackingRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if err := t.rt.RoundTrip(ctx, req, res); err != nil {
+		return err
+	}
+	if ref, ok := taskTrackingTaskReference(res); ok {
+		log.Printf("[DEBUG] Tracking task %q", ref.Value)
+		t.mu.Lock()
+		t.tasks = append(t.tasks, ref)
+		prune := len(t.tasks) >= taskTrackingPruneThreshold && !t.pruning
+		if prune {
+			t.pruning = true
+		}
+		t.mu.Unlock()
+		if prune {
+			t.prune(ctx)
+		}
+	}
+	return nil
+}
+
+// prune drops the tasks that have completed from the list of tracked tasks,
+// so that the list only grows with the tasks that are running at the same
+// time.
+func (t *taskTrackingRoundTripper) prune(ctx context.Context) {
+	t.mu.Lock()
+	tasks := t.tasks
+	t.mu.Unlock()
+
+	pctx, cancel := context.WithTimeout(ctx, defaultAPITimeout)
+	active, err := taskTrackingActiveTasks(pctx, t.client, tasks)
+	cancel()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruning = false
+	if err != nil {
+		log.Printf("[DEBUG] Error fetching state of tracked tasks: %s", err)
+		return
+	}
+	if len(t.tasks) < len(tasks) {
+		// cancelAll has taken the tasks in the meantime.
+		return
+	}
+	// Keep the tasks that were started while the states were being fetched.
+	t.tasks = append(active, t.tasks[len(tasks):]...)
+	log.Printf("[DEBUG] Pruned tracked tasks, %d of %d still running", len(active), len(tasks))
+}
+
+// cancelAll cancels the tracked tasks that are still queued or running. The
+// states of the tasks are fetched in one call, so that tasks that have
+// already completed are skipped, and the whole pass is bounded by
+// taskTrackingCancelTimeout.
+func (t *taskTrackingRoundTripper) cancelAll() {
+	t.mu.Lock()
+	tasks := t.tasks
+	t.tasks = nil
+	t.mu.Unlock()
+	if len(tasks) < 1 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), taskTrackingCancelTimeout)
+	defer cancel()
+	active, err := taskTrackingActiveTasks(ctx, t.client, tasks)
+	if err != nil {
+		log.Printf("[DEBUG] Error fetching state of tracked tasks, not cancelling them: %s", err)
+		return
+	}
+	for _, ref := range active {
+		if err := object.NewTask(t.client, ref).Cancel(ctx); err != nil {
+			// This is expected for tasks that complete, or that cannot be
+			// cancelled.
+			log.Printf("[DEBUG] Task %q not cancelled: %s", ref.Value, err)
+			continue
+		}
+		log.Printf("[DEBUG] Task %q cancelled", ref.Value)
+	}
+}
+
+// taskTrackingActiveTasks returns the tasks in refs that are queued or
+// running, fetching their states in a single property collector call.
+func taskTrackingActiveTasks(ctx context.Context, c *vim25.Client, refs []types.ManagedObjectReference) ([]types.ManagedObjectReference, error) {
+	pc := property.DefaultCollector(c)
+	for len(refs) > 0 {
+		var tasks []mo.Task
+		err := pc.Retrieve(ctx, refs, []string{"info.state"}, &tasks)
+		if err == nil {
+			var active []types.ManagedObjectReference
+			for _, task := range tasks {
+				switch task.Info.State {
+				case types.TaskInfoStateQueued, types.TaskInfoStateRunning:
+					active = append(active, task.Reference())
+				}
+			}
+			return active, nil
+		}
+		// vSphere removes tasks some time after they complete, and a task that
+		// no longer exists fails the whole call. Drop it, and try again with the
+		// rest.
+		missing, ok := taskTrackingMissingObject(err)
+		if !ok {
+			return nil, err
+		}
+		refs = taskTrackingRemoveReference(refs, missing)
+	}
+	return nil, nil
+}
+
+// taskTrackingMissingObject returns the object of a ManagedObjectNotFound
+// fault.
+func taskTrackingMissingObject(err error) (types.ManagedObjectReference, bool) {
+	if !soap.IsSoapFault(err) {
+		return types.ManagedObjectReference{}, false
+	}
+	f, ok := soap.ToSoapFault(err).VimFault().(types.ManagedObjectNotFound)
+	if !ok {
+		return types.ManagedObjectReference{}, false
+	}
+	return f.Obj, true
+}
+
+// taskTrackingRemoveReference returns refs without ref. refs is returned
+// without ref even if it does not contain it, so that callers that loop until
+// ref is removed always make progress.
+func taskTrackingRemoveReference(refs []types.ManagedObjectReference, ref types.ManagedObjectReference) []types.ManagedObjectReference {
+	var result []types.ManagedObjectReference
+	for _, r := range refs {
+		if r != ref {
+			result = append(result, r)
+		}
+	}
+	if len(result) == len(refs) {
+		return nil
+	}
+	return result
+}
+
+// taskTrackingTaskReference returns the task reference in the response of a
+// SOAP method that starts a task, as generated in the methods package, like
+// the Res.Returnval field of a CreateVM_TaskBody. The second return value is
+// false for responses of methods that do not start a task.
+func taskTrackingTaskReference(res interface{}) (types.ManagedObjectReference, bool) {
+	v := reflect.Indirect(reflect.ValueOf(res))
+	if v.Kind() != reflect.Struct {
+		return types.ManagedObjectReference{}, false
+	}
+	r := v.FieldByName("Res")
+	if !r.IsValid() || r.Kind() != reflect.Ptr || r.IsNil() {
+		return types.ManagedObjectReference{}, false
+	}
+	rv := reflect.Indirect(r).FieldByName("Returnval")
+	if !rv.IsValid() {
+		return types.ManagedObjectReference{}, false
+	}
+	ref, ok := rv.Interface().(types.ManagedObjectReference)
+	if !ok || ref.Type != "Task" {
+		return types.ManagedObjectReference{}, false
+	}
+	return ref, true
+}
+
+// enableTaskCancellation wraps the SOAP client of a VSphereClient so that
+// the tasks started through it are cancelled when ctx is done.
+func enableTaskCancellation(ctx context.Context, client *VSphereClient) {
+	rt := &taskTrackingRoundTripper{
+		rt:     client.vimClient.Client.RoundTripper,
+		client: client.vimClient.Client,
+	}
+	client.vimClient.Client.RoundTripper = rt
+	go func() {
+		<-ctx.Done()
+		log.Printf("[DEBUG] Terraform is stopping, cancelling running tasks")
+		rt.cancelAll()
+	}()
+}
@@ -0,0 +1,126 @@
+package vsphere
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// configureTransport applies the proxy and CA trust settings of the provider
+// to the HTTP client of a SOAP or REST client. It needs to be called before
+// the client makes any calls.
+func (c *Config) configureTransport(hc *http.Client) error {
+	if c.HTTPSProxy == "" && c.CABundleFile == "" && c.CABundle == "" {
+		return nil
+	}
+	t, ok := hc.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unexpected transport type %T", hc.Transport)
+	}
+
+	if c.CABundleFile != "" || c.CABundle != "" {
+		pool, err := c.rootCAs()
+		if err != nil {
+			return err
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+
+	if c.HTTPSProxy != "" {
+		proxy, err := url.Parse(c.HTTPSProxy)
+		if err != nil {
+			return fmt.Errorf("error parsing https_proxy: %s", err)
+		}
+		if proxy.Scheme == "" || proxy.Host == "" {
+			return fmt.Errorf("https_proxy must be a URL like http://proxy.example.com:3128, got %q", c.HTTPSProxy)
+		}
+		noProxy := splitNoProxy(c.NoProxy)
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			if proxyBypassed(req.URL.Hostname(), noProxy) {
+				return nil, nil
+			}
+			return proxy, nil
+		}
+		// The SOAP client dials TLS connections itself to support thumbprints,
+		// which connects to the proxy as if it was the endpoint. The transport
+		// verifies certificates with TLSClientConfig when this is removed.
+		t.DialTLS = nil
+		log.Printf("[DEBUG] Using proxy %s for vSphere API calls", proxy.Host)
+	}
+	return nil
+}
+
+// rootCAs returns the system certificate pool with the certificates in
+// ca_bundle_file and ca_bundle added to it.
+func (c *Config) rootCAs() (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		log.Printf("[DEBUG] Could not load system certificate pool, using only the CA bundle: %s", err)
+		pool = x509.NewCertPool()
+	}
+	if c.CABundleFile != "" {
+		b, err := ioutil.ReadFile(c.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ca_bundle_file: %s", err)
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("ca_bundle_file %q contains no PEM certificates", c.CABundleFile)
+		}
+	}
+	if c.CABundle != "" {
+		if !pool.AppendCertsFromPEM([]byte(c.CABundle)) {
+			return nil, fmt.Errorf("ca_bundle contains no PEM certificates")
+		}
+	}
+	return pool, nil
+}
+
+// splitNoProxy splits a comma-separated no_proxy value into its entries.
+func splitNoProxy(s string) []string {
+	var entries []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// proxyBypassed returns true if host matches an entry of noProxy. Entries can
+// be *, host names, which also match their subdomains, IP addresses, or CIDR
+// blocks.
+func proxyBypassed(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, e := range noProxy {
+		if e == "*" {
+			return true
+		}
+		if ip != nil {
+			if _, block, err := net.ParseCIDR(e); err == nil {
+				if block.Contains(ip) {
+					return true
+				}
+				continue
+			}
+			if ip.Equal(net.ParseIP(e)) {
+				return true
+			}
+			continue
+		}
+		e = strings.TrimPrefix(e, ".")
+		if host == e || strings.HasSuffix(host, "."+e) {
+			return true
+		}
+	}
+	return false
+}
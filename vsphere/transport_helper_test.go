@@ -0,0 +1,93 @@
+package vsphere
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestProxyBypassed(t *testing.T) {
+	noProxy := splitNoProxy(" vc.example.com, .lab.example.com,10.0.0.0/8 ,192.168.1.5")
+	cases := []struct {
+		host     string
+		expected bool
+	}{
+		{"vc.example.com", true},
+		{"VC.example.com", true},
+		{"other.example.com", false},
+		{"esxi1.lab.example.com", true},
+		{"lab.example.com", true},
+		{"notlab.example.com", false},
+		{"10.1.2.3", true},
+		{"11.1.2.3", false},
+		{"192.168.1.5", true},
+		{"192.168.1.6", false},
+	}
+	for _, tc := range cases {
+		if actual := proxyBypassed(tc.host, noProxy); actual != tc.expected {
+			t.Fatalf("%q: expected %t, got %t", tc.host, tc.expected, actual)
+		}
+	}
+	if !proxyBypassed("anything", splitNoProxy("*")) {
+		t.Fatalf("expected * to match all hosts")
+	}
+}
+
+func TestConfigureTransportProxy(t *testing.T) {
+	c := &Config{
+		HTTPSProxy: "http://proxy.example.com:3128",
+		NoProxy:    "vc.example.com",
+	}
+	tr := &http.Transport{}
+	if err := c.configureTransport(&http.Client{Transport: tr}); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://vc2.example.com/sdk", "proxy.example.com:3128"},
+		{"https://vc.example.com/sdk", ""},
+	}
+	for _, tc := range cases {
+		u, _ := url.Parse(tc.url)
+		proxy, err := tr.Proxy(&http.Request{URL: u})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual string
+		if proxy != nil {
+			actual = proxy.Host
+		}
+		if actual != tc.expected {
+			t.Fatalf("%q: expected proxy %q, got %q", tc.url, tc.expected, actual)
+		}
+	}
+}
+
+func TestConfigureTransportInvalid(t *testing.T) {
+	f, err := ioutil.TempFile("", "tf-vsphere-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("not a certificate")
+	f.Close()
+
+	cases := []struct {
+		name   string
+		config *Config
+	}{
+		{"proxy without scheme", &Config{HTTPSProxy: "proxy.example.com"}},
+		{"missing CA file", &Config{CABundleFile: "/nonexistent/ca.pem"}},
+		{"CA file without certificates", &Config{CABundleFile: f.Name()}},
+		{"inline CA without certificates", &Config{CABundle: "not a certificate"}},
+	}
+	for _, tc := range cases {
+		if err := tc.config.configureTransport(&http.Client{Transport: &http.Transport{}}); err == nil {
+			t.Fatalf("%q: expected error, got none", tc.name)
+		}
+	}
+}
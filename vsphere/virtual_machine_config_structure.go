@@ -5,6 +5,9 @@ import (
 	"io/ioutil"
 	"log"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/logging"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -37,11 +40,35 @@ var virtualMachineSwapPlacementAllowedValues = []string{
 	string(types.VirtualMachineConfigInfoSwapPlacementTypeHostLocal),
 }
 
+var virtualMachineLatencySensitivityAllowedValues = []string{
+	string(types.LatencySensitivitySensitivityLevelLow),
+	string(types.LatencySensitivitySensitivityLevelNormal),
+	string(types.LatencySensitivitySensitivityLevelMedium),
+	string(types.LatencySensitivitySensitivityLevelHigh),
+}
+
+var virtualMachineMigrateEncryptionAllowedValues = []string{
+	string(types.VirtualMachineConfigSpecEncryptedVMotionModesDisabled),
+	string(types.VirtualMachineConfigSpecEncryptedVMotionModesOpportunistic),
+	string(types.VirtualMachineConfigSpecEncryptedVMotionModesRequired),
+}
+
 var virtualMachineFirmwareAllowedValues = []string{
 	string(types.GuestOsDescriptorFirmwareTypeBios),
 	string(types.GuestOsDescriptorFirmwareTypeEfi),
 }
 
+// virtualMachineHardwareUpgradePolicyImmediate is the hardware upgrade policy
+// that upgrades a virtual machine's hardware version as soon as it is changed,
+// powering off the virtual machine if necessary.
+const virtualMachineHardwareUpgradePolicyImmediate = "immediate"
+
+var virtualMachineHardwareUpgradePolicyAllowedValues = []string{
+	virtualMachineHardwareUpgradePolicyImmediate,
+	string(types.ScheduledHardwareUpgradeInfoHardwareUpgradePolicyOnSoftPowerOff),
+	string(types.ScheduledHardwareUpgradeInfoHardwareUpgradePolicyAlways),
+}
+
 // getWithRestart fetches the resoruce data specified at key. If the value has
 // changed, a reboot is flagged in the virtual machine by setting
 // reboot_required to true.
@@ -156,10 +183,21 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 
 		// VirtualMachineConfigSpec
 		"name": {
-			Type:         schema.TypeString,
-			Required:     true,
-			Description:  "The name of this virtual machine.",
-			ValidateFunc: validation.StringLenBetween(1, 80),
+			Type:             schema.TypeString,
+			Optional:         true,
+			Computed:         true,
+			Description:      "The name of this virtual machine. One of name or name_prefix must be set.",
+			ConflictsWith:    []string{"name_prefix"},
+			ValidateFunc:     validation.StringLenBetween(1, 80),
+			DiffSuppressFunc: virtualMachineNameRule.diffSuppressFunc(),
+		},
+		"name_prefix": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			ForceNew:      true,
+			Description:   "Generate a unique name for this virtual machine that starts with this prefix.",
+			ConflictsWith: []string{"name"},
+			ValidateFunc:  virtualMachineNameRule.prefixValidateFunc(),
 		},
 		"num_cpus": {
 			Type:        schema.TypeInt,
@@ -204,6 +242,76 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "Allow memory to be added to this virtual machine while it is running.",
 		},
+		"memory_reservation_locked_to_max": {
+			Type:          schema.TypeBool,
+			Optional:      true,
+			Description:   "Lock the memory reservation of this virtual machine to its memory size, so that the reservation follows any changes to memory. Conflicts with memory_reservation.",
+			ConflictsWith: []string{"memory_reservation"},
+		},
+		"memory_balloon_max_size": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      -1,
+			Description:  "The maximum amount of memory (in MB) that can be reclaimed from this virtual machine by the balloon driver. 0 disables ballooning, and -1 means no limit.",
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+		"latency_sensitivity": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.LatencySensitivitySensitivityLevelNormal),
+			Description:  "Controls the scheduling delay of the virtual machine. Use a higher sensitivity for applications that require lower latency, such as VOIP, media player applications, or applications that require frequent access to mouse or keyboard devices. Can be one of low, normal, medium, or high.",
+			ValidateFunc: validation.StringInSlice(virtualMachineLatencySensitivityAllowedValues, false),
+		},
+		"cpu_affinity": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "The logical processors of the host that this virtual machine is allowed to run on. Not supported on virtual machines in DRS clusters.",
+			Elem: &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+		"numa_node_affinity": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "The NUMA nodes of the host that this virtual machine is allowed to run on.",
+			Elem: &schema.Schema{
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+		"vnc_port": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "The port of the legacy VNC console of this virtual machine, served by the host. 0 disables the VNC console.",
+			ValidateFunc: validation.IntBetween(0, 65535),
+		},
+		"vnc_password": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "The password of the legacy VNC console of this virtual machine.",
+		},
+		"video_memory": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The amount of video memory, in KB, of the video card of this virtual machine.",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"video_3d_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Enable 3D acceleration on the video card of this virtual machine.",
+		},
+		"video_num_displays": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The number of displays of the video card of this virtual machine.",
+			ValidateFunc: validation.IntBetween(1, 10),
+		},
 		"swap_placement_policy": {
 			Type:         schema.TypeString,
 			Optional:     true,
@@ -211,10 +319,24 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 			Description:  "The swap file placement policy for this virtual machine. Can be one of inherit, hostLocal, or vmDirectory.",
 			ValidateFunc: validation.StringInSlice(virtualMachineSwapPlacementAllowedValues, false),
 		},
+		"migrate_encryption": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The encryption mode to use for vMotion of this virtual machine. Can be one of disabled, opportunistic, or required.",
+			ValidateFunc: validation.StringInSlice(virtualMachineMigrateEncryptionAllowedValues, false),
+		},
 		"annotation": {
-			Type:        schema.TypeString,
-			Optional:    true,
-			Description: "User-provided description of the virtual machine.",
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "User-provided description of the virtual machine.",
+			ConflictsWith: []string{"managed_annotation"},
+		},
+		"managed_annotation": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "A section of the virtual machine's annotation that is managed by Terraform. Notes outside of this section are preserved. The placeholders {{name}}, {{folder}}, and {{guest_id}} are replaced with the respective values of the virtual machine.",
+			ConflictsWith: []string{"annotation"},
 		},
 		"guest_id": {
 			Type:        schema.TypeString,
@@ -234,6 +356,20 @@ func schemaVirtualMachineConfigSpec() map[string]*schema.Schema {
 			Description:  "The firmware interface to use on the virtual machine. Can be one of bios or EFI.",
 			ValidateFunc: validation.StringInSlice(virtualMachineFirmwareAllowedValues, false),
 		},
+		"hardware_version": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The hardware version of the virtual machine. Changes to this value upgrade the virtual machine according to hardware_upgrade_policy. Downgrades are not supported.",
+			ValidateFunc: validation.IntAtLeast(4),
+		},
+		"hardware_upgrade_policy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      virtualMachineHardwareUpgradePolicyImmediate,
+			Description:  "The policy for upgrading the hardware version of the virtual machine. Can be one of immediate, onSoftPowerOff, or always.",
+			ValidateFunc: validation.StringInSlice(virtualMachineHardwareUpgradePolicyAllowedValues, false),
+		},
 		"extra_config": {
 			Type:        schema.TypeMap,
 			Optional:    true,
@@ -291,8 +427,7 @@ func expandVirtualMachineBootOptions(d *schema.ResourceData, client *govmomi.Cli
 		BootRetryDelay:   int64(d.Get("boot_retry_delay").(int)),
 	}
 	// Only set EFI secure boot if we are on vSphere 6.5 and higher
-	version := viapi.ParseVersionFromClient(client)
-	if version.Newer(viapi.VSphereVersion{Product: version.Product, Major: 6, Minor: 5}) {
+	if viapi.CapabilityEFISecureBoot.Supported(client) {
 		obj.EfiSecureBootEnabled = getBoolWithRestart(d, "efi_secure_boot_enabled")
 	}
 	return obj
@@ -444,6 +579,205 @@ func flattenVirtualMachineResourceAllocation(d *schema.ResourceData, obj *types.
 	return nil
 }
 
+// virtualMachineMemoryBalloonMaxSizeKey is the extraConfig key used to
+// control the maximum size of the memory balloon in a virtual machine.
+const virtualMachineMemoryBalloonMaxSizeKey = "sched.mem.maxmemctl"
+
+// expandMemoryBalloonMaxSize returns the extraConfig option for
+// memory_balloon_max_size if it has changed. A value of -1 removes the option,
+// which restores the default of no limit.
+func expandMemoryBalloonMaxSize(d *schema.ResourceData) []types.BaseOptionValue {
+	if !d.HasChange("memory_balloon_max_size") {
+		return nil
+	}
+	// The balloon limit is only picked up on the next power on.
+	d.Set("reboot_required", true)
+	var value string
+	if v := d.Get("memory_balloon_max_size").(int); v >= 0 {
+		value = strconv.Itoa(v)
+	}
+	return []types.BaseOptionValue{
+		&types.OptionValue{
+			Key:   virtualMachineMemoryBalloonMaxSizeKey,
+			Value: value,
+		},
+	}
+}
+
+// flattenMemoryBalloonMaxSize reads the maximum memory balloon size from the
+// extraConfig of a virtual machine into memory_balloon_max_size.
+func flattenMemoryBalloonMaxSize(d *schema.ResourceData, opts []types.BaseOptionValue) error {
+	for _, v := range opts {
+		ov := v.GetOptionValue()
+		if ov.Key != virtualMachineMemoryBalloonMaxSizeKey {
+			continue
+		}
+		if s, ok := ov.Value.(string); ok && s != "" {
+			size, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("error parsing %s: %s", virtualMachineMemoryBalloonMaxSizeKey, err)
+			}
+			return d.Set("memory_balloon_max_size", size)
+		}
+	}
+	return d.Set("memory_balloon_max_size", -1)
+}
+
+// virtualMachineNumaNodeAffinityKey is the extraConfig key used to
+// constrain the NUMA nodes that a virtual machine can be scheduled on.
+const virtualMachineNumaNodeAffinityKey = "numa.nodeAffinity"
+
+// expandNumaNodeAffinity returns the extraConfig option for numa_node_affinity
+// if it has changed. An empty set removes the option, which allows the
+// virtual machine to run on all NUMA nodes.
+func expandNumaNodeAffinity(d *schema.ResourceData) []types.BaseOptionValue {
+	if !d.HasChange("numa_node_affinity") {
+		return nil
+	}
+	// NUMA placement is only done when the virtual machine is powered on.
+	d.Set("reboot_required", true)
+	nodes := structure.SliceInterfacesToInts(d.Get("numa_node_affinity").(*schema.Set).List())
+	sort.Ints(nodes)
+	var s []string
+	for _, n := range nodes {
+		s = append(s, strconv.Itoa(n))
+	}
+	return []types.BaseOptionValue{
+		&types.OptionValue{
+			Key:   virtualMachineNumaNodeAffinityKey,
+			Value: strings.Join(s, ","),
+		},
+	}
+}
+
+// flattenNumaNodeAffinity reads the NUMA node affinity from the extraConfig
+// of a virtual machine into numa_node_affinity.
+func flattenNumaNodeAffinity(d *schema.ResourceData, opts []types.BaseOptionValue) error {
+	var nodes []int
+	for _, v := range opts {
+		ov := v.GetOptionValue()
+		if ov.Key != virtualMachineNumaNodeAffinityKey {
+			continue
+		}
+		s, ok := ov.Value.(string)
+		if !ok || s == "" {
+			break
+		}
+		for _, p := range strings.Split(s, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil {
+				return fmt.Errorf("error parsing %s: %s", virtualMachineNumaNodeAffinityKey, err)
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	return d.Set("numa_node_affinity", nodes)
+}
+
+// The following are the extraConfig keys of the legacy VNC console of a
+// virtual machine.
+const (
+	virtualMachineVNCEnabledKey  = "RemoteDisplay.vnc.enabled"
+	virtualMachineVNCPortKey     = "RemoteDisplay.vnc.port"
+	virtualMachineVNCPasswordKey = "RemoteDisplay.vnc.password"
+)
+
+// expandVNC returns the extraConfig options for vnc_port and vnc_password if
+// either has changed. A port of 0 removes the options, which disables the VNC
+// console.
+func expandVNC(d *schema.ResourceData) []types.BaseOptionValue {
+	if !d.HasChange("vnc_port") && !d.HasChange("vnc_password") {
+		return nil
+	}
+	// The VNC console is only started with these settings on the next power on.
+	d.Set("reboot_required", true)
+	var enabled, port, password string
+	if v := d.Get("vnc_port").(int); v > 0 {
+		enabled = "true"
+		port = strconv.Itoa(v)
+		password = d.Get("vnc_password").(string)
+	}
+	return []types.BaseOptionValue{
+		&types.OptionValue{Key: virtualMachineVNCEnabledKey, Value: enabled},
+		&types.OptionValue{Key: virtualMachineVNCPortKey, Value: port},
+		&types.OptionValue{Key: virtualMachineVNCPasswordKey, Value: password},
+	}
+}
+
+// flattenVNC reads the port of the legacy VNC console from the extraConfig of
+// a virtual machine into vnc_port. The password is not read back, so that it
+// is not copied into state when it was set outside of Terraform.
+func flattenVNC(d *schema.ResourceData, opts []types.BaseOptionValue) error {
+	values := make(map[string]string)
+	for _, v := range opts {
+		ov := v.GetOptionValue()
+		if s, ok := ov.Value.(string); ok {
+			values[ov.Key] = s
+		}
+	}
+	if !strings.EqualFold(values[virtualMachineVNCEnabledKey], "true") || values[virtualMachineVNCPortKey] == "" {
+		return d.Set("vnc_port", 0)
+	}
+	port, err := strconv.Atoi(values[virtualMachineVNCPortKey])
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %s", virtualMachineVNCPortKey, err)
+	}
+	return d.Set("vnc_port", port)
+}
+
+// expandLatencySensitivity reads latency_sensitivity into a
+// LatencySensitivity. Changes only take effect when the virtual machine is
+// powered on again.
+func expandLatencySensitivity(d *schema.ResourceData) *types.LatencySensitivity {
+	return &types.LatencySensitivity{
+		Level: types.LatencySensitivitySensitivityLevel(getWithRestart(d, "latency_sensitivity").(string)),
+	}
+}
+
+// flattenLatencySensitivity reads a LatencySensitivity into
+// latency_sensitivity.
+func flattenLatencySensitivity(d *schema.ResourceData, obj *types.LatencySensitivity) error {
+	if obj == nil {
+		return d.Set("latency_sensitivity", string(types.LatencySensitivitySensitivityLevelNormal))
+	}
+	return d.Set("latency_sensitivity", string(obj.Level))
+}
+
+// expandCPUAffinity reads cpu_affinity into a VirtualMachineAffinityInfo. An
+// empty set clears the affinity.
+func expandCPUAffinity(d *schema.ResourceData) *types.VirtualMachineAffinityInfo {
+	cpus := structure.SliceInterfacesToInts(d.Get("cpu_affinity").(*schema.Set).List())
+	sort.Ints(cpus)
+	obj := &types.VirtualMachineAffinityInfo{
+		AffinitySet: []int32{},
+	}
+	for _, cpu := range cpus {
+		obj.AffinitySet = append(obj.AffinitySet, int32(cpu))
+	}
+	return obj
+}
+
+// flattenCPUAffinity reads a VirtualMachineAffinityInfo into cpu_affinity.
+func flattenCPUAffinity(d *schema.ResourceData, obj *types.VirtualMachineAffinityInfo) error {
+	var cpus []int
+	if obj != nil {
+		for _, cpu := range obj.AffinitySet {
+			cpus = append(cpus, int(cpu))
+		}
+	}
+	return d.Set("cpu_affinity", cpus)
+}
+
+// expandVirtualMachineExtraConfig returns the extraConfig options of
+// extra_config, along with the options of the other attributes that are
+// stored in extraConfig.
+func expandVirtualMachineExtraConfig(d *schema.ResourceData) []types.BaseOptionValue {
+	opts := expandExtraConfig(d)
+	opts = append(opts, expandMemoryBalloonMaxSize(d)...)
+	opts = append(opts, expandNumaNodeAffinity(d)...)
+	return append(opts, expandVNC(d)...)
+}
+
 // expandExtraConfig reads in all the extra_config key/value pairs and returns
 // the appropriate OptionValue slice.
 //
@@ -649,6 +983,143 @@ func flattenVAppConfig(d *schema.ResourceData, config types.BaseVmConfigInfo) er
 	return nil
 }
 
+// virtualMachineManagedAnnotationBegin and virtualMachineManagedAnnotationEnd
+// delimit the section of a virtual machine's annotation that is managed by
+// managed_annotation.
+const (
+	virtualMachineManagedAnnotationBegin = "--- BEGIN TERRAFORM MANAGED NOTES ---"
+	virtualMachineManagedAnnotationEnd   = "--- END TERRAFORM MANAGED NOTES ---"
+)
+
+// splitManagedAnnotation splits an annotation into the notes that live
+// outside of the managed section, and the contents of the managed section
+// itself. The boolean return value is false if there is no managed section
+// present in the annotation.
+func splitManagedAnnotation(s string) (string, string, bool) {
+	b := strings.Index(s, virtualMachineManagedAnnotationBegin)
+	if b < 0 {
+		return s, "", false
+	}
+	e := strings.Index(s[b:], virtualMachineManagedAnnotationEnd)
+	if e < 0 {
+		return s, "", false
+	}
+	e += b
+	section := strings.Trim(s[b+len(virtualMachineManagedAnnotationBegin):e], "\n")
+	manual := strings.TrimRight(s[:b], "\n")
+	if rest := strings.TrimLeft(s[e+len(virtualMachineManagedAnnotationEnd):], "\n"); rest != "" {
+		if manual != "" {
+			manual += "\n"
+		}
+		manual += rest
+	}
+	return manual, section, true
+}
+
+// joinManagedAnnotation appends a managed section to the supplied notes.
+func joinManagedAnnotation(manual, section string) string {
+	var parts []string
+	if manual != "" {
+		parts = append(parts, manual, "")
+	}
+	parts = append(parts, virtualMachineManagedAnnotationBegin, section, virtualMachineManagedAnnotationEnd)
+	return strings.Join(parts, "\n")
+}
+
+// interpolateManagedAnnotation replaces the placeholders supported in
+// managed_annotation with their values from the supplied ResourceData.
+func interpolateManagedAnnotation(d *schema.ResourceData, s string) string {
+	r := strings.NewReplacer(
+		"{{name}}", d.Get("name").(string),
+		"{{folder}}", d.Get("folder").(string),
+		"{{guest_id}}", d.Get("guest_id").(string),
+	)
+	return r.Replace(s)
+}
+
+// expandVirtualMachineAnnotation returns the annotation that should be set on
+// the virtual machine, taking into account the annotation currently on the
+// virtual machine, supplied by current.
+//
+// When managed_annotation is in use, only the managed section is replaced, and
+// notes outside of that section are preserved. Otherwise, annotation is
+// authoritative, with the exception of managed_annotation having just been
+// removed from configuration, in which case the managed section is stripped
+// and the remaining notes are kept.
+func expandVirtualMachineAnnotation(d *schema.ResourceData, current string) string {
+	manual, _, _ := splitManagedAnnotation(current)
+	if managed := d.Get("managed_annotation").(string); managed != "" {
+		return joinManagedAnnotation(manual, interpolateManagedAnnotation(d, managed))
+	}
+	if o, _ := d.GetChange("managed_annotation"); o.(string) != "" && d.Get("annotation").(string) == "" {
+		return manual
+	}
+	return d.Get("annotation").(string)
+}
+
+// flattenVirtualMachineAnnotation reads the annotation on a virtual machine
+// into annotation or managed_annotation, depending on which one is in use.
+//
+// When managed_annotation is in use, the managed section is compared to the
+// interpolated value of managed_annotation in state, so that the
+// uninterpolated value is kept as long as the section has not been changed.
+func flattenVirtualMachineAnnotation(d *schema.ResourceData, annotation string) error {
+	managed := d.Get("managed_annotation").(string)
+	if managed == "" {
+		return d.Set("annotation", annotation)
+	}
+	_, section, ok := splitManagedAnnotation(annotation)
+	switch {
+	case !ok:
+		return d.Set("managed_annotation", "")
+	case section != interpolateManagedAnnotation(d, managed):
+		return d.Set("managed_annotation", section)
+	}
+	return nil
+}
+
+// virtualMachineHardwareUpgradeKey returns the hardware version key that the
+// virtual machine needs to be upgraded to, based on the current hardware
+// version key of the virtual machine supplied in current. An empty string is
+// returned if no upgrade is necessary.
+func virtualMachineHardwareUpgradeKey(d *schema.ResourceData, current string) (string, error) {
+	v, ok := d.GetOk("hardware_version")
+	if !ok {
+		return "", nil
+	}
+	version := v.(int)
+	cv, err := viapi.ParseHardwareVersion(current)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case version < cv:
+		return "", fmt.Errorf("cannot downgrade hardware version from %d to %d", cv, version)
+	case version == cv:
+		return "", nil
+	}
+	return viapi.HardwareVersionKey(version), nil
+}
+
+// flattenVirtualMachineHardwareVersion reads the hardware version of a virtual
+// machine into hardware_version. If an upgrade has been scheduled and is still
+// pending, the scheduled version is used so that no diff is produced while
+// waiting for the upgrade to happen.
+func flattenVirtualMachineHardwareVersion(d *schema.ResourceData, obj *types.VirtualMachineConfigInfo) error {
+	key := obj.Version
+	if info := obj.ScheduledHardwareUpgradeInfo; info != nil {
+		if info.ScheduledHardwareUpgradeStatus == string(types.ScheduledHardwareUpgradeInfoHardwareUpgradeStatusPending) &&
+			info.UpgradePolicy != string(types.ScheduledHardwareUpgradeInfoHardwareUpgradePolicyNever) {
+			key = info.VersionKey
+		}
+	}
+	version, err := viapi.ParseHardwareVersion(key)
+	if err != nil {
+		return err
+	}
+	return d.Set("hardware_version", version)
+}
+
 // expandCPUCountConfig is a helper for expandVirtualMachineConfigSpec that
 // determines if we need to restart the VM due to a change in CPU count. This
 // is determined by the net change in CPU count and the pre-update values of
@@ -718,27 +1189,31 @@ func expandVirtualMachineConfigSpec(d *schema.ResourceData, client *govmomi.Clie
 	}
 
 	obj := types.VirtualMachineConfigSpec{
-		Name:                d.Get("name").(string),
-		GuestId:             getWithRestart(d, "guest_id").(string),
-		AlternateGuestName:  getWithRestart(d, "alternate_guest_name").(string),
-		Annotation:          d.Get("annotation").(string),
-		Tools:               expandToolsConfigInfo(d),
-		Flags:               expandVirtualMachineFlagInfo(d),
-		NumCPUs:             expandCPUCountConfig(d),
-		NumCoresPerSocket:   int32(getWithRestart(d, "num_cores_per_socket").(int)),
-		MemoryMB:            expandMemorySizeConfig(d),
-		MemoryHotAddEnabled: getBoolWithRestart(d, "memory_hot_add_enabled"),
-		CpuHotAddEnabled:    getBoolWithRestart(d, "cpu_hot_add_enabled"),
-		CpuHotRemoveEnabled: getBoolWithRestart(d, "cpu_hot_remove_enabled"),
-		CpuAllocation:       expandVirtualMachineResourceAllocation(d, "cpu"),
-		MemoryAllocation:    expandVirtualMachineResourceAllocation(d, "memory"),
-		ExtraConfig:         expandExtraConfig(d),
-		SwapPlacement:       getWithRestart(d, "swap_placement_policy").(string),
-		BootOptions:         expandVirtualMachineBootOptions(d, client),
-		VAppConfig:          vappConfig,
-		Firmware:            getWithRestart(d, "firmware").(string),
-		NestedHVEnabled:     getBoolWithRestart(d, "nested_hv_enabled"),
-		VPMCEnabled:         getBoolWithRestart(d, "cpu_performance_counters_enabled"),
+		Name:                         d.Get("name").(string),
+		GuestId:                      getWithRestart(d, "guest_id").(string),
+		AlternateGuestName:           getWithRestart(d, "alternate_guest_name").(string),
+		Annotation:                   expandVirtualMachineAnnotation(d, ""),
+		Tools:                        expandToolsConfigInfo(d),
+		Flags:                        expandVirtualMachineFlagInfo(d),
+		NumCPUs:                      expandCPUCountConfig(d),
+		NumCoresPerSocket:            int32(getWithRestart(d, "num_cores_per_socket").(int)),
+		MemoryMB:                     expandMemorySizeConfig(d),
+		MemoryHotAddEnabled:          getBoolWithRestart(d, "memory_hot_add_enabled"),
+		CpuHotAddEnabled:             getBoolWithRestart(d, "cpu_hot_add_enabled"),
+		CpuHotRemoveEnabled:          getBoolWithRestart(d, "cpu_hot_remove_enabled"),
+		CpuAllocation:                expandVirtualMachineResourceAllocation(d, "cpu"),
+		MemoryAllocation:             expandVirtualMachineResourceAllocation(d, "memory"),
+		ExtraConfig:                  expandVirtualMachineExtraConfig(d),
+		LatencySensitivity:           expandLatencySensitivity(d),
+		CpuAffinity:                  expandCPUAffinity(d),
+		SwapPlacement:                getWithRestart(d, "swap_placement_policy").(string),
+		MigrateEncryption:            d.Get("migrate_encryption").(string),
+		BootOptions:                  expandVirtualMachineBootOptions(d, client),
+		VAppConfig:                   vappConfig,
+		Firmware:                     getWithRestart(d, "firmware").(string),
+		NestedHVEnabled:              getBoolWithRestart(d, "nested_hv_enabled"),
+		VPMCEnabled:                  getBoolWithRestart(d, "cpu_performance_counters_enabled"),
+		MemoryReservationLockedToMax: structure.GetBool(d, "memory_reservation_locked_to_max"),
 	}
 
 	return obj, nil
@@ -752,7 +1227,6 @@ func flattenVirtualMachineConfigInfo(d *schema.ResourceData, obj *types.VirtualM
 	d.Set("name", obj.Name)
 	d.Set("guest_id", obj.GuestId)
 	d.Set("alternate_guest_name", obj.AlternateGuestName)
-	d.Set("annotation", obj.Annotation)
 	d.Set("num_cpus", obj.Hardware.NumCPU)
 	d.Set("num_cores_per_socket", obj.Hardware.NumCoresPerSocket)
 	d.Set("memory", obj.Hardware.MemoryMB)
@@ -760,12 +1234,19 @@ func flattenVirtualMachineConfigInfo(d *schema.ResourceData, obj *types.VirtualM
 	d.Set("cpu_hot_add_enabled", obj.CpuHotAddEnabled)
 	d.Set("cpu_hot_remove_enabled", obj.CpuHotRemoveEnabled)
 	d.Set("swap_placement_policy", obj.SwapPlacement)
+	d.Set("migrate_encryption", obj.MigrateEncryption)
 	d.Set("firmware", obj.Firmware)
 	d.Set("nested_hv_enabled", obj.NestedHVEnabled)
 	d.Set("cpu_performance_counters_enabled", obj.VPMCEnabled)
 	d.Set("change_version", obj.ChangeVersion)
 	d.Set("uuid", obj.Uuid)
 
+	if err := flattenVirtualMachineHardwareVersion(d, obj); err != nil {
+		return err
+	}
+	if err := flattenVirtualMachineAnnotation(d, obj.Annotation); err != nil {
+		return err
+	}
 	if err := flattenToolsConfigInfo(d, obj.Tools); err != nil {
 		return err
 	}
@@ -775,9 +1256,31 @@ func flattenVirtualMachineConfigInfo(d *schema.ResourceData, obj *types.VirtualM
 	if err := flattenVirtualMachineResourceAllocation(d, obj.CpuAllocation, "cpu"); err != nil {
 		return err
 	}
+	// When the memory reservation is locked to the memory size, vSphere manages
+	// the reservation itself, so memory_reservation is left alone.
+	reservation := d.Get("memory_reservation")
 	if err := flattenVirtualMachineResourceAllocation(d, obj.MemoryAllocation, "memory"); err != nil {
 		return err
 	}
+	if obj.MemoryReservationLockedToMax != nil && *obj.MemoryReservationLockedToMax {
+		d.Set("memory_reservation", reservation)
+	}
+	structure.SetBoolPtr(d, "memory_reservation_locked_to_max", obj.MemoryReservationLockedToMax)
+	if err := flattenMemoryBalloonMaxSize(d, obj.ExtraConfig); err != nil {
+		return err
+	}
+	if err := flattenNumaNodeAffinity(d, obj.ExtraConfig); err != nil {
+		return err
+	}
+	if err := flattenVNC(d, obj.ExtraConfig); err != nil {
+		return err
+	}
+	if err := flattenLatencySensitivity(d, obj.LatencySensitivity); err != nil {
+		return err
+	}
+	if err := flattenCPUAffinity(d, obj.CpuAffinity); err != nil {
+		return err
+	}
 	if err := flattenExtraConfig(d, obj.ExtraConfig); err != nil {
 		return err
 	}
@@ -822,6 +1325,26 @@ func expandVirtualMachineConfigSpecChanged(d *schema.ResourceData, client *govmo
 		return types.VirtualMachineConfigSpec{}, false, err
 	}
 
+	// Merge the new annotation with the notes currently on the virtual machine,
+	// in case only a managed section is being updated.
+	newSpec.Annotation = expandVirtualMachineAnnotation(d, info.Annotation)
+
+	// Carry over the current values of any attribute groups that have been
+	// excluded from sync and have not been changed in configuration. This
+	// ensures that out-of-band changes to these values are not reverted by
+	// unrelated updates.
+	if resourceVSphereVirtualMachineSyncSkipped(d, "sync_cpu_memory") {
+		newSpec.NumCPUs = oldSpec.NumCPUs
+		newSpec.NumCoresPerSocket = oldSpec.NumCoresPerSocket
+		newSpec.MemoryMB = oldSpec.MemoryMB
+		newSpec.CpuAllocation = oldSpec.CpuAllocation
+		newSpec.MemoryAllocation = oldSpec.MemoryAllocation
+		newSpec.MemoryReservationLockedToMax = oldSpec.MemoryReservationLockedToMax
+	}
+	if resourceVSphereVirtualMachineSyncSkipped(d, "sync_annotation") {
+		newSpec.Annotation = oldSpec.Annotation
+	}
+
 	// Return the new spec and compare
 	return newSpec, !reflect.DeepEqual(oldSpec, newSpec), nil
 }
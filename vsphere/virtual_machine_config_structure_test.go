@@ -0,0 +1,65 @@
+package vsphere
+
+import (
+	"testing"
+)
+
+type testSplitManagedAnnotation struct {
+	Name string
+
+	annotation      string
+	expectedManual  string
+	expectedSection string
+	expectedOK      bool
+}
+
+func (tc *testSplitManagedAnnotation) Test(t *testing.T) {
+	manual, section, ok := splitManagedAnnotation(tc.annotation)
+	if manual != tc.expectedManual {
+		t.Fatalf("expected manual notes %q, got %q", tc.expectedManual, manual)
+	}
+	if section != tc.expectedSection {
+		t.Fatalf("expected managed section %q, got %q", tc.expectedSection, section)
+	}
+	if ok != tc.expectedOK {
+		t.Fatalf("expected ok to be %t, got %t", tc.expectedOK, ok)
+	}
+}
+
+func TestSplitManagedAnnotation(t *testing.T) {
+	cases := []testSplitManagedAnnotation{
+		{
+			Name:           "no managed section",
+			annotation:     "foo\nbar",
+			expectedManual: "foo\nbar",
+		},
+		{
+			Name:            "managed section only",
+			annotation:      joinManagedAnnotation("", "managed"),
+			expectedSection: "managed",
+			expectedOK:      true,
+		},
+		{
+			Name:            "manual notes and managed section",
+			annotation:      joinManagedAnnotation("foo\nbar", "managed\nnotes"),
+			expectedManual:  "foo\nbar",
+			expectedSection: "managed\nnotes",
+			expectedOK:      true,
+		},
+		{
+			Name:            "notes after managed section",
+			annotation:      joinManagedAnnotation("foo", "managed") + "\nbar",
+			expectedManual:  "foo\nbar",
+			expectedSection: "managed",
+			expectedOK:      true,
+		},
+		{
+			Name:           "unterminated managed section",
+			annotation:     "foo\n" + virtualMachineManagedAnnotationBegin + "\nmanaged",
+			expectedManual: "foo\n" + virtualMachineManagedAnnotationBegin + "\nmanaged",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
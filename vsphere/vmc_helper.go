@@ -0,0 +1,64 @@
+package vsphere
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/resourcepool"
+)
+
+// vmcWorkloadFolder is the VM folder that virtual machines must be placed in,
+// or below, on VMware Cloud on AWS.
+const vmcWorkloadFolder = "Workloads"
+
+// vmcWorkloadFolderPath returns p as a path below vmcWorkloadFolder. Paths
+// that are already in vmcWorkloadFolder are returned as is.
+func vmcWorkloadFolderPath(p string) string {
+	p = strings.Trim(p, "/")
+	switch {
+	case p == "":
+		return vmcWorkloadFolder
+	case p == vmcWorkloadFolder || strings.HasPrefix(p, vmcWorkloadFolder+"/"):
+		return p
+	}
+	return vmcWorkloadFolder + "/" + p
+}
+
+// vmcCheckHostOperation returns an error when vmc_mode is set, for resources
+// that configure hosts directly. The cloud administrator of VMware Cloud on
+// AWS has no permission to do so, and the resource would fail part way
+// through with a permission fault otherwise.
+func vmcCheckHostOperation(meta interface{}, resourceType string) error {
+	if !meta.(*VSphereClient).vmcMode {
+		return nil
+	}
+	return fmt.Errorf("%s configures hosts directly, which is not permitted on VMware Cloud on AWS (vmc_mode is set)", resourceType)
+}
+
+// vmcCheckVirtualMachinePlacement returns an error when vmc_mode is set and
+// the virtual machine is to be placed in a folder outside of
+// vmcWorkloadFolder, or in the root resource pool of a cluster, neither of
+// which is permitted on VMware Cloud on AWS.
+func vmcCheckVirtualMachinePlacement(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient)
+	if !client.vmcMode {
+		return nil
+	}
+	if f := strings.Trim(d.Get("folder").(string), "/"); vmcWorkloadFolderPath(f) != f {
+		return fmt.Errorf("folder %q is not permitted on VMware Cloud on AWS, virtual machines must be placed in the %s folder or a folder below it", f, vmcWorkloadFolder)
+	}
+	poolID := d.Get("resource_pool_id").(string)
+	pool, err := resourcepool.FromID(client.vimClient, poolID)
+	if err != nil {
+		return fmt.Errorf("could not find resource pool ID %q: %s", poolID, err)
+	}
+	props, err := resourcepool.Properties(pool)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool properties: %s", err)
+	}
+	if props.Parent != nil && props.Parent.Type != "ResourcePool" && props.Parent.Type != "VirtualApp" {
+		return fmt.Errorf("resource pool %q is the root resource pool of its cluster, which is not permitted on VMware Cloud on AWS. Use a resource pool in it, like Compute-ResourcePool", pool.InventoryPath)
+	}
+	return nil
+}
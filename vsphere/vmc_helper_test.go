@@ -0,0 +1,21 @@
+package vsphere
+
+import "testing"
+
+func TestVmcWorkloadFolderPath(t *testing.T) {
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "", expected: "Workloads"},
+		{path: "Workloads", expected: "Workloads"},
+		{path: "/Workloads/web/", expected: "Workloads/web"},
+		{path: "terraform-quarantine", expected: "Workloads/terraform-quarantine"},
+		{path: "WorkloadsOld", expected: "Workloads/WorkloadsOld"},
+	}
+	for _, tc := range cases {
+		if actual := vmcWorkloadFolderPath(tc.path); actual != tc.expected {
+			t.Fatalf("%q: expected %q, got %q", tc.path, tc.expected, actual)
+		}
+	}
+}